@@ -0,0 +1,256 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/model"
+	"grid-trading-btc-binance/internal/repository"
+)
+
+const (
+	defaultAccountsDir  = "accounts"
+	accountRestartDelay = 10 * time.Second
+)
+
+// runAccounts dispatches the `accounts` subcommands. Every account is a directory of the accounts
+// dir with its own .env (or config.yaml): its API keys, symbol and settings. The state files,
+// logs and CSVs of the account live in that directory.
+func runAccounts(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing accounts command (expected run or report)")
+	}
+	switch args[0] {
+	case "run":
+		return runAccountsRun(args[1:])
+	case "report":
+		return runAccountsReport(args[1:])
+	}
+	return fmt.Errorf("unknown accounts command %q (expected run or report)", args[0])
+}
+
+// listAccounts returns the account directories of dir, sorted by name
+func listAccounts(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the accounts dir: %w", err)
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		for _, file := range []string{".env", "config.yaml"} {
+			if _, err := os.Stat(filepath.Join(dir, e.Name(), file)); err == nil {
+				names = append(names, e.Name())
+				break
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no account in %s (expected one directory per account with its .env or config.yaml)", dir)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// runAccountsRun implements `accounts run`: one bot per account, each in its own directory, with
+// ACCOUNT_NAME set to the directory name (tags its alerts). A bot that stops is restarted;
+// SIGINT/SIGTERM stop them all. The bots keep their state, config and singletons apart because
+// each one is its own process of this binary; their console output is prefixed with the account.
+func runAccountsRun(args []string) error {
+	fs := flag.NewFlagSet("accounts run", flag.ExitOnError)
+	dir := fs.String("dir", defaultAccountsDir, "directory with one subdirectory per account")
+	fs.Parse(args)
+
+	names, err := listAccounts(*dir)
+	if err != nil {
+		return err
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the executable: %w", err)
+	}
+
+	var (
+		mu       sync.Mutex
+		running  = make(map[string]*exec.Cmd)
+		stopping bool
+		wg       sync.WaitGroup
+		output   = &prefixWriter{out: os.Stdout}
+	)
+	for _, name := range names {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			for {
+				cmd := exec.Command(executable, "run")
+				cmd.Dir = filepath.Join(*dir, name)
+				cmd.Env = append(os.Environ(), "ACCOUNT_NAME="+name)
+				stdout, stderr := output.pipe(name), output.pipe(name)
+				cmd.Stdout, cmd.Stderr = stdout, stderr
+
+				mu.Lock()
+				if stopping {
+					mu.Unlock()
+					return
+				}
+				err := cmd.Start()
+				if err == nil {
+					running[name] = cmd
+				}
+				mu.Unlock()
+
+				if err == nil {
+					logger.Info("👥 Account started", "account", name, "pid", cmd.Process.Pid)
+					err = cmd.Wait()
+				}
+				stdout.Close()
+				stderr.Close()
+
+				mu.Lock()
+				delete(running, name)
+				stop := stopping
+				mu.Unlock()
+				if stop {
+					logger.Info("👥 Account stopped", "account", name)
+					return
+				}
+				logger.Error("❌ Account bot exited, restarting", "account", name, "error", err, "retry_in", accountRestartDelay.String())
+				time.Sleep(accountRestartDelay)
+			}
+		}(name)
+	}
+	logger.Info("👥 Running the accounts", "dir", *dir, "accounts", names)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		mu.Lock()
+		stopping = true
+		for _, cmd := range running {
+			cmd.Process.Signal(sig) // Each bot flushes its state before exiting
+		}
+		mu.Unlock()
+	}()
+	wg.Wait()
+	return nil
+}
+
+// prefixWriter merges the output of the account bots line by line, each line prefixed with
+// the account
+type prefixWriter struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// pipe returns a writer whose lines are copied to the output with the prefix of account
+func (p *prefixWriter) pipe(account string) io.WriteCloser {
+	reader, writer := io.Pipe()
+	go func() {
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			p.mu.Lock()
+			fmt.Fprintf(p.out, "[%s] %s\n", account, scanner.Text())
+			p.mu.Unlock()
+		}
+		io.Copy(io.Discard, reader) // Over-long line: keep the bot from blocking on the pipe
+	}()
+	return writer
+}
+
+// runAccountsReport implements `accounts report`: the status of every account from its state
+// files, with the totals. Offline, like `status`.
+func runAccountsReport(args []string) error {
+	fs := flag.NewFlagSet("accounts report", flag.ExitOnError)
+	dir := fs.String("dir", defaultAccountsDir, "directory with one subdirectory per account")
+	fs.Parse(args)
+
+	names, err := listAccounts(*dir)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "ACCOUNT\tSYMBOLS\tPAUSED\tOPEN BUYS\tPOSITIONS\tINVENTORY COST\tCLOSED\tREALIZED\tEQUITY\t")
+	var total positionSummary
+	var totalEquity float64
+	for _, name := range names {
+		storage := repository.NewStorageAt(filepath.Join(*dir, name))
+		stateRepo := repository.NewStateRepository(storage)
+		if err := stateRepo.Load(); err != nil {
+			return fmt.Errorf("%s: failed to read runtime state: %w", name, err)
+		}
+		transactionRepo := repository.NewTransactionRepository(storage)
+		active, err := transactionRepo.ReadActive()
+		if err != nil {
+			return fmt.Errorf("%s: failed to read transactions: %w", name, err)
+		}
+		history, err := transactionRepo.GetHistory()
+		if err != nil {
+			return fmt.Errorf("%s: failed to read the archive: %w", name, err)
+		}
+		equityRepo := repository.NewEquityRepository(storage)
+		if err := equityRepo.Load(); err != nil {
+			return fmt.Errorf("%s: failed to read the equity baseline: %w", name, err)
+		}
+
+		summary := summarizePositions(active, history, "")
+		paused := "no"
+		if stateRepo.Get().Paused {
+			paused = "yes"
+		}
+		equity := "-"
+		if equityRepo.Initialized() {
+			equity = fmt.Sprintf("%.2f", equityRepo.Get().Equity())
+			totalEquity += equityRepo.Get().Equity()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%.2f\t%d\t%.4f\t%s\t\n", name, gridSymbols(active, history), paused,
+			summary.OpenBuys, summary.Exits+summary.Held, summary.InventoryCost, summary.Closed, summary.Realized, equity)
+
+		total.OpenBuys += summary.OpenBuys
+		total.Exits += summary.Exits + summary.Held
+		total.InventoryCost += summary.InventoryCost
+		total.Closed += summary.Closed
+		total.Realized += summary.Realized
+	}
+	fmt.Fprintf(w, "TOTAL\t\t\t%d\t%d\t%.2f\t%d\t%.4f\t%.2f\t\n", total.OpenBuys, total.Exits, total.InventoryCost,
+		total.Closed, total.Realized, totalEquity)
+	w.Flush()
+	fmt.Println("\nAmounts in the quote asset of each account; equity only for accounts with COMPOUND_PROFITS.")
+	return nil
+}
+
+// gridSymbols lists the symbols traded by the grid buys of an account
+func gridSymbols(active, history []model.Transaction) string {
+	seen := make(map[string]bool)
+	var symbols []string
+	for _, list := range [][]model.Transaction{active, history} {
+		for _, tx := range list {
+			if tx.Type == "buy" && !seen[tx.Symbol] {
+				seen[tx.Symbol] = true
+				symbols = append(symbols, tx.Symbol)
+			}
+		}
+	}
+	if len(symbols) == 0 {
+		return "-"
+	}
+	sort.Strings(symbols)
+	return strings.Join(symbols, ",")
+}
@@ -0,0 +1,434 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"grid-trading-btc-binance/internal/api"
+	"grid-trading-btc-binance/internal/config"
+	"grid-trading-btc-binance/internal/core"
+	"grid-trading-btc-binance/internal/instance"
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/model"
+	"grid-trading-btc-binance/internal/repository"
+)
+
+const legacyIDPrefix = "LEGACY_"
+
+// legacyOrder is an order of the account history with its trades aggregated. Fees paid in
+// the base asset are taken out of Qty and fees in USDT folded into Quote; BNB fees are kept
+// apart, as in the transactions.
+type legacyOrder struct {
+	OrderID  int64
+	ClientID string
+	IsBuy    bool
+	Qty      float64
+	Quote    float64 // Buys: cost, sells: proceeds
+	FeeBNB   float64
+	Time     time.Time // First trade
+	LastTime time.Time
+
+	// Buys after matching against the sells that followed them (FIFO)
+	SoldQty   float64
+	SoldQuote float64
+	SoldAt    time.Time
+	HeldQty   float64
+}
+
+// runImport seeds the state with the positions bought before the bot existed: the account
+// trades before the bot's first transaction are matched FIFO, round trips already closed go to
+// the archive and the lots still held become positions (the bot places their exits).
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	before := fs.String("before", "", "only trades before this day, YYYY-MM-DD (default: the bot's first transaction)")
+	merge := fs.Bool("merge", false, "seed the held lots as a single position at their average cost")
+	yes := fs.Bool("yes", false, "write the transactions (without it, only shows what would be imported)")
+	fs.Parse(args)
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	// Held during the import: the bot rewrites transactions.json while running
+	guard, err := instance.Acquire(cfg.Symbol, cfg.BinanceApiKey)
+	if errors.Is(err, instance.ErrRunning) {
+		return fmt.Errorf("the bot is running: %w. Stop it before importing", err)
+	}
+	if err == nil {
+		defer guard.Release()
+	}
+
+	storage := repository.NewStorage()
+	transactionRepo := repository.NewTransactionRepository(storage)
+	active, err := transactionRepo.ReadActive()
+	if err != nil {
+		return fmt.Errorf("failed to read transactions: %w", err)
+	}
+	history, err := transactionRepo.GetHistory()
+	if err != nil {
+		return fmt.Errorf("failed to read the archive: %w", err)
+	}
+	known := make(map[string]bool)
+	cutoff := time.Now()
+	for _, tx := range append(append([]model.Transaction(nil), active...), history...) {
+		if strings.HasPrefix(tx.ID, legacyIDPrefix) {
+			return fmt.Errorf("legacy positions were already imported (%s)", tx.ID)
+		}
+		known[tx.ID] = true
+		known[tx.TransactionID] = true
+		if tx.SellOrderID != "" {
+			known[tx.SellOrderID] = true
+		}
+		if !strings.HasPrefix(tx.Notes, "Imported from myTrades") && tx.CreatedAt.Before(cutoff) {
+			cutoff = tx.CreatedAt
+		}
+	}
+	if *before != "" {
+		if cutoff, err = time.ParseInLocation(dateLayout, *before, time.Local); err != nil {
+			return fmt.Errorf("invalid -before %q (expected YYYY-MM-DD)", *before)
+		}
+	}
+
+	binance := newBinanceClient(cfg)
+	resolveAssets(cfg, binance)
+	orders, err := fetchLegacyOrders(binance, cfg, cutoff, known)
+	if err != nil {
+		return err
+	}
+	buys, unmatched := matchLegacyOrders(orders)
+
+	// The lots still held cannot exceed the base asset the bot does not track yet (the rest
+	// left the account by withdrawal or transfer): the oldest lots are trimmed first
+	base := cfg.BaseAsset
+	info, err := binance.GetAccountInfo()
+	if err != nil {
+		return fmt.Errorf("failed to fetch account info: %w", err)
+	}
+	untracked := baseBalance(info, base) - trackedQty(storage, active, cfg.Symbol)
+	trimmed := capHeldLots(buys, untracked)
+
+	positions := legacyPositions(buys, cfg.Symbol, *merge)
+	closed := legacyRoundTrips(buys, cfg.Symbol)
+	printLegacyImport(buys, positions, closed, cutoff, untracked, trimmed, unmatched, base)
+
+	if len(positions) == 0 && len(closed) == 0 {
+		fmt.Println("\nNothing to import.")
+		return nil
+	}
+	if !*yes {
+		fmt.Println("\nNothing changed. Stop the bot and rerun with -yes to import.")
+		return nil
+	}
+
+	if err := transactionRepo.Load(); err != nil {
+		return fmt.Errorf("failed to load transactions: %w", err)
+	}
+	for _, tx := range positions {
+		if err := transactionRepo.Save(tx); err != nil {
+			return fmt.Errorf("failed to save %s: %w", tx.ID, err)
+		}
+	}
+	err = transactionRepo.UpdateHistory(func(history []model.Transaction) ([]model.Transaction, bool) {
+		return append(history, closed...), len(closed) > 0
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update the archive: %w", err)
+	}
+	logger.Info("📥 Legacy positions imported", "symbol", cfg.Symbol, "before", cutoff.Format(dateLayout), "positions", len(positions), "round_trips", len(closed))
+	fmt.Printf("\nImported %d positions and %d closed round trips. On the next start the bot places the exits of the positions.\n", len(positions), len(closed))
+	return nil
+}
+
+// fetchLegacyOrders returns the orders of the symbol executed before cutoff, aggregated from
+// the account trades, leaving out the ones the state already has and the bot's own orders
+func fetchLegacyOrders(binance *api.BinanceClient, cfg *config.Config, cutoff time.Time, known map[string]bool) ([]*legacyOrder, error) {
+	byOrder := make(map[int64]*legacyOrder)
+	for fromID := int64(1); ; {
+		page, err := binance.GetMyTrades(cfg.Symbol, fromID, 0, reportTradesPage)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch account trades: %w", err)
+		}
+		for _, t := range page {
+			at := time.UnixMilli(t.Time)
+			if !at.Before(cutoff) {
+				continue
+			}
+			o, ok := byOrder[t.OrderID]
+			if !ok {
+				o = &legacyOrder{OrderID: t.OrderID, IsBuy: t.IsBuyer, Time: at}
+				byOrder[t.OrderID] = o
+			}
+			qty, _ := strconv.ParseFloat(t.Qty, 64)
+			quote, _ := strconv.ParseFloat(t.QuoteQty, 64)
+			commission, _ := strconv.ParseFloat(t.Commission, 64)
+			switch t.CommissionAsset {
+			case cfg.BaseAsset:
+				qty -= commission // Received less than bought
+			case cfg.QuoteAsset:
+				if t.IsBuyer {
+					quote += commission
+				} else {
+					quote -= commission
+				}
+			case feeAsset:
+				o.FeeBNB += commission
+			}
+			o.Qty += qty
+			o.Quote += quote
+			if at.After(o.LastTime) {
+				o.LastTime = at
+			}
+		}
+		if len(page) < reportTradesPage {
+			break
+		}
+		fromID = page[len(page)-1].ID + 1
+	}
+	if len(byOrder) == 0 {
+		return nil, nil
+	}
+
+	// Trades only carry the numeric orderId: resolve the client order IDs
+	first := int64(math.MaxInt64)
+	for id := range byOrder {
+		first = min(first, id)
+	}
+	for fromOrderID := first; ; {
+		page, err := binance.GetAllOrders(cfg.Symbol, fromOrderID, reportTradesPage)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch orders: %w", err)
+		}
+		for _, o := range page {
+			if lo, ok := byOrder[o.OrderId]; ok {
+				lo.ClientID = o.ClientOrderId
+			}
+			fromOrderID = max(fromOrderID, o.OrderId+1)
+		}
+		if len(page) < reportTradesPage {
+			break
+		}
+	}
+
+	orders := make([]*legacyOrder, 0, len(byOrder))
+	for _, o := range byOrder {
+		id := strconv.FormatInt(o.OrderID, 10)
+		if known[id] || (o.ClientID != "" && known[o.ClientID]) || isBotOrder(o.ClientID) || o.Qty <= 0 {
+			continue
+		}
+		orders = append(orders, o)
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].Time.Before(orders[j].Time) })
+	return orders, nil
+}
+
+// isBotOrder reports whether a client order ID was generated by the bot (grid, legacy grid,
+// take-profit, rebalancing, DCA, panic or drawdown stop orders)
+func isBotOrder(clientID string) bool {
+	if _, ok := core.ParseOrderID(clientID); ok {
+		return true
+	}
+	if _, ok := core.OrderLevel(clientID); ok {
+		return true
+	}
+	return strings.HasPrefix(clientID, "SELL_") || strings.HasPrefix(clientID, "PANIC_") ||
+		strings.HasPrefix(clientID, core.DrawdownOrderPrefix) || core.IsRebalanceOrder(clientID) || core.IsDCAOrder(clientID)
+}
+
+// matchLegacyOrders consumes the buys with the sells that followed them (FIFO) and returns
+// the buys with their sold and held parts, and the quantity sold without a known purchase
+func matchLegacyOrders(orders []*legacyOrder) ([]*legacyOrder, float64) {
+	var buys []*legacyOrder
+	unmatched := 0.0
+	for _, o := range orders {
+		if o.IsBuy {
+			o.HeldQty = o.Qty
+			buys = append(buys, o)
+			continue
+		}
+		price := o.Quote / o.Qty
+		remaining := o.Qty
+		for _, b := range buys {
+			if remaining <= 1e-12 {
+				break
+			}
+			take := min(remaining, b.HeldQty)
+			if take <= 0 {
+				continue
+			}
+			b.HeldQty -= take
+			b.SoldQty += take
+			b.SoldQuote += take * price
+			b.SoldAt = o.LastTime
+			remaining -= take
+		}
+		if remaining > 1e-12 {
+			unmatched += remaining
+		}
+	}
+	return buys, unmatched
+}
+
+// capHeldLots trims the held lots, oldest first, down to limit. Returns the quantity removed.
+func capHeldLots(buys []*legacyOrder, limit float64) float64 {
+	held := 0.0
+	for _, b := range buys {
+		held += b.HeldQty
+	}
+	excess := held - math.Max(limit, 0)
+	trimmed := 0.0
+	for _, b := range buys {
+		if excess <= 1e-12 {
+			break
+		}
+		take := min(excess, b.HeldQty)
+		b.HeldQty -= take
+		excess -= take
+		trimmed += take
+	}
+	return trimmed
+}
+
+// legacyPositions builds the active transactions of the held lots: filled buys without an
+// exit, which the startup sync gives a maker exit
+func legacyPositions(buys []*legacyOrder, symbol string, merge bool) []model.Transaction {
+	now := time.Now()
+	var positions []model.Transaction
+	var mergedQty, mergedCost, mergedFee float64
+	var mergedAt time.Time
+	for _, b := range buys {
+		if b.HeldQty <= 1e-8 {
+			continue
+		}
+		share := b.HeldQty / b.Qty
+		if merge {
+			mergedQty += b.HeldQty
+			mergedCost += b.Quote * share
+			mergedFee += b.FeeBNB * share
+			if mergedAt.IsZero() {
+				mergedAt = b.Time
+			}
+			continue
+		}
+		positions = append(positions, model.Transaction{
+			ID:                legacyIDPrefix + strconv.FormatInt(b.OrderID, 10),
+			TransactionID:     strconv.FormatInt(b.OrderID, 10),
+			Symbol:            symbol,
+			Type:              "buy",
+			Amount:            fmt.Sprintf("%.8f", b.HeldQty),
+			Price:             fmt.Sprintf("%.8f", b.Quote/b.Qty),
+			Fee:               fmt.Sprintf("%.8f", b.FeeBNB*share),
+			StatusTransaction: model.StatusFilled,
+			Notes:             "Imported legacy position (bought before the bot)",
+			CreatedAt:         b.Time,
+			UpdatedAt:         now,
+		})
+	}
+	if merge && mergedQty > 1e-8 {
+		positions = append(positions, model.Transaction{
+			ID:                legacyIDPrefix + "MERGED",
+			Symbol:            symbol,
+			Type:              "buy",
+			Amount:            fmt.Sprintf("%.8f", mergedQty),
+			Price:             fmt.Sprintf("%.8f", mergedCost/mergedQty),
+			Fee:               fmt.Sprintf("%.8f", mergedFee),
+			StatusTransaction: model.StatusFilled,
+			Notes:             "Imported legacy position (bought before the bot, lots merged at average cost)",
+			CreatedAt:         mergedAt,
+			UpdatedAt:         now,
+		})
+	}
+	return positions
+}
+
+// legacyRoundTrips builds the archived records of the sold parts, so realized PnL includes them
+func legacyRoundTrips(buys []*legacyOrder, symbol string) []model.Transaction {
+	now := time.Now()
+	var closed []model.Transaction
+	for _, b := range buys {
+		if b.SoldQty <= 1e-8 {
+			continue
+		}
+		soldAt := b.SoldAt
+		closed = append(closed, model.Transaction{
+			ID:                legacyIDPrefix + strconv.FormatInt(b.OrderID, 10) + "_C",
+			TransactionID:     strconv.FormatInt(b.OrderID, 10),
+			Symbol:            symbol,
+			Type:              "buy",
+			Amount:            fmt.Sprintf("%.8f", b.SoldQty),
+			Price:             fmt.Sprintf("%.8f", b.Quote/b.Qty),
+			Fee:               fmt.Sprintf("%.8f", b.FeeBNB*b.SoldQty/b.Qty),
+			StatusTransaction: model.StatusClosed,
+			Notes:             "Imported legacy round trip (before the bot)",
+			ClosedAt:          &soldAt,
+			CreatedAt:         b.Time,
+			UpdatedAt:         now,
+			SellPrice:         b.SoldQuote / b.SoldQty,
+		})
+	}
+	return closed
+}
+
+func baseBalance(info *api.AccountInfoResponse, asset string) float64 {
+	for _, b := range info.Balances {
+		if b.Asset == asset {
+			free, _ := strconv.ParseFloat(b.Free, 64)
+			locked, _ := strconv.ParseFloat(b.Locked, 64)
+			return free + locked
+		}
+	}
+	return 0
+}
+
+// trackedQty is the base asset the state already accounts for: grid positions and DCA lots
+func trackedQty(storage *repository.Storage, active []model.Transaction, symbol string) float64 {
+	qty := 0.0
+	for _, tx := range active {
+		if tx.Symbol != symbol || tx.Type != "buy" {
+			continue
+		}
+		if tx.StatusTransaction == model.StatusFilled || tx.StatusTransaction == model.StatusExitPlaced {
+			amount, _ := strconv.ParseFloat(tx.Amount, 64)
+			qty += amount - tx.QuantitySold
+		}
+	}
+	dcaRepo := repository.NewDCARepository(storage)
+	if err := dcaRepo.Load(); err != nil {
+		logger.Warn("⚠️ Failed to read the DCA stack, its lots are not counted as tracked", "error", err)
+	}
+	return qty + dcaRepo.Get().Qty()
+}
+
+func printLegacyImport(buys []*legacyOrder, positions, closed []model.Transaction, cutoff time.Time, untracked, trimmed, unmatched float64, base string) {
+	fmt.Printf("Trades before %s not known to the bot: %d buys\n\n", cutoff.Format("2006-01-02 15:04"), len(buys))
+	if len(buys) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ORDER\tDATE\tQTY\tPRICE\tSOLD\tSELL PRICE\tHELD")
+		for _, b := range buys {
+			sellPrice := 0.0
+			if b.SoldQty > 0 {
+				sellPrice = b.SoldQuote / b.SoldQty
+			}
+			fmt.Fprintf(w, "%d\t%s\t%.8f\t%.2f\t%.8f\t%.2f\t%.8f\n", b.OrderID, b.Time.Format("2006-01-02"),
+				b.Qty, b.Quote/b.Qty, b.SoldQty, sellPrice, b.HeldQty)
+		}
+		w.Flush()
+		fmt.Println()
+	}
+	fmt.Printf("Untracked %s in the account: %.8f\n", base, math.Max(untracked, 0))
+	if trimmed > 0 {
+		fmt.Printf("Held lots trimmed to the untracked balance (oldest first): %.8f\n", trimmed)
+	}
+	if unmatched > 0 {
+		fmt.Printf("Sold without a known purchase (ignored): %.8f\n", unmatched)
+	}
+	fmt.Printf("Positions to seed: %d, closed round trips to archive: %d\n", len(positions), len(closed))
+}
@@ -3,178 +3,74 @@ package main
 import (
 	"fmt"
 	"log"
-	"strconv"
-	"time"
+	"os"
+	"strings"
 
-	"grid-trading-btc-binance/internal/api"
-	"grid-trading-btc-binance/internal/config"
-	"grid-trading-btc-binance/internal/core"
 	"grid-trading-btc-binance/internal/logger"
-	"grid-trading-btc-binance/internal/market"
-	"grid-trading-btc-binance/internal/model"
-	"grid-trading-btc-binance/internal/repository"
-	"grid-trading-btc-binance/internal/service"
 )
 
-func main() {
-	logger.Init()
-	logger.Info("Starting Grid Trading Strategy (Production Mode)...")
-
-	cfg, err := config.Load()
-	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
-	}
+// command is a CLI subcommand
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
 
-	logger.Info("Configuration loaded successfully",
-		"symbol", cfg.Symbol,
-		"grid_levels", cfg.GridLevels,
-		"range_min", cfg.RangeMin,
-		"range_max", cfg.RangeMax,
-		"taker_fee", cfg.TakerFeePct,
-		"maker_fee", cfg.MakerFeePct,
-		"high_vol_mult", cfg.HighVolMultiplier,
-		"low_vol_mult", cfg.LowVolMultiplier,
-	)
+var commands = []command{
+	{"run", "trade (default when no command is given)", func(args []string) error { runBot(args, false); return nil }},
+	{"tui", "trade with the live status screen instead of console logs", func(args []string) error { runBot(args, true); return nil }},
+	{"accounts", "run one bot per account directory, or report them all (run, report)", runAccounts},
+	{"status", "summary of the local state files (offline)", runStatus},
+	{"orders", "open orders on Binance for the symbol", runOrders},
+	{"cancel", "cancel open orders on Binance (-all or -id)", runCancel},
+	{"export", "dump the transaction archive to CSV or JSON", runExport},
+	{"import", "seed the positions bought before the bot from the account trades", runImport},
+	{"optimize", "grid search of the strategy parameters over klines", runOptimize},
+	{"walkforward", "walk-forward validation of the optimizer", runWalkForward},
+	{"simulate", "expected fills per day and yield of a spacing/levels over recent klines", runSimulate},
+	{"suggest-range", "RANGE_MIN/RANGE_MAX from the recent volatility (-apply to store it)", runSuggestRange},
+	{"report", "capital gains tax report (report tax) or per grid level results (report levels)", runReport},
+	{"secrets", "encrypt the API credentials", runSecrets},
+	{"tv-sign", "signature and webhook URL of a fixed TradingView alert message", runTradingViewSign},
+	{"subaccount", "move funds between the master account and the sub-account (topup or skim)", runSubAccount},
+	{"snapshot", "capture the state files", runSnapshot},
+	{"restore", "restore the state files from a snapshot", runRestore},
+}
 
-	// Initialize Repositories
-	storage := repository.NewStorage()
-	balanceRepo := repository.NewBalanceRepository()
-	transactionRepo := repository.NewTransactionRepository(storage)
+func main() {
+	logger.Init()
 
-	// Initialize Binance API Client
-	binanceClient := api.NewBinanceClient(cfg.BinanceApiKey, cfg.BinanceSecretKey)
-	if err := binanceClient.SyncTime(); err != nil {
-		logger.Warn("⚠️ Failed to synchronize time with Binance, using local time", "error", err)
+	if len(os.Args) < 2 {
+		runBot(nil, false)
+		return
 	}
-
-	// Fetch Initial Balance & Fees
-	accountInfo, err := binanceClient.GetAccountInfo()
-	if err != nil {
-		logger.Error("Failed to fetch initial account info from Binance", "error", err)
-	} else {
-		// Sync Balances
-		syncBalances(balanceRepo, accountInfo)
-
-		// Sync Fees
-		syncFees(cfg, accountInfo)
-		logger.Info("Initial account info synchronized from Binance")
+	name := os.Args[1]
+	if name == "help" || name == "-h" || name == "--help" {
+		usage()
+		return
 	}
-
-	// Start Periodic Balance & Fee Sync (1 minute)
-	go func() {
-		ticker := time.NewTicker(1 * time.Minute)
-		defer ticker.Stop()
-		for range ticker.C {
-			info, err := binanceClient.GetAccountInfo()
-			if err != nil {
-				logger.Error("Failed to sync account info from Binance", "error", err)
-				continue
+	for _, cmd := range commands {
+		if cmd.name == name {
+			if err := cmd.run(os.Args[2:]); err != nil {
+				log.Fatalf("%s: %v", name, err)
 			}
-			syncBalances(balanceRepo, info)
-			syncFees(cfg, info)
-			logger.Info("Account info synchronized from Binance (1m check)")
+			return
 		}
-	}()
-
-	if err := transactionRepo.Load(); err != nil {
-		logger.Error("Failed to load transactions", "error", err)
 	}
-
-	// Services
-	// Services
-	marketDataService := service.NewMarketDataService()
-	volatilityService := market.NewVolatilityService(cfg, binanceClient)
-	dataCollector := service.NewDataCollector(cfg, balanceRepo, transactionRepo, marketDataService, volatilityService)
-	telegramService := service.NewTelegramService(cfg)
-	streamService := service.NewStreamService(binanceClient)
-
-	// Start Volatility Polling
-	volatilityService.StartPolling()
-
-	// Strategy
-	strategy := core.NewStrategy(cfg, balanceRepo, transactionRepo, telegramService, binanceClient, volatilityService)
-
-	// Bot
-	bot := core.NewBot(cfg, balanceRepo, transactionRepo, marketDataService, strategy, dataCollector)
-
-	// Analyze Startup State
-	strategy.AnalyzeStartupState()
-
-	// Sync Orders with Binance (Handle Offline Changes)
-	strategy.SyncOrdersOnStartup()
-
-	// Start Periodic Order Sync (Every 5 min)
-	strategy.StartPeriodicSync()
-
-	// Start WebSocket Stream
-	go func() {
-		// Simple retry loop for stream start
-		for {
-			if err := streamService.Start(); err != nil {
-				logger.Error("❌ Failed to start WebSocket Stream, retrying in 10s...", "error", err)
-				time.Sleep(10 * time.Second)
-				continue
-			}
-			// Blocked inside Start() -> readLoop
-			// If it returns, it disconnected
-			logger.Warn("⚠️ WebSocket Stream disconnected, reconnecting in 5s...")
-			time.Sleep(5 * time.Second)
-		}
-	}()
-
-	// Listen for WebSocket Updates
-	go func() {
-		for update := range streamService.Updates {
-			strategy.HandleOrderUpdate(update)
-		}
-	}()
-
-	bot.Run()
-}
-
-func syncBalances(repo *repository.BalanceRepository, info *api.AccountInfoResponse) {
-	var balances []model.Balance
-	for _, b := range info.Balances {
-		free, _ := strconv.ParseFloat(b.Free, 64)
-		locked, _ := strconv.ParseFloat(b.Locked, 64)
-
-		if free > 0 || locked > 0 {
-			balances = append(balances, model.Balance{
-				Currency: b.Asset,
-				Amount:   free, // Using Free balance for trading availability
-			})
-		}
+	names := make([]string, len(commands))
+	for i, cmd := range commands {
+		names[i] = cmd.name
 	}
-	repo.SetBalances(balances)
+	log.Fatalf("unknown command %q (expected %s, or no command to run the bot)", name, strings.Join(names, ", "))
 }
 
-func syncFees(cfg *config.Config, info *api.AccountInfoResponse) {
-	// Binance fees are in basis points (commission rate * 10000)
-	// Example: 10 => 0.0010 (0.10%)
-	makerFee := float64(info.MakerCommission) / 10000.0
-	takerFee := float64(info.TakerCommission) / 10000.0
-
-	updated := false
-
-	if makerFee != cfg.MakerFeePct {
-		logger.Info("🔄 Maker Fee Updated from API", "old", cfg.MakerFeePct, "new", makerFee)
-		cfg.MakerFeePct = makerFee
-		if err := config.UpdateEnvVariable("MAKER_FEE_PCT", fmt.Sprintf("%.6f", makerFee)); err != nil {
-			logger.Error("Failed to update .env for MAKER_FEE_PCT", "error", err)
-		}
-		updated = true
-	}
-
-	if takerFee != cfg.TakerFeePct {
-		logger.Info("🔄 Taker Fee Updated from API", "old", cfg.TakerFeePct, "new", takerFee)
-		cfg.TakerFeePct = takerFee
-		if err := config.UpdateEnvVariable("TAKER_FEE_PCT", fmt.Sprintf("%.6f", takerFee)); err != nil {
-			logger.Error("Failed to update .env for TAKER_FEE_PCT", "error", err)
-		}
-		updated = true
-	}
-
-	if updated {
-		logger.Info("✅ Fees synchronized with Binance and .env updated")
+func usage() {
+	fmt.Println("Usage: grid-bot [command] [flags]")
+	fmt.Println()
+	fmt.Println("Commands:")
+	for _, cmd := range commands {
+		fmt.Printf("  %-14s %s\n", cmd.name, cmd.usage)
 	}
+	fmt.Println()
+	fmt.Println("Run `grid-bot <command> -h` for the flags of a command.")
 }
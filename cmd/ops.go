@@ -0,0 +1,397 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"grid-trading-btc-binance/internal/api"
+	"grid-trading-btc-binance/internal/config"
+	"grid-trading-btc-binance/internal/instance"
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/model"
+	"grid-trading-btc-binance/internal/repository"
+)
+
+const dateLayout = "2006-01-02"
+
+// runStatus prints a summary of the local state files. Offline: nothing is fetched from
+// Binance and nothing is written, so it is safe while the bot runs.
+func runStatus(args []string) error {
+	fs := flag.NewFlagSet("status", flag.ExitOnError)
+	fs.Parse(args)
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	storage := repository.NewStorage()
+	stateRepo := repository.NewStateRepository(storage)
+	if err := stateRepo.Load(); err != nil {
+		return fmt.Errorf("failed to read runtime state: %w", err)
+	}
+	transactionRepo := repository.NewTransactionRepository(storage)
+	active, err := transactionRepo.ReadActive()
+	if err != nil {
+		return fmt.Errorf("failed to read transactions: %w", err)
+	}
+	history, err := transactionRepo.GetHistory()
+	if err != nil {
+		return fmt.Errorf("failed to read the archive: %w", err)
+	}
+
+	state := stateRepo.Get()
+	running := "no"
+	if guard, err := instance.Acquire(cfg.Symbol, cfg.BinanceApiKey); errors.Is(err, instance.ErrRunning) {
+		running = "yes"
+	} else if err == nil {
+		guard.Release()
+	} else {
+		running = "unknown"
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Symbol\t%s\n", cfg.Symbol)
+	fmt.Fprintf(w, "Running\t%s\n", running)
+	fmt.Fprintf(w, "Mode\t%s\n", cfg.StrategyMode)
+	if state.Profile != "" {
+		fmt.Fprintf(w, "Profile\t%s (runtime)\n", state.Profile)
+	} else if cfg.Profile != "" {
+		fmt.Fprintf(w, "Profile\t%s\n", cfg.Profile)
+	}
+	if state.Paused {
+		fmt.Fprintf(w, "Paused\tyes (%s)\n", state.PausedReason)
+	} else {
+		fmt.Fprintf(w, "Paused\tno\n")
+	}
+	if state.CircuitBreakerAt != nil {
+		fmt.Fprintf(w, "Circuit breaker\ttripped %s\n", state.CircuitBreakerAt.Local().Format("2006-01-02 15:04:05"))
+	}
+	rangeMin, rangeMax := cfg.RangeMin, cfg.RangeMax
+	if state.RangeMin > 0 && state.RangeMax > 0 {
+		rangeMin, rangeMax = state.RangeMin, state.RangeMax
+	}
+	fmt.Fprintf(w, "Range\t%.2f - %.2f\n", rangeMin, rangeMax)
+	w.Flush()
+
+	summary := summarizePositions(active, history, cfg.Symbol)
+
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Open buys\t%d / %d levels\n", summary.OpenBuys, cfg.GridLevels)
+	fmt.Fprintf(w, "Positions with exit\t%d\n", summary.Exits)
+	fmt.Fprintf(w, "Positions without exit\t%d\n", summary.Held)
+	if summary.InventoryQty > 0 {
+		fmt.Fprintf(w, "Inventory\t%.8f (avg cost %.2f)\n", summary.InventoryQty, summary.InventoryCost/summary.InventoryQty)
+	} else {
+		fmt.Fprintf(w, "Inventory\t0\n")
+	}
+	fmt.Fprintf(w, "Closed trades\t%d\n", summary.Closed)
+	fmt.Fprintf(w, "Realized profit (gross)\t%.4f USDT\n", summary.Realized)
+	w.Flush()
+	return nil
+}
+
+// positionSummary counts the grid positions of the local state files
+type positionSummary struct {
+	OpenBuys, Exits, Held, Closed int
+	InventoryQty, InventoryCost   float64
+	Realized                      float64 // Gross, from the closed trades of the archive
+}
+
+// summarizePositions summarizes the active transactions and the archive ("" symbol = every symbol)
+func summarizePositions(active, history []model.Transaction, symbol string) positionSummary {
+	var summary positionSummary
+	for _, tx := range active {
+		if (symbol != "" && tx.Symbol != symbol) || tx.Type != "buy" {
+			continue
+		}
+		qty, _ := strconv.ParseFloat(tx.Amount, 64)
+		price, _ := strconv.ParseFloat(tx.Price, 64)
+		switch tx.StatusTransaction {
+		case model.StatusOpen:
+			summary.OpenBuys++
+			continue
+		case model.StatusExitPlaced:
+			summary.Exits++
+		case model.StatusFilled:
+			summary.Held++
+		default:
+			continue
+		}
+		summary.InventoryQty += qty - tx.QuantitySold
+		summary.InventoryCost += (qty - tx.QuantitySold) * price
+	}
+
+	for _, tx := range history {
+		if (symbol != "" && tx.Symbol != symbol) || tx.StatusTransaction != model.StatusClosed || tx.SellPrice == 0 {
+			continue
+		}
+		qty, _ := strconv.ParseFloat(tx.Amount, 64)
+		price, _ := strconv.ParseFloat(tx.Price, 64)
+		summary.Realized += (tx.SellPrice - price) * qty
+		summary.Closed++
+	}
+	return summary
+}
+
+// runOrders lists the open orders of the symbol on Binance, flagging the ones the local
+// state does not know (placed by hand or lost from the state files)
+func runOrders(args []string) error {
+	fs := flag.NewFlagSet("orders", flag.ExitOnError)
+	fs.Parse(args)
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	binance := newBinanceClient(cfg)
+	orders, err := binance.GetOpenOrders(cfg.Symbol)
+	if err != nil {
+		return fmt.Errorf("failed to fetch open orders: %w", err)
+	}
+	known, err := knownOrderIDs()
+	if err != nil {
+		return err
+	}
+
+	if len(orders) == 0 {
+		fmt.Printf("No open orders for %s.\n", cfg.Symbol)
+		return nil
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		pi, _ := strconv.ParseFloat(orders[i].Price, 64)
+		pj, _ := strconv.ParseFloat(orders[j].Price, 64)
+		return pi > pj
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CLIENT ID\tSIDE\tTYPE\tPRICE\tQTY\tFILLED\tPLACED\tLOCAL")
+	for _, o := range orders {
+		local := "yes"
+		if !known[o.ClientOrderId] {
+			local = "NO"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", o.ClientOrderId, o.Side, o.Type, o.Price, o.OrigQty, o.ExecutedQty,
+			time.UnixMilli(o.TransactTime).Local().Format("2006-01-02 15:04"), local)
+	}
+	w.Flush()
+	fmt.Printf("\n%d open orders.\n", len(orders))
+	return nil
+}
+
+// knownOrderIDs returns the client order IDs tracked by the local state (buys and their exits)
+func knownOrderIDs() (map[string]bool, error) {
+	active, err := repository.NewTransactionRepository(repository.NewStorage()).ReadActive()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transactions: %w", err)
+	}
+	known := make(map[string]bool, 2*len(active))
+	for _, tx := range active {
+		known[tx.ID] = true
+		if tx.SellOrderID != "" {
+			known[tx.SellOrderID] = true
+		}
+	}
+	return known, nil
+}
+
+// runCancel cancels open orders of the symbol on Binance. The bot must be stopped: on the next
+// start the startup sync drops the canceled buys and places the exits of the held positions again.
+func runCancel(args []string) error {
+	fs := flag.NewFlagSet("cancel", flag.ExitOnError)
+	all := fs.Bool("all", false, "cancel every open order of the symbol")
+	id := fs.String("id", "", "cancel only the order with this client order ID")
+	yes := fs.Bool("yes", false, "cancel (without it, only lists what would be canceled)")
+	fs.Parse(args)
+
+	if *all == (*id != "") {
+		return fmt.Errorf("expected exactly one of -all or -id")
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	// Held during the cancel: a running bot would replace the orders right away
+	guard, err := instance.Acquire(cfg.Symbol, cfg.BinanceApiKey)
+	if errors.Is(err, instance.ErrRunning) {
+		return fmt.Errorf("the bot is running: %w. Stop it before canceling (or use /panic on Telegram)", err)
+	}
+	if err == nil {
+		defer guard.Release()
+	}
+
+	binance := newBinanceClient(cfg)
+	orders, err := binance.GetOpenOrders(cfg.Symbol)
+	if err != nil {
+		return fmt.Errorf("failed to fetch open orders: %w", err)
+	}
+	var targets []api.OrderResponse
+	for _, o := range orders {
+		if *all || o.ClientOrderId == *id {
+			targets = append(targets, o)
+		}
+	}
+	if len(targets) == 0 {
+		if *id != "" {
+			return fmt.Errorf("no open order %q for %s", *id, cfg.Symbol)
+		}
+		fmt.Printf("No open orders for %s.\n", cfg.Symbol)
+		return nil
+	}
+
+	for _, o := range targets {
+		fmt.Printf("  %s %s %s @ %s\n", o.ClientOrderId, o.Side, o.OrigQty, o.Price)
+	}
+	if !*yes {
+		fmt.Printf("\nNothing changed. Rerun with -yes to cancel these %d orders.\n", len(targets))
+		return nil
+	}
+
+	canceled := 0
+	for _, o := range targets {
+		if _, err := binance.CancelOrder(cfg.Symbol, o.ClientOrderId); err != nil {
+			logger.Error("❌ Failed to cancel order", "id", o.ClientOrderId, "error", err)
+			continue
+		}
+		canceled++
+	}
+	logger.Info("🧹 Orders canceled from the CLI", "symbol", cfg.Symbol, "canceled", canceled, "failed", len(targets)-canceled)
+	fmt.Printf("\n%d of %d orders canceled. The next start reconciles the local state with Binance.\n", canceled, len(targets))
+	if canceled < len(targets) {
+		return fmt.Errorf("%d orders could not be canceled", len(targets)-canceled)
+	}
+	return nil
+}
+
+// exportRecord is one transaction of the export, with its realized profit
+type exportRecord struct {
+	ID        string     `json:"id"`
+	Symbol    string     `json:"symbol"`
+	Type      string     `json:"type"`
+	Status    string     `json:"status"`
+	Amount    string     `json:"amount"`
+	Price     string     `json:"price"`
+	SellPrice float64    `json:"sellPrice,omitempty"`
+	Fee       string     `json:"fee"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ClosedAt  *time.Time `json:"closedAt,omitempty"`
+	Profit    float64    `json:"profit"` // Gross, closed buys only
+}
+
+// runExport dumps the transaction archive (logs/transactions_history.json) to CSV or JSON
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", "csv", "csv or json")
+	out := fs.String("out", "", "output file (default logs/transactions_export.<format>, - for stdout)")
+	from := fs.String("from", "", "only transactions created on or after this day (YYYY-MM-DD)")
+	to := fs.String("to", "", "only transactions created before the end of this day (YYYY-MM-DD)")
+	fs.Parse(args)
+
+	if *format != "csv" && *format != "json" {
+		return fmt.Errorf("invalid value for -format: %q (expected csv or json)", *format)
+	}
+	var fromTime, toTime time.Time
+	var err error
+	if *from != "" {
+		if fromTime, err = time.ParseInLocation(dateLayout, *from, time.Local); err != nil {
+			return fmt.Errorf("invalid -from %q (expected YYYY-MM-DD)", *from)
+		}
+	}
+	if *to != "" {
+		if toTime, err = time.ParseInLocation(dateLayout, *to, time.Local); err != nil {
+			return fmt.Errorf("invalid -to %q (expected YYYY-MM-DD)", *to)
+		}
+		toTime = toTime.AddDate(0, 0, 1)
+	}
+
+	history, err := repository.NewTransactionRepository(repository.NewStorage()).GetHistory()
+	if err != nil {
+		return fmt.Errorf("failed to read the archive: %w", err)
+	}
+	records := make([]exportRecord, 0, len(history))
+	for _, tx := range history {
+		if (!fromTime.IsZero() && tx.CreatedAt.Before(fromTime)) || (!toTime.IsZero() && !tx.CreatedAt.Before(toTime)) {
+			continue
+		}
+		record := exportRecord{
+			ID:        tx.ID,
+			Symbol:    tx.Symbol,
+			Type:      tx.Type,
+			Status:    tx.StatusTransaction,
+			Amount:    tx.Amount,
+			Price:     tx.Price,
+			SellPrice: tx.SellPrice,
+			Fee:       tx.Fee,
+			CreatedAt: tx.CreatedAt,
+			ClosedAt:  tx.ClosedAt,
+		}
+		if tx.StatusTransaction == model.StatusClosed && tx.SellPrice > 0 {
+			qty, _ := strconv.ParseFloat(tx.Amount, 64)
+			price, _ := strconv.ParseFloat(tx.Price, 64)
+			record.Profit = (tx.SellPrice - price) * qty
+		}
+		records = append(records, record)
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].CreatedAt.Before(records[j].CreatedAt) })
+
+	path := *out
+	if path == "" {
+		path = filepath.Join("logs", "transactions_export."+*format)
+	}
+	var file *os.File
+	if path == "-" {
+		file = os.Stdout
+	} else {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if file, err = os.Create(path); err != nil {
+			return err
+		}
+		defer file.Close()
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(file)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(records)
+	} else {
+		err = writeExportCSV(file, records)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if path != "-" {
+		fmt.Printf("%d transactions exported to %s\n", len(records), path)
+	}
+	return nil
+}
+
+func writeExportCSV(file *os.File, records []exportRecord) error {
+	w := csv.NewWriter(file)
+	w.Write([]string{"id", "symbol", "type", "status", "amount", "price", "sell_price", "fee", "created_at", "closed_at", "profit"})
+	for _, r := range records {
+		closedAt := ""
+		if r.ClosedAt != nil {
+			closedAt = r.ClosedAt.Format(time.RFC3339)
+		}
+		w.Write([]string{
+			r.ID, r.Symbol, r.Type, r.Status, r.Amount, r.Price,
+			strconv.FormatFloat(r.SellPrice, 'f', -1, 64), r.Fee,
+			r.CreatedAt.Format(time.RFC3339), closedAt,
+			strconv.FormatFloat(r.Profit, 'f', 8, 64),
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"grid-trading-btc-binance/internal/api"
+	"grid-trading-btc-binance/internal/backtest"
+	"grid-trading-btc-binance/internal/config"
+	"grid-trading-btc-binance/internal/data"
+	"grid-trading-btc-binance/internal/fees"
+	"grid-trading-btc-binance/internal/logger"
+)
+
+// searchFlags are the flags shared by the backtesting subcommands
+type searchFlags struct {
+	days     *int
+	interval *string
+	levels   *string
+	lowMult  *string
+	highMult *string
+	sizes    *string
+	samples  *int
+	seed     *int64
+	capital  *float64
+	rankBy   *string
+}
+
+func registerSearchFlags(fs *flag.FlagSet, defaultDays int) *searchFlags {
+	return &searchFlags{
+		days:     fs.Int("days", defaultDays, "days of history to backtest"),
+		interval: fs.String("interval", "1m", "kline interval (the live volatility uses 1m)"),
+		levels:   fs.String("levels", "5,10,15,20", "GRID_LEVELS values"),
+		lowMult:  fs.String("low-mult", "1.5,1.8,2.2,2.6", "LOW_VOL_MULTIPLIER values"),
+		highMult: fs.String("high-mult", "3,3.5,4", "HIGH_VOL_MULTIPLIER values"),
+		sizes:    fs.String("size", "0.05,0.1,0.15", "POSITION_SIZE_PCT values"),
+		samples:  fs.Int("samples", 0, "random parameter sets to try (0 = full grid search)"),
+		seed:     fs.Int64("seed", time.Now().UnixNano(), "random sampling seed"),
+		capital:  fs.Float64("capital", 1000, "starting USDT"),
+		rankBy:   fs.String("rank", backtest.RankSharpe, "ranking metric: sharpe, return or drawdown"),
+	}
+}
+
+// candidates returns the parameter sets to evaluate (full grid or random sample)
+func (f *searchFlags) candidates() ([]backtest.Params, error) {
+	if err := backtest.ValidateRank(*f.rankBy); err != nil {
+		return nil, err
+	}
+	space, err := parseSearchSpace(*f.levels, *f.lowMult, *f.highMult, *f.sizes)
+	if err != nil {
+		return nil, err
+	}
+	candidates := space.Combinations()
+	if *f.samples > 0 {
+		candidates = space.Sample(*f.samples, rand.New(rand.NewSource(*f.seed)))
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("empty search space")
+	}
+	return candidates, nil
+}
+
+// settings builds the fixed backtest inputs from .env (range, fees, fallback spacing)
+func (f *searchFlags) settings(cfg *config.Config) backtest.Settings {
+	feeModel := fees.For(cfg)
+	return backtest.Settings{
+		RangeMin:        cfg.RangeMin,
+		RangeMax:        cfg.RangeMax,
+		MakerFeePct:     feeModel.Maker,
+		MinSpacing:      math.Max(feeModel.MinSpacing(), cfg.MinDynamicSpacingPct),
+		MaxSpacing:      cfg.MaxDynamicSpacingPct,
+		MinOrderValue:   cfg.MinOrderValue,
+		FallbackSpacing: cfg.GridSpacingPct,
+		InitialUSDT:     *f.capital,
+		Interval:        *f.interval,
+	}
+}
+
+// loadCandles returns the last -days of SYMBOL candles (from the local klines cache,
+// downloading only what it lacks)
+func (f *searchFlags) loadCandles(cfg *config.Config) ([]backtest.Candle, time.Time, time.Time, error) {
+	binance := api.NewBinanceClient(cfg.BinanceApiKey, cfg.BinanceSecretKey)
+	applyNetwork(cfg, binance)
+	store := data.NewKlineStore(binance, data.DefaultKlinesDir)
+	end := time.Now()
+	start := end.Add(-time.Duration(*f.days) * 24 * time.Hour)
+	klines, err := store.Range(cfg.Symbol, *f.interval, start, end)
+	if err != nil {
+		return nil, start, end, err
+	}
+	return backtest.FromKlines(klines), start, end, nil
+}
+
+// runOptimize implements `optimize`: backtests a grid of parameter sets over historical
+// klines of SYMBOL (range and fees from .env) and prints them ranked.
+func runOptimize(args []string) error {
+	fs := flag.NewFlagSet("optimize", flag.ExitOnError)
+	search := registerSearchFlags(fs, 30)
+	top := fs.Int("top", 20, "rows to print (0 = all)")
+	fs.Parse(args)
+
+	candidates, err := search.candidates()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	candles, start, end, err := search.loadCandles(cfg)
+	if err != nil {
+		return err
+	}
+	logger.Info("🧪 Optimizing grid parameters", "symbol", cfg.Symbol, "candles", len(candles), "candidates", len(candidates), "rank", *search.rankBy)
+
+	results, err := backtest.Optimize(candles, search.settings(cfg), candidates, *search.rankBy, runtime.NumCPU())
+	if err != nil {
+		return err
+	}
+
+	if *top > 0 && *top < len(results) {
+		results = results[:*top]
+	}
+	printResults(results, start, end)
+	return nil
+}
+
+func printResults(results []backtest.Result, start, end time.Time) {
+	fmt.Printf("Backtest %s -> %s\n\n", start.Format("2006-01-02 15:04"), end.Format("2006-01-02 15:04"))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "#\tLEVELS\tLOW_MULT\tHIGH_MULT\tSIZE_PCT\tSHARPE\tRETURN_%\tMAX_DD_%\tTRADES\tPROFIT_USDT\t")
+	for i, r := range results {
+		fmt.Fprintf(w, "%d\t%d\t%.2f\t%.2f\t%.4f\t%.2f\t%.2f\t%.2f\t%d\t%.2f\t\n",
+			i+1, r.Params.GridLevels, r.Params.LowVolMultiplier, r.Params.HighVolMultiplier, r.Params.PositionSizePct,
+			r.Sharpe, r.TotalReturnPct, r.MaxDrawdownPct, r.Trades, r.RealizedProfit)
+	}
+	w.Flush()
+}
+
+func parseSearchSpace(levels, lowMult, highMult, sizes string) (backtest.SearchSpace, error) {
+	var space backtest.SearchSpace
+	var err error
+	if space.GridLevels, err = parseIntList(levels, "levels"); err != nil {
+		return space, err
+	}
+	if space.LowVolMultipliers, err = parseFloatList(lowMult, "low-mult"); err != nil {
+		return space, err
+	}
+	if space.HighVolMultipliers, err = parseFloatList(highMult, "high-mult"); err != nil {
+		return space, err
+	}
+	if space.PositionSizePcts, err = parseFloatList(sizes, "size"); err != nil {
+		return space, err
+	}
+	return space, nil
+}
+
+func parseIntList(value, name string) ([]int, error) {
+	var out []int
+	for _, part := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for -%s: %w", name, err)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+func parseFloatList(value, name string) ([]float64, error) {
+	var out []float64
+	for _, part := range strings.Split(value, ",") {
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for -%s: %w", name, err)
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
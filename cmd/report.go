@@ -0,0 +1,382 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"grid-trading-btc-binance/internal/api"
+	"grid-trading-btc-binance/internal/config"
+	"grid-trading-btc-binance/internal/data"
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/model"
+	"grid-trading-btc-binance/internal/repository"
+	"grid-trading-btc-binance/internal/service"
+	"grid-trading-btc-binance/internal/tax"
+)
+
+const (
+	reportTradesPage = 1000
+	feeAsset         = "BNB"
+)
+
+// runReport dispatches the `report` subcommands
+func runReport(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing report type (expected tax or levels)")
+	}
+	switch args[0] {
+	case "tax":
+		return runTaxReport(args[1:])
+	case "levels":
+		return runLevelsReport(args[1:])
+	}
+	return fmt.Errorf("unknown report %q (expected tax or levels)", args[0])
+}
+
+// runLevelsReport implements `report levels`: the round trips of the last -days days and the
+// positions held now, grouped by the grid level of their buy, to show whether the deep levels
+// ever pay off. Prices come from the book ticker; without them the fees and the unrealized
+// result are left at 0.
+func runLevelsReport(args []string) error {
+	fs := flag.NewFlagSet("report levels", flag.ExitOnError)
+	days := fs.Int("days", 30, "days of closed round trips")
+	fs.Parse(args)
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	transactionRepo := repository.NewTransactionRepository(repository.NewStorage())
+	active, err := transactionRepo.ReadActive()
+	if err != nil {
+		return fmt.Errorf("failed to read transactions: %w", err)
+	}
+	now := time.Now()
+	closed := transactionRepo.GetClosedTransactionsAfter(now.AddDate(0, 0, -*days))
+
+	binance := newBinanceClient(cfg)
+	resolveAssets(cfg, binance)
+	price, bnbPrice := 0.0, 0.0
+	if ticker, err := binance.GetBookTicker(cfg.Symbol); err == nil {
+		price, _ = strconv.ParseFloat(ticker.BidPrice, 64)
+	} else {
+		logger.Warn("⚠️ Failed to fetch the price, unrealized results left at 0", "error", err)
+	}
+	if ticker, err := binance.GetBookTicker("BNB" + cfg.QuoteAsset); err == nil {
+		bnbPrice, _ = strconv.ParseFloat(ticker.BidPrice, 64)
+	} else {
+		logger.Warn("⚠️ Failed to fetch the BNB price, fees left out", "error", err)
+	}
+
+	var symbolClosed, symbolActive []model.Transaction
+	for _, tx := range closed {
+		if tx.Symbol == cfg.Symbol {
+			symbolClosed = append(symbolClosed, tx)
+		}
+	}
+	for _, tx := range active {
+		if tx.Symbol == cfg.Symbol {
+			symbolActive = append(symbolActive, tx)
+		}
+	}
+	levels := service.ComputeLevelStats(symbolClosed, symbolActive, price, bnbPrice, now, *days)
+	if len(levels) == 0 {
+		fmt.Printf("No grid round trips or positions in the last %d days.\n", *days)
+		return nil
+	}
+
+	fmt.Printf("%s, round trips of the last %d days and positions held now (GRID_LEVELS=%d)\n\n", cfg.Symbol, *days, cfg.GridLevels)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "LEVEL\tTRIPS\tWINS\tHELD\tHIT_RATE_%\tAVG_HOLD_MIN\tNET_PROFIT\tPROFIT/TRIP\tUNREALIZED\t")
+	var total service.LevelStats
+	for _, l := range levels {
+		perTrip := 0.0
+		if l.RoundTrips > 0 {
+			perTrip = l.NetProfit / float64(l.RoundTrips)
+		}
+		fmt.Fprintf(w, "L%d\t%d\t%d\t%d\t%.1f\t%.0f\t%.2f\t%.4f\t%.2f\t\n",
+			l.Level, l.RoundTrips, l.Wins, l.Held, l.HitRate*100, l.AvgHoldMin, l.NetProfit, perTrip, l.Unrealized)
+		total.RoundTrips += l.RoundTrips
+		total.Wins += l.Wins
+		total.Held += l.Held
+		total.NetProfit += l.NetProfit
+		total.Unrealized += l.Unrealized
+	}
+	fmt.Fprintf(w, "ALL\t%d\t%d\t%d\t\t\t%.2f\t\t%.2f\t\n", total.RoundTrips, total.Wins, total.Held, total.NetProfit, total.Unrealized)
+	w.Flush()
+	return nil
+}
+
+// runTaxReport implements `report tax`: matches every sale of SYMBOL against its purchases
+// (FIFO, LIFO or average cost) and writes one CSV row per sale with its capital gain.
+// Amounts are in the quote asset; fees paid in BNB are valued at the BNB/quote daily
+// close. The whole history is always matched, -year only filters the output.
+func runTaxReport(args []string) error {
+	fs := flag.NewFlagSet("report tax", flag.ExitOnError)
+	method := fs.String("method", tax.MethodFIFO, "lot matching: fifo, lifo or average")
+	year := fs.Int("year", 0, "fiscal year to export (0 = every year)")
+	source := fs.String("source", "trades", "trades (Binance myTrades, authoritative) or archive (logs/transactions_history.json, offline)")
+	tz := fs.String("tz", "America/Sao_Paulo", "timezone that defines the fiscal year")
+	out := fs.String("out", "", "output CSV (default logs/tax_report_<symbol>_<year|all>_<method>.csv)")
+	fs.Parse(args)
+
+	if err := tax.ValidateMethod(*method); err != nil {
+		return err
+	}
+	loc, err := time.LoadLocation(*tz)
+	if err != nil {
+		return fmt.Errorf("invalid timezone %q: %w", *tz, err)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	binance := newBinanceClient(cfg)
+	resolveAssets(cfg, binance)
+	store := data.NewKlineStore(binance, data.DefaultKlinesDir)
+
+	var fills []tax.Fill
+	switch *source {
+	case "trades":
+		fills, err = fillsFromTrades(binance, store, cfg)
+	case "archive":
+		fills, err = fillsFromArchive(store, cfg)
+	default:
+		return fmt.Errorf("invalid value for -source: %q (expected trades or archive)", *source)
+	}
+	if err != nil {
+		return err
+	}
+	logger.Info("🧾 Tax report", "symbol", cfg.Symbol, "source", *source, "fills", len(fills), "method", *method)
+
+	disposals, heldQty, heldCost, err := tax.Match(fills, *method)
+	if err != nil {
+		return err
+	}
+	if *year != 0 {
+		var filtered []tax.Disposal
+		for _, d := range disposals {
+			if d.Time.In(loc).Year() == *year {
+				filtered = append(filtered, d)
+			}
+		}
+		disposals = filtered
+	}
+
+	path := *out
+	if path == "" {
+		label := "all"
+		if *year != 0 {
+			label = strconv.Itoa(*year)
+		}
+		path = fmt.Sprintf("logs/tax_report_%s_%s_%s.csv", cfg.Symbol, label, *method)
+	}
+	if err := writeTaxCSV(path, disposals, loc); err != nil {
+		return err
+	}
+
+	printTaxSummary(tax.Summarize(disposals, loc), heldQty, heldCost, path)
+	return nil
+}
+
+// fillsFromTrades pages through the whole myTrades history of the symbol
+func fillsFromTrades(binance *api.BinanceClient, store *data.KlineStore, cfg *config.Config) ([]tax.Fill, error) {
+	var trades []api.AccountTrade
+	for fromID := int64(1); ; {
+		page, err := binance.GetMyTrades(cfg.Symbol, fromID, 0, reportTradesPage)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch account trades: %w", err)
+		}
+		trades = append(trades, page...)
+		if len(page) < reportTradesPage {
+			break
+		}
+		fromID = page[len(page)-1].ID + 1
+	}
+	if len(trades) == 0 {
+		return nil, nil
+	}
+
+	bnbTimes := make([]time.Time, 0, len(trades))
+	for _, t := range trades {
+		if t.CommissionAsset == feeAsset {
+			bnbTimes = append(bnbTimes, time.UnixMilli(t.Time))
+		}
+	}
+	bnbPrice, err := bnbDailyPrices(store, cfg.QuoteAsset, bnbTimes)
+	if err != nil {
+		return nil, err
+	}
+
+	fills := make([]tax.Fill, 0, len(trades))
+	for _, t := range trades {
+		qty, _ := strconv.ParseFloat(t.Qty, 64)
+		price, _ := strconv.ParseFloat(t.Price, 64)
+		commission, _ := strconv.ParseFloat(t.Commission, 64)
+		at := time.UnixMilli(t.Time)
+
+		f := tax.Fill{Time: at, OrderID: strconv.FormatInt(t.OrderID, 10), IsBuy: t.IsBuyer, Qty: qty, Price: price}
+		switch t.CommissionAsset {
+		case cfg.QuoteAsset:
+			f.Fee = commission
+		case cfg.BaseAsset:
+			if t.IsBuyer {
+				f.Qty -= commission // Received less than bought
+			} else {
+				f.Fee = commission * price
+			}
+		case feeAsset:
+			f.Fee = commission * bnbPrice(at)
+		default:
+			logger.Warn("⚠️ Unsupported commission asset, fee ignored", "asset", t.CommissionAsset, "trade", t.ID)
+		}
+		fills = append(fills, f)
+	}
+	return fills, nil
+}
+
+// fillsFromArchive rebuilds the fills from the closed archived transactions: each closed
+// buy with an exit gives a purchase and a sale (the archived BNB fee covers both legs and is
+// split evenly), imported standalone buys/sells give a single fill.
+func fillsFromArchive(store *data.KlineStore, cfg *config.Config) ([]tax.Fill, error) {
+	history := repository.NewTransactionRepository(repository.NewStorage()).GetClosedTransactionsAfter(time.Time{})
+
+	var txs []model.Transaction
+	var bnbTimes []time.Time
+	for _, tx := range history {
+		if tx.Symbol != cfg.Symbol || (tx.Type != "buy" && tx.Type != "sell") {
+			continue
+		}
+		txs = append(txs, tx)
+		bnbTimes = append(bnbTimes, tx.CreatedAt)
+	}
+	if len(txs) == 0 {
+		return nil, nil
+	}
+	bnbPrice, err := bnbDailyPrices(store, cfg.QuoteAsset, bnbTimes)
+	if err != nil {
+		return nil, err
+	}
+
+	var fills []tax.Fill
+	for _, tx := range txs {
+		qty, _ := strconv.ParseFloat(tx.Amount, 64)
+		price, _ := strconv.ParseFloat(tx.Price, 64)
+		fee, _ := strconv.ParseFloat(tx.Fee, 64)
+		feeQuote := fee * bnbPrice(tx.CreatedAt)
+
+		if tx.Type == "sell" {
+			at := tx.CreatedAt
+			if tx.ClosedAt != nil {
+				at = *tx.ClosedAt
+			}
+			fills = append(fills, tax.Fill{Time: at, OrderID: tx.ID, Qty: qty, Price: price, Fee: feeQuote})
+			continue
+		}
+
+		exited := tx.SellOrderID != "" && tx.SellPrice > 0 && tx.ClosedAt != nil
+		if !exited {
+			fills = append(fills, tax.Fill{Time: tx.CreatedAt, OrderID: tx.ID, IsBuy: true, Qty: qty, Price: price, Fee: feeQuote})
+			continue
+		}
+		fills = append(fills,
+			tax.Fill{Time: tx.CreatedAt, OrderID: tx.ID, IsBuy: true, Qty: qty, Price: price, Fee: feeQuote / 2},
+			tax.Fill{Time: *tx.ClosedAt, OrderID: tx.SellOrderID, Qty: qty, Price: tx.SellPrice, Fee: feeQuote / 2},
+		)
+	}
+	return fills, nil
+}
+
+// bnbDailyPrices loads the BNB/quote daily closes covering the given times and returns a lookup
+// that falls back to the nearest earlier close (today's candle is still open)
+func bnbDailyPrices(store *data.KlineStore, quote string, times []time.Time) (func(time.Time) float64, error) {
+	if len(times) == 0 {
+		return func(time.Time) float64 { return 0 }, nil
+	}
+	first, last := times[0], times[0]
+	for _, t := range times {
+		if t.Before(first) {
+			first = t
+		}
+		if t.After(last) {
+			last = t
+		}
+	}
+
+	day := 24 * time.Hour
+	pair := "BNB" + quote
+	klines, err := store.Range(pair, "1d", first.UTC().Truncate(day).Add(-day), last.Add(day))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load BNB prices for fee valuation: %w", err)
+	}
+	if len(klines) == 0 {
+		return nil, fmt.Errorf("no %s daily candles to value the fees", pair)
+	}
+	closes := make([]float64, len(klines))
+	for i, k := range klines {
+		closes[i], _ = strconv.ParseFloat(k.Close, 64)
+	}
+
+	return func(t time.Time) float64 {
+		ms := t.UnixMilli()
+		i := sort.Search(len(klines), func(i int) bool { return klines[i].OpenTime > ms }) - 1
+		if i < 0 {
+			i = 0
+		}
+		return closes[i]
+	}, nil
+}
+
+func writeTaxCSV(path string, disposals []tax.Disposal, loc *time.Location) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create tax report dir: %w", err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create tax report: %w", err)
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	w.Write([]string{"fiscal_year", "sell_time", "sell_order_id", "qty", "proceeds_usdt", "cost_basis_usdt", "gain_usdt", "sell_fee_usdt", "holding_days", "unmatched_qty"})
+	for _, d := range disposals {
+		at := d.Time.In(loc)
+		w.Write([]string{
+			strconv.Itoa(at.Year()),
+			at.Format(time.RFC3339),
+			d.OrderID,
+			fmt.Sprintf("%.8f", d.Qty),
+			fmt.Sprintf("%.4f", d.Proceeds),
+			fmt.Sprintf("%.4f", d.CostBasis),
+			fmt.Sprintf("%.4f", d.Gain()),
+			fmt.Sprintf("%.4f", d.Fee),
+			fmt.Sprintf("%.2f", d.HoldingDays),
+			fmt.Sprintf("%.8f", d.UnmatchedQty),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("failed to write tax report: %w", err)
+	}
+	return nil
+}
+
+func printTaxSummary(years []tax.YearSummary, heldQty, heldCost float64, path string) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "YEAR\tSALES\tPROCEEDS\tCOST_BASIS\tGAINS\tLOSSES\tNET\tFEES\t")
+	for _, y := range years {
+		fmt.Fprintf(w, "%d\t%d\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\t\n",
+			y.Year, y.Disposals, y.Proceeds, y.CostBasis, y.Gains, y.Losses, y.Net(), y.Fees)
+	}
+	w.Flush()
+
+	fmt.Printf("\nStill held: %.8f (cost basis %.2f USDT)\n", heldQty, heldCost)
+	fmt.Printf("Report written to %s\n", path)
+}
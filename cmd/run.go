@@ -0,0 +1,726 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"grid-trading-btc-binance/internal/api"
+	"grid-trading-btc-binance/internal/audit"
+	"grid-trading-btc-binance/internal/backup"
+	"grid-trading-btc-binance/internal/config"
+	"grid-trading-btc-binance/internal/core"
+	"grid-trading-btc-binance/internal/crash"
+	"grid-trading-btc-binance/internal/data"
+	"grid-trading-btc-binance/internal/fees"
+	"grid-trading-btc-binance/internal/health"
+	"grid-trading-btc-binance/internal/instance"
+	"grid-trading-btc-binance/internal/leader"
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/market"
+	"grid-trading-btc-binance/internal/model"
+	"grid-trading-btc-binance/internal/repository"
+	"grid-trading-btc-binance/internal/service"
+	"grid-trading-btc-binance/internal/shadow"
+	"grid-trading-btc-binance/internal/tracing"
+	"grid-trading-btc-binance/internal/tradingview"
+	"grid-trading-btc-binance/internal/tui"
+)
+
+// runBot is the trading process: startup sync, then the strategy until a shutdown signal.
+// With tuiMode the status screen replaces the console logs.
+func runBot(args []string, tuiMode bool) {
+	name := "run"
+	if tuiMode {
+		name = "tui"
+	}
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	confirmCleanup := fs.Bool("confirm-cleanup", false, "run the startup cleanup held by SYNC_DRY_RUN without waiting for /cleanup confirm")
+	fs.Parse(args)
+
+	logger.Info("Starting Grid Trading Strategy (Production Mode)...")
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	if *confirmCleanup && cfg.SyncDryRun {
+		logger.Warn("🧹 -confirm-cleanup: SYNC_DRY_RUN ignored, the startup cleanup runs on this boot")
+		cfg.SyncDryRun = false
+	}
+	if tuiMode {
+		cfg.LogOutput = logger.OutputFile // The screen is the console
+		fmt.Printf("Starting %s, the status screen appears after the startup sync (logs in %s)...\n", cfg.Symbol, cfg.LogFile)
+	}
+	if err := logger.Configure(logger.Options{
+		Level:        cfg.LogLevel,
+		Output:       cfg.LogOutput,
+		File:         cfg.LogFile,
+		ErrorFile:    cfg.LogErrorFile,
+		ModuleLevels: cfg.LogModuleLevels,
+	}); err != nil {
+		log.Fatalf("Failed to configure logging: %v", err)
+	}
+	if err := crash.InitSentry(cfg.SentryDSN, cfg.SentryEnvironment); err != nil {
+		logger.Error("Crash reporting disabled", "error", err)
+	}
+	if cfg.AuditEnabled {
+		if err := audit.Init(cfg.AuditDir); err != nil {
+			logger.Error("Trade audit log disabled", "error", err)
+		}
+	}
+	var shutdownTracing func(context.Context) error
+	if cfg.TracingEndpoint != "" {
+		shutdown, err := tracing.Init(cfg.TracingEndpoint, cfg.TracingSampleRatio, cfg.Symbol)
+		if err != nil {
+			logger.Error("Tracing disabled", "error", err)
+		} else {
+			logger.Info("🔭 OpenTelemetry tracing enabled", "endpoint", cfg.TracingEndpoint, "sample_ratio", cfg.TracingSampleRatio)
+			shutdownTracing = shutdown
+		}
+	}
+
+	logger.Info("Configuration loaded successfully",
+		"symbol", cfg.Symbol,
+		"mode", cfg.StrategyMode,
+		"grid_levels", cfg.GridLevels,
+		"range_min", cfg.RangeMin,
+		"range_max", cfg.RangeMax,
+		"taker_fee", cfg.TakerFeePct,
+		"maker_fee", cfg.MakerFeePct,
+		"high_vol_mult", cfg.HighVolMultiplier,
+		"low_vol_mult", cfg.LowVolMultiplier,
+	)
+
+	if guard := acquireInstanceGuard(cfg); guard != nil {
+		defer guard.Release()
+	}
+
+	// Hot standby: wait for the leader lease before touching the state or placing orders
+	elector := newElector(cfg)
+	if elector != nil {
+		elector.Acquire()
+		elector.Start()
+	}
+
+	// Initialize Repositories
+	storage := repository.NewStorage()
+	balanceRepo := repository.NewBalanceRepository()
+	transactionRepo := repository.NewTransactionRepository(storage)
+	equityRepo := repository.NewEquityRepository(storage)
+	vaultRepo := repository.NewVaultRepository(storage)
+	stateRepo := repository.NewStateRepository(storage)
+	seenEventsRepo := repository.NewSeenEventRepository(storage)
+	dcaRepo := repository.NewDCARepository(storage)
+
+	// Runtime state first: its synced fees and /range override take precedence over .env
+	if err := stateRepo.Load(); err != nil {
+		logger.Error("Failed to load runtime state", "error", err)
+	}
+	applyRuntimeState(cfg, stateRepo.Get())
+
+	// Initialize Binance API Client
+	binanceClient := newBinanceClient(cfg)
+	if cfg.MonitorOnly {
+		binanceClient.ReadOnly = true
+		logger.Warn("👁️ MONITOR_ONLY is enabled: market data and account reads only, no order will be placed, canceled or transferred")
+	}
+	if cfg.SubAccountEmail != "" {
+		logger.Info("👤 Running on a sub-account", "email", cfg.SubAccountEmail)
+	}
+	if proxy := binanceProxy(cfg); proxy != nil || cfg.BinanceBaseURL != "" || cfg.BinanceCluster != "" || cfg.BinanceStreamURL != "" {
+		proxyText := ""
+		if proxy != nil {
+			proxyText = proxy.Redacted()
+		}
+		logger.Info("🌐 Custom Binance routing", "rest", binanceClient.BaseURL, "stream", cfg.BinanceStreamURL, "proxy", proxyText)
+	}
+	if binanceClient.Margin() {
+		logger.Info("🏦 Trading on the margin account", "account", cfg.AccountType, "auto_borrow", cfg.MarginAutoBorrow)
+	}
+	if err := binanceClient.SyncTime(); err != nil {
+		logger.Warn("⚠️ Failed to synchronize time with Binance, using local time", "error", err)
+	}
+	resolveAssets(cfg, binanceClient)
+
+	// Fetch Initial Balance & Fees
+	accountInfo, err := binanceClient.GetAccountInfo()
+	if err != nil {
+		logger.Error("Failed to fetch initial account info from Binance", "error", err)
+	} else {
+		// Sync Balances
+		syncBalances(balanceRepo, accountInfo)
+
+		// Sync Fees
+		syncFees(cfg, stateRepo, accountInfo)
+		logger.Info("Initial account info synchronized from Binance")
+	}
+	feeModel := fees.For(cfg)
+	logger.Info("💸 Fee model", "model", feeModel.Kind, "maker", feeModel.Maker, "taker", feeModel.Taker, "min_spacing", feeModel.MinSpacing())
+
+	// Start Periodic Balance & Fee Sync (1 minute)
+	crash.Go("balance sync", func() {
+		ticker := time.NewTicker(1 * time.Minute)
+		defer ticker.Stop()
+		for range ticker.C {
+			if binanceClient.RateLimited() {
+				continue // 429/418 protection: balances follow the user stream meanwhile
+			}
+			info, err := binanceClient.GetAccountInfo()
+			if err != nil {
+				logger.Error("Failed to sync account info from Binance", "error", err)
+				continue
+			}
+			syncBalances(balanceRepo, info)
+			syncFees(cfg, stateRepo, info)
+			logger.Info("Account info synchronized from Binance (1m check)")
+		}
+	})
+
+	if err := transactionRepo.Load(); err != nil {
+		logger.Error("Failed to load transactions", "error", err)
+	}
+	if cfg.TxFlushIntervalMs > 0 {
+		transactionRepo.StartFlusher(time.Duration(cfg.TxFlushIntervalMs) * time.Millisecond)
+	}
+	exit := flushOnExit(transactionRepo, shutdownTracing, elector)
+	if elector != nil {
+		go func() {
+			<-elector.Lost()
+			logger.Error("🚨 Leadership lost, exiting so that only the new leader trades")
+			exit(1)
+		}()
+	}
+	if err := equityRepo.Load(); err != nil {
+		logger.Error("Failed to load equity baseline", "error", err)
+	}
+	if err := vaultRepo.Load(); err != nil {
+		logger.Error("Failed to load vault", "error", err)
+	}
+	if err := seenEventsRepo.Load(); err != nil {
+		logger.Error("Failed to load seen WebSocket events", "error", err)
+	}
+	if err := dcaRepo.Load(); err != nil {
+		logger.Error("Failed to load DCA stack", "error", err)
+	}
+
+	// Services
+	// Services
+	marketDataService := service.NewMarketDataService()
+	klineStore := data.NewKlineStore(binanceClient, data.DefaultKlinesDir)
+	volatilityService := market.NewVolatilityService(cfg, binanceClient, klineStore)
+	volatilityService.State = stateRepo
+	volatilityService.History = repository.NewRegimeHistory(repository.RegimeHistoryFile)
+	dataCollector := service.NewDataCollector(cfg, balanceRepo, transactionRepo, marketDataService, volatilityService)
+	dataCollector.StartWriter()
+	telegramService := service.NewTelegramService(cfg)
+	notifier := service.NewNotificationService(cfg, telegramService)
+	streamService := service.NewStreamService(binanceClient)
+	if cfg.BinanceStreamURL != "" {
+		streamService.BaseURL = cfg.BinanceStreamURL
+	}
+	if proxy := binanceProxy(cfg); proxy != nil {
+		streamService.SetProxy(proxy)
+	}
+
+	// Recovered panics are alerted (the goroutine keeps running or is restarted)
+	crash.SetAlert(func(name, message string, suppressed int) {
+		notifier.NotifyTemplate(service.CategoryError, service.SeverityCritical, service.TemplateGoroutinePanic,
+			service.GoroutinePanicMessageData{Goroutine: name, Error: message, Suppressed: suppressed})
+	})
+	// 429/418: the client pauses the REST calls by itself, the operator is told
+	binanceClient.OnRateLimit = func(status api.RateLimitStatus) {
+		data := service.RateLimitMessageData{Lifted: !status.Active, Ban: status.Ban(), Path: status.Path,
+			Since: status.Since.Format("02/01 15:04:05"), Until: status.Until.Format("02/01 15:04:05"),
+			ProtectedUntil: status.ProtectedUntil.Format("02/01 15:04:05")}
+		severity := service.SeverityWarning
+		switch {
+		case data.Lifted:
+			severity = service.SeverityInfo
+		case data.Ban:
+			severity = service.SeverityCritical
+		}
+		notifier.NotifyTemplate(service.CategoryError, severity, service.TemplateRateLimited, data)
+	}
+	if elector != nil && elector.TookOver() {
+		notifier.NotifyTemplate(service.CategorySync, service.SeverityWarning, service.TemplateLeaderTakeover,
+			service.LeaderTakeoverMessageData{ID: elector.ID, Lock: elector.Lock.Name()})
+	}
+
+	// REST endpoint failover: probes the alternate clusters, moves away from a degraded primary
+	var failover *api.Failover
+	if cfg.EndpointFailover {
+		failover = api.NewFailover(binanceClient, time.Duration(cfg.EndpointProbeIntervalSec)*time.Second,
+			time.Duration(cfg.EndpointMaxLatencyMs)*time.Millisecond, cfg.EndpointMaxErrorRate)
+		failover.OnSwitch = func(from, to, reason string) {
+			data := service.EndpointMessageData{From: from, To: to, Reason: reason, FailBack: to == failover.Primary}
+			severity := service.SeverityWarning
+			if data.FailBack {
+				severity = service.SeverityInfo
+			}
+			notifier.NotifyTemplate(service.CategoryError, severity, service.TemplateEndpointSwitched, data)
+		}
+		failover.Start()
+	}
+
+	// Clock drift guard: resyncs the time when the responses show the local clock drifted
+	if cfg.ClockDriftAlertMs > 0 {
+		maxDrift := time.Duration(cfg.ClockDriftAlertMs) * time.Millisecond
+		guard := &api.ClockGuard{Client: binanceClient, MaxDrift: maxDrift}
+		guard.OnDrift = func(status api.ClockStatus, err error) {
+			data := service.ClockDriftMessageData{Drift: status.Drift.String(), Max: maxDrift.String(),
+				OldOffsetMs: status.Offset, NewOffsetMs: binanceClient.ClockStatus().Offset}
+			severity := service.SeverityWarning
+			if err != nil {
+				data.Error, severity = err.Error(), service.SeverityCritical
+			}
+			notifier.NotifyTemplate(service.CategoryError, severity, service.TemplateClockDrift, data)
+		}
+		guard.Start()
+	}
+
+	// Start Volatility Polling
+	volatilityService.StartPolling()
+
+	// Strategy
+	strategy := core.NewStrategy(cfg, balanceRepo, transactionRepo, equityRepo, vaultRepo, stateRepo, seenEventsRepo, dcaRepo, notifier, binanceClient, volatilityService)
+	if cfg.PlacementMode == "support" {
+		strategy.Supports = market.NewSupportLevels(cfg, klineStore)
+		strategy.Supports.Start()
+	}
+	strategy.Ledger = service.NewTradeLedger(marketDataService, cfg.QuoteAsset, cfg.CollectorJSONOutput)
+	strategy.Ledger.Start()
+	strategy.Executions = service.NewExecutionLog(cfg.CollectorJSONOutput)
+	strategy.Executions.Start()
+	dataCollector.Executions = strategy.Executions
+	if cfg.HedgeEnabled {
+		strategy.Futures = api.NewFuturesClient(cfg.BinanceApiKey, cfg.BinanceSecretKey)
+		strategy.Futures.ReadOnly = cfg.MonitorOnly
+		strategy.Futures.SetTransport(restTransport(cfg))
+		strategy.Futures.SetTimeBias(time.Duration(cfg.TimeBiasMs) * time.Millisecond)
+		if err := strategy.Futures.SyncTime(); err != nil {
+			logger.Warn("⚠️ Failed to synchronize time with Binance Futures, using local time", "error", err)
+		}
+		dataCollector.Hedge = strategy.HedgeStats
+	}
+	if cfg.EarnEnabled {
+		dataCollector.Earn = strategy.EarnStats
+	}
+	strategy.Macro = market.NewMacroCalendar(cfg)
+	if strategy.Macro != nil {
+		strategy.Macro.Start()
+		dataCollector.MacroPause = strategy.MacroPauseReason
+	}
+	if cfg.BookImbalanceFilter {
+		strategy.BookImbalance = service.NewBookImbalanceLog(cfg.CollectorJSONOutput)
+		strategy.BookImbalance.Start()
+	}
+
+	// Optional time-series sink: hourly records + closed trades
+	if metricsSink := service.NewMetricsSink(cfg); metricsSink != nil {
+		dataCollector.Sink = metricsSink
+		strategy.Sink = metricsSink
+	}
+
+	// Optional off-host backup of the state and CSV logs
+	if target := newBackupTarget(cfg); target != nil {
+		uploader := &backup.Uploader{
+			Target:    target,
+			Symbol:    cfg.Symbol,
+			Interval:  time.Duration(cfg.BackupIntervalMin) * time.Minute,
+			Retention: time.Duration(cfg.BackupRetentionDays) * 24 * time.Hour,
+			Files:     []string{service.CollectorCSVPath, service.TradeLedgerCSVPath, service.ExecutionsCSVPath, service.BookImbalanceCSVPath, repository.RegimeHistoryFile},
+			Flush:     transactionRepo.Flush,
+			OnFailure: func(err error) {
+				notifier.NotifyTemplate(service.CategoryError, service.SeverityWarning, service.TemplateBackupFailed, service.BackupFailedMessageData{
+					Target: target.Name(), Error: err.Error(), IntervalMin: cfg.BackupIntervalMin,
+				})
+			},
+		}
+		uploader.Start()
+	}
+
+	// Optional shadow strategy: another profile traded on paper against the same tickers
+	if cfg.ShadowProfile != "" {
+		shadowCfg, err := cfg.WithProfile(cfg.ShadowProfile)
+		if err != nil {
+			log.Fatalf("Failed to build shadow config: %v", err)
+		}
+		capital := cfg.ShadowCapitalUSDT
+		if capital == 0 {
+			if usdt, ok := balanceRepo.Get(cfg.QuoteAsset); ok {
+				capital = math.Max(usdt.Amount-cfg.USDTReserve, 0)
+			}
+		}
+		strategy.Shadow = shadow.NewEngine(shadowCfg, cfg.ShadowProfile, volatilityService, storage)
+		if err := strategy.Shadow.Load(capital); err != nil {
+			log.Fatalf("Failed to load shadow state: %v", err)
+		}
+	}
+
+	// Bot
+	bot := core.NewBot(cfg, balanceRepo, transactionRepo, marketDataService, strategy, dataCollector)
+	bot.PriceAlerts = service.NewPriceAlertService(cfg, notifier)
+
+	// Analyze Startup State
+	strategy.AnalyzeStartupState()
+
+	// Sync Orders with Binance (Handle Offline Changes)
+	strategy.SyncOrdersOnStartup()
+
+	// Cancel buys left outside the range (e.g. RANGE_MIN/RANGE_MAX changed while offline)
+	strategy.SweepOutOfRangeOrders()
+
+	// Fix quantities/prices/fees from the actual account trades and import manual trades,
+	// then record deposits/withdrawals so they are not mistaken for trading PnL
+	if cfg.TradeReconcileIntervalMin > 0 {
+		strategy.ReconcileTrades()
+		strategy.SyncCapitalFlows()
+	}
+
+	// Kill switch on start (explicit env, no confirmation step)
+	if cfg.PanicOnStart {
+		logger.Warn("🚨 PANIC_ON_START is enabled. Flattening everything before starting.")
+		if plan, err := strategy.PreviewPanic(); err == nil {
+			logger.Warn("🚨 PANIC plan", "open_orders", plan.OpenOrders, "inventory_btc", plan.InventoryQty, "expected_proceeds", plan.ExpectedProceeds)
+		}
+		if _, err := strategy.ExecutePanic("PANIC_ON_START"); err != nil {
+			logger.Error("❌ PANIC_ON_START failed", "error", err)
+		}
+	}
+	if strategy.IsPaused() {
+		logger.Warn("⏸️ Bot is PAUSED (kill switch). No orders will be placed until /resume.", "reason", stateRepo.Get().PausedReason)
+	}
+
+	// Telegram Commands (/panic, /resume, /status, /range, /profile, /cleanup)
+	core.RegisterCommands(telegramService, strategy)
+	telegramService.StartCommandListener()
+
+	// Start Periodic Order Sync (Every 5 min)
+	strategy.StartPeriodicSync()
+
+	// Start Trade Reconciliation (myTrades)
+	strategy.StartTradeReconciliation()
+
+	// Start Exit Recovery (failed_placement)
+	strategy.StartExitRecovery()
+
+	// Start Exposure Alarm (filled buys without a live exit)
+	strategy.StartExposureAlarm()
+
+	// Start Futures Hedge (short against the grid inventory in a downtrend)
+	strategy.StartHedge()
+
+	// Start Margin Monitor (ACCOUNT_TYPE cross/isolated) and Simple Earn parking of the idle USDT
+	strategy.StartMarginMonitor()
+	strategy.StartEarn()
+
+	// Order update workers (same transaction -> same worker), also used by the resync replays
+	updateWorkers := service.NewUpdateWorkerPool(cfg.WSUpdateWorkers, strategy.UpdateKey, strategy.HandleOrderUpdate)
+	strategy.Updates = updateWorkers
+
+	// Start WebSocket Stream (fills missed while disconnected are replayed on reconnect)
+	streamService.OnReconnect = strategy.ResyncMissedEvents
+	streamService.OnAccountPosition = func(position service.AccountPosition) {
+		applyAccountPosition(balanceRepo, position)
+	}
+	streamService.OnOverflow = func(dropped uint64) {
+		strategy.HandleUpdatesOverflow(dropped, streamService.Stats().Capacity)
+	}
+	crash.Go("user stream", func() {
+		// Simple retry loop for stream start
+		for {
+			if err := streamService.Start(); err != nil {
+				logger.Error("❌ Failed to start WebSocket Stream, retrying in 10s...", "error", err)
+				time.Sleep(10 * time.Second)
+				continue
+			}
+			// Blocked inside Start() -> readLoop
+			// If it returns, it disconnected
+			logger.Warn("⚠️ WebSocket Stream disconnected, reconnecting in 5s...")
+			time.Sleep(5 * time.Second)
+		}
+	})
+
+	// Listen for WebSocket Updates
+	crash.Go("order update dispatcher", func() { updateWorkers.Run(streamService.Updates) })
+
+	// Alert and reconnect when the ticker or the user stream goes silent
+	strategy.StartWatchdog(marketDataService, streamService)
+
+	// Liveness/readiness probes (/healthz, /readyz)
+	if cfg.HealthAddr != "" {
+		server := health.NewServer(cfg, marketDataService, streamService, binanceClient, storage)
+		if failover != nil {
+			server.Endpoint = failover.Status
+		}
+		server.Start()
+	}
+
+	// External signals (TradingView alerts) gating the grid
+	if cfg.TradingViewAddr != "" {
+		tradingview.NewServer(cfg, strategy, notifier).Start()
+	}
+
+	if tuiMode {
+		screen := &tui.Screen{Out: os.Stdout, Interval: time.Second, Source: func() tui.Frame {
+			price, _ := marketDataService.GetPrice(cfg.Symbol)
+			weight, weightAt := binanceClient.UsedWeight()
+			return tui.Frame{
+				Dashboard:   strategy.Dashboard(price),
+				TickerAt:    marketDataService.LastTickerAt(cfg.Symbol),
+				APIWeight:   weight,
+				APIWeightAt: weightAt,
+			}
+		}}
+		screen.Start()
+	}
+
+	bot.Run()
+}
+
+// flushOnExit writes the batched transactions and the queued CSV records, exports the buffered
+// spans (nil = tracing disabled) and releases the leader lease (nil = no election) before the
+// process stops on SIGINT/SIGTERM. The returned function does the same for other exits.
+func flushOnExit(transactionRepo *repository.TransactionRepository, shutdownTracing func(context.Context) error, elector *leader.Elector) func(code int) {
+	var once sync.Once
+	exit := func(code int) {
+		once.Do(func() {
+			if err := transactionRepo.Flush(); err != nil {
+				logger.Error("Failed to flush transactions", "error", err)
+			}
+			service.StopRecordWriters()
+			if shutdownTracing != nil {
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				if err := shutdownTracing(ctx); err != nil {
+					logger.Error("Failed to flush traces", "error", err)
+				}
+				cancel()
+			}
+			if elector != nil {
+				elector.Release()
+			}
+			os.Exit(code)
+		})
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		exit(0)
+	}()
+	return exit
+}
+
+func syncBalances(repo *repository.BalanceRepository, info *api.AccountInfoResponse) {
+	var balances []model.Balance
+	for _, b := range info.Balances {
+		free, _ := strconv.ParseFloat(b.Free, 64)
+		locked, _ := strconv.ParseFloat(b.Locked, 64)
+
+		if free > 0 || locked > 0 {
+			balances = append(balances, model.Balance{
+				Currency: b.Asset,
+				Amount:   free, // Using Free balance for trading availability
+			})
+		}
+	}
+	repo.SetBalances(balances)
+}
+
+// applyAccountPosition keeps the balance cache fresh between the 1m REST syncs
+func applyAccountPosition(repo *repository.BalanceRepository, position service.AccountPosition) {
+	balances := make([]model.Balance, 0, len(position.Balances))
+	for _, b := range position.Balances {
+		free, _ := strconv.ParseFloat(b.Free, 64)
+		balances = append(balances, model.Balance{Currency: b.Asset, Amount: free})
+	}
+	repo.ApplyPositions(balances)
+}
+
+// applyRuntimeState overrides the .env input with the values changed at runtime
+func applyRuntimeState(cfg *config.Config, state model.RuntimeState) {
+	if state.MakerFeePct != nil {
+		cfg.MakerFeePct = *state.MakerFeePct
+	}
+	if state.TakerFeePct != nil {
+		cfg.TakerFeePct = *state.TakerFeePct
+	}
+	if state.RangeMin > 0 && state.RangeMax > state.RangeMin {
+		if state.RangeMin != cfg.RangeMin || state.RangeMax != cfg.RangeMax {
+			logger.Warn("📐 Using the range set by /range (runtime state), not the .env one",
+				"range_min", state.RangeMin, "range_max", state.RangeMax,
+				"env_range_min", cfg.RangeMin, "env_range_max", cfg.RangeMax,
+			)
+		}
+		cfg.RangeMin = state.RangeMin
+		cfg.RangeMax = state.RangeMax
+	}
+	if state.TotalCycles > cfg.TotalCycles {
+		cfg.TotalCycles = state.TotalCycles
+		cfg.MsTimeProduction = state.MsTimeProduction
+	}
+}
+
+// resolveAssets takes the base and quote assets of the symbol from exchangeInfo, keeping the
+// ones derived from SYMBOL (or BASE_ASSET/QUOTE_ASSET) when it cannot be read
+func resolveAssets(cfg *config.Config, binance *api.BinanceClient) {
+	info, err := binance.GetExchangeInfo(cfg.Symbol)
+	if err != nil {
+		logger.Warn("⚠️ Failed to fetch exchangeInfo, using the assets derived from SYMBOL", "base", cfg.BaseAsset, "quote", cfg.QuoteAsset, "error", err)
+		return
+	}
+	for _, s := range info.Symbols {
+		if s.Symbol != cfg.Symbol {
+			continue
+		}
+		if s.BaseAsset != cfg.BaseAsset || s.QuoteAsset != cfg.QuoteAsset {
+			logger.Warn("⚠️ Assets derived from SYMBOL differ from exchangeInfo, using exchangeInfo",
+				"derived_base", cfg.BaseAsset, "derived_quote", cfg.QuoteAsset, "base", s.BaseAsset, "quote", s.QuoteAsset)
+		}
+		cfg.SetAssets(s.BaseAsset, s.QuoteAsset)
+		logger.Info("🪙 Trading pair assets", "symbol", cfg.Symbol, "base", cfg.BaseAsset, "quote", cfg.QuoteAsset)
+		return
+	}
+	logger.Warn("⚠️ Symbol not found in exchangeInfo, using the assets derived from SYMBOL", "symbol", cfg.Symbol)
+}
+
+func syncFees(cfg *config.Config, stateRepo *repository.StateRepository, info *api.AccountInfoResponse) {
+	// Binance fees are in basis points (commission rate * 10000)
+	// Example: 10 => 0.0010 (0.10%)
+	makerFee := float64(info.MakerCommission) / 10000.0
+	takerFee := float64(info.TakerCommission) / 10000.0
+
+	updated := false
+
+	if makerFee != cfg.MakerFeePct {
+		logger.Info("🔄 Maker Fee Updated from API", "old", cfg.MakerFeePct, "new", makerFee)
+		cfg.MakerFeePct = makerFee
+		updated = true
+	}
+
+	if takerFee != cfg.TakerFeePct {
+		logger.Info("🔄 Taker Fee Updated from API", "old", cfg.TakerFeePct, "new", takerFee)
+		cfg.TakerFeePct = takerFee
+		updated = true
+	}
+
+	if updated {
+		if err := stateRepo.SetFees(makerFee, takerFee); err != nil {
+			logger.Error("Failed to persist fees to runtime state", "error", err)
+			return
+		}
+		logger.Info("✅ Fees synchronized with Binance and saved to runtime state")
+	}
+}
+
+// newBinanceClient builds the Binance client of the account selected by ACCOUNT_TYPE
+func newBinanceClient(cfg *config.Config) *api.BinanceClient {
+	binance := api.NewBinanceClient(cfg.BinanceApiKey, cfg.BinanceSecretKey)
+	binance.SetAccount(cfg.AccountType, cfg.Symbol, cfg.MarginAutoBorrow)
+	applyNetwork(cfg, binance)
+	return binance
+}
+
+// applyNetwork points a Spot client to BINANCE_CLUSTER or BINANCE_BASE_URL, through the shared
+// connection pool (BINANCE_PROXY, HTTP_*), with the BINANCE_TIME_BIAS_MS of the signed calls
+func applyNetwork(cfg *config.Config, binance *api.BinanceClient) {
+	binance.TimeBias = time.Duration(cfg.TimeBiasMs) * time.Millisecond
+	if base, ok := api.ClusterURL(cfg.BinanceCluster); ok {
+		binance.BaseURL = base
+	}
+	if cfg.BinanceBaseURL != "" {
+		binance.BaseURL = cfg.BinanceBaseURL
+	}
+	binance.SetTransport(restTransport(cfg))
+}
+
+// sharedTransport is the connection pool of every REST client of the process
+var sharedTransport struct {
+	once      sync.Once
+	transport *http.Transport
+}
+
+// restTransport returns the connection pool of the REST clients (built on the first call)
+func restTransport(cfg *config.Config) *http.Transport {
+	sharedTransport.once.Do(func() {
+		sharedTransport.transport = api.NewTransport(api.TransportOptions{
+			MaxIdleConnsPerHost: cfg.HTTPMaxIdleConnsPerHost,
+			IdleConnTimeout:     time.Duration(cfg.HTTPIdleConnTimeoutSec) * time.Second,
+			HTTP2:               cfg.HTTP2Enabled,
+			Proxy:               binanceProxy(cfg),
+		})
+	})
+	return sharedTransport.transport
+}
+
+// binanceProxy returns BINANCE_PROXY (validated by config.Load), nil when unset
+func binanceProxy(cfg *config.Config) *url.URL {
+	if cfg.BinanceProxy == "" {
+		return nil
+	}
+	proxy, _ := url.Parse(cfg.BinanceProxy)
+	return proxy
+}
+
+// newBackupTarget builds the target selected by BACKUP_TARGET (nil when disabled)
+func newBackupTarget(cfg *config.Config) backup.Target {
+	switch cfg.BackupTarget {
+	case "s3":
+		return backup.NewS3Target(cfg.BackupS3Endpoint, cfg.BackupS3Region, cfg.BackupS3Bucket, cfg.BackupS3Prefix, cfg.BackupS3AccessKey, cfg.BackupS3SecretKey)
+	case "sftp":
+		return backup.NewSFTPTarget(cfg.BackupSFTPHost, cfg.BackupSFTPPort, cfg.BackupSFTPKey, cfg.BackupSFTPDir)
+	}
+	return nil
+}
+
+// newElector builds the leader election selected by LEADER_LOCK (nil when disabled)
+func newElector(cfg *config.Config) *leader.Elector {
+	var lock leader.Lock
+	switch cfg.LeaderLock {
+	case "file":
+		lock = leader.NewFileLock(cfg.LeaderLockFile)
+	case "redis":
+		lock = leader.NewRedisLock(cfg.LeaderRedisAddr, cfg.LeaderRedisPassword, cfg.LeaderLockKey)
+	default:
+		return nil
+	}
+	id := cfg.LeaderID
+	if id == "" {
+		id = leader.DefaultID()
+	}
+	return leader.NewElector(lock, id, time.Duration(cfg.LeaderLeaseSec)*time.Second)
+}
+
+// acquireInstanceGuard keeps a second trading instance with the same SYMBOL and API key from
+// starting on this host (INSTANCE_GUARD). Returns nil when not guarded.
+func acquireInstanceGuard(cfg *config.Config) *instance.Guard {
+	if cfg.MonitorOnly || cfg.LeaderLock != "" || cfg.InstanceGuard == "off" {
+		return nil
+	}
+	guard, err := instance.Acquire(cfg.Symbol, cfg.BinanceApiKey)
+	switch {
+	case err == nil:
+		return guard
+	case !errors.Is(err, instance.ErrRunning):
+		logger.Error("Single-instance guard disabled", "error", err)
+	case cfg.InstanceGuard == "monitor":
+		logger.Warn("⚠️ Another instance is already trading this symbol with this API key, starting as MONITOR_ONLY", "error", err)
+		cfg.MonitorOnly = true
+	default:
+		log.Fatalf("Refusing to start: %v. Stop it first, or set INSTANCE_GUARD=monitor to start read-only", err)
+	}
+	return nil
+}
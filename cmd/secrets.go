@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"grid-trading-btc-binance/internal/config"
+	"grid-trading-btc-binance/internal/secrets"
+
+	"golang.org/x/term"
+)
+
+// runSecrets dispatches the `secrets` subcommands, which move the API keys out of .env
+func runSecrets(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing secrets command (expected keyring, encrypt or check)")
+	}
+	switch args[0] {
+	case "keyring":
+		return runSecretsKeyring(args[1:])
+	case "encrypt":
+		return runSecretsEncrypt(args[1:])
+	case "check":
+		return runSecretsCheck()
+	}
+	return fmt.Errorf("unknown secrets command %q (expected keyring, encrypt or check)", args[0])
+}
+
+// runSecretsKeyring implements `secrets keyring`: stores the keys in the OS keyring
+// (use with SECRETS_SOURCE=keyring)
+func runSecretsKeyring(args []string) error {
+	fs := flag.NewFlagSet("secrets keyring", flag.ExitOnError)
+	service := fs.String("service", secrets.DefaultKeyringService, "keyring service name (SECRETS_KEYRING_SERVICE)")
+	fs.Parse(args)
+
+	keys, err := promptKeys()
+	if err != nil {
+		return err
+	}
+	if err := secrets.StoreKeyring(*service, keys); err != nil {
+		return err
+	}
+	fmt.Printf("Keys stored in the OS keyring (service %q). Set SECRETS_SOURCE=keyring and remove them from .env.\n", *service)
+	return nil
+}
+
+// runSecretsEncrypt implements `secrets encrypt`: writes the keys to an age file protected
+// by a passphrase (use with SECRETS_SOURCE=file)
+func runSecretsEncrypt(args []string) error {
+	fs := flag.NewFlagSet("secrets encrypt", flag.ExitOnError)
+	out := fs.String("out", secrets.DefaultFile, "encrypted secrets file (SECRETS_FILE)")
+	fs.Parse(args)
+
+	keys, err := promptKeys()
+	if err != nil {
+		return err
+	}
+	passphrase, err := promptSecret("Passphrase")
+	if err != nil {
+		return err
+	}
+	if confirm, err := promptSecret("Confirm passphrase"); err != nil {
+		return err
+	} else if confirm != passphrase {
+		return fmt.Errorf("passphrases do not match")
+	}
+
+	if err := secrets.EncryptFile(*out, passphrase, keys); err != nil {
+		return err
+	}
+	fmt.Printf("Keys encrypted to %s. Set SECRETS_SOURCE=file, provide SECRETS_PASSPHRASE (or SECRETS_PASSPHRASE_FILE) and remove them from .env.\n", *out)
+	return nil
+}
+
+// runSecretsCheck implements `secrets check`: loads the configuration and shows which keys
+// were resolved (masked)
+func runSecretsCheck() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	source := os.Getenv("SECRETS_SOURCE")
+	if source == "" {
+		source = secrets.SourceEnv
+	}
+	fmt.Printf("Source: %s\n%s: %s\n%s: %s\n", source,
+		secrets.APIKeyName, mask(cfg.BinanceApiKey), secrets.SecretKeyName, mask(cfg.BinanceSecretKey))
+	return nil
+}
+
+func promptKeys() (secrets.Keys, error) {
+	var keys secrets.Keys
+	var err error
+	if keys.APIKey, err = promptSecret(secrets.APIKeyName); err != nil {
+		return keys, err
+	}
+	if keys.SecretKey, err = promptSecret(secrets.SecretKeyName); err != nil {
+		return keys, err
+	}
+	return keys, nil
+}
+
+var stdinLines = bufio.NewScanner(os.Stdin)
+
+// promptSecret reads one value without echo on a terminal, or one line from piped stdin
+func promptSecret(name string) (string, error) {
+	fmt.Fprintf(os.Stderr, "%s: ", name)
+	var value string
+	if fd := int(os.Stdin.Fd()); term.IsTerminal(fd) {
+		raw, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		value = string(raw)
+	} else {
+		if !stdinLines.Scan() {
+			return "", fmt.Errorf("missing %s on stdin", name)
+		}
+		value = stdinLines.Text()
+	}
+
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", fmt.Errorf("%s cannot be empty", name)
+	}
+	return value, nil
+}
+
+func mask(value string) string {
+	if value == "" {
+		return "(missing)"
+	}
+	if len(value) <= 8 {
+		return strings.Repeat("*", len(value))
+	}
+	return value[:4] + strings.Repeat("*", len(value)-8) + value[len(value)-4:]
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"grid-trading-btc-binance/internal/api"
+	"grid-trading-btc-binance/internal/backtest"
+	"grid-trading-btc-binance/internal/config"
+	"grid-trading-btc-binance/internal/data"
+	"grid-trading-btc-binance/internal/fees"
+	"grid-trading-btc-binance/internal/logger"
+)
+
+// runSimulate implements `simulate`: expected fills per day and fee-adjusted yield of spacing and
+// levels candidates (the live dynamic spacing and GRID_LEVELS by default), from Monte Carlo paths
+// resampled from the recent 1m klines at the current volatility, to sanity check a configuration
+// before applying it live.
+func runSimulate(args []string) error {
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	days := fs.Int("days", 7, "days of 1m klines the paths are resampled from")
+	spacings := fs.String("spacing", "", "spacing values, as fractions (default the current dynamic spacing)")
+	levels := fs.String("levels", "", "GRID_LEVELS values (default GRID_LEVELS)")
+	vol := fs.String("vol", "current", "volatility of the paths: current (last 20 minutes), history (as recorded) or a per-minute value")
+	horizon := fs.Int("horizon", 1, "days per simulated path")
+	paths := fs.Int("paths", 1000, "simulated paths per candidate")
+	seed := fs.Int64("seed", time.Now().UnixNano(), "random sampling seed")
+	capital := fs.Float64("capital", 1000, "USDT split between the levels")
+	fs.Parse(args)
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	binance := api.NewBinanceClient(cfg.BinanceApiKey, cfg.BinanceSecretKey)
+	applyNetwork(cfg, binance)
+	store := data.NewKlineStore(binance, data.DefaultKlinesDir)
+	end := time.Now()
+	start := end.Add(-time.Duration(*days) * 24 * time.Hour)
+	klines, err := store.Range(cfg.Symbol, "1m", start, end)
+	if err != nil {
+		return err
+	}
+	candles := backtest.FromKlines(klines)
+
+	feeModel := fees.For(cfg)
+	current := backtest.CurrentSpacing(candles, backtest.Settings{
+		MinSpacing:      math.Max(feeModel.MinSpacing(), cfg.MinDynamicSpacingPct),
+		MaxSpacing:      cfg.MaxDynamicSpacingPct,
+		FallbackSpacing: cfg.GridSpacingPct,
+	}, backtest.Params{LowVolMultiplier: cfg.LowVolMultiplier, HighVolMultiplier: cfg.HighVolMultiplier})
+
+	spacingValues := []float64{current}
+	if *spacings != "" {
+		if spacingValues, err = parseFloatList(*spacings, "spacing"); err != nil {
+			return err
+		}
+	}
+	levelValues := []int{cfg.GridLevels}
+	if *levels != "" {
+		if levelValues, err = parseIntList(*levels, "levels"); err != nil {
+			return err
+		}
+	}
+
+	historyVol := backtest.Volatility(candles)
+	if historyVol <= 0 {
+		return fmt.Errorf("no volatility in %d candles", len(candles))
+	}
+	target := historyVol
+	switch *vol {
+	case "current":
+		target = backtest.CurrentVolatility(candles)
+	case "history":
+	default:
+		if target, err = strconv.ParseFloat(*vol, 64); err != nil || target <= 0 {
+			return fmt.Errorf("invalid value for -vol: expected current, history or a positive number, got %q", *vol)
+		}
+	}
+	logger.Info("🎲 Simulating grid fills", "symbol", cfg.Symbol, "candles", len(candles), "paths", *paths, "horizon_days", *horizon)
+
+	settings := backtest.FillSimSettings{
+		MakerFeePct:   feeModel.Maker,
+		Capital:       *capital,
+		VolScale:      target / historyVol,
+		CandlesPerDay: 24 * 60,
+		Days:          *horizon,
+		Paths:         *paths,
+	}
+	rng := rand.New(rand.NewSource(*seed))
+	var results []backtest.FillSimResult
+	for _, spacing := range spacingValues {
+		for _, n := range levelValues {
+			settings.Spacing, settings.Levels = spacing, n
+			result, err := backtest.SimulateFills(candles, settings, rng)
+			if err != nil {
+				return err
+			}
+			results = append(results, result)
+		}
+	}
+
+	fmt.Printf("Paths resampled from %s -> %s (%d x %dd per row)\n", start.Format("2006-01-02 15:04"), end.Format("2006-01-02 15:04"), *paths, *horizon)
+	fmt.Printf("Volatility per minute: %.5f recorded, %.5f simulated (x%.2f)\n", historyVol, target, settings.VolScale)
+	fmt.Printf("Current dynamic spacing: %.4f, maker fee: %.4f%%\n\n", current, feeModel.Maker*100)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "SPACING\tLEVELS\tBUYS/DAY\tTRIPS/DAY\tP10\tP50\tP90\tNO_TRIP_%\tNET/TRIP_%\tYIELD/DAY_%\tOPEN_PNL_%\tSTUCK\t")
+	for _, r := range results {
+		fmt.Fprintf(w, "%.4f\t%d\t%.1f\t%.1f\t%.1f\t%.1f\t%.1f\t%.1f\t%.3f\t%.3f\t%.2f\t%.1f\t\n",
+			r.Spacing, r.Levels, r.BuysPerDay, r.RoundTripsPerDay, r.RoundTripsP10, r.RoundTripsP50, r.RoundTripsP90,
+			r.NoTripPct, r.NetPerTripPct, r.DailyYieldPct, r.OpenPnLPct, r.StuckLevels)
+	}
+	w.Flush()
+	for _, r := range results {
+		if r.NetPerTripPct <= 0 && r.Levels == levelValues[0] {
+			fmt.Printf("\n⚠️  Spacing %.4f does not cover the maker fees of a round trip\n", r.Spacing)
+		}
+	}
+	return nil
+}
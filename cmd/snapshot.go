@@ -0,0 +1,139 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"grid-trading-btc-binance/internal/config"
+	"grid-trading-btc-binance/internal/instance"
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/model"
+	"grid-trading-btc-binance/internal/repository"
+	"grid-trading-btc-binance/internal/snapshot"
+)
+
+// runSnapshot implements `snapshot`: bundles the state files into one versioned file. Run it
+// with the bot stopped (or rely on the periodic snapshot) so batched changes are on disk.
+func runSnapshot(args []string) error {
+	fs := flag.NewFlagSet("snapshot", flag.ExitOnError)
+	dir := fs.String("dir", "", "output directory (default SNAPSHOT_DIR)")
+	offline := fs.Bool("offline", false, "do not fetch the account balances from Binance")
+	fs.Parse(args)
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if *dir == "" {
+		*dir = cfg.SnapshotDir
+	}
+
+	var balances []model.Balance
+	if !*offline {
+		balances = fetchBalances(cfg)
+	}
+	snap, err := snapshot.Create(cfg.Symbol, "manual", balances)
+	if err != nil {
+		return err
+	}
+	path, err := snapshot.Write(*dir, snap)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Snapshot written to %s (%d files, %d balances)\n", path, len(snap.Files), len(snap.Balances))
+	return nil
+}
+
+// runRestore implements `restore`: puts the state files of a snapshot back. The current state
+// is snapshotted first ("pre-restore"), so a restore can itself be undone. The bot must be
+// stopped, or it would overwrite the restored files on its next flush.
+func runRestore(args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	file := fs.String("file", "", "snapshot file to restore (required)")
+	yes := fs.Bool("yes", false, "apply the restore (without it, only shows what would be restored)")
+	force := fs.Bool("force", false, "restore a snapshot taken for a different SYMBOL")
+	fs.Parse(args)
+
+	if *file == "" {
+		return fmt.Errorf("missing -file")
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	snap, err := snapshot.Read(*file)
+	if err != nil {
+		return err
+	}
+	printSnapshot(snap)
+	if snap.Symbol != cfg.Symbol && !*force {
+		return fmt.Errorf("snapshot was taken for %s but SYMBOL is %s (use -force to restore anyway)", snap.Symbol, cfg.Symbol)
+	}
+	if !*yes {
+		fmt.Println("\nNothing changed. Stop the bot and rerun with -yes to restore.")
+		return nil
+	}
+
+	// Held during the restore: a guarded bot can neither be running nor start meanwhile
+	guard, err := instance.Acquire(cfg.Symbol, cfg.BinanceApiKey)
+	if errors.Is(err, instance.ErrRunning) {
+		return fmt.Errorf("the bot is running: %w. Stop it before restoring", err)
+	}
+	if err == nil {
+		defer guard.Release()
+	}
+
+	current, err := snapshot.Create(cfg.Symbol, "pre-restore", nil)
+	if err != nil {
+		return fmt.Errorf("failed to snapshot the current state: %w", err)
+	}
+	backup, err := snapshot.Write(cfg.SnapshotDir, current)
+	if err != nil {
+		return fmt.Errorf("failed to snapshot the current state: %w", err)
+	}
+	if err := snapshot.Restore(snap); err != nil {
+		return fmt.Errorf("restore incomplete (previous state in %s): %w", backup, err)
+	}
+	logger.Info("♻️ State restored from snapshot", "file", *file, "created_at", snap.CreatedAt, "backup", backup)
+	fmt.Printf("\nRestored. Previous state saved to %s. Start the bot: the startup sync reconciles the restored orders with Binance.\n", backup)
+	return nil
+}
+
+// fetchBalances reads the account balances (informational part of the snapshot)
+func fetchBalances(cfg *config.Config) []model.Balance {
+	binance := newBinanceClient(cfg)
+	info, err := binance.GetAccountInfo()
+	if err != nil {
+		logger.Warn("⚠️ Snapshot without balances: failed to fetch account info", "error", err)
+		return nil
+	}
+	repo := repository.NewBalanceRepository()
+	syncBalances(repo, info)
+	return repo.All()
+}
+
+func printSnapshot(snap *snapshot.Snapshot) {
+	fmt.Printf("Snapshot v%d of %s, taken %s (%s)\n\n", snap.Version, snap.Symbol,
+		snap.CreatedAt.Format("2006-01-02 15:04:05"), snap.Reason)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FILE\tBYTES")
+	for _, path := range snapshot.StateFiles() {
+		if raw, ok := snap.Files[path]; ok {
+			fmt.Fprintf(w, "%s\t%d\n", path, len(raw))
+		} else {
+			fmt.Fprintf(w, "%s\t(removed)\n", path)
+		}
+	}
+	w.Flush()
+
+	if len(snap.Balances) > 0 {
+		fmt.Println("\nBalances at the time (not restored, synced from Binance):")
+		for _, b := range snap.Balances {
+			fmt.Printf("  %s %.8f\n", b.Currency, b.Amount)
+		}
+	}
+}
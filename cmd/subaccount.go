@@ -0,0 +1,99 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+
+	"grid-trading-btc-binance/internal/api"
+	"grid-trading-btc-binance/internal/config"
+)
+
+// runSubAccount dispatches the `subaccount` subcommands, which move funds between the master
+// account and the sub-account the grid trades on (SUB_ACCOUNT_EMAIL)
+func runSubAccount(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing subaccount command (expected topup or skim)")
+	}
+	switch args[0] {
+	case "topup":
+		return runSubAccountTopUp(args[1:])
+	case "skim":
+		return runSubAccountSkim(args[1:])
+	}
+	return fmt.Errorf("unknown subaccount command %q (expected topup or skim)", args[0])
+}
+
+// runSubAccountTopUp implements `subaccount topup`: moves funds from the master Spot wallet to
+// the grid's wallet of the sub-account, with the master keys (MASTER_API_KEY/MASTER_SECRET_KEY)
+func runSubAccountTopUp(args []string) error {
+	fs := flag.NewFlagSet("subaccount topup", flag.ExitOnError)
+	amount := fs.Float64("amount", 0, "amount to transfer")
+	asset := fs.String("asset", "", "asset to transfer (default: the quote asset)")
+	fs.Parse(args)
+
+	cfg, err := loadSubAccountConfig(*amount, asset)
+	if err != nil {
+		return err
+	}
+	if cfg.MasterApiKey == "" {
+		return fmt.Errorf("the top-up needs the master account keys: set MASTER_API_KEY and MASTER_SECRET_KEY")
+	}
+
+	wallet := api.SubAccountWallet(cfg.AccountType)
+	master := api.NewBinanceClient(cfg.MasterApiKey, cfg.MasterSecretKey)
+	applyNetwork(cfg, master)
+	tranID, err := master.SubAccountTopUp(cfg.SubAccountEmail, wallet, cfg.Symbol, *asset, strconv.FormatFloat(*amount, 'f', -1, 64))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Transferred %s %s from the master account to %s (%s wallet, tranId %d).\n",
+		strconv.FormatFloat(*amount, 'f', -1, 64), *asset, cfg.SubAccountEmail, wallet, tranID)
+	fmt.Println("The bot records it as a deposit at the next capital flow sync.")
+	return nil
+}
+
+// runSubAccountSkim implements `subaccount skim`: moves funds from the sub-account Spot wallet
+// back to the master account, with the sub-account keys
+func runSubAccountSkim(args []string) error {
+	fs := flag.NewFlagSet("subaccount skim", flag.ExitOnError)
+	amount := fs.Float64("amount", 0, "amount to transfer")
+	asset := fs.String("asset", "", "asset to transfer (default: the quote asset)")
+	fs.Parse(args)
+
+	cfg, err := loadSubAccountConfig(*amount, asset)
+	if err != nil {
+		return err
+	}
+	if cfg.AccountType != api.AccountSpot {
+		return fmt.Errorf("skim transfers from the sub-account Spot wallet and is not supported with ACCOUNT_TYPE=%s", cfg.AccountType)
+	}
+
+	txnID, err := newBinanceClient(cfg).SubAccountToMaster(*asset, strconv.FormatFloat(*amount, 'f', -1, 64))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Transferred %s %s from %s to the master account (txnId %s).\n",
+		strconv.FormatFloat(*amount, 'f', -1, 64), *asset, cfg.SubAccountEmail, txnID)
+	fmt.Println("The bot records it as a withdrawal at the next capital flow sync. Use VAULT_MODE=transfer to skim profits automatically.")
+	return nil
+}
+
+// loadSubAccountConfig loads the configuration for a transfer, checking the sub-account mode
+// and the amount, and defaults asset to the quote asset
+func loadSubAccountConfig(amount float64, asset *string) (*config.Config, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("-amount must be > 0")
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if cfg.SubAccountEmail == "" {
+		return nil, fmt.Errorf("SUB_ACCOUNT_EMAIL is not set: the bot does not run on a sub-account")
+	}
+	if *asset == "" {
+		*asset = cfg.QuoteAsset
+	}
+	return cfg, nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"grid-trading-btc-binance/internal/api"
+	"grid-trading-btc-binance/internal/config"
+	"grid-trading-btc-binance/internal/data"
+	"grid-trading-btc-binance/internal/instance"
+	"grid-trading-btc-binance/internal/market"
+	"grid-trading-btc-binance/internal/repository"
+)
+
+// runSuggestRange implements `suggest-range`: a RANGE_MIN/RANGE_MAX from the recent volatility
+// (Bollinger band of the daily closes, widened to leave room around the price by the ATR), next
+// to the range in force. -apply stores it as the runtime range, like /range, while the bot is
+// stopped.
+func runSuggestRange(args []string) error {
+	fs := flag.NewFlagSet("suggest-range", flag.ExitOnError)
+	days := fs.Int("days", 0, "daily candles considered (default RANGE_SUGGEST_DAYS)")
+	stdDevs := fs.Float64("k", 0, "band width in standard deviations of the closes (default RANGE_SUGGEST_STDDEV)")
+	atrs := fs.Float64("atr", -1, "minimum room around the price in daily average true ranges (default RANGE_SUGGEST_ATR)")
+	apply := fs.Bool("apply", false, "store the suggestion as the runtime range (bot stopped)")
+	fs.Parse(args)
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if *days <= 0 {
+		*days = cfg.RangeDays
+	}
+	if *stdDevs <= 0 {
+		*stdDevs = cfg.RangeStdDevs
+	}
+	if *atrs < 0 {
+		*atrs = cfg.RangeATRs
+	}
+
+	stateRepo := repository.NewStateRepository(repository.NewStorage())
+	if err := stateRepo.Load(); err != nil {
+		return fmt.Errorf("failed to read runtime state: %w", err)
+	}
+	rangeMin, rangeMax := cfg.RangeMin, cfg.RangeMax
+	if state := stateRepo.Get(); state.RangeMin > 0 && state.RangeMax > 0 {
+		rangeMin, rangeMax = state.RangeMin, state.RangeMax
+	}
+
+	binance := api.NewBinanceClient(cfg.BinanceApiKey, cfg.BinanceSecretKey)
+	applyNetwork(cfg, binance)
+	store := data.NewKlineStore(binance, data.DefaultKlinesDir)
+	klines, err := store.Recent(cfg.Symbol, "1d", *days, 48*time.Hour)
+	if err != nil {
+		return err
+	}
+	suggestion, err := market.SuggestRange(klines, *stdDevs, *atrs)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Symbol\t%s\n", cfg.Symbol)
+	fmt.Fprintf(w, "Price\t%.2f\n", suggestion.Price)
+	fmt.Fprintf(w, "SMA %dd\t%.2f\n", suggestion.Days, suggestion.SMA)
+	fmt.Fprintf(w, "Std dev\t%.2f (%.2f%%)\n", suggestion.StdDev, suggestion.StdDev/suggestion.SMA*100)
+	fmt.Fprintf(w, "ATR (1d)\t%.2f (%.2f%%)\n", suggestion.ATR, suggestion.ATR/suggestion.Price*100)
+	fmt.Fprintf(w, "Current range\t%.2f - %.2f\n", rangeMin, rangeMax)
+	fmt.Fprintf(w, "Suggested range\t%.2f - %.2f (SMA +- %.1f sd, >= %.1f ATR around the price)\n", suggestion.Min, suggestion.Max, *stdDevs, *atrs)
+	switch suggestion.Stale(rangeMin, rangeMax) {
+	case market.RangeOutside:
+		fmt.Fprintf(w, "Verdict\tstale: the price is outside the range\n")
+	case market.RangeUncovered:
+		fmt.Fprintf(w, "Verdict\tstale: the range covers %.0f%% of the suggested band\n", suggestion.Coverage(rangeMin, rangeMax)*100)
+	case market.RangeWide:
+		fmt.Fprintf(w, "Verdict\tstale: the range is %.1fx wider than the suggested band\n", (rangeMax-rangeMin)/(suggestion.Max-suggestion.Min))
+	default:
+		fmt.Fprintf(w, "Verdict\tok\n")
+	}
+	w.Flush()
+
+	if !*apply {
+		return nil
+	}
+	guard, err := instance.Acquire(cfg.Symbol, cfg.BinanceApiKey)
+	if errors.Is(err, instance.ErrRunning) {
+		return fmt.Errorf("the bot is running: use /range %.2f %.2f on Telegram", suggestion.Min, suggestion.Max)
+	}
+	if err != nil {
+		return err
+	}
+	defer guard.Release()
+	if err := stateRepo.SetRange(suggestion.Min, suggestion.Max); err != nil {
+		return fmt.Errorf("failed to store the range: %w", err)
+	}
+	fmt.Printf("\nRuntime range set to %.2f - %.2f (applied on the next start).\n", suggestion.Min, suggestion.Max)
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"grid-trading-btc-binance/internal/config"
+	"grid-trading-btc-binance/internal/tradingview"
+)
+
+// runTradingViewSign implements `tv-sign`: the signature of a signal and the webhook URL
+// carrying it, to test the webhook or a relay. The URL is only accepted with
+// TRADINGVIEW_MAX_AGE_SEC > 0, so the message must carry a current "time" and is applied once.
+func runTradingViewSign(args []string) error {
+	fs := flag.NewFlagSet("tv-sign", flag.ExitOnError)
+	base := fs.String("url", "", "public base URL of TRADINGVIEW_ADDR, e.g. https://bot.example.com")
+	fs.Parse(args)
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if cfg.TradingViewSecret == "" {
+		return fmt.Errorf("TRADINGVIEW_SECRET is not set")
+	}
+
+	message := strings.Join(fs.Args(), " ")
+	if message == "" {
+		raw, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		message = strings.TrimRight(string(raw), "\r\n")
+	}
+	if message == "" {
+		return fmt.Errorf("missing message (argument or stdin), e.g. '{\"action\":\"pause\",\"reason\":\"CPI\"}'")
+	}
+
+	signature := tradingview.Sign(cfg.TradingViewSecret, []byte(message))
+	fmt.Printf("Message:   %s\nSignature: %s\n", message, signature)
+	if *base != "" && cfg.TradingViewMaxAgeSec > 0 {
+		fmt.Printf("URL:       %s%s?sig=%s\n", strings.TrimRight(*base, "/"), tradingview.Path, url.QueryEscape(signature))
+		fmt.Printf("\n⚠️  Valid once, within %ds of the message's \"time\"\n", cfg.TradingViewMaxAgeSec)
+	} else if *base != "" {
+		fmt.Println("\n⚠️  No URL: a signature in the URL is refused with TRADINGVIEW_MAX_AGE_SEC=0 (send it in the " + tradingview.SignatureHeader + " header)")
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"text/tabwriter"
+	"time"
+
+	"grid-trading-btc-binance/internal/backtest"
+	"grid-trading-btc-binance/internal/config"
+	"grid-trading-btc-binance/internal/data"
+	"grid-trading-btc-binance/internal/logger"
+)
+
+// runWalkForward implements `walkforward`: optimizes on a rolling in-sample window and
+// evaluates the winner on the following out-of-sample window, comparing it with the
+// parameters currently in .env, to catch overfitted choices before they go live.
+func runWalkForward(args []string) error {
+	fs := flag.NewFlagSet("walkforward", flag.ExitOnError)
+	search := registerSearchFlags(fs, 60)
+	inDays := fs.Float64("in-days", 14, "in-sample (optimization) window, in days")
+	outDays := fs.Float64("out-days", 7, "out-of-sample (validation) window and step, in days")
+	fs.Parse(args)
+
+	candidates, err := search.candidates()
+	if err != nil {
+		return err
+	}
+	step, err := data.IntervalDuration(*search.interval)
+	if err != nil {
+		return err
+	}
+	inCandles := int(*inDays * float64(24*time.Hour) / float64(step))
+	outCandles := int(*outDays * float64(24*time.Hour) / float64(step))
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	candles, _, _, err := search.loadCandles(cfg)
+	if err != nil {
+		return err
+	}
+	logger.Info("🧪 Walk-forward analysis", "symbol", cfg.Symbol, "candles", len(candles), "candidates", len(candidates), "in_sample", inCandles, "out_sample", outCandles)
+
+	live := backtest.Params{
+		GridLevels:        cfg.GridLevels,
+		LowVolMultiplier:  cfg.LowVolMultiplier,
+		HighVolMultiplier: cfg.HighVolMultiplier,
+		PositionSizePct:   cfg.PositionSizePct,
+	}
+	report, err := backtest.WalkForward(candles, search.settings(cfg), candidates, backtest.WalkForwardConfig{
+		InSample:  inCandles,
+		OutSample: outCandles,
+		RankBy:    *search.rankBy,
+		Workers:   runtime.NumCPU(),
+		Baseline:  &live,
+	})
+	if err != nil {
+		return err
+	}
+
+	printWalkForward(report, live)
+	return nil
+}
+
+func printWalkForward(report backtest.WalkForwardReport, live backtest.Params) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "OUT-OF-SAMPLE\tBEST IN-SAMPLE\tIS_RETURN_%\tOOS_RETURN_%\tOOS_MAX_DD_%\tENV_OOS_RETURN_%\t")
+	for _, win := range report.Windows {
+		envReturn := ""
+		if win.Baseline != nil {
+			envReturn = fmt.Sprintf("%.2f", win.Baseline.TotalReturnPct)
+		}
+		mark := ""
+		if win.Degraded() {
+			mark = " ⚠️"
+		}
+		fmt.Fprintf(w, "%s -> %s\t%s\t%.2f\t%.2f%s\t%.2f\t%s\t\n",
+			time.UnixMilli(win.OutStart).Format("2006-01-02"), time.UnixMilli(win.OutEnd).Format("2006-01-02"),
+			win.Best, win.InSample.TotalReturnPct, win.OutOfSample.TotalReturnPct, mark, win.OutOfSample.MaxDrawdownPct, envReturn)
+	}
+	w.Flush()
+
+	fmt.Printf("\nWindows: %d | Degraded (profit in-sample, loss out-of-sample): %d | Distinct winners: %d\n",
+		len(report.Windows), report.DegradedWindows, report.DistinctBest)
+	fmt.Printf("Walk-forward OOS return: %.2f%% | .env (%s) OOS return: %.2f%%\n", report.OOSReturnPct, live, report.BaselineReturnPct)
+	if report.Efficiency == 0 {
+		fmt.Println("Efficiency (OOS/IS return per candle): n/a (the in-sample winners lost money on average)")
+		return
+	}
+	fmt.Printf("Efficiency (OOS/IS return per candle): %.2f", report.Efficiency)
+	if report.Efficiency < 0.5 {
+		fmt.Print("  ⚠️ below 0.5: the optimized parameters are likely overfitted")
+	}
+	fmt.Println()
+}
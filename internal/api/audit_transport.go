@@ -0,0 +1,75 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"grid-trading-btc-binance/internal/audit"
+)
+
+const auditMaxBody = 4096 // Response bytes kept in the audit log
+
+// Parameters never written to the audit log (the API key travels in a header, not logged)
+var auditDropParams = map[string]bool{"signature": true, "timestamp": true, "recvWindow": true}
+
+// Parameters that name the order a request is about, in order of preference
+var auditOrderParams = []string{"newClientOrderId", "origClientOrderId", "cancelOrigClientOrderId"}
+
+// auditTransport writes every REST call that changes the account (orders, cancels,
+// transfers: anything but GET) and its response to the audit log. listenKey calls are skipped.
+type auditTransport struct {
+	next http.RoundTripper
+}
+
+func (t *auditTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !audit.Enabled() || req.Method == http.MethodGet || strings.Contains(req.URL.Path, "userDataStream") {
+		return t.next.RoundTrip(req)
+	}
+
+	params := audit.Fields{}
+	orderID := ""
+	query := req.URL.Query()
+	for key, values := range query {
+		if !auditDropParams[key] && len(values) > 0 {
+			params[key] = values[0]
+		}
+	}
+	for _, key := range auditOrderParams {
+		if id := query.Get(key); id != "" {
+			orderID = id
+			break
+		}
+	}
+
+	action := req.Method + " " + req.URL.Path
+	requestID := audit.Request(orderID, action, params)
+	start := time.Now()
+
+	resp, err := t.next.RoundTrip(req)
+	fields := audit.Fields{"duration_ms": time.Since(start).Milliseconds()}
+	if err != nil {
+		fields["error"] = err.Error()
+		audit.Response(requestID, orderID, action, fields)
+		return resp, err
+	}
+
+	// Read the body for the log and hand an identical copy to the caller
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	fields["status"] = resp.StatusCode
+	if err != nil {
+		fields["error"] = err.Error()
+		audit.Response(requestID, orderID, action, fields)
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if len(body) > auditMaxBody {
+		body = append(body[:auditMaxBody:auditMaxBody], "...(truncated)"...)
+	}
+	fields["body"] = string(body)
+	audit.Response(requestID, orderID, action, fields)
+	return resp, nil
+}
@@ -5,11 +5,13 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"grid-trading-btc-binance/internal/logger"
@@ -20,12 +22,29 @@ const (
 	BaseURL = "https://api.binance.com"
 )
 
+// ErrReadOnly is returned by the order and transfer calls of a read-only client
+var ErrReadOnly = errors.New("read-only client (MONITOR_ONLY): orders, cancels and transfers are disabled")
+
 type BinanceClient struct {
 	APIKey     string
 	SecretKey  string
 	BaseURL    string
 	Client     *http.Client
-	TimeOffset int64
+	TimeOffset int64         // Server - local clock (ms), set by SyncTime (atomic)
+	TimeBias   time.Duration // Timestamps signed this much behind the server clock (default DefaultTimeBias)
+	ReadOnly   bool          // Refuse every call that places, cancels or transfers (MONITOR_ONLY)
+
+	OnOrderLatency func(time.Duration)   // Called with the round trip of every CreateOrder (nil = not measured)
+	OnRateLimit    func(RateLimitStatus) // Called on entering (Active) and leaving the 429/418 protection mode (nil = only logged)
+	OnConnection   func(ConnTrace)       // Called with the connection side of every REST call (nil = not measured)
+
+	AccountType      string // spot (default) | cross | isolated: margin accounts route to /sapi/v1/margin (see SetAccount)
+	MarginSymbol     string // Isolated pair, and symbol of the borrow/repay calls
+	MarginAutoBorrow bool   // Margin buys borrow the missing quote asset (sideEffectType MARGIN_BUY)
+
+	requests *requestCounter // Error rate reported by the health endpoints
+	guard    *rateGuard      // 429/418 protection mode (see RateLimit)
+	active   atomic.Value    // REST host in use while the failover moved away from BaseURL (see Endpoint)
 }
 
 type AccountInfoResponse struct {
@@ -48,18 +67,36 @@ type BalanceResponse struct {
 }
 
 func NewBinanceClient(apiKey, secretKey string) *BinanceClient {
-	return &BinanceClient{
+	requests := newRequestCounter(nil)
+	c := &BinanceClient{
 		APIKey:    apiKey,
 		SecretKey: secretKey,
 		BaseURL:   BaseURL,
-		Client:    &http.Client{Timeout: 10 * time.Second},
-	}
+		TimeBias:  DefaultTimeBias,
+		requests:  requests,
+	}
+	requests.onConn = func(trace ConnTrace) {
+		if c.OnConnection != nil {
+			c.OnConnection(trace)
+		}
+	}
+	c.guard = &rateGuard{next: requests, onChange: func(status RateLimitStatus) {
+		if c.OnRateLimit != nil {
+			c.OnRateLimit(status)
+		}
+	}}
+	queue := &requestQueue{next: c.guard, weight: c.UsedWeight}
+	c.Client = &http.Client{Timeout: 10 * time.Second, Transport: &auditTransport{next: queue}}
+	return c
 }
 
 // SyncTime synchronizes the local time with Binance server time
 func (c *BinanceClient) SyncTime() error {
-	endpoint := "/api/v3/time"
-	reqURL := fmt.Sprintf("%s%s", c.BaseURL, endpoint)
+	return c.syncTime("/api/v3/time")
+}
+
+func (c *BinanceClient) syncTime(endpoint string) error {
+	reqURL := fmt.Sprintf("%s%s", c.Endpoint(), endpoint)
 
 	resp, err := c.Client.Get(reqURL)
 	if err != nil {
@@ -80,17 +117,18 @@ func (c *BinanceClient) SyncTime() error {
 	}
 
 	localTime := time.Now().UnixMilli()
-	c.TimeOffset = timeResp.ServerTime - localTime
+	offset := timeResp.ServerTime - localTime
+	atomic.StoreInt64(&c.TimeOffset, offset)
 
-	logger.Info("⏰ Time Synchronized", "server_time", timeResp.ServerTime, "local_time", localTime, "offset_ms", c.TimeOffset)
+	logger.Info("⏰ Time Synchronized", "server_time", timeResp.ServerTime, "local_time", localTime, "offset_ms", offset)
 	return nil
 }
 
-// serverTime returns the current time adjusted by the offset
-// We subtract 1000ms as a safety bias to ensure we are slightly "behind" the server.
-// Binance rejects requests > 1000ms ahead, but accepts requests up to recvWindow (60s) behind.
+// serverTime returns the current time adjusted by the offset, minus TimeBias to stay slightly
+// "behind" the server: Binance rejects requests > 1000ms ahead, but accepts requests up to
+// recvWindow (60s) behind. A proxy that delays the calls may need a smaller bias.
 func (c *BinanceClient) serverTime() int64 {
-	return time.Now().UnixMilli() + c.TimeOffset - 1000
+	return time.Now().UnixMilli() + atomic.LoadInt64(&c.TimeOffset) - c.TimeBias.Milliseconds()
 }
 
 func (c *BinanceClient) GetAccountInfo() (*AccountInfoResponse, error) {
@@ -107,7 +145,7 @@ func (c *BinanceClient) GetAccountInfo() (*AccountInfoResponse, error) {
 	params.Add("signature", signature)
 
 	// Build URL
-	reqURL := fmt.Sprintf("%s%s?%s", c.BaseURL, endpoint, params.Encode())
+	reqURL := fmt.Sprintf("%s%s?%s", c.Endpoint(), endpoint, params.Encode())
 
 	// Create request
 	req, err := http.NewRequest("GET", reqURL, nil)
@@ -139,6 +177,13 @@ func (c *BinanceClient) GetAccountInfo() (*AccountInfoResponse, error) {
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
+	// Commissions and permissions come from the Spot account, balances from the margin one
+	if c.Margin() {
+		if err := c.marginBalances(&accountInfo); err != nil {
+			return nil, fmt.Errorf("failed to get margin account: %w", err)
+		}
+	}
+
 	return &accountInfo, nil
 }
 
@@ -148,12 +193,44 @@ func (c *BinanceClient) sign(queryString string) string {
 	return hex.EncodeToString(mac.Sum(nil))
 }
 
+// signedRequest adds timestamp/recvWindow/signature to params, executes the request and
+// returns the raw body. Non-200 responses are returned as errors containing the body.
+func (c *BinanceClient) signedRequest(method, endpoint string, params url.Values) ([]byte, error) {
+	params.Set("timestamp", strconv.FormatInt(c.serverTime(), 10))
+	params.Set("recvWindow", "60000")
+	params.Set("signature", c.sign(params.Encode()))
+
+	req, err := http.NewRequest(method, fmt.Sprintf("%s%s", c.Endpoint(), endpoint), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.URL.RawQuery = params.Encode()
+	req.Header.Add("X-MBX-APIKEY", c.APIKey)
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read error: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, string(body))
+	}
+	return body, nil
+}
+
 type OrderRequest struct {
 	Symbol           string
 	Side             string
 	Type             string
 	TimeInForce      string
 	Quantity         string
+	QuoteOrderQty    string // MARKET orders only: spend this amount of quote asset instead of a fixed quantity
 	Price            string
 	NewClientOrderID string
 }
@@ -170,6 +247,7 @@ type OrderResponse struct {
 	Status              string `json:"status"`
 	Type                string `json:"type"`
 	Side                string `json:"side"`
+	Time                int64  `json:"time"` // Creation (order queries only)
 	Fills               []struct {
 		Price           string `json:"price"`
 		Qty             string `json:"qty"`
@@ -179,6 +257,9 @@ type OrderResponse struct {
 }
 
 func (c *BinanceClient) CreateOrder(req OrderRequest) (*OrderResponse, error) {
+	if c.ReadOnly {
+		return nil, ErrReadOnly
+	}
 	endpoint := "/api/v3/order"
 
 	params := url.Values{}
@@ -193,12 +274,19 @@ func (c *BinanceClient) CreateOrder(req OrderRequest) (*OrderResponse, error) {
 	if req.Quantity != "" {
 		params.Add("quantity", req.Quantity)
 	}
+	if req.QuoteOrderQty != "" {
+		params.Add("quoteOrderQty", req.QuoteOrderQty)
+	}
 	if req.Price != "" {
 		params.Add("price", req.Price)
 	}
 	if req.NewClientOrderID != "" {
 		params.Add("newClientOrderId", req.NewClientOrderID)
 	}
+	if c.Margin() {
+		params.Add("sideEffectType", c.sideEffect(req.Side))
+	}
+	endpoint = c.route(endpoint, params)
 
 	params.Add("timestamp", strconv.FormatInt(c.serverTime(), 10))
 	params.Add("recvWindow", "60000")
@@ -212,7 +300,7 @@ func (c *BinanceClient) CreateOrder(req OrderRequest) (*OrderResponse, error) {
 	// Simplest is often putting everything in the query string or encoded body.
 
 	// Let's use form-data body
-	reqURL := fmt.Sprintf("%s%s", c.BaseURL, endpoint)
+	reqURL := fmt.Sprintf("%s%s", c.Endpoint(), endpoint)
 
 	// While we can put params in URL, usually POST uses body.
 	// But Binance docs say: "parameters may be sent as a query string or in the request body".
@@ -230,6 +318,7 @@ func (c *BinanceClient) CreateOrder(req OrderRequest) (*OrderResponse, error) {
 
 	r.Header.Add("X-MBX-APIKEY", c.APIKey)
 
+	start := time.Now()
 	resp, err := c.Client.Do(r)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
@@ -240,6 +329,9 @@ func (c *BinanceClient) CreateOrder(req OrderRequest) (*OrderResponse, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
+	if c.OnOrderLatency != nil {
+		c.OnOrderLatency(time.Since(start))
+	}
 
 	if resp.StatusCode != http.StatusOK {
 		logger.Error("Binance Order Error", "status", resp.Status, "body", string(body))
@@ -259,13 +351,14 @@ func (c *BinanceClient) GetOrder(symbol, clientOrderID string) (*OrderResponse,
 	params := url.Values{}
 	params.Add("symbol", symbol)
 	params.Add("origClientOrderId", clientOrderID)
+	endpoint = c.route(endpoint, params)
 	params.Add("timestamp", strconv.FormatInt(c.serverTime(), 10))
 	params.Add("recvWindow", "60000")
 
 	signature := c.sign(params.Encode())
 	params.Add("signature", signature)
 
-	reqURL := fmt.Sprintf("%s%s?%s", c.BaseURL, endpoint, params.Encode())
+	reqURL := fmt.Sprintf("%s%s?%s", c.Endpoint(), endpoint, params.Encode())
 
 	req, err := http.NewRequest("GET", reqURL, nil)
 	if err != nil {
@@ -297,17 +390,21 @@ func (c *BinanceClient) GetOrder(symbol, clientOrderID string) (*OrderResponse,
 }
 
 func (c *BinanceClient) CancelOrder(symbol, clientOrderID string) (*OrderResponse, error) {
+	if c.ReadOnly {
+		return nil, ErrReadOnly
+	}
 	endpoint := "/api/v3/order"
 	params := url.Values{}
 	params.Add("symbol", symbol)
 	params.Add("origClientOrderId", clientOrderID)
+	endpoint = c.route(endpoint, params)
 	params.Add("timestamp", strconv.FormatInt(c.serverTime(), 10))
 	params.Add("recvWindow", "60000")
 
 	signature := c.sign(params.Encode())
 	params.Add("signature", signature)
 
-	reqURL := fmt.Sprintf("%s%s", c.BaseURL, endpoint)
+	reqURL := fmt.Sprintf("%s%s", c.Endpoint(), endpoint)
 
 	r, err := http.NewRequest("DELETE", reqURL, nil)
 	if err != nil {
@@ -338,17 +435,112 @@ func (c *BinanceClient) CancelOrder(symbol, clientOrderID string) (*OrderRespons
 	return &order, nil
 }
 
+// CancelReplaceResponse is returned by POST /api/v3/order/cancelReplace.
+// On partial failures (HTTP 400/409) the same structure comes inside the error's "data" field.
+type CancelReplaceResponse struct {
+	CancelResult     string         `json:"cancelResult"`   // SUCCESS | FAILURE | NOT_ATTEMPTED
+	NewOrderResult   string         `json:"newOrderResult"` // SUCCESS | FAILURE | NOT_ATTEMPTED
+	CancelResponse   *OrderResponse `json:"cancelResponse"`
+	NewOrderResponse *OrderResponse `json:"newOrderResponse"`
+}
+
+// CancelSucceeded reports whether the original order was canceled
+func (r *CancelReplaceResponse) CancelSucceeded() bool {
+	return r != nil && r.CancelResult == "SUCCESS"
+}
+
+// NewOrderSucceeded reports whether the replacement order was placed
+func (r *CancelReplaceResponse) NewOrderSucceeded() bool {
+	return r != nil && r.NewOrderResult == "SUCCESS" && r.NewOrderResponse != nil
+}
+
+// CancelReplaceOrder atomically cancels cancelClientOrderID and places req in a single call
+// (cancelReplaceMode=STOP_ON_FAILURE: the new order is only placed if the cancel succeeds).
+// Margin accounts have no cancelReplace: the cancel and the new order are two calls there.
+// On failure the parsed response is still returned (when available) so callers can tell
+// which side failed.
+func (c *BinanceClient) CancelReplaceOrder(cancelClientOrderID string, req OrderRequest) (*CancelReplaceResponse, error) {
+	if c.ReadOnly {
+		return nil, ErrReadOnly
+	}
+	if c.Margin() {
+		return c.marginCancelReplace(cancelClientOrderID, req)
+	}
+	endpoint := "/api/v3/order/cancelReplace"
+
+	params := url.Values{}
+	params.Add("symbol", req.Symbol)
+	params.Add("side", req.Side)
+	params.Add("type", req.Type)
+	params.Add("cancelReplaceMode", "STOP_ON_FAILURE")
+	params.Add("cancelOrigClientOrderId", cancelClientOrderID)
+	params.Add("newOrderRespType", "FULL")
+	if req.TimeInForce != "" {
+		params.Add("timeInForce", req.TimeInForce)
+	}
+	if req.Quantity != "" {
+		params.Add("quantity", req.Quantity)
+	}
+	if req.Price != "" {
+		params.Add("price", req.Price)
+	}
+	if req.NewClientOrderID != "" {
+		params.Add("newClientOrderId", req.NewClientOrderID)
+	}
+	params.Add("timestamp", strconv.FormatInt(c.serverTime(), 10))
+	params.Add("recvWindow", "60000")
+
+	signature := c.sign(params.Encode())
+	params.Add("signature", signature)
+
+	r, err := http.NewRequest("POST", fmt.Sprintf("%s%s", c.Endpoint(), endpoint), nil)
+	if err != nil {
+		return nil, err
+	}
+	r.URL.RawQuery = params.Encode()
+	r.Header.Add("X-MBX-APIKEY", c.APIKey)
+
+	resp, err := c.Client.Do(r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read error: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Code int                    `json:"code"`
+			Msg  string                 `json:"msg"`
+			Data *CancelReplaceResponse `json:"data"`
+		}
+		_ = json.Unmarshal(body, &apiErr)
+		logger.Error("Binance CancelReplace Error", "status", resp.Status, "body", string(body))
+		return apiErr.Data, fmt.Errorf("status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result CancelReplaceResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal error: %w", err)
+	}
+	return &result, nil
+}
+
 func (c *BinanceClient) GetOpenOrders(symbol string) ([]OrderResponse, error) {
 	endpoint := "/api/v3/openOrders"
 	params := url.Values{}
 	params.Add("symbol", symbol)
+	endpoint = c.route(endpoint, params)
 	params.Add("timestamp", strconv.FormatInt(c.serverTime(), 10))
 	params.Add("recvWindow", "60000")
 
 	signature := c.sign(params.Encode())
 	params.Add("signature", signature)
 
-	reqURL := fmt.Sprintf("%s%s?%s", c.BaseURL, endpoint, params.Encode())
+	reqURL := fmt.Sprintf("%s%s?%s", c.Endpoint(), endpoint, params.Encode())
 
 	req, err := http.NewRequest("GET", reqURL, nil)
 	if err != nil {
@@ -390,13 +582,15 @@ type ListenKeyResponse struct {
 }
 
 func (c *BinanceClient) StartUserStream() (string, error) {
-	endpoint := "/api/v3/userDataStream"
-	reqURL := fmt.Sprintf("%s%s", c.BaseURL, endpoint)
+	params := url.Values{}
+	endpoint := c.route("/api/v3/userDataStream", params)
+	reqURL := fmt.Sprintf("%s%s", c.Endpoint(), endpoint)
 
 	req, err := http.NewRequest("POST", reqURL, nil)
 	if err != nil {
 		return "", err
 	}
+	req.URL.RawQuery = params.Encode()
 	req.Header.Add("X-MBX-APIKEY", c.APIKey)
 
 	resp, err := c.Client.Do(req)
@@ -423,12 +617,11 @@ func (c *BinanceClient) StartUserStream() (string, error) {
 }
 
 func (c *BinanceClient) KeepAliveUserStream(listenKey string) error {
-	endpoint := "/api/v3/userDataStream"
-	reqURL := fmt.Sprintf("%s%s", c.BaseURL, endpoint)
-
 	// PUT request with listenKey
 	params := url.Values{}
 	params.Add("listenKey", listenKey)
+	endpoint := c.route("/api/v3/userDataStream", params)
+	reqURL := fmt.Sprintf("%s%s", c.Endpoint(), endpoint)
 
 	req, err := http.NewRequest("PUT", reqURL, nil)
 	if err != nil {
@@ -451,11 +644,10 @@ func (c *BinanceClient) KeepAliveUserStream(listenKey string) error {
 }
 
 func (c *BinanceClient) CloseUserStream(listenKey string) error {
-	endpoint := "/api/v3/userDataStream"
-	reqURL := fmt.Sprintf("%s%s", c.BaseURL, endpoint)
-
 	params := url.Values{}
 	params.Add("listenKey", listenKey)
+	endpoint := c.route("/api/v3/userDataStream", params)
+	reqURL := fmt.Sprintf("%s%s", c.Endpoint(), endpoint)
 
 	req, err := http.NewRequest("DELETE", reqURL, nil)
 	if err != nil {
@@ -487,7 +679,7 @@ type BookTickerResponse struct {
 
 func (c *BinanceClient) GetBookTicker(symbol string) (*BookTickerResponse, error) {
 	endpoint := "/api/v3/ticker/bookTicker"
-	reqURL := fmt.Sprintf("%s%s?symbol=%s", c.BaseURL, endpoint, symbol)
+	reqURL := fmt.Sprintf("%s%s?symbol=%s", c.Endpoint(), endpoint, symbol)
 
 	resp, err := c.Client.Get(reqURL)
 	if err != nil {
@@ -515,7 +707,7 @@ func (c *BinanceClient) GetBookTicker(symbol string) (*BookTickerResponse, error
 func (c *BinanceClient) GetExchangeInfo(symbol string) (*model.ExchangeInfoResponse, error) {
 	endpoint := "/api/v3/exchangeInfo"
 	// If symbol is provided, we can filter for efficiency
-	reqURL := fmt.Sprintf("%s%s", c.BaseURL, endpoint)
+	reqURL := fmt.Sprintf("%s%s", c.Endpoint(), endpoint)
 	if symbol != "" {
 		reqURL = fmt.Sprintf("%s?symbol=%s", reqURL, symbol)
 	}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// DepthResponse is the order book snapshot returned by GET /api/v3/depth. Each level is
+// [price, qty], best first.
+type DepthResponse struct {
+	LastUpdateID int64       `json:"lastUpdateId"`
+	Bids         [][2]string `json:"bids"`
+	Asks         [][2]string `json:"asks"`
+}
+
+// GetDepth returns the top limit levels of each side of the symbol's order book
+// (weight 5 up to 100 levels, more above)
+func (c *BinanceClient) GetDepth(symbol string, limit int) (*DepthResponse, error) {
+	endpoint := "/api/v3/depth"
+	reqURL := fmt.Sprintf("%s%s?symbol=%s&limit=%s", c.Endpoint(), endpoint, symbol, strconv.Itoa(limit))
+
+	resp, err := c.Client.Get(reqURL)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, string(body))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read error: %w", err)
+	}
+
+	var depth DepthResponse
+	if err := json.Unmarshal(body, &depth); err != nil {
+		return nil, fmt.Errorf("unmarshal error: %w", err)
+	}
+	return &depth, nil
+}
+
+// Volumes sums the quantity of the bid and ask levels of the snapshot
+func (d *DepthResponse) Volumes() (bidQty, askQty float64) {
+	for _, level := range d.Bids {
+		q, _ := strconv.ParseFloat(level[1], 64)
+		bidQty += q
+	}
+	for _, level := range d.Asks {
+		q, _ := strconv.ParseFloat(level[1], 64)
+		askQty += q
+	}
+	return bidQty, askQty
+}
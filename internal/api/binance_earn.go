@@ -0,0 +1,124 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// EarnProduct is a Simple Earn Flexible product from GET /sapi/v1/simple-earn/flexible/list
+type EarnProduct struct {
+	ProductID                  string `json:"productId"`
+	Asset                      string `json:"asset"`
+	LatestAnnualPercentageRate string `json:"latestAnnualPercentageRate"`
+	MinPurchaseAmount          string `json:"minPurchaseAmount"`
+	CanPurchase                bool   `json:"canPurchase"`
+	CanRedeem                  bool   `json:"canRedeem"`
+	IsSoldOut                  bool   `json:"isSoldOut"`
+}
+
+// EarnPosition is a Simple Earn Flexible holding from GET /sapi/v1/simple-earn/flexible/position
+type EarnPosition struct {
+	ProductID                  string `json:"productId"`
+	Asset                      string `json:"asset"`
+	TotalAmount                string `json:"totalAmount"`
+	LatestAnnualPercentageRate string `json:"latestAnnualPercentageRate"`
+	CumulativeTotalRewards     string `json:"cumulativeTotalRewards"` // Every reward paid on the position, in Asset
+	CanRedeem                  bool   `json:"canRedeem"`
+}
+
+// GetEarnProducts returns the Simple Earn Flexible products of asset
+func (c *BinanceClient) GetEarnProducts(asset string) ([]EarnProduct, error) {
+	params := url.Values{}
+	params.Add("asset", asset)
+	body, err := c.signedRequest("GET", "/sapi/v1/simple-earn/flexible/list", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var list struct {
+		Rows []EarnProduct `json:"rows"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("unmarshal error: %w", err)
+	}
+	return list.Rows, nil
+}
+
+// GetEarnPositions returns the Simple Earn Flexible holdings of asset
+func (c *BinanceClient) GetEarnPositions(asset string) ([]EarnPosition, error) {
+	params := url.Values{}
+	params.Add("asset", asset)
+	params.Add("size", "100")
+	body, err := c.signedRequest("GET", "/sapi/v1/simple-earn/flexible/position", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var list struct {
+		Rows []EarnPosition `json:"rows"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("unmarshal error: %w", err)
+	}
+	return list.Rows, nil
+}
+
+// SubscribeEarn moves amount from the Spot wallet into the Flexible product
+func (c *BinanceClient) SubscribeEarn(productID, amount string) error {
+	if c.ReadOnly {
+		return ErrReadOnly
+	}
+	params := url.Values{}
+	params.Add("productId", productID)
+	params.Add("amount", amount)
+	params.Add("sourceAccount", "SPOT")
+	body, err := c.signedRequest("POST", "/sapi/v1/simple-earn/flexible/subscribe", params)
+	if err != nil {
+		return fmt.Errorf("subscribe failed: %w", err)
+	}
+	return earnResult(body)
+}
+
+// RedeemEarn moves amount of the Flexible product back to the Spot wallet (all = the whole holding)
+func (c *BinanceClient) RedeemEarn(productID, amount string, all bool) error {
+	if c.ReadOnly {
+		return ErrReadOnly
+	}
+	params := url.Values{}
+	params.Add("productId", productID)
+	if all {
+		params.Add("redeemAll", "true")
+	} else {
+		params.Add("amount", amount)
+	}
+	params.Add("destAccount", "SPOT")
+	body, err := c.signedRequest("POST", "/sapi/v1/simple-earn/flexible/redeem", params)
+	if err != nil {
+		return fmt.Errorf("redeem failed: %w", err)
+	}
+	return earnResult(body)
+}
+
+// earnResult checks the success flag of a subscription or redemption
+func earnResult(body []byte) error {
+	var result struct {
+		Success bool `json:"success"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("unmarshal error: %w", err)
+	}
+	if !result.Success {
+		return fmt.Errorf("not accepted: %s", string(body))
+	}
+	return nil
+}
+
+// Amounts returns the holding, its cumulative rewards and its annual rate as numbers
+func (p EarnPosition) Amounts() (total, rewards, apr float64) {
+	total, _ = strconv.ParseFloat(p.TotalAmount, 64)
+	rewards, _ = strconv.ParseFloat(p.CumulativeTotalRewards, 64)
+	apr, _ = strconv.ParseFloat(p.LatestAnnualPercentageRate, 64)
+	return total, rewards, apr
+}
@@ -5,34 +5,53 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	"grid-trading-btc-binance/internal/logger"
 )
 
+// Kline is a candle of /api/v3/klines; prices and volume keep the decimal strings of Binance
 type Kline struct {
 	OpenTime  int64
 	Open      string
 	High      string
 	Low       string
 	Close     string
-	Volume    string
-	CloseTime int64
+	Volume    string // Base asset volume
+	CloseTime int64  // Last millisecond of the candle
 }
 
 func (c *BinanceClient) GetRecentKlines(symbol, interval string, limit int) ([]Kline, error) {
+	q := url.Values{}
+	q.Add("symbol", symbol)
+	q.Add("interval", interval)
+	q.Add("limit", strconv.Itoa(limit))
+	return c.getKlines(q)
+}
+
+// GetKlines returns up to limit (max 1000) candles opened at or after startMs
+// (endMs = 0 means up to now). Used to page through history.
+func (c *BinanceClient) GetKlines(symbol, interval string, startMs, endMs int64, limit int) ([]Kline, error) {
+	q := url.Values{}
+	q.Add("symbol", symbol)
+	q.Add("interval", interval)
+	q.Add("startTime", strconv.FormatInt(startMs, 10))
+	if endMs > 0 {
+		q.Add("endTime", strconv.FormatInt(endMs, 10))
+	}
+	q.Add("limit", strconv.Itoa(limit))
+	return c.getKlines(q)
+}
+
+func (c *BinanceClient) getKlines(q url.Values) ([]Kline, error) {
 	endpoint := "/api/v3/klines"
-	reqURL := fmt.Sprintf("%s%s", c.BaseURL, endpoint)
+	reqURL := fmt.Sprintf("%s%s", c.Endpoint(), endpoint)
 
 	req, err := http.NewRequest("GET", reqURL, nil)
 	if err != nil {
 		return nil, err
 	}
-
-	q := req.URL.Query()
-	q.Add("symbol", symbol)
-	q.Add("interval", interval)
-	q.Add("limit", strconv.Itoa(limit))
 	req.URL.RawQuery = q.Encode()
 
 	// No signature needed for public data, but using API Key is good practice
@@ -78,29 +97,50 @@ func (c *BinanceClient) GetRecentKlines(symbol, interval string, limit int) ([]K
 		return nil, fmt.Errorf("unmarshal error: %w", err)
 	}
 
-	var klines []Kline
-	for _, k := range rawKlines {
-		if len(k) < 7 {
-			continue
+	klines := make([]Kline, 0, len(rawKlines))
+	for i, raw := range rawKlines {
+		k, err := parseKline(raw)
+		if err != nil {
+			return nil, fmt.Errorf("kline %d: %w", i, err)
 		}
-		// Index 0: OpenTime
-		// Index 1: Open
-		// Index 2: High
-		// Index 3: Low
-		// Index 4: Close
-		ot, _ := k[0].(float64)
-		openPrice, _ := k[1].(string)
-		high, _ := k[2].(string)
-		low, _ := k[3].(string)
-		closePrice, _ := k[4].(string)
-
-		klines = append(klines, Kline{
-			OpenTime: int64(ot),
-			Open:     openPrice,
-			High:     high,
-			Low:      low,
-			Close:    closePrice,
-		})
+		klines = append(klines, k)
 	}
 	return klines, nil
 }
+
+// parseKline reads a kline row: [openTime, open, high, low, close, volume, closeTime, ...].
+// A missing or malformed field is an error rather than an empty value the volatility
+// estimators would read as a zero price.
+func parseKline(raw []interface{}) (Kline, error) {
+	if len(raw) < 7 {
+		return Kline{}, fmt.Errorf("%d fields, want at least 7", len(raw))
+	}
+	openTime, ok := raw[0].(float64)
+	if !ok {
+		return Kline{}, fmt.Errorf("open time %v is not a number", raw[0])
+	}
+	closeTime, ok := raw[6].(float64)
+	if !ok {
+		return Kline{}, fmt.Errorf("close time %v is not a number", raw[6])
+	}
+	var values [5]string // open, high, low, close, volume
+	for j := range values {
+		s, ok := raw[j+1].(string)
+		if !ok {
+			return Kline{}, fmt.Errorf("field %d (%v) is not a decimal string", j+1, raw[j+1])
+		}
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			return Kline{}, fmt.Errorf("field %d: %w", j+1, err)
+		}
+		values[j] = s
+	}
+	return Kline{
+		OpenTime:  int64(openTime),
+		Open:      values[0],
+		High:      values[1],
+		Low:       values[2],
+		Close:     values[3],
+		Volume:    values[4],
+		CloseTime: int64(closeTime),
+	}, nil
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseKline(t *testing.T) {
+	tests := []struct {
+		name    string
+		row     string
+		want    Kline
+		wantErr bool
+	}{
+		{
+			name: "valid row",
+			row:  `[1712345640000,"69000.10","69050.00","68990.00","69020.50","12.34500000",1712345699999,"852000.00",120,"6.1","421000.0","0"]`,
+			want: Kline{
+				OpenTime:  1712345640000,
+				Open:      "69000.10",
+				High:      "69050.00",
+				Low:       "68990.00",
+				Close:     "69020.50",
+				Volume:    "12.34500000",
+				CloseTime: 1712345699999,
+			},
+		},
+		{
+			name:    "fewer than 7 fields",
+			row:     `[1712345640000,"69000.10","69050.00","68990.00","69020.50","12.345"]`,
+			wantErr: true,
+		},
+		{
+			name:    "non-numeric open time",
+			row:     `["1712345640000","69000.10","69050.00","68990.00","69020.50","12.345",1712345699999]`,
+			wantErr: true,
+		},
+		{
+			name:    "non-numeric close time",
+			row:     `[1712345640000,"69000.10","69050.00","68990.00","69020.50","12.345",null]`,
+			wantErr: true,
+		},
+		{
+			name:    "price not a string",
+			row:     `[1712345640000,69000.10,"69050.00","68990.00","69020.50","12.345",1712345699999]`,
+			wantErr: true,
+		},
+		{
+			name:    "unparsable price",
+			row:     `[1712345640000,"69000.10","69050.00","68990.00","n/a","12.345",1712345699999]`,
+			wantErr: true,
+		},
+		{
+			name:    "volume not a string",
+			row:     `[1712345640000,"69000.10","69050.00","68990.00","69020.50",12.345,1712345699999]`,
+			wantErr: true,
+		},
+		{
+			name:    "unparsable volume",
+			row:     `[1712345640000,"69000.10","69050.00","68990.00","69020.50","",1712345699999]`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var raw []interface{}
+			if err := json.Unmarshal([]byte(tt.row), &raw); err != nil {
+				t.Fatalf("invalid test row: %v", err)
+			}
+			got, err := parseKline(raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseKline() = %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseKline() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("parseKline() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// Account types of the client (see SetAccount)
+const (
+	AccountSpot     = "spot"
+	AccountCross    = "cross"
+	AccountIsolated = "isolated"
+)
+
+// Spot endpoints with a margin counterpart (same parameters plus isIsolated)
+var marginEndpoints = map[string]string{
+	"/api/v3/order":          "/sapi/v1/margin/order",
+	"/api/v3/openOrders":     "/sapi/v1/margin/openOrders",
+	"/api/v3/myTrades":       "/sapi/v1/margin/myTrades",
+	"/api/v3/allOrders":      "/sapi/v1/margin/allOrders",
+	"/api/v3/userDataStream": "/sapi/v1/userDataStream",
+}
+
+// MarginAsset is one asset of a margin account
+type MarginAsset struct {
+	Asset    string `json:"asset"`
+	Free     string `json:"free"`
+	Locked   string `json:"locked"`
+	Borrowed string `json:"borrowed"`
+	Interest string `json:"interest"`
+	NetAsset string `json:"netAsset"`
+}
+
+// Debt returns what is owed on the asset (borrowed + interest)
+func (a MarginAsset) Debt() float64 {
+	borrowed, _ := strconv.ParseFloat(a.Borrowed, 64)
+	interest, _ := strconv.ParseFloat(a.Interest, 64)
+	return borrowed + interest
+}
+
+// MarginAccount is the cross margin account, or the isolated pair of the client's symbol
+type MarginAccount struct {
+	MarginLevel    float64 // Total assets / total liabilities (Binance liquidates near 1.1)
+	LiquidatePrice float64 // Isolated only
+	Assets         []MarginAsset
+}
+
+// SetAccount makes the client trade on a margin account: orders, open orders, trades and the
+// user data stream go to the /sapi/v1/margin endpoints (isolated: for symbol only), buys borrow
+// the missing quote asset when autoBorrow is set and sells repay the debt. accountType spot
+// restores the Spot endpoints.
+func (c *BinanceClient) SetAccount(accountType, symbol string, autoBorrow bool) {
+	c.AccountType = accountType
+	c.MarginSymbol = symbol
+	c.MarginAutoBorrow = autoBorrow
+}
+
+// Margin reports whether the client trades on a margin account
+func (c *BinanceClient) Margin() bool {
+	return c.AccountType == AccountCross || c.AccountType == AccountIsolated
+}
+
+// route returns the endpoint to call for a spot endpoint: its margin counterpart on a margin
+// account, with isIsolated (and the symbol of the isolated user stream) added to params
+func (c *BinanceClient) route(endpoint string, params url.Values) string {
+	margin, ok := marginEndpoints[endpoint]
+	if !c.Margin() || !ok {
+		return endpoint
+	}
+	if c.AccountType == AccountIsolated {
+		if margin == "/sapi/v1/userDataStream" {
+			params.Set("symbol", c.MarginSymbol)
+			return margin + "/isolated"
+		}
+		params.Set("isIsolated", "TRUE")
+	}
+	return margin
+}
+
+// sideEffect is the margin sideEffectType of an order: sells repay the debt, buys borrow what is
+// missing only with auto-borrow
+func (c *BinanceClient) sideEffect(side string) string {
+	switch {
+	case side == "SELL":
+		return "AUTO_REPAY"
+	case c.MarginAutoBorrow:
+		return "MARGIN_BUY"
+	default:
+		return "NO_SIDE_EFFECT"
+	}
+}
+
+// GetMarginAccount returns the cross margin account, or the isolated pair of the client's symbol
+func (c *BinanceClient) GetMarginAccount() (*MarginAccount, error) {
+	if c.AccountType == AccountIsolated {
+		params := url.Values{}
+		params.Add("symbols", c.MarginSymbol)
+		body, err := c.signedRequest("GET", "/sapi/v1/margin/isolated/account", params)
+		if err != nil {
+			return nil, err
+		}
+		var isolated struct {
+			Assets []struct {
+				Symbol         string      `json:"symbol"`
+				MarginLevel    string      `json:"marginLevel"`
+				LiquidatePrice string      `json:"liquidatePrice"`
+				BaseAsset      MarginAsset `json:"baseAsset"`
+				QuoteAsset     MarginAsset `json:"quoteAsset"`
+			} `json:"assets"`
+		}
+		if err := json.Unmarshal(body, &isolated); err != nil {
+			return nil, fmt.Errorf("unmarshal error: %w", err)
+		}
+		for _, pair := range isolated.Assets {
+			if pair.Symbol != c.MarginSymbol {
+				continue
+			}
+			account := &MarginAccount{Assets: []MarginAsset{pair.BaseAsset, pair.QuoteAsset}}
+			account.MarginLevel, _ = strconv.ParseFloat(pair.MarginLevel, 64)
+			account.LiquidatePrice, _ = strconv.ParseFloat(pair.LiquidatePrice, 64)
+			return account, nil
+		}
+		return nil, fmt.Errorf("isolated margin pair %s not found (enable it on Binance first)", c.MarginSymbol)
+	}
+
+	body, err := c.signedRequest("GET", "/sapi/v1/margin/account", url.Values{})
+	if err != nil {
+		return nil, err
+	}
+	var cross struct {
+		MarginLevel string        `json:"marginLevel"`
+		UserAssets  []MarginAsset `json:"userAssets"`
+	}
+	if err := json.Unmarshal(body, &cross); err != nil {
+		return nil, fmt.Errorf("unmarshal error: %w", err)
+	}
+	account := &MarginAccount{Assets: cross.UserAssets}
+	account.MarginLevel, _ = strconv.ParseFloat(cross.MarginLevel, 64)
+	return account, nil
+}
+
+// MarginMaxBorrowable returns how much of asset the account can still borrow
+func (c *BinanceClient) MarginMaxBorrowable(asset string) (float64, error) {
+	params := url.Values{}
+	params.Add("asset", asset)
+	if c.AccountType == AccountIsolated {
+		params.Add("isolatedSymbol", c.MarginSymbol)
+	}
+	body, err := c.signedRequest("GET", "/sapi/v1/margin/maxBorrowable", params)
+	if err != nil {
+		return 0, err
+	}
+	var resp struct {
+		Amount string `json:"amount"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return 0, fmt.Errorf("unmarshal error: %w", err)
+	}
+	amount, _ := strconv.ParseFloat(resp.Amount, 64)
+	return amount, nil
+}
+
+// MarginBorrow borrows amount of asset on the margin account
+func (c *BinanceClient) MarginBorrow(asset, amount string) error {
+	return c.marginBorrowRepay("BORROW", asset, amount)
+}
+
+// MarginRepay repays amount of asset (principal and interest) on the margin account
+func (c *BinanceClient) MarginRepay(asset, amount string) error {
+	return c.marginBorrowRepay("REPAY", asset, amount)
+}
+
+func (c *BinanceClient) marginBorrowRepay(kind, asset, amount string) error {
+	if c.ReadOnly {
+		return ErrReadOnly
+	}
+	params := url.Values{}
+	params.Add("asset", asset)
+	params.Add("amount", amount)
+	params.Add("type", kind)
+	params.Add("isIsolated", "FALSE")
+	params.Add("symbol", c.MarginSymbol)
+	if c.AccountType == AccountIsolated {
+		params.Set("isIsolated", "TRUE")
+	}
+	_, err := c.signedRequest("POST", "/sapi/v1/margin/borrow-repay", params)
+	return err
+}
+
+// marginBalances replaces the Spot balances of info with the margin account's
+func (c *BinanceClient) marginBalances(info *AccountInfoResponse) error {
+	account, err := c.GetMarginAccount()
+	if err != nil {
+		return err
+	}
+	info.Balances = info.Balances[:0]
+	for _, a := range account.Assets {
+		info.Balances = append(info.Balances, BalanceResponse{Asset: a.Asset, Free: a.Free, Locked: a.Locked})
+	}
+	return nil
+}
+
+// marginCancelReplace emulates cancelReplace (STOP_ON_FAILURE) on a margin account with a cancel
+// followed by the new order. Not atomic: a fill between the two calls fails the cancel, as on Spot.
+func (c *BinanceClient) marginCancelReplace(cancelClientOrderID string, req OrderRequest) (*CancelReplaceResponse, error) {
+	result := &CancelReplaceResponse{CancelResult: "FAILURE", NewOrderResult: "NOT_ATTEMPTED"}
+	canceled, err := c.CancelOrder(req.Symbol, cancelClientOrderID)
+	if err != nil {
+		return result, err
+	}
+	result.CancelResult = "SUCCESS"
+	result.CancelResponse = canceled
+
+	order, err := c.CreateOrder(req)
+	if err != nil {
+		result.NewOrderResult = "FAILURE"
+		return result, err
+	}
+	result.NewOrderResult = "SUCCESS"
+	result.NewOrderResponse = order
+	return result, nil
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// Sub-account transfer directions (GET /sapi/v1/sub-account/transfer/subUserHistory)
+const (
+	SubAccountTransferIn  = 1
+	SubAccountTransferOut = 2
+)
+
+// SubAccountTransfer is a transfer in or out of the sub-account, as seen by the sub-account
+type SubAccountTransfer struct {
+	TranID          int64  `json:"tranId"`
+	Type            int    `json:"type"` // SubAccountTransferIn | SubAccountTransferOut
+	CounterParty    string `json:"counterParty"`
+	Email           string `json:"email"`
+	Asset           string `json:"asset"`
+	Qty             string `json:"qty"`
+	FromAccountType string `json:"fromAccountType"`
+	ToAccountType   string `json:"toAccountType"`
+	Status          string `json:"status"` // SUCCESS once settled
+	Time            int64  `json:"time"`
+}
+
+// SubAccountWallet returns the wallet of the sub-account the grid trades on, as named by the
+// sub-account transfer endpoints
+func SubAccountWallet(accountType string) string {
+	switch accountType {
+	case AccountCross:
+		return "MARGIN"
+	case AccountIsolated:
+		return "ISOLATED_MARGIN"
+	}
+	return "SPOT"
+}
+
+// SubAccountToMaster moves asset from the Spot wallet of the sub-account (the client's keys)
+// to the Spot wallet of its master account, and returns the transfer id
+func (c *BinanceClient) SubAccountToMaster(asset, amount string) (string, error) {
+	if c.ReadOnly {
+		return "", ErrReadOnly
+	}
+	params := url.Values{}
+	params.Add("asset", asset)
+	params.Add("amount", amount)
+
+	body, err := c.signedRequest("POST", "/sapi/v1/sub-account/transfer/subToMaster", params)
+	if err != nil {
+		return "", fmt.Errorf("transfer to master failed: %w", err)
+	}
+
+	var transfer struct {
+		TxnID json.Number `json:"txnId"`
+	}
+	if err := json.Unmarshal(body, &transfer); err != nil {
+		return "", fmt.Errorf("unmarshal error: %w", err)
+	}
+	return transfer.TxnID.String(), nil
+}
+
+// SubAccountTopUp moves asset from the Spot wallet of the master account (the client's keys)
+// to the wallet of the sub-account email (symbol: the pair of an isolated margin wallet), and
+// returns the transfer id. Requires "Permits Universal Transfer" on the master key.
+func (c *BinanceClient) SubAccountTopUp(email, wallet, symbol, asset, amount string) (int64, error) {
+	if c.ReadOnly {
+		return 0, ErrReadOnly
+	}
+	params := url.Values{}
+	params.Add("toEmail", email)
+	params.Add("fromAccountType", "SPOT")
+	params.Add("toAccountType", wallet)
+	if wallet == "ISOLATED_MARGIN" {
+		params.Add("symbol", symbol)
+	}
+	params.Add("asset", asset)
+	params.Add("amount", amount)
+
+	body, err := c.signedRequest("POST", "/sapi/v1/sub-account/universalTransfer", params)
+	if err != nil {
+		return 0, fmt.Errorf("transfer to sub-account failed: %w", err)
+	}
+
+	var transfer TransferResponse
+	if err := json.Unmarshal(body, &transfer); err != nil {
+		return 0, fmt.Errorf("unmarshal error: %w", err)
+	}
+	return transfer.TranID, nil
+}
+
+// GetSubAccountTransfers returns the transfers in and out of the sub-account since startTimeMs
+// (Binance keeps 30 days per query)
+func (c *BinanceClient) GetSubAccountTransfers(startTimeMs int64) ([]SubAccountTransfer, error) {
+	params := url.Values{}
+	if startTimeMs > 0 {
+		params.Add("startTime", strconv.FormatInt(startTimeMs, 10))
+	}
+	params.Add("limit", "500")
+
+	body, err := c.signedRequest("GET", "/sapi/v1/sub-account/transfer/subUserHistory", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var transfers []SubAccountTransfer
+	if err := json.Unmarshal(body, &transfers); err != nil {
+		return nil, fmt.Errorf("unmarshal error: %w", err)
+	}
+	return transfers, nil
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// AccountTrade is a single execution returned by GET /api/v3/myTrades
+type AccountTrade struct {
+	Symbol          string `json:"symbol"`
+	ID              int64  `json:"id"`
+	OrderID         int64  `json:"orderId"`
+	Price           string `json:"price"`
+	Qty             string `json:"qty"`
+	QuoteQty        string `json:"quoteQty"`
+	Commission      string `json:"commission"`
+	CommissionAsset string `json:"commissionAsset"`
+	Time            int64  `json:"time"`
+	IsBuyer         bool   `json:"isBuyer"`
+	IsMaker         bool   `json:"isMaker"`
+}
+
+// GetMyTrades returns account trades for the symbol. When fromID > 0 trades with id >= fromID
+// are returned, otherwise trades since startTimeMs (Binance limits that window to 24h).
+func (c *BinanceClient) GetMyTrades(symbol string, fromID, startTimeMs int64, limit int) ([]AccountTrade, error) {
+	params := url.Values{}
+	params.Add("symbol", symbol)
+	if fromID > 0 {
+		params.Add("fromId", strconv.FormatInt(fromID, 10))
+	} else if startTimeMs > 0 {
+		params.Add("startTime", strconv.FormatInt(startTimeMs, 10))
+	}
+	if limit > 0 {
+		params.Add("limit", strconv.Itoa(limit))
+	}
+
+	body, err := c.signedRequest("GET", c.route("/api/v3/myTrades", params), params)
+	if err != nil {
+		return nil, err
+	}
+
+	var trades []AccountTrade
+	if err := json.Unmarshal(body, &trades); err != nil {
+		return nil, fmt.Errorf("unmarshal error: %w", err)
+	}
+	return trades, nil
+}
+
+// GetOrderTrades returns every trade of one order (myTrades?orderId=)
+func (c *BinanceClient) GetOrderTrades(symbol string, orderID int64) ([]AccountTrade, error) {
+	params := url.Values{}
+	params.Add("symbol", symbol)
+	params.Add("orderId", strconv.FormatInt(orderID, 10))
+
+	body, err := c.signedRequest("GET", c.route("/api/v3/myTrades", params), params)
+	if err != nil {
+		return nil, err
+	}
+
+	var trades []AccountTrade
+	if err := json.Unmarshal(body, &trades); err != nil {
+		return nil, fmt.Errorf("unmarshal error: %w", err)
+	}
+	return trades, nil
+}
+
+// GetOrdersBetween returns orders (any status) created between startMs and endMs, oldest first.
+// Binance allows at most 24h between the two. Weight 20, against 4 for a GetOrder.
+func (c *BinanceClient) GetOrdersBetween(symbol string, startMs, endMs int64, limit int) ([]OrderResponse, error) {
+	params := url.Values{}
+	params.Add("symbol", symbol)
+	params.Add("startTime", strconv.FormatInt(startMs, 10))
+	params.Add("endTime", strconv.FormatInt(endMs, 10))
+	if limit > 0 {
+		params.Add("limit", strconv.Itoa(limit))
+	}
+
+	body, err := c.signedRequest("GET", c.route("/api/v3/allOrders", params), params)
+	if err != nil {
+		return nil, err
+	}
+
+	var orders []OrderResponse
+	if err := json.Unmarshal(body, &orders); err != nil {
+		return nil, fmt.Errorf("unmarshal error: %w", err)
+	}
+	return orders, nil
+}
+
+// GetAllOrders returns orders (any status) with orderId >= fromOrderID
+func (c *BinanceClient) GetAllOrders(symbol string, fromOrderID int64, limit int) ([]OrderResponse, error) {
+	params := url.Values{}
+	params.Add("symbol", symbol)
+	if fromOrderID > 0 {
+		params.Add("orderId", strconv.FormatInt(fromOrderID, 10))
+	}
+	if limit > 0 {
+		params.Add("limit", strconv.Itoa(limit))
+	}
+
+	body, err := c.signedRequest("GET", c.route("/api/v3/allOrders", params), params)
+	if err != nil {
+		return nil, err
+	}
+
+	var orders []OrderResponse
+	if err := json.Unmarshal(body, &orders); err != nil {
+		return nil, fmt.Errorf("unmarshal error: %w", err)
+	}
+	return orders, nil
+}
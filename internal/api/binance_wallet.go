@@ -0,0 +1,170 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// Universal transfer types (see Binance /sapi/v1/asset/transfer)
+const (
+	TransferSpotToFunding = "MAIN_FUNDING"
+)
+
+type TransferResponse struct {
+	TranID int64 `json:"tranId"`
+}
+
+// UniversalTransfer moves assets between the user's own wallets (e.g. Spot -> Funding).
+// Requires "Permits Universal Transfer" on the API key.
+func (c *BinanceClient) UniversalTransfer(transferType, asset, amount string) (*TransferResponse, error) {
+	if c.ReadOnly {
+		return nil, ErrReadOnly
+	}
+	params := url.Values{}
+	params.Add("type", transferType)
+	params.Add("asset", asset)
+	params.Add("amount", amount)
+
+	body, err := c.signedRequest("POST", "/sapi/v1/asset/transfer", params)
+	if err != nil {
+		return nil, fmt.Errorf("transfer failed: %w", err)
+	}
+
+	var transfer TransferResponse
+	if err := json.Unmarshal(body, &transfer); err != nil {
+		return nil, fmt.Errorf("unmarshal error: %w", err)
+	}
+	return &transfer, nil
+}
+
+// Deposit/withdraw statuses that mean the funds reached (or left) the account
+const (
+	DepositStatusSuccess    = 1
+	DepositStatusCredited   = 6 // Credited but cannot be withdrawn yet
+	WithdrawStatusCompleted = 6
+)
+
+// DepositRecord is an entry of GET /sapi/v1/capital/deposit/hisrec
+type DepositRecord struct {
+	ID         string `json:"id"`
+	Amount     string `json:"amount"`
+	Coin       string `json:"coin"`
+	Network    string `json:"network"`
+	Status     int    `json:"status"`
+	TxID       string `json:"txId"`
+	InsertTime int64  `json:"insertTime"`
+}
+
+// WithdrawRecord is an entry of GET /sapi/v1/capital/withdraw/history
+type WithdrawRecord struct {
+	ID             string `json:"id"`
+	Amount         string `json:"amount"`
+	TransactionFee string `json:"transactionFee"`
+	Coin           string `json:"coin"`
+	Network        string `json:"network"`
+	Status         int    `json:"status"`
+	TxID           string `json:"txId"`
+	ApplyTime      string `json:"applyTime"`    // "2006-01-02 15:04:05" (UTC)
+	CompleteTime   string `json:"completeTime"` // Same format, empty until completed
+}
+
+// GetDepositHistory returns deposits since startTimeMs (Binance caps the window at 90 days)
+func (c *BinanceClient) GetDepositHistory(startTimeMs int64) ([]DepositRecord, error) {
+	params := url.Values{}
+	if startTimeMs > 0 {
+		params.Add("startTime", strconv.FormatInt(startTimeMs, 10))
+	}
+
+	body, err := c.signedRequest("GET", "/sapi/v1/capital/deposit/hisrec", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var deposits []DepositRecord
+	if err := json.Unmarshal(body, &deposits); err != nil {
+		return nil, fmt.Errorf("unmarshal error: %w", err)
+	}
+	return deposits, nil
+}
+
+// GetWithdrawHistory returns withdrawals since startTimeMs (Binance caps the window at 90 days)
+func (c *BinanceClient) GetWithdrawHistory(startTimeMs int64) ([]WithdrawRecord, error) {
+	params := url.Values{}
+	if startTimeMs > 0 {
+		params.Add("startTime", strconv.FormatInt(startTimeMs, 10))
+	}
+
+	body, err := c.signedRequest("GET", "/sapi/v1/capital/withdraw/history", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var withdrawals []WithdrawRecord
+	if err := json.Unmarshal(body, &withdrawals); err != nil {
+		return nil, fmt.Errorf("unmarshal error: %w", err)
+	}
+	return withdrawals, nil
+}
+
+// DustAsset is an asset the account can convert to BNB (POST /sapi/v1/asset/dust-btc)
+type DustAsset struct {
+	Asset      string `json:"asset"`
+	AmountFree string `json:"amountFree"`
+	ToBNB      string `json:"toBNB"` // Estimated BNB received, before the service charge
+}
+
+// DustTransfer is the conversion of one asset in a dust transfer
+type DustTransfer struct {
+	FromAsset           string `json:"fromAsset"`
+	Amount              string `json:"amount"`              // Converted, in FromAsset
+	TransferedAmount    string `json:"transferedAmount"`    // BNB received
+	ServiceChargeAmount string `json:"serviceChargeAmount"` // BNB charged
+	TranID              int64  `json:"tranId"`
+	OperateTime         int64  `json:"operateTime"`
+}
+
+type DustResponse struct {
+	TotalServiceCharge string         `json:"totalServiceCharge"`
+	TotalTransfered    string         `json:"totalTransfered"`
+	TransferResult     []DustTransfer `json:"transferResult"`
+}
+
+// GetDustAssets lists the balances small enough to be converted to BNB
+func (c *BinanceClient) GetDustAssets() ([]DustAsset, error) {
+	body, err := c.signedRequest("POST", "/sapi/v1/asset/dust-btc", url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Details []DustAsset `json:"details"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("unmarshal error: %w", err)
+	}
+	return resp.Details, nil
+}
+
+// ConvertDust converts the whole free balance of assets to BNB (Binance allows one conversion
+// every 6 hours)
+func (c *BinanceClient) ConvertDust(assets []string) (*DustResponse, error) {
+	if c.ReadOnly {
+		return nil, ErrReadOnly
+	}
+	params := url.Values{}
+	for _, asset := range assets {
+		params.Add("asset", asset)
+	}
+	body, err := c.signedRequest("POST", "/sapi/v1/asset/dust", params)
+	if err != nil {
+		return nil, fmt.Errorf("dust transfer failed: %w", err)
+	}
+
+	var resp DustResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("unmarshal error: %w", err)
+	}
+	return &resp, nil
+}
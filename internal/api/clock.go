@@ -0,0 +1,135 @@
+package api
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"grid-trading-btc-binance/internal/crash"
+	"grid-trading-btc-binance/internal/logger"
+)
+
+const (
+	DefaultTimeBias    = time.Second // Timestamps signed this much behind the server clock
+	clockSamples       = 64          // Date headers kept for the drift estimate
+	clockMinSamples    = 8
+	clockMaxUncertain  = 500 * time.Millisecond // Estimates vaguer than this are not judged
+	clockCheckInterval = time.Minute
+)
+
+// clockEstimator estimates the server clock offset from the Date header of the responses. A Date
+// has a 1s resolution, but each response bounds the offset between Date - received and
+// Date + 1s - sent; the intersection of many narrows to about a round trip.
+type clockEstimator struct {
+	mu      sync.Mutex
+	samples [clockSamples]struct{ lo, hi int64 } // Offset bounds (ms)
+	count   int
+	next    int
+}
+
+// observe records the Date of a response to a call sent and received at the local times given
+func (e *clockEstimator) observe(sent, received time.Time, resp *http.Response) {
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.samples[e.next].lo = date.UnixMilli() - received.UnixMilli()
+	e.samples[e.next].hi = date.UnixMilli() + 1000 - sent.UnixMilli()
+	e.next = (e.next + 1) % clockSamples
+	if e.count < clockSamples {
+		e.count++
+	}
+}
+
+// estimate intersects the bounds from the newest sample back, stopping at the first one that
+// disagrees (the local clock was stepped): returns the offset (ms), its uncertainty and the
+// samples used
+func (e *clockEstimator) estimate() (offset int64, uncertainty time.Duration, used int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var lo, hi int64
+	for i := 0; i < e.count; i++ {
+		s := e.samples[(e.next-1-i+clockSamples)%clockSamples]
+		if i == 0 {
+			lo, hi = s.lo, s.hi
+		} else if s.lo > hi || s.hi < lo {
+			break
+		} else {
+			lo, hi = max(lo, s.lo), min(hi, s.hi)
+		}
+		used++
+	}
+	return (lo + hi) / 2, time.Duration(hi-lo) * time.Millisecond / 2, used
+}
+
+// ClockStatus compares the offset the client signs with to the one the responses show
+type ClockStatus struct {
+	Offset      int64         // Applied server - local offset (ms), from SyncTime
+	Estimated   int64         // Offset from the Date headers (ms)
+	Uncertainty time.Duration // Of Estimated
+	Samples     int
+	Drift       time.Duration // Estimated - Offset
+}
+
+// Known reports whether enough consistent responses back the estimate
+func (s ClockStatus) Known() bool {
+	return s.Samples >= clockMinSamples && s.Uncertainty <= clockMaxUncertain
+}
+
+// ClockStatus returns the drift of the local clock since the last SyncTime
+func (c *BinanceClient) ClockStatus() ClockStatus {
+	estimated, uncertainty, samples := c.requests.clock.estimate()
+	offset := atomic.LoadInt64(&c.TimeOffset)
+	return ClockStatus{
+		Offset:      offset,
+		Estimated:   estimated,
+		Uncertainty: uncertainty,
+		Samples:     samples,
+		Drift:       time.Duration(estimated-offset) * time.Millisecond,
+	}
+}
+
+// ClockGuard checks the drift every minute. Past MaxDrift the signed timestamps are at risk
+// (-1021 "outside of the recvWindow", or a bias that no longer keeps them behind the server):
+// it synchronizes the time again and reports it.
+type ClockGuard struct {
+	Client   *BinanceClient
+	MaxDrift time.Duration
+	OnDrift  func(status ClockStatus, err error) // After every resync (err: it failed); nil = only logged
+}
+
+// Start checks the drift in background
+func (g *ClockGuard) Start() {
+	crash.Go("clock guard", func() {
+		logger.Info("⏰ Starting clock drift guard", "max_drift", g.MaxDrift.String(), "bias", g.Client.TimeBias.String())
+		ticker := time.NewTicker(clockCheckInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			g.check()
+		}
+	})
+}
+
+func (g *ClockGuard) check() {
+	status := g.Client.ClockStatus()
+	if !status.Known() || status.Drift.Abs() <= g.MaxDrift {
+		return
+	}
+	logger.Warn("⏰ Clock drift detected, synchronizing the time again", "drift", status.Drift.String(),
+		"offset_ms", status.Offset, "estimated_ms", status.Estimated, "uncertainty", status.Uncertainty.String())
+	err := g.Client.SyncTime()
+	if err != nil {
+		logger.Error("❌ Time resync failed: signed calls may be rejected", "drift", status.Drift.String(), "error", err)
+	}
+	if g.OnDrift != nil {
+		g.OnDrift(status, err)
+	}
+}
+
+// SetTimeBias sets the TimeBias of the futures signed calls
+func (f *FuturesClient) SetTimeBias(bias time.Duration) {
+	f.c.TimeBias = bias
+}
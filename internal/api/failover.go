@@ -0,0 +1,273 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"grid-trading-btc-binance/internal/crash"
+	"grid-trading-btc-binance/internal/logger"
+)
+
+const (
+	probeWindow           = 5 // Probes kept per host; also the healthy probes the primary needs to fail back
+	probeTimeout          = 5 * time.Second
+	probeMinLatencies     = 3 // Successful probes before the latency of a host is judged
+	probeMaxFailures      = 2 // Failed probes in the window that make a host unhealthy
+	failoverMinErrorCalls = 5 // REST calls before the live error rate is judged
+)
+
+// HostStatus is the probe summary of a REST host
+type HostStatus struct {
+	URL      string `json:"url"`
+	Latency  string `json:"latency"` // Median of the successful probes ("" without any)
+	Failures int    `json:"failures"`
+	Probes   int    `json:"probes"`
+}
+
+// EndpointStatus is the failover state shown by the health endpoints
+type EndpointStatus struct {
+	Active  string       `json:"active"`
+	Primary string       `json:"primary"`
+	Since   *time.Time   `json:"since,omitempty"`  // When the client left the primary
+	Reason  string       `json:"reason,omitempty"` // Why
+	Hosts   []HostStatus `json:"hosts"`
+}
+
+type probe struct {
+	latency time.Duration
+	err     error
+}
+
+// Failover pings the primary REST host and the alternate clusters, moves the client to the
+// fastest healthy alternate when the primary degrades (failed probes, median latency above
+// MaxLatency, or REST error rate above MaxErrorRate) and back once the primary passes a full
+// window of probes again.
+type Failover struct {
+	Client       *BinanceClient
+	Primary      string
+	Alternates   []string
+	Interval     time.Duration
+	MaxLatency   time.Duration
+	MaxErrorRate float64
+	OnSwitch     func(from, to, reason string) // Called after every switch (nil = only logged)
+
+	mu         sync.Mutex
+	probes     map[string][]probe
+	healthyRun int // Consecutive healthy checks of the primary while on an alternate
+	since      time.Time
+	reason     string
+	prober     *http.Client
+}
+
+// NewFailover builds the failover of client: its BaseURL is the primary, api.binance.com and
+// the clusters are the alternates. Call it after SetTransport: the probes share the transport.
+func NewFailover(client *BinanceClient, interval, maxLatency time.Duration, maxErrorRate float64) *Failover {
+	hosts := []string{BaseURL}
+	for _, host := range restClusters {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts[1:])
+
+	f := &Failover{
+		Client:       client,
+		Primary:      client.BaseURL,
+		Interval:     interval,
+		MaxLatency:   maxLatency,
+		MaxErrorRate: maxErrorRate,
+		probes:       make(map[string][]probe),
+		prober:       &http.Client{Timeout: probeTimeout, Transport: client.requests.next},
+	}
+	for _, host := range hosts {
+		if host != f.Primary {
+			f.Alternates = append(f.Alternates, host)
+		}
+	}
+	return f
+}
+
+// Start probes the hosts every Interval in background
+func (f *Failover) Start() {
+	crash.Go("endpoint failover", func() {
+		logger.Info("🌐 Starting REST endpoint failover", "primary", f.Primary, "alternates", len(f.Alternates),
+			"interval", f.Interval.String(), "max_latency", f.MaxLatency.String(), "max_error_rate", f.MaxErrorRate)
+		ticker := time.NewTicker(f.Interval)
+		defer ticker.Stop()
+		for ; ; <-ticker.C {
+			f.check()
+		}
+	})
+}
+
+// Status returns the active host and the probe summary of every host
+func (f *Failover) Status() EndpointStatus {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	status := EndpointStatus{Active: f.Client.Endpoint(), Primary: f.Primary, Reason: f.reason}
+	if status.Active != f.Primary {
+		since := f.since
+		status.Since = &since
+	}
+	for _, host := range append([]string{f.Primary}, f.Alternates...) {
+		h := HostStatus{URL: host, Probes: len(f.probes[host])}
+		var latencies []time.Duration
+		for _, p := range f.probes[host] {
+			if p.err != nil {
+				h.Failures++
+			} else {
+				latencies = append(latencies, p.latency)
+			}
+		}
+		if len(latencies) > 0 {
+			h.Latency = median(latencies).Round(time.Millisecond).String()
+		}
+		status.Hosts = append(status.Hosts, h)
+	}
+	return status
+}
+
+// check runs one round of probes and switches the client when needed. Skipped in the 429/418
+// protection mode: the limits are per IP, another host would not help and the pings count.
+func (f *Failover) check() {
+	if f.Client.RateLimited() {
+		return
+	}
+	hosts := append([]string{f.Primary}, f.Alternates...)
+	results := make([]probe, len(hosts))
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = f.probe(host)
+		}()
+	}
+	wg.Wait()
+
+	f.mu.Lock()
+	for i, host := range hosts {
+		window := append(f.probes[host], results[i])
+		if len(window) > probeWindow {
+			window = window[len(window)-probeWindow:]
+		}
+		f.probes[host] = window
+	}
+	active := f.Client.Endpoint()
+	primaryOK, why := f.healthy(f.Primary)
+
+	var to, reason string
+	switch {
+	case active == f.Primary:
+		stats := f.Client.RequestStats()
+		if primaryOK && time.Since(f.since) > stats.Window && stats.Requests >= failoverMinErrorCalls && stats.ErrorRate() > f.MaxErrorRate {
+			primaryOK, why = false, fmt.Sprintf("REST error rate %.0f%% (max %.0f%%)", stats.ErrorRate()*100, f.MaxErrorRate*100)
+		}
+		if !primaryOK {
+			if to = f.best(); to == "" {
+				logger.Warn("⚠️ Primary REST endpoint degraded, no healthy alternate", "primary", f.Primary, "reason", why)
+			}
+			reason = why
+		}
+	case primaryOK:
+		f.healthyRun++
+		if ok, _ := f.healthy(active); f.healthyRun >= probeWindow || !ok {
+			to, reason = f.Primary, "primary recovered"
+		}
+	default:
+		f.healthyRun = 0
+		if ok, activeWhy := f.healthy(active); !ok {
+			if best := f.best(); best != "" && best != active {
+				to, reason = best, activeWhy
+			}
+		}
+	}
+	if to == "" {
+		f.mu.Unlock()
+		return
+	}
+
+	if to == f.Primary {
+		f.Client.SetEndpoint("")
+		f.reason = ""
+	} else {
+		f.Client.SetEndpoint(to)
+		f.reason = reason
+	}
+	f.since, f.healthyRun = time.Now(), 0
+	f.mu.Unlock()
+
+	if to == f.Primary {
+		logger.Info("🌐 REST endpoint failed back to the primary", "from", active, "to", to)
+	} else {
+		logger.Warn("🌐 REST endpoint failover", "from", active, "to", to, "reason", reason)
+	}
+	if f.OnSwitch != nil {
+		f.OnSwitch(active, to, reason)
+	}
+}
+
+// probe measures a GET /api/v3/ping of host
+func (f *Failover) probe(host string) probe {
+	start := time.Now()
+	resp, err := f.prober.Get(host + "/api/v3/ping")
+	if err != nil {
+		return probe{err: err}
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return probe{err: fmt.Errorf("status %d", resp.StatusCode)}
+	}
+	return probe{latency: time.Since(start)}
+}
+
+// healthy judges the probe window of host. Needs mu.
+func (f *Failover) healthy(host string) (bool, string) {
+	var failures int
+	var latencies []time.Duration
+	for _, p := range f.probes[host] {
+		if p.err != nil {
+			failures++
+		} else {
+			latencies = append(latencies, p.latency)
+		}
+	}
+	if failures >= probeMaxFailures {
+		return false, fmt.Sprintf("%d of the last %d probes failed", failures, len(f.probes[host]))
+	}
+	if len(latencies) >= probeMinLatencies && median(latencies) > f.MaxLatency {
+		return false, fmt.Sprintf("median latency %s (max %s)", median(latencies).Round(time.Millisecond), f.MaxLatency)
+	}
+	return true, ""
+}
+
+// best returns the healthy alternate with the lowest median latency ("" = none). Needs mu.
+func (f *Failover) best() string {
+	var best string
+	var bestLatency time.Duration
+	for _, host := range f.Alternates {
+		if ok, _ := f.healthy(host); !ok {
+			continue
+		}
+		var latencies []time.Duration
+		for _, p := range f.probes[host] {
+			if p.err == nil {
+				latencies = append(latencies, p.latency)
+			}
+		}
+		if len(latencies) == 0 {
+			continue
+		}
+		if latency := median(latencies); best == "" || latency < bestLatency {
+			best, bestLatency = host, latency
+		}
+	}
+	return best
+}
+
+func median(values []time.Duration) time.Duration {
+	sorted := append([]time.Duration(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[len(sorted)/2]
+}
@@ -0,0 +1,211 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const FuturesBaseURL = "https://fapi.binance.com"
+
+// FuturesClient is the USDⓈ-M futures sub-client used by the hedge: same API keys and signing
+// as the spot client, with its own base URL, clock offset and endpoints. Assumes the one-way
+// position mode.
+type FuturesClient struct {
+	ReadOnly bool // Refuse every call that places orders or changes the leverage (MONITOR_ONLY)
+
+	c *BinanceClient
+}
+
+// FuturesPosition is the position of a symbol from GET /fapi/v2/positionRisk
+// (PositionAmt < 0 = short)
+type FuturesPosition struct {
+	Symbol           string `json:"symbol"`
+	PositionAmt      string `json:"positionAmt"`
+	EntryPrice       string `json:"entryPrice"`
+	MarkPrice        string `json:"markPrice"`
+	UnRealizedProfit string `json:"unRealizedProfit"`
+	LiquidationPrice string `json:"liquidationPrice"`
+	Leverage         string `json:"leverage"`
+}
+
+// FuturesOrderRequest is a MARKET order on the futures account
+type FuturesOrderRequest struct {
+	Symbol           string
+	Side             string
+	Quantity         string
+	ReduceOnly       bool
+	NewClientOrderID string
+}
+
+type FuturesOrderResponse struct {
+	Symbol        string `json:"symbol"`
+	OrderId       int64  `json:"orderId"`
+	ClientOrderId string `json:"clientOrderId"`
+	Status        string `json:"status"`
+	Side          string `json:"side"`
+	AvgPrice      string `json:"avgPrice"`
+	ExecutedQty   string `json:"executedQty"`
+	CumQuote      string `json:"cumQuote"`
+}
+
+// FuturesIncome is an entry of the futures income history (REALIZED_PNL, COMMISSION,
+// FUNDING_FEE...), in Asset
+type FuturesIncome struct {
+	Symbol     string `json:"symbol"`
+	IncomeType string `json:"incomeType"`
+	Income     string `json:"income"`
+	Asset      string `json:"asset"`
+	Time       int64  `json:"time"`
+}
+
+// FuturesFilters are the quantity filters of a futures symbol
+type FuturesFilters struct {
+	StepSize    float64
+	MinQty      float64
+	MinNotional float64
+}
+
+func NewFuturesClient(apiKey, secretKey string) *FuturesClient {
+	c := NewBinanceClient(apiKey, secretKey)
+	c.BaseURL = FuturesBaseURL
+	return &FuturesClient{c: c}
+}
+
+// SyncTime synchronizes the local time with the futures server time
+func (f *FuturesClient) SyncTime() error {
+	return f.c.syncTime("/fapi/v1/time")
+}
+
+// SetLeverage sets the initial leverage of symbol
+func (f *FuturesClient) SetLeverage(symbol string, leverage int) error {
+	if f.ReadOnly {
+		return ErrReadOnly
+	}
+	params := url.Values{}
+	params.Add("symbol", symbol)
+	params.Add("leverage", strconv.Itoa(leverage))
+	_, err := f.c.signedRequest("POST", "/fapi/v1/leverage", params)
+	return err
+}
+
+// GetPosition returns the one-way position of symbol (zero PositionAmt when flat)
+func (f *FuturesClient) GetPosition(symbol string) (*FuturesPosition, error) {
+	params := url.Values{}
+	params.Add("symbol", symbol)
+	body, err := f.c.signedRequest("GET", "/fapi/v2/positionRisk", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var positions []FuturesPosition
+	if err := json.Unmarshal(body, &positions); err != nil {
+		return nil, fmt.Errorf("unmarshal error: %w", err)
+	}
+	for _, p := range positions {
+		if p.Symbol == symbol {
+			return &p, nil
+		}
+	}
+	return &FuturesPosition{Symbol: symbol, PositionAmt: "0"}, nil
+}
+
+// CreateOrder places a MARKET order and returns its result (average price, executed quantity)
+func (f *FuturesClient) CreateOrder(req FuturesOrderRequest) (*FuturesOrderResponse, error) {
+	if f.ReadOnly {
+		return nil, ErrReadOnly
+	}
+	params := url.Values{}
+	params.Add("symbol", req.Symbol)
+	params.Add("side", req.Side)
+	params.Add("type", "MARKET")
+	params.Add("quantity", req.Quantity)
+	if req.ReduceOnly {
+		params.Add("reduceOnly", "true")
+	}
+	if req.NewClientOrderID != "" {
+		params.Add("newClientOrderId", req.NewClientOrderID)
+	}
+	params.Add("newOrderRespType", "RESULT")
+
+	body, err := f.c.signedRequest("POST", "/fapi/v1/order", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var order FuturesOrderResponse
+	if err := json.Unmarshal(body, &order); err != nil {
+		return nil, fmt.Errorf("unmarshal error: %w", err)
+	}
+	return &order, nil
+}
+
+// GetIncome returns the income history of symbol since startTimeMs (up to 1000 entries)
+func (f *FuturesClient) GetIncome(symbol string, startTimeMs int64) ([]FuturesIncome, error) {
+	params := url.Values{}
+	params.Add("symbol", symbol)
+	params.Add("startTime", strconv.FormatInt(startTimeMs, 10))
+	params.Add("limit", "1000")
+	body, err := f.c.signedRequest("GET", "/fapi/v1/income", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var income []FuturesIncome
+	if err := json.Unmarshal(body, &income); err != nil {
+		return nil, fmt.Errorf("unmarshal error: %w", err)
+	}
+	return income, nil
+}
+
+// GetFilters returns the MARKET order quantity filters of symbol from the futures exchangeInfo
+func (f *FuturesClient) GetFilters(symbol string) (*FuturesFilters, error) {
+	resp, err := f.c.Client.Get(f.c.Endpoint() + "/fapi/v1/exchangeInfo")
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read error: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var info struct {
+		Symbols []struct {
+			Symbol  string `json:"symbol"`
+			Filters []struct {
+				FilterType string `json:"filterType"`
+				StepSize   string `json:"stepSize"`
+				MinQty     string `json:"minQty"`
+				Notional   string `json:"notional"`
+			} `json:"filters"`
+		} `json:"symbols"`
+	}
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, fmt.Errorf("unmarshal error: %w", err)
+	}
+	for _, s := range info.Symbols {
+		if s.Symbol != symbol {
+			continue
+		}
+		var filters FuturesFilters
+		for _, filter := range s.Filters {
+			switch filter.FilterType {
+			case "MARKET_LOT_SIZE":
+				filters.StepSize, _ = strconv.ParseFloat(filter.StepSize, 64)
+				filters.MinQty, _ = strconv.ParseFloat(filter.MinQty, 64)
+			case "MIN_NOTIONAL":
+				filters.MinNotional, _ = strconv.ParseFloat(filter.Notional, 64)
+			}
+		}
+		return &filters, nil
+	}
+	return nil, fmt.Errorf("futures symbol %s not found", symbol)
+}
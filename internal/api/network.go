@@ -0,0 +1,96 @@
+package api
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	defaultIdleConnsPerHost = 16
+	defaultIdleConnTimeout  = 90 * time.Second
+	tlsSessionCacheSize     = 64
+)
+
+// defaultTransport is the connection pool of the clients until SetTransport
+var defaultTransport = NewTransport(TransportOptions{})
+
+// TransportOptions tune the connection pool of the REST clients (zero values = defaults)
+type TransportOptions struct {
+	MaxIdleConnsPerHost int           // Idle keep-alive connections kept per host (default 16)
+	IdleConnTimeout     time.Duration // Default 90s
+	HTTP2               bool          // Negotiate HTTP/2 (default HTTP/1.1)
+	Proxy               *url.URL      // http:// or socks5:// (nil = HTTPS_PROXY/NO_PROXY like any Go program)
+}
+
+// restClusters are the alternate hosts of the Spot REST API (BINANCE_CLUSTER): same API and
+// keys, other routes, for the networks where api.binance.com is slow or unreachable
+var restClusters = map[string]string{
+	"api1": "https://api1.binance.com",
+	"api2": "https://api2.binance.com",
+	"api3": "https://api3.binance.com",
+	"api4": "https://api4.binance.com",
+	"gcp":  "https://api-gcp.binance.com",
+}
+
+// ClusterURL returns the base URL of a REST cluster (false for an unknown name)
+func ClusterURL(cluster string) (string, bool) {
+	base, ok := restClusters[cluster]
+	return base, ok
+}
+
+// Endpoint returns the REST host the calls go to: BaseURL, or the alternate the failover
+// switched to (see SetEndpoint)
+func (c *BinanceClient) Endpoint() string {
+	if active, ok := c.active.Load().(string); ok && active != "" {
+		return active
+	}
+	return c.BaseURL
+}
+
+// SetEndpoint sends the next REST calls to base ("" = back to BaseURL)
+func (c *BinanceClient) SetEndpoint(base string) {
+	c.active.Store(base)
+}
+
+// NewTransport builds a connection pool for the REST clients: enough idle connections per host
+// for the calls the queue sends at once, and TLS session resumption for the connections it
+// opens, so a burst of signed calls does not pay a full handshake each. Share one between the
+// clients (SetTransport): the pool is per host.
+func NewTransport(opts TransportOptions) *http.Transport {
+	if opts.MaxIdleConnsPerHost <= 0 {
+		opts.MaxIdleConnsPerHost = defaultIdleConnsPerHost
+	}
+	if opts.IdleConnTimeout <= 0 {
+		opts.IdleConnTimeout = defaultIdleConnTimeout
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = 4 * opts.MaxIdleConnsPerHost
+	transport.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+	transport.IdleConnTimeout = opts.IdleConnTimeout
+	transport.TLSClientConfig = &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ClientSessionCache: tls.NewLRUClientSessionCache(tlsSessionCacheSize),
+	}
+	transport.ForceAttemptHTTP2 = opts.HTTP2
+	if !opts.HTTP2 {
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{} // HTTP/1.1 only
+	}
+	if opts.Proxy != nil {
+		transport.Proxy = http.ProxyURL(opts.Proxy)
+	}
+	return transport
+}
+
+// SetTransport sends the REST calls through transport (see NewTransport). Call it before
+// NewFailover: the probes share it.
+func (c *BinanceClient) SetTransport(transport http.RoundTripper) {
+	c.requests.next = transport
+}
+
+// SetTransport sends the futures REST calls through transport (see BinanceClient.SetTransport)
+func (f *FuturesClient) SetTransport(transport http.RoundTripper) {
+	f.c.SetTransport(transport)
+}
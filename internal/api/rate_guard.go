@@ -0,0 +1,168 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"grid-trading-btc-binance/internal/logger"
+)
+
+const (
+	rateLimitDefaultWait = time.Minute     // 429 without Retry-After
+	banDefaultWait       = 2 * time.Minute // 418 without Retry-After (the shortest ban)
+	rateLimitCooldown    = 2 * time.Minute // Essential calls only, after the Retry-After of a 429
+	banCooldown          = 15 * time.Minute
+)
+
+// ErrRateLimited is returned, without calling Binance, by the calls refused in protection mode
+// (see RateLimitStatus)
+var ErrRateLimited = errors.New("binance rate limit protection: call not sent")
+
+// RateLimitStatus is the protection mode entered on a 429 (request rate exceeded) or a 418 (IP
+// banned for hammering after 429s). Until Until no call is sent; until ProtectedUntil only the
+// essential ones are: placing and cancelling orders, and the user stream keep-alive. The bot
+// follows the fills and prices through the WebSockets meanwhile.
+type RateLimitStatus struct {
+	Active         bool      `json:"active"`
+	Status         int       `json:"status,omitempty"` // 429 | 418
+	Path           string    `json:"path,omitempty"`   // The call that got it
+	Since          time.Time `json:"since,omitempty"`
+	Until          time.Time `json:"until,omitempty"` // End of the Retry-After
+	ProtectedUntil time.Time `json:"protected_until,omitempty"`
+}
+
+// Ban reports whether the protection comes from an IP ban (418)
+func (s RateLimitStatus) Ban() bool {
+	return s.Status == http.StatusTeapot
+}
+
+// rateGuard wraps the HTTP transport: it enters the protection mode on a 429 or 418 and refuses
+// the calls the mode does not allow, so that a rate limit does not become a ban, nor a short ban
+// a multi-day one
+type rateGuard struct {
+	next     http.RoundTripper
+	onChange func(RateLimitStatus) // Called on entering, extending and leaving the mode
+
+	mu     sync.Mutex
+	status RateLimitStatus
+	lift   *time.Timer
+}
+
+func (g *rateGuard) RoundTrip(req *http.Request) (*http.Response, error) {
+	if status := g.current(); status.Active {
+		now := time.Now()
+		if now.Before(status.Until) {
+			return nil, fmt.Errorf("%w (HTTP %d, retry after %s)", ErrRateLimited, status.Status, status.Until.Format(time.RFC3339))
+		}
+		if !essentialCall(req) {
+			return nil, fmt.Errorf("%w (non-essential calls paused until %s)", ErrRateLimited, status.ProtectedUntil.Format(time.RFC3339))
+		}
+	}
+
+	resp, err := g.next.RoundTrip(req)
+	if err == nil && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusTeapot) {
+		g.enter(resp.StatusCode, retryAfter(resp), req.URL.Path)
+	}
+	return resp, err
+}
+
+// essentialCall reports whether req is allowed once the Retry-After is over: orders, cancels
+// and the listen key of the user stream. Every GET (klines, syncs, account info) waits.
+func essentialCall(req *http.Request) bool {
+	if req.Method == http.MethodGet {
+		return false
+	}
+	path := req.URL.Path
+	return strings.Contains(path, "/order") || strings.HasSuffix(path, "/openOrders") || strings.Contains(path, "userDataStream")
+}
+
+// retryAfter reads the Retry-After header (seconds), with the defaults of the status without it
+func retryAfter(resp *http.Response) time.Duration {
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if resp.StatusCode == http.StatusTeapot {
+		return banDefaultWait
+	}
+	return rateLimitDefaultWait
+}
+
+func (g *rateGuard) current() RateLimitStatus {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.status
+}
+
+// enter starts or extends the protection mode. A 418 during a 429 protection upgrades it.
+func (g *rateGuard) enter(code int, wait time.Duration, path string) {
+	now := time.Now()
+	cooldown := rateLimitCooldown
+	if code == http.StatusTeapot {
+		cooldown = banCooldown
+	}
+
+	g.mu.Lock()
+	status := g.status
+	if !status.Active {
+		status = RateLimitStatus{Active: true, Since: now}
+	}
+	if code == http.StatusTeapot || !status.Ban() {
+		status.Status = code
+	}
+	status.Path = path
+	if until := now.Add(wait); until.After(status.Until) {
+		status.Until = until
+	}
+	if protected := status.Until.Add(cooldown); protected.After(status.ProtectedUntil) {
+		status.ProtectedUntil = protected
+	}
+	changed := status != g.status
+	g.status = status
+	if g.lift != nil {
+		g.lift.Stop()
+	}
+	g.lift = time.AfterFunc(time.Until(status.ProtectedUntil), g.leave)
+	g.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	logger.Error("🚫 Binance rate limit hit, REST calls paused", "status", code, "path", path,
+		"retry_after", wait.String(), "until", status.Until.Format(time.RFC3339), "essential_only_until", status.ProtectedUntil.Format(time.RFC3339))
+	if g.onChange != nil {
+		g.onChange(status)
+	}
+}
+
+// leave ends the protection mode once ProtectedUntil is over
+func (g *rateGuard) leave() {
+	g.mu.Lock()
+	status := g.status
+	if !status.Active || time.Now().Before(status.ProtectedUntil) {
+		g.mu.Unlock()
+		return
+	}
+	g.status, g.lift = RateLimitStatus{}, nil
+	g.mu.Unlock()
+
+	logger.Info("✅ Binance rate limit protection lifted, REST calls resumed", "status", status.Status, "since", status.Since.Format(time.RFC3339))
+	if g.onChange != nil {
+		g.onChange(RateLimitStatus{Status: status.Status, Since: status.Since})
+	}
+}
+
+// RateLimit returns the protection mode of the client (Active false outside it)
+func (c *BinanceClient) RateLimit() RateLimitStatus {
+	return c.guard.current()
+}
+
+// RateLimited reports whether the client is in protection mode: the periodic syncs skip their
+// round instead of logging refused calls
+func (c *BinanceClient) RateLimited() bool {
+	return c.guard.current().Active
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"grid-trading-btc-binance/internal/logger"
+)
+
+// Request priorities of the queue: lower runs first
+const (
+	PriorityOrder  = iota // P0: placing and cancelling orders, user stream keep-alive
+	PriorityStatus        // P1: order status checks (order, openOrders, allOrders, myTrades)
+	PriorityBulk          // P2: klines, account info, exchange info, wallet and earn calls
+	priorities
+)
+
+const (
+	queueMaxInFlight = 4    // REST calls sent at once; the others wait their turn by priority
+	weightLimit      = 6000 // REQUEST_WEIGHT per minute of the spot API
+)
+
+// ErrWeightPressure is returned, without calling Binance, by the low priority calls the weight of
+// the minute does not leave room for
+var ErrWeightPressure = errors.New("binance API weight pressure")
+
+// Used weight of the current minute above which a priority waits for the next minute
+// (P0 never waits)
+var weightHold = [priorities]int{PriorityOrder: weightLimit + 1, PriorityStatus: weightLimit * 9 / 10, PriorityBulk: weightLimit * 3 / 4}
+
+// requestQueue wraps the HTTP transport and sends at most queueMaxInFlight calls at once, the
+// waiting ones by priority and then arrival, so that the periodic syncs cannot starve an exit
+// placement. Under weight pressure the status checks and the bulk calls wait for the weight to
+// reset at the next minute.
+type requestQueue struct {
+	next   http.RoundTripper
+	weight func() (int, time.Time) // Last X-MBX-USED-WEIGHT-1M and when
+
+	mu       sync.Mutex
+	inFlight int
+	waiting  [priorities][]*queuedRequest
+	heldAt   time.Time // Last weight hold logged
+}
+
+type queuedRequest struct {
+	ready   chan struct{}
+	granted bool
+}
+
+// requestPriority classifies a REST call
+func requestPriority(req *http.Request) int {
+	path := req.URL.Path
+	switch {
+	case essentialCall(req):
+		return PriorityOrder
+	case strings.HasSuffix(path, "/order") || strings.HasSuffix(path, "Orders") || strings.HasSuffix(path, "/myTrades"):
+		return PriorityStatus
+	}
+	return PriorityBulk
+}
+
+func (q *requestQueue) RoundTrip(req *http.Request) (*http.Response, error) {
+	priority := requestPriority(req)
+	if err := q.acquire(req, priority); err != nil {
+		return nil, err
+	}
+	defer q.release()
+	return q.next.RoundTrip(req)
+}
+
+// acquire waits for the weight to allow priority and for a free slot. A call that would time
+// out before the weight resets fails at once.
+func (q *requestQueue) acquire(req *http.Request, priority int) error {
+	if wait := q.hold(priority); wait > 0 {
+		if deadline, ok := req.Context().Deadline(); ok && time.Until(deadline) < wait {
+			return fmt.Errorf("%w: low priority call deferred to the next minute", ErrWeightPressure)
+		}
+		logger.Debug("⏳ REST call held by the weight pressure", "path", req.URL.Path, "priority", priority, "wait", wait.String())
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return fmt.Errorf("held by the API weight pressure: %w", req.Context().Err())
+		}
+	}
+
+	q.mu.Lock()
+	if q.inFlight < queueMaxInFlight && !q.waitingFrom(priority) {
+		q.inFlight++
+		q.mu.Unlock()
+		return nil
+	}
+	waiter := &queuedRequest{ready: make(chan struct{})}
+	q.waiting[priority] = append(q.waiting[priority], waiter)
+	q.mu.Unlock()
+
+	select {
+	case <-waiter.ready:
+		return nil
+	case <-req.Context().Done():
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		if waiter.granted {
+			q.inFlight--
+			q.grant()
+		} else {
+			q.remove(priority, waiter)
+		}
+		return fmt.Errorf("queued behind higher priority calls: %w", req.Context().Err())
+	}
+}
+
+// hold returns how long priority waits for the weight of the minute to reset (0 = no wait)
+func (q *requestQueue) hold(priority int) time.Duration {
+	used, at := q.weight()
+	now := time.Now()
+	minute := now.Truncate(time.Minute)
+	if used < weightHold[priority] || at.Before(minute) {
+		return 0
+	}
+	wait := minute.Add(time.Minute).Sub(now)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.heldAt.Before(minute) {
+		q.heldAt = now
+		logger.Warn("⚠️ API weight pressure: low priority REST calls wait for the next minute", "used", used, "limit", weightLimit, "priority", priority, "wait", wait.Round(time.Second).String())
+	}
+	return wait
+}
+
+func (q *requestQueue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.inFlight--
+	q.grant()
+}
+
+// grant hands the free slots to the waiting calls, highest priority first. Needs mu.
+func (q *requestQueue) grant() {
+	for priority := range q.waiting {
+		for q.inFlight < queueMaxInFlight && len(q.waiting[priority]) > 0 {
+			waiter := q.waiting[priority][0]
+			q.waiting[priority] = q.waiting[priority][1:]
+			waiter.granted = true
+			q.inFlight++
+			close(waiter.ready)
+		}
+	}
+}
+
+// waitingFrom reports whether a call of priority or higher is waiting. Needs mu.
+func (q *requestQueue) waitingFrom(priority int) bool {
+	for p := 0; p <= priority; p++ {
+		if len(q.waiting[p]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// remove drops a waiter whose call gave up. Needs mu.
+func (q *requestQueue) remove(priority int, waiter *queuedRequest) {
+	for i, w := range q.waiting[priority] {
+		if w == waiter {
+			q.waiting[priority] = append(q.waiting[priority][:i], q.waiting[priority][i+1:]...)
+			return
+		}
+	}
+}
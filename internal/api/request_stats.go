@@ -0,0 +1,180 @@
+package api
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	statsBucket  = time.Minute
+	statsBuckets = 5 // Rolling window of the REST error rate
+)
+
+// RequestStats are the REST calls of the rolling window and how many of them failed
+type RequestStats struct {
+	Window   time.Duration
+	Requests int
+	Errors   int
+}
+
+// ErrorRate returns Errors / Requests (0 without requests)
+func (s RequestStats) ErrorRate() float64 {
+	if s.Requests == 0 {
+		return 0
+	}
+	return float64(s.Errors) / float64(s.Requests)
+}
+
+// ConnTrace is the connection side of a REST call
+type ConnTrace struct {
+	Reused    bool          // Kept-alive connection: no DNS, connect nor handshake
+	Protocol  string        // HTTP/1.1 | HTTP/2.0
+	Connect   time.Duration // DNS + TCP connect (new connections)
+	Handshake time.Duration // TLS handshake (new connections)
+	Resumed   bool          // TLS session resumed: abbreviated handshake
+	FirstByte time.Duration // Request sent -> first response byte (the server and network time)
+}
+
+// requestCounter wraps the HTTP transport and counts the calls per minute. A failure is a
+// transport error or any status other than 2xx and 400: Binance answers expected business
+// rejections (insufficient balance, unknown order, filters) with 400.
+type requestCounter struct {
+	next http.RoundTripper
+
+	mu      sync.Mutex
+	buckets [statsBuckets]struct {
+		minute   int64
+		requests int
+		errors   int
+	}
+	weight   int       // Last X-MBX-USED-WEIGHT-1M seen
+	weightAt time.Time // When it was seen
+
+	onConn func(ConnTrace) // Called after every call that got a response
+	clock  clockEstimator  // Server clock from the Date headers
+}
+
+func newRequestCounter(next http.RoundTripper) *requestCounter {
+	if next == nil {
+		next = defaultTransport
+	}
+	return &requestCounter{next: next}
+}
+
+func (c *requestCounter) RoundTrip(req *http.Request) (*http.Response, error) {
+	var tracer *connTracer
+	if c.onConn != nil {
+		tracer = &connTracer{}
+		req = req.WithContext(httptrace.WithClientTrace(req.Context(), tracer.clientTrace()))
+	}
+
+	sent := time.Now()
+	resp, err := c.next.RoundTrip(req)
+	if err == nil {
+		c.clock.observe(sent, time.Now(), resp)
+		if tracer != nil {
+			c.onConn(tracer.result(resp.Proto))
+		}
+	}
+	failed := err != nil || (resp.StatusCode >= 300 && resp.StatusCode != http.StatusBadRequest)
+
+	minute := time.Now().Unix() / int64(statsBucket/time.Second)
+	c.mu.Lock()
+	b := &c.buckets[minute%statsBuckets]
+	if b.minute != minute {
+		b.minute, b.requests, b.errors = minute, 0, 0
+	}
+	b.requests++
+	if failed {
+		b.errors++
+	}
+	if resp != nil {
+		if weight, err := strconv.Atoi(resp.Header.Get("X-MBX-USED-WEIGHT-1M")); err == nil {
+			c.weight, c.weightAt = weight, time.Now()
+		}
+	}
+	c.mu.Unlock()
+	return resp, err
+}
+
+func (c *requestCounter) stats() RequestStats {
+	stats := RequestStats{Window: statsBuckets * statsBucket}
+	oldest := time.Now().Unix()/int64(statsBucket/time.Second) - statsBuckets + 1
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, b := range c.buckets {
+		if b.minute >= oldest {
+			stats.Requests += b.requests
+			stats.Errors += b.errors
+		}
+	}
+	return stats
+}
+
+// RequestStats returns the REST calls made in the last minutes and their failures
+func (c *BinanceClient) RequestStats() RequestStats {
+	return c.requests.stats()
+}
+
+// UsedWeight returns the request weight Binance last reported for the current minute
+// (limit 6000) and when. The weight resets every minute, so an old reading means ~0.
+func (c *BinanceClient) UsedWeight() (int, time.Time) {
+	c.requests.mu.Lock()
+	defer c.requests.mu.Unlock()
+	return c.requests.weight, c.requests.weightAt
+}
+
+// connTracer collects the ConnTrace of one call. Locked: a dial may finish in its own goroutine
+// after the call went out on another connection.
+type connTracer struct {
+	mu                        sync.Mutex
+	trace                     ConnTrace
+	connectStart, connectDone time.Time
+	tlsStart, wrote           time.Time
+}
+
+func (t *connTracer) clientTrace() *httptrace.ClientTrace {
+	at := func(f func(now time.Time)) {
+		now := time.Now()
+		t.mu.Lock()
+		f(now)
+		t.mu.Unlock()
+	}
+	return &httptrace.ClientTrace{
+		GotConn:  func(info httptrace.GotConnInfo) { at(func(time.Time) { t.trace.Reused = info.Reused }) },
+		DNSStart: func(httptrace.DNSStartInfo) { at(func(now time.Time) { t.connectStart = now }) },
+		ConnectStart: func(string, string) {
+			at(func(now time.Time) {
+				if t.connectStart.IsZero() {
+					t.connectStart = now
+				}
+			})
+		},
+		ConnectDone:       func(string, string, error) { at(func(now time.Time) { t.connectDone = now }) },
+		TLSHandshakeStart: func() { at(func(now time.Time) { t.tlsStart = now }) },
+		TLSHandshakeDone: func(state tls.ConnectionState, _ error) {
+			at(func(now time.Time) { t.trace.Handshake, t.trace.Resumed = now.Sub(t.tlsStart), state.DidResume })
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { at(func(now time.Time) { t.wrote = now }) },
+		GotFirstResponseByte: func() { at(func(now time.Time) { t.trace.FirstByte = now.Sub(t.wrote) }) },
+	}
+}
+
+func (t *connTracer) result(protocol string) ConnTrace {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	trace := t.trace
+	trace.Protocol = protocol
+	if !trace.Reused && !t.connectStart.IsZero() && t.connectDone.After(t.connectStart) {
+		trace.Connect = t.connectDone.Sub(t.connectStart)
+	}
+	if trace.Reused {
+		trace.Handshake, trace.Resumed = 0, false
+	}
+	return trace
+}
@@ -0,0 +1,130 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultDir holds one append-only JSONL file per day (audit-2006-01-02.jsonl)
+const DefaultDir = "logs/audit"
+
+// Kinds of entries
+const (
+	KindIntent     = "intent"     // The bot decided to place/cancel/transfer, and why
+	KindRequest    = "request"    // REST call that changes the account (sanitized)
+	KindResponse   = "response"   // Its answer, paired by request_id
+	KindTransition = "transition" // Transaction status change
+)
+
+const maxTrackedOrders = 10000 // Order -> correlation IDs kept in memory
+
+// Fields are the details of an entry
+type Fields map[string]interface{}
+
+// Entry is one line of the audit log. Everything about one position (its buy, its exit,
+// their requests and status changes) shares the correlation_id: the transaction ID.
+type Entry struct {
+	Seq           uint64    `json:"seq"`
+	Time          time.Time `json:"time"`
+	Kind          string    `json:"kind"`
+	CorrelationID string    `json:"correlation_id,omitempty"`
+	OrderID       string    `json:"order_id,omitempty"` // Client order ID the entry is about
+	RequestID     string    `json:"request_id,omitempty"`
+	Action        string    `json:"action"`
+	Fields        Fields    `json:"fields,omitempty"`
+}
+
+var (
+	mu          sync.Mutex
+	dir         string // "" = disabled
+	seq         atomic.Uint64
+	requests    atomic.Uint64
+	correlation = map[string]string{} // Client order ID -> transaction ID
+)
+
+// Init enables the audit log in path (created if missing)
+func Init(path string) error {
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return fmt.Errorf("failed to create audit dir: %w", err)
+	}
+	mu.Lock()
+	dir = path
+	mu.Unlock()
+	return nil
+}
+
+// Enabled reports whether Init was called
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return dir != ""
+}
+
+// Intent records a decision before its order is sent. orderID is linked to correlationID,
+// so the requests and responses about that order get the same correlation_id.
+func Intent(correlationID, orderID, action string, fields Fields) {
+	record(Entry{Kind: KindIntent, CorrelationID: correlationID, OrderID: orderID, Action: action, Fields: fields})
+}
+
+// Transition records a transaction status change
+func Transition(txID, from, to, reason string) {
+	record(Entry{Kind: KindTransition, CorrelationID: txID, Action: from + " -> " + to, Fields: Fields{"reason": reason}})
+}
+
+// Request records an API call and returns the ID that pairs it with its Response
+func Request(orderID, action string, fields Fields) string {
+	id := fmt.Sprintf("req-%d", requests.Add(1))
+	record(Entry{Kind: KindRequest, OrderID: orderID, RequestID: id, Action: action, Fields: fields})
+	return id
+}
+
+// Response records the answer to the request with requestID
+func Response(requestID, orderID, action string, fields Fields) {
+	record(Entry{Kind: KindResponse, OrderID: orderID, RequestID: requestID, Action: action, Fields: fields})
+}
+
+func record(entry Entry) {
+	mu.Lock()
+	defer mu.Unlock()
+	if dir == "" {
+		return
+	}
+
+	if entry.OrderID != "" {
+		if entry.CorrelationID != "" {
+			if len(correlation) >= maxTrackedOrders {
+				correlation = map[string]string{}
+			}
+			correlation[entry.OrderID] = entry.CorrelationID
+		} else {
+			entry.CorrelationID = correlation[entry.OrderID]
+		}
+	}
+	entry.Seq = seq.Add(1)
+	entry.Time = time.Now()
+
+	if err := appendLine(entry); err != nil {
+		// The app log is the fallback: the audit log must never stop trading
+		fmt.Fprintf(os.Stderr, "audit: %v\n", err)
+	}
+}
+
+func appendLine(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode entry: %w", err)
+	}
+	path := filepath.Join(dir, "audit-"+entry.Time.Format("2006-01-02")+".jsonl")
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,284 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"grid-trading-btc-binance/internal/api"
+	"grid-trading-btc-binance/internal/data"
+)
+
+const (
+	shortVolCandles  = 5
+	longVolCandles   = 20
+	highVolRatio     = 1.5   // Short vol above long vol * ratio -> HIGH_VOL regime
+	highVolThreshold = 0.002 // ... and above this absolute level
+)
+
+// Candle is a parsed kline
+type Candle struct {
+	OpenTime int64
+	Open     float64
+	High     float64
+	Low      float64
+	Close    float64
+}
+
+// FromKlines parses the REST klines into candles
+func FromKlines(klines []api.Kline) []Candle {
+	candles := make([]Candle, 0, len(klines))
+	for _, k := range klines {
+		o, _ := strconv.ParseFloat(k.Open, 64)
+		h, _ := strconv.ParseFloat(k.High, 64)
+		l, _ := strconv.ParseFloat(k.Low, 64)
+		c, _ := strconv.ParseFloat(k.Close, 64)
+		if o <= 0 || l <= 0 {
+			continue
+		}
+		candles = append(candles, Candle{OpenTime: k.OpenTime, Open: o, High: h, Low: l, Close: c})
+	}
+	return candles
+}
+
+// Settings are the inputs of a simulation that are not being optimized
+type Settings struct {
+	RangeMin        float64
+	RangeMax        float64
+	MakerFeePct     float64
+	MinSpacing      float64 // Dynamic spacing floor (fee model, MIN_DYNAMIC_SPACING_PCT), as in VolatilityService.GetDynamicSpacing
+	MaxSpacing      float64 // MAX_DYNAMIC_SPACING_PCT (0 = no ceiling)
+	MinOrderValue   float64
+	FallbackSpacing float64 // GRID_SPACING_PCT, used until there are enough candles for the volatility
+	InitialUSDT     float64
+	Interval        string // Candle interval, used to annualize the Sharpe ratio
+	WarmupCandles   int    // Leading candles only used for the volatility (no trading, not measured)
+}
+
+// Params are the grid parameters being evaluated
+type Params struct {
+	GridLevels        int
+	LowVolMultiplier  float64
+	HighVolMultiplier float64
+	PositionSizePct   float64
+}
+
+func (p Params) String() string {
+	return fmt.Sprintf("levels=%d low=%.2f high=%.2f size=%.4f", p.GridLevels, p.LowVolMultiplier, p.HighVolMultiplier, p.PositionSizePct)
+}
+
+// Result is the outcome of one simulation
+type Result struct {
+	Params         Params
+	FinalEquity    float64
+	TotalReturnPct float64
+	MaxDrawdownPct float64 // Positive number (10 = equity fell 10% from its peak)
+	Sharpe         float64 // Annualized, per-candle returns
+	Trades         int     // Round trips (buy + exit filled)
+	RealizedProfit float64
+}
+
+// lot is a grid position: an open buy or a filled buy waiting for its exit
+type lot struct {
+	buyPrice  float64
+	qty       float64
+	filled    bool
+	sellPrice float64
+	filledAt  int // Candle index of the fill (exits only fill on later candles)
+}
+
+// Run simulates the live grid over the candles. It mirrors placeNewGridOrders and
+// placeMakerExitOrder in simplified form: one maker buy per candle at the close when the
+// grid has no open buys or the price dropped the dynamic spacing below the lowest one,
+// filled when a later candle trades through it, with an exit at buy * (1 + spacing).
+// Stale-order expiry, repositioning and the crash circuit breaker are not simulated.
+func Run(candles []Candle, settings Settings, params Params) Result {
+	result := Result{Params: params, FinalEquity: settings.InitialUSDT}
+	if len(candles) == 0 || settings.InitialUSDT <= 0 {
+		return result
+	}
+
+	cash := settings.InitialUSDT // Free USDT (open buys have their value reserved)
+	var lots []*lot
+	equity := make([]float64, 0, len(candles))
+
+	for i, candle := range candles {
+		if i < settings.WarmupCandles {
+			continue
+		}
+
+		// 1. Fills on this candle (orders placed on earlier candles)
+		for _, l := range lots {
+			if !l.filled && candle.Low <= l.buyPrice {
+				l.filled = true
+				l.filledAt = i
+				l.sellPrice = l.buyPrice * (1 + spacingAt(candles, i, settings, params))
+			}
+		}
+		remaining := lots[:0]
+		for _, l := range lots {
+			if l.filled && l.filledAt < i && candle.High >= l.sellPrice {
+				revenue := l.sellPrice * l.qty * (1 - settings.MakerFeePct)
+				cost := l.buyPrice * l.qty * (1 + settings.MakerFeePct)
+				cash += revenue
+				result.RealizedProfit += revenue - cost
+				result.Trades++
+				continue
+			}
+			remaining = append(remaining, l)
+		}
+		lots = remaining
+
+		// 2. New entry at the close
+		price := candle.Close
+		spacing := spacingAt(candles, i, settings, params)
+		if l := nextBuy(lots, price, spacing, cash, settings, params); l != nil {
+			cash -= l.buyPrice * l.qty * (1 + settings.MakerFeePct)
+			lots = append(lots, l)
+		}
+
+		equity = append(equity, markToMarket(cash, lots, price, settings.MakerFeePct))
+	}
+
+	if len(equity) == 0 {
+		return result
+	}
+	result.FinalEquity = equity[len(equity)-1]
+	result.TotalReturnPct = (result.FinalEquity/settings.InitialUSDT - 1) * 100
+	result.MaxDrawdownPct = maxDrawdown(equity) * 100
+	result.Sharpe = sharpe(equity, periodsPerYear(settings.Interval))
+	return result
+}
+
+// nextBuy returns the buy to place at price, or nil when the grid rules say no
+func nextBuy(lots []*lot, price, spacing, cash float64, settings Settings, params Params) *lot {
+	if price < settings.RangeMin || price > settings.RangeMax || len(lots) >= params.GridLevels {
+		return nil
+	}
+
+	lowestOpen := 0.0
+	for _, l := range lots {
+		if !l.filled && (lowestOpen == 0 || l.buyPrice < lowestOpen) {
+			lowestOpen = l.buyPrice
+		}
+		// Anti-duplicate: nothing within half a spacing of an existing order or position
+		if math.Abs(l.buyPrice-price)/l.buyPrice < spacing*0.5 {
+			return nil
+		}
+	}
+	if lowestOpen > 0 && (lowestOpen-price)/lowestOpen < spacing {
+		return nil
+	}
+
+	value := math.Max(cash*params.PositionSizePct, settings.MinOrderValue)
+	if value > cash || value <= 0 {
+		return nil
+	}
+	return &lot{buyPrice: price, qty: value / price / (1 + settings.MakerFeePct)}
+}
+
+// spacingAt is the dynamic spacing the live VolatilityService would use at candle i
+func spacingAt(candles []Candle, i int, settings Settings, params Params) float64 {
+	if i+1 < longVolCandles {
+		return settings.FallbackSpacing
+	}
+	shortVol := garmanKlass(candles[i+1-shortVolCandles : i+1])
+	longVol := garmanKlass(candles[i+1-longVolCandles : i+1])
+
+	multiplier := params.LowVolMultiplier
+	if longVol > 0 && shortVol > longVol*highVolRatio && shortVol > highVolThreshold {
+		multiplier = params.HighVolMultiplier
+	}
+	if shortVol == 0 {
+		return settings.FallbackSpacing
+	}
+	spacing := shortVol * multiplier
+	if settings.MaxSpacing > 0 {
+		spacing = math.Min(spacing, settings.MaxSpacing)
+	}
+	return math.Max(spacing, settings.MinSpacing)
+}
+
+// garmanKlass is the same estimator as VolatilityService.calculateGK
+func garmanKlass(candles []Candle) float64 {
+	cons := 2.0*math.Log(2.0) - 1.0
+	sum := 0.0
+	for _, c := range candles {
+		sum += 0.5*math.Pow(math.Log(c.High/c.Low), 2) - cons*math.Pow(math.Log(c.Close/c.Open), 2)
+	}
+	if len(candles) == 0 || sum <= 0 {
+		return 0
+	}
+	return math.Sqrt(sum / float64(len(candles)))
+}
+
+// markToMarket values the cash, the USDT reserved in open buys and the held BTC (net of the exit fee)
+func markToMarket(cash float64, lots []*lot, price, fee float64) float64 {
+	equity := cash
+	for _, l := range lots {
+		if l.filled {
+			equity += l.qty * price * (1 - fee)
+		} else {
+			equity += l.buyPrice * l.qty * (1 + fee)
+		}
+	}
+	return equity
+}
+
+func maxDrawdown(equity []float64) float64 {
+	peak, worst := 0.0, 0.0
+	for _, e := range equity {
+		if e > peak {
+			peak = e
+		}
+		if peak > 0 {
+			if dd := (peak - e) / peak; dd > worst {
+				worst = dd
+			}
+		}
+	}
+	return worst
+}
+
+func sharpe(equity []float64, periods float64) float64 {
+	if len(equity) < 2 {
+		return 0
+	}
+	returns := make([]float64, 0, len(equity)-1)
+	for i := 1; i < len(equity); i++ {
+		if equity[i-1] > 0 {
+			returns = append(returns, equity[i]/equity[i-1]-1)
+		}
+	}
+	mean, std := meanStd(returns)
+	if std == 0 {
+		return 0
+	}
+	return mean / std * math.Sqrt(periods)
+}
+
+func meanStd(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
+
+// periodsPerYear converts a Binance interval (1m, 15m, 1h, 1d...) into candles per year
+func periodsPerYear(interval string) float64 {
+	d, err := data.IntervalDuration(interval)
+	if err != nil {
+		d = time.Minute
+	}
+	return float64(365*24*time.Hour) / float64(d)
+}
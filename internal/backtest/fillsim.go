@@ -0,0 +1,178 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+const fillSimBlock = 60 // Candles resampled together, so the paths keep the volatility clustering of an hour
+
+// FillSimSettings are the inputs of SimulateFills
+type FillSimSettings struct {
+	Spacing       float64 // Fixed grid spacing: drop between buys and exit target
+	Levels        int
+	MakerFeePct   float64
+	Capital       float64 // USDT split evenly between the levels
+	VolScale      float64 // Multiplier of the resampled candle moves (1 = as recorded)
+	CandlesPerDay int
+	Days          int // Length of each path
+	Paths         int
+}
+
+// FillSimResult are the expected fills and yield of a spacing/levels pair
+type FillSimResult struct {
+	Spacing          float64
+	Levels           int
+	BuysPerDay       float64 // Mean buy fills per day
+	RoundTripsPerDay float64 // Mean exits filled per day
+	RoundTripsP10    float64 // Percentiles of the round trips per day across the paths
+	RoundTripsP50    float64
+	RoundTripsP90    float64
+	NoTripPct        float64 // Paths without a single round trip, in %
+	NetPerTripPct    float64 // Profit of a round trip after both maker fees, % of the order
+	DailyYieldPct    float64 // Mean realized profit per day, % of the capital
+	OpenPnLPct       float64 // Mean unrealized result of the unsold positions at the end of a path, % of the capital
+	StuckLevels      float64 // Mean levels still holding BTC at the end of a path
+}
+
+// SimulateFills estimates how often a grid with a fixed spacing fills and what it yields, by
+// running the grid rules of Run over paths built from random hour blocks of the recent
+// candles (their moves scaled by VolScale to the volatility being assumed). The range, the
+// position sizing and the regimes are left out: every level is Capital/Levels.
+func SimulateFills(history []Candle, settings FillSimSettings, rng *rand.Rand) (FillSimResult, error) {
+	result := FillSimResult{Spacing: settings.Spacing, Levels: settings.Levels}
+	if len(history) < fillSimBlock {
+		return result, fmt.Errorf("%d candles, need at least %d", len(history), fillSimBlock)
+	}
+	if settings.Spacing <= 0 || settings.Levels <= 0 || settings.Capital <= 0 || settings.Days <= 0 || settings.Paths <= 0 {
+		return result, fmt.Errorf("spacing, levels, capital, days and paths must be positive")
+	}
+
+	gridSettings := Settings{
+		RangeMax:      math.MaxFloat64,
+		MakerFeePct:   settings.MakerFeePct,
+		MinOrderValue: settings.Capital / float64(settings.Levels),
+	}
+	params := Params{GridLevels: settings.Levels}
+	fee := settings.MakerFeePct
+
+	days := float64(settings.Days)
+	trips := make([]float64, settings.Paths)
+	for p := range trips {
+		path := resamplePath(history, settings.CandlesPerDay*settings.Days, settings.VolScale, rng)
+		var lots []*lot
+		buys, exits, profit := 0, 0, 0.0
+
+		// Same fill and entry rules as Run, with the spacing fixed
+		for i, candle := range path {
+			for _, l := range lots {
+				if !l.filled && candle.Low <= l.buyPrice {
+					l.filled, l.filledAt = true, i
+					l.sellPrice = l.buyPrice * (1 + settings.Spacing)
+					buys++
+				}
+			}
+			remaining := lots[:0]
+			for _, l := range lots {
+				if l.filled && l.filledAt < i && candle.High >= l.sellPrice {
+					profit += l.sellPrice*l.qty*(1-fee) - l.buyPrice*l.qty*(1+fee)
+					exits++
+					continue
+				}
+				remaining = append(remaining, l)
+			}
+			lots = remaining
+
+			if l := nextBuy(lots, candle.Close, settings.Spacing, settings.Capital, gridSettings, params); l != nil {
+				lots = append(lots, l)
+			}
+		}
+
+		last := path[len(path)-1].Close
+		for _, l := range lots {
+			if l.filled {
+				result.OpenPnLPct += l.qty*last*(1-fee) - l.buyPrice*l.qty*(1+fee)
+				result.StuckLevels++
+			}
+		}
+		result.BuysPerDay += float64(buys) / days
+		result.DailyYieldPct += profit / days
+		trips[p] = float64(exits) / days
+		if exits == 0 {
+			result.NoTripPct++
+		}
+	}
+
+	paths := float64(settings.Paths)
+	sort.Float64s(trips)
+	for _, t := range trips {
+		result.RoundTripsPerDay += t
+	}
+	result.RoundTripsPerDay /= paths
+	result.RoundTripsP10 = percentile(trips, 0.1)
+	result.RoundTripsP50 = percentile(trips, 0.5)
+	result.RoundTripsP90 = percentile(trips, 0.9)
+	result.BuysPerDay /= paths
+	result.NoTripPct = result.NoTripPct / paths * 100
+	result.NetPerTripPct = ((1+settings.Spacing)*(1-fee)/(1+fee) - 1) * 100
+	result.DailyYieldPct = result.DailyYieldPct / paths / settings.Capital * 100
+	result.OpenPnLPct = result.OpenPnLPct / paths / settings.Capital * 100
+	result.StuckLevels /= paths
+	return result, nil
+}
+
+// resamplePath chains random fillSimBlock-candle blocks of the history into a path of n candles
+// that starts at the last close: each candle keeps its high, low and close relative to its
+// open (the log moves times scale) and opens at the previous close
+func resamplePath(history []Candle, n int, scale float64, rng *rand.Rand) []Candle {
+	path := make([]Candle, 0, n)
+	price := history[len(history)-1].Close
+	for len(path) < n {
+		start := rng.Intn(len(history) - fillSimBlock + 1)
+		for _, c := range history[start : start+fillSimBlock] {
+			if len(path) == n {
+				break
+			}
+			next := Candle{
+				Open:  price,
+				High:  price * math.Exp(scale*math.Log(c.High/c.Open)),
+				Low:   price * math.Exp(scale*math.Log(c.Low/c.Open)),
+				Close: price * math.Exp(scale*math.Log(c.Close/c.Open)),
+			}
+			path = append(path, next)
+			price = next.Close
+		}
+	}
+	return path
+}
+
+// percentile of sorted values (nearest rank)
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	return sorted[min(int(p*float64(len(sorted))), len(sorted)-1)]
+}
+
+// Volatility is the per-candle Garman-Klass volatility of the candles
+func Volatility(candles []Candle) float64 {
+	return garmanKlass(candles)
+}
+
+// CurrentVolatility is the reading of the live regime detection over the last candles (long window)
+func CurrentVolatility(candles []Candle) float64 {
+	if len(candles) < longVolCandles {
+		return garmanKlass(candles)
+	}
+	return garmanKlass(candles[len(candles)-longVolCandles:])
+}
+
+// CurrentSpacing is the dynamic spacing the live grid would use after the last candle
+func CurrentSpacing(candles []Candle, settings Settings, params Params) float64 {
+	if len(candles) == 0 {
+		return settings.FallbackSpacing
+	}
+	return spacingAt(candles, len(candles)-1, settings, params)
+}
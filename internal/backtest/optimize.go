@@ -0,0 +1,113 @@
+package backtest
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// SearchSpace lists the values tried for each parameter
+type SearchSpace struct {
+	GridLevels         []int
+	LowVolMultipliers  []float64
+	HighVolMultipliers []float64
+	PositionSizePcts   []float64
+}
+
+// Combinations returns every parameter set of the space (the full grid)
+func (s SearchSpace) Combinations() []Params {
+	var all []Params
+	for _, levels := range s.GridLevels {
+		for _, low := range s.LowVolMultipliers {
+			for _, high := range s.HighVolMultipliers {
+				if high < low {
+					continue // The crash regime never tightens the grid
+				}
+				for _, size := range s.PositionSizePcts {
+					all = append(all, Params{GridLevels: levels, LowVolMultiplier: low, HighVolMultiplier: high, PositionSizePct: size})
+				}
+			}
+		}
+	}
+	return all
+}
+
+// Sample returns up to n distinct random parameter sets from the space
+func (s SearchSpace) Sample(n int, rng *rand.Rand) []Params {
+	all := s.Combinations()
+	rng.Shuffle(len(all), func(i, j int) { all[i], all[j] = all[j], all[i] })
+	if n < len(all) {
+		all = all[:n]
+	}
+	return all
+}
+
+// Optimize backtests every parameter set over the candles (in parallel) and returns the
+// results ranked by the given metric
+func Optimize(candles []Candle, settings Settings, candidates []Params, rankBy string, workers int) ([]Result, error) {
+	if err := ValidateRank(rankBy); err != nil {
+		return nil, err
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	results := make([]Result, len(candidates))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = Run(candles, settings, candidates[i])
+			}
+		}()
+	}
+	for i := range candidates {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	Rank(results, rankBy)
+	return results, nil
+}
+
+// Rank metrics accepted by Optimize
+const (
+	RankSharpe   = "sharpe"
+	RankReturn   = "return"
+	RankDrawdown = "drawdown"
+)
+
+// ValidateRank checks the ranking metric name
+func ValidateRank(rankBy string) error {
+	switch rankBy {
+	case RankSharpe, RankReturn, RankDrawdown:
+		return nil
+	}
+	return fmt.Errorf("invalid rank metric %q (expected %s, %s or %s)", rankBy, RankSharpe, RankReturn, RankDrawdown)
+}
+
+// Rank sorts results best first: highest Sharpe, highest return or smallest drawdown.
+// Ties fall back to the total return.
+func Rank(results []Result, rankBy string) {
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		switch rankBy {
+		case RankReturn:
+			return a.TotalReturnPct > b.TotalReturnPct
+		case RankDrawdown:
+			if a.MaxDrawdownPct != b.MaxDrawdownPct {
+				return a.MaxDrawdownPct < b.MaxDrawdownPct
+			}
+		default:
+			if a.Sharpe != b.Sharpe {
+				return a.Sharpe > b.Sharpe
+			}
+		}
+		return a.TotalReturnPct > b.TotalReturnPct
+	})
+}
@@ -0,0 +1,101 @@
+package backtest
+
+import "fmt"
+
+// WalkForwardConfig sizes the rolling windows, in candles
+type WalkForwardConfig struct {
+	InSample  int // Candles used to pick the best parameter set
+	OutSample int // Following candles the chosen set is evaluated on (also the step)
+	RankBy    string
+	Workers   int
+	Baseline  *Params // Optional set (e.g. the live .env) evaluated on the same out-of-sample windows
+}
+
+// WalkForwardWindow is one optimize-then-validate step
+type WalkForwardWindow struct {
+	InStart, OutStart, OutEnd int64 // Candle open times (ms)
+	Best                      Params
+	InSample                  Result
+	OutOfSample               Result
+	Baseline                  *Result
+}
+
+// Degraded reports whether the chosen set lost money out-of-sample after making money in-sample
+func (w WalkForwardWindow) Degraded() bool {
+	return w.InSample.TotalReturnPct > 0 && w.OutOfSample.TotalReturnPct <= 0
+}
+
+// WalkForwardReport aggregates the out-of-sample performance of every window
+type WalkForwardReport struct {
+	Windows           []WalkForwardWindow
+	OOSReturnPct      float64 // Compounded over the out-of-sample windows
+	BaselineReturnPct float64 // Same, for the baseline set (if any)
+	Efficiency        float64 // Mean OOS return per candle / mean IS return per candle (< 0.5 suggests overfitting; 0 if IS lost money)
+	DegradedWindows   int
+	DistinctBest      int // How many different sets won a window (high = unstable choice)
+}
+
+// WalkForward rolls an in-sample window over the candles, optimizes on it, then runs the
+// winner on the out-of-sample window right after it. The out-of-sample runs start flat
+// (no inherited positions) and use the end of the in-sample window as volatility warm-up.
+func WalkForward(candles []Candle, settings Settings, candidates []Params, cfg WalkForwardConfig) (WalkForwardReport, error) {
+	var report WalkForwardReport
+	if cfg.InSample <= longVolCandles || cfg.OutSample <= 0 {
+		return report, fmt.Errorf("walk-forward windows too small (in-sample %d, out-of-sample %d candles)", cfg.InSample, cfg.OutSample)
+	}
+	if len(candles) < cfg.InSample+cfg.OutSample {
+		return report, fmt.Errorf("not enough candles for one walk-forward window: have %d, need %d", len(candles), cfg.InSample+cfg.OutSample)
+	}
+
+	oosGrowth, baselineGrowth := 1.0, 1.0
+	var isPerCandle, oosPerCandle float64
+	winners := make(map[Params]bool)
+
+	for start := 0; start+cfg.InSample+cfg.OutSample <= len(candles); start += cfg.OutSample {
+		inSample := candles[start : start+cfg.InSample]
+		results, err := Optimize(inSample, settings, candidates, cfg.RankBy, cfg.Workers)
+		if err != nil {
+			return report, err
+		}
+		best := results[0]
+
+		// Out-of-sample: warm the volatility up with the tail of the in-sample window
+		outStart := start + cfg.InSample
+		oosSettings := settings
+		oosSettings.WarmupCandles = longVolCandles
+		outSample := candles[outStart-longVolCandles : outStart+cfg.OutSample]
+
+		window := WalkForwardWindow{
+			InStart:     inSample[0].OpenTime,
+			OutStart:    candles[outStart].OpenTime,
+			OutEnd:      candles[outStart+cfg.OutSample-1].OpenTime,
+			Best:        best.Params,
+			InSample:    best,
+			OutOfSample: Run(outSample, oosSettings, best.Params),
+		}
+		if cfg.Baseline != nil {
+			baseline := Run(outSample, oosSettings, *cfg.Baseline)
+			window.Baseline = &baseline
+			baselineGrowth *= 1 + baseline.TotalReturnPct/100
+		}
+
+		oosGrowth *= 1 + window.OutOfSample.TotalReturnPct/100
+		isPerCandle += window.InSample.TotalReturnPct / float64(cfg.InSample)
+		oosPerCandle += window.OutOfSample.TotalReturnPct / float64(cfg.OutSample)
+		if window.Degraded() {
+			report.DegradedWindows++
+		}
+		winners[best.Params] = true
+		report.Windows = append(report.Windows, window)
+	}
+
+	report.OOSReturnPct = (oosGrowth - 1) * 100
+	if cfg.Baseline != nil {
+		report.BaselineReturnPct = (baselineGrowth - 1) * 100
+	}
+	if isPerCandle > 0 {
+		report.Efficiency = oosPerCandle / isPerCandle
+	}
+	report.DistinctBest = len(winners)
+	return report, nil
+}
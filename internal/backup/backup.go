@@ -0,0 +1,141 @@
+// Package backup uploads the bot state off the host (S3-compatible storage or SFTP) on a
+// schedule, so a disk failure does not take the trade history the tax report depends on.
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"grid-trading-btc-binance/internal/crash"
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/snapshot"
+)
+
+const stampLayout = "20060102-150405"
+
+// Target stores backup runs: each run is a directory/prefix named after its UTC stamp
+// (YYYYMMDD-HHMMSS) holding the uploaded files
+type Target interface {
+	Name() string
+	Put(run, file string, body []byte) error
+	Runs() ([]string, error) // Names directly under the prefix/directory (not only run stamps)
+	DeleteRun(run string) error
+}
+
+// Uploader takes a snapshot (transactions, archive, runtime state) plus the CSV logs every
+// Interval and uploads them as one run, deleting runs older than Retention
+type Uploader struct {
+	Target    Target
+	Symbol    string
+	Interval  time.Duration
+	Retention time.Duration // 0 = keep every run
+	Files     []string      // Extra files uploaded as they are (missing ones are skipped)
+
+	Flush     func() error    // Writes pending state before the snapshot (optional)
+	OnFailure func(err error) // Called when a run fails after a successful one (optional)
+
+	failing bool
+}
+
+// Start runs the first backup right away, then every Interval
+func (u *Uploader) Start() {
+	crash.Go("backup uploader", func() {
+		logger.Info("☁️ Remote backup enabled", "target", u.Target.Name(), "interval", u.Interval, "retention", u.Retention)
+		ticker := time.NewTicker(u.Interval)
+		defer ticker.Stop()
+
+		for {
+			u.run()
+			<-ticker.C
+		}
+	})
+}
+
+func (u *Uploader) run() {
+	uploaded, pruned, err := u.Run(time.Now())
+	if err != nil {
+		logger.Error("❌ Remote backup failed", "target", u.Target.Name(), "error", err)
+		if !u.failing && u.OnFailure != nil {
+			u.OnFailure(err)
+		}
+		u.failing = true
+		return
+	}
+	if u.failing {
+		logger.Info("✅ Remote backup recovered", "target", u.Target.Name())
+	}
+	u.failing = false
+	logger.Info("☁️ Remote backup uploaded", "target", u.Target.Name(), "files", uploaded, "pruned", pruned)
+}
+
+// Run uploads one backup stamped with now and applies the retention. It returns how many
+// files were uploaded and how many old runs were deleted.
+func (u *Uploader) Run(now time.Time) (int, int, error) {
+	if u.Flush != nil {
+		if err := u.Flush(); err != nil {
+			return 0, 0, fmt.Errorf("failed to flush state: %w", err)
+		}
+	}
+	snap, err := snapshot.Create(u.Symbol, "backup", nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	raw, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	stamp := now.UTC().Format(stampLayout)
+	if err := u.Target.Put(stamp, "snapshot.json", raw); err != nil {
+		return 0, 0, fmt.Errorf("failed to upload snapshot: %w", err)
+	}
+	uploaded := 1
+	for _, file := range u.Files {
+		body, err := os.ReadFile(file)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return uploaded, 0, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		if err := u.Target.Put(stamp, filepath.Base(file), body); err != nil {
+			return uploaded, 0, fmt.Errorf("failed to upload %s: %w", file, err)
+		}
+		uploaded++
+	}
+
+	pruned, err := u.prune(now)
+	if err != nil {
+		return uploaded, pruned, fmt.Errorf("uploaded, but failed to apply retention: %w", err)
+	}
+	return uploaded, pruned, nil
+}
+
+// prune deletes the runs older than Retention. Anything not named like a run is never touched.
+func (u *Uploader) prune(now time.Time) (int, error) {
+	if u.Retention <= 0 {
+		return 0, nil
+	}
+	runs, err := u.Target.Runs()
+	if err != nil {
+		return 0, err
+	}
+	sort.Strings(runs)
+	cutoff := now.Add(-u.Retention)
+	pruned := 0
+	for _, run := range runs {
+		at, err := time.Parse(stampLayout, run)
+		if err != nil || !at.Before(cutoff) {
+			continue
+		}
+		if err := u.Target.DeleteRun(run); err != nil {
+			return pruned, err
+		}
+		pruned++
+	}
+	return pruned, nil
+}
@@ -0,0 +1,109 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const sftpTimeout = 2 * time.Minute
+
+// SFTPTarget writes to a directory on an SSH server through the system sftp client in batch
+// mode, so host keys, ~/.ssh/config aliases and agents work as they do in the shell. The
+// host must already be in known_hosts (no interactive prompt).
+type SFTPTarget struct {
+	Host    string // [user@]host or an ~/.ssh/config alias
+	Port    int    // 0 = ssh default/config
+	KeyFile string // Identity file (empty = ssh default/agent)
+	Dir     string // Remote directory holding the runs
+}
+
+func NewSFTPTarget(host string, port int, keyFile, dir string) *SFTPTarget {
+	return &SFTPTarget{Host: host, Port: port, KeyFile: keyFile, Dir: strings.TrimRight(dir, "/")}
+}
+
+func (t *SFTPTarget) Name() string {
+	return "sftp://" + t.Host + "/" + strings.TrimLeft(t.Dir, "/")
+}
+
+func (t *SFTPTarget) Put(run, file string, body []byte) error {
+	tmp, err := os.CreateTemp("", "grid-backup-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	runDir := path.Join(t.Dir, run)
+	// "-" ignores the error when the directory already exists
+	_, err = t.batch(
+		"-mkdir "+quote(t.Dir),
+		"-mkdir "+quote(runDir),
+		"put "+quote(tmp.Name())+" "+quote(path.Join(runDir, file)),
+	)
+	return err
+}
+
+func (t *SFTPTarget) Runs() ([]string, error) {
+	out, err := t.batch("ls -1 " + quote(t.Dir))
+	if err != nil {
+		return nil, err
+	}
+	var runs []string
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "sftp>") {
+			continue
+		}
+		runs = append(runs, path.Base(line))
+	}
+	return runs, nil
+}
+
+func (t *SFTPTarget) DeleteRun(run string) error {
+	runDir := path.Join(t.Dir, run)
+	_, err := t.batch("-rm "+quote(runDir+"/*"), "rmdir "+quote(runDir))
+	return err
+}
+
+// batch runs the commands in one sftp session and returns its output
+func (t *SFTPTarget) batch(commands ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), sftpTimeout)
+	defer cancel()
+
+	args := []string{"-q", "-b", "-", "-o", "BatchMode=yes"}
+	if t.Port > 0 {
+		args = append(args, "-P", strconv.Itoa(t.Port))
+	}
+	if t.KeyFile != "" {
+		args = append(args, "-i", t.KeyFile)
+	}
+	args = append(args, t.Host)
+
+	cmd := exec.CommandContext(ctx, "sftp", args...)
+	cmd.Stdin = strings.NewReader(strings.Join(commands, "\n") + "\n")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("sftp %s: %w: %s", t.Host, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// quote protects paths with spaces in sftp batch commands
+func quote(p string) string {
+	return `"` + strings.ReplaceAll(p, `"`, `\"`) + `"`
+}
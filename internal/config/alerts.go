@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kinds of PRICE_ALERTS entries
+const (
+	PriceAlertAbove = "above" // The price crosses up through Price
+	PriceAlertBelow = "below" // The price crosses down through Price
+	PriceAlertMove  = "move"  // The price moves Pct (either way) within Window
+	PriceAlertRange = "range" // The price gets within Pct of RANGE_MIN or RANGE_MAX (or leaves the range)
+)
+
+// PriceAlert is one entry of PRICE_ALERTS: a heads-up independent of the trading
+type PriceAlert struct {
+	Label  string // The entry as written
+	Kind   string
+	Price  float64       // above, below
+	Pct    float64       // move: change, range: distance to a boundary (0.01 = 1%)
+	Window time.Duration // move
+}
+
+// ParsePriceAlerts reads PRICE_ALERTS: entries separated by ";", each "above <price>",
+// "below <price>", "move <pct> <minutes>m" or "range <pct>". Percentages take a "%" suffix
+// (3%) or are fractions (0.03).
+func ParsePriceAlerts(raw string) ([]PriceAlert, error) {
+	var alerts []PriceAlert
+	for _, entry := range strings.Split(raw, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		a, err := parsePriceAlert(strings.Fields(strings.ToLower(entry)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid PRICE_ALERTS entry %q: %w", entry, err)
+		}
+		a.Label = entry
+		alerts = append(alerts, a)
+	}
+	return alerts, nil
+}
+
+func parsePriceAlert(fields []string) (PriceAlert, error) {
+	a := PriceAlert{Kind: fields[0]}
+	switch a.Kind {
+	case PriceAlertAbove, PriceAlertBelow:
+		if len(fields) != 2 {
+			return a, fmt.Errorf("expected \"%s <price>\"", a.Kind)
+		}
+		price, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil || price <= 0 {
+			return a, fmt.Errorf("expected a positive price, got %q", fields[1])
+		}
+		a.Price = price
+	case PriceAlertMove:
+		if len(fields) != 3 {
+			return a, fmt.Errorf("expected \"move <pct> <minutes>m\"")
+		}
+		pct, err := parseAlertPct(fields[1])
+		if err != nil {
+			return a, err
+		}
+		minutes, err := strconv.Atoi(strings.TrimSuffix(fields[2], "m"))
+		if err != nil || minutes < 1 || minutes > 24*60 {
+			return a, fmt.Errorf("expected a window between 1m and 1440m, got %q", fields[2])
+		}
+		a.Pct, a.Window = pct, time.Duration(minutes)*time.Minute
+	case PriceAlertRange:
+		if len(fields) != 2 {
+			return a, fmt.Errorf("expected \"range <pct>\"")
+		}
+		pct, err := parseAlertPct(fields[1])
+		if err != nil {
+			return a, err
+		}
+		a.Pct = pct
+	default:
+		return a, fmt.Errorf("unknown kind %q (expected above, below, move or range)", a.Kind)
+	}
+	return a, nil
+}
+
+// parseAlertPct reads "3%" or "0.03" as 0.03
+func parseAlertPct(value string) (float64, error) {
+	number, percent := strings.CutSuffix(value, "%")
+	pct, err := strconv.ParseFloat(number, 64)
+	if percent {
+		pct /= 100
+	}
+	if err != nil || pct <= 0 || pct >= 1 {
+		return 0, fmt.Errorf("expected a percentage between 0 and 100%%, got %q", value)
+	}
+	return pct, nil
+}
@@ -0,0 +1,25 @@
+package config
+
+import "strings"
+
+// knownQuotes are the quote assets recognized when splitting a symbol without exchangeInfo,
+// checked in order (FDUSD and TUSD before shorter suffixes they contain)
+var knownQuotes = []string{"FDUSD", "USDT", "USDC", "TUSD", "BUSD", "EUR", "TRY", "BRL", "BTC", "ETH", "BNB"}
+
+// SplitSymbol derives the base and quote assets of a symbol from its quote suffix
+// (BTCUSDT -> BTC, USDT). Used before exchangeInfo is available; false when no known quote matches.
+func SplitSymbol(symbol string) (string, string, bool) {
+	for _, quote := range knownQuotes {
+		if base, ok := strings.CutSuffix(symbol, quote); ok && base != "" {
+			return base, quote, true
+		}
+	}
+	return "", "", false
+}
+
+// SetAssets replaces the assets derived from SYMBOL with the ones reported by exchangeInfo
+func (c *Config) SetAssets(base, quote string) {
+	if base != "" && quote != "" {
+		c.BaseAsset, c.QuoteAsset = base, quote
+	}
+}
@@ -2,16 +2,27 @@ package config
 
 import (
 	"fmt"
+	"log/slog"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
+	"time"
+
+	"grid-trading-btc-binance/internal/audit"
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/secrets"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
 	Symbol          string
-	MakerFeePct     float64
+	BaseAsset       string  // Traded asset (BTC of BTCUSDT): from exchangeInfo at startup, derived from SYMBOL until then
+	QuoteAsset      string  // Asset prices, balances and PnL are in (USDT of BTCUSDT)
+	MakerFeePct     float64 // Standard account rates (synced from Binance); FeeModel applies discounts
 	TakerFeePct     float64
+	FeeModel        string // auto | standard | bnb | zero
 	GridLevels      int
 	GridSpacingPct  float64
 	PositionSizePct float64
@@ -20,18 +31,98 @@ type Config struct {
 	MaxSpreadPct    float64
 	RangeMin        float64
 	RangeMax        float64
+	GridZones       []GridZone // Bands of the range with their own capital share, levels and spacing (empty = one flat grid)
 	MinOrderValue   float64
+	USDTReserve     float64 // Free USDT never deployed by the strategy
+	StrategyMode    string  // grid | dca
+
+	// Range Suggestion (Bollinger band of the daily closes, widened by the ATR)
+	RangeCheck     bool    // Daily check of the range against the suggestion (range_stale alert)
+	RangeAutoApply bool    // A stale range is replaced by the suggestion
+	RangeDays      int     // Daily candles of the suggestion (also the suggest-range default)
+	RangeStdDevs   float64 // Band width: SMA ± RangeStdDevs σ of the closes
+	RangeATRs      float64 // Minimum room around the price, in average true ranges of a day
+
+	// DCA Accumulation (STRATEGY_MODE=dca)
+	DCABuyAmountUSDT float64 // USDT spent per buy
+	DCADropPct       float64 // Buy when the price is this far below the last buy (0 = disabled)
+	DCAIntervalMin   int     // Buy every N minutes regardless of price (0 = disabled)
+	DCATakeProfitPct float64 // Sell the whole stack this far above its average entry (0 = never sell)
+	DCAMaxStackUSDT  float64 // Maximum cost of the stack (0 = unlimited)
+
+	// Profit Compounding
+	CompoundProfits     bool
+	CompoundBaseCapital float64 // Optional explicit starting capital (0 = derive on first run)
+
+	// Entry Sizing: POSITION_SIZE_PCT of the free USDT instead of the min notional
+	SizeFromPositionPct bool
+
+	// Profit Vault
+	VaultSkimPct         float64 // Share of each realized profit moved to the vault (0 = disabled)
+	VaultMode            string  // accounting | transfer
+	VaultTransferMinUSDT float64
+
+	// Inventory Rebalancer
+	RebalanceEnabled      bool
+	RebalanceTargetRatio  float64 // Target BTC value / equity
+	RebalanceBand         float64 // Allowed drift around the target before acting
+	RebalanceMaxOrderUSDT float64
+	RebalanceCooldownMin  int
 
 	// Volatility Settings
 	HighVolMultiplier  float64
 	LowVolMultiplier   float64
 	VolatilityLookback int
+	RegimeEnterRatio   float64 // Short/long volatility ratio that enters HIGH_VOL_CRASH
+	RegimeExitRatio    float64 // Ratio below which HIGH_VOL_CRASH is left (hysteresis)
+	RegimeMinDwellMin  int     // Minutes a regime holds before it can flip again
+
+	MinDynamicSpacingPct float64 // Floor of the dynamic spacing (0 = only the fee model floor)
+	MaxDynamicSpacingPct float64 // Ceiling of the dynamic spacing (0 = none); the fee floor still wins
 
 	// Smart Entry Repositioning
 	SmartEntryRepositionPct        float64
 	SmartEntryRepositionCooldown   int
 	SmartEntryRepositionMaxIdleMin int
 
+	// Stale Buy Order Expiry
+	MaxBuyOrderAgeMin      int
+	MaxBuyOrderDistancePct float64
+
+	// Exit Fallback
+	ExitFallbackMode           string  // none | market | limit (IOC)
+	ExitFallbackAfter          int     // LIMIT exit attempts before the fallback
+	ExitFallbackMaxSlippagePct float64 // limit mode: lowest price below the best bid
+
+	// Exit Recovery (failed_placement)
+	ExitRecoveryIntervalMin int // Retry failed exits every N minutes (0 = disabled, the sync purges them)
+	ExitRecoveryDeadlineMin int // Give up and escalate to manual after N minutes
+
+	// Trade Reconciliation (myTrades)
+	TradeReconcileIntervalMin int
+
+	// User Stream Processing
+	WSUpdateWorkers int
+
+	// Transaction Persistence
+	TxFlushIntervalMs int // transactions.json is written at most this often, pending changes on shutdown (0 = on every change)
+
+	// State Snapshots (transactions, archive, runtime state and circuit breaker in one file)
+	SnapshotIntervalMin int    // Periodic snapshot interval (0 = disabled)
+	SnapshotDir         string // Where snapshots are written
+	SnapshotKeep        int    // Newest snapshots kept (0 = keep all)
+
+	// Ticker Debounce: the strategy runs at most every EvalIntervalMs, or sooner on a price move
+	EvalIntervalMs     int     // Minimum time between evaluations (0 = every ticker)
+	EvalPriceChangePct float64 // Price move since the last evaluation that runs the strategy at once (0.0002 = 0.02%, 0 = disabled)
+
+	// Stream Watchdog (alert + forced reconnection of stale streams)
+	WatchdogTickerStaleSec int // No ticker for this long (0 = disabled)
+	WatchdogStreamStaleMin int // No user stream event or ping for this long while orders are open (0 = disabled)
+
+	// Exposure Alarm (filled buys without a live exit)
+	ExposureMaxNakedSec int // Alert and repair after this long without an exit (0 = disabled)
+
 	// Metrics
 	MsTimeProduction int64
 	TotalCycles      int64
@@ -39,6 +130,37 @@ type Config struct {
 	// Binance API
 	BinanceApiKey    string
 	BinanceSecretKey string
+	BinanceBaseURL   string // Custom REST base URL (relay/reverse proxy); wins over BinanceCluster
+	BinanceCluster   string // Alternate REST host: api1..api4 or gcp ("" = api.binance.com)
+	BinanceStreamURL string // Custom WebSocket base URL of the user stream
+	BinanceProxy     string // http:// or socks5:// proxy of every Binance connection
+
+	// REST Endpoint Failover (probes the alternate clusters, moves away from a degraded primary)
+	EndpointFailover         bool
+	EndpointProbeIntervalSec int     // Every host is pinged this often
+	EndpointMaxLatencyMs     int     // Median probe latency above which a host is degraded
+	EndpointMaxErrorRate     float64 // Share of failed REST calls (last 5 min) above which the primary is degraded
+
+	// Connection pool shared by the REST clients (keep-alive, TLS session resumption)
+	HTTPMaxIdleConnsPerHost int  // Idle connections kept open per host for the next calls
+	HTTPIdleConnTimeoutSec  int  // An idle connection is closed after this
+	HTTP2Enabled            bool // Negotiate HTTP/2 with the REST hosts (one multiplexed connection)
+
+	// Clock of the signed calls
+	TimeBiasMs        int // Timestamps signed this much behind the server clock
+	ClockDriftAlertMs int // Drift of the local clock (from the response Date headers) that triggers a resync and an alert (0 = off)
+
+	// Sub-account (SUB_ACCOUNT_EMAIL: the API keys above belong to a Binance sub-account)
+	SubAccountEmail string
+	MasterApiKey    string // Master account keys, only used by `grid-bot subaccount topup`
+	MasterSecretKey string
+
+	// Margin Account (ACCOUNT_TYPE cross/isolated: the grid trades on margin instead of Spot)
+	AccountType         string  // spot | cross | isolated
+	MarginAutoBorrow    bool    // Buys borrow the missing quote asset (MARGIN_BUY); sells always repay (AUTO_REPAY)
+	MarginMaxBorrow     float64 // Quote asset debt the grid buys may reach with MarginAutoBorrow
+	MarginLevelWarn     float64 // Margin level alerted as low
+	MarginLevelCritical float64 // Margin level alerted as critical; free quote asset repays the debt
 
 	// Telegram
 	TelegramToken  string
@@ -48,16 +170,197 @@ type Config struct {
 	CrashProtectionEnabled bool
 	MaxDropPct5m           float64
 	CrashPauseMin          int
+	CBMaxTrips             int    // Circuit breaker trips tolerated within CB_TRIP_WINDOW_HOURS; one more escalates (0 = disabled)
+	CBTripWindowHours      int    // Window of the trip count, and how long widen/halve last after the last trip
+	CBEscalation           string // What the escalation does: widen (spacing x2), halve (position size /2) or stop (pause until /resume)
 	PauseBuys              bool
+	PanicOnStart           bool   // Kill switch on startup: cancel all, flatten, pause
+	SyncDryRun             bool   // Startup sync only previews the ghost/duplicate/zombie cleanup until confirmed (/cleanup confirm or run -confirm-cleanup)
+	MonitorOnly            bool   // Read-only instance: never places, cancels or transfers (watchdog/reporter)
+	InstanceGuard          string // Another trading instance on this host with the same SYMBOL and API key: refuse, monitor or off
+
+	// Drawdown Stop (unrealized PnL of the grid inventory)
+	DrawdownStopUSDT     float64 // Trigger at this unrealized loss in USDT (0 = disabled)
+	DrawdownStopPct      float64 // ...or at this loss over the inventory cost (0.08 = 8%, 0 = disabled)
+	DrawdownSellFraction float64 // Share of the inventory market-sold, deepest positions first (0 = only pause)
+	DrawdownCooloffMin   int     // /resume is refused for this long after a trigger
+	DrawdownConfirm      bool    // Pause and wait for /drawdown confirm before selling
+
+	// Position Sizing (how POSITION_SIZE_PCT becomes an order value)
+	SizingMode         string  // flat | volatility | kelly
+	SizingTargetVol    float64 // volatility: GK volatility (per 1m candle) at which the order is the flat size
+	SizingMinOrderUSDT float64 // volatility: bounds of the scaled order value (0 = MIN_ORDER_VALUE / no cap)
+	SizingMaxOrderUSDT float64
+	KellyFraction      float64 // kelly: share of the full Kelly fraction used (0.25 = quarter Kelly)
+	KellyMaxSizePct    float64 // kelly: cap of the resulting position size
+	KellyMinTrades     int     // kelly: below this many archived trades POSITION_SIZE_PCT is used
+	KellyLookbackDays  int     // kelly: archived trades considered
+
+	// Trading Windows (low-liquidity hours, weekends, scheduled events)
+	TradingWindows []TradingWindow // No new grid entries, or smaller ones, while one is active
+
+	// Macro Calendar (no new grid entries around high-impact releases: FOMC, CPI...)
+	MacroCalendarFile         string         // Static schedule, one event per line ("" = none)
+	MacroCalendarURL          string         // JSON calendar feed ("" = none)
+	MacroCalendarCountries    []string       // Feed: currencies whose events count (USD)
+	MacroCalendarImpact       string         // Feed: lowest impact that pauses (high | medium)
+	MacroCalendarKeywords     []string       // Feed: only titles containing one of these (empty = any)
+	MacroCalendarTZ           *time.Location // Times of the schedule file
+	MacroCalendarRefreshHours int
+	MacroPauseBeforeMin       int // New entries stop this long before an event...
+	MacroPauseAfterMin        int // ...and resume this long after it
+
+	// Order-Book Imbalance Filter (delays grid buys under heavy ask-side pressure)
+	BookImbalanceFilter      bool
+	BookImbalanceSource      string  // depth (REST order book) | ticker (best bid/ask quantities of the stream)
+	BookImbalanceDepth       int     // depth: levels per side summed
+	BookImbalanceThreshold   float64 // Buys wait while (bid vol - ask vol) / (bid vol + ask vol) <= -threshold
+	BookImbalanceMaxDelaySec int     // A buy delayed this long is placed anyway (0 = no limit)
+
+	// Buy Placement (PLACEMENT_MODE=support: grid buys snap just below support levels)
+	PlacementMode string  // spacing (at the bid) | support
+	SRMethod      string  // swings (swing highs/lows) | volume (volume profile nodes)
+	SRInterval    string  // Kline interval the levels are computed on
+	SRLookback    int     // Candles of the interval considered
+	SRSwingWindow int     // swings: candles on each side a swing must exceed
+	SRMaxSnapPct  float64 // Farthest below the bid a support may be to take the buy
+	SROffsetPct   float64 // The buy goes this far below the support
+
+	// Futures Hedge (USDⓈ-M short against the grid inventory in a downtrend)
+	HedgeEnabled       bool
+	HedgeSymbol        string  // Perpetual contract shorted (default SYMBOL)
+	HedgeInventoryUSDT float64 // Grid inventory value (at the bid) above which the hedge opens
+	HedgeRatio         float64 // Share of the grid inventory quantity shorted
+	HedgeTrendHours    int     // Downtrend: price below the moving average of this many hourly closes
+	HedgeLeverage      int
+
+	// Simple Earn (idle quote asset parked in Flexible savings)
+	EarnEnabled      bool
+	EarnBufferOrders int     // Grid buys worth of quote asset always kept in the wallet
+	EarnMinUSDT      float64 // Smallest subscription (avoids churning small amounts)
+
+	// BNB Auto Top-Up (fees)
+	BNBAutoTopUp       bool
+	BNBTopUpAmountUSDT float64
+	BNBTopUpMaxPerDay  int
+
+	// Dust Conversion (weekly: base asset crumbs below the min notional -> BNB)
+	DustConvert bool
+
+	// Hourly Collector
+	CollectorJSONOutput bool // Also write the hourly records and the trade ledger as JSON Lines (.jsonl)
+
+	// Time-Series Metrics Sink (hourly records + closed trades)
+	MetricsSink  string // "" (disabled) or "influxdb"
+	InfluxURL    string
+	InfluxToken  string
+	InfluxOrg    string
+	InfluxBucket string
+
+	// Remote Backup (snapshot + CSV logs uploaded off the host)
+	BackupTarget        string // "" (disabled), "s3" or "sftp"
+	BackupIntervalMin   int
+	BackupRetentionDays int // Older runs are deleted from the target (0 = keep all)
+	BackupS3Endpoint    string
+	BackupS3Region      string
+	BackupS3Bucket      string
+	BackupS3Prefix      string
+	BackupS3AccessKey   string
+	BackupS3SecretKey   string
+	BackupSFTPHost      string // [user@]host or ~/.ssh/config alias
+	BackupSFTPPort      int    // 0 = ssh default
+	BackupSFTPKey       string // Identity file (empty = ssh default/agent)
+	BackupSFTPDir       string
 
 	// Metrics API
 	MetricsAPIURL   string
 	MetricsAPIToken string
+
+	// Logging
+	LogLevel        slog.Level
+	LogOutput       string                // file | console | both
+	LogFile         string                // JSON log (rotated)
+	LogErrorFile    string                // Extra file with only the errors ("" = disabled)
+	LogModuleLevels map[string]slog.Level // Per-package overrides (LOG_MODULE_LEVELS)
+
+	// Trade Audit Log (append-only, separate from the app log)
+	AuditEnabled bool
+	AuditDir     string // One JSONL file per day
+
+	// Tracing (OpenTelemetry spans of the order flows)
+	TracingEndpoint    string  // OTLP/HTTP URL ("" = disabled)
+	TracingSampleRatio float64 // Share of the traces exported (0-1)
+
+	// Crash Reporting (recovered panics)
+	SentryDSN         string // "" = disabled
+	SentryEnvironment string
+
+	// Additional Notification Channels (each one is enabled when its URL is set)
+	DiscordWebhookURL string
+	SlackWebhookURL   string
+	WebhookURL        string
+	WebhookToken      string
+
+	// Notification Preferences
+	NotifyEntryFills     bool
+	NotifyExits          bool
+	NotifyCircuitBreaker bool
+	NotifyLowBalance     bool
+	NotifySyncEvents     bool
+	NotifyErrors         bool
+	NotifyMinSeverity    string
+	NotifyTemplatesDir   string
+	AccountName          string // Tags every alert with the account (set per account by `grid-bot accounts run`)
+
+	// Price Alerts (heads-up only, independent of the trading)
+	PriceAlerts           []PriceAlert
+	PriceAlertCooldownMin int // Silence of an alert after it fires
+
+	// Parameter Profiles
+	Profiles                  map[string]map[string]string // Name -> env name -> value (config file only)
+	Profile                   string                       // Applied at startup ("" = base values)
+	ProfileAutoSwitchTo       string                       // Switched to when the circuit breaker keeps tripping ("" = disabled)
+	ProfileAutoSwitchTriggers int                          // Circuit breaker triggers in one day that cause the switch
+
+	// Shadow Strategy (paper copy with another profile, compared daily with the live one)
+	ShadowProfile     string  // Profile the shadow runs with ("" = disabled)
+	ShadowCapitalUSDT float64 // Virtual starting capital (0 = live deployable USDT on the first start)
+
+	// Health Endpoints (/healthz, /readyz)
+	HealthAddr            string  // Listen address, e.g. ":8080" ("" = disabled)
+	HealthMaxTickerAgeSec int     // Unhealthy when no ticker arrived for this long
+	HealthMaxStreamAgeSec int     // Unhealthy when the user stream was silent (not even a ping) for this long
+	HealthMaxAPIErrorRate float64 // Not ready when this share of the recent REST calls failed
+
+	// TradingView Webhook (external signals gate the grid)
+	TradingViewAddr         string // Listen address, e.g. ":8090" ("" = disabled)
+	TradingViewSecret       string // HMAC-SHA256 key of the signals
+	TradingViewMaxAgeSec    int    // Signals with an older "time" are refused (0 = no check)
+	TradingViewAllowFlatten bool   // Accept the flatten action (cancel everything and market-sell)
+
+	// Leader Election (hot standby: only the lease holder trades)
+	LeaderLock          string // "" (disabled), "file" or "redis"
+	LeaderLockFile      string // Lease file (same host or shared filesystem)
+	LeaderRedisAddr     string // host:port
+	LeaderRedisPassword string
+	LeaderLockKey       string // Redis key ("" = grid-bot:leader:<SYMBOL>)
+	LeaderLeaseSec      int    // A standby takes over this long after the leader stops renewing
+	LeaderID            string // This instance in the lease ("" = hostname-pid)
 }
 
+// Load reads the configuration from the environment, .env and the optional config file
+// (config.yaml), validating every key before parsing.
 func Load() (*Config, error) {
-	if err := godotenv.Load(); err != nil {
-		return nil, fmt.Errorf("error loading .env file: %w", err)
+	envErr := godotenv.Load()
+	file, fileErr := applyConfigFile()
+	if fileErr != nil {
+		return nil, fileErr
+	}
+	if envErr != nil && file == nil {
+		return nil, fmt.Errorf("error loading .env file: %w", envErr)
+	}
+	if err := validateEnv(); err != nil {
+		return nil, err
 	}
 
 	cfg := &Config{}
@@ -67,6 +370,22 @@ func Load() (*Config, error) {
 	if cfg.Symbol == "" {
 		return nil, fmt.Errorf("SYMBOL is required")
 	}
+	cfg.BaseAsset, cfg.QuoteAsset = os.Getenv("BASE_ASSET"), os.Getenv("QUOTE_ASSET")
+	if cfg.BaseAsset == "" || cfg.QuoteAsset == "" {
+		base, quote, ok := SplitSymbol(cfg.Symbol)
+		if !ok {
+			return nil, fmt.Errorf("cannot derive the base/quote assets of SYMBOL %q: set BASE_ASSET and QUOTE_ASSET", cfg.Symbol)
+		}
+		if cfg.BaseAsset == "" {
+			cfg.BaseAsset = base
+		}
+		if cfg.QuoteAsset == "" {
+			cfg.QuoteAsset = quote
+		}
+	}
+	if cfg.BaseAsset+cfg.QuoteAsset != cfg.Symbol {
+		return nil, fmt.Errorf("BASE_ASSET %q and QUOTE_ASSET %q do not form SYMBOL %q", cfg.BaseAsset, cfg.QuoteAsset, cfg.Symbol)
+	}
 
 	cfg.MakerFeePct, err = parseFloat(os.Getenv("MAKER_FEE_PCT"), "MAKER_FEE_PCT")
 	if err != nil {
@@ -78,6 +397,15 @@ func Load() (*Config, error) {
 		return nil, err
 	}
 
+	cfg.FeeModel = strings.ToLower(os.Getenv("FEE_MODEL"))
+	switch cfg.FeeModel {
+	case "":
+		cfg.FeeModel = "auto"
+	case "auto", "standard", "bnb", "zero":
+	default:
+		return nil, fmt.Errorf("invalid value for FEE_MODEL: %q (expected auto, standard, bnb or zero)", cfg.FeeModel)
+	}
+
 	cfg.GridLevels, err = parseInt(os.Getenv("GRID_LEVELS"), "GRID_LEVELS")
 	if err != nil {
 		return nil, err
@@ -118,11 +446,42 @@ func Load() (*Config, error) {
 		return nil, err
 	}
 
+	// Range Suggestion (optional)
+	cfg.RangeCheck = optionalBool("RANGE_CHECK", true)
+	cfg.RangeAutoApply = optionalBool("RANGE_AUTO_APPLY", false)
+	cfg.RangeDays, err = optionalInt("RANGE_SUGGEST_DAYS", 30)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.RangeDays < 10 || cfg.RangeDays > 1000 {
+		return nil, fmt.Errorf("RANGE_SUGGEST_DAYS must be between 10 and 1000, got %d", cfg.RangeDays)
+	}
+	cfg.RangeStdDevs, err = optionalFloat("RANGE_SUGGEST_STDDEV", 2)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.RangeStdDevs <= 0 {
+		return nil, fmt.Errorf("RANGE_SUGGEST_STDDEV must be > 0, got %v", cfg.RangeStdDevs)
+	}
+	cfg.RangeATRs, err = optionalFloat("RANGE_SUGGEST_ATR", 2)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.RangeATRs < 0 {
+		return nil, fmt.Errorf("RANGE_SUGGEST_ATR must be >= 0, got %v", cfg.RangeATRs)
+	}
+
 	cfg.MinOrderValue, err = parseFloat(os.Getenv("MIN_ORDER_VALUE"), "MIN_ORDER_VALUE")
 	if err != nil {
 		return nil, err
 	}
 
+	// Grid Zones (optional)
+	cfg.GridZones, err = ParseGridZones(os.Getenv("GRID_ZONES"))
+	if err != nil {
+		return nil, err
+	}
+
 	// Volatility Settings
 	valHighVol := os.Getenv("HIGH_VOL_MULTIPLIER")
 	if valHighVol != "" {
@@ -146,6 +505,46 @@ func Load() (*Config, error) {
 
 	cfg.VolatilityLookback = 20 // Fixed lookback
 
+	cfg.RegimeEnterRatio, err = optionalFloat("REGIME_ENTER_RATIO", 1.5)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.RegimeEnterRatio <= 1 {
+		return nil, fmt.Errorf("REGIME_ENTER_RATIO must be > 1, got %v", cfg.RegimeEnterRatio)
+	}
+	cfg.RegimeExitRatio, err = optionalFloat("REGIME_EXIT_RATIO", 1.2)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.RegimeExitRatio < 1 || cfg.RegimeExitRatio > cfg.RegimeEnterRatio {
+		return nil, fmt.Errorf("REGIME_EXIT_RATIO must be between 1 and REGIME_ENTER_RATIO (%v), got %v", cfg.RegimeEnterRatio, cfg.RegimeExitRatio)
+	}
+	cfg.RegimeMinDwellMin, err = optionalInt("REGIME_MIN_DWELL_MIN", 5)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.RegimeMinDwellMin < 0 {
+		return nil, fmt.Errorf("REGIME_MIN_DWELL_MIN must be >= 0, got %d", cfg.RegimeMinDwellMin)
+	}
+
+	cfg.MinDynamicSpacingPct, err = optionalFloat("MIN_DYNAMIC_SPACING_PCT", 0)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MinDynamicSpacingPct < 0 || cfg.MinDynamicSpacingPct >= 1 {
+		return nil, fmt.Errorf("MIN_DYNAMIC_SPACING_PCT must be between 0 and 1, got %v", cfg.MinDynamicSpacingPct)
+	}
+	cfg.MaxDynamicSpacingPct, err = optionalFloat("MAX_DYNAMIC_SPACING_PCT", 0)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MaxDynamicSpacingPct < 0 || cfg.MaxDynamicSpacingPct >= 1 {
+		return nil, fmt.Errorf("MAX_DYNAMIC_SPACING_PCT must be between 0 (no ceiling) and 1, got %v", cfg.MaxDynamicSpacingPct)
+	}
+	if cfg.MaxDynamicSpacingPct > 0 && cfg.MaxDynamicSpacingPct < cfg.MinDynamicSpacingPct {
+		return nil, fmt.Errorf("MAX_DYNAMIC_SPACING_PCT (%v) must be >= MIN_DYNAMIC_SPACING_PCT (%v)", cfg.MaxDynamicSpacingPct, cfg.MinDynamicSpacingPct)
+	}
+
 	// Smart Entry Defaults (Optional params)
 	valRepositionPct := os.Getenv("SMART_ENTRY_REPOSITION_PCT")
 	if valRepositionPct != "" {
@@ -177,6 +576,167 @@ func Load() (*Config, error) {
 		cfg.SmartEntryRepositionMaxIdleMin = 20
 	}
 
+	// Stale Buy Order Expiry (0 = disabled)
+	cfg.MaxBuyOrderAgeMin, err = optionalInt("MAX_BUY_ORDER_AGE_MIN", 0)
+	if err != nil {
+		return nil, err
+	}
+	cfg.MaxBuyOrderDistancePct, err = optionalFloat("MAX_BUY_ORDER_DISTANCE_PCT", 0.03)
+	if err != nil {
+		return nil, err
+	}
+
+	// Exit Fallback (none = failed maker exits wait for a human)
+	cfg.ExitFallbackMode = strings.ToLower(os.Getenv("EXIT_FALLBACK_MODE"))
+	switch cfg.ExitFallbackMode {
+	case "":
+		cfg.ExitFallbackMode = "none"
+	case "none", "market", "limit":
+	default:
+		return nil, fmt.Errorf("invalid value for EXIT_FALLBACK_MODE: %q (expected none, market or limit)", cfg.ExitFallbackMode)
+	}
+	cfg.ExitFallbackAfter, err = optionalInt("EXIT_FALLBACK_AFTER", 5)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.ExitFallbackAfter < 1 {
+		return nil, fmt.Errorf("EXIT_FALLBACK_AFTER must be >= 1, got %d", cfg.ExitFallbackAfter)
+	}
+	cfg.ExitFallbackMaxSlippagePct, err = optionalFloat("EXIT_FALLBACK_MAX_SLIPPAGE_PCT", 0.003)
+	if err != nil {
+		return nil, err
+	}
+
+	// Exit Recovery (0 = disabled)
+	cfg.ExitRecoveryIntervalMin, err = optionalInt("EXIT_RECOVERY_INTERVAL_MIN", 3)
+	if err != nil {
+		return nil, err
+	}
+	cfg.ExitRecoveryDeadlineMin, err = optionalInt("EXIT_RECOVERY_DEADLINE_MIN", 60)
+	if err != nil {
+		return nil, err
+	}
+
+	// Strategy Mode
+	cfg.StrategyMode = strings.ToLower(os.Getenv("STRATEGY_MODE"))
+	switch cfg.StrategyMode {
+	case "":
+		cfg.StrategyMode = "grid"
+	case "grid", "dca":
+	default:
+		return nil, fmt.Errorf("invalid value for STRATEGY_MODE: %q (expected grid or dca)", cfg.StrategyMode)
+	}
+
+	// DCA Accumulation (only used when STRATEGY_MODE=dca)
+	cfg.DCABuyAmountUSDT, err = optionalFloat("DCA_BUY_AMOUNT_USDT", 20)
+	if err != nil {
+		return nil, err
+	}
+	cfg.DCADropPct, err = optionalFloat("DCA_DROP_PCT", 0.02)
+	if err != nil {
+		return nil, err
+	}
+	cfg.DCAIntervalMin, err = optionalInt("DCA_INTERVAL_MIN", 0)
+	if err != nil {
+		return nil, err
+	}
+	cfg.DCATakeProfitPct, err = optionalFloat("DCA_TAKE_PROFIT_PCT", 0.05)
+	if err != nil {
+		return nil, err
+	}
+	cfg.DCAMaxStackUSDT, err = optionalFloat("DCA_MAX_STACK_USDT", 0)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.StrategyMode == "dca" {
+		if cfg.DCABuyAmountUSDT < 5 {
+			return nil, fmt.Errorf("DCA_BUY_AMOUNT_USDT must be >= 5 (Binance min notional), got %.2f", cfg.DCABuyAmountUSDT)
+		}
+		if cfg.DCADropPct <= 0 && cfg.DCAIntervalMin <= 0 {
+			return nil, fmt.Errorf("STRATEGY_MODE=dca needs DCA_DROP_PCT > 0 or DCA_INTERVAL_MIN > 0")
+		}
+	}
+
+	// Trade Reconciliation (0 = disabled)
+	cfg.TradeReconcileIntervalMin, err = optionalInt("TRADE_RECONCILE_INTERVAL_MIN", 60)
+	if err != nil {
+		return nil, err
+	}
+
+	// User Stream Processing
+	cfg.WSUpdateWorkers, err = optionalInt("WS_UPDATE_WORKERS", 4)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.WSUpdateWorkers < 1 {
+		return nil, fmt.Errorf("WS_UPDATE_WORKERS must be >= 1, got %d", cfg.WSUpdateWorkers)
+	}
+
+	cfg.TxFlushIntervalMs, err = optionalInt("TX_FLUSH_INTERVAL_MS", 500)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.TxFlushIntervalMs < 0 {
+		return nil, fmt.Errorf("TX_FLUSH_INTERVAL_MS must be >= 0, got %d", cfg.TxFlushIntervalMs)
+	}
+
+	cfg.SnapshotIntervalMin, err = optionalInt("SNAPSHOT_INTERVAL_MIN", 60)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.SnapshotIntervalMin < 0 {
+		return nil, fmt.Errorf("SNAPSHOT_INTERVAL_MIN must be >= 0, got %d", cfg.SnapshotIntervalMin)
+	}
+	cfg.SnapshotDir = os.Getenv("SNAPSHOT_DIR")
+	if cfg.SnapshotDir == "" {
+		cfg.SnapshotDir = "snapshots"
+	}
+	cfg.SnapshotKeep, err = optionalInt("SNAPSHOT_KEEP", 48)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.SnapshotKeep < 0 {
+		return nil, fmt.Errorf("SNAPSHOT_KEEP must be >= 0, got %d", cfg.SnapshotKeep)
+	}
+
+	cfg.EvalIntervalMs, err = optionalInt("EVAL_INTERVAL_MS", 250)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.EvalIntervalMs < 0 {
+		return nil, fmt.Errorf("EVAL_INTERVAL_MS must be >= 0, got %d", cfg.EvalIntervalMs)
+	}
+	cfg.EvalPriceChangePct, err = optionalFloat("EVAL_PRICE_CHANGE_PCT", 0.0002)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.EvalPriceChangePct < 0 || cfg.EvalPriceChangePct > 1 {
+		return nil, fmt.Errorf("EVAL_PRICE_CHANGE_PCT must be between 0 and 1, got %.4f", cfg.EvalPriceChangePct)
+	}
+
+	cfg.WatchdogTickerStaleSec, err = optionalInt("WATCHDOG_TICKER_STALE_SEC", 60)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.WatchdogTickerStaleSec < 0 {
+		return nil, fmt.Errorf("WATCHDOG_TICKER_STALE_SEC must be >= 0, got %d", cfg.WatchdogTickerStaleSec)
+	}
+	cfg.WatchdogStreamStaleMin, err = optionalInt("WATCHDOG_STREAM_STALE_MIN", 10)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.WatchdogStreamStaleMin < 0 {
+		return nil, fmt.Errorf("WATCHDOG_STREAM_STALE_MIN must be >= 0, got %d", cfg.WatchdogStreamStaleMin)
+	}
+
+	cfg.ExposureMaxNakedSec, err = optionalInt("EXPOSURE_MAX_NAKED_SEC", 60)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.ExposureMaxNakedSec < 0 {
+		return nil, fmt.Errorf("EXPOSURE_MAX_NAKED_SEC must be >= 0, got %d", cfg.ExposureMaxNakedSec)
+	}
+
 	// We no longer load metrics from .env, but we keep the struct fields for runtime usage if needed.
 	// Actually, user said to remove from .env but keep showing in log.
 	// We can initialize them to 0 or defaults here if we want, or just leave them as 0.
@@ -186,6 +746,125 @@ func Load() (*Config, error) {
 	cfg.BinanceApiKey = os.Getenv("BINANCE_API_KEY")
 	cfg.BinanceSecretKey = os.Getenv("BINANCE_SECRET_KEY")
 
+	// Secret store for the API keys (optional): keeps them out of the plaintext .env
+	source := strings.ToLower(os.Getenv("SECRETS_SOURCE"))
+	if source == "" {
+		source = secrets.SourceEnv
+	}
+	if err := secrets.ValidateSource(source); err != nil {
+		return nil, err
+	}
+	if source != secrets.SourceEnv {
+		if cfg.BinanceApiKey != "" || cfg.BinanceSecretKey != "" {
+			return nil, fmt.Errorf("BINANCE_API_KEY and BINANCE_SECRET_KEY must be empty when SECRETS_SOURCE=%s (remove the plaintext keys)", source)
+		}
+		opts := secrets.Options{
+			Source:         source,
+			KeyringService: os.Getenv("SECRETS_KEYRING_SERVICE"),
+			File:           os.Getenv("SECRETS_FILE"),
+			Passphrase:     os.Getenv("SECRETS_PASSPHRASE"),
+			PassphraseFile: os.Getenv("SECRETS_PASSPHRASE_FILE"),
+			Command:        os.Getenv("SECRETS_COMMAND"),
+		}
+		if opts.KeyringService == "" {
+			opts.KeyringService = secrets.DefaultKeyringService
+		}
+		if opts.File == "" {
+			opts.File = secrets.DefaultFile
+		}
+		keys, err := secrets.Load(opts)
+		if err != nil {
+			return nil, err
+		}
+		cfg.BinanceApiKey, cfg.BinanceSecretKey = keys.APIKey, keys.SecretKey
+	}
+	os.Unsetenv("SECRETS_PASSPHRASE") // Not inherited by child processes
+
+	// Network routing (optional): proxy and alternate endpoints to reach Binance
+	cfg.BinanceBaseURL = strings.TrimSuffix(os.Getenv("BINANCE_BASE_URL"), "/")
+	if err := checkURL("BINANCE_BASE_URL", cfg.BinanceBaseURL, "https", "http"); err != nil {
+		return nil, err
+	}
+	cfg.BinanceCluster = strings.ToLower(os.Getenv("BINANCE_CLUSTER"))
+	switch cfg.BinanceCluster {
+	case "", "api1", "api2", "api3", "api4", "gcp":
+	default:
+		return nil, fmt.Errorf("invalid value for BINANCE_CLUSTER: %q (expected api1, api2, api3, api4, gcp or empty)", cfg.BinanceCluster)
+	}
+	cfg.BinanceStreamURL = strings.TrimSuffix(os.Getenv("BINANCE_STREAM_URL"), "/")
+	if err := checkURL("BINANCE_STREAM_URL", cfg.BinanceStreamURL, "wss", "ws"); err != nil {
+		return nil, err
+	}
+	cfg.BinanceProxy = os.Getenv("BINANCE_PROXY")
+	if err := checkURL("BINANCE_PROXY", cfg.BinanceProxy, "http", "socks5"); err != nil {
+		return nil, err
+	}
+	cfg.EndpointFailover = optionalBool("ENDPOINT_FAILOVER", false)
+	cfg.EndpointProbeIntervalSec, err = optionalInt("ENDPOINT_PROBE_INTERVAL_SEC", 30)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.EndpointProbeIntervalSec < 5 {
+		return nil, fmt.Errorf("ENDPOINT_PROBE_INTERVAL_SEC must be >= 5, got %d", cfg.EndpointProbeIntervalSec)
+	}
+	cfg.EndpointMaxLatencyMs, err = optionalInt("ENDPOINT_MAX_LATENCY_MS", 1000)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.EndpointMaxLatencyMs <= 0 {
+		return nil, fmt.Errorf("ENDPOINT_MAX_LATENCY_MS must be > 0, got %d", cfg.EndpointMaxLatencyMs)
+	}
+	cfg.EndpointMaxErrorRate, err = optionalFloat("ENDPOINT_MAX_ERROR_RATE", 0.25)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.EndpointMaxErrorRate <= 0 || cfg.EndpointMaxErrorRate > 1 {
+		return nil, fmt.Errorf("ENDPOINT_MAX_ERROR_RATE must be between 0 and 1, got %.2f", cfg.EndpointMaxErrorRate)
+	}
+	cfg.HTTPMaxIdleConnsPerHost, err = optionalInt("HTTP_MAX_IDLE_CONNS_PER_HOST", 16)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.HTTPMaxIdleConnsPerHost < 1 {
+		return nil, fmt.Errorf("HTTP_MAX_IDLE_CONNS_PER_HOST must be >= 1, got %d", cfg.HTTPMaxIdleConnsPerHost)
+	}
+	cfg.HTTPIdleConnTimeoutSec, err = optionalInt("HTTP_IDLE_CONN_TIMEOUT_SEC", 90)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.HTTPIdleConnTimeoutSec < 1 {
+		return nil, fmt.Errorf("HTTP_IDLE_CONN_TIMEOUT_SEC must be >= 1, got %d", cfg.HTTPIdleConnTimeoutSec)
+	}
+	cfg.HTTP2Enabled = optionalBool("HTTP2_ENABLED", false)
+	cfg.TimeBiasMs, err = optionalInt("BINANCE_TIME_BIAS_MS", 1000)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.TimeBiasMs < 0 || cfg.TimeBiasMs >= 60000 {
+		return nil, fmt.Errorf("BINANCE_TIME_BIAS_MS must be between 0 and 59999 (the recvWindow), got %d", cfg.TimeBiasMs)
+	}
+	cfg.ClockDriftAlertMs, err = optionalInt("CLOCK_DRIFT_ALERT_MS", 1000)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.ClockDriftAlertMs != 0 && cfg.ClockDriftAlertMs < 500 {
+		return nil, fmt.Errorf("CLOCK_DRIFT_ALERT_MS must be 0 (off) or >= 500, got %d", cfg.ClockDriftAlertMs)
+	}
+
+	// Sub-account (optional): isolates the grid from the funds of the master account
+	cfg.SubAccountEmail = strings.TrimSpace(os.Getenv("SUB_ACCOUNT_EMAIL"))
+	cfg.MasterApiKey = os.Getenv("MASTER_API_KEY")
+	cfg.MasterSecretKey = os.Getenv("MASTER_SECRET_KEY")
+	if cfg.SubAccountEmail != "" && !strings.Contains(cfg.SubAccountEmail, "@") {
+		return nil, fmt.Errorf("invalid value for SUB_ACCOUNT_EMAIL: %q (expected the sub-account email)", cfg.SubAccountEmail)
+	}
+	if (cfg.MasterApiKey == "") != (cfg.MasterSecretKey == "") {
+		return nil, fmt.Errorf("MASTER_API_KEY and MASTER_SECRET_KEY must be set together")
+	}
+	if cfg.MasterApiKey != "" && cfg.SubAccountEmail == "" {
+		return nil, fmt.Errorf("MASTER_API_KEY requires SUB_ACCOUNT_EMAIL (the sub-account to top up)")
+	}
+
 	cfg.TelegramToken = os.Getenv("TELEGRAM_TOKEN")
 	cfg.TelegramChatID = os.Getenv("TELEGRAM_CHAT_ID")
 
@@ -215,6 +894,30 @@ func Load() (*Config, error) {
 		cfg.CrashPauseMin = 15 // 15 min default
 	}
 
+	// Circuit breaker escalation (optional)
+	cfg.CBMaxTrips, err = optionalInt("CB_MAX_TRIPS", 0)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.CBMaxTrips < 0 {
+		return nil, fmt.Errorf("CB_MAX_TRIPS must be >= 0, got %d", cfg.CBMaxTrips)
+	}
+	cfg.CBTripWindowHours, err = optionalInt("CB_TRIP_WINDOW_HOURS", 6)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.CBTripWindowHours < 1 {
+		return nil, fmt.Errorf("CB_TRIP_WINDOW_HOURS must be >= 1, got %d", cfg.CBTripWindowHours)
+	}
+	cfg.CBEscalation = strings.ToLower(os.Getenv("CB_ESCALATION"))
+	switch cfg.CBEscalation {
+	case "":
+		cfg.CBEscalation = "stop"
+	case "widen", "halve", "stop":
+	default:
+		return nil, fmt.Errorf("invalid value for CB_ESCALATION: %q (expected widen, halve or stop)", cfg.CBEscalation)
+	}
+
 	// Soft Panic Button
 	if val := os.Getenv("PAUSE_BUYS"); val == "true" {
 		cfg.PauseBuys = true
@@ -222,25 +925,682 @@ func Load() (*Config, error) {
 		cfg.PauseBuys = false
 	}
 
-	// Metrics API (optional)
-	cfg.MetricsAPIURL = os.Getenv("METRICS_API_URL")
-	cfg.MetricsAPIToken = os.Getenv("METRICS_API_TOKEN")
-
-	return cfg, nil
-}
+	// Drawdown Stop (optional)
+	cfg.DrawdownStopUSDT, err = optionalFloat("DRAWDOWN_STOP_USDT", 0)
+	if err != nil {
+		return nil, err
+	}
+	cfg.DrawdownStopPct, err = optionalFloat("DRAWDOWN_STOP_PCT", 0)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.DrawdownStopUSDT < 0 || cfg.DrawdownStopPct < 0 || cfg.DrawdownStopPct >= 1 {
+		return nil, fmt.Errorf("DRAWDOWN_STOP_USDT must be >= 0 and DRAWDOWN_STOP_PCT between 0 and 1, got %.2f and %.4f", cfg.DrawdownStopUSDT, cfg.DrawdownStopPct)
+	}
+	cfg.DrawdownSellFraction, err = optionalFloat("DRAWDOWN_SELL_FRACTION", 0.5)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.DrawdownSellFraction < 0 || cfg.DrawdownSellFraction > 1 {
+		return nil, fmt.Errorf("DRAWDOWN_SELL_FRACTION must be between 0 and 1, got %.2f", cfg.DrawdownSellFraction)
+	}
+	cfg.DrawdownCooloffMin, err = optionalInt("DRAWDOWN_COOLOFF_MIN", 120)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.DrawdownCooloffMin < 0 {
+		return nil, fmt.Errorf("DRAWDOWN_COOLOFF_MIN must be >= 0, got %d", cfg.DrawdownCooloffMin)
+	}
+	cfg.DrawdownConfirm = optionalBool("DRAWDOWN_CONFIRM", false)
+
+	// Position Sizing (optional)
+	cfg.SizingMode = strings.ToLower(os.Getenv("SIZING_MODE"))
+	switch cfg.SizingMode {
+	case "":
+		cfg.SizingMode = "flat"
+	case "flat", "volatility", "kelly":
+	default:
+		return nil, fmt.Errorf("invalid value for SIZING_MODE: %q (expected flat, volatility or kelly)", cfg.SizingMode)
+	}
+	cfg.SizingTargetVol, err = optionalFloat("SIZING_TARGET_VOL", 0.001)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.SizingTargetVol <= 0 {
+		return nil, fmt.Errorf("SIZING_TARGET_VOL must be > 0, got %.4f", cfg.SizingTargetVol)
+	}
+	cfg.SizingMinOrderUSDT, err = optionalFloat("SIZING_MIN_ORDER_USDT", 0)
+	if err != nil {
+		return nil, err
+	}
+	cfg.SizingMaxOrderUSDT, err = optionalFloat("SIZING_MAX_ORDER_USDT", 0)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.SizingMinOrderUSDT < 0 || cfg.SizingMaxOrderUSDT < 0 || (cfg.SizingMaxOrderUSDT > 0 && cfg.SizingMaxOrderUSDT < cfg.SizingMinOrderUSDT) {
+		return nil, fmt.Errorf("SIZING_MIN_ORDER_USDT and SIZING_MAX_ORDER_USDT must be >= 0 with max >= min, got %.2f and %.2f", cfg.SizingMinOrderUSDT, cfg.SizingMaxOrderUSDT)
+	}
+	cfg.KellyFraction, err = optionalFloat("KELLY_FRACTION", 0.25)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.KellyFraction <= 0 || cfg.KellyFraction > 1 {
+		return nil, fmt.Errorf("KELLY_FRACTION must be between 0 and 1, got %.2f", cfg.KellyFraction)
+	}
+	cfg.KellyMaxSizePct, err = optionalFloat("KELLY_MAX_SIZE_PCT", 0.05)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.KellyMaxSizePct <= 0 || cfg.KellyMaxSizePct > 1 {
+		return nil, fmt.Errorf("KELLY_MAX_SIZE_PCT must be between 0 and 1, got %.4f", cfg.KellyMaxSizePct)
+	}
+	cfg.KellyMinTrades, err = optionalInt("KELLY_MIN_TRADES", 30)
+	if err != nil {
+		return nil, err
+	}
+	cfg.KellyLookbackDays, err = optionalInt("KELLY_LOOKBACK_DAYS", 30)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.KellyMinTrades < 1 || cfg.KellyLookbackDays < 1 {
+		return nil, fmt.Errorf("KELLY_MIN_TRADES and KELLY_LOOKBACK_DAYS must be >= 1, got %d and %d", cfg.KellyMinTrades, cfg.KellyLookbackDays)
+	}
+
+	// Trading Windows (optional)
+	windowsTZ := time.UTC
+	if tz := os.Getenv("TRADING_WINDOWS_TZ"); tz != "" {
+		windowsTZ, err = time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for TRADING_WINDOWS_TZ: %q (%v)", tz, err)
+		}
+	}
+	cfg.TradingWindows, err = ParseTradingWindows(os.Getenv("TRADING_WINDOWS"), windowsTZ)
+	if err != nil {
+		return nil, err
+	}
+
+	// Macro Calendar (optional)
+	cfg.MacroCalendarFile = os.Getenv("MACRO_CALENDAR_FILE")
+	cfg.MacroCalendarURL = os.Getenv("MACRO_CALENDAR_URL")
+	if err := checkURL("MACRO_CALENDAR_URL", cfg.MacroCalendarURL, "https", "http"); err != nil {
+		return nil, err
+	}
+	cfg.MacroCalendarCountries = splitList(os.Getenv("MACRO_CALENDAR_COUNTRIES"), strings.ToUpper)
+	if len(cfg.MacroCalendarCountries) == 0 {
+		cfg.MacroCalendarCountries = []string{"USD"}
+	}
+	cfg.MacroCalendarImpact = strings.ToLower(os.Getenv("MACRO_CALENDAR_IMPACT"))
+	if cfg.MacroCalendarImpact == "" {
+		cfg.MacroCalendarImpact = "high"
+	}
+	if cfg.MacroCalendarImpact != "high" && cfg.MacroCalendarImpact != "medium" {
+		return nil, fmt.Errorf("MACRO_CALENDAR_IMPACT must be high or medium, got %q", cfg.MacroCalendarImpact)
+	}
+	cfg.MacroCalendarKeywords = splitList(os.Getenv("MACRO_CALENDAR_KEYWORDS"), strings.ToLower)
+	cfg.MacroCalendarTZ = time.UTC
+	if tz := os.Getenv("MACRO_CALENDAR_TZ"); tz != "" {
+		cfg.MacroCalendarTZ, err = time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for MACRO_CALENDAR_TZ: %q (%v)", tz, err)
+		}
+	}
+	cfg.MacroCalendarRefreshHours, err = optionalInt("MACRO_CALENDAR_REFRESH_HOURS", 6)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MacroCalendarRefreshHours < 1 {
+		return nil, fmt.Errorf("MACRO_CALENDAR_REFRESH_HOURS must be >= 1, got %d", cfg.MacroCalendarRefreshHours)
+	}
+	cfg.MacroPauseBeforeMin, err = optionalInt("MACRO_PAUSE_BEFORE_MIN", 30)
+	if err != nil {
+		return nil, err
+	}
+	cfg.MacroPauseAfterMin, err = optionalInt("MACRO_PAUSE_AFTER_MIN", 60)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MacroPauseBeforeMin < 0 || cfg.MacroPauseAfterMin < 0 || cfg.MacroPauseBeforeMin+cfg.MacroPauseAfterMin == 0 {
+		return nil, fmt.Errorf("MACRO_PAUSE_BEFORE_MIN and MACRO_PAUSE_AFTER_MIN must be >= 0 and not both 0, got %d and %d", cfg.MacroPauseBeforeMin, cfg.MacroPauseAfterMin)
+	}
+
+	// Order-Book Imbalance Filter (optional)
+	cfg.BookImbalanceFilter = optionalBool("BOOK_IMBALANCE_FILTER", false)
+	cfg.BookImbalanceSource = strings.ToLower(os.Getenv("BOOK_IMBALANCE_SOURCE"))
+	switch cfg.BookImbalanceSource {
+	case "":
+		cfg.BookImbalanceSource = "depth"
+	case "depth", "ticker":
+	default:
+		return nil, fmt.Errorf("invalid value for BOOK_IMBALANCE_SOURCE: %q (expected depth or ticker)", cfg.BookImbalanceSource)
+	}
+	cfg.BookImbalanceDepth, err = optionalInt("BOOK_IMBALANCE_DEPTH", 20)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.BookImbalanceDepth < 1 || cfg.BookImbalanceDepth > 5000 {
+		return nil, fmt.Errorf("BOOK_IMBALANCE_DEPTH must be between 1 and 5000, got %d", cfg.BookImbalanceDepth)
+	}
+	cfg.BookImbalanceThreshold, err = optionalFloat("BOOK_IMBALANCE_THRESHOLD", 0.6)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.BookImbalanceThreshold <= 0 || cfg.BookImbalanceThreshold >= 1 {
+		return nil, fmt.Errorf("BOOK_IMBALANCE_THRESHOLD must be between 0 and 1, got %.2f", cfg.BookImbalanceThreshold)
+	}
+	cfg.BookImbalanceMaxDelaySec, err = optionalInt("BOOK_IMBALANCE_MAX_DELAY_SEC", 300)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.BookImbalanceMaxDelaySec < 0 {
+		return nil, fmt.Errorf("BOOK_IMBALANCE_MAX_DELAY_SEC must be >= 0, got %d", cfg.BookImbalanceMaxDelaySec)
+	}
+
+	// Buy Placement (optional)
+	cfg.PlacementMode = strings.ToLower(os.Getenv("PLACEMENT_MODE"))
+	switch cfg.PlacementMode {
+	case "":
+		cfg.PlacementMode = "spacing"
+	case "spacing", "support":
+	default:
+		return nil, fmt.Errorf("invalid value for PLACEMENT_MODE: %q (expected spacing or support)", cfg.PlacementMode)
+	}
+	cfg.SRMethod = strings.ToLower(os.Getenv("SR_METHOD"))
+	switch cfg.SRMethod {
+	case "":
+		cfg.SRMethod = "swings"
+	case "swings", "volume":
+	default:
+		return nil, fmt.Errorf("invalid value for SR_METHOD: %q (expected swings or volume)", cfg.SRMethod)
+	}
+	cfg.SRInterval = os.Getenv("SR_INTERVAL")
+	switch cfg.SRInterval {
+	case "":
+		cfg.SRInterval = "15m"
+	case "1m", "3m", "5m", "15m", "30m", "1h", "2h", "4h", "6h", "8h", "12h", "1d":
+	default:
+		return nil, fmt.Errorf("invalid value for SR_INTERVAL: %q (expected a Binance kline interval from 1m to 1d)", cfg.SRInterval)
+	}
+	cfg.SRLookback, err = optionalInt("SR_LOOKBACK", 200)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.SRLookback < 20 || cfg.SRLookback > 1000 {
+		return nil, fmt.Errorf("SR_LOOKBACK must be between 20 and 1000, got %d", cfg.SRLookback)
+	}
+	cfg.SRSwingWindow, err = optionalInt("SR_SWING_WINDOW", 3)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.SRSwingWindow < 1 || cfg.SRSwingWindow*2 >= cfg.SRLookback {
+		return nil, fmt.Errorf("SR_SWING_WINDOW must be between 1 and SR_LOOKBACK/2, got %d", cfg.SRSwingWindow)
+	}
+	cfg.SRMaxSnapPct, err = optionalFloat("SR_MAX_SNAP_PCT", 0.005)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.SRMaxSnapPct <= 0 || cfg.SRMaxSnapPct >= 0.1 {
+		return nil, fmt.Errorf("SR_MAX_SNAP_PCT must be between 0 and 0.1, got %v", cfg.SRMaxSnapPct)
+	}
+	cfg.SROffsetPct, err = optionalFloat("SR_OFFSET_PCT", 0.0005)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.SROffsetPct < 0 || cfg.SROffsetPct >= cfg.SRMaxSnapPct {
+		return nil, fmt.Errorf("SR_OFFSET_PCT must be between 0 and SR_MAX_SNAP_PCT (%v), got %v", cfg.SRMaxSnapPct, cfg.SROffsetPct)
+	}
+
+	// Futures Hedge (optional)
+	cfg.HedgeEnabled = optionalBool("HEDGE_ENABLED", false)
+	cfg.HedgeSymbol = strings.ToUpper(os.Getenv("HEDGE_SYMBOL"))
+	if cfg.HedgeSymbol == "" {
+		cfg.HedgeSymbol = cfg.Symbol
+	}
+	cfg.HedgeInventoryUSDT, err = optionalFloat("HEDGE_INVENTORY_USDT", 0)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.HedgeEnabled && cfg.HedgeInventoryUSDT <= 0 {
+		return nil, fmt.Errorf("HEDGE_INVENTORY_USDT must be > 0 when HEDGE_ENABLED=true, got %.2f", cfg.HedgeInventoryUSDT)
+	}
+	cfg.HedgeRatio, err = optionalFloat("HEDGE_RATIO", 0.3)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.HedgeRatio <= 0 || cfg.HedgeRatio > 1 {
+		return nil, fmt.Errorf("HEDGE_RATIO must be between 0 and 1, got %.2f", cfg.HedgeRatio)
+	}
+	cfg.HedgeTrendHours, err = optionalInt("HEDGE_TREND_HOURS", 24)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.HedgeTrendHours < 2 || cfg.HedgeTrendHours > 1000 {
+		return nil, fmt.Errorf("HEDGE_TREND_HOURS must be between 2 and 1000, got %d", cfg.HedgeTrendHours)
+	}
+	cfg.HedgeLeverage, err = optionalInt("HEDGE_LEVERAGE", 1)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.HedgeLeverage < 1 || cfg.HedgeLeverage > 20 {
+		return nil, fmt.Errorf("HEDGE_LEVERAGE must be between 1 and 20, got %d", cfg.HedgeLeverage)
+	}
+
+	// Simple Earn (optional): park the quote asset the grid does not need in Flexible savings
+	cfg.EarnEnabled = optionalBool("EARN_ENABLED", false)
+	cfg.EarnBufferOrders, err = optionalInt("EARN_BUFFER_ORDERS", 3)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.EarnBufferOrders < 1 {
+		return nil, fmt.Errorf("EARN_BUFFER_ORDERS must be >= 1, got %d", cfg.EarnBufferOrders)
+	}
+	cfg.EarnMinUSDT, err = optionalFloat("EARN_MIN_USDT", 10)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.EarnMinUSDT <= 0 {
+		return nil, fmt.Errorf("EARN_MIN_USDT must be > 0, got %.2f", cfg.EarnMinUSDT)
+	}
+
+	// USDT Reserve (optional): floor of free USDT the strategy never deploys
+	cfg.USDTReserve, err = optionalFloat("USDT_RESERVE", 0)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.USDTReserve < 0 {
+		return nil, fmt.Errorf("USDT_RESERVE must be >= 0, got %.2f", cfg.USDTReserve)
+	}
+
+	// Profit Compounding (optional): size orders from capital + realized profits
+	cfg.CompoundProfits = optionalBool("COMPOUND_PROFITS", false)
+	cfg.CompoundBaseCapital, err = optionalFloat("COMPOUND_BASE_CAPITAL", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// Entry sizing (optional): without it, and without compounding, a sizing mode or zones,
+	// every grid buy is placed at the min notional
+	cfg.SizeFromPositionPct = optionalBool("SIZE_FROM_POSITION_PCT", false)
+
+	// Profit Vault (optional): skim a share of realized profits out of trading
+	cfg.VaultSkimPct, err = optionalFloat("VAULT_SKIM_PCT", 0)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.VaultSkimPct < 0 || cfg.VaultSkimPct > 1 {
+		return nil, fmt.Errorf("VAULT_SKIM_PCT must be between 0 and 1, got %.4f", cfg.VaultSkimPct)
+	}
+	cfg.VaultMode = strings.ToLower(os.Getenv("VAULT_MODE"))
+	switch cfg.VaultMode {
+	case "":
+		cfg.VaultMode = "accounting"
+	case "accounting", "transfer":
+	default:
+		return nil, fmt.Errorf("invalid value for VAULT_MODE: %q (expected accounting or transfer)", cfg.VaultMode)
+	}
+	cfg.VaultTransferMinUSDT, err = optionalFloat("VAULT_TRANSFER_MIN_USDT", 10)
+	if err != nil {
+		return nil, err
+	}
+
+	// Inventory Rebalancer (optional)
+	cfg.RebalanceEnabled = optionalBool("REBALANCE_ENABLED", false)
+	cfg.RebalanceTargetRatio, err = optionalFloat("REBALANCE_TARGET_RATIO", 0.5)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.RebalanceTargetRatio < 0 || cfg.RebalanceTargetRatio > 1 {
+		return nil, fmt.Errorf("REBALANCE_TARGET_RATIO must be between 0 and 1, got %.4f", cfg.RebalanceTargetRatio)
+	}
+	cfg.RebalanceBand, err = optionalFloat("REBALANCE_BAND", 0.10)
+	if err != nil {
+		return nil, err
+	}
+	cfg.RebalanceMaxOrderUSDT, err = optionalFloat("REBALANCE_MAX_ORDER_USDT", 50)
+	if err != nil {
+		return nil, err
+	}
+	cfg.RebalanceCooldownMin, err = optionalInt("REBALANCE_COOLDOWN_MIN", 30)
+	if err != nil {
+		return nil, err
+	}
+
+	// Hourly Collector / Trade Ledger: optional JSON Lines copy of the CSV records
+	cfg.CollectorJSONOutput = optionalBool("COLLECTOR_JSON_OUTPUT", false)
+
+	// Time-Series Metrics Sink (optional)
+	cfg.MetricsSink = os.Getenv("METRICS_SINK")
+	cfg.InfluxURL = os.Getenv("INFLUX_URL")
+	cfg.InfluxToken = os.Getenv("INFLUX_TOKEN")
+	cfg.InfluxOrg = os.Getenv("INFLUX_ORG")
+	cfg.InfluxBucket = os.Getenv("INFLUX_BUCKET")
+	switch cfg.MetricsSink {
+	case "":
+	case "influxdb":
+		if cfg.InfluxURL == "" || cfg.InfluxBucket == "" {
+			return nil, fmt.Errorf("METRICS_SINK=influxdb requires INFLUX_URL and INFLUX_BUCKET")
+		}
+	default:
+		return nil, fmt.Errorf("invalid value for METRICS_SINK: %q (expected influxdb or empty)", cfg.MetricsSink)
+	}
+
+	// Remote Backup (optional)
+	cfg.BackupTarget = strings.ToLower(os.Getenv("BACKUP_TARGET"))
+	cfg.BackupIntervalMin, err = optionalInt("BACKUP_INTERVAL_MIN", 360)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.BackupIntervalMin < 1 {
+		return nil, fmt.Errorf("BACKUP_INTERVAL_MIN must be >= 1, got %d", cfg.BackupIntervalMin)
+	}
+	cfg.BackupRetentionDays, err = optionalInt("BACKUP_RETENTION_DAYS", 30)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.BackupRetentionDays < 0 {
+		return nil, fmt.Errorf("BACKUP_RETENTION_DAYS must be >= 0, got %d", cfg.BackupRetentionDays)
+	}
+	cfg.BackupS3Endpoint = os.Getenv("BACKUP_S3_ENDPOINT")
+	if cfg.BackupS3Endpoint == "" {
+		cfg.BackupS3Endpoint = "https://s3.amazonaws.com"
+	}
+	cfg.BackupS3Region = os.Getenv("BACKUP_S3_REGION")
+	if cfg.BackupS3Region == "" {
+		cfg.BackupS3Region = "us-east-1"
+	}
+	cfg.BackupS3Bucket = os.Getenv("BACKUP_S3_BUCKET")
+	cfg.BackupS3Prefix = os.Getenv("BACKUP_S3_PREFIX")
+	cfg.BackupS3AccessKey = os.Getenv("BACKUP_S3_ACCESS_KEY")
+	cfg.BackupS3SecretKey = os.Getenv("BACKUP_S3_SECRET_KEY")
+	cfg.BackupSFTPHost = os.Getenv("BACKUP_SFTP_HOST")
+	cfg.BackupSFTPPort, err = optionalInt("BACKUP_SFTP_PORT", 0)
+	if err != nil {
+		return nil, err
+	}
+	cfg.BackupSFTPKey = os.Getenv("BACKUP_SFTP_KEY")
+	cfg.BackupSFTPDir = os.Getenv("BACKUP_SFTP_DIR")
+	if cfg.BackupSFTPDir == "" {
+		cfg.BackupSFTPDir = "grid-bot-backups"
+	}
+	switch cfg.BackupTarget {
+	case "":
+	case "s3":
+		if cfg.BackupS3Bucket == "" || cfg.BackupS3AccessKey == "" || cfg.BackupS3SecretKey == "" {
+			return nil, fmt.Errorf("BACKUP_TARGET=s3 requires BACKUP_S3_BUCKET, BACKUP_S3_ACCESS_KEY and BACKUP_S3_SECRET_KEY")
+		}
+	case "sftp":
+		if cfg.BackupSFTPHost == "" {
+			return nil, fmt.Errorf("BACKUP_TARGET=sftp requires BACKUP_SFTP_HOST")
+		}
+	default:
+		return nil, fmt.Errorf("invalid value for BACKUP_TARGET: %q (expected s3, sftp or empty)", cfg.BackupTarget)
+	}
+
+	// Metrics API (optional)
+	cfg.MetricsAPIURL = os.Getenv("METRICS_API_URL")
+	cfg.MetricsAPIToken = os.Getenv("METRICS_API_TOKEN")
+
+	// Logging (applied by main right after the configuration is loaded)
+	levelName := os.Getenv("LOG_LEVEL")
+	if levelName == "" {
+		levelName = "debug"
+	}
+	if cfg.LogLevel, err = logger.ParseLevel(levelName); err != nil {
+		return nil, fmt.Errorf("invalid value for LOG_LEVEL: %w", err)
+	}
+	cfg.LogOutput = os.Getenv("LOG_OUTPUT")
+	if cfg.LogOutput == "" {
+		cfg.LogOutput = logger.OutputFile
+	}
+	cfg.LogFile = os.Getenv("LOG_FILE")
+	if cfg.LogFile == "" {
+		cfg.LogFile = logger.DefaultFile
+	}
+	cfg.LogErrorFile = os.Getenv("LOG_ERROR_FILE")
+	if cfg.LogModuleLevels, err = logger.ParseModuleLevels(os.Getenv("LOG_MODULE_LEVELS")); err != nil {
+		return nil, fmt.Errorf("invalid value for LOG_MODULE_LEVELS: %w", err)
+	}
+
+	// Trade Audit Log: order intents, account-changing API calls and status transitions
+	cfg.AuditEnabled = optionalBool("AUDIT_ENABLED", true)
+	cfg.AuditDir = os.Getenv("AUDIT_DIR")
+	if cfg.AuditDir == "" {
+		cfg.AuditDir = audit.DefaultDir
+	}
+
+	// Tracing (optional): ticker -> decision -> API call -> WS fill -> exit placement
+	cfg.TracingEndpoint = os.Getenv("TRACING_ENDPOINT")
+	cfg.TracingSampleRatio, err = optionalFloat("TRACING_SAMPLE_RATIO", 1.0)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.TracingSampleRatio <= 0 || cfg.TracingSampleRatio > 1 {
+		return nil, fmt.Errorf("TRACING_SAMPLE_RATIO must be between 0 and 1, got %.2f", cfg.TracingSampleRatio)
+	}
+
+	// Crash Reporting (optional): recovered panics are also sent to Sentry
+	cfg.SentryDSN = os.Getenv("SENTRY_DSN")
+	cfg.SentryEnvironment = os.Getenv("SENTRY_ENVIRONMENT")
+	if cfg.SentryEnvironment == "" {
+		cfg.SentryEnvironment = "production"
+	}
+
+	// Additional Notification Channels (optional, can be combined with Telegram)
+	cfg.DiscordWebhookURL = os.Getenv("DISCORD_WEBHOOK_URL")
+	cfg.SlackWebhookURL = os.Getenv("SLACK_WEBHOOK_URL")
+	cfg.WebhookURL = os.Getenv("WEBHOOK_URL")
+	cfg.WebhookToken = os.Getenv("WEBHOOK_TOKEN")
+
+	// Notification Preferences (all categories enabled by default)
+	cfg.NotifyEntryFills = optionalBool("NOTIFY_ENTRY_FILLS", true)
+	cfg.NotifyExits = optionalBool("NOTIFY_EXITS", true)
+	cfg.NotifyCircuitBreaker = optionalBool("NOTIFY_CIRCUIT_BREAKER", true)
+	cfg.NotifyLowBalance = optionalBool("NOTIFY_LOW_BALANCE", true)
+	cfg.NotifySyncEvents = optionalBool("NOTIFY_SYNC_EVENTS", true)
+	cfg.NotifyErrors = optionalBool("NOTIFY_ERRORS", true)
+
+	cfg.NotifyMinSeverity = strings.ToLower(os.Getenv("NOTIFY_MIN_SEVERITY"))
+	switch cfg.NotifyMinSeverity {
+	case "":
+		cfg.NotifyMinSeverity = "info"
+	case "info", "warning", "critical":
+	default:
+		return nil, fmt.Errorf("invalid value for NOTIFY_MIN_SEVERITY: %q (expected info, warning or critical)", cfg.NotifyMinSeverity)
+	}
+
+	// Price Alerts (optional)
+	cfg.PriceAlerts, err = ParsePriceAlerts(os.Getenv("PRICE_ALERTS"))
+	if err != nil {
+		return nil, err
+	}
+	cfg.PriceAlertCooldownMin, err = optionalInt("PRICE_ALERT_COOLDOWN_MIN", 30)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.PriceAlertCooldownMin < 0 {
+		return nil, fmt.Errorf("PRICE_ALERT_COOLDOWN_MIN must be >= 0, got %d", cfg.PriceAlertCooldownMin)
+	}
+
+	// BNB Auto Top-Up (disabled by default: only alerts)
+	cfg.BNBAutoTopUp = optionalBool("BNB_AUTO_TOPUP", false)
+	cfg.BNBTopUpAmountUSDT, err = optionalFloat("BNB_TOPUP_AMOUNT_USDT", 10.0)
+	if err != nil {
+		return nil, err
+	}
+	cfg.BNBTopUpMaxPerDay, err = optionalInt("BNB_TOPUP_MAX_PER_DAY", 1)
+	if err != nil {
+		return nil, err
+	}
+
+	// Dust Conversion (disabled by default)
+	cfg.DustConvert = optionalBool("DUST_CONVERT", false)
+
+	// Kill switch on startup (cancel all, market-sell inventory, pause)
+	cfg.PanicOnStart = optionalBool("PANIC_ON_START", false)
+
+	// Startup cleanup preview (after editing the state files or trading by hand)
+	cfg.SyncDryRun = optionalBool("SYNC_DRY_RUN", false)
+
+	// Monitor-only: market data, user stream and account reads, but no order is ever sent
+	cfg.MonitorOnly = optionalBool("MONITOR_ONLY", false)
+
+	// Single-instance guard (trading instances only)
+	cfg.InstanceGuard = strings.ToLower(os.Getenv("INSTANCE_GUARD"))
+	if cfg.InstanceGuard == "" {
+		cfg.InstanceGuard = "refuse"
+	}
+	switch cfg.InstanceGuard {
+	case "refuse", "monitor", "off":
+	default:
+		return nil, fmt.Errorf("invalid value for INSTANCE_GUARD: %q (expected refuse, monitor or off)", cfg.InstanceGuard)
+	}
+
+	// Optional directory with <name>.tmpl files overriding the built-in message templates
+	cfg.NotifyTemplatesDir = os.Getenv("NOTIFY_TEMPLATES_DIR")
+	cfg.AccountName = strings.TrimSpace(os.Getenv("ACCOUNT_NAME"))
 
-func UpdateEnvVariable(key, value string) error {
-	envMap, err := godotenv.Read()
+	// Parameter Profiles (profiles.<name> in the config file, switched with /profile)
+	if file != nil {
+		cfg.Profiles = file.profiles
+	}
+	cfg.Profile = strings.ToLower(os.Getenv("PROFILE"))
+	if cfg.Profile != "" && !cfg.HasProfile(cfg.Profile) {
+		return nil, fmt.Errorf("PROFILE %q is not defined in the config file profiles", cfg.Profile)
+	}
+	cfg.ProfileAutoSwitchTo = strings.ToLower(os.Getenv("PROFILE_AUTO_SWITCH_TO"))
+	if cfg.ProfileAutoSwitchTo != "" && !cfg.HasProfile(cfg.ProfileAutoSwitchTo) {
+		return nil, fmt.Errorf("PROFILE_AUTO_SWITCH_TO %q is not defined in the config file profiles", cfg.ProfileAutoSwitchTo)
+	}
+	cfg.ProfileAutoSwitchTriggers, err = optionalInt("PROFILE_AUTO_SWITCH_TRIGGERS", 2)
 	if err != nil {
-		return fmt.Errorf("error reading .env file: %w", err)
+		return nil, err
+	}
+	if cfg.ProfileAutoSwitchTriggers < 1 {
+		return nil, fmt.Errorf("PROFILE_AUTO_SWITCH_TRIGGERS must be >= 1, got %d", cfg.ProfileAutoSwitchTriggers)
 	}
 
-	envMap[key] = value
+	// Shadow Strategy (optional): runs SHADOW_PROFILE on paper next to the live grid
+	cfg.ShadowProfile = strings.ToLower(os.Getenv("SHADOW_PROFILE"))
+	if cfg.ShadowProfile != "" && !cfg.HasProfile(cfg.ShadowProfile) {
+		return nil, fmt.Errorf("SHADOW_PROFILE %q is not defined in the config file profiles", cfg.ShadowProfile)
+	}
+	cfg.ShadowCapitalUSDT, err = optionalFloat("SHADOW_CAPITAL_USDT", 0)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.ShadowCapitalUSDT < 0 {
+		return nil, fmt.Errorf("SHADOW_CAPITAL_USDT must be >= 0, got %.2f", cfg.ShadowCapitalUSDT)
+	}
 
-	if err := godotenv.Write(envMap, ".env"); err != nil {
-		return fmt.Errorf("error writing .env file: %w", err)
+	// Health Endpoints (for systemd/Docker/Kubernetes probes)
+	cfg.HealthAddr = os.Getenv("HEALTH_ADDR")
+	cfg.HealthMaxTickerAgeSec, err = optionalInt("HEALTH_MAX_TICKER_AGE_SEC", 60)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.HealthMaxTickerAgeSec < 1 {
+		return nil, fmt.Errorf("HEALTH_MAX_TICKER_AGE_SEC must be >= 1, got %d", cfg.HealthMaxTickerAgeSec)
 	}
-	return nil
+	cfg.HealthMaxStreamAgeSec, err = optionalInt("HEALTH_MAX_STREAM_AGE_SEC", 600)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.HealthMaxStreamAgeSec < 1 {
+		return nil, fmt.Errorf("HEALTH_MAX_STREAM_AGE_SEC must be >= 1, got %d", cfg.HealthMaxStreamAgeSec)
+	}
+	cfg.HealthMaxAPIErrorRate, err = optionalFloat("HEALTH_MAX_API_ERROR_RATE", 0.5)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.HealthMaxAPIErrorRate <= 0 || cfg.HealthMaxAPIErrorRate > 1 {
+		return nil, fmt.Errorf("HEALTH_MAX_API_ERROR_RATE must be between 0 and 1, got %.2f", cfg.HealthMaxAPIErrorRate)
+	}
+
+	// TradingView Webhook (optional)
+	cfg.TradingViewAddr = os.Getenv("TRADINGVIEW_ADDR")
+	cfg.TradingViewSecret = os.Getenv("TRADINGVIEW_SECRET")
+	if cfg.TradingViewAddr != "" && len(cfg.TradingViewSecret) < 16 {
+		return nil, fmt.Errorf("TRADINGVIEW_SECRET must have at least 16 characters when TRADINGVIEW_ADDR is set, got %d", len(cfg.TradingViewSecret))
+	}
+	cfg.TradingViewMaxAgeSec, err = optionalInt("TRADINGVIEW_MAX_AGE_SEC", 300)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.TradingViewMaxAgeSec < 0 {
+		return nil, fmt.Errorf("TRADINGVIEW_MAX_AGE_SEC must be >= 0, got %d", cfg.TradingViewMaxAgeSec)
+	}
+	cfg.TradingViewAllowFlatten = optionalBool("TRADINGVIEW_ALLOW_FLATTEN", false)
+
+	// Leader Election (optional)
+	cfg.LeaderLock = strings.ToLower(os.Getenv("LEADER_LOCK"))
+	cfg.LeaderLockFile = os.Getenv("LEADER_LOCK_FILE")
+	if cfg.LeaderLockFile == "" {
+		cfg.LeaderLockFile = "leader.lock"
+	}
+	cfg.LeaderRedisAddr = os.Getenv("LEADER_REDIS_ADDR")
+	if cfg.LeaderRedisAddr == "" {
+		cfg.LeaderRedisAddr = "localhost:6379"
+	}
+	cfg.LeaderRedisPassword = os.Getenv("LEADER_REDIS_PASSWORD")
+	cfg.LeaderLockKey = os.Getenv("LEADER_LOCK_KEY")
+	if cfg.LeaderLockKey == "" {
+		cfg.LeaderLockKey = "grid-bot:leader:" + cfg.Symbol
+	}
+	cfg.LeaderLeaseSec, err = optionalInt("LEADER_LEASE_SEC", 15)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.LeaderLeaseSec < 5 {
+		return nil, fmt.Errorf("LEADER_LEASE_SEC must be >= 5, got %d", cfg.LeaderLeaseSec)
+	}
+	cfg.LeaderID = os.Getenv("LEADER_ID")
+	switch cfg.LeaderLock {
+	case "", "file", "redis":
+	default:
+		return nil, fmt.Errorf("invalid value for LEADER_LOCK: %q (expected file, redis or empty)", cfg.LeaderLock)
+	}
+
+	// Margin Account (optional)
+	cfg.AccountType = strings.ToLower(os.Getenv("ACCOUNT_TYPE"))
+	switch cfg.AccountType {
+	case "":
+		cfg.AccountType = "spot"
+	case "spot", "cross", "isolated":
+	default:
+		return nil, fmt.Errorf("invalid value for ACCOUNT_TYPE: %q (expected spot, cross or isolated)", cfg.AccountType)
+	}
+	cfg.MarginAutoBorrow = optionalBool("MARGIN_AUTO_BORROW", false)
+	cfg.MarginMaxBorrow, err = optionalFloat("MARGIN_MAX_BORROW", 0)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MarginAutoBorrow && cfg.MarginMaxBorrow <= 0 {
+		return nil, fmt.Errorf("MARGIN_MAX_BORROW must be > 0 when MARGIN_AUTO_BORROW is enabled, got %.2f", cfg.MarginMaxBorrow)
+	}
+	cfg.MarginLevelWarn, err = optionalFloat("MARGIN_LEVEL_WARN", 1.5)
+	if err != nil {
+		return nil, err
+	}
+	cfg.MarginLevelCritical, err = optionalFloat("MARGIN_LEVEL_CRITICAL", 1.2)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MarginLevelCritical <= 1 || cfg.MarginLevelWarn <= cfg.MarginLevelCritical {
+		return nil, fmt.Errorf("MARGIN_LEVEL_WARN and MARGIN_LEVEL_CRITICAL must satisfy warn > critical > 1, got %.2f and %.2f", cfg.MarginLevelWarn, cfg.MarginLevelCritical)
+	}
+	if cfg.AccountType != "spot" && cfg.VaultMode == "transfer" {
+		return nil, fmt.Errorf("VAULT_MODE=transfer moves Spot funds and is not supported with ACCOUNT_TYPE=%s", cfg.AccountType)
+	}
+	if cfg.AccountType != "spot" && cfg.EarnEnabled {
+		return nil, fmt.Errorf("EARN_ENABLED subscribes from the Spot wallet and is not supported with ACCOUNT_TYPE=%s", cfg.AccountType)
+	}
+	if cfg.AccountType != "spot" && cfg.DustConvert {
+		return nil, fmt.Errorf("DUST_CONVERT converts Spot balances and is not supported with ACCOUNT_TYPE=%s", cfg.AccountType)
+	}
+	if cfg.AccountType == "isolated" && cfg.BNBAutoTopUp {
+		return nil, fmt.Errorf("BNB_AUTO_TOPUP trades BNB%s and is not supported with ACCOUNT_TYPE=isolated", cfg.QuoteAsset)
+	}
+
+	return cfg, nil
 }
 
 func parseFloat(value, name string) (float64, error) {
@@ -254,6 +1614,65 @@ func parseFloat(value, name string) (float64, error) {
 	return f, nil
 }
 
+// splitList reads a comma separated setting, normalizing each item (empty items are dropped)
+func splitList(raw string, normalize func(string) string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = normalize(strings.TrimSpace(item)); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// checkURL validates an optional URL setting: absolute, with a host and one of schemes
+func checkURL(name, value string, schemes ...string) error {
+	if value == "" {
+		return nil
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid value for %s: %q (expected %s://host[:port])", name, value, schemes[0])
+	}
+	for _, scheme := range schemes {
+		if u.Scheme == scheme {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid value for %s: %q (expected the scheme %s)", name, value, strings.Join(schemes, " or "))
+}
+
+// optionalFloat parses an optional env var, returning def when it is unset
+func optionalFloat(name string, def float64) (float64, error) {
+	val := os.Getenv(name)
+	if val == "" {
+		return def, nil
+	}
+	return parseFloat(val, name)
+}
+
+// optionalInt parses an optional env var, returning def when it is unset
+func optionalInt(name string, def int) (int, error) {
+	val := os.Getenv(name)
+	if val == "" {
+		return def, nil
+	}
+	return parseInt(val, name)
+}
+
+// optionalBool follows the same convention as CRASH_PROTECTION_ENABLED/PAUSE_BUYS:
+// only the literal strings "true"/"false" override the default.
+func optionalBool(name string, def bool) bool {
+	switch os.Getenv(name) {
+	case "true":
+		return true
+	case "false":
+		return false
+	default:
+		return def
+	}
+}
+
 func parseInt(value, name string) (int, error) {
 	if value == "" {
 		return 0, fmt.Errorf("%s is required", name)
@@ -0,0 +1,192 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const defaultConfigFile = "config.yaml"
+
+// fileValues is a config file flattened to env var names
+type fileValues struct {
+	base       map[string]string
+	symbols    map[string]map[string]string // symbols.<SYMBOL>: applied when it is the active symbol
+	strategies map[string]map[string]string // strategies.<mode>: applied when it is the active STRATEGY_MODE
+	profiles   map[string]map[string]string // profiles.<name>: switched at runtime (PROFILE, /profile)
+}
+
+// applyConfigFile loads CONFIG_FILE (default config.yaml, optional) into the environment.
+// Nested keys map to the env names by joining the path with "_" (grid.levels -> GRID_LEVELS).
+// Precedence: real env vars and .env > symbols.<SYMBOL> > strategies.<mode> > top level.
+// It returns the parsed file (nil when there is none); every unknown or mistyped key is
+// returned at once.
+func applyConfigFile() (*fileValues, error) {
+	path := os.Getenv("CONFIG_FILE")
+	if path == "" {
+		path = defaultConfigFile
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return nil, nil
+		}
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file: %w", err)
+	}
+	values, problems := parseConfigFile(path, raw)
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return nil, &ValidationError{Problems: problems}
+	}
+
+	// Resolve the active symbol and mode first: they select the override sections
+	resolve := func(name string) string {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+		return values.base[name]
+	}
+	merged := make(map[string]string, len(values.base))
+	for k, v := range values.base {
+		merged[k] = v
+	}
+	for k, v := range values.strategies[strings.ToLower(resolve("STRATEGY_MODE"))] {
+		merged[k] = v
+	}
+	for k, v := range values.symbols[strings.ToUpper(resolve("SYMBOL"))] {
+		merged[k] = v
+	}
+
+	for k, v := range merged {
+		if os.Getenv(k) != "" {
+			continue // Env wins (empty = unset, like the optional* helpers)
+		}
+		os.Setenv(k, v)
+	}
+	return &values, nil
+}
+
+// parseConfigFile flattens the YAML document, checking every key against the schema
+func parseConfigFile(path string, raw []byte) (fileValues, []string) {
+	values := fileValues{
+		base:       map[string]string{},
+		symbols:    map[string]map[string]string{},
+		strategies: map[string]map[string]string{},
+		profiles:   map[string]map[string]string{},
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return values, []string{fmt.Sprintf("%s: %v", path, err)}
+	}
+	if len(doc.Content) == 0 {
+		return values, nil // Empty file
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return values, []string{fmt.Sprintf("%s:%d: top level must be a mapping of keys", path, root.Line)}
+	}
+
+	var problems []string
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, node := root.Content[i], root.Content[i+1]
+		switch strings.ToLower(key.Value) {
+		case "symbols", "strategies":
+			if node.Kind != yaml.MappingNode {
+				problems = append(problems, fmt.Sprintf("%s:%d: %s must map names to sections", path, node.Line, key.Value))
+				continue
+			}
+			target := values.symbols
+			normalize := strings.ToUpper
+			if strings.ToLower(key.Value) == "strategies" {
+				target, normalize = values.strategies, strings.ToLower
+			}
+			for j := 0; j+1 < len(node.Content); j += 2 {
+				name := normalize(node.Content[j].Value)
+				section := map[string]string{}
+				problems = append(problems, flatten(path, "", key.Value+"."+node.Content[j].Value, node.Content[j+1], section)...)
+				target[name] = section
+			}
+		case "profiles":
+			problems = append(problems, parseProfiles(path, node, values.profiles)...)
+		default:
+			problems = append(problems, flattenKey(path, "", "", key, node, values.base)...)
+		}
+	}
+	return values, problems
+}
+
+// parseProfiles reads the profiles.<name> sections. Names are case-insensitive and a section
+// may only set the keys listed in profileFields.
+func parseProfiles(path string, node *yaml.Node, out map[string]map[string]string) []string {
+	if node.Kind != yaml.MappingNode {
+		return []string{fmt.Sprintf("%s:%d: profiles must map names to sections", path, node.Line)}
+	}
+	var problems []string
+	for j := 0; j+1 < len(node.Content); j += 2 {
+		key := node.Content[j]
+		name := strings.ToLower(key.Value)
+		if name == DefaultProfile {
+			problems = append(problems, fmt.Sprintf("%s:%d: profile name %q is reserved for the base values", path, key.Line, DefaultProfile))
+			continue
+		}
+		at := "profiles." + key.Value
+		section := map[string]string{}
+		problems = append(problems, flatten(path, "", at, node.Content[j+1], section)...)
+		for k := range section {
+			if _, ok := profileFields[k]; !ok {
+				problems = append(problems, fmt.Sprintf("%s:%d: %s: %s cannot be set by a profile", path, key.Line, at, k))
+			}
+		}
+		out[name] = section
+	}
+	return problems
+}
+
+// flatten walks a mapping node, storing its scalar leaves under their env names. prefix is
+// the env name so far, at the key path as written in the file (for error messages).
+func flatten(path, prefix, at string, node *yaml.Node, out map[string]string) []string {
+	if node.Kind != yaml.MappingNode {
+		return []string{fmt.Sprintf("%s:%d: expected a mapping of keys", path, node.Line)}
+	}
+	var problems []string
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		problems = append(problems, flattenKey(path, prefix, at, node.Content[i], node.Content[i+1], out)...)
+	}
+	return problems
+}
+
+func flattenKey(path, prefix, at string, key, node *yaml.Node, out map[string]string) []string {
+	name := strings.ToUpper(key.Value)
+	if prefix != "" {
+		name = prefix + "_" + name
+	}
+	if at != "" {
+		at += "."
+	}
+	at += key.Value
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		return flatten(path, name, at, node, out)
+	case yaml.ScalarNode:
+		f, known := schema[name]
+		if !known {
+			return []string{fmt.Sprintf("%s:%d: unknown key %s (%s)", path, key.Line, at, name)}
+		}
+		if node.Value == "" || node.Tag == "!!null" {
+			return nil
+		}
+		if err := f.check(node.Value); err != nil {
+			return []string{fmt.Sprintf("%s:%d: %s: %v", path, node.Line, at, err)}
+		}
+		out[name] = node.Value
+		return nil
+	default:
+		return []string{fmt.Sprintf("%s:%d: %s must be a single value", path, node.Line, at)}
+	}
+}
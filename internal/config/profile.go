@@ -0,0 +1,101 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// DefaultProfile names the values loaded from .env and the config file, before any profile
+const DefaultProfile = "default"
+
+// profileFields are the keys a profile may set. The strategy reads all of them on every
+// cycle, so a profile switch takes effect without a restart.
+var profileFields = map[string]func(c *Config) interface{}{
+	"GRID_LEVELS":                func(c *Config) interface{} { return &c.GridLevels },
+	"GRID_SPACING_PCT":           func(c *Config) interface{} { return &c.GridSpacingPct },
+	"POSITION_SIZE_PCT":          func(c *Config) interface{} { return &c.PositionSizePct },
+	"MIN_NET_PROFIT_PCT":         func(c *Config) interface{} { return &c.MinNetProfitPct },
+	"USDT_RESERVE":               func(c *Config) interface{} { return &c.USDTReserve },
+	"HIGH_VOL_MULTIPLIER":        func(c *Config) interface{} { return &c.HighVolMultiplier },
+	"LOW_VOL_MULTIPLIER":         func(c *Config) interface{} { return &c.LowVolMultiplier },
+	"MIN_DYNAMIC_SPACING_PCT":    func(c *Config) interface{} { return &c.MinDynamicSpacingPct },
+	"MAX_DYNAMIC_SPACING_PCT":    func(c *Config) interface{} { return &c.MaxDynamicSpacingPct },
+	"SMART_ENTRY_REPOSITION_PCT": func(c *Config) interface{} { return &c.SmartEntryRepositionPct },
+	"MAX_BUY_ORDER_DISTANCE_PCT": func(c *Config) interface{} { return &c.MaxBuyOrderDistancePct },
+	"MAX_DROP_PCT_5M":            func(c *Config) interface{} { return &c.MaxDropPct5m },
+	"CRASH_PAUSE_MIN":            func(c *Config) interface{} { return &c.CrashPauseMin },
+	"DCA_BUY_AMOUNT_USDT":        func(c *Config) interface{} { return &c.DCABuyAmountUSDT },
+	"DCA_DROP_PCT":               func(c *Config) interface{} { return &c.DCADropPct },
+	"DCA_TAKE_PROFIT_PCT":        func(c *Config) interface{} { return &c.DCATakeProfitPct },
+}
+
+// ProfileNames returns the configured profile names, sorted, after DefaultProfile
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return append([]string{DefaultProfile}, names...)
+}
+
+// HasProfile reports whether name is DefaultProfile or a configured profile
+func (c *Config) HasProfile(name string) bool {
+	if name == DefaultProfile {
+		return true
+	}
+	_, ok := c.Profiles[name]
+	return ok
+}
+
+// WithProfile returns a copy of the configuration with the profile applied on top
+// (used by the shadow strategy; the receiver is not changed)
+func (c *Config) WithProfile(name string) (*Config, error) {
+	if !c.HasProfile(name) {
+		return nil, fmt.Errorf("unknown profile %q", name)
+	}
+	copied := *c
+	if name != DefaultProfile {
+		if err := copied.ApplyValues(c.Profiles[name]); err != nil {
+			return nil, fmt.Errorf("profile %s: %w", name, err)
+		}
+	}
+	return &copied, nil
+}
+
+// ProfileSnapshot returns the current value of every key a profile may set, so the
+// defaults can be restored with ApplyValues after switching back
+func (c *Config) ProfileSnapshot() map[string]string {
+	values := make(map[string]string, len(profileFields))
+	for name, ptr := range profileFields {
+		switch v := ptr(c).(type) {
+		case *float64:
+			values[name] = strconv.FormatFloat(*v, 'f', -1, 64)
+		case *int:
+			values[name] = strconv.Itoa(*v)
+		}
+	}
+	return values
+}
+
+// ApplyValues sets profile keys (env name -> raw value). Nothing is changed on error.
+func (c *Config) ApplyValues(values map[string]string) error {
+	for name, raw := range values {
+		if _, ok := profileFields[name]; !ok {
+			return fmt.Errorf("%s cannot be set by a profile", name)
+		}
+		if err := schema[name].check(raw); err != nil {
+			return fmt.Errorf("invalid value for %s: %v", name, err)
+		}
+	}
+	for name, raw := range values {
+		switch v := profileFields[name](c).(type) {
+		case *float64:
+			*v, _ = strconv.ParseFloat(raw, 64)
+		case *int:
+			*v, _ = strconv.Atoi(raw)
+		}
+	}
+	return nil
+}
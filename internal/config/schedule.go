@@ -0,0 +1,151 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TradingWindow is one entry of TRADING_WINDOWS: during it new grid entries are paused or
+// sized by SizeFactor. Start/End are minutes of the day in TRADING_WINDOWS_TZ; an End before
+// Start runs past midnight (the day spec refers to the day the window starts).
+type TradingWindow struct {
+	Label      string  // The entry as written
+	Days       [7]bool // By time.Weekday (unused when Date is set)
+	Date       string  // YYYY-MM-DD, for one-off windows (macro events)
+	Start      int     // Minutes since 00:00
+	End        int     // Minutes since 00:00 (1440 = 24:00)
+	Pause      bool    // No new entries
+	SizeFactor float64 // Order value multiplier when not paused
+	Location   *time.Location
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ParseTradingWindows reads TRADING_WINDOWS: entries separated by ";", each
+// "<days> <HH:MM>-<HH:MM> <pause|size=F>". days is daily, a weekday (mon), a range (mon-fri),
+// a list (sat,sun) or a date (2026-11-04).
+func ParseTradingWindows(raw string, loc *time.Location) ([]TradingWindow, error) {
+	var windows []TradingWindow
+	for _, entry := range strings.Split(raw, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		w, err := parseTradingWindow(strings.ToLower(entry))
+		if err != nil {
+			return nil, fmt.Errorf("invalid TRADING_WINDOWS entry %q: %w", entry, err)
+		}
+		w.Label = entry
+		w.Location = loc
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+func parseTradingWindow(entry string) (TradingWindow, error) {
+	var w TradingWindow
+	fields := strings.Fields(entry)
+	if len(fields) != 3 {
+		return w, fmt.Errorf("expected \"<days> <HH:MM>-<HH:MM> <pause|size=F>\"")
+	}
+
+	if err := w.parseDays(fields[0]); err != nil {
+		return w, err
+	}
+
+	start, end, ok := strings.Cut(fields[1], "-")
+	if !ok {
+		return w, fmt.Errorf("expected a time range HH:MM-HH:MM, got %q", fields[1])
+	}
+	var err error
+	if w.Start, err = parseClock(start); err != nil {
+		return w, err
+	}
+	if w.End, err = parseClock(end); err != nil {
+		return w, err
+	}
+	if w.Start == w.End || w.Start == 24*60 {
+		return w, fmt.Errorf("empty time range %q", fields[1])
+	}
+
+	switch action := fields[2]; {
+	case action == "pause":
+		w.Pause = true
+	case strings.HasPrefix(action, "size="):
+		w.SizeFactor, err = strconv.ParseFloat(strings.TrimPrefix(action, "size="), 64)
+		if err != nil || w.SizeFactor <= 0 || w.SizeFactor > 1 {
+			return w, fmt.Errorf("size must be between 0 and 1, got %q", action)
+		}
+	default:
+		return w, fmt.Errorf("unknown action %q (expected pause or size=F)", action)
+	}
+	return w, nil
+}
+
+func (w *TradingWindow) parseDays(spec string) error {
+	if spec == "daily" {
+		for d := range w.Days {
+			w.Days[d] = true
+		}
+		return nil
+	}
+	if _, err := time.Parse("2006-01-02", spec); err == nil {
+		w.Date = spec
+		return nil
+	}
+	for _, part := range strings.Split(spec, ",") {
+		from, to, isRange := strings.Cut(part, "-")
+		first, ok := weekdays[from]
+		if !ok {
+			return fmt.Errorf("unknown day %q (expected daily, mon..sun or YYYY-MM-DD)", from)
+		}
+		last := first
+		if isRange {
+			if last, ok = weekdays[to]; !ok {
+				return fmt.Errorf("unknown day %q (expected mon..sun)", to)
+			}
+		}
+		for d := first; ; d = (d + 1) % 7 {
+			w.Days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func parseClock(s string) (int, error) {
+	h, m, ok := strings.Cut(s, ":")
+	hour, errH := strconv.Atoi(h)
+	minute, errM := strconv.Atoi(m)
+	if !ok || errH != nil || errM != nil || hour < 0 || minute < 0 || minute > 59 || hour > 24 || (hour == 24 && minute > 0) {
+		return 0, fmt.Errorf("invalid time %q (expected HH:MM, 00:00 to 24:00)", s)
+	}
+	return hour*60 + minute, nil
+}
+
+// Contains reports whether t falls inside the window
+func (w TradingWindow) Contains(t time.Time) bool {
+	if w.Location != nil {
+		t = t.In(w.Location)
+	}
+	minute := t.Hour()*60 + t.Minute()
+	if w.Start < w.End {
+		return w.onDay(t) && minute >= w.Start && minute < w.End
+	}
+	// Past midnight: the tail belongs to the previous day
+	return (w.onDay(t) && minute >= w.Start) || (w.onDay(t.AddDate(0, 0, -1)) && minute < w.End)
+}
+
+func (w TradingWindow) onDay(t time.Time) bool {
+	if w.Date != "" {
+		return t.Format("2006-01-02") == w.Date
+	}
+	return w.Days[t.Weekday()]
+}
@@ -0,0 +1,342 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type valueKind int
+
+const (
+	kindString valueKind = iota
+	kindFloat
+	kindInt
+	kindBool
+)
+
+// field describes one configuration key (its env var name)
+type field struct {
+	kind     valueKind
+	required bool
+	enum     []string // Allowed values (case-insensitive), empty = any
+}
+
+// schema lists every key the bot reads. The config file is checked against it (unknown keys
+// are errors) and so is the final environment, before Load parses anything.
+var schema = map[string]field{
+	"SYMBOL":             {kind: kindString, required: true},
+	"BASE_ASSET":         {kind: kindString},
+	"QUOTE_ASSET":        {kind: kindString},
+	"MAKER_FEE_PCT":      {kind: kindFloat, required: true},
+	"TAKER_FEE_PCT":      {kind: kindFloat, required: true},
+	"FEE_MODEL":          {kind: kindString, enum: []string{"auto", "standard", "bnb", "zero"}},
+	"GRID_LEVELS":        {kind: kindInt, required: true},
+	"GRID_SPACING_PCT":   {kind: kindFloat, required: true},
+	"GRID_ZONES":         {kind: kindString},
+	"POSITION_SIZE_PCT":  {kind: kindFloat, required: true},
+	"MIN_NET_PROFIT_PCT": {kind: kindFloat, required: true},
+	"STOP_LOSS_PCT":      {kind: kindFloat, required: true},
+	"MAX_SPREAD_PCT":     {kind: kindFloat, required: true},
+	"RANGE_MIN":          {kind: kindFloat, required: true},
+	"RANGE_MAX":          {kind: kindFloat, required: true},
+	"MIN_ORDER_VALUE":    {kind: kindFloat, required: true},
+
+	"RANGE_CHECK":          {kind: kindBool},
+	"RANGE_AUTO_APPLY":     {kind: kindBool},
+	"RANGE_SUGGEST_DAYS":   {kind: kindInt},
+	"RANGE_SUGGEST_STDDEV": {kind: kindFloat},
+	"RANGE_SUGGEST_ATR":    {kind: kindFloat},
+
+	"HIGH_VOL_MULTIPLIER":                 {kind: kindFloat},
+	"LOW_VOL_MULTIPLIER":                  {kind: kindFloat},
+	"REGIME_ENTER_RATIO":                  {kind: kindFloat},
+	"REGIME_EXIT_RATIO":                   {kind: kindFloat},
+	"REGIME_MIN_DWELL_MIN":                {kind: kindInt},
+	"MIN_DYNAMIC_SPACING_PCT":             {kind: kindFloat},
+	"MAX_DYNAMIC_SPACING_PCT":             {kind: kindFloat},
+	"SMART_ENTRY_REPOSITION_PCT":          {kind: kindFloat},
+	"SMART_ENTRY_REPOSITION_COOLDOWN_MIN": {kind: kindInt},
+	"SMART_ENTRY_REPOSITION_MAX_IDLE_MIN": {kind: kindInt},
+	"MAX_BUY_ORDER_AGE_MIN":               {kind: kindInt},
+	"MAX_BUY_ORDER_DISTANCE_PCT":          {kind: kindFloat},
+	"EXIT_FALLBACK_MODE":                  {kind: kindString, enum: []string{"none", "market", "limit"}},
+	"EXIT_FALLBACK_AFTER":                 {kind: kindInt},
+	"EXIT_FALLBACK_MAX_SLIPPAGE_PCT":      {kind: kindFloat},
+	"EXIT_RECOVERY_INTERVAL_MIN":          {kind: kindInt},
+	"EXIT_RECOVERY_DEADLINE_MIN":          {kind: kindInt},
+
+	"STRATEGY_MODE":       {kind: kindString, enum: []string{"grid", "dca"}},
+	"DCA_BUY_AMOUNT_USDT": {kind: kindFloat},
+	"DCA_DROP_PCT":        {kind: kindFloat},
+	"DCA_INTERVAL_MIN":    {kind: kindInt},
+	"DCA_TAKE_PROFIT_PCT": {kind: kindFloat},
+	"DCA_MAX_STACK_USDT":  {kind: kindFloat},
+
+	"TRADE_RECONCILE_INTERVAL_MIN": {kind: kindInt},
+	"WS_UPDATE_WORKERS":            {kind: kindInt},
+	"TX_FLUSH_INTERVAL_MS":         {kind: kindInt},
+	"SNAPSHOT_INTERVAL_MIN":        {kind: kindInt},
+	"SNAPSHOT_DIR":                 {kind: kindString},
+	"SNAPSHOT_KEEP":                {kind: kindInt},
+	"EVAL_INTERVAL_MS":             {kind: kindInt},
+	"EVAL_PRICE_CHANGE_PCT":        {kind: kindFloat},
+	"WATCHDOG_TICKER_STALE_SEC":    {kind: kindInt},
+	"WATCHDOG_STREAM_STALE_MIN":    {kind: kindInt},
+	"EXPOSURE_MAX_NAKED_SEC":       {kind: kindInt},
+
+	"BINANCE_API_KEY":    {kind: kindString},
+	"BINANCE_SECRET_KEY": {kind: kindString},
+	"BINANCE_BASE_URL":   {kind: kindString},
+	"BINANCE_CLUSTER":    {kind: kindString, enum: []string{"api1", "api2", "api3", "api4", "gcp"}},
+	"BINANCE_STREAM_URL": {kind: kindString},
+	"BINANCE_PROXY":      {kind: kindString},
+	"SUB_ACCOUNT_EMAIL":  {kind: kindString},
+	"MASTER_API_KEY":     {kind: kindString},
+	"MASTER_SECRET_KEY":  {kind: kindString},
+
+	"ENDPOINT_FAILOVER":           {kind: kindBool},
+	"ENDPOINT_PROBE_INTERVAL_SEC": {kind: kindInt},
+	"ENDPOINT_MAX_LATENCY_MS":     {kind: kindInt},
+	"ENDPOINT_MAX_ERROR_RATE":     {kind: kindFloat},
+
+	"HTTP_MAX_IDLE_CONNS_PER_HOST": {kind: kindInt},
+	"HTTP_IDLE_CONN_TIMEOUT_SEC":   {kind: kindInt},
+	"HTTP2_ENABLED":                {kind: kindBool},
+	"BINANCE_TIME_BIAS_MS":         {kind: kindInt},
+	"CLOCK_DRIFT_ALERT_MS":         {kind: kindInt},
+
+	"SECRETS_SOURCE":          {kind: kindString, enum: []string{"env", "keyring", "file", "command"}},
+	"SECRETS_KEYRING_SERVICE": {kind: kindString},
+	"SECRETS_FILE":            {kind: kindString},
+	"SECRETS_PASSPHRASE":      {kind: kindString},
+	"SECRETS_PASSPHRASE_FILE": {kind: kindString},
+	"SECRETS_COMMAND":         {kind: kindString},
+	"TELEGRAM_TOKEN":          {kind: kindString},
+	"TELEGRAM_CHAT_ID":        {kind: kindString},
+
+	"CRASH_PROTECTION_ENABLED":     {kind: kindBool},
+	"MAX_DROP_PCT_5M":              {kind: kindFloat},
+	"CRASH_PAUSE_MIN":              {kind: kindInt},
+	"CB_MAX_TRIPS":                 {kind: kindInt},
+	"CB_TRIP_WINDOW_HOURS":         {kind: kindInt},
+	"CB_ESCALATION":                {kind: kindString, enum: []string{"widen", "halve", "stop"}},
+	"PAUSE_BUYS":                   {kind: kindBool},
+	"PANIC_ON_START":               {kind: kindBool},
+	"SYNC_DRY_RUN":                 {kind: kindBool},
+	"MONITOR_ONLY":                 {kind: kindBool},
+	"INSTANCE_GUARD":               {kind: kindString, enum: []string{"refuse", "monitor", "off"}},
+	"DRAWDOWN_STOP_USDT":           {kind: kindFloat},
+	"DRAWDOWN_STOP_PCT":            {kind: kindFloat},
+	"DRAWDOWN_SELL_FRACTION":       {kind: kindFloat},
+	"DRAWDOWN_COOLOFF_MIN":         {kind: kindInt},
+	"DRAWDOWN_CONFIRM":             {kind: kindBool},
+	"SIZING_MODE":                  {kind: kindString, enum: []string{"flat", "volatility", "kelly"}},
+	"SIZING_TARGET_VOL":            {kind: kindFloat},
+	"SIZING_MIN_ORDER_USDT":        {kind: kindFloat},
+	"SIZING_MAX_ORDER_USDT":        {kind: kindFloat},
+	"KELLY_FRACTION":               {kind: kindFloat},
+	"KELLY_MAX_SIZE_PCT":           {kind: kindFloat},
+	"KELLY_MIN_TRADES":             {kind: kindInt},
+	"KELLY_LOOKBACK_DAYS":          {kind: kindInt},
+	"TRADING_WINDOWS":              {kind: kindString},
+	"TRADING_WINDOWS_TZ":           {kind: kindString},
+	"BOOK_IMBALANCE_FILTER":        {kind: kindBool},
+	"BOOK_IMBALANCE_SOURCE":        {kind: kindString, enum: []string{"depth", "ticker"}},
+	"BOOK_IMBALANCE_DEPTH":         {kind: kindInt},
+	"BOOK_IMBALANCE_THRESHOLD":     {kind: kindFloat},
+	"BOOK_IMBALANCE_MAX_DELAY_SEC": {kind: kindInt},
+	"PLACEMENT_MODE":               {kind: kindString, enum: []string{"spacing", "support"}},
+	"SR_METHOD":                    {kind: kindString, enum: []string{"swings", "volume"}},
+	"SR_INTERVAL":                  {kind: kindString},
+	"SR_LOOKBACK":                  {kind: kindInt},
+	"SR_SWING_WINDOW":              {kind: kindInt},
+	"SR_MAX_SNAP_PCT":              {kind: kindFloat},
+	"SR_OFFSET_PCT":                {kind: kindFloat},
+	"HEDGE_ENABLED":                {kind: kindBool},
+	"HEDGE_SYMBOL":                 {kind: kindString},
+	"HEDGE_INVENTORY_USDT":         {kind: kindFloat},
+	"HEDGE_RATIO":                  {kind: kindFloat},
+	"HEDGE_TREND_HOURS":            {kind: kindInt},
+	"HEDGE_LEVERAGE":               {kind: kindInt},
+	"EARN_ENABLED":                 {kind: kindBool},
+	"EARN_BUFFER_ORDERS":           {kind: kindInt},
+	"EARN_MIN_USDT":                {kind: kindFloat},
+	"ACCOUNT_TYPE":                 {kind: kindString, enum: []string{"spot", "cross", "isolated"}},
+	"MARGIN_AUTO_BORROW":           {kind: kindBool},
+	"MARGIN_MAX_BORROW":            {kind: kindFloat},
+	"MARGIN_LEVEL_WARN":            {kind: kindFloat},
+	"MARGIN_LEVEL_CRITICAL":        {kind: kindFloat},
+
+	"USDT_RESERVE":            {kind: kindFloat},
+	"COMPOUND_PROFITS":        {kind: kindBool},
+	"COMPOUND_BASE_CAPITAL":   {kind: kindFloat},
+	"SIZE_FROM_POSITION_PCT":  {kind: kindBool},
+	"VAULT_SKIM_PCT":          {kind: kindFloat},
+	"VAULT_MODE":              {kind: kindString, enum: []string{"accounting", "transfer"}},
+	"VAULT_TRANSFER_MIN_USDT": {kind: kindFloat},
+
+	"REBALANCE_ENABLED":        {kind: kindBool},
+	"REBALANCE_TARGET_RATIO":   {kind: kindFloat},
+	"REBALANCE_BAND":           {kind: kindFloat},
+	"REBALANCE_MAX_ORDER_USDT": {kind: kindFloat},
+	"REBALANCE_COOLDOWN_MIN":   {kind: kindInt},
+
+	"BNB_AUTO_TOPUP":        {kind: kindBool},
+	"BNB_TOPUP_AMOUNT_USDT": {kind: kindFloat},
+	"BNB_TOPUP_MAX_PER_DAY": {kind: kindInt},
+	"DUST_CONVERT":          {kind: kindBool},
+
+	"COLLECTOR_JSON_OUTPUT": {kind: kindBool},
+	"METRICS_SINK":          {kind: kindString, enum: []string{"influxdb"}},
+	"INFLUX_URL":            {kind: kindString},
+	"INFLUX_TOKEN":          {kind: kindString},
+	"INFLUX_ORG":            {kind: kindString},
+	"INFLUX_BUCKET":         {kind: kindString},
+	"BACKUP_TARGET":         {kind: kindString, enum: []string{"s3", "sftp"}},
+	"BACKUP_INTERVAL_MIN":   {kind: kindInt},
+	"BACKUP_RETENTION_DAYS": {kind: kindInt},
+	"BACKUP_S3_ENDPOINT":    {kind: kindString},
+	"BACKUP_S3_REGION":      {kind: kindString},
+	"BACKUP_S3_BUCKET":      {kind: kindString},
+	"BACKUP_S3_PREFIX":      {kind: kindString},
+	"BACKUP_S3_ACCESS_KEY":  {kind: kindString},
+	"BACKUP_S3_SECRET_KEY":  {kind: kindString},
+	"BACKUP_SFTP_HOST":      {kind: kindString},
+	"BACKUP_SFTP_PORT":      {kind: kindInt},
+	"BACKUP_SFTP_KEY":       {kind: kindString},
+	"BACKUP_SFTP_DIR":       {kind: kindString},
+	"METRICS_API_URL":       {kind: kindString},
+	"METRICS_API_TOKEN":     {kind: kindString},
+	"LOG_LEVEL":             {kind: kindString, enum: []string{"debug", "info", "warn", "error"}},
+	"LOG_OUTPUT":            {kind: kindString, enum: []string{"file", "console", "both"}},
+	"LOG_FILE":              {kind: kindString},
+	"LOG_ERROR_FILE":        {kind: kindString},
+	"LOG_MODULE_LEVELS":     {kind: kindString},
+	"AUDIT_ENABLED":         {kind: kindBool},
+	"AUDIT_DIR":             {kind: kindString},
+	"TRACING_ENDPOINT":      {kind: kindString},
+	"TRACING_SAMPLE_RATIO":  {kind: kindFloat},
+	"SENTRY_DSN":            {kind: kindString},
+	"SENTRY_ENVIRONMENT":    {kind: kindString},
+
+	"DISCORD_WEBHOOK_URL":    {kind: kindString},
+	"SLACK_WEBHOOK_URL":      {kind: kindString},
+	"WEBHOOK_URL":            {kind: kindString},
+	"WEBHOOK_TOKEN":          {kind: kindString},
+	"NOTIFY_ENTRY_FILLS":     {kind: kindBool},
+	"NOTIFY_EXITS":           {kind: kindBool},
+	"NOTIFY_CIRCUIT_BREAKER": {kind: kindBool},
+	"NOTIFY_LOW_BALANCE":     {kind: kindBool},
+	"NOTIFY_SYNC_EVENTS":     {kind: kindBool},
+	"NOTIFY_ERRORS":          {kind: kindBool},
+	"NOTIFY_MIN_SEVERITY":    {kind: kindString, enum: []string{"info", "warning", "critical"}},
+	"NOTIFY_TEMPLATES_DIR":   {kind: kindString},
+	"ACCOUNT_NAME":           {kind: kindString},
+
+	"PRICE_ALERTS":             {kind: kindString},
+	"PRICE_ALERT_COOLDOWN_MIN": {kind: kindInt},
+
+	"PROFILE":                      {kind: kindString},
+	"PROFILE_AUTO_SWITCH_TO":       {kind: kindString},
+	"PROFILE_AUTO_SWITCH_TRIGGERS": {kind: kindInt},
+	"SHADOW_PROFILE":               {kind: kindString},
+	"SHADOW_CAPITAL_USDT":          {kind: kindFloat},
+
+	"HEALTH_ADDR":               {kind: kindString},
+	"HEALTH_MAX_TICKER_AGE_SEC": {kind: kindInt},
+	"HEALTH_MAX_STREAM_AGE_SEC": {kind: kindInt},
+	"HEALTH_MAX_API_ERROR_RATE": {kind: kindFloat},
+
+	"TRADINGVIEW_ADDR":          {kind: kindString},
+	"TRADINGVIEW_SECRET":        {kind: kindString},
+	"TRADINGVIEW_MAX_AGE_SEC":   {kind: kindInt},
+	"TRADINGVIEW_ALLOW_FLATTEN": {kind: kindBool},
+
+	"MACRO_CALENDAR_FILE":          {kind: kindString},
+	"MACRO_CALENDAR_URL":           {kind: kindString},
+	"MACRO_CALENDAR_COUNTRIES":     {kind: kindString},
+	"MACRO_CALENDAR_IMPACT":        {kind: kindString, enum: []string{"high", "medium"}},
+	"MACRO_CALENDAR_KEYWORDS":      {kind: kindString},
+	"MACRO_CALENDAR_TZ":            {kind: kindString},
+	"MACRO_CALENDAR_REFRESH_HOURS": {kind: kindInt},
+	"MACRO_PAUSE_BEFORE_MIN":       {kind: kindInt},
+	"MACRO_PAUSE_AFTER_MIN":        {kind: kindInt},
+
+	"LEADER_LOCK":           {kind: kindString, enum: []string{"file", "redis"}},
+	"LEADER_LOCK_FILE":      {kind: kindString},
+	"LEADER_REDIS_ADDR":     {kind: kindString},
+	"LEADER_REDIS_PASSWORD": {kind: kindString},
+	"LEADER_LOCK_KEY":       {kind: kindString},
+	"LEADER_LEASE_SEC":      {kind: kindInt},
+	"LEADER_ID":             {kind: kindString},
+
+	// Listed in .env.example but not read by the bot (accepted so old files convert as-is)
+	"APP":                {kind: kindString},
+	"EXCHANGE":           {kind: kindString},
+	"SOURCE":             {kind: kindString},
+	"STATE_KEY":          {kind: kindString},
+	"MS_TIME_PRODUCTION": {kind: kindInt},
+	"TOTAL_CYCLES":       {kind: kindInt},
+}
+
+// check validates a raw value against the field (empty values are "unset")
+func (f field) check(value string) error {
+	switch f.kind {
+	case kindFloat:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+	case kindInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("%q is not an integer", value)
+		}
+	case kindBool:
+		if value != "true" && value != "false" {
+			return fmt.Errorf("%q is not true or false", value)
+		}
+	}
+	if len(f.enum) > 0 {
+		for _, allowed := range f.enum {
+			if strings.EqualFold(value, allowed) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%q is not one of %s", value, strings.Join(f.enum, ", "))
+	}
+	return nil
+}
+
+// ValidationError lists every invalid key found, so they can all be fixed in one go
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// validateEnv checks the final environment (.env + config file + real env vars) against
+// the schema: required keys present and every set key of the right type
+func validateEnv() error {
+	var problems []string
+	for name, f := range schema {
+		value := os.Getenv(name)
+		if value == "" {
+			if f.required {
+				problems = append(problems, fmt.Sprintf("%s is required", name))
+			}
+			continue
+		}
+		if err := f.check(value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	return &ValidationError{Problems: problems}
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GridZone is one entry of GRID_ZONES: a price band of the range with its own share of the
+// grid capital, level budget and (optionally) fixed spacing
+type GridZone struct {
+	Label   string  // The entry as written
+	Min     float64 // Inclusive
+	Max     float64 // Exclusive
+	Capital float64 // Share of the grid capital (0.3 = 30%)
+	Levels  int     // Orders the zone may hold at once; its capital is split evenly among them
+	Spacing float64 // Fixed spacing for entries and exits in the zone (0 = dynamic)
+}
+
+// Contains reports whether price falls inside the zone
+func (z GridZone) Contains(price float64) bool {
+	return price >= z.Min && price < z.Max
+}
+
+// ParseGridZones reads GRID_ZONES: entries separated by ";", each
+// "<min>-<max> capital=F levels=N [spacing=F]". Zones may not overlap and their capital
+// shares may not add up to more than 1.
+func ParseGridZones(raw string) ([]GridZone, error) {
+	var zones []GridZone
+	total := 0.0
+	for _, entry := range strings.Split(raw, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		z, err := parseGridZone(strings.ToLower(entry))
+		if err != nil {
+			return nil, fmt.Errorf("invalid GRID_ZONES entry %q: %w", entry, err)
+		}
+		z.Label = entry
+		total += z.Capital
+		zones = append(zones, z)
+	}
+
+	sort.Slice(zones, func(i, j int) bool { return zones[i].Min < zones[j].Min })
+	for i := 1; i < len(zones); i++ {
+		if zones[i].Min < zones[i-1].Max {
+			return nil, fmt.Errorf("GRID_ZONES %q and %q overlap", zones[i-1].Label, zones[i].Label)
+		}
+	}
+	if total > 1+1e-9 {
+		return nil, fmt.Errorf("GRID_ZONES capital shares add up to %.2f, more than 1", total)
+	}
+	return zones, nil
+}
+
+func parseGridZone(entry string) (GridZone, error) {
+	var z GridZone
+	fields := strings.Fields(entry)
+	if len(fields) < 3 {
+		return z, fmt.Errorf("expected \"<min>-<max> capital=F levels=N [spacing=F]\"")
+	}
+
+	lo, hi, ok := strings.Cut(fields[0], "-")
+	min, errMin := strconv.ParseFloat(lo, 64)
+	max, errMax := strconv.ParseFloat(hi, 64)
+	if !ok || errMin != nil || errMax != nil || min <= 0 || max <= min {
+		return z, fmt.Errorf("expected a price band min-max with 0 < min < max, got %q", fields[0])
+	}
+	z.Min, z.Max = min, max
+
+	for _, field := range fields[1:] {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return z, fmt.Errorf("expected key=value, got %q", field)
+		}
+		var err error
+		switch key {
+		case "capital":
+			z.Capital, err = strconv.ParseFloat(value, 64)
+			if err == nil && (z.Capital <= 0 || z.Capital > 1) {
+				err = fmt.Errorf("must be between 0 and 1")
+			}
+		case "levels":
+			z.Levels, err = strconv.Atoi(value)
+			if err == nil && z.Levels < 1 {
+				err = fmt.Errorf("must be >= 1")
+			}
+		case "spacing":
+			z.Spacing, err = strconv.ParseFloat(value, 64)
+			if err == nil && (z.Spacing < 0 || z.Spacing >= 1) {
+				err = fmt.Errorf("must be between 0 and 1")
+			}
+		default:
+			return z, fmt.Errorf("unknown key %q (expected capital, levels or spacing)", key)
+		}
+		if err != nil {
+			return z, fmt.Errorf("%s=%s: %v", key, value, err)
+		}
+	}
+	if z.Capital == 0 || z.Levels == 0 {
+		return z, fmt.Errorf("capital and levels are required")
+	}
+	return z, nil
+}
@@ -0,0 +1,95 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"grid-trading-btc-binance/internal/api"
+	"grid-trading-btc-binance/internal/audit"
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/service"
+)
+
+// tryBNBTopUp buys BNB_TOPUP_AMOUNT_USDT worth of BNB with a market order so fees keep
+// being paid in BNB. Returns false when disabled, capped or failed (caller falls back to the alert).
+func (s *Strategy) tryBNBTopUp() bool {
+	if !s.Cfg.BNBAutoTopUp {
+		return false
+	}
+
+	// Daily cap (persisted in the runtime state so restarts don't reset it; resets at local midnight)
+	today := time.Now().Format("2006-01-02")
+	count := 0
+	if state := s.StateRepo.Get(); state.BNBTopUpDay == today {
+		count = state.BNBTopUpCount
+	}
+	if count >= s.Cfg.BNBTopUpMaxPerDay {
+		logger.Warn("⚠️ BNB auto top-up daily cap reached. Falling back to alert.", "count", count, "max", s.Cfg.BNBTopUpMaxPerDay)
+		return false
+	}
+
+	amount := s.Cfg.BNBTopUpAmountUSDT
+	usdtBalance := s.deployableUSDT()
+	if usdtBalance < amount {
+		logger.Warn("⚠️ Not enough USDT (above reserve) for BNB auto top-up", "deployable", usdtBalance, "required", amount)
+		return false
+	}
+	if !s.fundFromEarn(amount) {
+		return false
+	}
+
+	// Count the attempt before placing it so a failing order (or a crash loop) can't repeat it
+	// within the day; an attempt that cannot be recorded is not placed
+	count++
+	if err := s.StateRepo.SetBNBTopUp(today, count); err != nil {
+		logger.Error("❌ BNB auto top-up skipped: failed to persist the daily counter", "error", err)
+		return false
+	}
+
+	logger.Info("🪙 Auto top-up: buying BNB for fees", "amount_usdt", amount, "count", count)
+	clientOrderID := fmt.Sprintf("BNB_TOPUP_%d", time.Now().UnixMilli())
+	audit.Intent(clientOrderID, clientOrderID, "bnb_topup", audit.Fields{"amount_usdt": amount, "count": count})
+	resp, err := s.Binance.CreateOrder(api.OrderRequest{
+		Symbol:           "BNB" + s.Cfg.QuoteAsset,
+		Side:             "BUY",
+		Type:             "MARKET",
+		QuoteOrderQty:    fmt.Sprintf("%.2f", amount),
+		NewClientOrderID: clientOrderID,
+	})
+	if err != nil {
+		logger.Error("❌ BNB auto top-up failed", "error", err)
+		return false
+	}
+
+	bought, _ := strconv.ParseFloat(resp.ExecutedQty, 64)
+	spent, _ := strconv.ParseFloat(resp.CummulativeQuoteQty, 64)
+	for _, fill := range resp.Fills {
+		if fill.CommissionAsset == "BNB" {
+			commission, _ := strconv.ParseFloat(fill.Commission, 64)
+			bought -= commission
+		}
+	}
+
+	avgPrice := 0.0
+	if bought > 0 {
+		avgPrice = spent / bought
+	}
+
+	// Keep local balances in line until the next account sync
+	s.updateBalance("BNB", bought)
+	s.updateBalance(s.Cfg.QuoteAsset, -spent)
+
+	logger.Info("✅ BNB auto top-up completed", "bought_bnb", bought, "spent_usdt", spent, "avg_price", avgPrice)
+
+	s.Notifier.NotifyTemplate(service.CategoryLowBalance, service.SeverityInfo, service.TemplateBNBTopUp, service.BNBTopUpMessageData{
+		SpentUSDT:  spent,
+		BoughtBNB:  bought,
+		AvgPrice:   avgPrice,
+		BalanceBNB: s.getBalance("BNB"),
+		CountToday: count,
+		MaxPerDay:  s.Cfg.BNBTopUpMaxPerDay,
+		Date:       time.Now().Format("02/01/2006, 15:04:05"),
+	})
+	return true
+}
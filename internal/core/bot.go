@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"grid-trading-btc-binance/internal/config"
+	"grid-trading-btc-binance/internal/crash"
 	"grid-trading-btc-binance/internal/logger"
 	"grid-trading-btc-binance/internal/metrics"
 	"grid-trading-btc-binance/internal/repository"
@@ -18,6 +19,7 @@ type Bot struct {
 	MarketDataService *service.MarketDataService
 	Strategy          *Strategy
 	DataCollector     *service.DataCollector
+	PriceAlerts       *service.PriceAlertService // PRICE_ALERTS (nil = none)
 
 	lastBNBPrice     float64
 	lastLoggedPrice  float64
@@ -25,9 +27,13 @@ type Bot struct {
 }
 
 func NewBot(cfg *config.Config, balanceRepo *repository.BalanceRepository, transactionRepo *repository.TransactionRepository, marketDataService *service.MarketDataService, strategy *Strategy, dataCollector *service.DataCollector) *Bot {
+	tracker := metrics.NewTracker(cfg, strategy.StateRepo)
+	strategy.Metrics = tracker
+	strategy.Binance.OnOrderLatency = tracker.TrackCreateOrder
+	strategy.Binance.OnConnection = tracker.TrackConnection
 	return &Bot{
 		Cfg:               cfg,
-		Metrics:           metrics.NewTracker(cfg),
+		Metrics:           tracker,
 		BalanceRepo:       balanceRepo,
 		TransactionRepo:   transactionRepo,
 		MarketDataService: marketDataService,
@@ -43,8 +49,13 @@ func (b *Bot) Run() {
 	// Startup Analysis (User Request)
 	b.Strategy.AnalyzeStartupState()
 
-	// Start monitoring tickers
-	b.MarketDataService.Start([]string{"BTCUSDT", "BNBUSDT"})
+	// Start monitoring tickers (the BNB pair values the fees; it may be the traded symbol itself)
+	bnbSymbol := "BNB" + b.Cfg.QuoteAsset
+	symbols := []string{b.Cfg.Symbol}
+	if bnbSymbol != b.Cfg.Symbol {
+		symbols = append(symbols, bnbSymbol)
+	}
+	b.MarketDataService.Start(symbols)
 
 	updates := b.MarketDataService.GetUpdates()
 
@@ -79,18 +90,23 @@ func (b *Bot) Run() {
 		case ticker := <-updates:
 			start := time.Now()
 
-			if ticker.Symbol == "BNBUSDT" {
+			if ticker.Symbol == bnbSymbol {
 				b.lastBNBPrice = ticker.Price
-			} else if ticker.Symbol == b.Cfg.Symbol {
-				// Execute Strategy
-				b.Strategy.Execute(ticker, b.lastBNBPrice)
+			}
+			if ticker.Symbol == b.Cfg.Symbol && b.PriceAlerts != nil {
+				// Price alerts run on every ticker, whatever the strategy does with it
+				crash.Guard("price alerts", func() { b.PriceAlerts.Check(ticker.Price, time.Now()) })
+			}
+			if ticker.Symbol == b.Cfg.Symbol {
+				// Execute Strategy (a panic skips this ticker, never the loop)
+				crash.Guard("strategy", func() { b.Strategy.Execute(ticker, b.lastBNBPrice) })
 			}
 
 			// Track cycle metrics
 			b.Metrics.TrackCycle(time.Since(start))
 
 		case <-dataTickerCh:
-			b.DataCollector.CollectAndSave()
+			crash.Guard("data collector", b.DataCollector.CollectAndSave)
 
 		case <-time.After(1 * time.Minute):
 			// Keep-alive or maintenance tasks
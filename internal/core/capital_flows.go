@@ -0,0 +1,240 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"grid-trading-btc-binance/internal/api"
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/model"
+)
+
+const (
+	capitalFlowMaxWindow = 89 * 24 * time.Hour // Binance caps deposit/withdraw history at 90 days
+	subAccountMaxWindow  = 29 * 24 * time.Hour // And sub-account transfer history at 30 days
+	capitalFlowOverlap   = 1 * time.Hour       // Re-read recent entries still pending confirmation
+	withdrawTimeLayout   = "2006-01-02 15:04:05"
+)
+
+// capitalFlow is a deposit or withdrawal normalized for recording
+type capitalFlow struct {
+	ID     string // "DEP_<id>", "WD_<id>", or "SUBIN_<id>"/"SUBOUT_<id>" for sub-account transfers
+	Type   string // deposit, withdraw
+	Coin   string
+	Amount float64 // Gross amount that entered/left the account
+	Fee    float64
+	Price  float64 // USDT per unit at sync time (0 if unknown)
+	Time   time.Time
+	TxID   string
+}
+
+// SyncCapitalFlows reads the deposit and withdrawal history and records new entries as
+// "deposit"/"withdraw" transactions in the archive. Flows after the equity baseline started
+// adjust its NetDeposits, so compounding and PnL only reflect trading performance.
+func (s *Strategy) SyncCapitalFlows() {
+	now := time.Now()
+	since := time.UnixMilli(s.StateRepo.Get().LastCapitalFlowSync).Add(-capitalFlowOverlap)
+	if oldest := now.Add(-capitalFlowMaxWindow); since.Before(oldest) {
+		since = oldest
+	}
+
+	flows, err := s.fetchCapitalFlows(since)
+	if err != nil {
+		logger.Error("❌ Capital flow sync failed", "error", err)
+		return
+	}
+
+	// Price outside of UpdateHistory: it blocks Archive while running
+	prices := make(map[string]float64)
+	for i := range flows {
+		price, ok := prices[flows[i].Coin]
+		if !ok {
+			price = s.assetPrice(flows[i].Coin)
+			prices[flows[i].Coin] = price
+		}
+		flows[i].Price = price
+	}
+
+	var recorded []capitalFlow
+	err = s.TransactionRepo.UpdateHistory(func(history []model.Transaction) ([]model.Transaction, bool) {
+		known := make(map[string]bool)
+		for _, tx := range history {
+			if tx.Type == "deposit" || tx.Type == "withdraw" {
+				known[tx.ID] = true
+			}
+		}
+		for _, f := range flows {
+			if known[f.ID] {
+				continue
+			}
+			flowTime := f.Time
+			history = append(history, model.Transaction{
+				ID:                f.ID,
+				TransactionID:     f.TxID,
+				Symbol:            f.Coin,
+				Type:              f.Type,
+				Amount:            fmt.Sprintf("%.8f", f.Amount),
+				Price:             fmt.Sprintf("%.8f", f.Price),
+				Fee:               fmt.Sprintf("%.8f", f.Fee),
+				StatusTransaction: model.StatusClosed,
+				Notes:             "Imported from capital history",
+				ClosedAt:          &flowTime,
+				CreatedAt:         flowTime,
+				UpdatedAt:         now,
+			})
+			known[f.ID] = true
+			recorded = append(recorded, f)
+		}
+		return history, len(recorded) > 0
+	})
+	if err != nil {
+		logger.Error("❌ Failed to record capital flows", "error", err)
+		return
+	}
+
+	for _, f := range recorded {
+		if f.Price <= 0 {
+			logger.Warn("⚠️ Capital flow recorded without USDT price", "id", f.ID, "coin", f.Coin)
+		}
+		value := f.Amount * f.Price
+		if f.Type == "withdraw" {
+			value = -value
+		}
+		logger.Info("🏦 Capital flow recorded", "type", f.Type, "coin", f.Coin, "amount", f.Amount, "value_usdt", fmt.Sprintf("%.2f", value))
+		s.recordCapitalFlow(f.Time, value)
+	}
+
+	if err := s.StateRepo.SetLastCapitalFlowSync(now.UnixMilli()); err != nil {
+		logger.Error("Failed to persist capital flow watermark", "error", err)
+	}
+}
+
+// recordCapitalFlow moves the compounding baseline by a deposit/withdrawal made after it started
+func (s *Strategy) recordCapitalFlow(at time.Time, valueUSDT float64) {
+	if !s.Cfg.CompoundProfits || s.EquityRepo == nil || !s.EquityRepo.Initialized() || valueUSDT == 0 {
+		return
+	}
+	if at.Before(s.EquityRepo.Get().StartedAt) {
+		return // Already part of BaseCapital
+	}
+	if err := s.EquityRepo.AddCapitalFlow(valueUSDT); err != nil {
+		logger.Error("Failed to update equity baseline", "error", err)
+		return
+	}
+	logger.Info("📈 Equity baseline adjusted for capital flow", "flow", valueUSDT, "equity", s.EquityRepo.Get().Equity())
+}
+
+func (s *Strategy) fetchCapitalFlows(since time.Time) ([]capitalFlow, error) {
+	deposits, err := s.Binance.GetDepositHistory(since.UnixMilli())
+	if err != nil {
+		return nil, fmt.Errorf("deposit history: %w", err)
+	}
+	withdrawals, err := s.Binance.GetWithdrawHistory(since.UnixMilli())
+	if err != nil {
+		return nil, fmt.Errorf("withdraw history: %w", err)
+	}
+
+	var flows []capitalFlow
+	for _, d := range deposits {
+		if d.Status != api.DepositStatusSuccess && d.Status != api.DepositStatusCredited {
+			continue // Pending; picked up by a later sync thanks to the overlap
+		}
+		amount, _ := strconv.ParseFloat(d.Amount, 64)
+		flows = append(flows, capitalFlow{
+			ID:     "DEP_" + d.ID,
+			Type:   "deposit",
+			Coin:   d.Coin,
+			Amount: amount,
+			Time:   time.UnixMilli(d.InsertTime),
+			TxID:   d.TxID,
+		})
+	}
+	for _, w := range withdrawals {
+		if w.Status != api.WithdrawStatusCompleted {
+			continue
+		}
+		amount, _ := strconv.ParseFloat(w.Amount, 64)
+		fee, _ := strconv.ParseFloat(w.TransactionFee, 64)
+		at, err := time.ParseInLocation(withdrawTimeLayout, w.ApplyTime, time.UTC)
+		if err != nil {
+			at = time.Now()
+		}
+		flows = append(flows, capitalFlow{
+			ID:     "WD_" + w.ID,
+			Type:   "withdraw",
+			Coin:   w.Coin,
+			Amount: amount + fee, // The fee also leaves the account
+			Fee:    fee,
+			Time:   at,
+			TxID:   w.TxID,
+		})
+	}
+
+	if s.Cfg.SubAccountEmail != "" {
+		transfers, err := s.fetchSubAccountFlows(since)
+		if err != nil {
+			return nil, fmt.Errorf("sub-account transfer history: %w", err)
+		}
+		flows = append(flows, transfers...)
+	}
+	return flows, nil
+}
+
+// fetchSubAccountFlows turns the transfers between the master account and the grid's wallet of
+// the sub-account into deposits (top-ups) and withdrawals (manual skims). The vault's own
+// transfers to the master are skipped: the skimmed profit never entered the equity baseline.
+func (s *Strategy) fetchSubAccountFlows(since time.Time) ([]capitalFlow, error) {
+	if oldest := time.Now().Add(-subAccountMaxWindow); since.Before(oldest) {
+		since = oldest
+	}
+	transfers, err := s.Binance.GetSubAccountTransfers(since.UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+
+	vaultTransfers := make(map[string]bool)
+	if s.VaultRepo != nil {
+		for _, id := range s.VaultRepo.Get().MasterTransferIDs {
+			vaultTransfers[id] = true
+		}
+	}
+	wallet := api.SubAccountWallet(s.Cfg.AccountType)
+
+	var flows []capitalFlow
+	for _, t := range transfers {
+		id := strconv.FormatInt(t.TranID, 10)
+		if t.Status != "SUCCESS" || vaultTransfers[id] {
+			continue
+		}
+		amount, _ := strconv.ParseFloat(t.Qty, 64)
+		flow := capitalFlow{Coin: t.Asset, Amount: amount, Time: time.UnixMilli(t.Time), TxID: id}
+		switch {
+		case t.Type == api.SubAccountTransferIn && t.ToAccountType == wallet:
+			flow.ID, flow.Type = "SUBIN_"+id, "deposit"
+		case t.Type == api.SubAccountTransferOut && t.FromAccountType == wallet:
+			flow.ID, flow.Type = "SUBOUT_"+id, "withdraw"
+		default:
+			continue // Another wallet of the sub-account
+		}
+		flows = append(flows, flow)
+	}
+	return flows, nil
+}
+
+// usdStablecoins are valued at par against each other
+var usdStablecoins = map[string]bool{"USDT": true, "USDC": true, "FDUSD": true}
+
+// assetPrice returns the value of one unit of coin in the quote asset (0 if unknown)
+func (s *Strategy) assetPrice(coin string) float64 {
+	quote := s.Cfg.QuoteAsset
+	if coin == quote || (usdStablecoins[coin] && usdStablecoins[quote]) {
+		return 1
+	}
+	book, err := s.Binance.GetBookTicker(coin + quote)
+	if err != nil {
+		return 0
+	}
+	price, _ := strconv.ParseFloat(book.BidPrice, 64)
+	return price
+}
@@ -0,0 +1,60 @@
+package core
+
+import (
+	"fmt"
+
+	"grid-trading-btc-binance/internal/api"
+	"grid-trading-btc-binance/internal/logger"
+)
+
+// CleanupPending reports whether SYNC_DRY_RUN held back a startup cleanup that was not confirmed yet
+func (s *Strategy) CleanupPending() bool {
+	s.cleanupMu.Lock()
+	defer s.cleanupMu.Unlock()
+	return s.cleanupPending
+}
+
+func (s *Strategy) setCleanupPending(pending bool) {
+	s.cleanupMu.Lock()
+	s.cleanupPending = pending
+	s.cleanupMu.Unlock()
+}
+
+// PreviewStartupCleanup reruns the ghost, duplicate and zombie phases in dry run against the
+// current open orders: every decision is logged, nothing changes
+func (s *Strategy) PreviewStartupCleanup() (startupReport, error) {
+	return s.runCleanupPhases(true)
+}
+
+// ExecuteStartupCleanup runs the cleanup held back by SYNC_DRY_RUN (phases 3 to 5 of the
+// startup sync) and resumes the periodic ghost cleanup
+func (s *Strategy) ExecuteStartupCleanup() (startupReport, error) {
+	if s.Cfg.MonitorOnly {
+		return startupReport{}, errMonitorOnly
+	}
+	report, err := s.runCleanupPhases(false)
+	if err != nil {
+		return report, err
+	}
+	s.setCleanupPending(false)
+	logger.Info("🧹 Startup cleanup confirmed and executed",
+		"purged", report.Purged, "duplicates", report.Duplicates, "rescued", report.ZombiesRescued, "archived", report.ZombiesCleaned)
+	return report, nil
+}
+
+func (s *Strategy) runCleanupPhases(dryRun bool) (startupReport, error) {
+	openOrders, err := s.Binance.GetOpenOrders(s.Cfg.Symbol)
+	if err != nil {
+		return startupReport{}, fmt.Errorf("failed to fetch open orders: %w", err)
+	}
+	binanceOrderMap := make(map[string]api.OrderResponse)
+	for _, o := range openOrders {
+		binanceOrderMap[o.ClientOrderId] = o
+	}
+
+	report := startupReport{OpenOrders: len(openOrders), DryRun: dryRun}
+	report.Purged = s.purgeGhostTransactions(binanceOrderMap, dryRun)
+	report.Duplicates = s.purgeDuplicateTransactions(dryRun)
+	report.ZombiesRescued, report.ZombiesCleaned = s.rescueZombieTransactions(dryRun)
+	return report, nil
+}
@@ -0,0 +1,368 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"grid-trading-btc-binance/internal/metrics"
+	"grid-trading-btc-binance/internal/model"
+	"grid-trading-btc-binance/internal/service"
+)
+
+const panicConfirmWindow = 60 * time.Second
+
+// CommandCenter wires the Telegram commands to the strategy
+type CommandCenter struct {
+	Strategy *Strategy
+
+	mu               sync.Mutex
+	panicRequestedAt time.Time
+}
+
+// RegisterCommands registers /panic, /resume, /status, /range, /profile, /cleanup, /drawdown and /levels on the Telegram listener
+func RegisterCommands(telegram *service.TelegramService, strategy *Strategy) *CommandCenter {
+	c := &CommandCenter{Strategy: strategy}
+	telegram.RegisterCommand("panic", c.handlePanic)
+	telegram.RegisterCommand("resume", c.handleResume)
+	telegram.RegisterCommand("status", c.handleStatus)
+	telegram.RegisterCommand("range", c.handleRange)
+	telegram.RegisterCommand("profile", c.handleProfile)
+	telegram.RegisterCommand("cleanup", c.handleCleanup)
+	telegram.RegisterCommand("drawdown", c.handleDrawdown)
+	telegram.RegisterCommand("levels", c.handleLevels)
+	return c
+}
+
+// handlePanic: "/panic" shows the dry-run, "/panic confirm" (within 60s) executes it
+func (c *CommandCenter) handlePanic(args []string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.Strategy.Cfg.MonitorOnly {
+		return "👁️ Modo monitor (MONITOR_ONLY): esta instância não cria nem cancela ordens. Use /panic na instância que opera."
+	}
+
+	if len(args) > 0 && strings.ToLower(args[0]) == "confirm" {
+		if c.panicRequestedAt.IsZero() || time.Since(c.panicRequestedAt) > panicConfirmWindow {
+			return "Nenhum /panic pendente (ou a confirmação expirou). Envie /panic para ver a simulação."
+		}
+		c.panicRequestedAt = time.Time{}
+
+		result, err := c.Strategy.ExecutePanic("telegram /panic")
+		if err != nil {
+			return fmt.Sprintf("🚨 PANIC executado com erro: %v", err)
+		}
+		return fmt.Sprintf("🚨 PANIC executado. %d ordens canceladas, %.5f %s vendidos a $%.2f. Bot PAUSADO, use /resume para retomar.",
+			result.CanceledOrders, result.SoldQty, c.Strategy.Cfg.BaseAsset, result.AvgPrice)
+	}
+
+	plan, err := c.Strategy.PreviewPanic()
+	if err != nil {
+		return fmt.Sprintf("❌ Falha ao simular o PANIC: %v", err)
+	}
+	c.panicRequestedAt = time.Now()
+
+	return fmt.Sprintf(
+		"⚠️ SIMULAÇÃO DO PANIC (nada foi executado)\n\n"+
+			"🧾 Ordens abertas a cancelar: %d\n"+
+			"📦 Inventário a vender (mercado): %.5f %s\n"+
+			"💲 Bid atual: $%.2f\n"+
+			"💵 Valor esperado (após taxa): $%.2f\n"+
+			"📉 Custo do inventário: $%.2f\n"+
+			"💰 Resultado esperado: $%.2f\n"+
+			"🗄️ Transações a arquivar: %d\n\n"+
+			"Envie /panic confirm em até %d segundos para executar.",
+		plan.OpenOrders, plan.InventoryQty, c.Strategy.Cfg.BaseAsset, plan.BidPrice, plan.ExpectedProceeds,
+		plan.CostBasis, plan.ExpectedPnL(), plan.TrackedTxs, int(panicConfirmWindow.Seconds()),
+	)
+}
+
+func (c *CommandCenter) handleResume(args []string) string {
+	if !c.Strategy.IsPaused() {
+		return "O bot não está pausado."
+	}
+	if until := c.Strategy.StateRepo.Get().CooloffUntil; until != nil && time.Now().Before(*until) {
+		return fmt.Sprintf("⏳ Cool-off do stop por drawdown: /resume liberado a partir de %s.", until.Format("02/01 15:04"))
+	}
+	c.Strategy.Resume()
+	return "▶️ Bot retomado. Novas ordens voltarão a ser criadas."
+}
+
+// handleRange: "/range" shows the active range, "/range <min> <max>" changes it
+func (c *CommandCenter) handleRange(args []string) string {
+	cfg := c.Strategy.Cfg
+	if len(args) == 0 {
+		return fmt.Sprintf("📐 Range ativo: $%.2f - $%.2f\nUse /range <min> <max> para alterar.", cfg.RangeMin, cfg.RangeMax)
+	}
+	if len(args) != 2 {
+		return "Uso: /range <min> <max>"
+	}
+	if cfg.MonitorOnly {
+		return "👁️ Modo monitor (MONITOR_ONLY): o range só pode ser alterado na instância que opera."
+	}
+
+	rangeMin, errMin := strconv.ParseFloat(args[0], 64)
+	rangeMax, errMax := strconv.ParseFloat(args[1], 64)
+	if errMin != nil || errMax != nil {
+		return "Valores inválidos. Uso: /range <min> <max>"
+	}
+
+	canceled, err := c.Strategy.SetRange(rangeMin, rangeMax)
+	if err != nil {
+		return fmt.Sprintf("❌ %v", err)
+	}
+	return fmt.Sprintf("📐 Range atualizado para $%.2f - $%.2f. %d ordens fora do range canceladas (serão recolocadas dentro do range).", rangeMin, rangeMax, canceled)
+}
+
+// handleProfile: "/profile" lists the profiles, "/profile <name>" switches to one
+func (c *CommandCenter) handleProfile(args []string) string {
+	s := c.Strategy
+	active := s.ActiveProfile()
+	if len(args) == 0 {
+		var lines []string
+		for _, name := range s.Cfg.ProfileNames() {
+			marker := "▫️"
+			if name == active {
+				marker = "✅"
+			}
+			lines = append(lines, fmt.Sprintf("%s %s", marker, name))
+		}
+		return fmt.Sprintf("🎛️ Perfil ativo: %s\n\n%s\n\nUse /profile <nome> para trocar.", active, strings.Join(lines, "\n"))
+	}
+	if len(args) != 1 {
+		return "Uso: /profile <nome>"
+	}
+
+	if err := s.SetProfile(args[0], "telegram /profile"); err != nil {
+		return fmt.Sprintf("❌ %v", err)
+	}
+	cfg := s.Cfg
+	return fmt.Sprintf("🎛️ Perfil alterado para %s.\n📏 Espaçamento: %.2f%%\n📦 Tamanho da posição: %.2f%%\n🪜 Níveis: %d\nOrdens abertas são mantidas; os novos valores valem para as próximas ordens.",
+		s.ActiveProfile(), cfg.GridSpacingPct*100, cfg.PositionSizePct*100, cfg.GridLevels)
+}
+
+// handleCleanup: "/cleanup" previews the startup cleanup held by SYNC_DRY_RUN (ghosts,
+// duplicates, zombies), "/cleanup confirm" executes it
+func (c *CommandCenter) handleCleanup(args []string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := c.Strategy
+	if !s.CleanupPending() {
+		return "Nenhuma limpeza de startup pendente (SYNC_DRY_RUN desligado ou limpeza já confirmada)."
+	}
+	if len(args) > 0 && strings.ToLower(args[0]) == "confirm" {
+		report, err := s.ExecuteStartupCleanup()
+		if err != nil {
+			return fmt.Sprintf("❌ Falha na limpeza: %v", err)
+		}
+		return fmt.Sprintf("🧹 Limpeza executada.\n📦 Fantasmas arquivadas: %d\n👯 Duplicadas removidas: %d\n🧟 Zumbis: %d com nova saída, %d arquivadas\nA limpeza periódica voltou a rodar.",
+			report.Purged, report.Duplicates, report.ZombiesRescued, report.ZombiesCleaned)
+	}
+
+	report, err := s.PreviewStartupCleanup()
+	if err != nil {
+		return fmt.Sprintf("❌ Falha ao simular a limpeza: %v", err)
+	}
+	return fmt.Sprintf(
+		"🔍 SIMULAÇÃO DA LIMPEZA (nada foi executado)\n\n"+
+			"📦 Fantasmas a arquivar: %d\n"+
+			"👯 Duplicadas a remover: %d\n"+
+			"🧟 Zumbis: %d receberiam nova saída, %d seriam arquivadas\n\n"+
+			"Cada transação está no log (\"Dry run\"). Envie /cleanup confirm para executar.",
+		report.Purged, report.Duplicates, report.ZombiesRescued, report.ZombiesCleaned,
+	)
+}
+
+// handleDrawdown: "/drawdown" shows the drawdown stop, "/drawdown confirm" executes the sell
+// waiting for confirmation (DRAWDOWN_CONFIRM)
+func (c *CommandCenter) handleDrawdown(args []string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := c.Strategy
+	state := s.StateRepo.Get()
+	if len(args) > 0 && strings.ToLower(args[0]) == "confirm" {
+		if !state.DrawdownPending {
+			return "Nenhuma venda do stop por drawdown aguardando confirmação."
+		}
+		result, err := s.ConfirmDrawdownSell()
+		if err != nil {
+			return fmt.Sprintf("❌ Venda do stop por drawdown falhou: %v", err)
+		}
+		return fmt.Sprintf("🩸 Stop por drawdown executado: %.5f %s vendidos a $%.2f (%d posições, resultado $%.2f). Bot segue PAUSADO.",
+			result.SoldQty, s.Cfg.BaseAsset, result.AvgPrice, result.Positions, result.RealizedPnL)
+	}
+
+	var limits []string
+	if s.Cfg.DrawdownStopUSDT > 0 {
+		limits = append(limits, fmt.Sprintf("-$%.2f", s.Cfg.DrawdownStopUSDT))
+	}
+	if s.Cfg.DrawdownStopPct > 0 {
+		limits = append(limits, fmt.Sprintf("-%.2f%%", s.Cfg.DrawdownStopPct*100))
+	}
+	if len(limits) == 0 {
+		return "Stop por drawdown desativado (DRAWDOWN_STOP_USDT e DRAWDOWN_STOP_PCT em 0)."
+	}
+
+	_, qty, cost := s.trackedInventory()
+	pnl := 0.0
+	if book, err := s.Binance.GetBookTicker(s.Cfg.Symbol); err == nil {
+		bid, _ := strconv.ParseFloat(book.BidPrice, 64)
+		pnl = qty*bid - cost
+	}
+	text := fmt.Sprintf("🩸 Stop por drawdown: %s (venda de %.0f%% do inventário)\n📦 Inventário: %.5f %s (custo $%.2f)\n📉 Resultado não realizado: $%.2f",
+		strings.Join(limits, " ou "), s.Cfg.DrawdownSellFraction*100, qty, s.Cfg.BaseAsset, cost, pnl)
+	if state.CooloffUntil != nil && time.Now().Before(*state.CooloffUntil) {
+		text += "\n⏳ Cool-off até " + state.CooloffUntil.Format("02/01 15:04")
+	}
+	if state.DrawdownPending {
+		text += "\n⚠️ Venda aguardando confirmação: envie /drawdown confirm."
+	}
+	return text
+}
+
+func (c *CommandCenter) handleStatus(args []string) string {
+	return c.Strategy.StatusText()
+}
+
+// handleLevels: "/levels [days]" shows the realized results per grid level (30 days by default)
+func (c *CommandCenter) handleLevels(args []string) string {
+	days := 30
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n <= 0 {
+			return "Uso: /levels [dias] (ex.: /levels 7)"
+		}
+		days = n
+	}
+	return c.Strategy.LevelsText(days)
+}
+
+// LevelsText is the per grid level summary used by /levels
+func (s *Strategy) LevelsText(days int) string {
+	var price, bnbPrice float64
+	if book, err := s.Binance.GetBookTicker(s.Cfg.Symbol); err == nil {
+		price, _ = strconv.ParseFloat(book.BidPrice, 64)
+	}
+	if book, err := s.Binance.GetBookTicker("BNB" + s.Cfg.QuoteAsset); err == nil {
+		bnbPrice, _ = strconv.ParseFloat(book.BidPrice, 64)
+	}
+	now := time.Now()
+	var closed, active []model.Transaction
+	for _, tx := range s.TransactionRepo.GetClosedTransactionsAfter(now.AddDate(0, 0, -days)) {
+		if tx.Symbol == s.Cfg.Symbol {
+			closed = append(closed, tx)
+		}
+	}
+	for _, tx := range s.TransactionRepo.GetAll() {
+		if tx.Symbol == s.Cfg.Symbol {
+			active = append(active, tx)
+		}
+	}
+
+	levels := service.ComputeLevelStats(closed, active, price, bnbPrice, now, days)
+	if len(levels) == 0 {
+		return fmt.Sprintf("🪜 Nenhum round trip do grid nos últimos %d dias e nenhuma posição aberta.", days)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "🪜 Resultado por nível (%d dias, GRID_LEVELS=%d)\n", days, s.Cfg.GridLevels)
+	for _, l := range levels {
+		fmt.Fprintf(&b, "\nL%d: %d round trips, acerto %.0f%%, %.0f min em média, $%.2f líquido", l.Level, l.RoundTrips, l.HitRate*100, l.AvgHoldMin, l.NetProfit)
+		if l.Held > 0 {
+			fmt.Fprintf(&b, " | %d em aberto ($%.2f)", l.Held, l.Unrealized)
+		}
+	}
+	return b.String()
+}
+
+// StatusText is the plain-text summary used by /status
+func (s *Strategy) StatusText() string {
+	state := "ATIVO"
+	if s.Cfg.MonitorOnly {
+		state = "MONITOR (somente leitura)"
+	}
+	if st := s.StateRepo.Get(); st.Paused {
+		state = fmt.Sprintf("PAUSADO (%s)", st.PausedReason)
+		if st.PausedAt != nil {
+			state += " desde " + st.PausedAt.Format("02/01 15:04")
+		}
+		if st.CooloffUntil != nil && time.Now().Before(*st.CooloffUntil) {
+			state += ", cool-off até " + st.CooloffUntil.Format("02/01 15:04")
+		}
+	}
+
+	if escalation, _ := s.circuitBreakerEscalation(); escalation != "" {
+		state += fmt.Sprintf(" (circuit breaker escalado: %s)", escalation)
+	}
+
+	openBuys := 0
+	for _, tx := range s.TransactionRepo.GetAll() {
+		if tx.Symbol == s.Cfg.Symbol && tx.Type == "buy" && tx.StatusTransaction == model.StatusOpen {
+			openBuys++
+		}
+	}
+	_, qty, cost := s.trackedInventory()
+
+	if s.Cfg.StrategyMode == "dca" {
+		stack := s.DCARepo.Get()
+		target := "desativado"
+		if s.Cfg.DCATakeProfitPct > 0 && stack.AvgEntry() > 0 {
+			target = fmt.Sprintf("$%.2f", stack.AvgEntry()*(1+s.Cfg.DCATakeProfitPct))
+		}
+		return fmt.Sprintf(
+			"📊 Status: %s (modo DCA)\n"+
+				"🎛️ Perfil: %s\n"+
+				"🪜 Lotes: %d\n"+
+				"📦 Pilha: %.5f %s (custo $%.2f, preço médio $%.2f)\n"+
+				"🎯 Take-profit: %s\n"+
+				"💰 %s livre: $%.2f (disponível: $%.2f)",
+			state, s.ActiveProfile(), len(stack.Lots), stack.Qty(), s.Cfg.BaseAsset, stack.Cost(), stack.AvgEntry(), target, s.Cfg.QuoteAsset, s.getBalance(s.Cfg.QuoteAsset), s.deployableUSDT(),
+		)
+	}
+
+	text := fmt.Sprintf(
+		"📊 Status: %s\n"+
+			"🎛️ Perfil: %s\n"+
+			"🧾 Compras abertas: %d\n"+
+			"📦 Inventário: %.5f %s (custo $%.2f)\n"+
+			"💰 %s livre: $%.2f (disponível para o grid: $%.2f)\n"+
+			"🕒 Janela de negociação: %s\n"+
+			"⏱️ Fill → saída: %s\n"+
+			"📨 CreateOrder: %s\n"+
+			"🔌 Conexões REST: %s",
+		state, s.ActiveProfile(), openBuys, qty, s.Cfg.BaseAsset, cost, s.Cfg.QuoteAsset, s.getBalance(s.Cfg.QuoteAsset), s.deployableUSDT(),
+		s.tradingWindowText(), latencyText(s.Metrics.FillToExitStats()), latencyText(s.Metrics.CreateOrderStats()),
+		connectionText(s.Metrics.ConnectionStats()),
+	)
+	if s.Macro != nil {
+		text += "\n📅 Evento macro: " + s.macroText()
+	}
+	if s.Futures != nil {
+		text += "\n🛡️ Hedge: " + s.hedgeText()
+	}
+	if s.Cfg.EarnEnabled {
+		text += "\n💤 Earn: " + s.earnText()
+	}
+	if s.Binance.Margin() {
+		text += "\n🏦 Margem: " + s.marginText()
+	}
+	return text
+}
+
+func connectionText(stats metrics.ConnectionStats) string {
+	if stats.Calls == 0 {
+		return "sem amostras"
+	}
+	return stats.String()
+}
+
+func latencyText(stats metrics.LatencyStats) string {
+	if stats.Count == 0 {
+		return "sem amostras"
+	}
+	return stats.String()
+}
@@ -0,0 +1,120 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"grid-trading-btc-binance/internal/model"
+)
+
+const maxRecentFills = 10
+
+// Fill is an execution seen on the user stream (recent fills of the dashboard)
+type Fill struct {
+	At     time.Time
+	Side   string // BUY, SELL
+	Price  float64
+	Qty    float64
+	Profit float64 // Gross, exits only
+}
+
+// LadderLevel is a price of the grid ladder: an open buy, or a held position and its exit
+type LadderLevel struct {
+	ID         string
+	Kind       string // buy (open entry), exit (position with its maker exit), held (position without exit)
+	Price      float64
+	Qty        float64
+	EntryPrice float64 // exit/held: what the position cost
+}
+
+// Dashboard is a point-in-time view of the bot for the terminal UI
+type Dashboard struct {
+	Symbol        string
+	State         string
+	Profile       string
+	Price         float64
+	Regime        string
+	Spacing       float64
+	NextBuy       float64 // Price that triggers the next grid buy (0 = none: grid full or out of range)
+	Ladder        []LadderLevel
+	InventoryQty  float64
+	InventoryCost float64
+	UnrealizedPnL float64 // Held inventory marked at Price
+	FreeUSDT      float64
+	Fills         []Fill // Newest first
+}
+
+// recordFill keeps the last fills for the dashboard
+func (s *Strategy) recordFill(side, price, qty string, profit float64) {
+	p, _ := strconv.ParseFloat(price, 64)
+	q, _ := strconv.ParseFloat(qty, 64)
+
+	s.fillsMu.Lock()
+	defer s.fillsMu.Unlock()
+	s.recentFills = append([]Fill{{At: time.Now(), Side: side, Price: p, Qty: q, Profit: profit}}, s.recentFills...)
+	if len(s.recentFills) > maxRecentFills {
+		s.recentFills = s.recentFills[:maxRecentFills]
+	}
+}
+
+// Dashboard builds the view at the given price. Read-only: safe to call from any goroutine.
+func (s *Strategy) Dashboard(price float64) Dashboard {
+	d := Dashboard{
+		Symbol:   s.Cfg.Symbol,
+		State:    "ACTIVE",
+		Profile:  s.ActiveProfile(),
+		Price:    price,
+		Regime:   s.VolatilityService.GetRegime(),
+		Spacing:  s.VolatilityService.GetDynamicSpacing(),
+		FreeUSDT: s.getBalance(s.Cfg.QuoteAsset),
+	}
+	if s.Cfg.MonitorOnly {
+		d.State = "MONITOR"
+	}
+	if st := s.StateRepo.Get(); st.Paused {
+		d.State = fmt.Sprintf("PAUSED (%s)", st.PausedReason)
+	}
+
+	lowestBuy := 0.0
+	for _, tx := range s.TransactionRepo.Find(s.gridBuys(model.StatusOpen, model.StatusFilled, model.StatusExitPlaced)) {
+		p, _ := strconv.ParseFloat(tx.Price, 64)
+		q, _ := strconv.ParseFloat(tx.Amount, 64)
+		switch {
+		case tx.StatusTransaction == model.StatusOpen:
+			d.Ladder = append(d.Ladder, LadderLevel{ID: tx.ID, Kind: "buy", Price: p, Qty: q})
+			if lowestBuy == 0 || p < lowestBuy {
+				lowestBuy = p
+			}
+		case tx.StatusTransaction == model.StatusExitPlaced && tx.SellPrice > 0:
+			d.Ladder = append(d.Ladder, LadderLevel{ID: tx.ID, Kind: "exit", Price: tx.SellPrice, Qty: q, EntryPrice: p})
+		default:
+			d.Ladder = append(d.Ladder, LadderLevel{ID: tx.ID, Kind: "held", Price: p, Qty: q, EntryPrice: p})
+		}
+		if tx.StatusTransaction != model.StatusOpen {
+			d.InventoryQty += q
+			d.InventoryCost += q * p
+		}
+	}
+	sort.Slice(d.Ladder, func(i, j int) bool { return d.Ladder[i].Price > d.Ladder[j].Price })
+	if price > 0 {
+		d.UnrealizedPnL = d.InventoryQty*price - d.InventoryCost
+	}
+
+	// Same trigger as placeNewGridOrders: a spacing below the lowest open buy, or right away
+	if len(d.Ladder) < s.Cfg.GridLevels && s.Cfg.StrategyMode != "dca" {
+		next := price
+		if lowestBuy > 0 {
+			next = lowestBuy * (1 - d.Spacing)
+		}
+		if next >= s.Cfg.RangeMin && next <= s.Cfg.RangeMax {
+			d.NextBuy = next
+		}
+	}
+
+	s.fillsMu.Lock()
+	d.Fills = append([]Fill(nil), s.recentFills...)
+	s.fillsMu.Unlock()
+	return d
+}
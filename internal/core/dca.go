@@ -0,0 +1,259 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"grid-trading-btc-binance/internal/api"
+	"grid-trading-btc-binance/internal/audit"
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/model"
+)
+
+// DCA orders are not tracked as grid transactions: the stack lives in dca_stack.json and
+// its lots are archived to the history only when the take-profit sells them.
+const (
+	DCABuyOrderPrefix = "DCA_BUY_"
+	DCATPOrderPrefix  = "DCA_TP_"
+
+	dcaRetryAfter = 1 * time.Minute // Backoff after a failed market order
+)
+
+// IsDCAOrder reports whether a client order id belongs to the DCA mode
+func IsDCAOrder(clientOrderID string) bool {
+	return strings.HasPrefix(clientOrderID, DCABuyOrderPrefix) || strings.HasPrefix(clientOrderID, DCATPOrderPrefix)
+}
+
+// executeDCA runs one cycle of the DCA accumulation mode: take-profit on the whole stack
+// first, then a new buy when the price dropped DCA_DROP_PCT from the last buy or
+// DCA_INTERVAL_MIN elapsed. The grid, exits and rebalancer are not used in this mode.
+func (s *Strategy) executeDCA(ticker model.Ticker, bnbPrice float64) {
+	if time.Since(s.lastDCAFailure) < dcaRetryAfter {
+		return
+	}
+
+	if s.checkDCATakeProfit(ticker.Bid) {
+		return
+	}
+
+	if !s.isMarketSafe(ticker.Price) {
+		return // Block new entries
+	}
+	if s.Cfg.PauseBuys {
+		logger.Warn("⚠️ PAUSE_BUYS está ATIVO. Pulando compras DCA.")
+		return
+	}
+
+	stack := s.DCARepo.Get()
+	reason, due := s.dcaBuyDue(stack, ticker.Ask)
+	if !due {
+		return
+	}
+
+	amount := s.Cfg.DCABuyAmountUSDT
+	if s.Cfg.DCAMaxStackUSDT > 0 && stack.Cost()+amount > s.Cfg.DCAMaxStackUSDT {
+		logger.Debug("🪜 DCA buy skipped: stack at its maximum", "cost", stack.Cost(), "max", s.Cfg.DCAMaxStackUSDT)
+		return
+	}
+	if deployable := s.deployableUSDT(); deployable < amount {
+		s.checkAndAlertLowUSDT(deployable, amount)
+		return
+	}
+	if !s.fundFromEarn(amount) {
+		return
+	}
+
+	s.placeDCABuy(amount, reason)
+	s.checkLowBNB(bnbPrice)
+}
+
+// dcaBuyDue tells whether a new DCA buy should be placed now, and why
+func (s *Strategy) dcaBuyDue(stack model.DCAStack, ask float64) (string, bool) {
+	if stack.LastBuyAt.IsZero() {
+		return "first buy", true
+	}
+	if s.Cfg.DCADropPct > 0 && stack.LastBuyPrice > 0 && ask <= stack.LastBuyPrice*(1-s.Cfg.DCADropPct) {
+		return fmt.Sprintf("price %.2f is %.2f%% below the last buy", ask, (1-ask/stack.LastBuyPrice)*100), true
+	}
+	if s.Cfg.DCAIntervalMin > 0 && time.Since(stack.LastBuyAt) >= time.Duration(s.Cfg.DCAIntervalMin)*time.Minute {
+		return "schedule", true
+	}
+	return "", false
+}
+
+// placeDCABuy spends amount USDT with a market order and adds the lot to the stack
+func (s *Strategy) placeDCABuy(amount float64, reason string) {
+	clientOrderID := fmt.Sprintf("%s%d", DCABuyOrderPrefix, time.Now().UnixMilli())
+	logger.Info("🪜 DCA: buying", "amount_usdt", amount, "reason", reason)
+	audit.Intent(clientOrderID, clientOrderID, "dca_buy", audit.Fields{"amount_usdt": amount, "reason": reason})
+
+	resp, err := s.Binance.CreateOrder(api.OrderRequest{
+		Symbol:           s.Cfg.Symbol,
+		Side:             "BUY",
+		Type:             "MARKET",
+		QuoteOrderQty:    fmt.Sprintf("%.2f", amount),
+		NewClientOrderID: clientOrderID,
+	})
+	if err != nil {
+		s.lastDCAFailure = time.Now()
+		logger.Error("❌ DCA buy failed", "error", err)
+		return
+	}
+
+	qty, _ := strconv.ParseFloat(resp.ExecutedQty, 64)
+	spent, _ := strconv.ParseFloat(resp.CummulativeQuoteQty, 64)
+	if qty <= 0 {
+		logger.Warn("⚠️ DCA buy not executed", "id", clientOrderID, "status", resp.Status)
+		return
+	}
+	price := spent / qty
+
+	fee := 0.0
+	for _, fill := range resp.Fills {
+		commission, _ := strconv.ParseFloat(fill.Commission, 64)
+		fee += commission
+		if fill.CommissionAsset == s.Cfg.BaseAsset {
+			qty -= commission // Fee paid in the base asset reduces what we hold
+		}
+	}
+
+	lot := model.DCALot{OrderID: clientOrderID, Qty: qty, Price: price, Fee: fee, At: time.Now()}
+	if err := s.DCARepo.AddLot(lot); err != nil {
+		logger.Error("Failed to persist DCA lot", "id", clientOrderID, "error", err)
+	}
+
+	// Keep local balances in line until the next account sync
+	s.updateBalance(s.Cfg.BaseAsset, qty)
+	s.updateBalance(s.Cfg.QuoteAsset, -spent)
+
+	stack := s.DCARepo.Get()
+	logger.Info("✅ DCA buy filled",
+		"id", clientOrderID,
+		"qty", fmt.Sprintf("%.8f", qty),
+		"price", fmt.Sprintf("%.2f", price),
+		"stack_qty", fmt.Sprintf("%.8f", stack.Qty()),
+		"avg_entry", fmt.Sprintf("%.2f", stack.AvgEntry()),
+	)
+
+	s.sendTradeNotification(model.Transaction{
+		ID:                clientOrderID,
+		TransactionID:     strconv.FormatInt(resp.OrderId, 10),
+		Symbol:            s.Cfg.Symbol,
+		Type:              "buy",
+		Amount:            fmt.Sprintf("%.8f", qty),
+		Price:             s.normalizer.FormatPrice(price),
+		Fee:               fmt.Sprintf("%.8f", fee),
+		StatusTransaction: model.StatusFilled,
+		Notes:             "DCA: " + reason,
+		CreatedAt:         lot.At,
+		UpdatedAt:         lot.At,
+	}, 0, nil)
+}
+
+// checkDCATakeProfit market-sells the whole stack when the bid reaches DCA_TAKE_PROFIT_PCT
+// above the average entry. Returns true if the stack was sold.
+func (s *Strategy) checkDCATakeProfit(bid float64) bool {
+	if s.Cfg.DCATakeProfitPct <= 0 || bid <= 0 {
+		return false
+	}
+	stack := s.DCARepo.Get()
+	avgEntry := stack.AvgEntry()
+	if avgEntry <= 0 || bid < avgEntry*(1+s.Cfg.DCATakeProfitPct) {
+		return false
+	}
+
+	// Never sell more than is free (manual moves, fees)
+	sellQty := s.normalizer.FloorQty(math.Min(stack.Qty(), s.getBalance(s.Cfg.BaseAsset)))
+	if sellQty*bid < s.normalizer.MinNotional() {
+		logger.Warn("⚠️ DCA take-profit reached but the free base asset is below the minimum notional", "stack_qty", stack.Qty(), "free_base", s.getBalance(s.Cfg.BaseAsset))
+		return false
+	}
+
+	clientOrderID := fmt.Sprintf("%s%d", DCATPOrderPrefix, time.Now().UnixMilli())
+	s.Executions.Expect(clientOrderID, bid)
+	logger.Info("🎯 DCA take-profit reached. Selling the whole stack...",
+		"avg_entry", fmt.Sprintf("%.2f", avgEntry),
+		"bid", fmt.Sprintf("%.2f", bid),
+		"qty", fmt.Sprintf("%.5f", sellQty),
+	)
+	audit.Intent(clientOrderID, clientOrderID, "dca_take_profit", audit.Fields{
+		"avg_entry": avgEntry,
+		"bid":       bid,
+		"qty":       s.normalizer.FormatQty(sellQty),
+	})
+
+	resp, err := s.Binance.CreateOrder(api.OrderRequest{
+		Symbol:           s.Cfg.Symbol,
+		Side:             "SELL",
+		Type:             "MARKET",
+		Quantity:         s.normalizer.FormatQty(sellQty),
+		NewClientOrderID: clientOrderID,
+	})
+	if err != nil {
+		s.lastDCAFailure = time.Now()
+		logger.Error("❌ DCA take-profit sell failed", "error", err)
+		return false
+	}
+
+	soldQty, _ := strconv.ParseFloat(resp.ExecutedQty, 64)
+	proceeds, _ := strconv.ParseFloat(resp.CummulativeQuoteQty, 64)
+	if soldQty <= 0 {
+		logger.Warn("⚠️ DCA take-profit sell not executed", "id", clientOrderID, "status", resp.Status)
+		return false
+	}
+	sellPrice := proceeds / soldQty
+	profit := (sellPrice - avgEntry) * soldQty
+	s.recordRealizedProfit(profit)
+
+	s.updateBalance(s.Cfg.BaseAsset, -soldQty)
+	s.updateBalance(s.Cfg.QuoteAsset, proceeds)
+
+	// Archive each lot as a closed buy so history, metrics and reconciliation see the trades
+	now := time.Now()
+	for _, lot := range stack.Lots {
+		lotProfit := (sellPrice - lot.Price) * lot.Qty
+		tx := model.Transaction{
+			ID:                lot.OrderID,
+			Symbol:            s.Cfg.Symbol,
+			Type:              "buy",
+			Amount:            fmt.Sprintf("%.8f", lot.Qty),
+			Price:             fmt.Sprintf("%.8f", lot.Price),
+			Fee:               fmt.Sprintf("%.8f", lot.Fee),
+			StatusTransaction: model.StatusClosed,
+			Notes:             fmt.Sprintf("DCA lot | Sold at %.2f (Profit: $%.2f)", sellPrice, lotProfit),
+			ClosedAt:          &now,
+			CreatedAt:         lot.At,
+			UpdatedAt:         now,
+			SellOrderID:       clientOrderID,
+			SellPrice:         sellPrice,
+			SellCreatedAt:     now,
+			QuantitySold:      lot.Qty,
+		}
+		s.recordTrade(tx, sellPrice, now, "dca")
+		if err := s.TransactionRepo.Archive(tx); err != nil {
+			logger.Error("⚠️ Failed to archive DCA lot", "id", lot.OrderID, "error", err)
+		}
+	}
+	if err := s.DCARepo.Close(); err != nil {
+		logger.Error("Failed to persist closed DCA stack", "error", err)
+	}
+
+	logger.Info("💰 DCA stack sold", "id", clientOrderID, "qty", soldQty, "price", fmt.Sprintf("%.2f", sellPrice), "profit", fmt.Sprintf("%.2f", profit), "lots", len(stack.Lots))
+
+	s.sendTradeNotification(model.Transaction{
+		ID:                clientOrderID,
+		TransactionID:     strconv.FormatInt(resp.OrderId, 10),
+		Symbol:            s.Cfg.Symbol,
+		Type:              "sell",
+		Amount:            fmt.Sprintf("%.8f", soldQty),
+		Price:             s.normalizer.FormatPrice(sellPrice),
+		StatusTransaction: model.StatusFilled,
+		Notes:             fmt.Sprintf("DCA take-profit (%d lots)", len(stack.Lots)),
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}, profit, nil)
+	return true
+}
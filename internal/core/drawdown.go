@@ -0,0 +1,240 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"grid-trading-btc-binance/internal/api"
+	"grid-trading-btc-binance/internal/audit"
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/model"
+	"grid-trading-btc-binance/internal/service"
+)
+
+// DrawdownOrderPrefix marks the market sells of the drawdown stop
+const DrawdownOrderPrefix = "DDSTOP_"
+
+var errNoDrawdownPending = errors.New("no drawdown stop sell is waiting for confirmation")
+
+// DrawdownResult summarizes a drawdown stop sell
+type DrawdownResult struct {
+	Positions   int // Positions closed (fully or partly) by the sell
+	SoldQty     float64
+	AvgPrice    float64
+	RealizedPnL float64
+}
+
+// checkDrawdownStop fires the drawdown stop when the unrealized PnL of the grid inventory,
+// marked at the bid, falls to -DRAWDOWN_STOP_USDT or -DRAWDOWN_STOP_PCT of its cost: the grid
+// is paused with a cool-off, its open buys are canceled and DRAWDOWN_SELL_FRACTION of the
+// inventory is market-sold (or waits for /drawdown confirm). Returns true when it fired.
+func (s *Strategy) checkDrawdownStop(bid float64) bool {
+	if (s.Cfg.DrawdownStopUSDT <= 0 && s.Cfg.DrawdownStopPct <= 0) || bid <= 0 {
+		return false
+	}
+	_, qty, cost := s.trackedInventory()
+	if qty <= 0 || cost <= 0 {
+		return false
+	}
+	pnl := qty*bid - cost
+	usdtHit := s.Cfg.DrawdownStopUSDT > 0 && pnl <= -s.Cfg.DrawdownStopUSDT
+	pctHit := s.Cfg.DrawdownStopPct > 0 && pnl <= -cost*s.Cfg.DrawdownStopPct
+	if !usdtHit && !pctHit {
+		return false
+	}
+
+	pnlPct := pnl / cost * 100
+	logger.Error("🩸 Drawdown stop triggered",
+		"unrealized_pnl", fmt.Sprintf("%.2f", pnl),
+		"pnl_pct", fmt.Sprintf("%.2f%%", pnlPct),
+		"cost", fmt.Sprintf("%.2f", cost),
+		"bid", fmt.Sprintf("%.2f", bid),
+	)
+	s.Pause(fmt.Sprintf("drawdown stop: unrealized %.2f %s (%.2f%%)", pnl, s.Cfg.QuoteAsset, pnlPct))
+
+	cooloffUntil := time.Now().Add(time.Duration(s.Cfg.DrawdownCooloffMin) * time.Minute)
+	pending := s.Cfg.DrawdownConfirm && s.Cfg.DrawdownSellFraction > 0
+	if err := s.StateRepo.SetDrawdown(cooloffUntil, pending); err != nil {
+		logger.Error("Failed to persist drawdown stop state", "error", err)
+	}
+
+	// No new inventory while paused: the open grid buys go, the exits stay
+	for _, tx := range s.TransactionRepo.Find(s.gridBuys(model.StatusOpen)) {
+		s.cancelAndArchiveBuy(tx, "Canceled by the drawdown stop")
+	}
+
+	data := service.DrawdownMessageData{
+		Base:          s.Cfg.BaseAsset,
+		Quote:         s.Cfg.QuoteAsset,
+		UnrealizedPnL: pnl,
+		PnLPct:        pnlPct,
+		CostBasis:     cost,
+		SellPct:       s.Cfg.DrawdownSellFraction * 100,
+		Pending:       pending,
+		CooloffUntil:  cooloffUntil.Format("02/01 15:04"),
+	}
+	if !pending && s.Cfg.DrawdownSellFraction > 0 {
+		result, err := s.sellDrawdown(s.Cfg.DrawdownSellFraction, bid)
+		if err != nil {
+			logger.Error("❌ Drawdown stop sell failed", "error", err)
+			data.Error = err.Error()
+		}
+		if result != nil {
+			data.SoldQty = result.SoldQty
+			data.AvgPrice = result.AvgPrice
+			data.RealizedPnL = result.RealizedPnL
+			data.Positions = result.Positions
+		}
+	}
+	s.Notifier.NotifyTemplate(service.CategoryError, service.SeverityCritical, service.TemplateDrawdownStop, data)
+	return true
+}
+
+// ConfirmDrawdownSell executes the sell of a drawdown stop waiting for /drawdown confirm.
+// The cool-off is kept.
+func (s *Strategy) ConfirmDrawdownSell() (*DrawdownResult, error) {
+	if s.Cfg.MonitorOnly {
+		return nil, errMonitorOnly
+	}
+	state := s.StateRepo.Get()
+	if !state.DrawdownPending {
+		return nil, errNoDrawdownPending
+	}
+
+	bid := 0.0
+	if book, err := s.Binance.GetBookTicker(s.Cfg.Symbol); err == nil {
+		bid, _ = strconv.ParseFloat(book.BidPrice, 64)
+	}
+	result, err := s.sellDrawdown(s.Cfg.DrawdownSellFraction, bid)
+
+	cooloffUntil := time.Time{}
+	if state.CooloffUntil != nil {
+		cooloffUntil = *state.CooloffUntil
+	}
+	if err := s.StateRepo.SetDrawdown(cooloffUntil, false); err != nil {
+		logger.Error("Failed to persist drawdown stop state", "error", err)
+	}
+	return result, err
+}
+
+// sellDrawdown market-sells fraction of the tracked inventory. Whole positions are picked from
+// the highest buy price down (the deepest losses) until they cover the fraction; their exits
+// are canceled first so the base asset is free. bid is only the reference for the slippage.
+func (s *Strategy) sellDrawdown(fraction, bid float64) (*DrawdownResult, error) {
+	txs, total, _ := s.trackedInventory()
+	sort.Slice(txs, func(i, j int) bool {
+		pi, _ := strconv.ParseFloat(txs[i].Price, 64)
+		pj, _ := strconv.ParseFloat(txs[j].Price, 64)
+		return pi > pj
+	})
+
+	target := total * fraction
+	var chosen []model.Transaction
+	var chosenQty float64
+	for _, tx := range txs {
+		if chosenQty >= target {
+			break
+		}
+		if tx.StatusTransaction == model.StatusExitPlaced && tx.SellOrderID != "" {
+			audit.Intent(tx.ID, tx.SellOrderID, "cancel_exit", audit.Fields{"reason": "drawdown stop"})
+			if _, err := s.Binance.CancelOrder(s.Cfg.Symbol, tx.SellOrderID); err != nil {
+				logger.Warn("⚠️ Drawdown stop: cannot cancel exit, position kept", "id", tx.ID, "sellOrderID", tx.SellOrderID, "error", err)
+				continue
+			}
+			// Paused: the canceled exit is not revived by the stream
+			tx.SellOrderID = ""
+			tx.SellPrice = 0
+			s.transition(&tx, model.StatusFilled, "drawdown stop: exit canceled")
+			s.TransactionRepo.Update(tx)
+		}
+		q, _ := strconv.ParseFloat(tx.Amount, 64)
+		chosenQty += q
+		chosen = append(chosen, tx)
+	}
+
+	result := &DrawdownResult{}
+	if len(chosen) == 0 {
+		return result, nil
+	}
+
+	// Never sell more than is free (manual moves, fees)
+	freeBase := chosenQty
+	if info, err := s.Binance.GetAccountInfo(); err == nil {
+		for _, b := range info.Balances {
+			if b.Asset == s.Cfg.BaseAsset {
+				freeBase, _ = strconv.ParseFloat(b.Free, 64)
+			}
+		}
+	} else {
+		logger.Warn("⚠️ Drawdown stop: cannot refresh balances, selling tracked qty", "error", err)
+	}
+	sellQty := s.normalizer.FloorQty(math.Min(chosenQty, freeBase))
+	if sellQty < s.normalizer.Filters().MinQty {
+		return result, fmt.Errorf("free %s (%.8f) below the minimum quantity", s.Cfg.BaseAsset, freeBase)
+	}
+
+	orderID := fmt.Sprintf("%s%d", DrawdownOrderPrefix, time.Now().UnixMilli())
+	closes := make([]string, len(chosen))
+	for i, tx := range chosen {
+		closes[i] = tx.ID
+	}
+	logger.Warn("🩸 Drawdown stop: selling", "qty", s.normalizer.FormatQty(sellQty), "positions", len(chosen), "fraction", fraction)
+	audit.Intent(orderID, orderID, "drawdown_sell", audit.Fields{"qty": s.normalizer.FormatQty(sellQty), "fraction": fraction, "bid": bid, "closes": closes})
+	s.Executions.Expect(orderID, bid)
+
+	resp, err := s.Binance.CreateOrder(api.OrderRequest{
+		Symbol:           s.Cfg.Symbol,
+		Side:             "SELL",
+		Type:             "MARKET",
+		Quantity:         s.normalizer.FormatQty(sellQty),
+		NewClientOrderID: orderID,
+	})
+	if err != nil {
+		// Exits canceled and bot paused: after /resume the exposure alarm (or the startup rescue) places them again
+		return result, fmt.Errorf("market sell failed (exits canceled, bot paused): %w", err)
+	}
+
+	soldQty, _ := strconv.ParseFloat(resp.ExecutedQty, 64)
+	proceeds, _ := strconv.ParseFloat(resp.CummulativeQuoteQty, 64)
+	if soldQty <= 0 {
+		return result, fmt.Errorf("market sell %s not executed (status %s)", orderID, resp.Status)
+	}
+	sellPrice := proceeds / soldQty
+	sellFee := 0.0
+	for _, fill := range resp.Fills {
+		commission, _ := strconv.ParseFloat(fill.Commission, 64)
+		sellFee += commission
+	}
+
+	s.updateBalance(s.Cfg.BaseAsset, -soldQty)
+	s.updateBalance(s.Cfg.QuoteAsset, proceeds)
+
+	// The sold quantity closes the chosen positions in order; the last one may be closed in part
+	remaining := soldQty
+	for i := range chosen {
+		if remaining <= 0 {
+			break
+		}
+		tx := &chosen[i]
+		q, _ := strconv.ParseFloat(tx.Amount, 64)
+		part := math.Min(q, remaining)
+		_, profit, full := s.closeSoldPart(tx, part, sellPrice, sellFee*part/soldQty, orderID, "drawdown", "Drawdown stop")
+		if !full {
+			s.TransactionRepo.Update(*tx)
+		}
+		remaining -= part
+		result.Positions++
+		result.RealizedPnL += profit
+	}
+	result.SoldQty = soldQty
+	result.AvgPrice = sellPrice
+
+	logger.Warn("🩸 Drawdown stop: inventory sold", "id", orderID, "qty", soldQty,
+		"avg_price", fmt.Sprintf("%.2f", sellPrice), "profit", fmt.Sprintf("%.2f", result.RealizedPnL), "positions", result.Positions)
+	s.recordFill("SELL", s.normalizer.FormatPrice(sellPrice), s.normalizer.FormatQty(soldQty), result.RealizedPnL)
+	return result, nil
+}
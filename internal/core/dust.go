@@ -0,0 +1,96 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"grid-trading-btc-binance/internal/audit"
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/model"
+)
+
+const (
+	dustConvertInterval = 7 * 24 * time.Hour
+	dustRetryAfter      = time.Hour // After a failed or postponed conversion
+)
+
+// checkDustConversion converts the base asset crumbs left by the rounding of exits to BNB once a
+// week (DUST_CONVERT), and records each conversion in the archive as a "dust" transaction so the
+// balances reconcile. The dust endpoint converts the whole free balance, so it only runs while no
+// position holds free base asset (filled buys without an exit, DCA lots) and the free balance is
+// below the symbol's min notional.
+func (s *Strategy) checkDustConversion() {
+	if !s.Cfg.DustConvert || s.Cfg.MonitorOnly || time.Since(s.dustTriedAt) < dustRetryAfter {
+		return
+	}
+	if last := s.StateRepo.Get().LastDustConvertAt; last != nil && time.Since(*last) < dustConvertInterval {
+		return
+	}
+	s.dustTriedAt = time.Now()
+
+	if held := s.TransactionRepo.Count(s.gridBuys(model.StatusFilled, model.StatusFailed)); held > 0 || len(s.DCARepo.Get().Lots) > 0 {
+		logger.Debug("🧹 Dust conversion postponed: positions hold free base asset", "filled_without_exit", held, "dca_lots", len(s.DCARepo.Get().Lots))
+		return
+	}
+
+	assets, err := s.Binance.GetDustAssets()
+	if err != nil {
+		logger.Warn("⚠️ Dust conversion skipped: cannot list the convertible assets", "error", err)
+		return
+	}
+	var free float64
+	for _, a := range assets {
+		if a.Asset == s.Cfg.BaseAsset {
+			free, _ = strconv.ParseFloat(a.AmountFree, 64)
+		}
+	}
+	price := s.assetPrice(s.Cfg.BaseAsset)
+	if free <= 0 || price <= 0 || free*price >= s.normalizer.MinNotional() {
+		logger.Info("🧹 No dust to convert", "asset", s.Cfg.BaseAsset, "free", free, "value", fmt.Sprintf("%.4f", free*price))
+		s.markDustConverted()
+		return
+	}
+
+	audit.Intent("", "", "dust_convert", audit.Fields{"asset": s.Cfg.BaseAsset, "amount": free, "value": free * price})
+	result, err := s.Binance.ConvertDust([]string{s.Cfg.BaseAsset})
+	if err != nil {
+		logger.Error("❌ Dust conversion failed", "asset", s.Cfg.BaseAsset, "amount", free, "error", err)
+		return
+	}
+	s.markDustConverted()
+
+	now := time.Now()
+	for _, t := range result.TransferResult {
+		amount, _ := strconv.ParseFloat(t.Amount, 64)
+		received, _ := strconv.ParseFloat(t.TransferedAmount, 64)
+		charge, _ := strconv.ParseFloat(t.ServiceChargeAmount, 64)
+		at := time.UnixMilli(t.OperateTime)
+		tx := model.Transaction{
+			ID:                fmt.Sprintf("DUST_%d", t.TranID),
+			TransactionID:     strconv.FormatInt(t.TranID, 10),
+			Symbol:            t.FromAsset,
+			Type:              "dust",
+			Amount:            fmt.Sprintf("%.8f", amount),
+			Price:             fmt.Sprintf("%.8f", price),
+			Fee:               fmt.Sprintf("%.8f", charge),
+			StatusTransaction: model.StatusClosed,
+			Notes:             fmt.Sprintf("Dust converted to %.8f BNB (service charge %.8f BNB)", received, charge),
+			ClosedAt:          &at,
+			CreatedAt:         at,
+			UpdatedAt:         now,
+		}
+		if err := s.TransactionRepo.Archive(tx); err != nil {
+			logger.Error("Failed to archive dust conversion", "tranId", t.TranID, "error", err)
+		}
+		s.updateBalance(t.FromAsset, -amount)
+		s.updateBalance("BNB", received)
+		logger.Info("🧹 Dust converted to BNB", "asset", t.FromAsset, "amount", amount, "bnb", received, "service_charge", charge, "tranId", t.TranID)
+	}
+}
+
+func (s *Strategy) markDustConverted() {
+	if err := s.StateRepo.SetLastDustConvert(time.Now()); err != nil {
+		logger.Error("Failed to persist dust conversion time", "error", err)
+	}
+}
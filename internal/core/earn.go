@@ -0,0 +1,180 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"grid-trading-btc-binance/internal/audit"
+	"grid-trading-btc-binance/internal/crash"
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/service"
+)
+
+const (
+	earnCheckInterval = 10 * time.Minute
+	earnRetryAfter    = time.Minute // After a failed redemption
+)
+
+// StartEarn parks the idle quote asset in Simple Earn Flexible every 10 minutes (EARN_ENABLED):
+// what the wallet holds beyond EARN_BUFFER_ORDERS grid buys (above the reserve and the vault) is
+// subscribed once it reaches EARN_MIN_USDT. Buys redeem what they lack (see fundFromEarn), and
+// the interest paid on the holding is added to the equity baseline.
+func (s *Strategy) StartEarn() {
+	if !s.Cfg.EarnEnabled {
+		return
+	}
+	s.Rebalancer.Parked = s.earnParkedUSDT
+	crash.Go("simple earn", func() {
+		logger.Info("💤 Starting Simple Earn parking", "asset", s.Cfg.QuoteAsset, "buffer_orders", s.Cfg.EarnBufferOrders, "min_usdt", s.Cfg.EarnMinUSDT)
+		ticker := time.NewTicker(earnCheckInterval)
+		defer ticker.Stop()
+		for ; ; <-ticker.C {
+			if s.Binance.RateLimited() {
+				continue
+			}
+			s.checkEarn()
+		}
+	})
+}
+
+// EarnStats returns the Simple Earn holding as of the last check (collector and /status)
+func (s *Strategy) EarnStats() service.EarnStats {
+	s.earnMu.Lock()
+	defer s.earnMu.Unlock()
+	stats := s.earn
+	stats.Interest = s.StateRepo.Get().EarnInterest
+	return stats
+}
+
+// earnParkedUSDT is the quote asset held in Simple Earn, counted as deployable
+func (s *Strategy) earnParkedUSDT() float64 {
+	if !s.Cfg.EarnEnabled {
+		return 0
+	}
+	s.earnMu.Lock()
+	defer s.earnMu.Unlock()
+	return s.earn.Balance
+}
+
+// earnText describes the Simple Earn holding for /status
+func (s *Strategy) earnText() string {
+	stats := s.EarnStats()
+	return fmt.Sprintf("$%.2f em Flexible (APR %.2f%%, juros acumulados $%.4f)", stats.Balance, stats.APR*100, stats.Interest)
+}
+
+// checkEarn refreshes the holding, accounts the interest paid since the last check and parks
+// the idle quote asset
+func (s *Strategy) checkEarn() {
+	s.earnMu.Lock()
+	defer s.earnMu.Unlock()
+
+	if s.earnProductID == "" {
+		products, err := s.Binance.GetEarnProducts(s.Cfg.QuoteAsset)
+		if err != nil {
+			logger.Warn("⚠️ Simple Earn skipped: cannot list the Flexible products", "asset", s.Cfg.QuoteAsset, "error", err)
+			return
+		}
+		for _, p := range products {
+			if p.CanPurchase && p.CanRedeem && !p.IsSoldOut {
+				s.earnProductID = p.ProductID
+				break
+			}
+		}
+		if s.earnProductID == "" {
+			logger.Warn("⚠️ Simple Earn skipped: no Flexible product open for the asset", "asset", s.Cfg.QuoteAsset)
+			return
+		}
+		logger.Info("💤 Simple Earn product", "asset", s.Cfg.QuoteAsset, "product", s.earnProductID)
+	}
+
+	positions, err := s.Binance.GetEarnPositions(s.Cfg.QuoteAsset)
+	if err != nil {
+		logger.Warn("⚠️ Simple Earn check skipped: cannot read the Flexible holding", "error", err)
+		return
+	}
+	var balance, rewards, apr float64
+	for _, p := range positions {
+		if p.ProductID == s.earnProductID {
+			balance, rewards, apr = p.Amounts()
+		}
+	}
+	s.earn.Balance, s.earn.APR = balance, apr
+	s.accountEarnInterest(rewards)
+
+	if s.IsPaused() || s.Cfg.MonitorOnly {
+		return
+	}
+	wallet := s.walletDeployableUSDT()
+	buffer := s.calculateOrderValue(wallet+s.earn.Balance) * float64(s.Cfg.EarnBufferOrders)
+	excess := math.Floor((wallet-buffer)*100) / 100
+	if excess < s.Cfg.EarnMinUSDT {
+		return
+	}
+	audit.Intent("", "", "earn_subscribe", audit.Fields{"amount": excess, "asset": s.Cfg.QuoteAsset, "product": s.earnProductID})
+	if err := s.Binance.SubscribeEarn(s.earnProductID, fmt.Sprintf("%.2f", excess)); err != nil {
+		logger.Error("❌ Simple Earn subscription failed", "amount", excess, "error", err)
+		return
+	}
+	s.updateBalance(s.Cfg.QuoteAsset, -excess)
+	s.earn.Balance += excess
+	logger.Info("💤 Idle quote asset parked in Simple Earn", "amount", excess, "buffer", buffer, "parked", s.earn.Balance)
+}
+
+// accountEarnInterest adds the growth of the position's cumulative rewards to the tracked
+// interest and to the equity baseline. The first reading only sets the starting point; a
+// drop (position fully redeemed and reopened) restarts from the new value. Needs earnMu.
+func (s *Strategy) accountEarnInterest(rewards float64) {
+	state := s.StateRepo.Get()
+	if state.EarnStartedAt != nil && rewards == state.EarnRewardsSeen {
+		return
+	}
+	startedAt, interest := time.Now(), 0.0
+	if state.EarnStartedAt != nil {
+		startedAt, interest = *state.EarnStartedAt, state.EarnInterest
+		if earned := rewards - state.EarnRewardsSeen; earned > 0 {
+			interest += earned
+			if s.EquityRepo != nil && s.EquityRepo.Initialized() {
+				if err := s.EquityRepo.AddEarnInterest(earned); err != nil {
+					logger.Error("Failed to persist Simple Earn interest to the equity baseline", "error", err)
+				}
+			}
+			logger.Info("💤 Simple Earn interest", "earned", earned, "total", interest)
+		}
+	}
+	if err := s.StateRepo.SetEarn(startedAt, rewards, interest); err != nil {
+		logger.Error("Failed to persist Simple Earn state", "error", err)
+	}
+}
+
+// fundFromEarn makes sure amount of quote asset is free in the wallet before a buy, redeeming
+// what is missing from Simple Earn (topped up to refill EARN_BUFFER_ORDERS buys of that size).
+// False when the wallet is still short: the buy is skipped and retried on a later ticker.
+func (s *Strategy) fundFromEarn(amount float64) bool {
+	wallet := s.walletDeployableUSDT()
+	if !s.Cfg.EarnEnabled || wallet >= amount {
+		return true
+	}
+	s.earnMu.Lock()
+	defer s.earnMu.Unlock()
+	if s.earnProductID == "" || s.earn.Balance <= 0 || time.Since(s.earnFailedAt) < earnRetryAfter ||
+		s.monitorOnly("Simple Earn redemption", "amount", amount-wallet) {
+		return false
+	}
+
+	redeem := math.Ceil(math.Max(amount*float64(s.Cfg.EarnBufferOrders)-wallet, s.Cfg.EarnMinUSDT)*100) / 100
+	all := redeem >= s.earn.Balance
+	if all {
+		redeem = s.earn.Balance
+	}
+	audit.Intent("", "", "earn_redeem", audit.Fields{"amount": redeem, "all": all, "asset": s.Cfg.QuoteAsset, "product": s.earnProductID})
+	if err := s.Binance.RedeemEarn(s.earnProductID, fmt.Sprintf("%.2f", redeem), all); err != nil {
+		s.earnFailedAt = time.Now()
+		logger.Error("❌ Simple Earn redemption failed, buy skipped", "amount", redeem, "error", err)
+		return false
+	}
+	s.updateBalance(s.Cfg.QuoteAsset, redeem)
+	s.earn.Balance -= redeem
+	logger.Info("💤 Redeemed from Simple Earn for a buy", "amount", redeem, "needed", amount, "parked", s.earn.Balance)
+	return wallet+redeem >= amount
+}
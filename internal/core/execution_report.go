@@ -0,0 +1,53 @@
+package core
+
+import (
+	"time"
+
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/service"
+)
+
+// checkExecutionReport sends the previous day's fill quality (maker ratio and slippage from
+// logs/executions.csv) once the day is over. Days without fills are skipped silently.
+func (s *Strategy) checkExecutionReport() {
+	if s.Executions == nil {
+		return
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	yesterday := today.AddDate(0, 0, -1)
+	day := yesterday.Format("2006-01-02")
+	if s.StateRepo.Get().ExecutionReportDay >= day {
+		return
+	}
+
+	stats, err := service.ReadExecutionStats(service.ExecutionsCSVPath, yesterday, today)
+	if err != nil {
+		logger.Error("Failed to read the execution log", "error", err)
+		return
+	}
+	if stats.Fills > 0 {
+		regime, err := s.VolatilityService.RegimeStats(yesterday, today)
+		if err != nil {
+			logger.Warn("⚠️ Failed to read the regime history", "error", err)
+		}
+		s.Notifier.NotifyTemplate(service.CategoryReport, service.SeverityInfo, service.TemplateExecutionReport, service.ExecutionReportMessageData{
+			Day:              day,
+			Symbol:           s.Cfg.Symbol,
+			Fills:            stats.Fills,
+			TakerFills:       stats.Fills - stats.MakerFills,
+			MakerRatioPct:    stats.MakerRatioPct(),
+			SlippageBps:      stats.SlippageBps(),
+			WorstSlippageBps: stats.WorstSlippage,
+			RegimeChanges:    regime.Changes,
+			HighVolPct:       regime.HighVolPct,
+		})
+		logger.Info("🎯 Daily execution report sent", "day", day, "fills", stats.Fills,
+			"maker_ratio_pct", stats.MakerRatioPct(), "slippage_bps", stats.SlippageBps())
+	}
+
+	if err := s.StateRepo.SetExecutionReportDay(day); err != nil {
+		logger.Error("Failed to persist execution report day", "error", err)
+	}
+}
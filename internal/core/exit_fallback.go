@@ -0,0 +1,170 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"grid-trading-btc-binance/internal/api"
+	"grid-trading-btc-binance/internal/audit"
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/model"
+	"grid-trading-btc-binance/internal/tracing"
+)
+
+// exitRetryMaxBackoff caps the wait between maker exit attempts: the position has no exit
+// meanwhile and the update worker of its shard is blocked
+const exitRetryMaxBackoff = 4 * time.Second
+
+// exitFallback sells qty of tx after its maker exit could not be placed EXIT_FALLBACK_AFTER
+// times: a MARKET sell, or with EXIT_FALLBACK_MODE=limit an IOC limit at most
+// EXIT_FALLBACK_MAX_SLIPPAGE_PCT below the best bid. The position is closed from the order
+// response. Returns true when it was fully sold; a partial IOC fill archives the sold part
+// and leaves the rest of tx to the failed_placement path. The sell never takes the free base
+// owed to other positions (filled buys waiting for their exit, the DCA stack).
+func (s *Strategy) exitFallback(ctx context.Context, tx *model.Transaction, qty float64) bool {
+	mode := s.Cfg.ExitFallbackMode
+
+	if spare := s.getBalance(s.Cfg.BaseAsset) - s.owedBase(tx.ID); qty > spare {
+		logger.Warn("⚠️ Exit fallback capped to the free base not owed to other positions", "id", tx.ID, "wanted", qty, "spare", spare)
+		qty = s.normalizer.FloorQty(math.Max(spare, 0))
+	}
+	if qty < s.normalizer.Filters().MinQty {
+		logger.Error("❌ Exit fallback skipped: no free base left for this position", "id", tx.ID, "qty", qty)
+		return false
+	}
+
+	bid := 0.0
+	if book, err := s.Binance.GetBookTicker(s.Cfg.Symbol); err == nil {
+		bid, _ = strconv.ParseFloat(book.BidPrice, 64)
+	}
+	if mode == "limit" && bid <= 0 {
+		logger.Error("❌ Exit fallback skipped: best bid unavailable", "id", tx.ID)
+		return false
+	}
+
+	orderID := fallbackExitOrderID(tx.ID)
+	req := api.OrderRequest{
+		Symbol:           s.Cfg.Symbol,
+		Side:             "SELL",
+		Type:             "MARKET",
+		Quantity:         s.normalizer.FormatQty(qty),
+		NewClientOrderID: orderID,
+	}
+	if mode == "limit" {
+		req.Type = "LIMIT"
+		req.TimeInForce = "IOC"
+		req.Price = s.normalizer.SellPrice(bid * (1 - s.Cfg.ExitFallbackMaxSlippagePct))
+	}
+
+	logger.Warn("🆘 Maker exit failed: selling through the fallback", "id", tx.ID, "mode", mode, "qty", req.Quantity, "bid", bid, "price", req.Price)
+	audit.Intent(tx.ID, orderID, "exit_fallback", audit.Fields{
+		"mode":      mode,
+		"buy_price": tx.Price,
+		"bid":       bid,
+		"price":     req.Price,
+		"qty":       req.Quantity,
+	})
+	s.Executions.Expect(orderID, bid)
+
+	var resp *api.OrderResponse
+	err := tracing.Call(ctx, "binance.create_order", func() (err error) {
+		resp, err = s.Binance.CreateOrder(req)
+		return err
+	}, attribute.String("client_order_id", orderID), attribute.String("fallback", mode))
+	if err != nil {
+		logger.Error("❌ Exit fallback order failed", "id", tx.ID, "error", err)
+		return false
+	}
+
+	soldQty, _ := strconv.ParseFloat(resp.ExecutedQty, 64)
+	proceeds, _ := strconv.ParseFloat(resp.CummulativeQuoteQty, 64)
+	if soldQty <= 0 {
+		logger.Warn("⚠️ Exit fallback not executed", "id", tx.ID, "orderID", orderID, "status", resp.Status)
+		return false
+	}
+	sellPrice := proceeds / soldQty
+
+	sellFee := 0.0
+	for _, fill := range resp.Fills {
+		commission, _ := strconv.ParseFloat(fill.Commission, 64)
+		sellFee += commission
+	}
+
+	s.updateBalance(s.Cfg.BaseAsset, -soldQty)
+	s.updateBalance(s.Cfg.QuoteAsset, proceeds)
+
+	// The sold part is archived as its own closed trade; its share of the buy fee goes with it
+	closed, profit, full := s.closeSoldPart(tx, soldQty, sellPrice, sellFee, resp.ClientOrderId, "fallback", "Fallback "+mode+" exit")
+	if !full {
+		logger.Warn("⚠️ Exit fallback partially filled", "id", tx.ID, "sold", closed.Amount, "remaining", tx.Amount)
+	}
+
+	logger.Info("💰 Exit fallback filled", "id", tx.ID, "orderID", orderID, "mode", mode,
+		"qty", closed.Amount, "price", fmt.Sprintf("%.2f", sellPrice), "profit", fmt.Sprintf("%.2f", profit))
+
+	sellTx := closed
+	sellTx.ID = resp.ClientOrderId
+	sellTx.TransactionID = strconv.FormatInt(resp.OrderId, 10)
+	sellTx.Type = "sell"
+	sellTx.Price = s.normalizer.FormatPrice(sellPrice)
+	sellTx.StatusTransaction = model.StatusFilled
+	s.recordFill("SELL", sellTx.Price, sellTx.Amount, profit)
+	s.sendTradeNotification(sellTx, profit, nil)
+	return full
+}
+
+// findPlacedExit looks sellOrderID up after its placement failed. It returns the exit when it is
+// working or filled; known is false when the lookup itself failed, so the exit may exist.
+func (s *Strategy) findPlacedExit(sellOrderID string) (*api.OrderResponse, bool) {
+	order, err := s.Binance.GetOrder(s.Cfg.Symbol, sellOrderID)
+	if err != nil {
+		if strings.Contains(err.Error(), "-2013") { // Order does not exist
+			return nil, true
+		}
+		logger.Warn("⚠️ Could not check whether the maker exit exists", "sellOrderID", sellOrderID, "error", err)
+		return nil, false
+	}
+	switch order.Status {
+	case "NEW", "PARTIALLY_FILLED", "FILLED":
+		return order, true
+	}
+	return nil, true
+}
+
+// exitRejected reports whether Binance refused the maker exit itself: -2010 (rejected, e.g. it
+// would match as taker) or -1013 (filter failure). Anything else, a transport error included,
+// leaves the outcome unknown and must not trigger the fallback.
+func exitRejected(err error) bool {
+	body, ok := strings.CutPrefix(err.Error(), "api error: ")
+	if !ok {
+		return false
+	}
+	var apiErr struct {
+		Code int `json:"code"`
+	}
+	if json.Unmarshal([]byte(body), &apiErr) != nil {
+		return false
+	}
+	return apiErr.Code == -2010 || apiErr.Code == -1013
+}
+
+// owedBase is the free base asset held for positions other than exceptID ("" = all): filled
+// grid buys without a working exit (filled, failed_placement) and the DCA stack
+func (s *Strategy) owedBase(exceptID string) float64 {
+	owed := s.DCARepo.Get().Qty()
+	for _, tx := range s.TransactionRepo.Find(s.gridBuys(model.StatusFilled, model.StatusFailed)) {
+		if tx.ID == exceptID {
+			continue
+		}
+		qty, _ := strconv.ParseFloat(tx.Amount, 64)
+		owed += qty
+	}
+	return owed
+}
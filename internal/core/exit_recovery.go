@@ -0,0 +1,80 @@
+package core
+
+import (
+	"time"
+
+	"grid-trading-btc-binance/internal/crash"
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/model"
+	"grid-trading-btc-binance/internal/service"
+	"grid-trading-btc-binance/internal/tracing"
+)
+
+// StartExitRecovery retries the exit of failed_placement buys every EXIT_RECOVERY_INTERVAL_MIN
+func (s *Strategy) StartExitRecovery() {
+	if s.Cfg.ExitRecoveryIntervalMin <= 0 {
+		return
+	}
+	crash.Go("exit recovery", func() {
+		interval := time.Duration(s.Cfg.ExitRecoveryIntervalMin) * time.Minute
+		logger.Info("⏰ Starting Exit Recovery", "interval", interval.String(), "deadline_min", s.Cfg.ExitRecoveryDeadlineMin)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			s.recoverFailedExits()
+		}
+	})
+}
+
+// recoverFailedExits places the exit of every failed_placement buy again. Once
+// EXIT_RECOVERY_DEADLINE_MIN elapsed since the first failure the position is escalated:
+// critical alert, no more retries, and the periodic sync purges it as before.
+func (s *Strategy) recoverFailedExits() {
+	if s.IsPaused() {
+		return
+	}
+	deadline := time.Duration(s.Cfg.ExitRecoveryDeadlineMin) * time.Minute
+
+	for _, tx := range s.TransactionRepo.Find(s.gridBuys(model.StatusFailed)) {
+		if tx.ExitEscalated {
+			continue
+		}
+		failedAt := tx.UpdatedAt
+		if tx.ExitFailedAt != nil {
+			failedAt = *tx.ExitFailedAt
+		}
+		elapsed := time.Since(failedAt).Round(time.Minute)
+
+		if elapsed >= deadline {
+			logger.Error("🚨 Exit recovery gave up: manual intervention needed", "id", tx.ID, "attempts", tx.ExitRecoveries, "elapsed", elapsed.String())
+			tx.ExitEscalated = true
+			tx.Notes += " | Exit recovery escalated to manual"
+			s.TransactionRepo.Update(tx)
+			s.Notifier.NotifyTemplate(service.CategoryError, service.SeverityCritical, service.TemplateExitEscalated, service.ExitRecoveryMessageData{
+				ID:       tx.ID,
+				Attempts: tx.ExitRecoveries,
+				Elapsed:  elapsed.String(),
+				Amount:   tx.Amount,
+				Price:    tx.Price,
+			})
+			continue
+		}
+
+		tx.ExitRecoveries++
+		attempts := tx.ExitRecoveries
+		logger.Info("🚑 Retrying failed exit", "id", tx.ID, "attempt", attempts, "failed_for", elapsed.String())
+		s.placeMakerExitOrder(tracing.FromNotes(tx.Notes), &tx)
+
+		if tx.StatusTransaction != model.StatusFailed {
+			logger.Info("✅ Failed exit recovered", "id", tx.ID, "status", tx.StatusTransaction, "attempts", attempts)
+			s.Notifier.NotifyTemplate(service.CategoryExit, service.SeverityInfo, service.TemplateExitRecovered, service.ExitRecoveryMessageData{
+				ID:       tx.ID,
+				Attempts: attempts,
+				Elapsed:  elapsed.String(),
+				Amount:   tx.Amount,
+				Price:    tx.Price,
+			})
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"grid-trading-btc-binance/internal/api"
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/model"
+	"grid-trading-btc-binance/internal/service"
+	"grid-trading-btc-binance/internal/tracing"
+)
+
+const maxExitPriceOffset = 100 // Ticks tried above the target before giving up on a free level
+
+// reserveExitPrice moves price up one tick at a time until no other exit (placed, or being
+// placed by another fill) sits on the same level. Buys filled at nearly the same price would
+// otherwise share an exit level. release frees the reservation once the exit is persisted.
+func (s *Strategy) reserveExitPrice(tx *model.Transaction, price string) (string, func()) {
+	s.exitMu.Lock()
+	defer s.exitMu.Unlock()
+
+	taken := make(map[string]bool)
+	for level, buyID := range s.exitLevels {
+		if buyID != tx.ID {
+			taken[level] = true
+		}
+	}
+	for _, other := range s.TransactionRepo.Find(s.gridBuys(model.StatusExitPlaced)) {
+		if other.ID == tx.ID || other.SellPrice <= 0 {
+			continue
+		}
+		taken[s.normalizer.SellPrice(other.SellPrice)] = true
+	}
+
+	chosen := price
+	if taken[price] {
+		tick := s.normalizer.Filters().TickSize
+		p, _ := strconv.ParseFloat(price, 64)
+		for i := 1; i <= maxExitPriceOffset; i++ {
+			candidate := s.normalizer.SellPrice(p + float64(i)*tick)
+			if !taken[candidate] {
+				logger.Info("↕️ Exit price clash avoided", "id", tx.ID, "target", price, "price", candidate, "ticks", i)
+				chosen = candidate
+				break
+			}
+		}
+	}
+
+	if s.exitLevels == nil {
+		s.exitLevels = make(map[string]string)
+	}
+	s.exitLevels[chosen] = tx.ID
+	return chosen, func() {
+		s.exitMu.Lock()
+		defer s.exitMu.Unlock()
+		if s.exitLevels[chosen] == tx.ID {
+			delete(s.exitLevels, chosen)
+		}
+	}
+}
+
+// exitInFlight reports whether an exit of txID is being placed right now
+func (s *Strategy) exitInFlight(txID string) bool {
+	s.exitMu.Lock()
+	defer s.exitMu.Unlock()
+	for _, buyID := range s.exitLevels {
+		if buyID == txID {
+			return true
+		}
+	}
+	return false
+}
+
+// findExitOnBook looks for the open exit of a filled buy: the linked exit, then the exit
+// whose client order ID names the buy, then (legacy SELL_<timestamp> exits only, which name no
+// buy) a sell of the same quantity that no other transaction owns. Exits that name a buy are
+// never matched by quantity: two buys of the same size would be indistinguishable.
+func (s *Strategy) findExitOnBook(tx model.Transaction, book map[string]api.OrderResponse) (string, string) {
+	if sellID, ok := s.TransactionRepo.ExitOf(tx.ID); ok {
+		if _, ok := book[sellID]; ok {
+			return sellID, "linked id"
+		}
+	}
+	for clientID, bo := range book {
+		if buyID, ok := exitBuyID(clientID); ok && buyID == tx.ID && bo.Side == "SELL" {
+			return clientID, "client order id"
+		}
+	}
+
+	buyQty, _ := strconv.ParseFloat(tx.Amount, 64)
+	for clientID, bo := range book {
+		if bo.Side != "SELL" || !isUnstructuredExit(clientID) || s.TransactionRepo.IsLinkedExit(clientID) {
+			continue
+		}
+		sellQty, _ := strconv.ParseFloat(bo.OrigQty, 64)
+		if math.Abs(sellQty-buyQty) < 0.00000001 {
+			return clientID, "quantity (legacy exit)"
+		}
+	}
+	return "", ""
+}
+
+// adoptExit rebuilds a buy missing from the DB (lost transactions.json, manual cleanup) from
+// the exit that names it: the buy's fill is read back from Binance and the pair is saved as an
+// exit in place, so the round trip is accounted for when the exit fills.
+func (s *Strategy) adoptExit(exit api.OrderResponse, buyID string) bool {
+	buy, err := s.Binance.GetOrder(s.Cfg.Symbol, buyID)
+	if err != nil || buy.Status != "FILLED" {
+		logger.Warn("⚠️ Cannot adopt exit: its buy was not found filled on Binance", "sellID", exit.ClientOrderId, "buyID", buyID, "error", err)
+		return false
+	}
+
+	price := buy.Price
+	executedQty, _ := strconv.ParseFloat(buy.ExecutedQty, 64)
+	if quoteQty, _ := strconv.ParseFloat(buy.CummulativeQuoteQty, 64); executedQty > 0 && quoteQty > 0 {
+		price = s.normalizer.FormatPrice(quoteQty / executedQty)
+	}
+	sellPrice, _ := strconv.ParseFloat(exit.Price, 64)
+
+	now := time.Now()
+	tx := model.Transaction{
+		ID:                buyID,
+		TransactionID:     buyID,
+		Symbol:            s.Cfg.Symbol,
+		Type:              "buy",
+		Amount:            buy.ExecutedQty,
+		Price:             price,
+		StatusTransaction: model.StatusExitPlaced,
+		Notes:             "Adopted from exit " + exit.ClientOrderId + " during Startup Sync",
+		CreatedAt:         now,
+		UpdatedAt:         now,
+		SellOrderID:       exit.ClientOrderId,
+		SellPrice:         sellPrice,
+		SellCreatedAt:     now,
+	}
+	if err := s.TransactionRepo.Save(tx); err != nil {
+		logger.Error("Failed to save adopted buy", "id", buyID, "error", err)
+		return false
+	}
+	logger.Info("🔗 Exit adopted: buy rebuilt from Binance", "buyID", buyID, "sellID", exit.ClientOrderId, "price", price, "qty", buy.ExecutedQty)
+	return true
+}
+
+// closeSoldPart archives soldQty of tx, sold at sellPrice by sellOrderID, as a closed trade
+// carrying its share of the buy fee plus sellFee, and records the realized profit. When what
+// is left is below the minimum lot the position is closed and removed (tx becomes the archived
+// copy); otherwise tx keeps the rest, still active. Returns the archived copy, its profit and
+// whether the position was closed.
+func (s *Strategy) closeSoldPart(tx *model.Transaction, soldQty, sellPrice, sellFee float64, sellOrderID, source, reason string) (model.Transaction, float64, bool) {
+	buyPrice, _ := strconv.ParseFloat(tx.Price, 64)
+	buyQty, _ := strconv.ParseFloat(tx.Amount, 64)
+	buyFee, _ := strconv.ParseFloat(tx.Fee, 64)
+	soldShare := 1.0
+	if buyQty > soldQty {
+		soldShare = soldQty / buyQty
+	}
+	remaining := s.normalizer.FloorQty(buyQty - soldQty)
+	full := remaining < s.normalizer.Filters().MinQty
+
+	now := time.Now()
+	profit := (sellPrice - buyPrice) * soldQty
+	s.recordRealizedProfit(profit)
+
+	closed := *tx
+	closed.Amount = s.normalizer.FormatQty(soldQty)
+	closed.Fee = fmt.Sprintf("%.8f", buyFee*soldShare+sellFee)
+	closed.SellOrderID = sellOrderID
+	closed.SellPrice = sellPrice
+	closed.SellCreatedAt = now
+	closed.QuantitySold = soldQty
+	closed.ClosedAt = &now
+	closed.Notes += fmt.Sprintf(" | %s at %.2f (Profit: $%.2f)", reason, sellPrice, profit)
+	if full {
+		s.transition(&closed, model.StatusClosed, reason+": "+sellOrderID)
+	} else {
+		// The buy stays active for the rest: only the archived copy is closed
+		closed.StatusTransaction = model.StatusClosed
+		closed.UpdatedAt = now
+	}
+	s.recordTrade(closed, sellPrice, now, source)
+
+	if err := s.TransactionRepo.Archive(closed); err != nil {
+		logger.Error("⚠️ Failed to archive transaction", "id", tx.ID, "error", err)
+	}
+	if full {
+		if err := s.TransactionRepo.Delete(tx.ID); err != nil {
+			logger.Error("⚠️ Failed to delete active transaction after archive", "id", tx.ID, "error", err)
+		}
+		*tx = closed
+		return closed, profit, true
+	}
+
+	tx.Amount = s.normalizer.FormatQty(remaining)
+	tx.Fee = fmt.Sprintf("%.8f", buyFee*(1-soldShare))
+	tx.Notes += fmt.Sprintf(" | %s sold %s, %s left", reason, closed.Amount, tx.Amount)
+	return closed, profit, false
+}
+
+// reviveCanceledExit handles an exit canceled, rejected or expired outside the bot: the part it
+// sold before is archived, the buy goes back to filled and a new exit is placed right away,
+// instead of leaving the position naked until the periodic sync notices. While paused (kill
+// switch, which cancels every order itself) the position is left alone.
+func (s *Strategy) reviveCanceledExit(tx model.Transaction, event service.OrderUpdate) {
+	if s.IsPaused() {
+		logger.Warn("⚠️ Maker Exit Order Canceled/Rejected while paused", "id", tx.ID, "sellOrderID", tx.SellOrderID, "status", event.Status)
+		return
+	}
+	logger.Warn("⚠️ Maker Exit Order Canceled/Rejected. Placing a new exit.", "id", tx.ID, "sellOrderID", tx.SellOrderID, "status", event.Status)
+
+	data := service.ExitCanceledMessageData{ID: tx.ID, SellOrderID: event.ClientOrderID, Status: event.Status}
+	if sold, _ := strconv.ParseFloat(event.CumExecQty, 64); sold > 0 {
+		price, _ := strconv.ParseFloat(event.Price, 64)
+		closed, profit, full := s.closeSoldPart(&tx, sold, price, 0, event.ClientOrderID, "grid", "Exit "+event.Status+" after a partial fill")
+		s.recordFill("SELL", event.Price, closed.Amount, profit)
+		if full {
+			return
+		}
+		data.Sold = closed.Amount
+	}
+
+	tx.SellOrderID = ""
+	tx.SellPrice = 0
+	if !s.transition(&tx, model.StatusFilled, "WS: exit "+event.Status+": needs new exit") {
+		return
+	}
+	tx.Notes += fmt.Sprintf(" | Exit %s via WS (re-placed)", event.Status)
+	s.TransactionRepo.Update(tx)
+	s.Notifier.NotifyTemplate(service.CategoryExit, service.SeverityWarning, service.TemplateExitCanceled, data)
+
+	s.placeMakerExitOrder(tracing.FromNotes(tx.Notes), &tx)
+}
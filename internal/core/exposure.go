@@ -0,0 +1,179 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"grid-trading-btc-binance/internal/api"
+	"grid-trading-btc-binance/internal/crash"
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/model"
+	"grid-trading-btc-binance/internal/service"
+	"grid-trading-btc-binance/internal/tracing"
+)
+
+const exposureCheckInterval = 20 * time.Second
+
+// nakedPosition is a filled buy seen without a live exit
+type nakedPosition struct {
+	since   time.Time
+	alerted bool
+}
+
+// StartExposureAlarm checks at runtime that every filled grid buy has a live exit on Binance
+// (the startup zombie rescue, continuously). A position without one for EXPOSURE_MAX_NAKED_SEC
+// gets a critical alert and a new exit. failed_placement positions are left to the exit
+// recovery.
+func (s *Strategy) StartExposureAlarm() {
+	limit := time.Duration(s.Cfg.ExposureMaxNakedSec) * time.Second
+	if limit <= 0 {
+		return
+	}
+
+	crash.Go("exposure alarm", func() {
+		logger.Info("🛡️ Starting exposure alarm", "max_naked", limit.String())
+		naked := make(map[string]*nakedPosition)
+
+		ticker := time.NewTicker(exposureCheckInterval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			s.checkExposure(naked, limit, now)
+		}
+	})
+}
+
+// checkExposure runs one pass of the exposure alarm over the positions tracked in naked
+func (s *Strategy) checkExposure(naked map[string]*nakedPosition, limit time.Duration, now time.Time) {
+	if s.Cfg.MonitorOnly || s.IsPaused() {
+		return
+	}
+	openOrders, err := s.Binance.GetOpenOrders(s.Cfg.Symbol)
+	if err != nil {
+		logger.Warn("⚠️ Exposure check skipped: cannot fetch open orders", "error", err)
+		return
+	}
+	book := make(map[string]api.OrderResponse, len(openOrders))
+	for _, o := range openOrders {
+		book[o.ClientOrderId] = o
+	}
+
+	// The book is read first: an exit placed meanwhile only shows up once, within the grace period
+	current := make(map[string]model.Transaction)
+	for _, tx := range s.TransactionRepo.Find(s.gridBuys(model.StatusFilled, model.StatusExitPlaced)) {
+		if sellID, _ := s.findExitOnBook(tx, book); sellID != "" || s.exitInFlight(tx.ID) {
+			continue
+		}
+		current[tx.ID] = tx
+	}
+
+	wasAlerted := false
+	for id, pos := range naked {
+		wasAlerted = wasAlerted || pos.alerted
+		if _, ok := current[id]; !ok {
+			delete(naked, id)
+		}
+	}
+
+	var overdue []model.Transaction
+	gone := make(map[string]*api.OrderResponse) // Linked exits no longer working, by buy ID
+	for id, tx := range current {
+		pos, ok := naked[id]
+		if !ok {
+			naked[id] = &nakedPosition{since: now}
+			continue
+		}
+		if now.Sub(pos.since) < limit {
+			continue
+		}
+		if tx.SellOrderID != "" {
+			exit, ok := s.exitGone(tx)
+			if !ok {
+				delete(naked, id) // Filled or still working: not naked
+				continue
+			}
+			gone[id] = exit
+		}
+		overdue = append(overdue, tx)
+	}
+
+	if len(overdue) == 0 {
+		if wasAlerted && !anyAlerted(naked) {
+			failed := s.TransactionRepo.Count(s.gridBuys(model.StatusFailed))
+			logger.Info("✅ Exposure restored: every filled buy has an exit", "failed_placement", failed)
+			s.Notifier.NotifyTemplate(service.CategoryError, service.SeverityInfo, service.TemplateExposureRestored, service.ExposureMessageData{Failed: failed})
+		}
+		return
+	}
+	sort.Slice(overdue, func(i, j int) bool { return naked[overdue[i].ID].since.Before(naked[overdue[j].ID].since) })
+
+	var positions []string
+	for _, tx := range overdue {
+		pos := naked[tx.ID]
+		if pos.alerted {
+			continue
+		}
+		pos.alerted = true
+		age := now.Sub(pos.since).Round(time.Second)
+		positions = append(positions, fmt.Sprintf("%s: %s @ %s (%s)", tx.ID, tx.Amount, tx.Price, age))
+		logger.Error("🚨 Filled buy without a live exit", "id", tx.ID, "status", tx.StatusTransaction, "sellOrderID", tx.SellOrderID, "naked_for", age.String())
+	}
+	if len(positions) > 0 {
+		s.Notifier.NotifyTemplate(service.CategoryError, service.SeverityCritical, service.TemplateExposureNaked, service.ExposureMessageData{
+			Count:     len(positions),
+			Limit:     limit.String(),
+			Positions: positions,
+		})
+	}
+
+	for _, tx := range overdue {
+		s.repairExposure(tx, gone[tx.ID])
+	}
+}
+
+// exitGone returns the exit linked to tx when it is no longer working on Binance (canceled,
+// rejected or expired). Filled exits and lookup errors are left to the stream and the sync.
+func (s *Strategy) exitGone(tx model.Transaction) (*api.OrderResponse, bool) {
+	resp, err := s.Binance.GetOrder(s.Cfg.Symbol, tx.SellOrderID)
+	if err != nil {
+		logger.Warn("⚠️ Exposure check: cannot verify exit", "id", tx.ID, "sellOrderID", tx.SellOrderID, "error", err)
+		return nil, false
+	}
+	switch resp.Status {
+	case "CANCELED", "REJECTED", "EXPIRED":
+		return resp, true
+	}
+	return nil, false
+}
+
+// repairExposure places a new exit for a naked position: a linked exit that is gone is revived
+// like one canceled outside the bot, a buy that never got one has it placed now
+func (s *Strategy) repairExposure(tx model.Transaction, exit *api.OrderResponse) {
+	// Re-read: the stream may have changed it since the check started
+	current, ok := s.TransactionRepo.Get(tx.ID)
+	if !ok || current.SellOrderID != tx.SellOrderID || s.exitInFlight(tx.ID) ||
+		(current.StatusTransaction != model.StatusFilled && current.StatusTransaction != model.StatusExitPlaced) {
+		return
+	}
+	logger.Warn("🔧 Exposure repair: placing a new exit", "id", tx.ID, "sellOrderID", tx.SellOrderID)
+	if exit == nil {
+		s.placeMakerExitOrder(tracing.FromNotes(current.Notes), &current)
+		return
+	}
+	s.reviveCanceledExit(current, service.OrderUpdate{
+		Symbol:        s.Cfg.Symbol,
+		ClientOrderID: exit.ClientOrderId,
+		Status:        exit.Status,
+		Price:         exit.Price,
+		CumExecQty:    exit.ExecutedQty,
+	})
+}
+
+func anyAlerted(naked map[string]*nakedPosition) bool {
+	for _, pos := range naked {
+		if pos.alerted {
+			return true
+		}
+	}
+	return false
+}
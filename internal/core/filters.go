@@ -0,0 +1,71 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/precision"
+	"grid-trading-btc-binance/internal/service"
+)
+
+const filtersRefreshInterval = 24 * time.Hour
+
+// loadExchangeFilters reads tickSize, stepSize and minNotional on startup. On failure the
+// BTCUSDT defaults stay in use and the periodic refresh tries again.
+func (s *Strategy) loadExchangeFilters() {
+	filters, err := s.fetchExchangeFilters()
+	if err != nil {
+		logger.Error("⚠️ Failed to fetch exchange filters. Using BTCUSDT defaults.", "error", err)
+		return
+	}
+	s.normalizer.SetFilters(filters)
+	s.filtersCheckedAt = time.Now()
+	logger.Info("✅ Exchange filters loaded",
+		"symbol", s.Cfg.Symbol,
+		"tickSize", filters.TickSize,
+		"stepSize", filters.StepSize,
+		"minQty", filters.MinQty,
+		"minNotional", filters.MinNotional,
+	)
+}
+
+// checkExchangeFilters refreshes the filters once a day. Binance occasionally changes them;
+// orders normalized with stale filters are rejected (-1013) until the bot restarts.
+func (s *Strategy) checkExchangeFilters() {
+	if time.Since(s.filtersCheckedAt) < filtersRefreshInterval {
+		return
+	}
+	filters, err := s.fetchExchangeFilters()
+	if err != nil {
+		logger.Warn("⚠️ Exchange filters refresh failed. Retrying on the next sync.", "error", err)
+		return
+	}
+	s.filtersCheckedAt = time.Now()
+
+	changes := s.normalizer.Filters().Changes(filters)
+	if len(changes) == 0 {
+		logger.Debug("Exchange filters unchanged", "symbol", s.Cfg.Symbol)
+		return
+	}
+	s.normalizer.SetFilters(filters)
+	logger.Warn("⚠️ Exchange filters changed. New orders use the new values.", "symbol", s.Cfg.Symbol, "changes", strings.Join(changes, ", "))
+	s.Notifier.NotifyTemplate(service.CategorySync, service.SeverityWarning, service.TemplateFiltersChanged, service.FiltersChangedMessageData{
+		Symbol:  s.Cfg.Symbol,
+		Changes: changes,
+	})
+}
+
+func (s *Strategy) fetchExchangeFilters() (precision.Filters, error) {
+	info, err := s.Binance.GetExchangeInfo(s.Cfg.Symbol)
+	if err != nil {
+		return precision.Filters{}, err
+	}
+	for _, symbol := range info.Symbols {
+		if symbol.Symbol == s.Cfg.Symbol {
+			return precision.ParseFilters(symbol), nil
+		}
+	}
+	return precision.Filters{}, fmt.Errorf("symbol %s not found in exchangeInfo", s.Cfg.Symbol)
+}
@@ -0,0 +1,269 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"grid-trading-btc-binance/internal/api"
+	"grid-trading-btc-binance/internal/audit"
+	"grid-trading-btc-binance/internal/crash"
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/precision"
+	"grid-trading-btc-binance/internal/service"
+)
+
+// HedgeOrderPrefix marks the futures orders of the hedge
+const HedgeOrderPrefix = "HEDGE_"
+
+const (
+	hedgeCheckInterval = time.Minute
+	hedgeRetryAfter    = 15 * time.Minute // After a failed hedge order
+)
+
+// StartHedge runs the futures hedge every minute: a short of HEDGE_RATIO of the grid inventory
+// is opened on HEDGE_SYMBOL when the inventory is worth HEDGE_INVENTORY_USDT or more and the
+// price is below its HEDGE_TREND_HOURS moving average, and bought back when either condition
+// ends. Only the short the hedge opened is managed; any other futures position is left alone.
+func (s *Strategy) StartHedge() {
+	if s.Futures == nil {
+		return
+	}
+	if !s.Cfg.MonitorOnly {
+		if err := s.Futures.SetLeverage(s.Cfg.HedgeSymbol, s.Cfg.HedgeLeverage); err != nil {
+			logger.Warn("⚠️ Futures hedge: cannot set the leverage", "symbol", s.Cfg.HedgeSymbol, "leverage", s.Cfg.HedgeLeverage, "error", err)
+		}
+	}
+
+	crash.Go("futures hedge", func() {
+		logger.Info("🛡️ Starting futures hedge", "symbol", s.Cfg.HedgeSymbol, "inventory_usdt", s.Cfg.HedgeInventoryUSDT,
+			"ratio", s.Cfg.HedgeRatio, "trend_hours", s.Cfg.HedgeTrendHours, "leverage", s.Cfg.HedgeLeverage)
+		ticker := time.NewTicker(hedgeCheckInterval)
+		defer ticker.Stop()
+		for ; ; <-ticker.C {
+			s.checkHedge()
+		}
+	})
+}
+
+// HedgeStats returns the hedge as of the last check (collector and /status)
+func (s *Strategy) HedgeStats() service.HedgeStats {
+	s.hedgeMu.Lock()
+	defer s.hedgeMu.Unlock()
+	stats := s.hedge
+	stats.RealizedPnL = s.StateRepo.Get().HedgeRealizedPnL
+	return stats
+}
+
+// hedgeText describes the hedge for /status
+func (s *Strategy) hedgeText() string {
+	stats := s.HedgeStats()
+	if stats.Qty == 0 {
+		return fmt.Sprintf("sem short (acumulado $%.2f)", stats.RealizedPnL)
+	}
+	return fmt.Sprintf("short %s %s a $%.2f (não realizado $%.2f, acumulado $%.2f)",
+		strconv.FormatFloat(stats.Qty, 'f', -1, 64), s.Cfg.HedgeSymbol, stats.EntryPrice, stats.UnrealizedPnL, stats.RealizedPnL)
+}
+
+// checkHedge runs one pass of the hedge
+func (s *Strategy) checkHedge() {
+	if s.hedgeNormalizer == nil {
+		filters, err := s.Futures.GetFilters(s.Cfg.HedgeSymbol)
+		if err != nil {
+			logger.Warn("⚠️ Futures hedge skipped: cannot read the contract filters", "symbol", s.Cfg.HedgeSymbol, "error", err)
+			return
+		}
+		s.hedgeNormalizer = precision.NewNormalizer(precision.Filters{
+			TickSize:    precision.DefaultFilters.TickSize,
+			StepSize:    filters.StepSize,
+			MinQty:      filters.MinQty,
+			MinNotional: filters.MinNotional,
+		})
+	}
+
+	pos, err := s.Futures.GetPosition(s.Cfg.HedgeSymbol)
+	if err != nil {
+		logger.Warn("⚠️ Futures hedge skipped: cannot read the position", "error", err)
+		return
+	}
+	amt, _ := strconv.ParseFloat(pos.PositionAmt, 64)
+	entry, _ := strconv.ParseFloat(pos.EntryPrice, 64)
+	unrealized, _ := strconv.ParseFloat(pos.UnRealizedProfit, 64)
+
+	state := s.StateRepo.Get()
+	managed := state.HedgeOpenedAt != nil
+	short := 0.0
+	if managed && amt < 0 {
+		short = -amt
+	}
+	s.hedgeMu.Lock()
+	s.hedge = service.HedgeStats{Qty: short, EntryPrice: entry, UnrealizedPnL: unrealized}
+	if short == 0 {
+		s.hedge.EntryPrice, s.hedge.UnrealizedPnL = 0, 0
+	}
+	s.hedgeMu.Unlock()
+
+	// Closed outside the bot (or liquidated): account for it and start over
+	if managed && short == 0 {
+		pnl := s.hedgeIncome(*state.HedgeOpenedAt, 0)
+		logger.Warn("⚠️ Futures hedge closed outside the bot", "symbol", s.Cfg.HedgeSymbol, "pnl", fmt.Sprintf("%.2f", pnl))
+		if err := s.StateRepo.SetHedge(time.Time{}, state.HedgeRealizedPnL+pnl); err != nil {
+			logger.Error("Failed to persist hedge state", "error", err)
+		}
+		return
+	}
+	if s.Cfg.MonitorOnly || time.Since(s.hedgeFailedAt) < hedgeRetryAfter {
+		return
+	}
+
+	bid := 0.0
+	if book, err := s.Binance.GetBookTicker(s.Cfg.Symbol); err == nil {
+		bid, _ = strconv.ParseFloat(book.BidPrice, 64)
+	}
+	average, err := s.hedgeMovingAverage()
+	if err != nil || bid <= 0 {
+		logger.Warn("⚠️ Futures hedge skipped: no price or moving average", "bid", bid, "error", err)
+		return
+	}
+	_, qty, _ := s.trackedInventory()
+	inventoryValue := qty * bid
+	downtrend := bid < average
+	exposed := inventoryValue >= s.Cfg.HedgeInventoryUSDT
+
+	switch {
+	case !managed && amt == 0 && downtrend && exposed && !s.IsPaused():
+		s.openHedge(qty*s.Cfg.HedgeRatio, bid, average, inventoryValue)
+	case managed && !downtrend:
+		s.closeHedge(short, entry, "trend", average, inventoryValue)
+	case managed && !exposed:
+		s.closeHedge(short, entry, "inventory", average, inventoryValue)
+	}
+}
+
+// hedgeMovingAverage is the mean of the last HEDGE_TREND_HOURS hourly closes of SYMBOL
+func (s *Strategy) hedgeMovingAverage() (float64, error) {
+	klines, err := s.Binance.GetRecentKlines(s.Cfg.Symbol, "1h", s.Cfg.HedgeTrendHours)
+	if err != nil {
+		return 0, err
+	}
+	if len(klines) < s.Cfg.HedgeTrendHours {
+		return 0, fmt.Errorf("only %d hourly candles", len(klines))
+	}
+	sum := 0.0
+	for _, k := range klines {
+		c, _ := strconv.ParseFloat(k.Close, 64)
+		sum += c
+	}
+	return sum / float64(len(klines)), nil
+}
+
+// openHedge sells qty of HEDGE_SYMBOL at market
+func (s *Strategy) openHedge(qty, bid, average, inventoryValue float64) {
+	qty = s.hedgeNormalizer.FloorQty(qty)
+	filters := s.hedgeNormalizer.Filters()
+	if qty < filters.MinQty || qty*bid < filters.MinNotional {
+		logger.Debug("Futures hedge too small for the contract", "qty", qty, "min_qty", filters.MinQty, "min_notional", filters.MinNotional)
+		return
+	}
+
+	orderID := fmt.Sprintf("%s%d", HedgeOrderPrefix, time.Now().UnixMilli())
+	logger.Warn("🛡️ Opening futures hedge", "symbol", s.Cfg.HedgeSymbol, "qty", s.hedgeNormalizer.FormatQty(qty),
+		"inventory_usdt", fmt.Sprintf("%.2f", inventoryValue), "bid", bid, "moving_average", fmt.Sprintf("%.2f", average))
+	audit.Intent(orderID, orderID, "hedge_open", audit.Fields{
+		"symbol":          s.Cfg.HedgeSymbol,
+		"qty":             s.hedgeNormalizer.FormatQty(qty),
+		"bid":             bid,
+		"moving_average":  average,
+		"inventory_value": inventoryValue,
+	})
+	resp, err := s.Futures.CreateOrder(api.FuturesOrderRequest{
+		Symbol:           s.Cfg.HedgeSymbol,
+		Side:             "SELL",
+		Quantity:         s.hedgeNormalizer.FormatQty(qty),
+		NewClientOrderID: orderID,
+	})
+	if err != nil {
+		s.hedgeFailedAt = time.Now()
+		logger.Error("❌ Futures hedge order failed", "error", err)
+		return
+	}
+
+	openedAt := time.Now()
+	if err := s.StateRepo.SetHedge(openedAt, s.StateRepo.Get().HedgeRealizedPnL); err != nil {
+		logger.Error("Failed to persist hedge state", "error", err)
+	}
+	filled, _ := strconv.ParseFloat(resp.ExecutedQty, 64)
+	price, _ := strconv.ParseFloat(resp.AvgPrice, 64)
+	logger.Info("🛡️ Futures hedge opened", "id", orderID, "qty", filled, "price", price)
+	s.Notifier.NotifyTemplate(service.CategoryExit, service.SeverityWarning, service.TemplateHedgeOpened, service.HedgeMessageData{
+		Symbol:         s.Cfg.HedgeSymbol,
+		Qty:            filled,
+		Price:          price,
+		InventoryValue: inventoryValue,
+		MovingAverage:  average,
+		TrendHours:     s.Cfg.HedgeTrendHours,
+	})
+}
+
+// closeHedge buys back the short (reduce-only) and books its net result. reason is trend (the
+// price is back above the moving average) or inventory (below HEDGE_INVENTORY_USDT).
+func (s *Strategy) closeHedge(short, entry float64, reason string, average, inventoryValue float64) {
+	orderID := fmt.Sprintf("%s%d", HedgeOrderPrefix, time.Now().UnixMilli())
+	logger.Info("🛡️ Closing futures hedge", "symbol", s.Cfg.HedgeSymbol, "qty", short, "reason", reason)
+	audit.Intent(orderID, orderID, "hedge_close", audit.Fields{"symbol": s.Cfg.HedgeSymbol, "qty": short, "reason": reason})
+	resp, err := s.Futures.CreateOrder(api.FuturesOrderRequest{
+		Symbol:           s.Cfg.HedgeSymbol,
+		Side:             "BUY",
+		Quantity:         s.hedgeNormalizer.FormatQty(short),
+		ReduceOnly:       true,
+		NewClientOrderID: orderID,
+	})
+	if err != nil {
+		s.hedgeFailedAt = time.Now()
+		logger.Error("❌ Futures hedge close failed", "error", err)
+		return
+	}
+	price, _ := strconv.ParseFloat(resp.AvgPrice, 64)
+
+	state := s.StateRepo.Get()
+	pnl := (entry - price) * short // Estimate, replaced by the income history when available
+	if state.HedgeOpenedAt != nil {
+		pnl = s.hedgeIncome(*state.HedgeOpenedAt, pnl)
+	}
+	total := state.HedgeRealizedPnL + pnl
+	if err := s.StateRepo.SetHedge(time.Time{}, total); err != nil {
+		logger.Error("Failed to persist hedge state", "error", err)
+	}
+	s.hedgeMu.Lock()
+	s.hedge = service.HedgeStats{}
+	s.hedgeMu.Unlock()
+
+	logger.Info("🛡️ Futures hedge closed", "id", orderID, "qty", short, "price", price, "pnl", fmt.Sprintf("%.2f", pnl), "total", fmt.Sprintf("%.2f", total))
+	s.Notifier.NotifyTemplate(service.CategoryExit, service.SeverityInfo, service.TemplateHedgeClosed, service.HedgeMessageData{
+		Symbol:         s.Cfg.HedgeSymbol,
+		Qty:            short,
+		Price:          price,
+		InventoryValue: inventoryValue,
+		MovingAverage:  average,
+		TrendHours:     s.Cfg.HedgeTrendHours,
+		Reason:         reason,
+		PnL:            pnl,
+		TotalPnL:       total,
+	})
+}
+
+// hedgeIncome sums the realized PnL, fees and funding of HEDGE_SYMBOL since the hedge opened
+// (fallback when the income history cannot be read)
+func (s *Strategy) hedgeIncome(openedAt time.Time, fallback float64) float64 {
+	income, err := s.Futures.GetIncome(s.Cfg.HedgeSymbol, openedAt.UnixMilli())
+	if err != nil {
+		logger.Warn("⚠️ Futures hedge: cannot read the income history, result estimated", "error", err)
+		return fallback
+	}
+	total := 0.0
+	for _, entry := range income {
+		v, _ := strconv.ParseFloat(entry.Income, 64)
+		total += v
+	}
+	return total
+}
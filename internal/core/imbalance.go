@@ -0,0 +1,92 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"grid-trading-btc-binance/internal/logger"
+)
+
+const (
+	bookImbalanceRecheck = 5 * time.Second // Readings while a buy is delayed (the depth costs weight)
+	bookImbalanceStale   = time.Minute     // A delay not rechecked for this long was given up (the buy signal went away)
+)
+
+// bookVolumes returns the bid and ask volume the imbalance filter reads: the top
+// BOOK_IMBALANCE_DEPTH levels of the REST order book, or the best bid/ask quantities of the
+// ticker being executed. ok is false when there is no usable reading.
+func (s *Strategy) bookVolumes() (bidVolume, askVolume float64, ok bool) {
+	if s.Cfg.BookImbalanceSource == "ticker" {
+		bidVolume, askVolume = s.ticker.BidQty, s.ticker.AskQty
+	} else {
+		depth, err := s.Binance.GetDepth(s.Cfg.Symbol, s.Cfg.BookImbalanceDepth)
+		if err != nil {
+			logger.Warn("⚠️ Order-book imbalance unavailable, buy not filtered", "error", err)
+			return 0, 0, false
+		}
+		bidVolume, askVolume = depth.Volumes()
+	}
+	return bidVolume, askVolume, bidVolume+askVolume > 0
+}
+
+// bookImbalanceGate applies BOOK_IMBALANCE_FILTER to a grid buy about to be placed: false while
+// the imbalance (bid vol - ask vol) / (bid vol + ask vol) is at or below -BOOK_IMBALANCE_THRESHOLD.
+// A buy delayed for BOOK_IMBALANCE_MAX_DELAY_SEC is placed anyway. Every reading goes to the
+// imbalance log; an unavailable reading never blocks the buy.
+func (s *Strategy) bookImbalanceGate() bool {
+	if !s.Cfg.BookImbalanceFilter {
+		return true
+	}
+	now := time.Now()
+	if !s.imbalanceDelayedAt.IsZero() && now.Sub(s.imbalanceCheckedAt) > bookImbalanceStale {
+		s.imbalanceDelayedAt = time.Time{}
+	}
+	delaying := !s.imbalanceDelayedAt.IsZero()
+	if delaying && now.Sub(s.imbalanceCheckedAt) < bookImbalanceRecheck {
+		return false
+	}
+	s.imbalanceCheckedAt = now
+
+	bidVolume, askVolume, ok := s.bookVolumes()
+	if !ok {
+		s.imbalanceDelayedAt = time.Time{}
+		return true
+	}
+	imbalance := (bidVolume - askVolume) / (bidVolume + askVolume)
+
+	var delayed time.Duration
+	if delaying {
+		delayed = now.Sub(s.imbalanceDelayedAt)
+	}
+	maxDelay := time.Duration(s.Cfg.BookImbalanceMaxDelaySec) * time.Second
+	action := "buy"
+	if imbalance <= -s.Cfg.BookImbalanceThreshold {
+		action = "delay"
+		if maxDelay > 0 && delayed >= maxDelay {
+			action = "forced"
+		}
+	}
+	s.BookImbalance.Record(now, s.Cfg.BookImbalanceSource, s.ticker.Bid, s.ticker.Ask, bidVolume, askVolume, imbalance, action, delayed)
+
+	switch action {
+	case "delay":
+		if !delaying {
+			s.imbalanceDelayedAt = now
+			logger.Info("⏳ Grid buy delayed: ask-side pressure",
+				"imbalance", fmt.Sprintf("%.2f", imbalance),
+				"bid_volume", bidVolume,
+				"ask_volume", askVolume,
+				"max_delay", maxDelay.String(),
+			)
+		}
+		return false
+	case "forced":
+		logger.Warn("⏳ Ask-side pressure persists, placing the delayed buy", "imbalance", fmt.Sprintf("%.2f", imbalance), "delayed", delayed.Round(time.Second).String())
+	default:
+		if delaying {
+			logger.Info("✅ Ask-side pressure eased, placing the delayed buy", "imbalance", fmt.Sprintf("%.2f", imbalance), "delayed", delayed.Round(time.Second).String())
+		}
+	}
+	s.imbalanceDelayedAt = time.Time{}
+	return true
+}
@@ -0,0 +1,37 @@
+package core
+
+import (
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/model"
+	"grid-trading-btc-binance/internal/repository"
+)
+
+// transition moves tx to the given status through the lifecycle state machine.
+// Invalid transitions are logged and refused (tx is left untouched).
+func (s *Strategy) transition(tx *model.Transaction, to, reason string) bool {
+	if err := s.TransactionRepo.Transition(tx, to, reason); err != nil {
+		logger.Warn("⚠️ Refused transaction state change", "id", tx.ID, "from", tx.StatusTransaction, "to", to, "reason", reason, "error", err)
+		return false
+	}
+	return true
+}
+
+// beginLifecycle starts a freshly placed/imported order as NEW and moves it to status
+func (s *Strategy) beginLifecycle(tx *model.Transaction, status, reason string) {
+	if err := s.TransactionRepo.Begin(tx, status, reason); err != nil {
+		logger.Warn("⚠️ Refused transaction state change", "id", tx.ID, "to", status, "reason", reason, "error", err)
+	}
+}
+
+// terminalStatusFor returns how a removed order ends: CANCELED if it never executed, CLOSED otherwise
+func terminalStatusFor(tx model.Transaction) string {
+	if tx.StatusTransaction == model.StatusOpen || tx.StatusTransaction == model.StatusNew {
+		return model.StatusCanceled
+	}
+	return model.StatusClosed
+}
+
+// gridBuys selects the buys of the configured symbol in any of statuses (indexed query)
+func (s *Strategy) gridBuys(statuses ...string) repository.TransactionQuery {
+	return repository.TransactionQuery{Symbol: s.Cfg.Symbol, Type: "buy", Statuses: statuses}
+}
@@ -0,0 +1,56 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"grid-trading-btc-binance/internal/logger"
+)
+
+// macroGate keeps new grid entries out of the pause around the macro calendar events (false
+// while one is in force). The entries resume by themselves when it ends; the persisted pause
+// of /panic and /resume is not touched. Entering and leaving a pause is logged once.
+func (s *Strategy) macroGate() bool {
+	if s.Macro == nil {
+		return true
+	}
+	event, resume, active := s.Macro.Active(time.Now())
+	label := ""
+	if active {
+		label = event.Title + " " + event.At.Format(time.RFC3339)
+	}
+	if label != s.macroEvent {
+		if label == "" {
+			logger.Info("📅 Macro event pause ended, new grid entries resumed", "event", s.macroEvent)
+		} else {
+			logger.Warn("📅 Macro event pause: no new grid entries", "event", event.Title, "source", event.Source,
+				"at", event.At.Format(time.RFC3339), "resumes_at", resume.Format(time.RFC3339))
+		}
+		s.macroEvent = label
+	}
+	return !active
+}
+
+// MacroPauseReason is the macro event pausing the new entries now ("" = none), for the reports
+func (s *Strategy) MacroPauseReason() string {
+	if s.Macro == nil {
+		return ""
+	}
+	event, resume, active := s.Macro.Active(time.Now())
+	if !active {
+		return ""
+	}
+	return fmt.Sprintf("%s @ %s (until %s)", event.Title, event.At.UTC().Format("2006-01-02 15:04"), resume.UTC().Format("15:04"))
+}
+
+// macroText describes the macro calendar for /status
+func (s *Strategy) macroText() string {
+	now := time.Now()
+	if event, resume, active := s.Macro.Active(now); active {
+		return fmt.Sprintf("%s às %s (novas compras pausadas até %s)", event.Title, event.At.Local().Format("02/01 15:04"), resume.Local().Format("15:04"))
+	}
+	if event, ok := s.Macro.Next(now); ok {
+		return fmt.Sprintf("nenhum em curso, próximo: %s às %s", event.Title, event.At.Local().Format("02/01 15:04"))
+	}
+	return "nenhum evento agendado"
+}
@@ -0,0 +1,169 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"grid-trading-btc-binance/internal/audit"
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/model"
+	"grid-trading-btc-binance/internal/tracing"
+)
+
+// staleCancelRetryAfter spaces the retries of a stale buy whose cancel failed
+const staleCancelRetryAfter = time.Minute
+
+// expireStaleBuyOrders cancels buy orders older than MAX_BUY_ORDER_AGE_MIN that the market left
+// far behind (more than MAX_BUY_ORDER_DISTANCE_PCT below the price), freeing their capital.
+// Smart Entry only moves the highest order, so deeper levels could otherwise linger forever.
+func (s *Strategy) expireStaleBuyOrders(openOrders []model.Transaction, currentPrice float64) {
+	if s.Cfg.MaxBuyOrderAgeMin <= 0 || currentPrice <= 0 {
+		return
+	}
+	maxAge := time.Duration(s.Cfg.MaxBuyOrderAgeMin) * time.Minute
+
+	open := make(map[string]bool, len(openOrders))
+	for _, tx := range openOrders {
+		open[tx.ID] = true
+	}
+	for id := range s.staleCancelFailedAt {
+		if !open[id] {
+			delete(s.staleCancelFailedAt, id)
+		}
+	}
+
+	for _, tx := range openOrders {
+		if tx.Type != "buy" || tx.StatusTransaction != model.StatusOpen || time.Since(tx.CreatedAt) < maxAge {
+			continue
+		}
+		if time.Since(s.staleCancelFailedAt[tx.ID]) < staleCancelRetryAfter {
+			continue
+		}
+		price, _ := strconv.ParseFloat(tx.Price, 64)
+		if price <= 0 {
+			continue
+		}
+		distPct := (currentPrice - price) / price
+		if distPct < s.Cfg.MaxBuyOrderDistancePct {
+			continue
+		}
+
+		logger.Info("⏱️ Expiring stale buy order",
+			"id", tx.ID,
+			"price", price,
+			"currentPrice", currentPrice,
+			"distPct", fmt.Sprintf("%.2f%%", distPct*100),
+			"age", time.Since(tx.CreatedAt).Round(time.Minute).String(),
+		)
+		if s.cancelAndArchiveBuy(tx, "Expired (stale, far below price)") {
+			delete(s.staleCancelFailedAt, tx.ID)
+			continue
+		}
+		if s.staleCancelFailedAt == nil {
+			s.staleCancelFailedAt = make(map[string]time.Time)
+		}
+		s.staleCancelFailedAt[tx.ID] = time.Now()
+	}
+}
+
+// cancelAndArchiveBuy cancels an open buy on Binance, archives it and returns its capital
+// to the local USDT balance (the next account sync confirms it). A buy that was partially
+// filled keeps its filled part as a position with its own exit; only the unfilled part is
+// returned. Returns false if the cancel failed.
+func (s *Strategy) cancelAndArchiveBuy(tx model.Transaction, note string) bool {
+	if s.monitorOnly("cancel", "id", tx.ID, "note", note) {
+		return false
+	}
+	audit.Intent(tx.ID, tx.ID, "cancel_buy", audit.Fields{"reason": note, "price": tx.Price, "qty": tx.Amount})
+	resp, err := s.Binance.CancelOrder(s.Cfg.Symbol, tx.ID)
+	if err != nil {
+		// Often "Unknown Order" if it was already filled/canceled. The sync will reconcile it.
+		logger.Error("⚠️ Failed to cancel buy order", "id", tx.ID, "error", err)
+		return false
+	}
+
+	price, _ := strconv.ParseFloat(tx.Price, 64)
+	qty, _ := strconv.ParseFloat(tx.Amount, 64)
+	executed, _ := strconv.ParseFloat(resp.ExecutedQty, 64)
+	if executed > 0 {
+		spent, _ := strconv.ParseFloat(resp.CummulativeQuoteQty, 64)
+		s.updateBalance(s.Cfg.QuoteAsset, price*qty-spent)
+		s.updateBalance(s.Cfg.BaseAsset, executed)
+
+		tx.Amount = resp.ExecutedQty
+		if spent > 0 {
+			tx.Price = s.normalizer.FormatPrice(spent / executed)
+		}
+		if !s.transition(&tx, model.StatusFilled, note+": partially filled, rest canceled") {
+			return true
+		}
+		tx.Notes += fmt.Sprintf(" | %s (filled %s of %s)", note, resp.ExecutedQty, s.normalizer.FormatQty(qty))
+		s.TransactionRepo.Update(tx)
+		logger.Info("✂️ Partially filled buy canceled, filled part kept", "id", tx.ID, "filled", resp.ExecutedQty, "ordered", qty, "reason", note)
+		s.placeMakerExitOrder(tracing.FromNotes(tx.Notes), &tx)
+		return true
+	}
+	s.updateBalance(s.Cfg.QuoteAsset, price*qty)
+
+	s.transition(&tx, model.StatusCanceled, note)
+	tx.Notes += " | " + note
+
+	if err := s.TransactionRepo.Archive(tx); err != nil {
+		logger.Error("Failed to archive canceled buy order", "id", tx.ID, "error", err)
+	}
+	if err := s.TransactionRepo.Delete(tx.ID); err != nil {
+		logger.Error("Failed to delete canceled buy order", "id", tx.ID, "error", err)
+	} else {
+		logger.Info("🗑️ Buy order canceled and archived", "id", tx.ID, "reason", note)
+	}
+	return true
+}
+
+// SetRange changes the active price range at runtime, persists it to the runtime state and sweeps
+// the open buys that fell outside of it.
+func (s *Strategy) SetRange(rangeMin, rangeMax float64) (int, error) {
+	if s.Cfg.MonitorOnly {
+		return 0, errMonitorOnly
+	}
+	if rangeMin <= 0 || rangeMax <= rangeMin {
+		return 0, fmt.Errorf("invalid range: min %.2f, max %.2f", rangeMin, rangeMax)
+	}
+
+	oldMin, oldMax := s.Cfg.RangeMin, s.Cfg.RangeMax
+	s.Cfg.RangeMin = rangeMin
+	s.Cfg.RangeMax = rangeMax
+	logger.Info("📐 Range updated", "old_min", oldMin, "old_max", oldMax, "new_min", rangeMin, "new_max", rangeMax)
+
+	if err := s.StateRepo.SetRange(rangeMin, rangeMax); err != nil {
+		logger.Error("Failed to persist range to runtime state", "error", err)
+	}
+
+	return s.SweepOutOfRangeOrders(), nil
+}
+
+// SweepOutOfRangeOrders cancels open buys priced outside [RangeMin, RangeMax].
+// The grid never places outside the range, so these only exist after a range change.
+// The freed slots are re-deployed inside the range by the normal placement on the next ticks.
+func (s *Strategy) SweepOutOfRangeOrders() int {
+	canceled := 0
+	for _, tx := range s.TransactionRepo.GetAll() {
+		if tx.Symbol != s.Cfg.Symbol || tx.Type != "buy" || tx.StatusTransaction != model.StatusOpen {
+			continue
+		}
+		price, _ := strconv.ParseFloat(tx.Price, 64)
+		if price >= s.Cfg.RangeMin && price <= s.Cfg.RangeMax {
+			continue
+		}
+
+		logger.Warn("📐 Buy order outside the active range. Canceling...", "id", tx.ID, "price", price, "range_min", s.Cfg.RangeMin, "range_max", s.Cfg.RangeMax)
+		if s.cancelAndArchiveBuy(tx, fmt.Sprintf("Canceled (outside range %.2f-%.2f)", s.Cfg.RangeMin, s.Cfg.RangeMax)) {
+			canceled++
+		}
+	}
+
+	if canceled > 0 {
+		logger.Info("📐 Range sweep complete", "canceled", canceled)
+	}
+	return canceled
+}
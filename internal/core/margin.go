@@ -0,0 +1,154 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"grid-trading-btc-binance/internal/crash"
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/service"
+)
+
+const marginCheckInterval = time.Minute
+
+// marginState is the margin account as of the last check
+type marginState struct {
+	MarginLevel    float64
+	QuoteDebt      float64 // Borrowed + interest
+	BaseDebt       float64
+	Borrowable     float64 // Quote asset Binance still lends
+	LiquidatePrice float64 // Isolated only
+	Alert          string  // "" | warn | critical
+}
+
+// StartMarginMonitor reads the margin account every minute (ACCOUNT_TYPE cross/isolated): a
+// margin level below MARGIN_LEVEL_WARN or MARGIN_LEVEL_CRITICAL is alerted once per step, and at
+// the critical level the free quote asset repays the quote debt. Borrowing for new buys stops
+// while the level is below MARGIN_LEVEL_WARN.
+func (s *Strategy) StartMarginMonitor() {
+	if !s.Binance.Margin() {
+		return
+	}
+	crash.Go("margin monitor", func() {
+		logger.Info("🏦 Starting margin monitor", "account", s.Cfg.AccountType, "auto_borrow", s.Cfg.MarginAutoBorrow,
+			"max_borrow", s.Cfg.MarginMaxBorrow, "warn", s.Cfg.MarginLevelWarn, "critical", s.Cfg.MarginLevelCritical)
+		ticker := time.NewTicker(marginCheckInterval)
+		defer ticker.Stop()
+		for ; ; <-ticker.C {
+			if s.Binance.RateLimited() {
+				continue
+			}
+			s.checkMargin()
+		}
+	})
+}
+
+// checkMargin runs one pass of the margin monitor
+func (s *Strategy) checkMargin() {
+	account, err := s.Binance.GetMarginAccount()
+	if err != nil {
+		logger.Warn("⚠️ Margin check skipped: cannot read the margin account", "error", err)
+		return
+	}
+	state := marginState{MarginLevel: account.MarginLevel, LiquidatePrice: account.LiquidatePrice}
+	var freeQuote float64
+	for _, a := range account.Assets {
+		switch a.Asset {
+		case s.Cfg.QuoteAsset:
+			state.QuoteDebt = a.Debt()
+			freeQuote, _ = strconv.ParseFloat(a.Free, 64)
+		case s.Cfg.BaseAsset:
+			state.BaseDebt = a.Debt()
+		}
+	}
+	if s.Cfg.MarginAutoBorrow {
+		if state.Borrowable, err = s.Binance.MarginMaxBorrowable(s.Cfg.QuoteAsset); err != nil {
+			logger.Warn("⚠️ Cannot read the borrowable amount, borrowing paused", "asset", s.Cfg.QuoteAsset, "error", err)
+		}
+	}
+
+	// Without debt Binance reports a huge level (999): nothing to watch
+	switch {
+	case state.QuoteDebt+state.BaseDebt > 0 && state.MarginLevel < s.Cfg.MarginLevelCritical:
+		state.Alert = "critical"
+	case state.QuoteDebt+state.BaseDebt > 0 && state.MarginLevel < s.Cfg.MarginLevelWarn:
+		state.Alert = "warn"
+	}
+
+	s.marginMu.Lock()
+	previous := s.margin.Alert
+	s.margin = state
+	s.marginMu.Unlock()
+
+	data := service.MarginMessageData{
+		Level:          state.Alert,
+		MarginLevel:    state.MarginLevel,
+		Warn:           s.Cfg.MarginLevelWarn,
+		Critical:       s.Cfg.MarginLevelCritical,
+		Base:           s.Cfg.BaseAsset,
+		Quote:          s.Cfg.QuoteAsset,
+		QuoteDebt:      state.QuoteDebt,
+		BaseDebt:       state.BaseDebt,
+		LiquidatePrice: state.LiquidatePrice,
+	}
+	switch {
+	case state.Alert == "critical":
+		data.Repaid = s.repayMargin(freeQuote, state.QuoteDebt)
+		if previous == "critical" && data.Repaid == 0 {
+			return
+		}
+		logger.Error("🚨 Margin level critical", "margin_level", state.MarginLevel, "critical", s.Cfg.MarginLevelCritical,
+			"quote_debt", state.QuoteDebt, "base_debt", state.BaseDebt, "repaid", data.Repaid)
+		s.Notifier.NotifyTemplate(service.CategoryError, service.SeverityCritical, service.TemplateMarginLevelLow, data)
+	case state.Alert == "warn" && previous == "":
+		logger.Warn("⚠️ Margin level low", "margin_level", state.MarginLevel, "warn", s.Cfg.MarginLevelWarn,
+			"quote_debt", state.QuoteDebt, "base_debt", state.BaseDebt)
+		s.Notifier.NotifyTemplate(service.CategoryError, service.SeverityWarning, service.TemplateMarginLevelLow, data)
+	case state.Alert == "" && previous != "":
+		logger.Info("✅ Margin level restored", "margin_level", state.MarginLevel)
+		s.Notifier.NotifyTemplate(service.CategoryError, service.SeverityInfo, service.TemplateMarginLevelRestored, data)
+	}
+}
+
+// repayMargin repays the quote debt with the free quote asset and returns the amount repaid
+func (s *Strategy) repayMargin(freeQuote, debt float64) float64 {
+	amount := math.Floor(math.Min(freeQuote, debt)*100) / 100
+	if s.Cfg.MonitorOnly || amount <= 0 {
+		return 0
+	}
+	if err := s.Binance.MarginRepay(s.Cfg.QuoteAsset, strconv.FormatFloat(amount, 'f', 2, 64)); err != nil {
+		logger.Error("❌ Margin repay failed", "asset", s.Cfg.QuoteAsset, "amount", amount, "error", err)
+		return 0
+	}
+	s.updateBalance(s.Cfg.QuoteAsset, -amount)
+	logger.Warn("🔧 Margin debt repaid with the free balance", "asset", s.Cfg.QuoteAsset, "amount", amount)
+	return amount
+}
+
+// marginBorrowable returns the quote asset new buys may still borrow: up to MARGIN_MAX_BORROW
+// of debt and what Binance lends, nothing while the margin level is alerted
+func (s *Strategy) marginBorrowable() float64 {
+	if !s.Cfg.MarginAutoBorrow || !s.Binance.Margin() {
+		return 0
+	}
+	s.marginMu.Lock()
+	defer s.marginMu.Unlock()
+	if s.margin.Alert != "" {
+		return 0
+	}
+	return math.Max(0, math.Min(s.margin.Borrowable, s.Cfg.MarginMaxBorrow-s.margin.QuoteDebt))
+}
+
+// marginText describes the margin account for /status
+func (s *Strategy) marginText() string {
+	s.marginMu.Lock()
+	state := s.margin
+	s.marginMu.Unlock()
+	if state.QuoteDebt+state.BaseDebt == 0 {
+		return fmt.Sprintf("%s sem dívida", s.Cfg.AccountType)
+	}
+	return fmt.Sprintf("%s, nível %.2f, dívida %.2f %s + %s %s", s.Cfg.AccountType, state.MarginLevel,
+		state.QuoteDebt, s.Cfg.QuoteAsset, strconv.FormatFloat(state.BaseDebt, 'f', -1, 64), s.Cfg.BaseAsset)
+}
@@ -0,0 +1,26 @@
+package core
+
+import (
+	"time"
+
+	"grid-trading-btc-binance/internal/model"
+	"grid-trading-btc-binance/internal/service"
+)
+
+// recordTrade writes a closed round trip (buy tx sold at sellPrice) to the trade ledger and
+// the metrics sink (and the live side of the shadow comparison). source tells how it closed: grid (maker exit), recovery (exit found
+// filled on sync), dca (stack take-profit) or panic.
+func (s *Strategy) recordTrade(tx model.Transaction, sellPrice float64, soldAt time.Time, source string) {
+	if s.Ledger == nil {
+		return
+	}
+	record := s.Ledger.Add(tx, sellPrice, soldAt, source)
+	if s.Shadow != nil {
+		s.Shadow.RecordLive(soldAt, service.LedgerValue(record, "net_usdt"))
+	}
+
+	if s.Sink != nil {
+		tags := map[string]string{"symbol": tx.Symbol, "source": source}
+		s.Sink.Write(service.PointFromRecord("grid_trade", tags, service.TradeLedgerHeader, service.TradeLedgerTyped, record, soldAt))
+	}
+}
@@ -0,0 +1,20 @@
+package core
+
+import (
+	"errors"
+
+	"grid-trading-btc-binance/internal/logger"
+)
+
+// errMonitorOnly is returned by the commands that would trade while MONITOR_ONLY is set
+var errMonitorOnly = errors.New("monitor-only mode (MONITOR_ONLY): orders are never placed or canceled")
+
+// monitorOnly reports whether MONITOR_ONLY blocks an order action, logging what was skipped.
+// The API client refuses the calls as well; checking here keeps the local state untouched.
+func (s *Strategy) monitorOnly(action string, args ...any) bool {
+	if !s.Cfg.MonitorOnly {
+		return false
+	}
+	logger.Info("👁️ Monitor-only: "+action+" skipped", args...)
+	return true
+}
@@ -0,0 +1,100 @@
+package core
+
+import (
+	"time"
+
+	"grid-trading-btc-binance/internal/api"
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/model"
+)
+
+const (
+	orderQueryWeight = 4  // GET /api/v3/order
+	allOrdersWeight  = 20 // GET /api/v3/allOrders
+	allOrdersWindow  = 24 * time.Hour
+	allOrdersLimit   = 1000
+	orderClockMargin = time.Minute // The local creation time may lead or lag the exchange
+)
+
+// orderQuery is an order whose status a sync needs, with a time at or before its creation
+type orderQuery struct {
+	ID    string
+	Since time.Time
+}
+
+// entryQuery is the entry order of tx
+func entryQuery(tx model.Transaction) orderQuery {
+	return orderQuery{ID: tx.ID, Since: tx.CreatedAt}
+}
+
+// exitQuery is the exit order of tx: placed at SellCreatedAt, or at least after the entry
+func exitQuery(tx model.Transaction) orderQuery {
+	if !tx.SellCreatedAt.IsZero() {
+		return orderQuery{ID: tx.SellOrderID, Since: tx.SellCreatedAt}
+	}
+	return orderQuery{ID: tx.SellOrderID, Since: tx.CreatedAt}
+}
+
+// orderLookup answers the order status checks of a sync. When the orders of the symbol created
+// since the oldest query fit in fewer allOrders pages than the queries would cost as single
+// GetOrder calls (by weight), they are fetched up front, 24h windows at a time; the orders not
+// found there (other symbol, page error) are still queried one by one.
+type orderLookup struct {
+	binance *api.BinanceClient
+	symbol  string
+	orders  map[string]api.OrderResponse // By client order id
+}
+
+// newOrderLookup prefetches the orders of queries when batching is cheaper
+func (s *Strategy) newOrderLookup(queries []orderQuery) *orderLookup {
+	l := &orderLookup{binance: s.Binance, symbol: s.Cfg.Symbol, orders: make(map[string]api.OrderResponse)}
+	if len(queries) == 0 {
+		return l
+	}
+	from := queries[0].Since
+	for _, q := range queries[1:] {
+		if q.Since.Before(from) {
+			from = q.Since
+		}
+	}
+	now := time.Now()
+	from = from.Add(-orderClockMargin)
+	windows := int(now.Sub(from)/allOrdersWindow) + 1
+	if windows*allOrdersWeight >= len(queries)*orderQueryWeight {
+		return l
+	}
+
+	pages := 0
+	for start := from; start.Before(now); start = start.Add(allOrdersWindow) {
+		end := start.Add(allOrdersWindow - time.Millisecond)
+		if end.After(now) {
+			end = now
+		}
+		startMs := start.UnixMilli()
+		for {
+			orders, err := s.Binance.GetOrdersBetween(l.symbol, startMs, end.UnixMilli(), allOrdersLimit)
+			if err != nil {
+				logger.Warn("⚠️ Batch order lookup failed, querying the orders one by one", "from", start.Format(time.RFC3339), "error", err)
+				return l
+			}
+			pages++
+			for _, o := range orders {
+				l.orders[o.ClientOrderId] = o
+			}
+			if len(orders) < allOrdersLimit {
+				break
+			}
+			startMs = orders[len(orders)-1].Time + 1
+		}
+	}
+	logger.Info("📦 Batch order lookup", "queries", len(queries), "pages", pages, "orders", len(l.orders), "since", from.Format(time.RFC3339))
+	return l
+}
+
+// get returns the order clientOrderID of symbol, from the prefetched orders or Binance
+func (l *orderLookup) get(symbol, clientOrderID string) (*api.OrderResponse, error) {
+	if order, ok := l.orders[clientOrderID]; ok && symbol == l.symbol {
+		return &order, nil
+	}
+	return l.binance.GetOrder(symbol, clientOrderID)
+}
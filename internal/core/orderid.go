@@ -0,0 +1,154 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Grid client order IDs are G<version>_<kind>_L<level>_<key>, where key is the placement time
+// (UnixNano, base 36). An exit reuses the level and key of its buy, so the buy↔sell relationship
+// can be rebuilt from the Binance open orders alone:
+//
+//	G1_B_L3_1v8kz3x4q2abc   grid buy at level 3
+//	G1_S_L3_1v8kz3x4q2abc   its maker exit
+//	G1_T_L0_1v8kz3x9d7xyz   take-profit market sell (closes several buys, no parent)
+//	G1_F_L3_1v8kz3x4q2abc   fallback exit of the level 3 buy (market or IOC after failed maker exits)
+//
+// OrderIDVersion changes when the encoding or the meaning of a field changes; parsers keep
+// accepting the versions already on the book.
+const (
+	OrderIDVersion = 1
+
+	OrderKindBuy        = "B"
+	OrderKindExit       = "S"
+	OrderKindTakeProfit = "T"
+	OrderKindFallback   = "F"
+)
+
+// Legacy IDs (BUY_<ms>_L<n>, BUY_R_<ms>, SELL_<buyID>) are still parsed for orders placed
+// before the scheme existed
+const legacyExitPrefix = "SELL_"
+
+const maxClientOrderIDLen = 36 // Binance limit for newClientOrderId
+
+// OrderID is a parsed grid client order ID
+type OrderID struct {
+	Version int
+	Kind    string
+	Level   int
+	Key     string
+}
+
+func newOrderID(kind string, level int) OrderID {
+	return OrderID{
+		Version: OrderIDVersion,
+		Kind:    kind,
+		Level:   level,
+		Key:     strconv.FormatInt(time.Now().UnixNano(), 36),
+	}
+}
+
+func (id OrderID) String() string {
+	return fmt.Sprintf("G%d_%s_L%d_%s", id.Version, id.Kind, id.Level, id.Key)
+}
+
+// ParseOrderID decodes a grid client order ID. Legacy and foreign IDs return false.
+func ParseOrderID(clientOrderID string) (OrderID, bool) {
+	parts := strings.Split(clientOrderID, "_")
+	if len(parts) != 4 || len(parts[0]) < 2 || parts[0][0] != 'G' || !strings.HasPrefix(parts[2], "L") || parts[3] == "" {
+		return OrderID{}, false
+	}
+	version, err := strconv.Atoi(parts[0][1:])
+	if err != nil || version < 1 || version > OrderIDVersion {
+		return OrderID{}, false
+	}
+	level, err := strconv.Atoi(parts[2][1:])
+	if err != nil || level < 0 {
+		return OrderID{}, false
+	}
+	switch parts[1] {
+	case OrderKindBuy, OrderKindExit, OrderKindTakeProfit, OrderKindFallback:
+	default:
+		return OrderID{}, false
+	}
+	return OrderID{Version: version, Kind: parts[1], Level: level, Key: parts[3]}, true
+}
+
+// OrderLevel returns the grid level encoded in a buy or exit ID (legacy BUY_<ms>_L<n> included)
+func OrderLevel(clientOrderID string) (int, bool) {
+	if id, ok := ParseOrderID(clientOrderID); ok {
+		return id.Level, id.Kind != OrderKindTakeProfit
+	}
+	i := strings.LastIndex(clientOrderID, "_L")
+	if !strings.HasPrefix(clientOrderID, "BUY_") || i < 0 {
+		return 0, false
+	}
+	level, err := strconv.Atoi(clientOrderID[i+2:])
+	return level, err == nil
+}
+
+// newBuyOrderID returns the ID of a grid buy placed at level
+func newBuyOrderID(level int) string {
+	return newOrderID(OrderKindBuy, level).String()
+}
+
+// newTakeProfitOrderID returns the ID of a take-profit market sell
+func newTakeProfitOrderID() string {
+	return newOrderID(OrderKindTakeProfit, 0).String()
+}
+
+// exitOrderID returns the client order ID of the exit of buyID. Legacy buys get SELL_<buyID>;
+// foreign IDs too long for that fall back to a timestamp, relinked by SellOrderID or quantity.
+func exitOrderID(buyID string) string {
+	if id, ok := ParseOrderID(buyID); ok && id.Kind == OrderKindBuy {
+		id.Kind = OrderKindExit
+		return id.String()
+	}
+	if id := legacyExitPrefix + buyID; len(id) <= maxClientOrderIDLen {
+		return id
+	}
+	return fmt.Sprintf("%s%d", legacyExitPrefix, time.Now().UnixNano())
+}
+
+// fallbackExitOrderID returns the client order ID of the fallback exit of buyID. It is never
+// stored as the exit of the buy: the position is closed from the order response, so updates
+// for it do not resolve to a transaction.
+func fallbackExitOrderID(buyID string) string {
+	if id, ok := ParseOrderID(buyID); ok && id.Kind == OrderKindBuy {
+		id.Kind = OrderKindFallback
+		return id.String()
+	}
+	return newOrderID(OrderKindFallback, 0).String()
+}
+
+// exitBuyID returns the buy ID embedded in an exit's client order ID
+func exitBuyID(sellID string) (string, bool) {
+	if id, ok := ParseOrderID(sellID); ok {
+		if id.Kind != OrderKindExit {
+			return "", false
+		}
+		id.Kind = OrderKindBuy
+		return id.String(), true
+	}
+	buyID, ok := strings.CutPrefix(sellID, legacyExitPrefix)
+	if !ok || buyID == "" {
+		return "", false
+	}
+	if _, err := strconv.ParseInt(buyID, 10, 64); err == nil {
+		return "", false // Legacy SELL_<timestamp>: carries no buy ID
+	}
+	return buyID, true
+}
+
+// isUnstructuredExit reports whether sellID is a SELL_<timestamp> exit (legacy, or of a foreign
+// buy ID too long to embed): it names no buy and can only be relinked by quantity
+func isUnstructuredExit(sellID string) bool {
+	suffix, ok := strings.CutPrefix(sellID, legacyExitPrefix)
+	if !ok {
+		return false
+	}
+	_, err := strconv.ParseInt(suffix, 10, 64)
+	return err == nil
+}
@@ -0,0 +1,270 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"grid-trading-btc-binance/internal/api"
+	"grid-trading-btc-binance/internal/audit"
+	"grid-trading-btc-binance/internal/fees"
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/model"
+	"grid-trading-btc-binance/internal/service"
+)
+
+// PanicPlan is the dry-run of the kill switch: what would be canceled and sold
+type PanicPlan struct {
+	OpenOrders       int
+	TrackedTxs       int
+	InventoryQty     float64
+	CostBasis        float64
+	BidPrice         float64
+	ExpectedProceeds float64 // After taker fee
+}
+
+// ExpectedPnL is the expected realized result of the liquidation
+func (p PanicPlan) ExpectedPnL() float64 {
+	return p.ExpectedProceeds - p.CostBasis
+}
+
+// PanicResult summarizes an executed kill switch
+type PanicResult struct {
+	CanceledOrders int
+	FailedCancels  int
+	SoldQty        float64
+	AvgPrice       float64
+	Proceeds       float64
+	RealizedPnL    float64
+	Archived       int
+	Kept           int // Positions left active: not covered by the sell, or exit still on the book
+}
+
+// IsPaused reports whether trading is halted (set by /panic, cleared by /resume)
+func (s *Strategy) IsPaused() bool {
+	return s.StateRepo != nil && s.StateRepo.Get().Paused
+}
+
+// Pause halts all new orders until Resume is called (persisted across restarts)
+func (s *Strategy) Pause(reason string) {
+	if err := s.StateRepo.SetPaused(true, reason); err != nil {
+		logger.Error("Failed to persist paused state", "error", err)
+	}
+	logger.Warn("⏸️ Bot PAUSED", "reason", reason)
+}
+
+// Resume clears the paused state (and a drawdown stop's cool-off and pending sell)
+func (s *Strategy) Resume() {
+	if err := s.StateRepo.SetPaused(false, ""); err != nil {
+		logger.Error("Failed to persist paused state", "error", err)
+	}
+	if st := s.StateRepo.Get(); st.CooloffUntil != nil || st.DrawdownPending {
+		if err := s.StateRepo.SetDrawdown(time.Time{}, false); err != nil {
+			logger.Error("Failed to persist drawdown stop state", "error", err)
+		}
+	}
+	logger.Info("▶️ Bot RESUMED")
+}
+
+// trackedInventory returns the grid buys currently holding BTC and their total qty/cost
+func (s *Strategy) trackedInventory() ([]model.Transaction, float64, float64) {
+	var txs []model.Transaction
+	var qty, cost float64
+	for _, tx := range s.TransactionRepo.GetAll() {
+		if tx.Symbol != s.Cfg.Symbol || tx.Type != "buy" {
+			continue
+		}
+		if tx.StatusTransaction == model.StatusFilled || tx.StatusTransaction == model.StatusExitPlaced {
+			q, _ := strconv.ParseFloat(tx.Amount, 64)
+			p, _ := strconv.ParseFloat(tx.Price, 64)
+			qty += q
+			cost += q * p
+			txs = append(txs, tx)
+		}
+	}
+	return txs, qty, cost
+}
+
+// PreviewPanic computes the kill switch plan without touching any order
+func (s *Strategy) PreviewPanic() (*PanicPlan, error) {
+	openOrders, err := s.Binance.GetOpenOrders(s.Cfg.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch open orders: %w", err)
+	}
+	book, err := s.Binance.GetBookTicker(s.Cfg.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch book ticker: %w", err)
+	}
+	bid, _ := strconv.ParseFloat(book.BidPrice, 64)
+
+	tracked := 0
+	for _, tx := range s.TransactionRepo.GetAll() {
+		if tx.Symbol == s.Cfg.Symbol {
+			tracked++
+		}
+	}
+
+	_, qty, cost := s.trackedInventory()
+	return &PanicPlan{
+		OpenOrders:       len(openOrders),
+		TrackedTxs:       tracked,
+		InventoryQty:     qty,
+		CostBasis:        cost,
+		BidPrice:         bid,
+		ExpectedProceeds: qty * bid * (1 - fees.For(s.Cfg).Taker),
+	}, nil
+}
+
+// ExecutePanic is the kill switch: pause, cancel every open order, market-sell the tracked
+// inventory and archive every active transaction.
+func (s *Strategy) ExecutePanic(reason string) (*PanicResult, error) {
+	if s.Cfg.MonitorOnly {
+		return nil, errMonitorOnly
+	}
+	logger.Warn("🚨 PANIC: Kill switch triggered", "reason", reason)
+	s.Pause("panic: " + reason)
+
+	result := &PanicResult{}
+
+	// 1. Cancel ALL open orders for the symbol (grid, exits and rebalancing)
+	openOrders, err := s.Binance.GetOpenOrders(s.Cfg.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch open orders: %w", err)
+	}
+	stillLive := make(map[string]bool) // Orders whose cancel failed
+	for _, o := range openOrders {
+		audit.Intent(o.ClientOrderId, o.ClientOrderId, "panic_cancel", audit.Fields{"reason": reason})
+		if _, err := s.Binance.CancelOrder(s.Cfg.Symbol, o.ClientOrderId); err != nil {
+			logger.Error("❌ PANIC: Failed to cancel order", "id", o.ClientOrderId, "error", err)
+			result.FailedCancels++
+			stillLive[o.ClientOrderId] = true
+			continue
+		}
+		result.CanceledOrders++
+	}
+
+	// 2. Market-sell the held positions whose exit is off the book (limited to the BTC actually
+	// free after the cancels). A position whose exit could not be canceled keeps it.
+	var txs []model.Transaction
+	var trackedQty float64
+	for _, tx := range s.TransactionRepo.Find(s.gridBuys(model.StatusFilled, model.StatusFailed, model.StatusExitPlaced)) {
+		if tx.StatusTransaction == model.StatusExitPlaced {
+			if stillLive[tx.SellOrderID] {
+				continue
+			}
+			tx.SellOrderID = ""
+			tx.SellPrice = 0
+			s.transition(&tx, model.StatusFilled, "PANIC: exit canceled")
+			s.TransactionRepo.Update(tx)
+		}
+		q, _ := strconv.ParseFloat(tx.Amount, 64)
+		trackedQty += q
+		txs = append(txs, tx)
+	}
+	freeBTC := trackedQty
+	if info, err := s.Binance.GetAccountInfo(); err == nil {
+		for _, b := range info.Balances {
+			if b.Asset == s.Cfg.BaseAsset {
+				freeBTC, _ = strconv.ParseFloat(b.Free, 64)
+			}
+		}
+	} else {
+		logger.Warn("⚠️ PANIC: Cannot refresh balances, selling tracked qty", "error", err)
+	}
+
+	sellQty := s.normalizer.FloorQty(math.Min(trackedQty, freeBTC))
+	panicOrderID := fmt.Sprintf("PANIC_%d", time.Now().UnixMilli())
+	if sellQty > 0 {
+		closes := make([]string, len(txs))
+		for i, tx := range txs {
+			closes[i] = tx.ID
+		}
+		audit.Intent(panicOrderID, panicOrderID, "panic_sell", audit.Fields{"reason": reason, "qty": sellQty, "closes": closes})
+		resp, err := s.Binance.CreateOrder(api.OrderRequest{
+			Symbol:           s.Cfg.Symbol,
+			Side:             "SELL",
+			Type:             "MARKET",
+			Quantity:         s.normalizer.FormatQty(sellQty),
+			NewClientOrderID: panicOrderID,
+		})
+		if err != nil {
+			// Orders are canceled and the bot is paused: report and let the user act manually
+			return result, fmt.Errorf("market sell failed (orders canceled, bot paused): %w", err)
+		}
+		result.SoldQty, _ = strconv.ParseFloat(resp.ExecutedQty, 64)
+		result.Proceeds, _ = strconv.ParseFloat(resp.CummulativeQuoteQty, 64)
+		if result.SoldQty > 0 {
+			result.AvgPrice = result.Proceeds / result.SoldQty
+		}
+		logger.Warn("🚨 PANIC: Inventory liquidated", "qty", result.SoldQty, "avg_price", result.AvgPrice, "proceeds", result.Proceeds)
+
+		sellFee := 0.0
+		for _, fill := range resp.Fills {
+			commission, _ := strconv.ParseFloat(fill.Commission, 64)
+			sellFee += commission
+		}
+		s.updateBalance(s.Cfg.BaseAsset, -result.SoldQty)
+		s.updateBalance(s.Cfg.QuoteAsset, result.Proceeds)
+
+		// The sold quantity closes the positions in order; the last one may be closed in part
+		remaining := result.SoldQty
+		for i := range txs {
+			if remaining <= 0 {
+				break
+			}
+			tx := &txs[i]
+			q, _ := strconv.ParseFloat(tx.Amount, 64)
+			part := math.Min(q, remaining)
+			_, profit, full := s.closeSoldPart(tx, part, result.AvgPrice, sellFee*part/result.SoldQty, panicOrderID, "panic", "PANIC: liquidated")
+			if !full {
+				s.TransactionRepo.Update(*tx)
+			}
+			remaining -= part
+			result.RealizedPnL += profit
+			result.Archived++
+		}
+	}
+
+	// 3. Archive the orders that were canceled; held positions not sold stay active
+	now := time.Now()
+	for _, tx := range s.TransactionRepo.GetAll() {
+		if tx.Symbol != s.Cfg.Symbol {
+			continue
+		}
+		if (tx.StatusTransaction != model.StatusOpen && tx.StatusTransaction != model.StatusNew) || stillLive[tx.ID] {
+			result.Kept++
+			continue
+		}
+		s.transition(&tx, terminalStatusFor(tx), "PANIC: canceled")
+		tx.ClosedAt = &now
+		tx.Notes += " | PANIC: canceled"
+
+		if err := s.TransactionRepo.Archive(tx); err != nil {
+			logger.Error("⚠️ PANIC: Failed to archive transaction", "id", tx.ID, "error", err)
+			continue
+		}
+		if err := s.TransactionRepo.Delete(tx.ID); err != nil {
+			logger.Error("⚠️ PANIC: Failed to delete archived transaction", "id", tx.ID, "error", err)
+			continue
+		}
+		result.Archived++
+	}
+	if result.Kept > 0 {
+		logger.Warn("⚠️ PANIC: Positions left active (not sold or exit still on the book)", "kept", result.Kept)
+	}
+
+	s.Notifier.NotifyTemplate(service.CategoryError, service.SeverityCritical, service.TemplatePanicExecuted, service.PanicMessageData{
+		Base:           s.Cfg.BaseAsset,
+		Reason:         reason,
+		CanceledOrders: result.CanceledOrders,
+		FailedCancels:  result.FailedCancels,
+		SoldQty:        result.SoldQty,
+		AvgPrice:       result.AvgPrice,
+		Proceeds:       result.Proceeds,
+		RealizedPnL:    result.RealizedPnL,
+		Archived:       result.Archived,
+		Kept:           result.Kept,
+	})
+	return result, nil
+}
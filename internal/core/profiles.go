@@ -0,0 +1,235 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"grid-trading-btc-binance/internal/config"
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/service"
+)
+
+const cbEscalationFactor = 2.0 // widen multiplies the spacing by it, halve divides the position size by it
+
+// initProfiles snapshots the base values (the "default" profile) and applies the startup
+// profile: the one last switched at runtime, otherwise PROFILE
+func (s *Strategy) initProfiles() {
+	s.baseValues = s.Cfg.ProfileSnapshot()
+	s.activeProfile = config.DefaultProfile
+
+	name := s.StateRepo.Get().Profile
+	if name == "" {
+		name = s.Cfg.Profile
+	}
+	if name == "" || name == config.DefaultProfile {
+		return
+	}
+	if !s.Cfg.HasProfile(name) {
+		logger.Warn("⚠️ Saved profile is no longer in the config. Using the base values.", "profile", name)
+		return
+	}
+	if err := s.applyProfile(name); err != nil {
+		logger.Error("Failed to apply startup profile", "profile", name, "error", err)
+		return
+	}
+	logger.Info("🎛️ Profile active", "profile", name)
+}
+
+// ActiveProfile returns the name of the profile in use
+func (s *Strategy) ActiveProfile() string {
+	s.profileMu.Lock()
+	defer s.profileMu.Unlock()
+	return s.activeProfile
+}
+
+// SetProfile switches the parameter profile and persists the choice. Open orders are kept:
+// the new values apply to the next placements.
+func (s *Strategy) SetProfile(name, reason string) error {
+	name = strings.ToLower(name)
+	if !s.Cfg.HasProfile(name) {
+		return fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(s.Cfg.ProfileNames(), ", "))
+	}
+
+	previous := s.ActiveProfile()
+	if err := s.applyProfile(name); err != nil {
+		return err
+	}
+	logger.Info("🎛️ Profile switched", "from", previous, "to", name, "reason", reason)
+
+	if err := s.StateRepo.SetProfile(name); err != nil {
+		logger.Error("Failed to persist profile to runtime state", "error", err)
+	}
+	return nil
+}
+
+// applyProfile restores the base values and layers the profile on top, so switching
+// between profiles never leaves values from the previous one behind. A circuit breaker
+// escalation in force is layered last.
+func (s *Strategy) applyProfile(name string) error {
+	s.profileMu.Lock()
+	defer s.profileMu.Unlock()
+
+	if err := s.Cfg.ApplyValues(s.baseValues); err != nil {
+		return err
+	}
+	if name != config.DefaultProfile {
+		if err := s.Cfg.ApplyValues(s.Cfg.Profiles[name]); err != nil {
+			return fmt.Errorf("profile %s: %w", name, err)
+		}
+	}
+	switch s.cbEscalation {
+	case "widen":
+		s.Cfg.GridSpacingPct *= cbEscalationFactor
+		s.Cfg.LowVolMultiplier *= cbEscalationFactor
+		s.Cfg.HighVolMultiplier *= cbEscalationFactor
+	case "halve":
+		s.Cfg.PositionSizePct /= cbEscalationFactor
+	}
+	s.activeProfile = name
+	return nil
+}
+
+// countCircuitBreakerTrigger counts the circuit breaker triggers of the day and switches to
+// PROFILE_AUTO_SWITCH_TO once they reach PROFILE_AUTO_SWITCH_TRIGGERS. Switching back is manual.
+func (s *Strategy) countCircuitBreakerTrigger() {
+	today := time.Now().Format("2006-01-02")
+	if s.cbTriggerDay != today {
+		s.cbTriggerDay = today
+		s.cbTriggerCount = 0
+	}
+	s.cbTriggerCount++
+
+	target := s.Cfg.ProfileAutoSwitchTo
+	previous := s.ActiveProfile()
+	if target == "" || s.cbTriggerCount < s.Cfg.ProfileAutoSwitchTriggers || previous == target {
+		return
+	}
+
+	reason := fmt.Sprintf("circuit breaker triggered %d times today", s.cbTriggerCount)
+	if err := s.SetProfile(target, reason); err != nil {
+		logger.Error("Failed to auto-switch profile", "profile", target, "error", err)
+		return
+	}
+	s.Notifier.NotifyTemplate(service.CategoryCircuitBreaker, service.SeverityWarning, service.TemplateProfileAutoSwitched, service.ProfileMessageData{
+		Profile:  target,
+		Previous: previous,
+		Triggers: s.cbTriggerCount,
+	})
+}
+
+// initCircuitBreaker restores the circuit breaker pause and trigger counter from runtime state
+func (s *Strategy) initCircuitBreaker() {
+	state := s.StateRepo.Get()
+	if state.CircuitBreakerAt != nil {
+		s.circuitBreakerTriggeredAt = *state.CircuitBreakerAt
+		logger.Info("⏸️ Circuit breaker pause restored", "triggered_at", s.circuitBreakerTriggeredAt.Format(time.RFC3339))
+	}
+	s.cbTriggerDay = state.CircuitBreakerDay
+	s.cbTriggerCount = state.CircuitBreakerTriggers
+
+	s.cbTrips = state.CircuitBreakerTrips
+	if state.CircuitBreakerEscalation != "" && state.CircuitBreakerEscalatedAt != nil {
+		s.setCircuitBreakerEscalation(state.CircuitBreakerEscalation, *state.CircuitBreakerEscalatedAt)
+		logger.Info("🧯 Circuit breaker escalation restored", "escalation", state.CircuitBreakerEscalation, "since", state.CircuitBreakerEscalatedAt.Format(time.RFC3339))
+	}
+}
+
+// saveCircuitBreaker persists the circuit breaker pause and trigger counter
+func (s *Strategy) saveCircuitBreaker() {
+	if err := s.StateRepo.SetCircuitBreaker(s.circuitBreakerTriggeredAt, s.cbTriggerDay, s.cbTriggerCount); err != nil {
+		logger.Error("Failed to persist circuit breaker state", "error", err)
+	}
+}
+
+// recordCircuitBreakerTrip counts a trip within CB_TRIP_WINDOW_HOURS and escalates once there
+// are more than CB_MAX_TRIPS, so the grid does not come back at full size after every pause.
+// A trip while widen/halve is in force extends it instead.
+func (s *Strategy) recordCircuitBreakerTrip(now time.Time) {
+	if s.Cfg.CBMaxTrips <= 0 {
+		return
+	}
+	window := time.Duration(s.Cfg.CBTripWindowHours) * time.Hour
+	var trips []time.Time
+	for _, at := range s.cbTrips {
+		if now.Sub(at) < window {
+			trips = append(trips, at)
+		}
+	}
+	s.cbTrips = append(trips, now)
+
+	if escalation, _ := s.circuitBreakerEscalation(); escalation != "" {
+		s.setCircuitBreakerEscalation(escalation, now)
+	} else if len(s.cbTrips) > s.Cfg.CBMaxTrips {
+		s.escalateCircuitBreaker(len(s.cbTrips), now)
+		s.cbTrips = nil // A fresh count after /resume or once the escalation is lifted
+	}
+	s.saveCircuitBreakerEscalation()
+}
+
+// escalateCircuitBreaker applies CB_ESCALATION: widen or halve on top of the active profile
+// for CB_TRIP_WINDOW_HOURS, or stop (pause until /resume)
+func (s *Strategy) escalateCircuitBreaker(trips int, now time.Time) {
+	escalation := s.Cfg.CBEscalation
+	logger.Warn("🧯 Circuit breaker keeps tripping: escalating", "trips", trips, "window_hours", s.Cfg.CBTripWindowHours, "escalation", escalation)
+
+	data := service.CircuitBreakerEscalationMessageData{
+		Trips:       trips,
+		WindowHours: s.Cfg.CBTripWindowHours,
+		Escalation:  escalation,
+	}
+	if escalation == "stop" {
+		s.Pause(fmt.Sprintf("circuit breaker: %d trips in %dh", trips, s.Cfg.CBTripWindowHours))
+	} else {
+		s.setCircuitBreakerEscalation(escalation, now)
+		data.Until = now.Add(time.Duration(s.Cfg.CBTripWindowHours) * time.Hour).Format("02/01 15:04")
+	}
+	s.Notifier.NotifyTemplate(service.CategoryCircuitBreaker, service.SeverityCritical, service.TemplateCircuitBreakerEscalated, data)
+}
+
+// relaxCircuitBreakerEscalation lifts widen/halve once CB_TRIP_WINDOW_HOURS passed since the
+// last trip
+func (s *Strategy) relaxCircuitBreakerEscalation() {
+	escalation, at := s.circuitBreakerEscalation()
+	if escalation == "" || time.Since(at) < time.Duration(s.Cfg.CBTripWindowHours)*time.Hour {
+		return
+	}
+	logger.Info("✅ Circuit breaker escalation lifted", "escalation", escalation, "last_trip", at.Format(time.RFC3339))
+	s.setCircuitBreakerEscalation("", time.Time{})
+	s.saveCircuitBreakerEscalation()
+	s.Notifier.NotifyTemplate(service.CategoryCircuitBreaker, service.SeverityInfo, service.TemplateCircuitBreakerRelaxed, service.CircuitBreakerEscalationMessageData{
+		WindowHours: s.Cfg.CBTripWindowHours,
+		Escalation:  escalation,
+	})
+}
+
+// circuitBreakerEscalation returns the escalation in force ("" = none) and its last renewal
+func (s *Strategy) circuitBreakerEscalation() (string, time.Time) {
+	s.profileMu.Lock()
+	defer s.profileMu.Unlock()
+	return s.cbEscalation, s.cbEscalatedAt
+}
+
+// setCircuitBreakerEscalation puts escalation ("" = none) in force and re-layers the active profile
+func (s *Strategy) setCircuitBreakerEscalation(escalation string, at time.Time) {
+	s.profileMu.Lock()
+	changed := s.cbEscalation != escalation
+	s.cbEscalation = escalation
+	s.cbEscalatedAt = at
+	s.profileMu.Unlock()
+
+	if !changed {
+		return
+	}
+	if err := s.applyProfile(s.ActiveProfile()); err != nil {
+		logger.Error("Failed to apply circuit breaker escalation", "escalation", escalation, "error", err)
+	}
+}
+
+// saveCircuitBreakerEscalation persists the recent trips and the escalation in force
+func (s *Strategy) saveCircuitBreakerEscalation() {
+	escalation, at := s.circuitBreakerEscalation()
+	if err := s.StateRepo.SetCircuitBreakerEscalation(s.cbTrips, escalation, at); err != nil {
+		logger.Error("Failed to persist circuit breaker escalation", "error", err)
+	}
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"time"
+
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/market"
+	"grid-trading-btc-binance/internal/service"
+)
+
+// checkRangeSuggestion compares the range with the volatility suggestion (Bollinger band of the
+// daily closes, widened by the ATR) once a day and flags a stale one with range_stale; with
+// RANGE_AUTO_APPLY the suggestion replaces it, as a /range would
+func (s *Strategy) checkRangeSuggestion() {
+	if !s.Cfg.RangeCheck || s.Cfg.StrategyMode != "grid" {
+		return
+	}
+	day := time.Now().Format("2006-01-02")
+	if s.StateRepo.Get().RangeCheckDay >= day {
+		return
+	}
+
+	klines, err := s.Binance.GetRecentKlines(s.Cfg.Symbol, "1d", s.Cfg.RangeDays)
+	if err != nil {
+		logger.Warn("⚠️ Range check: Failed to fetch daily klines, retrying on the next sync", "error", err)
+		return
+	}
+	suggestion, err := market.SuggestRange(klines, s.Cfg.RangeStdDevs, s.Cfg.RangeATRs)
+	if err != nil {
+		logger.Warn("⚠️ Range check skipped", "error", err)
+		return
+	}
+	if err := s.StateRepo.SetRangeCheckDay(day); err != nil {
+		logger.Error("Failed to persist range check day", "error", err)
+	}
+
+	rangeMin, rangeMax := s.Cfg.RangeMin, s.Cfg.RangeMax
+	reason := suggestion.Stale(rangeMin, rangeMax)
+	if reason == "" {
+		logger.Info("📐 Range fits the volatility", "range_min", rangeMin, "range_max", rangeMax,
+			"suggested_min", suggestion.Min, "suggested_max", suggestion.Max)
+		return
+	}
+
+	data := service.RangeStaleMessageData{
+		Symbol:       s.Cfg.Symbol,
+		Reason:       reason,
+		Price:        suggestion.Price,
+		RangeMin:     rangeMin,
+		RangeMax:     rangeMax,
+		SuggestedMin: suggestion.Min,
+		SuggestedMax: suggestion.Max,
+		CoveragePct:  suggestion.Coverage(rangeMin, rangeMax) * 100,
+		WidthRatio:   (rangeMax - rangeMin) / (suggestion.Max - suggestion.Min),
+		Days:         suggestion.Days,
+		StdDevs:      s.Cfg.RangeStdDevs,
+		ATR:          suggestion.ATR,
+	}
+	logger.Warn("📐 Range is stale", "reason", reason, "price", suggestion.Price, "range_min", rangeMin, "range_max", rangeMax,
+		"suggested_min", suggestion.Min, "suggested_max", suggestion.Max, "auto_apply", s.Cfg.RangeAutoApply)
+
+	severity := service.SeverityInfo
+	if s.Cfg.RangeAutoApply && !s.Cfg.MonitorOnly {
+		severity = service.SeverityWarning
+		data.Canceled, err = s.SetRange(suggestion.Min, suggestion.Max)
+		if err != nil {
+			data.Error = err.Error()
+		} else {
+			data.Applied = true
+		}
+	}
+	s.Notifier.NotifyTemplate(service.CategoryReport, severity, service.TemplateRangeStale, data)
+}
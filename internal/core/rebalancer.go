@@ -0,0 +1,190 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"grid-trading-btc-binance/internal/api"
+	"grid-trading-btc-binance/internal/audit"
+	"grid-trading-btc-binance/internal/config"
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/precision"
+	"grid-trading-btc-binance/internal/repository"
+	"grid-trading-btc-binance/internal/service"
+)
+
+// RebalanceOrderPrefix marks rebalancing orders. They are not tracked as grid transactions,
+// so a rebalancing buy never gets a maker exit attached.
+const RebalanceOrderPrefix = "REB_"
+
+const (
+	rebalanceCheckInterval = 1 * time.Minute
+	rebalanceOrderTimeout  = 10 * time.Minute // Unfilled maker orders are canceled and re-evaluated
+)
+
+// Rebalancer keeps the BTC/USDT equity ratio around REBALANCE_TARGET_RATIO, placing maker
+// orders whenever the inventory ratio drifts outside the band, independently of grid spacing.
+type Rebalancer struct {
+	Cfg             *config.Config
+	BalanceRepo     *repository.BalanceRepository
+	TransactionRepo *repository.TransactionRepository
+	Binance         *api.BinanceClient
+	Normalizer      *precision.Normalizer
+	Parked          func() float64 // USDT held outside the wallet (Simple Earn), counted in the equity (nil = none)
+
+	lastCheck     time.Time
+	lastPlacement time.Time
+	activeOrderID string
+	activeSince   time.Time
+}
+
+func NewRebalancer(cfg *config.Config, balanceRepo *repository.BalanceRepository, transactionRepo *repository.TransactionRepository, binanceClient *api.BinanceClient, normalizer *precision.Normalizer) *Rebalancer {
+	return &Rebalancer{
+		Cfg:             cfg,
+		BalanceRepo:     balanceRepo,
+		TransactionRepo: transactionRepo,
+		Binance:         binanceClient,
+		Normalizer:      normalizer,
+	}
+}
+
+// Check evaluates the inventory ratio and places/maintains a single rebalancing order.
+// deployableUSDT is the USDT the strategy may spend (reserve and vault already excluded),
+// owedBase the free BTC held for positions (filled buys waiting for their exit, the DCA stack).
+func (r *Rebalancer) Check(bid, ask, deployableUSDT, owedBase float64) {
+	if !r.Cfg.RebalanceEnabled || bid <= 0 || ask <= 0 {
+		return
+	}
+	if time.Since(r.lastCheck) < rebalanceCheckInterval {
+		return
+	}
+	r.lastCheck = time.Now()
+
+	// 1. Follow up on the order in flight (one at a time)
+	if r.activeOrderID != "" {
+		if !r.resolveActiveOrder() {
+			return
+		}
+	}
+
+	if time.Since(r.lastPlacement) < time.Duration(r.Cfg.RebalanceCooldownMin)*time.Minute {
+		return
+	}
+
+	// 2. Measure drift
+	mid := (bid + ask) / 2
+	inv := service.ComputeInventory(r.Cfg, r.BalanceRepo, r.TransactionRepo, mid)
+	if r.Parked != nil {
+		inv = inv.WithParked(r.Parked())
+	}
+	if inv.Equity <= 0 {
+		return
+	}
+
+	drift := inv.InventoryRatio - r.Cfg.RebalanceTargetRatio
+	if math.Abs(drift) <= r.Cfg.RebalanceBand {
+		return
+	}
+
+	value := math.Min(math.Abs(drift)*inv.Equity, r.Cfg.RebalanceMaxOrderUSDT)
+
+	var side, price string
+	var qty float64
+	if drift < 0 {
+		// Too much USDT: buy BTC at the bid
+		value = math.Min(value, deployableUSDT)
+		if value < r.Normalizer.MinNotional() {
+			logger.Debug("⚖️ Rebalance skipped: not enough deployable USDT", "deployable", deployableUSDT)
+			return
+		}
+		side = "BUY"
+		price = r.Normalizer.BuyPrice(bid)
+		qty = r.Normalizer.CeilQty(value / bid)
+	} else {
+		// Too much BTC: sell free BTC at the ask (grid inventory stays locked in its exits, and
+		// the free BTC owed to positions waiting for an exit or to the DCA stack is left alone)
+		spare := math.Max(inv.BalanceBTC-owedBase, 0)
+		qty = r.Normalizer.FloorQty(math.Min(value/ask, spare))
+		if qty*ask < r.Normalizer.MinNotional() {
+			logger.Debug("⚖️ Rebalance skipped: not enough free BTC", "free_btc", inv.BalanceBTC, "owed", owedBase)
+			return
+		}
+		side = "SELL"
+		price = r.Normalizer.SellPrice(ask)
+	}
+
+	clientOrderID := fmt.Sprintf("%s%s_%d", RebalanceOrderPrefix, side, time.Now().UnixMilli())
+	logger.Info("⚖️ Inventory ratio out of band. Rebalancing...",
+		"ratio", fmt.Sprintf("%.4f", inv.InventoryRatio),
+		"target", r.Cfg.RebalanceTargetRatio,
+		"band", r.Cfg.RebalanceBand,
+		"side", side,
+		"qty", fmt.Sprintf("%.5f", qty),
+		"price", price,
+	)
+
+	audit.Intent(clientOrderID, clientOrderID, "rebalance", audit.Fields{
+		"ratio":  inv.InventoryRatio,
+		"target": r.Cfg.RebalanceTargetRatio,
+		"side":   side,
+		"qty":    r.Normalizer.FormatQty(qty),
+		"price":  price,
+	})
+	resp, err := r.Binance.CreateOrder(api.OrderRequest{
+		Symbol:           r.Cfg.Symbol,
+		Side:             side,
+		Type:             "LIMIT_MAKER",
+		Quantity:         r.Normalizer.FormatQty(qty),
+		Price:            price,
+		NewClientOrderID: clientOrderID,
+	})
+	r.lastPlacement = time.Now()
+	if err != nil {
+		logger.Error("❌ Failed to place rebalance order", "error", err)
+		return
+	}
+	if resp.Status == "EXPIRED" || resp.Status == "CANCELED" {
+		logger.Warn("⚠️ Rebalance maker order rejected (would take)", "status", resp.Status)
+		return
+	}
+
+	r.activeOrderID = clientOrderID
+	r.activeSince = time.Now()
+	logger.Info("✅ Rebalance order placed", "id", clientOrderID, "status", resp.Status)
+}
+
+// resolveActiveOrder checks the order in flight. Returns true when the slot is free again.
+func (r *Rebalancer) resolveActiveOrder() bool {
+	resp, err := r.Binance.GetOrder(r.Cfg.Symbol, r.activeOrderID)
+	if err != nil {
+		logger.Warn("⚠️ Cannot check rebalance order", "id", r.activeOrderID, "error", err)
+		return false
+	}
+
+	switch resp.Status {
+	case "NEW", "PARTIALLY_FILLED":
+		if time.Since(r.activeSince) < rebalanceOrderTimeout {
+			return false
+		}
+		audit.Intent(r.activeOrderID, r.activeOrderID, "cancel_rebalance", audit.Fields{"reason": "timeout", "executed": resp.ExecutedQty})
+		if _, err := r.Binance.CancelOrder(r.Cfg.Symbol, r.activeOrderID); err != nil {
+			logger.Warn("⚠️ Failed to cancel stale rebalance order", "id", r.activeOrderID, "error", err)
+			return false
+		}
+		logger.Info("⏱️ Stale rebalance order canceled", "id", r.activeOrderID, "executed", resp.ExecutedQty)
+	case "FILLED":
+		logger.Info("⚖️ Rebalance order filled", "id", r.activeOrderID, "qty", resp.ExecutedQty, "price", resp.Price)
+	default:
+		logger.Info("⚖️ Rebalance order closed", "id", r.activeOrderID, "status", resp.Status)
+	}
+
+	r.activeOrderID = ""
+	return true
+}
+
+// IsRebalanceOrder reports whether a client order id belongs to the rebalancer
+func IsRebalanceOrder(clientOrderID string) bool {
+	return strings.HasPrefix(clientOrderID, RebalanceOrderPrefix)
+}
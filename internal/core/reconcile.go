@@ -0,0 +1,377 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"grid-trading-btc-binance/internal/api"
+	"grid-trading-btc-binance/internal/crash"
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/model"
+)
+
+const (
+	reconcileTradesLimit = 1000
+	reconcileFirstWindow = 24 * time.Hour // Binance only accepts a 24h startTime window
+)
+
+// tradeFill aggregates the account trades of a single order
+type tradeFill struct {
+	OrderID       int64
+	ClientOrderID string
+	IsBuyer       bool
+	Qty           float64
+	Quote         float64
+	Commission    float64
+	FirstTime     int64
+	LastTime      int64
+	FirstTradeID  int64
+}
+
+// add aggregates one trade of the order
+func (f *tradeFill) add(t api.AccountTrade) {
+	qty, _ := strconv.ParseFloat(t.Qty, 64)
+	quote, _ := strconv.ParseFloat(t.QuoteQty, 64)
+	comm, _ := strconv.ParseFloat(t.Commission, 64)
+	f.Qty += qty
+	f.Quote += quote
+	f.Commission += comm
+	if f.FirstTime == 0 || t.Time < f.FirstTime {
+		f.FirstTime = t.Time
+	}
+	if t.Time > f.LastTime {
+		f.LastTime = t.Time
+	}
+	if f.FirstTradeID == 0 || t.ID < f.FirstTradeID {
+		f.FirstTradeID = t.ID
+	}
+}
+
+func (f *tradeFill) AvgPrice() float64 {
+	if f.Qty <= 0 {
+		return 0
+	}
+	return f.Quote / f.Qty
+}
+
+// ReconcileTrades matches local transactions against the account trades returned by
+// GET /api/v3/myTrades, fixing quantities, prices and fees, and imports the trades of
+// unknown orders (e.g. placed manually in the app) into the archive as "manual".
+func (s *Strategy) ReconcileTrades() {
+	fills, lastTradeID, err := s.fetchTradeFills()
+	if err != nil {
+		logger.Error("❌ Trade reconciliation failed", "error", err)
+		return
+	}
+	if len(fills) == 0 {
+		return
+	}
+
+	byClientID := make(map[string]*tradeFill, len(fills))
+	for _, f := range fills {
+		byClientID[f.ClientOrderID] = f
+	}
+	known := make(map[string]bool)
+	for _, lot := range s.DCARepo.Get().Lots {
+		known[lot.OrderID] = true // Open DCA lots are archived when the stack is sold
+	}
+
+	// 1. Active transactions
+	fixedActive := 0
+	for _, tx := range s.TransactionRepo.GetAll() {
+		markKnown(known, tx)
+		if s.applyFills(&tx, byClientID) {
+			if err := s.TransactionRepo.Update(tx); err != nil {
+				logger.Error("Failed to update reconciled transaction", "id", tx.ID, "error", err)
+				continue
+			}
+			fixedActive++
+		}
+	}
+
+	// 2. Archive (fix + import unknown)
+	fixedArchived, imported := 0, 0
+	err = s.TransactionRepo.UpdateHistory(func(history []model.Transaction) ([]model.Transaction, bool) {
+		for i := range history {
+			markKnown(known, history[i])
+			if s.applyFills(&history[i], byClientID) {
+				fixedArchived++
+			}
+		}
+		for _, f := range fills {
+			if f.ClientOrderID != "" && known[f.ClientOrderID] {
+				continue
+			}
+			history = append(history, s.importedTransaction(f))
+			imported++
+		}
+		return history, fixedArchived > 0 || imported > 0
+	})
+	if err != nil {
+		logger.Error("❌ Failed to update transaction history during reconciliation", "error", err)
+		return // Keep the watermark so the next run retries
+	}
+
+	if err := s.StateRepo.SetLastReconciledTradeID(lastTradeID); err != nil {
+		logger.Error("Failed to persist trade reconciliation watermark", "error", err)
+	}
+
+	logger.Info("🔎 Trade reconciliation completed",
+		"orders", len(fills),
+		"fixed_active", fixedActive,
+		"fixed_archived", fixedArchived,
+		"imported", imported,
+		"last_trade_id", lastTradeID,
+	)
+}
+
+// fetchTradeFills pages through myTrades from the watermark and groups trades per order,
+// resolving each orderId to its clientOrderId.
+func (s *Strategy) fetchTradeFills() ([]*tradeFill, int64, error) {
+	lastTradeID := s.StateRepo.Get().LastReconciledTradeID
+	fromID := int64(0)
+	startTime := int64(0)
+	if lastTradeID > 0 {
+		fromID = lastTradeID + 1
+	} else {
+		startTime = time.Now().Add(-reconcileFirstWindow).UnixMilli()
+	}
+
+	byOrder := make(map[int64]*tradeFill)
+	for {
+		trades, err := s.Binance.GetMyTrades(s.Cfg.Symbol, fromID, startTime, reconcileTradesLimit)
+		if err != nil {
+			return nil, lastTradeID, fmt.Errorf("myTrades: %w", err)
+		}
+		for _, t := range trades {
+			f, ok := byOrder[t.OrderID]
+			if !ok {
+				f = &tradeFill{OrderID: t.OrderID, IsBuyer: t.IsBuyer}
+				byOrder[t.OrderID] = f
+			}
+			f.add(t)
+			if t.ID > lastTradeID {
+				lastTradeID = t.ID
+			}
+		}
+		if len(trades) < reconcileTradesLimit {
+			break
+		}
+		fromID = lastTradeID + 1
+		startTime = 0
+	}
+
+	if len(byOrder) == 0 {
+		return nil, lastTradeID, nil
+	}
+
+	// Resolve clientOrderIds: trades only carry the numeric orderId
+	fills := make([]*tradeFill, 0, len(byOrder))
+	minOrderID := int64(math.MaxInt64)
+	for _, f := range byOrder {
+		fills = append(fills, f)
+		if f.OrderID < minOrderID {
+			minOrderID = f.OrderID
+		}
+	}
+	sort.Slice(fills, func(i, j int) bool { return fills[i].OrderID < fills[j].OrderID })
+
+	orderState := make(map[int64]api.OrderResponse, len(fills))
+	fromOrderID := minOrderID
+	for {
+		orders, err := s.Binance.GetAllOrders(s.Cfg.Symbol, fromOrderID, reconcileTradesLimit)
+		if err != nil {
+			return nil, s.StateRepo.Get().LastReconciledTradeID, fmt.Errorf("allOrders: %w", err)
+		}
+		for _, o := range orders {
+			if f, ok := byOrder[o.OrderId]; ok {
+				f.ClientOrderID = o.ClientOrderId
+				orderState[o.OrderId] = o
+			}
+			if o.OrderId >= fromOrderID {
+				fromOrderID = o.OrderId + 1
+			}
+		}
+		if len(orders) < reconcileTradesLimit || fromOrderID > fills[len(fills)-1].OrderID {
+			break
+		}
+	}
+
+	// Only orders done trading are applied, with all their trades: an order still working holds
+	// the watermark before its first trade (the next run sees it whole), and an order with
+	// trades before the watermark or the first window is completed by its orderId
+	complete := fills[:0]
+	heldFrom := int64(0)
+	for _, f := range fills {
+		o, ok := orderState[f.OrderID]
+		if ok && !isFinalOrderStatus(o.Status) {
+			if heldFrom == 0 || f.FirstTradeID < heldFrom {
+				heldFrom = f.FirstTradeID
+			}
+			continue
+		}
+		if executed, _ := strconv.ParseFloat(o.ExecutedQty, 64); ok && executed-f.Qty > 1e-12 {
+			trades, err := s.Binance.GetOrderTrades(s.Cfg.Symbol, f.OrderID)
+			if err != nil {
+				return nil, s.StateRepo.Get().LastReconciledTradeID, fmt.Errorf("myTrades orderId %d: %w", f.OrderID, err)
+			}
+			whole := &tradeFill{OrderID: f.OrderID, ClientOrderID: f.ClientOrderID, IsBuyer: f.IsBuyer}
+			for _, t := range trades {
+				whole.add(t)
+			}
+			f = whole
+		}
+		complete = append(complete, f)
+	}
+	if heldFrom > 0 && heldFrom-1 < lastTradeID {
+		logger.Info("🔎 Reconcile: orders still working, watermark held before their trades", "held_from_trade_id", heldFrom)
+		lastTradeID = heldFrom - 1
+	}
+
+	return complete, lastTradeID, nil
+}
+
+// applyFills corrects the transaction with the executions of its buy and sell orders.
+// Returns true if anything changed.
+func (s *Strategy) applyFills(tx *model.Transaction, byClientID map[string]*tradeFill) bool {
+	if tx.StatusTransaction == model.StatusNew || tx.StatusTransaction == model.StatusOpen || tx.StatusTransaction == model.StatusCanceled || tx.StatusTransaction == model.StatusFailed {
+		return false // Nothing executed yet; the order sync handles these
+	}
+
+	entry := byClientID[tx.ID]
+	var exit *tradeFill
+	if tx.SellOrderID != "" {
+		exit = byClientID[tx.SellOrderID]
+	}
+	if entry == nil && exit == nil {
+		return false
+	}
+
+	changed := false
+	if entry != nil && entry.Qty > 0 {
+		amount := fmt.Sprintf("%.8f", entry.Qty)
+		price := fmt.Sprintf("%.8f", entry.AvgPrice())
+		if !sameFloat(tx.Amount, amount) || !sameFloat(tx.Price, price) {
+			logger.Info("🔧 Reconcile: fixing entry",
+				"id", tx.ID,
+				"amount", tx.Amount+" -> "+amount,
+				"price", tx.Price+" -> "+price,
+			)
+			tx.Amount = amount
+			tx.Price = price
+			changed = true
+		}
+	}
+	if exit != nil && exit.Qty > 0 {
+		sellPrice := exit.AvgPrice()
+		if math.Abs(tx.SellPrice-sellPrice) > 1e-8 {
+			logger.Info("🔧 Reconcile: fixing exit price", "id", tx.ID, "sellPrice", fmt.Sprintf("%.2f -> %.2f", tx.SellPrice, sellPrice))
+			tx.SellPrice = sellPrice
+			changed = true
+		}
+	}
+
+	// Fee is the sum of both legs; only recompute when every executed leg is visible
+	exitExecuted := tx.StatusTransaction == model.StatusClosed && tx.SellOrderID != ""
+	if entry != nil && (!exitExecuted || exit != nil) {
+		fee := entry.Commission
+		if exit != nil {
+			fee += exit.Commission
+		}
+		feeStr := fmt.Sprintf("%.8f", fee)
+		if !sameFloat(tx.Fee, feeStr) {
+			logger.Info("🔧 Reconcile: fixing fee", "id", tx.ID, "fee", tx.Fee+" -> "+feeStr)
+			tx.Fee = feeStr
+			changed = true
+		}
+	}
+
+	if changed {
+		tx.UpdatedAt = time.Now()
+		if !strings.Contains(tx.Notes, "Reconciled via myTrades") {
+			tx.Notes += " | Reconciled via myTrades"
+		}
+	}
+	return changed
+}
+
+// importedTransaction builds an archived record for an order the bot does not know about
+func (s *Strategy) importedTransaction(f *tradeFill) model.Transaction {
+	side := "sell"
+	if f.IsBuyer {
+		side = "buy"
+	}
+	id := f.ClientOrderID
+	if id == "" {
+		id = strconv.FormatInt(f.OrderID, 10)
+	}
+	source := "manual"
+	if oid, ok := ParseOrderID(id); ok && oid.Kind == OrderKindTakeProfit {
+		source = "take_profit" // Closes several buys that are cleared, not archived with it
+	} else if IsRebalanceOrder(id) {
+		source = "rebalance"
+	} else if IsDCAOrder(id) {
+		source = "dca"
+	}
+
+	created := time.UnixMilli(f.FirstTime)
+	closed := time.UnixMilli(f.LastTime)
+	logger.Info("📥 Reconcile: importing unknown order", "id", id, "side", side, "qty", f.Qty, "price", f.AvgPrice(), "source", source)
+
+	return model.Transaction{
+		ID:                id,
+		TransactionID:     strconv.FormatInt(f.OrderID, 10),
+		Symbol:            s.Cfg.Symbol,
+		Type:              side,
+		Amount:            fmt.Sprintf("%.8f", f.Qty),
+		Price:             fmt.Sprintf("%.8f", f.AvgPrice()),
+		Fee:               fmt.Sprintf("%.8f", f.Commission),
+		StatusTransaction: model.StatusClosed,
+		Notes:             fmt.Sprintf("Imported from myTrades (%s)", source),
+		ClosedAt:          &closed,
+		CreatedAt:         created,
+		UpdatedAt:         time.Now(),
+	}
+}
+
+// StartTradeReconciliation runs ReconcileTrades and SyncCapitalFlows every TRADE_RECONCILE_INTERVAL_MIN
+func (s *Strategy) StartTradeReconciliation() {
+	if s.Cfg.TradeReconcileIntervalMin <= 0 {
+		return
+	}
+	crash.Go("trade reconciliation", func() {
+		interval := time.Duration(s.Cfg.TradeReconcileIntervalMin) * time.Minute
+		logger.Info("⏰ Starting Trade Reconciliation", "interval", interval.String())
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if s.Binance.RateLimited() {
+				continue // Next round: the trades stay in the history
+			}
+			s.ReconcileTrades()
+			s.SyncCapitalFlows()
+		}
+	})
+}
+
+func markKnown(known map[string]bool, tx model.Transaction) {
+	known[tx.ID] = true
+	if tx.SellOrderID != "" {
+		known[tx.SellOrderID] = true
+	}
+}
+
+// sameFloat compares two decimal strings numerically ("0.00010" == "0.0001")
+func sameFloat(a, b string) bool {
+	x, errA := strconv.ParseFloat(a, 64)
+	y, errB := strconv.ParseFloat(b, 64)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return math.Abs(x-y) <= 1e-8*math.Max(1, math.Abs(y))
+}
@@ -0,0 +1,132 @@
+package core
+
+import (
+	"strconv"
+
+	"grid-trading-btc-binance/internal/api"
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/model"
+	"grid-trading-btc-binance/internal/service"
+)
+
+// ResyncMissedEvents runs right after the user stream reconnects. Orders that left the book
+// during the gap are queried via REST and replayed through the update workers, so fills are
+// processed within seconds instead of waiting for the 5-minute periodic sync.
+func (s *Strategy) ResyncMissedEvents() {
+	openOrders, err := s.Binance.GetOpenOrders(s.Cfg.Symbol)
+	if err != nil {
+		logger.Error("❌ Resync: Failed to fetch open orders", "error", err)
+		return
+	}
+	onBook := make(map[string]bool, len(openOrders))
+	for _, o := range openOrders {
+		onBook[o.ClientOrderId] = true
+	}
+
+	var queries []orderQuery
+	for _, tx := range s.TransactionRepo.GetAll() {
+		if tx.Symbol != s.Cfg.Symbol {
+			continue
+		}
+
+		// The order whose outcome we may have missed: the entry while open, the exit afterwards
+		var q orderQuery
+		switch {
+		case tx.StatusTransaction == model.StatusOpen:
+			q = entryQuery(tx)
+		case tx.StatusTransaction == model.StatusExitPlaced && tx.SellOrderID != "":
+			q = exitQuery(tx)
+		default:
+			continue
+		}
+		if !onBook[q.ID] {
+			queries = append(queries, q)
+		}
+	}
+
+	// Many levels may have moved during a long gap: a few allOrders pages instead of one call each
+	lookup := s.newOrderLookup(queries)
+	replayed := 0
+	for _, q := range queries {
+		resp, err := lookup.get(s.Cfg.Symbol, q.ID)
+		if err != nil {
+			logger.Warn("⚠️ Resync: Failed to query order (periodic sync will retry)", "id", q.ID, "error", err)
+			continue
+		}
+		if !isFinalOrderStatus(resp.Status) {
+			continue // Still working (e.g. PARTIALLY_FILLED between snapshots)
+		}
+
+		logger.Info("🔁 Resync: Replaying missed order update", "id", q.ID, "status", resp.Status)
+		s.dispatchUpdate(s.replayEvent(resp))
+		replayed++
+	}
+
+	logger.Info("✅ Resync after reconnect completed", "open_on_binance", len(openOrders), "replayed", replayed)
+}
+
+// dispatchUpdate hands a replayed update to the worker of its transaction, behind the live
+// events already queued for it
+func (s *Strategy) dispatchUpdate(event service.OrderUpdate) {
+	if s.Updates == nil {
+		s.HandleOrderUpdate(event)
+		return
+	}
+	s.Updates.Dispatch(event)
+}
+
+// isFinalOrderStatus reports whether an order left the book for good
+func isFinalOrderStatus(status string) bool {
+	switch status {
+	case "FILLED", "CANCELED", "EXPIRED", "REJECTED", "EXPIRED_IN_MATCH":
+		return true
+	}
+	return false
+}
+
+// replayEvent builds an executionReport-like update from the REST order state.
+// TradeID 0 and the RESYNC execution type keep its dedup key apart from live events.
+func (s *Strategy) replayEvent(resp *api.OrderResponse) service.OrderUpdate {
+	execType := "TRADE"
+	if resp.Status != "FILLED" {
+		execType = resp.Status
+	}
+
+	price := resp.Price
+	executed, _ := strconv.ParseFloat(resp.ExecutedQty, 64)
+	quote, _ := strconv.ParseFloat(resp.CummulativeQuoteQty, 64)
+	if executed > 0 && quote > 0 {
+		price = s.normalizer.FormatPrice(quote / executed) // Average fill price
+	}
+
+	return service.OrderUpdate{
+		Event:         "executionReport",
+		Symbol:        resp.Symbol,
+		ClientOrderID: resp.ClientOrderId,
+		Side:          resp.Side,
+		Type:          resp.Type,
+		ExecutionType: "RESYNC_" + execType,
+		Status:        resp.Status,
+		OrderID:       resp.OrderId,
+		LastExecQty:   resp.ExecutedQty,
+		CumExecQty:    resp.ExecutedQty,
+		LastExecPrice: price,
+		CumQuoteQty:   resp.CummulativeQuoteQty,
+	}
+}
+
+// UpdateKey groups an order update with the transaction it belongs to: exit updates map to
+// the owning buy, so the update workers process a transaction's events in sequence
+func (s *Strategy) UpdateKey(event service.OrderUpdate) string {
+	if tx, ok := s.TransactionRepo.GetBySellID(event.ClientOrderID); ok {
+		return tx.ID
+	}
+	return event.ClientOrderID
+}
+
+// HandleUpdatesOverflow alerts and recovers the order updates dropped from a saturated queue
+func (s *Strategy) HandleUpdatesOverflow(dropped uint64, capacity int) {
+	s.Notifier.NotifyTemplate(service.CategoryError, service.SeverityCritical, service.TemplateUpdatesOverflow,
+		service.UpdatesOverflowMessageData{Dropped: dropped, Capacity: capacity})
+	s.ResyncMissedEvents()
+}
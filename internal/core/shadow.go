@@ -0,0 +1,39 @@
+package core
+
+import (
+	"time"
+
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/service"
+)
+
+// checkShadowReport sends the daily shadow vs live comparison once the day is over
+func (s *Strategy) checkShadowReport() {
+	if s.Shadow == nil {
+		return
+	}
+	report, ok := s.Shadow.PendingReport(time.Now())
+	if !ok {
+		return
+	}
+
+	s.Notifier.NotifyTemplate(service.CategoryReport, service.SeverityInfo, service.TemplateShadowReport, service.ShadowReportMessageData{
+		Day:            report.Day,
+		Profile:        report.Profile,
+		LiveTrades:     report.Stats.LiveTrades,
+		LiveNet:        report.Stats.LiveNet,
+		ShadowTrades:   report.Stats.ShadowTrades,
+		ShadowBuys:     report.Stats.ShadowBuys,
+		ShadowNet:      report.Stats.ShadowNet,
+		Diff:           report.Stats.ShadowNet - report.Stats.LiveNet,
+		TotalLiveNet:   report.Total.LiveNet,
+		TotalShadowNet: report.Total.ShadowNet,
+		Equity:         report.Equity,
+		Capital:        report.Capital,
+		OpenPositions:  report.OpenPositions,
+		OpenBuys:       report.OpenBuys,
+	})
+	logger.Info("🧪 Daily shadow report sent", "day", report.Day,
+		"live_net", report.Stats.LiveNet, "shadow_net", report.Stats.ShadowNet)
+	s.Shadow.MarkReported(report.Day)
+}
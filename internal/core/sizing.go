@@ -0,0 +1,86 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/service"
+)
+
+const maxVolSizingScale = 3.0 // Volatility sizing stays within 1/3x and 3x of the flat order value
+
+// volatilityTargetedValue scales the flat order value by SIZING_TARGET_VOL over the current
+// Garman-Klass volatility: bigger orders in calm regimes, smaller during crashes. The result
+// stays within SIZING_MIN/MAX_ORDER_USDT and maxVolSizingScale of the flat value; the flat
+// value is kept until the first volatility reading.
+func (s *Strategy) volatilityTargetedValue(flat float64) float64 {
+	vol, _ := s.VolatilityService.GetMetrics()
+	if vol <= 0 || flat <= 0 {
+		return flat
+	}
+	scale := math.Min(math.Max(s.Cfg.SizingTargetVol/vol, 1/maxVolSizingScale), maxVolSizingScale)
+	value := flat * scale
+	if s.Cfg.SizingMaxOrderUSDT > 0 {
+		value = math.Min(value, s.Cfg.SizingMaxOrderUSDT)
+	}
+	value = math.Max(value, s.Cfg.SizingMinOrderUSDT)
+
+	logger.Debug("📏 Volatility-targeted order value", "vol", fmt.Sprintf("%.5f", vol), "scale", fmt.Sprintf("%.2f", scale),
+		"flat", fmt.Sprintf("%.2f", flat), "value", fmt.Sprintf("%.2f", value))
+	return value
+}
+
+// refreshKellySizing recomputes, once a day, the position size of SIZING_MODE=kelly from the
+// archived round trips of the last KELLY_LOOKBACK_DAYS: the Kelly fraction W - (1-W)/R (win
+// rate W, payoff ratio R = average win / average loss; W alone when there were no losses)
+// times KELLY_FRACTION, capped at KELLY_MAX_SIZE_PCT. A negative edge sizes at the minimum.
+func (s *Strategy) refreshKellySizing(bnbPrice float64) {
+	if s.Cfg.SizingMode != "kelly" {
+		return
+	}
+	now := time.Now()
+	day := now.Format("2006-01-02")
+	if s.kellyDay == day {
+		return
+	}
+	s.kellyDay = day
+
+	days := s.Cfg.KellyLookbackDays
+	stats := service.ComputeTradeStats(s.TransactionRepo.GetClosedTransactionsAfter(now.AddDate(0, 0, -days)), 0, bnbPrice, now, days)
+	if stats.Trades < s.Cfg.KellyMinTrades {
+		s.kellyReady = false
+		logger.Info("🎲 Kelly sizing: not enough trades yet, using POSITION_SIZE_PCT", "trades", stats.Trades, "min", s.Cfg.KellyMinTrades)
+		return
+	}
+
+	kelly := stats.WinRate
+	payoff := 0.0
+	if stats.AvgLoss < 0 {
+		payoff = stats.AvgWin / -stats.AvgLoss
+		kelly = stats.WinRate - (1-stats.WinRate)/payoff
+	}
+	s.kellySize = math.Min(math.Max(kelly*s.Cfg.KellyFraction, 0), s.Cfg.KellyMaxSizePct)
+	s.kellyReady = true
+	logger.Info("🎲 Kelly sizing updated",
+		"trades", stats.Trades,
+		"win_rate", fmt.Sprintf("%.2f", stats.WinRate),
+		"payoff", fmt.Sprintf("%.2f", payoff),
+		"kelly", fmt.Sprintf("%.4f", kelly),
+		"size_pct", fmt.Sprintf("%.4f", s.kellySize),
+	)
+}
+
+// kellySizePct is the position size of SIZING_MODE=kelly: POSITION_SIZE_PCT until there are
+// KELLY_MIN_TRADES archived trades. A circuit breaker "halve" escalation applies to it too.
+func (s *Strategy) kellySizePct() float64 {
+	if !s.kellyReady {
+		return s.Cfg.PositionSizePct
+	}
+	size := s.kellySize
+	if escalation, _ := s.circuitBreakerEscalation(); escalation == "halve" {
+		size /= cbEscalationFactor
+	}
+	return size
+}
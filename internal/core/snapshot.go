@@ -0,0 +1,41 @@
+package core
+
+import (
+	"time"
+
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/snapshot"
+)
+
+// checkSnapshot bundles the whole state into SNAPSHOT_DIR every SNAPSHOT_INTERVAL_MIN and
+// keeps the newest SNAPSHOT_KEEP files
+func (s *Strategy) checkSnapshot() {
+	if s.Cfg.SnapshotIntervalMin <= 0 {
+		return
+	}
+	if time.Since(s.lastSnapshotAt) < time.Duration(s.Cfg.SnapshotIntervalMin)*time.Minute {
+		return
+	}
+	s.lastSnapshotAt = time.Now()
+
+	// Pending batched changes first, or the snapshot would miss the last window
+	if err := s.TransactionRepo.Flush(); err != nil {
+		logger.Error("❌ Failed to flush transactions before snapshot", "error", err)
+		return
+	}
+	snap, err := snapshot.Create(s.Cfg.Symbol, "periodic", s.BalanceRepo.All())
+	if err != nil {
+		logger.Error("❌ Failed to create state snapshot", "error", err)
+		return
+	}
+	path, err := snapshot.Write(s.Cfg.SnapshotDir, snap)
+	if err != nil {
+		logger.Error("❌ Failed to write state snapshot", "error", err)
+		return
+	}
+	removed, err := snapshot.Prune(s.Cfg.SnapshotDir, s.Cfg.SnapshotKeep)
+	if err != nil {
+		logger.Warn("⚠️ Failed to prune old snapshots", "error", err)
+	}
+	logger.Info("📸 State snapshot saved", "path", path, "files", len(snap.Files), "pruned", removed)
+}
@@ -0,0 +1,85 @@
+package core
+
+import (
+	"strconv"
+
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/model"
+	"grid-trading-btc-binance/internal/service"
+)
+
+// startupReport counts what each phase of SyncOrdersOnStartup changed
+type startupReport struct {
+	OpenOrders      int  // On Binance when the sync started
+	Imported        int  // Phase 1: orphan orders imported, buys rebuilt from their exits
+	Linked          int  // Phases 1-2: exits on the book linked to their buys
+	FilledOffline   int  // Phase 2
+	CanceledOffline int  // Phase 2
+	Purged          int  // Phase 3: ghosts archived (exits sold offline, failed placements, vanished buys)
+	Duplicates      int  // Phase 4
+	ZombiesRescued  int  // Phase 5: exit placed
+	ZombiesCleaned  int  // Phase 5: archived, BTC no longer in the account
+	DryRun          bool // SYNC_DRY_RUN: phases 3 to 5 only counted what they would do
+}
+
+// sendStartupReport logs and notifies one summary of the startup sync with the resulting
+// exposure, so the state after a restart is checked at a glance
+func (s *Strategy) sendStartupReport(r startupReport) {
+	data := service.StartupReportMessageData{
+		Symbol:          s.Cfg.Symbol,
+		Quote:           s.Cfg.QuoteAsset,
+		OpenOrders:      r.OpenOrders,
+		Imported:        r.Imported,
+		Linked:          r.Linked,
+		FilledOffline:   r.FilledOffline,
+		CanceledOffline: r.CanceledOffline,
+		Purged:          r.Purged,
+		Duplicates:      r.Duplicates,
+		ZombiesRescued:  r.ZombiesRescued,
+		ZombiesCleaned:  r.ZombiesCleaned,
+		FreeUSDT:        s.getBalance(s.Cfg.QuoteAsset),
+		Paused:          s.StateRepo.Get().Paused,
+		DryRun:          r.DryRun,
+	}
+	for _, tx := range s.TransactionRepo.Find(s.gridBuys(model.StatusOpen, model.StatusFilled, model.StatusExitPlaced)) {
+		qty, _ := strconv.ParseFloat(tx.Amount, 64)
+		price, _ := strconv.ParseFloat(tx.Price, 64)
+		switch tx.StatusTransaction {
+		case model.StatusOpen:
+			data.OpenBuys++
+			data.OpenBuysUSDT += qty * price
+			continue
+		case model.StatusExitPlaced:
+			data.WithExit++
+		default:
+			data.WithoutExit++
+		}
+		data.InventoryQty += qty
+		data.InventoryCost += qty * price
+	}
+
+	logger.Info("📋 Startup sync report",
+		"open_orders", data.OpenOrders,
+		"imported", data.Imported,
+		"linked", data.Linked,
+		"filled_offline", data.FilledOffline,
+		"canceled_offline", data.CanceledOffline,
+		"purged", data.Purged,
+		"duplicates", data.Duplicates,
+		"zombies_rescued", data.ZombiesRescued,
+		"zombies_cleaned", data.ZombiesCleaned,
+		"dry_run", data.DryRun,
+		"open_buys", data.OpenBuys,
+		"open_buys_usdt", data.OpenBuysUSDT,
+		"positions_with_exit", data.WithExit,
+		"positions_without_exit", data.WithoutExit,
+		"inventory_qty", data.InventoryQty,
+		"inventory_cost", data.InventoryCost,
+	)
+
+	severity := service.SeverityInfo
+	if data.WithoutExit > 0 || data.ZombiesCleaned > 0 || (data.DryRun && data.Purged+data.Duplicates+data.ZombiesRescued > 0) {
+		severity = service.SeverityWarning
+	}
+	s.Notifier.NotifyTemplate(service.CategorySync, severity, service.TemplateStartupReport, data)
+}
@@ -1,48 +1,136 @@
 package core
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"sort"
 	"strconv"
+	"sync"
 	"time"
 
 	"grid-trading-btc-binance/internal/api"
+	"grid-trading-btc-binance/internal/audit"
 	"grid-trading-btc-binance/internal/config"
+	"grid-trading-btc-binance/internal/crash"
+	"grid-trading-btc-binance/internal/fees"
 	"grid-trading-btc-binance/internal/logger"
 	"grid-trading-btc-binance/internal/market"
+	"grid-trading-btc-binance/internal/metrics"
 	"grid-trading-btc-binance/internal/model"
+	"grid-trading-btc-binance/internal/precision"
 	"grid-trading-btc-binance/internal/repository"
 	"grid-trading-btc-binance/internal/service"
+	"grid-trading-btc-binance/internal/shadow"
+	"grid-trading-btc-binance/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	balanceSnapshotMaxAge = 2 * time.Minute // Balance cache is refreshed every minute at most
 )
 
 type Strategy struct {
 	Cfg                       *config.Config
 	BalanceRepo               *repository.BalanceRepository
 	TransactionRepo           *repository.TransactionRepository
-	TelegramService           *service.TelegramService
+	EquityRepo                *repository.EquityRepository
+	VaultRepo                 *repository.VaultRepository
+	StateRepo                 *repository.StateRepository
+	SeenEvents                *repository.SeenEventRepository
+	DCARepo                   *repository.DCARepository
+	Notifier                  *service.NotificationService
 	Binance                   *api.BinanceClient
+	Futures                   *api.FuturesClient // USDⓈ-M account of the hedge (nil = HEDGE_ENABLED=false)
 	VolatilityService         *market.VolatilityService
+	Supports                  *market.SupportLevels // Buy placement below supports (nil = PLACEMENT_MODE=spacing)
+	Macro                     *market.MacroCalendar // No new entries around macro events (nil = no MACRO_CALENDAR_FILE/URL)
+	Rebalancer                *Rebalancer
+	Ledger                    *service.TradeLedger      // One row per closed round trip (nil = disabled)
+	Executions                *service.ExecutionLog     // Intended vs fill price and maker/taker of every fill (nil = disabled)
+	BookImbalance             *service.BookImbalanceLog // Order-book imbalance readings of the entry filter (nil = not logged)
+	Sink                      service.MetricsSink       // Optional per-trade metrics (nil = disabled)
+	Shadow                    *shadow.Engine            // Paper strategy compared daily with the live one (nil = disabled)
+	Metrics                   *metrics.Tracker          // Fill-to-exit latency, debounced tickers (set by NewBot, nil = not measured)
+	Updates                   *service.UpdateWorkerPool // Order update workers the resync replays go through (nil = handled inline)
 	lastFillCheck             time.Time
 	lastUSDTAlertTime         time.Time
 	lastBNBAlertTime          time.Time
 	circuitBreakerTriggeredAt time.Time
-	lastBuyFailureTime        time.Time // Circuit Breaker for Order Placement -2010 loops
-	tickSize                  float64
+	lastBuyFailureTime        time.Time             // Circuit Breaker for Order Placement -2010 loops
+	normalizer                *precision.Normalizer // Rounds prices/quantities to the symbol's exchange filters
+	filtersCheckedAt          time.Time
+	lastDCAFailure            time.Time
+	profileMu                 sync.Mutex
+	activeProfile             string            // Parameter profile in use (config.DefaultProfile = base values)
+	baseValues                map[string]string // Profile keys as loaded, restored before applying a profile
+	cbTriggerDay              string            // Day (YYYY-MM-DD) the circuit breaker trigger counter refers to
+	cbTriggerCount            int
+	cbTrips                   []time.Time // Circuit breaker trips within CB_TRIP_WINDOW_HOURS
+	cbEscalation              string      // widen/halve in force on top of the profile ("" = none, guarded by profileMu)
+	cbEscalatedAt             time.Time   // Start of the escalation, renewed by every trip
+	tradingWindow             string      // Label of the trading window last seen in force ("" = none)
+	macroEvent                string      // Macro event whose pause was last seen in force ("" = none)
+	kellyDay                  string      // Day (YYYY-MM-DD) the Kelly size was computed for
+	kellySize                 float64     // Position size from the Kelly fraction...
+	kellyReady                bool        // ...once there are KELLY_MIN_TRADES archived trades
+	imbalanceDelayedAt        time.Time   // First buy delayed by the order-book imbalance filter (zero = none)
+	imbalanceCheckedAt        time.Time   // Last reading of the order-book imbalance filter
+	earnProductID             string      // Simple Earn Flexible product of the quote asset
+	earnFailedAt              time.Time   // Last failed redemption (retried after earnRetryAfter)
+	dustTriedAt               time.Time   // Last dust conversion attempt (retried after dustRetryAfter)
+	hedgeMu                   sync.Mutex
+	hedge                     service.HedgeStats    // Position at the last hedge check (guarded by hedgeMu)
+	hedgeNormalizer           *precision.Normalizer // Quantity filters of HEDGE_SYMBOL
+	hedgeFailedAt             time.Time             // Last failed hedge order (retried after hedgeRetryAfter)
+	tickAt                    time.Time             // Time of the ticker being executed (start of the order traces)
+	ticker                    model.Ticker
+	marginMu                  sync.Mutex
+	margin                    marginState // Margin account at the last margin check (guarded by marginMu)
+	earnMu                    sync.Mutex
+	earn                      service.EarnStats // Simple Earn holding at the last check (guarded by earnMu)
+	exitMu                    sync.Mutex
+	exitLevels                map[string]string // Exit price -> buy ID, while the exit is being placed
+	lastEvalAt                time.Time         // Last ticker the strategy ran on (see dueForEvaluation)
+	lastEvalPrice             float64
+	lastSnapshotAt            time.Time // Last periodic state snapshot (see checkSnapshot)
+	fillsMu                   sync.Mutex
+	recentFills               []Fill // Newest first (see Dashboard)
+	cleanupMu                 sync.Mutex
+	cleanupPending            bool                 // SYNC_DRY_RUN previewed a startup cleanup that was not confirmed yet
+	staleCancelFailedAt       map[string]time.Time // Stale buys whose cancel failed (retried after staleCancelRetryAfter)
 }
 
-func NewStrategy(cfg *config.Config, balanceRepo *repository.BalanceRepository, transactionRepo *repository.TransactionRepository, telegramService *service.TelegramService, binanceClient *api.BinanceClient, volatilityService *market.VolatilityService) *Strategy {
+func NewStrategy(cfg *config.Config, balanceRepo *repository.BalanceRepository, transactionRepo *repository.TransactionRepository, equityRepo *repository.EquityRepository, vaultRepo *repository.VaultRepository, stateRepo *repository.StateRepository, seenEvents *repository.SeenEventRepository, dcaRepo *repository.DCARepository, notifier *service.NotificationService, binanceClient *api.BinanceClient, volatilityService *market.VolatilityService) *Strategy {
+	normalizer := precision.NewNormalizer(precision.DefaultFilters)
 	s := &Strategy{
 		Cfg:               cfg,
 		BalanceRepo:       balanceRepo,
 		TransactionRepo:   transactionRepo,
-		TelegramService:   telegramService,
+		EquityRepo:        equityRepo,
+		VaultRepo:         vaultRepo,
+		StateRepo:         stateRepo,
+		SeenEvents:        seenEvents,
+		DCARepo:           dcaRepo,
+		Notifier:          notifier,
 		Binance:           binanceClient,
 		VolatilityService: volatilityService,
+		Rebalancer:        NewRebalancer(cfg, balanceRepo, transactionRepo, binanceClient, normalizer),
+		normalizer:        normalizer,
 	}
 
-	// Fetch TickSize on startup
-	s.fetchTickSize()
+	// Fetch tickSize/stepSize/minNotional on startup (refreshed daily by the periodic sync)
+	s.loadExchangeFilters()
+
+	// Compounding: make sure there is a persisted equity baseline to size from
+	s.initEquityBaseline()
+
+	// Parameter profile: the one switched at runtime, otherwise PROFILE
+	s.initProfiles()
+
+	// A circuit breaker pause in progress survives the restart
+	s.initCircuitBreaker()
 
 	// Cleanup Closed Transactions on Startup
 	cleaned := s.TransactionRepo.CleanupClosed()
@@ -53,75 +141,54 @@ func NewStrategy(cfg *config.Config, balanceRepo *repository.BalanceRepository,
 	return s
 }
 
-func (s *Strategy) fetchTickSize() {
-	info, err := s.Binance.GetExchangeInfo(s.Cfg.Symbol)
-	if err != nil {
-		logger.Error("⚠️ Failed to fetch ExchangeInfo for TickSize. Using default 0.01.", "error", err)
-		s.tickSize = 0.01
-		return
+func (s *Strategy) Execute(ticker model.Ticker, bnbPrice float64) {
+	// The shadow strategy sees every ticker, even while the live grid is paused
+	if s.Shadow != nil {
+		s.Shadow.OnTicker(ticker)
 	}
 
-	for _, symbol := range info.Symbols {
-		if symbol.Symbol == s.Cfg.Symbol {
-			for _, filter := range symbol.Filters {
-				if filter.FilterType == "PRICE_FILTER" {
-					ts, err := strconv.ParseFloat(filter.TickSize, 64)
-					if err == nil && ts > 0 {
-						s.tickSize = ts
-						logger.Info("✅ TickSize Detected", "symbol", s.Cfg.Symbol, "tickSize", ts)
-						return
-					}
-				}
-			}
-		}
+	// Ticker bursts: the full evaluation runs at most every EVAL_INTERVAL_MS, or on a real move
+	if !s.dueForEvaluation(ticker.Price) {
+		return
 	}
-	logger.Warn("⚠️ TickSize not found in ExchangeInfo. Defaulting to 0.01.")
-	s.tickSize = 0.01
-}
 
-func (s *Strategy) Execute(ticker model.Ticker, bnbPrice float64) {
-	// 1. Fetch Data
-	transactions := s.TransactionRepo.GetAll()
+	s.tickAt = ticker.Time
+	s.ticker = ticker
 
-	// Filter open and filled orders
-	var openOrders []model.Transaction
-	var filledOrders []model.Transaction
+	// 0. Kill switch: nothing is placed while paused (/panic, cleared by /resume)
+	if s.IsPaused() {
+		return
+	}
 
-	for _, tx := range transactions {
-		if tx.Symbol == s.Cfg.Symbol && tx.Type == "buy" {
-			if tx.StatusTransaction == "open" {
-				openOrders = append(openOrders, tx)
-			} else if tx.StatusTransaction == "filled" {
-				filledOrders = append(filledOrders, tx)
-			}
-		}
+	// Monitor-only: watch the market (circuit breaker alerts) but never trade
+	if s.Cfg.MonitorOnly {
+		s.isMarketSafe(ticker.Price)
+		return
 	}
 
-	// 2. Process Fills (REMOVED - Now handled by WebSocket)
-	// s.processFills(openOrders, ticker.Price)
+	// DCA accumulation mode replaces the grid entirely (no per-order exits)
+	if s.Cfg.StrategyMode == "dca" {
+		s.executeDCA(ticker, bnbPrice)
+		return
+	}
 
-	// 3. Check Take Profit (Taker)
-	// We check this every cycle still, to catch things if WS notified us already
-	// or if we rely on loop for TP check.
+	// Drawdown stop: pauses the grid and sells part of the inventory
+	if s.checkDrawdownStop(ticker.Bid) {
+		return
+	}
 
-	// Re-fetch filled orders after potential fills
-	transactions = s.TransactionRepo.GetAll()
-	filledOrders = []model.Transaction{}
-	activeOpenOrders := []model.Transaction{}
+	// 1. Fetch Data (indexed queries: a ticker never scans the whole repository)
+	activeOpenOrders := s.TransactionRepo.Find(s.gridBuys(model.StatusOpen))
 
-	for _, tx := range transactions {
-		if tx.Symbol == s.Cfg.Symbol && tx.Type == "buy" {
-			if tx.StatusTransaction == "filled" {
-				filledOrders = append(filledOrders, tx)
-			} else if tx.StatusTransaction == "open" {
-				activeOpenOrders = append(activeOpenOrders, tx)
-			}
-		}
-	}
+	// 2. Process Fills (REMOVED - Now handled by WebSocket)
+	// s.processFills(openOrders, ticker.Price)
 
 	// 3. Check Take Profit (Legacy Polling Removed - Now Event Driven)
 	// s.checkTakeProfit(filledOrders, activeOpenOrders, ticker.Price, bnbPrice)
 
+	// 4. Expire stale buys the market ran away from (frees capital)
+	s.expireStaleBuyOrders(activeOpenOrders, ticker.Price)
+
 	// 5. Volatility Circuit Breaker (Crash Protection)
 	if !s.isMarketSafe(ticker.Price) {
 		return // Block new entries
@@ -134,25 +201,34 @@ func (s *Strategy) Execute(ticker model.Ticker, bnbPrice float64) {
 	}
 
 	// 6. Place New Grid Orders (Maker)
-	// Re-fetch open/filled to be sure
-	transactions = s.TransactionRepo.GetAll()
-	openOrders = []model.Transaction{}
-	filledOrders = []model.Transaction{}
-	for _, tx := range transactions {
-		if tx.Symbol == s.Cfg.Symbol && tx.Type == "buy" {
-			if tx.StatusTransaction == "open" {
-				openOrders = append(openOrders, tx)
-			} else if tx.StatusTransaction == "filled" || tx.StatusTransaction == "waiting_sell" {
-				filledOrders = append(filledOrders, tx)
-			}
-		}
-	}
+	// Re-fetch open/filled: stale buys may have just been expired
+	openOrders := s.TransactionRepo.Find(s.gridBuys(model.StatusOpen))
+	filledOrders := s.TransactionRepo.Find(s.gridBuys(model.StatusFilled, model.StatusExitPlaced))
 
+	s.refreshKellySizing(bnbPrice)
 	s.placeNewGridOrders(openOrders, filledOrders, ticker.Price, ticker.Bid, bnbPrice)
+	s.Rebalancer.Check(ticker.Bid, ticker.Ask, s.walletDeployableUSDT(), s.owedBase(""))
 	s.checkLowBNB(bnbPrice)
 	s.checkSmartEntryReposition(openOrders, filledOrders, ticker.Price)
 }
 
+// dueForEvaluation debounces BookTicker events, which arrive many times per second: the
+// strategy runs when EVAL_INTERVAL_MS has passed since the last run or the price moved
+// EVAL_PRICE_CHANGE_PCT from the price of that run. Skipped tickers cost nothing.
+func (s *Strategy) dueForEvaluation(price float64) bool {
+	now := time.Now()
+	interval := time.Duration(s.Cfg.EvalIntervalMs) * time.Millisecond
+	moved := s.Cfg.EvalPriceChangePct > 0 && s.lastEvalPrice > 0 &&
+		math.Abs(price-s.lastEvalPrice)/s.lastEvalPrice >= s.Cfg.EvalPriceChangePct
+	if now.Sub(s.lastEvalAt) < interval && !moved {
+		s.Metrics.TrackDebounced()
+		return false
+	}
+	s.lastEvalAt = now
+	s.lastEvalPrice = price
+	return true
+}
+
 // HandleOrderUpdate processes executionReport events from WebSocket
 func (s *Strategy) HandleOrderUpdate(event service.OrderUpdate) {
 	if event.Symbol != s.Cfg.Symbol {
@@ -161,6 +237,33 @@ func (s *Strategy) HandleOrderUpdate(event service.OrderUpdate) {
 
 	// logger.Debug("⚡ Processing Order Update") // Reduced noise
 
+	// Idempotency: reconnect replays or duplicated frames must never double-place an exit
+	// or double-count profit
+	eventKey := fmt.Sprintf("%d:%d:%s", event.OrderID, event.TradeID, event.ExecutionType)
+	first, err := s.SeenEvents.MarkSeen(eventKey)
+	if err != nil {
+		logger.Error("Failed to persist seen WebSocket event", "key", eventKey, "error", err)
+	}
+	if !first {
+		logger.Info("🔁 Duplicate order update ignored", "id", event.ClientOrderID, "key", eventKey)
+		return
+	}
+	// A final state is processed once, whether the live event or a resync replay reports it
+	// (their keys above differ: the replay has no trade ID)
+	if isFinalOrderStatus(event.Status) {
+		finalKey := fmt.Sprintf("%d:%s", event.OrderID, event.Status)
+		first, err := s.SeenEvents.MarkSeen(finalKey)
+		if err != nil {
+			logger.Error("Failed to persist seen WebSocket event", "key", finalKey, "error", err)
+		}
+		if !first {
+			logger.Info("🔁 Order final state already processed, update ignored", "id", event.ClientOrderID, "status", event.Status, "execType", event.ExecutionType)
+			return
+		}
+	}
+
+	s.Executions.Observe(event)
+
 	logger.Info("⚡ Order Update Received",
 		"id", event.ClientOrderID,
 		"status", event.Status,
@@ -173,6 +276,15 @@ func (s *Strategy) HandleOrderUpdate(event service.OrderUpdate) {
 		// Check secondary lookup by SellOrderID
 		var found bool
 		tx, found = s.TransactionRepo.GetBySellID(event.ClientOrderID)
+		if !found {
+			// SELL_<buyID> whose link was not persisted yet
+			if buyID, ok := exitBuyID(event.ClientOrderID); ok {
+				if buyTx, ok := s.TransactionRepo.Get(buyID); ok && buyTx.SellOrderID == "" {
+					buyTx.SellOrderID = event.ClientOrderID
+					tx, found = buyTx, true
+				}
+			}
+		}
 		if !found {
 			// Possibly a manual order or one we don't track?
 			logger.Debug("Received update for unknown order", "id", event.ClientOrderID)
@@ -183,7 +295,7 @@ func (s *Strategy) HandleOrderUpdate(event service.OrderUpdate) {
 	}
 
 	if event.Status == "FILLED" {
-		if tx.StatusTransaction != "filled" && tx.StatusTransaction != "waiting_sell" && tx.StatusTransaction != "closed" {
+		if tx.StatusTransaction != model.StatusFilled && tx.StatusTransaction != model.StatusExitPlaced && tx.StatusTransaction != model.StatusClosed {
 			logger.Info("⚡ WebSocket: Order FILLED", "orderID", tx.ID, "price", event.LastExecPrice)
 
 			// If it's a BUY order, we treat it as an entry fill -> Place Exit
@@ -194,7 +306,9 @@ func (s *Strategy) HandleOrderUpdate(event service.OrderUpdate) {
 					return
 				}
 
-				tx.StatusTransaction = "filled"
+				if !s.transition(&tx, model.StatusFilled, "WS: buy filled") {
+					return
+				}
 				tx.Price = event.LastExecPrice // Update entry price
 				if event.LastExecQty != "" {
 					tx.Amount = event.LastExecQty
@@ -208,10 +322,22 @@ func (s *Strategy) HandleOrderUpdate(event service.OrderUpdate) {
 				tx.Notes += " | WS Verified Fill"
 				s.TransactionRepo.Update(tx)
 
+				// The fill joins the trace of its buy, starting when Binance executed it
+				ctx, span := tracing.StartAt(tracing.FromNotes(tx.Notes), "ws.fill", time.UnixMilli(event.TxTime),
+					attribute.String("order_id", tx.ID),
+					attribute.String("price", event.LastExecPrice),
+					attribute.Int64("detect_latency_ms", time.Now().UnixMilli()-event.TxTime),
+				)
+
 				// TRIGGER MAKER EXIT
-				s.placeMakerExitOrder(&tx)
+				s.placeMakerExitOrder(ctx, &tx)
+				span.End()
+				if tx.StatusTransaction == model.StatusExitPlaced && !event.ReceivedAt.IsZero() {
+					s.Metrics.TrackFillToExit(time.Since(event.ReceivedAt))
+				}
 
 				// Notify Entry
+				s.recordFill("BUY", tx.Price, tx.Amount, 0)
 				s.sendTradeNotification(tx, 0, nil)
 
 			} else if tx.Type == "sell" {
@@ -234,9 +360,17 @@ func (s *Strategy) HandleOrderUpdate(event service.OrderUpdate) {
 			// If tx.SellOrderID == event.ClientOrderID ...
 			if tx.SellOrderID == event.ClientOrderID {
 				logger.Info("💰 WebSocket: Maker Exit Order FILLED", "sellOrderID", event.ClientOrderID)
+				_, span := tracing.StartAt(tracing.FromNotes(tx.Notes), "ws.exit_fill", time.UnixMilli(event.TxTime),
+					attribute.String("order_id", tx.ID),
+					attribute.String("sell_order_id", event.ClientOrderID),
+					attribute.String("price", event.LastExecPrice),
+				)
+				defer span.End()
 
 				// Mark as closed/sold
-				tx.StatusTransaction = "closed"
+				if !s.transition(&tx, model.StatusClosed, "WS: exit filled") {
+					return
+				}
 				now := time.Now()
 				tx.ClosedAt = &now
 
@@ -248,6 +382,7 @@ func (s *Strategy) HandleOrderUpdate(event service.OrderUpdate) {
 				revenue := sellPrice * qty
 				cost := buyPrice * qty
 				profit := revenue - cost
+				s.recordRealizedProfit(profit)
 
 				// Fee Accumulation (Sell Side)
 				if event.Commission != "" {
@@ -255,6 +390,7 @@ func (s *Strategy) HandleOrderUpdate(event service.OrderUpdate) {
 					currentFee, _ := strconv.ParseFloat(tx.Fee, 64)
 					tx.Fee = fmt.Sprintf("%.8f", currentFee+comm)
 				}
+				s.recordTrade(tx, sellPrice, now, "grid")
 
 				// tx.Notes += fmt.Sprintf(" | Sold at %.2f (Profit: $%.2f)", sellPrice, profit)
 				// s.TransactionRepo.Update(tx) // Old Update
@@ -278,28 +414,23 @@ func (s *Strategy) HandleOrderUpdate(event service.OrderUpdate) {
 				sellTx.ID = event.ClientOrderID
 				sellTx.Type = "sell"
 				sellTx.Price = event.LastExecPrice
-				sellTx.StatusTransaction = "filled"
+				sellTx.StatusTransaction = model.StatusFilled
 
+				s.recordFill("SELL", event.LastExecPrice, tx.Amount, profit)
 				s.sendTradeNotification(sellTx, profit, nil)
 			}
 		}
 	} else if event.Status == "CANCELED" || event.Status == "REJECTED" || event.Status == "EXPIRED" {
-		if tx.StatusTransaction != "closed" {
+		if !model.IsTerminal(tx.StatusTransaction) {
 			// Check if it's the Sell Order that was canceled
 			if tx.SellOrderID == event.ClientOrderID {
-				logger.Warn("⚠️ Maker Exit Order Canceled/Rejected", "sellOrderID", tx.SellOrderID)
-				// Reset status to filled so we retry placing it?
-				// Or 'waiting_sell' so startup sync catches it?
-				// Strategy says: "Retry... if failed... log critical"
-				// But if canceled externally?
-				// Let's set it back to 'filled' to retry placement if appropriate, or 'waiting_sell' and let sync handle it.
-				// If we set to 'filled', the next 'execute' loop won't inherently trigger 'placeMakerExitOrder' unless we add logic there.
-				// But safely, we can log and maybe try to replace immediately?
-				// For now, let's log.
+				s.reviveCanceledExit(tx, event)
 			} else {
 				// It's the buy order
 				logger.Warn("⚠️ WebSocket: Buy Order Closed/Canceled", "orderID", tx.ID, "status", event.Status)
-				tx.StatusTransaction = "closed"
+				if !s.transition(&tx, model.StatusCanceled, "WS: "+event.Status) {
+					return
+				}
 				tx.Notes += fmt.Sprintf(" | Closed via WS: %s", event.Status)
 				s.TransactionRepo.Update(tx)
 			}
@@ -308,53 +439,67 @@ func (s *Strategy) HandleOrderUpdate(event service.OrderUpdate) {
 }
 
 // sendTradeNotification helper to avoid duplicated code
+// Balances come from the cached snapshot (stream position events + 1m REST sync), so the
+// update workers never block on REST latency here.
 func (s *Strategy) sendTradeNotification(tx model.Transaction, profit float64, ordersToClose []model.Transaction) {
-	var usdtBal, bnbBal, btcBal float64
-	accInfo, err := s.Binance.GetAccountInfo()
-	if err != nil {
-		logger.Error("⚠️ Failed to fetch fresh balances", "error", err)
-		usdtBal = s.getBalance("USDT")
-		bnbBal = s.getBalance("BNB")
-		btcBal = s.getBalance("BTC")
-	} else {
-		for _, b := range accInfo.Balances {
-			if b.Asset == "USDT" {
-				usdtBal, _ = strconv.ParseFloat(b.Free, 64)
-			} else if b.Asset == "BNB" {
-				bnbBal, _ = strconv.ParseFloat(b.Free, 64)
-			} else if b.Asset == "BTC" {
-				btcBal, _ = strconv.ParseFloat(b.Free, 64)
-			}
-		}
+	if age := time.Since(s.BalanceRepo.UpdatedAt()); age > balanceSnapshotMaxAge {
+		logger.Warn("⚠️ Balance snapshot is stale for trade notification", "age", age.Round(time.Second).String())
 	}
-	s.TelegramService.SendTradeNotification(tx, profit, ordersToClose, usdtBal, bnbBal, btcBal)
+	s.Notifier.NotifyTrade(tx, profit, ordersToClose, s.getBalance(s.Cfg.QuoteAsset), s.getBalance("BNB"), s.getBalance(s.Cfg.BaseAsset))
+}
+
+// retryBuyPrice is the price of the next LIMIT_MAKER buy attempt after a rejection: the live
+// best bid on the first retry, one tick below it on the next ones (the book keeps moving
+// while we retry), never above the price the buy was decided at. Without the book it falls
+// back to dropping the previous price by 0.05%.
+func (s *Strategy) retryBuyPrice(previous string, decided float64, attempt int) string {
+	book, err := s.Binance.GetBookTicker(s.Cfg.Symbol)
+	bid := 0.0
+	if err == nil {
+		bid, _ = strconv.ParseFloat(book.BidPrice, 64)
+	}
+	if bid <= 0 {
+		logger.Warn("⚠️ Order book unavailable for the retry price, dropping 0.05%", "error", err)
+		p, _ := strconv.ParseFloat(previous, 64)
+		return s.normalizer.BuyPrice(p * (1 - 0.0005))
+	}
+
+	price := bid
+	if attempt > 0 {
+		price -= s.normalizer.Filters().TickSize
+	}
+	return s.normalizer.BuyPrice(math.Min(price, decided))
 }
 
 // Implement placeMakerExitOrder
-func (s *Strategy) placeMakerExitOrder(tx *model.Transaction) {
+func (s *Strategy) placeMakerExitOrder(ctx context.Context, tx *model.Transaction) {
+	if s.monitorOnly("maker exit", "id", tx.ID) {
+		return
+	}
+	ctx, span := tracing.Start(ctx, "exit.place", attribute.String("order_id", tx.ID))
+	defer span.End()
+
 	// 1. Calculate Sell Price
+	// Grid exit: Sell = Buy + spacing, never below break-even on both maker fees plus
+	// MIN_NET_PROFIT_PCT (a spacing floor tuned for another fee rate must not sell at a loss)
 	buyPrice, _ := strconv.ParseFloat(tx.Price, 64)
-	// profitMargin := s.Cfg.MinNetProfitPct // Unused in Grid Strategy (Fixed Spacing)
-	// Or should we use GridSpacing? Usually Grid uses fixed spacing.
-	// But let's stick to "ProfitMargin" concept from prompt.
-	// Assuming MinNetProfitPct is appropriate or we should check if there is a separate config.
-	// The prompt said: SellPrice = BuyPrice * (1 + ProfitMargin).
-	// Let's use GridSpacingPct as valid Proxy if ProfitMargin not explicit.
-	// Actually, typically for Grid, Sell = Buy + GridSpacing.
-	dynamicSpacing := s.VolatilityService.GetDynamicSpacing()
+	dynamicSpacing := s.spacingAt(buyPrice) // The fixed spacing of the buy's zone, if any
 	targetPrice := buyPrice * (1 + dynamicSpacing)
+	minExit := fees.For(s.Cfg).MinExitPrice(buyPrice, s.Cfg.MinNetProfitPct)
+	if targetPrice < minExit {
+		logger.Info("📐 Exit raised to the net profit floor", "id", tx.ID, "spacing_target", targetPrice, "min_exit", minExit)
+		targetPrice = minExit
+	}
 
-	sellPriceStr := fmt.Sprintf("%.2f", targetPrice)
+	// Another exit on the same level would make the two indistinguishable on the book
+	sellPriceStr, release := s.reserveExitPrice(tx, s.normalizer.SellPrice(targetPrice))
+	defer release()
 
 	// 2. Calculate Quantity (Safety Check)
 	buyQty, _ := strconv.ParseFloat(tx.Amount, 64)
 
-	// Check Available Balance
-	// We need to know which asset we are selling. BTCUSDT -> Sell BTC.
-	var baseAsset string = "BTC" // Hardcoded for BTCUSDT or derive from Symbol
-	if len(s.Cfg.Symbol) > 4 && s.Cfg.Symbol[len(s.Cfg.Symbol)-4:] == "USDT" {
-		baseAsset = s.Cfg.Symbol[:len(s.Cfg.Symbol)-4]
-	}
+	// Check Available Balance of the asset being sold (BTC of BTCUSDT)
+	baseAsset := s.Cfg.BaseAsset
 
 	// Get LIVE balance to be safe
 	accInfo, err := s.Binance.GetAccountInfo()
@@ -382,16 +527,18 @@ func (s *Strategy) placeMakerExitOrder(tx *model.Transaction) {
 		sellQty = safeSellQty
 	}
 
-	// Min Lot Size Check? 0.00001 BTC.
-	if sellQty < 0.00001 {
+	// Min Lot Size Check (LOT_SIZE minQty)
+	sellQty = s.normalizer.FloorQty(sellQty)
+	if sellQty < s.normalizer.Filters().MinQty {
 		logger.Error("❌ Sell Quantity too low to place order", "qty", sellQty)
 		return
 	}
 
-	qtyStr := fmt.Sprintf("%.5f", sellQty)
+	qtyStr := s.normalizer.FormatQty(sellQty)
 
 	// 3. Execution with Retry
-	sellOrderID := fmt.Sprintf("SELL_%d", time.Now().UnixNano())
+	// Same level and key as the buy: the exit names its buy, so relinking never depends on quantities
+	sellOrderID := exitOrderID(tx.ID)
 
 	req := api.OrderRequest{
 		Symbol:           s.Cfg.Symbol,
@@ -402,49 +549,86 @@ func (s *Strategy) placeMakerExitOrder(tx *model.Transaction) {
 		Price:            sellPriceStr,
 		NewClientOrderID: sellOrderID,
 	}
+	audit.Intent(tx.ID, sellOrderID, "place_exit", audit.Fields{
+		"buy_price":   tx.Price,
+		"spacing_pct": dynamicSpacing,
+		"min_exit":    minExit,
+		"sell_price":  sellPriceStr,
+		"qty":         qtyStr,
+	})
 
 	var resp *api.OrderResponse
-	maxRetries := 5
+	maxRetries := s.Cfg.ExitFallbackAfter
 	backoff := 1 * time.Second
 
 	for i := 0; i < maxRetries; i++ {
-		resp, err = s.Binance.CreateOrder(req)
+		err = tracing.Call(ctx, "binance.create_order", func() (err error) {
+			resp, err = s.Binance.CreateOrder(req)
+			return err
+		}, attribute.String("client_order_id", sellOrderID), attribute.Int("attempt", i+1))
 		if err == nil {
 			break
 		}
+		if exitRejected(err) {
+			// Deterministic: the same order would be refused again
+			logger.Warn("⚠️ Maker Exit rejected by Binance, not retrying", "attempt", i+1, "error", err)
+			break
+		}
+		if i == maxRetries-1 {
+			break
+		}
 		logger.Warn("⚠️ Failed to place Maker Exit. Retrying...", "attempt", i+1, "error", err)
 		time.Sleep(backoff)
-		backoff *= 2
+		backoff = min(backoff*2, exitRetryMaxBackoff)
 	}
 
 	if err != nil {
-		logger.Error("🚨 CRITICAL: Failed to place Maker Exit Order after retries!", "buyOrderID", tx.ID)
-		s.TelegramService.SendMessage(fmt.Sprintf("🚨 CRITICAL: Failed to place Maker Exit for Order %s. Please check manually!", tx.ID))
+		// A timeout or -1007 (execution status unknown) may still have left the exit on the book
+		placed, known := s.findPlacedExit(sellOrderID)
+		if placed != nil {
+			logger.Warn("🔗 Maker exit found on the book despite the placement error, keeping it", "id", tx.ID, "sellOrderID", sellOrderID, "status", placed.Status, "error", err)
+			resp, err = placed, nil
+			sellPriceStr = placed.Price
+		} else if known && exitRejected(err) && s.Cfg.ExitFallbackMode != "none" && s.exitFallback(ctx, tx, sellQty) {
+			return
+		}
+	}
 
-		// Mark as failed_placement so we know it needs manual intervention
-		tx.StatusTransaction = "failed_placement"
+	if err != nil {
+		logger.Error("🚨 CRITICAL: Failed to place Maker Exit Order after retries!", "buyOrderID", tx.ID)
+		tracing.Fail(span, err)
+
+		// Mark as failed_placement: the exit recovery retries it until its deadline (alerted
+		// once, on the first failure), without recovery it needs manual intervention
+		if tx.ExitFailedAt == nil {
+			now := time.Now()
+			tx.ExitFailedAt = &now
+			data := service.ExitFailedMessageData{ID: tx.ID}
+			if s.Cfg.ExitRecoveryIntervalMin > 0 {
+				data.RetryMin = s.Cfg.ExitRecoveryIntervalMin
+				data.Deadline = now.Add(time.Duration(s.Cfg.ExitRecoveryDeadlineMin) * time.Minute).Format("15:04")
+			}
+			s.Notifier.NotifyTemplate(service.CategoryError, service.SeverityCritical, service.TemplateExitFailed, data)
+		}
+		s.transition(tx, model.StatusFailed, "maker exit placement failed")
 		s.TransactionRepo.Update(*tx)
 		return
 	}
 
-	logger.Info("✅ Maker Exit Order Placed", "sellOrderID", resp.OrderId, "price", sellPriceStr)
+	logger.Info("✅ Maker Exit Order Placed", "sellOrderID", resp.OrderId, "price", sellPriceStr, "trace_id", tracing.TraceID(ctx))
 
 	// 4. Persistence
 	tx.SellOrderID = resp.ClientOrderId // Or resp.OrderId (int) converted to string? Model has string.
 	// Usually ClientOrderId is reliable if we set it.
-	tx.SellPrice = targetPrice
+	tx.SellPrice, _ = strconv.ParseFloat(sellPriceStr, 64) // Level actually placed (tick-rounded, clash-offset)
 	tx.SellCreatedAt = time.Now()
-	tx.StatusTransaction = "waiting_sell"
+	tx.ExitSpacingPct = dynamicSpacing
+	tx.ExitFailedAt, tx.ExitRecoveries = nil, 0
+	s.transition(tx, model.StatusExitPlaced, "maker exit placed: "+tx.SellOrderID)
 
 	s.TransactionRepo.Update(*tx)
 }
 
-const (
-	FeeRateBNB = 0.00075 // 0.075%
-	FeeRateStd = 0.00100 // 0.10%
-	BNBBuffer  = 1.1     // 10% safety buffer
-)
-
 func (s *Strategy) checkTakeProfit(filledOrders, openOrders []model.Transaction, currentBid, bnbPrice float64) bool {
 	if len(filledOrders) == 0 {
 		return false
@@ -473,8 +657,8 @@ func (s *Strategy) checkTakeProfit(filledOrders, openOrders []model.Transaction,
 	// Simplify logic for decision: Just check if Total Profit > Required
 	// Fees: Taker Fee (0.1% or similar).
 	// We need to estimate fee to know if it's profitable.
-	// We will assume standard fee for calculation check.
-	estExitFee := grossValue * s.Cfg.TakerFeePct
+	// Market exit: the taker rate of the pair's fee model.
+	estExitFee := grossValue * fees.For(s.Cfg).Taker
 	netUSDT := grossValue - estExitFee
 	totalProfit := netUSDT - totalCost
 	requiredProfit := totalCost * s.Cfg.MinNetProfitPct
@@ -485,15 +669,28 @@ func (s *Strategy) checkTakeProfit(filledOrders, openOrders []model.Transaction,
 		// 1. Create Sell Order on Binance
 		// We sell the total accumulated quantity.
 		side := "SELL"
-		qtyStr := fmt.Sprintf("%.8f", totalQty)
+		qtyStr := s.normalizer.FormatQty(s.normalizer.FloorQty(totalQty))
 
+		sellOrderID := newTakeProfitOrderID()
+		s.Executions.Expect(sellOrderID, currentBid)
 		req := api.OrderRequest{
 			Symbol:           s.Cfg.Symbol,
 			Side:             side,
 			Type:             "MARKET", // Taker execution for immediate exit
 			Quantity:         qtyStr,
-			NewClientOrderID: fmt.Sprintf("SELL_%d", time.Now().UnixMilli()),
+			NewClientOrderID: sellOrderID,
+		}
+		closes := make([]string, len(ordersToClose))
+		for i, order := range ordersToClose {
+			closes[i] = order.ID
 		}
+		// One sell closes several positions: it is its own correlation ID and lists them
+		audit.Intent(sellOrderID, sellOrderID, "take_profit", audit.Fields{
+			"net_profit": totalProfit,
+			"required":   requiredProfit,
+			"qty":        qtyStr,
+			"closes":     closes,
+		})
 
 		resp, err := s.Binance.CreateOrder(req)
 		if err != nil {
@@ -508,6 +705,7 @@ func (s *Strategy) checkTakeProfit(filledOrders, openOrders []model.Transaction,
 		for _, oOrder := range openOrders {
 			// Cancel order on Binance
 			logger.Info("🧹 Canceling Zombie Order", "orderID", oOrder.ID, "price", oOrder.Price)
+			audit.Intent(oOrder.ID, oOrder.ID, "cancel_buy", audit.Fields{"reason": "take profit " + sellOrderID})
 			_, err := s.Binance.CancelOrder(s.Cfg.Symbol, oOrder.ID)
 			if err != nil {
 				// We log error but continue to clear.
@@ -521,6 +719,12 @@ func (s *Strategy) checkTakeProfit(filledOrders, openOrders []model.Transaction,
 		// "removemos todas as makers que fazem parte da que agrediram a taker"
 		// "processo esta completo... começamos um novo"
 		// This implies the current cycle is closed.
+		for i := range ordersToClose {
+			s.transition(&ordersToClose[i], model.StatusClosed, "take profit (taker): "+resp.ClientOrderId)
+		}
+		for i := range openOrders {
+			s.transition(&openOrders[i], model.StatusCanceled, "take profit (taker): zombie canceled")
+		}
 		if err := s.TransactionRepo.Clear(); err != nil {
 			logger.Error("Failed to clear transactions", "error", err)
 		}
@@ -534,8 +738,8 @@ func (s *Strategy) checkTakeProfit(filledOrders, openOrders []model.Transaction,
 			Symbol:            s.Cfg.Symbol,
 			Type:              "sell",
 			Amount:            resp.ExecutedQty,
-			Price:             fmt.Sprintf("%.2f", currentBid), // Use bid or actual fill price from resp
-			StatusTransaction: "filled",
+			Price:             s.normalizer.FormatPrice(currentBid), // Use bid or actual fill price from resp
+			StatusTransaction: model.StatusFilled,
 			Notes:             fmt.Sprintf("TAKER PROFIT: $%.4f", totalProfit),
 			CreatedAt:         time.Now(),
 		}
@@ -555,15 +759,15 @@ func (s *Strategy) checkTakeProfit(filledOrders, openOrders []model.Transaction,
 		}
 		if totalFilledQty > 0 {
 			avgPrice := totalVal / totalFilledQty
-			sellTx.Price = fmt.Sprintf("%.2f", avgPrice)
+			sellTx.Price = s.normalizer.FormatPrice(avgPrice)
 		}
 		sellTx.Fee = fmt.Sprintf("%.8f", totalComm)
 
 		// Notify Telegram
-		finalUSDT := s.getBalance("USDT") // This might be stale until next sync, but okay.
+		finalUSDT := s.getBalance(s.Cfg.QuoteAsset) // This might be stale until next sync, but okay.
 		finalBNB := s.getBalance("BNB")
-		finalBTC := s.getBalance("BTC")
-		s.TelegramService.SendTradeNotification(sellTx, totalProfit, ordersToClose, finalUSDT, finalBNB, finalBTC)
+		finalBTC := s.getBalance(s.Cfg.BaseAsset)
+		s.Notifier.NotifyTrade(sellTx, totalProfit, ordersToClose, finalUSDT, finalBNB, finalBTC)
 
 		return true
 	}
@@ -577,6 +781,17 @@ func (s *Strategy) placeNewGridOrders(openOrders, filledOrders []model.Transacti
 		return
 	}
 
+	// Trading windows: no new entries, or smaller ones, in the configured hours
+	sizeFactor, allowed := s.tradingWindowGate()
+	if !allowed {
+		return
+	}
+
+	// Macro calendar: no new entries around the high-impact releases (FOMC, CPI...)
+	if !s.macroGate() {
+		return
+	}
+
 	allOrders := append(openOrders, filledOrders...)
 
 	// Sort by price ascending to find lowest/highest for different logic
@@ -610,8 +825,14 @@ func (s *Strategy) placeNewGridOrders(openOrders, filledOrders []model.Transacti
 	isGridEmptyOfBuys := len(activeBuyOrders) == 0
 	priceInRange := currentAsk >= s.Cfg.RangeMin && currentAsk <= s.Cfg.RangeMax
 
-	// DYNAMIC SPREAD via Volatility Service
-	dynamicSpacing := s.VolatilityService.GetDynamicSpacing()
+	// DYNAMIC SPREAD via Volatility Service (or the fixed spacing of the price's zone)
+	dynamicSpacing := s.spacingAt(currentAsk)
+
+	// Grid zones: outside them no new entries
+	zone := s.zoneAt(currentAsk)
+	if len(s.Cfg.GridZones) > 0 && zone == nil {
+		return
+	}
 
 	// Logic: Buy if (No Active Buys currently) OR (Price dropped enough below lowest active buy)
 	if priceInRange && (isGridEmptyOfBuys || dropPct >= dynamicSpacing) {
@@ -640,14 +861,36 @@ func (s *Strategy) placeNewGridOrders(openOrders, filledOrders []model.Transacti
 			// Using currentAsk triggers Taker execution immediately on LIMIT buys.
 			executionPrice := currentBid // Was currentAsk
 
+			// PLACEMENT_MODE=support: rest the buy just below the nearest support in reach
+			if s.Supports != nil {
+				if price, support, ok := s.Supports.SnapBuy(currentBid); ok {
+					executionPrice = price
+					logger.Info("🧲 Buy snapped below support", "bid", currentBid, "support", support, "price", price)
+				}
+			}
+
 			currentLevel := len(allOrders) + 1
 
-			// Calculate Order Value
-			// Calculate Order Value
-			saldoUSDT := s.getBalance("USDT")
+			// Calculate Order Value (only over the USDT above the reserve)
+			saldoUSDT := s.deployableUSDT()
 			orderValue := s.calculateOrderValue(saldoUSDT)
+			if zone != nil {
+				var ok bool
+				if orderValue, ok = s.zoneOrderValue(*zone, allOrders); !ok {
+					return
+				}
+			}
+			orderValue = math.Max(orderValue*sizeFactor, s.Cfg.MinOrderValue)
 
 			if saldoUSDT >= orderValue {
+				// Order-book imbalance: wait while the ask side overwhelms the bid side
+				if !s.bookImbalanceGate() {
+					return
+				}
+				if !s.fundFromEarn(orderValue) {
+					return
+				}
+
 				// Calculate Qty base on Price
 				// For Limit order, we use 'executionPrice'. Assuming we want to buy NOW at market basically?
 				// Grid usually places Limit orders below market.
@@ -659,17 +902,13 @@ func (s *Strategy) placeNewGridOrders(openOrders, filledOrders []model.Transacti
 				// User strategy seems to be "Buy the dip" via immediate orders when price trigger is hit.
 				// Let's use LIMIT GTC at currentAsk.
 
-				// NOTIONAL FIX: Calculate qty ensuring notional >= $5 (Binance min)
-				// Use math.Ceil to round UP, preventing truncation that causes NOTIONAL errors
-				minNotional := 5.0
-				minQtyForNotional := minNotional / executionPrice
-				buyQty := math.Ceil(minQtyForNotional*100000) / 100000 // Round UP to 5 decimals
+				buyQty := s.buyQuantity(orderValue, executionPrice)
 
 				// 1. Create Buy Order (Maker/Position Entry) on Binance
-				qtyStr := fmt.Sprintf("%.5f", buyQty)
+				qtyStr := s.normalizer.FormatQty(buyQty)
 
-				priceStr := fmt.Sprintf("%.2f", executionPrice)
-				clientOrderID := fmt.Sprintf("BUY_%d_L%d", time.Now().UnixMilli(), currentLevel)
+				priceStr := s.normalizer.BuyPrice(executionPrice)
+				clientOrderID := newBuyOrderID(currentLevel)
 
 				req := api.OrderRequest{
 					Symbol: s.Cfg.Symbol,
@@ -682,6 +921,27 @@ func (s *Strategy) placeNewGridOrders(openOrders, filledOrders []model.Transacti
 				}
 
 				logger.Info("Attempting to Place Order", "qty", qtyStr, "price", priceStr)
+				s.Executions.Expect(clientOrderID, executionPrice) // The bid that triggered the buy (or its support snap)
+				// Root of the position trace: from the ticker that triggered the buy to its exit
+				ctx, span := tracing.StartAt(context.Background(), "grid.buy", s.tickAt,
+					attribute.String("order_id", clientOrderID),
+					attribute.Int("level", currentLevel),
+					attribute.Float64("drop_pct", dropPct),
+					attribute.Float64("spacing_pct", dynamicSpacing),
+					attribute.String("price", priceStr),
+					attribute.String("qty", qtyStr),
+				)
+				defer span.End()
+				// The transaction takes the client order ID, so it is already the correlation ID
+				audit.Intent(clientOrderID, clientOrderID, "place_buy", audit.Fields{
+					"price":       priceStr,
+					"qty":         qtyStr,
+					"level":       currentLevel,
+					"drop_pct":    dropPct,
+					"spacing_pct": dynamicSpacing,
+					"order_value": orderValue,
+					"regime":      s.VolatilityService.GetRegime(),
+				})
 
 				// 3. Execution with Retry (Smart Logic for -2010)
 				var resp *api.OrderResponse
@@ -690,7 +950,10 @@ func (s *Strategy) placeNewGridOrders(openOrders, filledOrders []model.Transacti
 
 				for i := 0; i < maxRetries; i++ {
 					req.Price = priceStr // Ensure reset on retry loop
-					resp, err = s.Binance.CreateOrder(req)
+					err = tracing.Call(ctx, "binance.create_order", func() (err error) {
+						resp, err = s.Binance.CreateOrder(req)
+						return err
+					}, attribute.String("client_order_id", clientOrderID), attribute.Int("attempt", i+1))
 
 					if err == nil {
 						break // Success
@@ -705,25 +968,15 @@ func (s *Strategy) placeNewGridOrders(openOrders, filledOrders []model.Transacti
 					// Smart Backoff & Price Adjustment
 					time.Sleep(time.Duration(200+(i*100)) * time.Millisecond)
 
-					// Adjust Price: Decrease strictly to avoid Taker
-					if s.tickSize > 0 {
-						p, _ := strconv.ParseFloat(priceStr, 64)
-						// CRASH FIX: If price is falling fast, 1 tick is not enough.
-						// We need to back off significantly to be a MAKER.
-						// Let's drop 0.05% per retry. This is aggressive but guarantees placement.
-						// 87000 * 0.0005 = $43.
-						// If user wants to catch the knife, catching it $40 lower is better than failing.
-						dropStep := p * 0.0005 // 0.05%
-
-						newPrice := p - dropStep
-						priceStr = fmt.Sprintf("%.2f", newPrice)
-						logger.Info("📉 Adjusting Price (0.05%) for Retry", "old", req.Price, "new", priceStr)
-					}
+					// Adjust Price: follow the live book so the retry joins it as maker
+					priceStr = s.retryBuyPrice(priceStr, executionPrice, i)
+					logger.Info("📉 Adjusting Price for Retry", "old", req.Price, "new", priceStr)
 				}
 
 				if err != nil {
 					// Handle GTX Rejection (Post Only) caused by failure even after retries
 					logger.Error("❌ Failed to create Buy Order after retries. Pausing Buys for 60s.", "error", err)
+					tracing.Fail(span, err)
 					// CIRCUIT BREAKER: Pause buying to prevent ban/spam
 					s.lastBuyFailureTime = time.Now()
 					return
@@ -743,32 +996,36 @@ func (s *Strategy) placeNewGridOrders(openOrders, filledOrders []model.Transacti
 				// Response gives Status.
 
 				buyTx := model.Transaction{
-					ID:                resp.ClientOrderId, // Use what we sent or what they returned
-					TransactionID:     resp.ClientOrderId,
-					Symbol:            s.Cfg.Symbol,
-					Type:              "buy",
-					Amount:            resp.OrigQty, // Use confirmed qty
-					Price:             resp.Price,   // Use confirmed price
-					StatusTransaction: "open",       // Assume open, updated via stream later?
-					// If Status is FILLED, we mark as filled immediately?
-					// Code processFills() handles updates. But if it's already filled, processFills might not catch it if we check CurrentPrice vs OrderPrice?
-					// If filled immediately, we should mark filled.
-					Notes:     fmt.Sprintf("Grid L%d (Maker)", currentLevel),
-					CreatedAt: time.Now(),
-					UpdatedAt: time.Now(),
+					ID:            resp.ClientOrderId, // Use what we sent or what they returned
+					TransactionID: resp.ClientOrderId,
+					Symbol:        s.Cfg.Symbol,
+					Type:          "buy",
+					Amount:        resp.OrigQty, // Use confirmed qty
+					Price:         resp.Price,   // Use confirmed price
+					Notes:         fmt.Sprintf("Grid L%d (Maker)", currentLevel) + tracing.Note(ctx),
+					CreatedAt:     time.Now(),
+					UpdatedAt:     time.Now(),
+					EntryRegime:   s.VolatilityService.GetRegime(),
+					EntryLevel:    currentLevel,
 				}
+				// Open until the stream reports the fill (or filled right away, see below)
+				s.beginLifecycle(&buyTx, model.StatusOpen, fmt.Sprintf("grid buy placed (L%d)", currentLevel))
 
 				if resp.Status == "FILLED" {
-					buyTx.StatusTransaction = "filled"
+					s.transition(&buyTx, model.StatusFilled, "filled on creation")
 					// LOGIC FIX: Immediate Fill handling
 					// If filled immediately (e.g. matched hidden order or race condition despite GTX?), ensure Sell is placed.
 					// With GTX, this shouldn't happen often for "Maker", but if it does (e.g. auction), handle it.
 					logger.Info("⚡ Order filled immediately on creation - Placing Exit Order", "id", buyTx.ID)
-					s.placeMakerExitOrder(&buyTx)
+					s.placeMakerExitOrder(ctx, &buyTx)
 					// FIX: Notify User of Immediate Fill
 					s.sendTradeNotification(buyTx, 0, nil)
 				}
 
+				// An exit fallback may already have sold and archived it
+				if model.IsTerminal(buyTx.StatusTransaction) {
+					return
+				}
 				if err := s.TransactionRepo.Save(buyTx); err != nil {
 					logger.Error("Failed to save transaction", "error", err)
 				}
@@ -776,7 +1033,7 @@ func (s *Strategy) placeNewGridOrders(openOrders, filledOrders []model.Transacti
 				logger.Info("📌 Maker Transaction Recorded", "level", currentLevel)
 
 			} else {
-				logger.Warn("Insufficient funds for new order", "needed", orderValue, "have", saldoUSDT)
+				logger.Warn("Insufficient funds for new order", "needed", orderValue, "have", saldoUSDT, "reserve", s.Cfg.USDTReserve)
 				s.checkAndAlertLowUSDT(saldoUSDT, orderValue)
 			}
 		} else {
@@ -798,14 +1055,105 @@ func (s *Strategy) updateBalance(currency string, amount float64) {
 	s.BalanceRepo.Update(currency, current+amount)
 }
 
+// deployableUSDT is the free USDT the strategy may use, i.e. above the USDT_RESERVE floor
+// and excluding profits skimmed to the vault that are still in the Spot wallet, plus the USDT
+// parked in Simple Earn (redeemed by the buys that need it, see fundFromEarn)
+func (s *Strategy) deployableUSDT() float64 {
+	return s.walletDeployableUSDT() + s.earnParkedUSDT()
+}
+
+// walletDeployableUSDT is the part of deployableUSDT usable without a redemption (with what
+// margin buys may still borrow)
+func (s *Strategy) walletDeployableUSDT() float64 {
+	deployable := s.getBalance(s.Cfg.QuoteAsset) + s.marginBorrowable() - s.Cfg.USDTReserve - s.vaultReservedUSDT()
+	if deployable < 0 {
+		return 0
+	}
+	return deployable
+}
+
+// calculateOrderValue sizes an order as PositionSizePct of the sizing base: the free USDT,
+// or the persisted equity baseline (capital + realized profits) when COMPOUND_PROFITS is on.
+// SIZING_MODE=volatility scales it by the current volatility, kelly replaces PositionSizePct
+// with the capped Kelly fraction of the archived trades.
 func (s *Strategy) calculateOrderValue(balance float64) float64 {
-	rawOrderValue := balance * s.Cfg.PositionSizePct
+	base := balance
+	if s.Cfg.CompoundProfits && s.EquityRepo != nil && s.EquityRepo.Initialized() {
+		base = s.EquityRepo.Get().Equity()
+	}
+	rawOrderValue := base * s.Cfg.PositionSizePct
+	switch s.Cfg.SizingMode {
+	case "volatility":
+		rawOrderValue = s.volatilityTargetedValue(rawOrderValue)
+	case "kelly":
+		rawOrderValue = base * s.kellySizePct()
+	}
 	if rawOrderValue < s.Cfg.MinOrderValue {
 		return s.Cfg.MinOrderValue
 	}
 	return rawOrderValue
 }
 
+// buyQuantity converts an order value into a quantity, never below the symbol's min notional.
+// The order value only applies when the sizing was opted into (see sizesFromOrderValue);
+// otherwise every entry is placed at the min notional.
+// NOTIONAL FIX: rounds UP to the stepSize, preventing truncation that causes NOTIONAL errors.
+func (s *Strategy) buyQuantity(orderValue, price float64) float64 {
+	notional := s.normalizer.MinNotional()
+	if s.sizesFromOrderValue() {
+		notional = math.Max(orderValue, notional)
+	}
+	return s.normalizer.CeilQty(notional / price)
+}
+
+// sizesFromOrderValue reports whether entries are sized from the calculated order value:
+// SIZE_FROM_POSITION_PCT, COMPOUND_PROFITS, a SIZING_MODE other than flat or GRID_ZONES
+func (s *Strategy) sizesFromOrderValue() bool {
+	return s.Cfg.SizeFromPositionPct || s.Cfg.CompoundProfits || s.Cfg.SizingMode != "flat" || len(s.Cfg.GridZones) > 0
+}
+
+// initEquityBaseline seeds the compounding baseline on first run: COMPOUND_BASE_CAPITAL if set,
+// otherwise the deployable USDT plus the cost of the inventory currently held by the grid.
+func (s *Strategy) initEquityBaseline() {
+	if !s.Cfg.CompoundProfits || s.EquityRepo == nil {
+		return
+	}
+	if s.EquityRepo.Initialized() {
+		b := s.EquityRepo.Get()
+		logger.Info("📈 Compounding enabled", "base_capital", b.BaseCapital, "realized_profit", b.RealizedProfit, "equity", b.Equity())
+		return
+	}
+
+	base := s.Cfg.CompoundBaseCapital
+	if base <= 0 {
+		base = s.deployableUSDT()
+		for _, tx := range s.TransactionRepo.Find(s.gridBuys(model.StatusOpen, model.StatusFilled, model.StatusExitPlaced)) {
+			price, _ := strconv.ParseFloat(tx.Price, 64)
+			qty, _ := strconv.ParseFloat(tx.Amount, 64)
+			base += price * qty
+		}
+	}
+
+	if err := s.EquityRepo.Init(base); err != nil {
+		logger.Error("Failed to persist equity baseline", "error", err)
+	}
+}
+
+// recordRealizedProfit skims the vault share of a realized profit and feeds the
+// retained part into the compounding baseline
+func (s *Strategy) recordRealizedProfit(profit float64) {
+	retained := profit - s.skimProfit(profit)
+
+	if !s.Cfg.CompoundProfits || s.EquityRepo == nil || !s.EquityRepo.Initialized() {
+		return
+	}
+	if err := s.EquityRepo.AddProfit(retained); err != nil {
+		logger.Error("Failed to update equity baseline", "error", err)
+		return
+	}
+	logger.Info("📈 Equity baseline updated", "profit", retained, "equity", s.EquityRepo.Get().Equity())
+}
+
 func (s *Strategy) AnalyzeStartupState() {
 	logger.Info("🔄 Analyzing Startup State from transactions.json...")
 
@@ -818,7 +1166,7 @@ func (s *Strategy) AnalyzeStartupState() {
 
 	for _, tx := range transactions {
 		if tx.Symbol == s.Cfg.Symbol && tx.Type == "buy" {
-			if tx.StatusTransaction == "open" {
+			if tx.StatusTransaction == model.StatusOpen {
 				openBuyCount++
 				price, _ := strconv.ParseFloat(tx.Price, 64)
 				if price < lowestPrice {
@@ -827,7 +1175,7 @@ func (s *Strategy) AnalyzeStartupState() {
 				if price > highestPrice {
 					highestPrice = price
 				}
-			} else if tx.StatusTransaction == "filled" {
+			} else if tx.StatusTransaction == model.StatusFilled {
 				filledInventoryCount++
 				qty, _ := strconv.ParseFloat(tx.Amount, 64)
 				totalInventoryBTC += qty
@@ -871,6 +1219,8 @@ func (s *Strategy) SyncOrdersOnStartup() {
 	for _, bo := range binantOpenOrders {
 		binanceOrderMap[bo.ClientOrderId] = bo
 	}
+	dryRun := s.Cfg.SyncDryRun
+	report := startupReport{OpenOrders: len(binantOpenOrders), DryRun: dryRun}
 
 	// 2. Load Local Transactions
 	transactions := s.TransactionRepo.GetAll()
@@ -885,21 +1235,39 @@ func (s *Strategy) SyncOrdersOnStartup() {
 	// ===================================================================================
 	for clientID, binOrder := range binanceOrderMap {
 		if _, exists := localOrderMap[clientID]; !exists {
+			// Rebalancer orders are intentionally not tracked as grid transactions
+			if IsRebalanceOrder(clientID) {
+				continue
+			}
+
 			// Orphan Detected!
 
 			// DUPLICATE PREVENTER: Check if this "Orphan" Sell is actually linked to a Buy
 			if binOrder.Side == "SELL" {
-				isLinked := false
-				for _, localTx := range transactions {
-					if localTx.SellOrderID == clientID {
-						isLinked = true
-						break
-					}
-				}
-				if isLinked {
+				if s.TransactionRepo.IsLinkedExit(clientID) {
 					logger.Info("⚠️ Skipping import of Linked Sell Order (Already in DB as SellOrderID)", "id", clientID)
 					continue
 				}
+
+				// The exit names its buy: link it to the local buy (placed, link not persisted), or
+				// rebuild the buy from Binance when it is missing from the DB
+				if buyID, ok := exitBuyID(clientID); ok {
+					if _, exists := localOrderMap[buyID]; !exists && s.adoptExit(binOrder, buyID) {
+						report.Imported++
+						continue
+					}
+					if buyTx, exists := localOrderMap[buyID]; exists {
+						buyTx.SellOrderID = clientID
+						buyTx.SellPrice, _ = strconv.ParseFloat(binOrder.Price, 64)
+						if buyTx.StatusTransaction == model.StatusFilled {
+							s.transition(buyTx, model.StatusExitPlaced, "startup sync: exit linked by client order id")
+						}
+						s.TransactionRepo.Update(*buyTx)
+						logger.Info("🔗 Sell Order linked to its buy by client order ID", "sellID", clientID, "buyID", buyID)
+						report.Linked++
+						continue
+					}
+				}
 			}
 
 			logger.Warn("👻 Orphan Order Detected on Binance (Not in DB). Importing...", "id", clientID, "price", binOrder.Price)
@@ -917,7 +1285,7 @@ func (s *Strategy) SyncOrdersOnStartup() {
 				Type:              txType,
 				Amount:            binOrder.OrigQty,
 				Price:             binOrder.Price,
-				StatusTransaction: "open", // It's in OpenOrders, so it MUST be open
+				StatusTransaction: model.StatusOpen, // It's in OpenOrders, so it MUST be open
 				Notes:             "Recovered during Startup Sync",
 				CreatedAt:         time.Unix(binOrder.TransactTime/1000, 0),
 				UpdatedAt:         time.Now(),
@@ -927,6 +1295,7 @@ func (s *Strategy) SyncOrdersOnStartup() {
 				logger.Error("Failed to save imported orphan order", "error", err)
 			} else {
 				logger.Info("✅ Orphan Order Imported Successfully", "id", newTx.ID)
+				report.Imported++
 			}
 		}
 	}
@@ -942,13 +1311,19 @@ func (s *Strategy) SyncOrdersOnStartup() {
 
 	// We iterate over the original list + imports? No, just iterate repo again or map.
 	// Let's iterate current state of Repo to be safe.
-	currTransactions := s.TransactionRepo.GetAll()
-
-	for _, tx := range currTransactions {
+	var closedOffline []model.Transaction
+	var queries []orderQuery
+	for _, tx := range s.TransactionRepo.GetAll() {
 		// We only care about reconciling 'open' or 'waiting_sell' orders
-		if tx.StatusTransaction != "open" && tx.StatusTransaction != "waiting_sell" {
+		if tx.StatusTransaction != model.StatusOpen && tx.StatusTransaction != model.StatusExitPlaced {
 			continue
 		}
+		// Exit still live on Binance: nothing to reconcile
+		if sellID, linked := s.TransactionRepo.ExitOf(tx.ID); tx.StatusTransaction == model.StatusExitPlaced && linked {
+			if _, ok := binanceOrderMap[sellID]; ok {
+				continue
+			}
+		}
 
 		// Check if this local order exists in the Binance Open Orders list
 		_, isOpenOnBinance := binanceOrderMap[tx.ID]
@@ -958,13 +1333,20 @@ func (s *Strategy) SyncOrdersOnStartup() {
 			// Optional: Update Price/Qty if modified? Usually not for Limit.
 			continue
 		}
+		closedOffline = append(closedOffline, tx)
+		queries = append(queries, entryQuery(tx))
+	}
 
+	// After a long downtime there may be dozens: a few allOrders pages instead of one call each
+	lookup := s.newOrderLookup(queries)
+
+	for _, tx := range closedOffline {
 		// IF WE ARE HERE: Order is OPEN locally, but NOT in Binance Open Orders.
 		// Conclusion: It was Filled, Canceled, or Expired while we were offline.
 		logger.Info("🔄 Order missed from OpenOrders list (Closed offline). Checking status...", "id", tx.ID)
 
 		// Fetch specific order details to know exact final state
-		resp, err := s.Binance.GetOrder(tx.Symbol, tx.ID)
+		resp, err := lookup.get(tx.Symbol, tx.ID)
 		if err != nil {
 			logger.Error("⚠️ Failed to check status of missing order", "id", tx.ID, "error", err)
 			// Decide: Keep as open? Or mark unknown? Keep open to retry next sync.
@@ -973,7 +1355,11 @@ func (s *Strategy) SyncOrdersOnStartup() {
 
 		// Update Local State
 		if resp.Status == "FILLED" {
-			tx.StatusTransaction = "filled"
+			if tx.Type == "sell" {
+				// Standalone sell: executing it ends the lifecycle (handled below)
+			} else if !s.transition(&tx, model.StatusFilled, "startup sync: filled offline") {
+				continue
+			}
 			tx.Price = resp.Price
 			if resp.ExecutedQty != "" {
 				tx.Amount = resp.ExecutedQty
@@ -992,46 +1378,29 @@ func (s *Strategy) SyncOrdersOnStartup() {
 				// Check if we already have a sell order linked or orphan.
 				// This handles cases where we created the order offline.
 
-				var foundSellID string
-				buyQtyFloat, _ := strconv.ParseFloat(resp.ExecutedQty, 64)
-
-				// 1. Check strict link
-				if tx.SellOrderID != "" {
-					if _, ok := binanceOrderMap[tx.SellOrderID]; ok {
-						foundSellID = tx.SellOrderID
-						logger.Info("🔗 Startup Relinking: Existing Sell Order found by ID.", "sellID", foundSellID)
-					}
-				}
-
-				// 2. Scan for Orphan Sell Orders (Match by Quantity)
-				if foundSellID == "" {
-					for _, bo := range binanceOrderMap {
-						if bo.Side == "SELL" {
-							sellQtyFloat, _ := strconv.ParseFloat(bo.OrigQty, 64)
-							if math.Abs(sellQtyFloat-buyQtyFloat) < 0.00000001 {
-								foundSellID = bo.ClientOrderId
-								logger.Info("🔗 Startup Relinking: Matching Orphan Sell Order found by Quantity.", "sellID", foundSellID)
-								break
-							}
-						}
-					}
+				foundSellID, how := s.findExitOnBook(tx, binanceOrderMap)
+				if foundSellID != "" {
+					logger.Info("🔗 Startup Relinking: Existing Sell Order found.", "sellID", foundSellID, "by", how)
 				}
 
 				if foundSellID != "" {
 					tx.SellOrderID = foundSellID
-					tx.StatusTransaction = "waiting_sell" // Or filled? waiting_sell implies we are waiting for it to fill. Correct.
-					tx.UpdatedAt = time.Now()
+					tx.SellPrice, _ = strconv.ParseFloat(binanceOrderMap[foundSellID].Price, 64)
+					s.transition(&tx, model.StatusExitPlaced, "startup sync: relinked exit "+foundSellID)
 					s.TransactionRepo.Update(tx)
 					logger.Info("✅ Startup Sync: Linked existing Sell Order.", "buyID", tx.ID, "sellID", foundSellID)
+					report.Linked++
 				} else {
 					logger.Info("🚀 Startup Sync: Triggering Maker Exit for Offline Fill", "buyID", tx.ID)
-					s.placeMakerExitOrder(&tx)
+					s.placeMakerExitOrder(tracing.FromNotes(tx.Notes), &tx)
 				}
 			}
 
 			// ACTION: If it was a SELL (Maker Exit), calculate profit
 			if tx.Type == "sell" {
-				tx.StatusTransaction = "closed"
+				if !s.transition(&tx, model.StatusClosed, "startup sync: sold offline") {
+					continue
+				}
 				now := time.Now()
 				tx.ClosedAt = &now
 				tx.Notes += " | Sold Offline"
@@ -1041,12 +1410,13 @@ func (s *Strategy) SyncOrdersOnStartup() {
 			}
 
 		} else if resp.Status == "CANCELED" || resp.Status == "EXPIRED" || resp.Status == "REJECTED" {
-			// If it was CANCELED, we mark it closed (or removed).
-			tx.StatusTransaction = "closed" // Or "cancelled" if we had that status
+			if !s.transition(&tx, terminalStatusFor(tx), "startup sync: "+resp.Status+" offline") {
+				continue
+			}
 			tx.Notes += fmt.Sprintf(" | Synced (%s Offline)", resp.Status)
-			tx.UpdatedAt = time.Now()
 			s.TransactionRepo.Update(tx)
 			logger.Warn("⚠️ Order Synced: CANCELED/EXPIRED Offline", "id", tx.ID, "status", resp.Status)
+			report.CanceledOffline++
 
 			// If it was a Maker Exit that got Canceled, do we need to replace it?
 			// Maybe. But for safety, we mark closed. Next strategy cycle might not see it.
@@ -1059,6 +1429,7 @@ func (s *Strategy) SyncOrdersOnStartup() {
 	}
 
 	logger.Info("✅ Startup Sync Phase 2 Completed", "synced_updates", syncedCount)
+	report.FilledOffline = syncedCount
 
 	// ===================================================================================
 	// PHASE 3: GHOST TRANSACTION CLEANUP
@@ -1066,13 +1437,13 @@ func (s *Strategy) SyncOrdersOnStartup() {
 	// on Binance anymore, the sell was completed and we should archive it.
 	// Also cleans failed_placement entries.
 	// ===================================================================================
-	s.purgeGhostTransactions(binanceOrderMap)
+	report.Purged = s.purgeGhostTransactions(binanceOrderMap, dryRun)
 
 	// ===================================================================================
 	// PHASE 4: DUPLICATE TRANSACTION CLEANUP
 	// Removes standalone "SELL" transactions that are already linked to a "BUY"
 	// ===================================================================================
-	s.purgeDuplicateTransactions()
+	report.Duplicates = s.purgeDuplicateTransactions(dryRun)
 
 	// ===================================================================================
 	// PHASE 5: ZOMBIE RESCUE (Naked Buys)
@@ -1080,18 +1451,27 @@ func (s *Strategy) SyncOrdersOnStartup() {
 	// Action: Attempts to place the missing Exit Order.
 	// If Insufficient Balance (already sold manually?), archives and cleans up.
 	// ===================================================================================
-	s.rescueZombieTransactions()
+	report.ZombiesRescued, report.ZombiesCleaned = s.rescueZombieTransactions(dryRun)
+
+	if dryRun && report.Purged+report.Duplicates+report.ZombiesRescued+report.ZombiesCleaned > 0 {
+		s.setCleanupPending(true)
+		logger.Warn("🔍 SYNC_DRY_RUN: startup cleanup only previewed. Confirm with /cleanup confirm or restart with -confirm-cleanup",
+			"purge", report.Purged, "duplicates", report.Duplicates, "rescue", report.ZombiesRescued, "archive", report.ZombiesCleaned)
+	}
+	s.sendStartupReport(report)
 }
 
-// rescueZombieTransactions finds "Filled" Buys without SellOrderID and tries to fix them
-func (s *Strategy) rescueZombieTransactions() {
+// rescueZombieTransactions finds "Filled" Buys without SellOrderID and tries to fix them.
+// Returns how many got an exit placed and how many were archived as already sold (with
+// dryRun, how many would be: nothing is placed or archived).
+func (s *Strategy) rescueZombieTransactions(dryRun bool) (int, int) {
 	logger.Info("🧟 Phase 5: Checking for Zombie Transactions (Filled Buys without Exit)...")
 	transactions := s.TransactionRepo.GetAll()
-	var rescueCount int
+	var rescueCount, cleanedCount int
 
 	for _, tx := range transactions {
 		// Criteria: Buy + Filled + Empty SellOrderID
-		if tx.Type == "buy" && tx.StatusTransaction == "filled" && tx.SellOrderID == "" {
+		if tx.Type == "buy" && tx.StatusTransaction == model.StatusFilled && tx.SellOrderID == "" {
 			logger.Warn("🧟 Zombie Detected! Filled Buy with no Exit Order.", "id", tx.ID, "price", tx.Price)
 
 			// Attempt to Rescue: Place Exit Order
@@ -1104,53 +1484,63 @@ func (s *Strategy) rescueZombieTransactions() {
 			// BUT, to archive it if failed, we need feedback.
 
 			// Custom Logic for Rescue:
-			balance := s.getBalance("BTC")
+			balance := s.getBalance(s.Cfg.BaseAsset)
 			qty, _ := strconv.ParseFloat(tx.Amount, 64)
 
 			// Safety factor 0.999 is used in placeMakerExitOrder, let's verify here first?
 			if balance < qty*0.99 {
 				logger.Warn("🧟 Zombie Rescue Failed: Insufficient BTC Balance. Assuming manually sold.", "id", tx.ID, "needed", qty, "have", balance)
+				if dryRun {
+					logger.Warn("🔍 Dry run: would archive zombie as sold", "id", tx.ID, "price", tx.Price, "qty", tx.Amount)
+					cleanedCount++
+					continue
+				}
 
 				// Archive & Delete (It's a Ghost/Lost order)
-				tx.StatusTransaction = "closed"
+				s.transition(&tx, model.StatusClosed, "zombie cleaned (assumed sold)")
 				tx.Notes += " | Zombie Cleaned (Insufficient Balance - Assumed Sold)"
 				s.TransactionRepo.Archive(tx)
 				s.TransactionRepo.Delete(tx.ID)
+				cleanedCount++
 				continue
 			}
 
 			// If we have balance, we try to place the order
+			if dryRun {
+				logger.Warn("🔍 Dry run: would place the missing exit", "id", tx.ID, "price", tx.Price, "qty", tx.Amount)
+				rescueCount++
+				continue
+			}
 			logger.Info("🚑 Attempting Zombie Rescue: Placing Exit Order...", "id", tx.ID)
-			s.placeMakerExitOrder(&tx)
+			s.placeMakerExitOrder(tracing.FromNotes(tx.Notes), &tx)
 			rescueCount++
 		}
 	}
 
 	if rescueCount > 0 {
-		logger.Info("✅ Zombie Rescue Operations Triggered", "count", rescueCount)
+		logger.Info("✅ Zombie Rescue Operations Triggered", "count", rescueCount, "dry_run", dryRun)
 	} else {
 		logger.Info("✅ No Zombie Transactions found")
 	}
+	return rescueCount, cleanedCount
 }
 
 // purgeDuplicateTransactions removes 'sell' type transactions that are already present as SellOrderID in a 'buy' transaction
-func (s *Strategy) purgeDuplicateTransactions() {
+// (with dryRun only logs them)
+func (s *Strategy) purgeDuplicateTransactions(dryRun bool) int {
 	logger.Info("🧹 Phase 4: Checking for Duplicate Transactions...")
 	transactions := s.TransactionRepo.GetAll()
 
-	// Build map of linked SellIDs
-	linkedSellIDs := make(map[string]bool)
-	for _, tx := range transactions {
-		if tx.Type == "buy" && tx.SellOrderID != "" {
-			linkedSellIDs[tx.SellOrderID] = true
-		}
-	}
-
 	var matchCount int
 	for _, tx := range transactions {
 		if tx.Type == "sell" {
-			// Check if this Sell Transaction ID is used as a SellOrderID in any Buy
-			if linkedSellIDs[tx.ID] {
+			// Check if this Sell Transaction ID is the exit of any Buy
+			if s.TransactionRepo.IsLinkedExit(tx.ID) {
+				if dryRun {
+					logger.Warn("🔍 Dry run: would archive duplicate sell transaction", "id", tx.ID, "price", tx.Price, "qty", tx.Amount)
+					matchCount++
+					continue
+				}
 				logger.Info("👯 Duplicate Sell Transaction Detected. Archiving...", "id", tx.ID)
 
 				// Archive
@@ -1168,36 +1558,51 @@ func (s *Strategy) purgeDuplicateTransactions() {
 	}
 
 	if matchCount > 0 {
-		logger.Info("✅ Duplicate Cleanup Complete", "removed_count", matchCount)
+		logger.Info("✅ Duplicate Cleanup Complete", "removed_count", matchCount, "dry_run", dryRun)
 	} else {
 		logger.Info("✅ No duplicate transactions found")
 	}
+	return matchCount
 }
 
 // purgeGhostTransactions removes transactions that reference orders no longer on Binance.
 // This handles cases where sells were filled while bot was offline.
-func (s *Strategy) purgeGhostTransactions(binanceOrderMap map[string]api.OrderResponse) int {
+// With dryRun the decisions are only logged: nothing is archived, reset or placed.
+func (s *Strategy) purgeGhostTransactions(binanceOrderMap map[string]api.OrderResponse, dryRun bool) int {
 	logger.Info("🧹 Phase 3: Checking for Ghost Transactions...")
 
 	transactions := s.TransactionRepo.GetAll()
 	var purgedCount int
 
+	// The orders of cases 2 and 3 below, fetched in a few allOrders pages when there are many
+	var queries []orderQuery
+	for _, tx := range transactions {
+		if _, exists := binanceOrderMap[tx.SellOrderID]; tx.StatusTransaction == model.StatusFilled && tx.SellOrderID != "" && !exists {
+			queries = append(queries, exitQuery(tx))
+		}
+		if _, exists := binanceOrderMap[tx.ID]; tx.StatusTransaction == model.StatusOpen && tx.Type == "buy" && !exists {
+			queries = append(queries, entryQuery(tx))
+		}
+	}
+	lookup := s.newOrderLookup(queries)
+
 	for _, tx := range transactions {
 		shouldPurge := false
 		reason := ""
 
-		// Case 1: failed_placement - these never had valid orders
-		if tx.StatusTransaction == "failed_placement" {
+		// Case 1: failed_placement - these never had valid orders (left to the exit recovery
+		// until it escalates them)
+		if tx.StatusTransaction == model.StatusFailed && (s.Cfg.ExitRecoveryIntervalMin <= 0 || tx.ExitEscalated) {
 			shouldPurge = true
 			reason = "Failed Placement (Never had valid order)"
 		}
 
 		// Case 2: filled with SellOrderID - check if sell still exists
-		if tx.StatusTransaction == "filled" && tx.SellOrderID != "" {
+		if tx.StatusTransaction == model.StatusFilled && tx.SellOrderID != "" {
 			if _, exists := binanceOrderMap[tx.SellOrderID]; !exists {
 				// Sell order doesn't exist in open orders - it was either filled or canceled
 				// We need to query Binance to find out the actual status
-				resp, err := s.Binance.GetOrder(tx.Symbol, tx.SellOrderID)
+				resp, err := lookup.get(tx.Symbol, tx.SellOrderID)
 				if err != nil {
 					logger.Warn("⚠️ Cannot verify sell order status (API error). Keeping transaction.", "id", tx.ID, "sellID", tx.SellOrderID, "error", err)
 					continue
@@ -1211,27 +1616,35 @@ func (s *Strategy) purgeGhostTransactions(binanceOrderMap map[string]api.OrderRe
 					sellPrice, _ := strconv.ParseFloat(resp.Price, 64)
 					qty, _ := strconv.ParseFloat(tx.Amount, 64)
 					profit := (sellPrice - buyPrice) * qty
+					if !dryRun {
+						s.recordRealizedProfit(profit)
+						s.recordTrade(tx, sellPrice, time.Now(), "recovery")
+					}
 					tx.Notes += fmt.Sprintf(" | Sold at %.2f (Profit: $%.2f) [Ghost Recovery]", sellPrice, profit)
 				} else if resp.Status == "CANCELED" || resp.Status == "EXPIRED" {
 					// Sell order was canceled - we have exposure without exit!
 					// Don't purge, but reset to trigger new sell placement
+					if dryRun {
+						logger.Warn("🔍 Dry run: exit was canceled, would reset the position and place a new exit", "id", tx.ID, "sellID", tx.SellOrderID, "status", resp.Status)
+						continue
+					}
 					logger.Warn("⚠️ Ghost Sell Order was CANCELED. Resetting to trigger new exit.", "id", tx.ID, "sellID", tx.SellOrderID)
 					tx.SellOrderID = ""
-					tx.StatusTransaction = "filled"
+					s.transition(&tx, model.StatusFilled, "exit "+resp.Status+": needs new exit")
 					tx.Notes += " | Sell Canceled (Ghost Recovery: Needs New Exit)"
 					s.TransactionRepo.Update(tx)
 					// Immediately place new exit
-					s.placeMakerExitOrder(&tx)
+					s.placeMakerExitOrder(tracing.FromNotes(tx.Notes), &tx)
 					continue
 				}
 			}
 		}
 
 		// Case 3: open buy that doesn't exist on Binance and isn't FILLED
-		if tx.StatusTransaction == "open" && tx.Type == "buy" {
+		if tx.StatusTransaction == model.StatusOpen && tx.Type == "buy" {
 			if _, exists := binanceOrderMap[tx.ID]; !exists {
 				// Query to check actual status
-				resp, err := s.Binance.GetOrder(tx.Symbol, tx.ID)
+				resp, err := lookup.get(tx.Symbol, tx.ID)
 				if err != nil {
 					// Order truly doesn't exist - remove it
 					shouldPurge = true
@@ -1244,8 +1657,14 @@ func (s *Strategy) purgeGhostTransactions(binanceOrderMap map[string]api.OrderRe
 			}
 		}
 
+		if shouldPurge && dryRun {
+			logger.Warn("🔍 Dry run: would purge ghost transaction", "id", tx.ID, "status", tx.StatusTransaction, "price", tx.Price, "qty", tx.Amount, "reason", reason)
+			purgedCount++
+			continue
+		}
 		if shouldPurge {
 			logger.Info("📦 Purging Ghost Transaction", "id", tx.ID, "reason", reason)
+			s.transition(&tx, terminalStatusFor(tx), "ghost purge: "+reason)
 
 			// Archive first
 			if err := s.TransactionRepo.Archive(tx); err != nil {
@@ -1263,7 +1682,7 @@ func (s *Strategy) purgeGhostTransactions(binanceOrderMap map[string]api.OrderRe
 	}
 
 	if purgedCount > 0 {
-		logger.Info("✅ Ghost Cleanup Complete", "purged_count", purgedCount)
+		logger.Info("✅ Ghost Cleanup Complete", "purged_count", purgedCount, "dry_run", dryRun)
 	} else {
 		logger.Info("✅ No ghost transactions found")
 	}
@@ -1274,6 +1693,10 @@ func (s *Strategy) purgeGhostTransactions(binanceOrderMap map[string]api.OrderRe
 // PeriodicSyncOrders runs the ghost cleanup periodically (every 5 min)
 // to catch any orders that got filled between syncs
 func (s *Strategy) PeriodicSyncOrders() {
+	if s.CleanupPending() {
+		logger.Info("🔍 Periodic ghost cleanup skipped: the startup cleanup awaits confirmation (/cleanup confirm)")
+		return
+	}
 	logger.Info("🔄 Periodic Sync: Validating transactions against Binance...")
 
 	binanceOpenOrders, err := s.Binance.GetOpenOrders(s.Cfg.Symbol)
@@ -1287,7 +1710,7 @@ func (s *Strategy) PeriodicSyncOrders() {
 		binanceOrderMap[bo.ClientOrderId] = bo
 	}
 
-	purged := s.purgeGhostTransactions(binanceOrderMap)
+	purged := s.purgeGhostTransactions(binanceOrderMap, false)
 	if purged > 0 {
 		logger.Info("🧹 Periodic Sync: Cleaned up ghost transactions", "count", purged)
 	}
@@ -1299,7 +1722,7 @@ func (s *Strategy) checkAndAlertLowUSDT(currentBalance, required float64) {
 	}
 
 	logger.Warn("⚠️ Alerting Low USDT Balance", "balance", currentBalance, "required", required)
-	s.TelegramService.SendLowBalanceAlert("USDT", currentBalance, required)
+	s.Notifier.NotifyLowBalance(s.Cfg.QuoteAsset, currentBalance, required)
 	s.lastUSDTAlertTime = time.Now()
 }
 
@@ -1308,7 +1731,7 @@ func (s *Strategy) checkLowBNB(bnbPrice float64) {
 		return
 	}
 
-	saldoUSDT := s.getBalance("USDT")
+	saldoUSDT := s.deployableUSDT()
 	calculated := saldoUSDT * s.Cfg.PositionSizePct
 	if calculated < s.Cfg.MinOrderValue {
 		calculated = s.Cfg.MinOrderValue
@@ -1322,8 +1745,14 @@ func (s *Strategy) checkLowBNB(bnbPrice float64) {
 	if bnbValueUSDT < thresholdUSDT {
 		logger.Warn("⚠️ BNB Balance Low", "bnb_value_usdt", bnbValueUSDT, "threshold", thresholdUSDT)
 
+		// Auto top-up (optional). On success, the purchase notification replaces the alert.
+		if s.tryBNBTopUp() {
+			s.lastBNBAlertTime = time.Now()
+			return
+		}
+
 		thresholdBNB := thresholdUSDT / bnbPrice
-		s.TelegramService.SendLowBalanceAlert("BNB", bnbBalance, thresholdBNB)
+		s.Notifier.NotifyLowBalance("BNB", bnbBalance, thresholdBNB)
 
 		s.lastBNBAlertTime = time.Now()
 	}
@@ -1426,58 +1855,31 @@ func (s *Strategy) checkSmartEntryReposition(openOrders, filledOrders []model.Tr
 	// Safety: Ensure newPrice is actually higher than old price?
 	// Usually yes if diffPct is positive.
 
-	// 5. Execute Reposition
+	// 5. Execute Reposition (atomic cancelReplace: the level is never left without an order)
 
-	// A) Cancel Old Order
-	_, err = s.Binance.CancelOrder(s.Cfg.Symbol, highestOrder.ID)
-	if err != nil {
-		logger.Error("⚠️ Failed to cancel old order for reposition", "orderID", highestOrder.ID, "error", err)
-		// If failed (e.g. already filled), we stop.
-		// Check if it was filled?
-		return
-	}
+	// Size the new order. Recalculate based on Config PositionSizePct, as price changed,
+	// counting the capital released by the canceled order as available.
+	oldPrice, _ := strconv.ParseFloat(highestOrder.Price, 64)
+	oldQty, _ := strconv.ParseFloat(highestOrder.Amount, 64)
+	releasedUSDT := oldPrice * oldQty
 
-	// B) Update Old Order in Repo, Archive, and Delete
-	highestOrder.StatusTransaction = "closed"
-	highestOrder.Notes += " | Repositioned (Smart Entry)"
-
-	// Archive the canceled order to history
-	if err := s.TransactionRepo.Archive(*highestOrder); err != nil {
-		logger.Error("Failed to archive repositioned order", "error", err)
-	}
-
-	// Delete from active transactions
-	if err := s.TransactionRepo.Delete(highestOrder.ID); err != nil {
-		logger.Error("Failed to delete repositioned order", "error", err)
-	} else {
-		logger.Info("🗑️ Repositioned order archived and removed", "id", highestOrder.ID)
-	}
-
-	// C) Create New Order at CurrentBid (Maker Attempt)
-	// Reuse quantity/value logic?
-	// Use same quantity as old order? Or recalculate based on current balance?
-	// If we use same quantity, we preserve "Entry Size".
-	// If we recalculate, we fit valid size.
-	// Let's use the AMOUNT of the old order to be consistent?
-	// Or better: Recalculate based on Config PositionSizePct, as price changed.
-	// Let's Recalculate to be safe with MinOrderValue etc.
-
-	saldoUSDT := s.getBalance("USDT")
+	saldoUSDT := s.deployableUSDT() + releasedUSDT
 	orderValue := s.calculateOrderValue(saldoUSDT)
 
-	// Logic from placeNewGridOrders
 	if saldoUSDT < orderValue {
-		logger.Warn("Insufficient funds for Reposition", "needed", orderValue, "have", saldoUSDT)
+		logger.Warn("Insufficient funds for Reposition", "needed", orderValue, "have", saldoUSDT, "reserve", s.Cfg.USDTReserve)
+		return
+	}
+	if !s.fundFromEarn(orderValue - releasedUSDT) {
 		return
 	}
 
-	// NOTIONAL FIX: Calculate qty ensuring notional >= $5 (Binance min)
-	minNotional := 5.0
-	minQtyForNotional := minNotional / newPrice
-	buyQty := math.Ceil(minQtyForNotional*100000) / 100000 // Round UP to 5 decimals
-	qtyStr := fmt.Sprintf("%.5f", buyQty)
+	buyQty := s.buyQuantity(orderValue, newPrice)
+	qtyStr := s.normalizer.FormatQty(buyQty)
 
-	newClientOrderID := fmt.Sprintf("BUY_R_%d", time.Now().UnixMilli())
+	// The new buy keeps the level of the one it replaces
+	level, _ := OrderLevel(highestOrder.ID)
+	newClientOrderID := newBuyOrderID(level)
 
 	req := api.OrderRequest{
 		Symbol:           s.Cfg.Symbol,
@@ -1489,32 +1891,75 @@ func (s *Strategy) checkSmartEntryReposition(openOrders, filledOrders []model.Tr
 		NewClientOrderID: newClientOrderID,
 	}
 
-	logger.Info("🔄 Placing Reposition Order (Maker Attempt)", "price", newPriceStr, "qty", qtyStr)
+	logger.Info("🔄 Replacing Order (cancelReplace)", "oldID", highestOrder.ID, "price", newPriceStr, "qty", qtyStr)
+	audit.Intent(newClientOrderID, newClientOrderID, "reposition_buy", audit.Fields{
+		"replaces":  highestOrder.ID,
+		"reason":    triggerReason,
+		"old_price": highestOrder.Price,
+		"price":     newPriceStr,
+		"qty":       qtyStr,
+	})
+	ctx, span := tracing.StartAt(context.Background(), "grid.reposition", s.tickAt,
+		attribute.String("order_id", newClientOrderID),
+		attribute.String("replaces", highestOrder.ID),
+		attribute.String("reason", triggerReason),
+		attribute.String("price", newPriceStr),
+		attribute.String("qty", qtyStr),
+	)
+	defer span.End()
+
+	var result *api.CancelReplaceResponse
+	err = tracing.Call(ctx, "binance.cancel_replace", func() (err error) {
+		result, err = s.Binance.CancelReplaceOrder(highestOrder.ID, req)
+		return err
+	}, attribute.String("client_order_id", newClientOrderID))
+	if !result.CancelSucceeded() {
+		// Old order untouched (e.g. already filled). WS/sync will reconcile it.
+		logger.Error("⚠️ Failed to cancel old order for reposition", "orderID", highestOrder.ID, "error", err)
+		tracing.Fail(span, err)
+		return
+	}
+
+	// A) Old order is gone: Archive and Delete it
+	s.transition(highestOrder, model.StatusCanceled, "repositioned (smart entry)")
+	highestOrder.Notes += " | Repositioned (Smart Entry)"
 
-	resp, err := s.Binance.CreateOrder(req)
-	if err != nil {
-		logger.Error("❌ Failed to create Reposition Order", "error", err)
+	if err := s.TransactionRepo.Archive(*highestOrder); err != nil {
+		logger.Error("Failed to archive repositioned order", "error", err)
+	}
+	if err := s.TransactionRepo.Delete(highestOrder.ID); err != nil {
+		logger.Error("Failed to delete repositioned order", "error", err)
+	} else {
+		logger.Info("🗑️ Repositioned order archived and removed", "id", highestOrder.ID)
+	}
+
+	if !result.NewOrderSucceeded() {
+		// Rare with STOP_ON_FAILURE (e.g. filter error on the new order). The slot is re-filled by normal placement.
+		s.updateBalance(s.Cfg.QuoteAsset, releasedUSDT)
+		logger.Error("❌ Failed to create Reposition Order (old order canceled)", "error", err)
+		tracing.Fail(span, err)
 		return
 	}
 
+	resp := result.NewOrderResponse
 	logger.Info("✅ Reposition Order Placed", "orderID", resp.OrderId)
 
-	// D) Save New Transaction
+	// B) Save New Transaction
 	newTx := model.Transaction{
-		ID:                resp.ClientOrderId,
-		TransactionID:     resp.ClientOrderId,
-		Symbol:            s.Cfg.Symbol,
-		Type:              "buy",
-		Amount:            resp.OrigQty,
-		Price:             resp.Price,
-		StatusTransaction: "open",
-		Notes:             "Smart Entry Reposition",
-		CreatedAt:         time.Now(),
-		UpdatedAt:         time.Now(),
-	}
+		ID:            resp.ClientOrderId,
+		TransactionID: resp.ClientOrderId,
+		Symbol:        s.Cfg.Symbol,
+		Type:          "buy",
+		Amount:        resp.OrigQty,
+		Price:         resp.Price,
+		Notes:         "Smart Entry Reposition" + tracing.Note(ctx),
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	s.beginLifecycle(&newTx, model.StatusOpen, "smart entry reposition of "+highestOrder.ID)
 
 	if resp.Status == "FILLED" {
-		newTx.StatusTransaction = "filled"
+		s.transition(&newTx, model.StatusFilled, "filled on creation")
 	}
 
 	if err := s.TransactionRepo.Save(newTx); err != nil {
@@ -1538,14 +1983,19 @@ func (s *Strategy) ForceSyncOpenOrders() {
 	}
 
 	// 2. Iterate Local Open Orders
-	transactions := s.TransactionRepo.GetAll()
-	syncedCount := 0
-
-	for _, tx := range transactions {
+	var zombies []model.Transaction
+	var queries []orderQuery
+	for _, tx := range s.TransactionRepo.GetAll() {
 		// We only care about reconciling 'open' or 'waiting_sell' orders
-		if tx.StatusTransaction != "open" && tx.StatusTransaction != "waiting_sell" {
+		if tx.StatusTransaction != model.StatusOpen && tx.StatusTransaction != model.StatusExitPlaced {
 			continue
 		}
+		// Exit still live on Binance: nothing to reconcile
+		if sellID, linked := s.TransactionRepo.ExitOf(tx.ID); tx.StatusTransaction == model.StatusExitPlaced && linked {
+			if _, ok := binanceOrderMap[sellID]; ok {
+				continue
+			}
+		}
 
 		if tx.Symbol != s.Cfg.Symbol {
 			continue
@@ -1557,13 +2007,21 @@ func (s *Strategy) ForceSyncOpenOrders() {
 		if isOpenOnBinance {
 			continue // All good
 		}
+		zombies = append(zombies, tx)
+		queries = append(queries, entryQuery(tx))
+	}
 
+	// 3. Query the final state of the rest (in a few allOrders pages when there are many)
+	lookup := s.newOrderLookup(queries)
+	syncedCount := 0
+
+	for _, tx := range zombies {
 		// IF WE ARE HERE: Order is OPEN locally, but NOT in Binance Open Orders.
 		// Conclusion: It was Filled, Canceled, or Expired while we were offline/missed WS.
 		logger.Warn("🔄 Sync: Zombie Order Detected (Locally Open, Remote Closed). Recovering...", "id", tx.ID)
 
 		// Fetch specific order details to know exact final state
-		resp, err := s.Binance.GetOrder(tx.Symbol, tx.ID)
+		resp, err := lookup.get(tx.Symbol, tx.ID)
 		if err != nil {
 			logger.Error("⚠️ Sync: Failed to check status of zombie order", "id", tx.ID, "error", err)
 			continue
@@ -1571,7 +2029,11 @@ func (s *Strategy) ForceSyncOpenOrders() {
 
 		// Update Local State
 		if resp.Status == "FILLED" {
-			tx.StatusTransaction = "filled"
+			if tx.Type == "sell" {
+				// Standalone sell: executing it ends the lifecycle (handled below)
+			} else if !s.transition(&tx, model.StatusFilled, "periodic sync: filled") {
+				continue
+			}
 			tx.Price = resp.Price
 			if resp.ExecutedQty != "" {
 				tx.Amount = resp.ExecutedQty
@@ -1589,52 +2051,30 @@ func (s *Strategy) ForceSyncOpenOrders() {
 				// Before creating a NEW sell order, check if one already exists in Binance Open Orders.
 				// This handles cases where we created the order (via WS or other thread) but persistence failed (Race).
 
-				var foundSellID string
-				buyQtyFloat, _ := strconv.ParseFloat(resp.ExecutedQty, 64)
-
-				// 1. Check strict link (if we have ID but status was wrong)
-				if tx.SellOrderID != "" {
-					if _, ok := binanceOrderMap[tx.SellOrderID]; ok {
-						foundSellID = tx.SellOrderID
-						logger.Info("🔗 Relinking: Existing Sell Order found by ID.", "sellID", foundSellID)
-					}
-				}
-
-				// 2. Scan for Orphan Sell Orders (Match by Quantity)
-				// If we lost the ID (zero persistence), we look for a SELL with matching quantity.
-				if foundSellID == "" {
-					for _, bo := range binanceOrderMap {
-						if bo.Side == "SELL" {
-							sellQtyFloat, _ := strconv.ParseFloat(bo.OrigQty, 64)
-							// Tolerance for float comparison
-							if math.Abs(sellQtyFloat-buyQtyFloat) < 0.00000001 {
-								// We found a SELL order with matching quantity. Safe to assume it's ours?
-								// Grid logic usually 1:1.
-								foundSellID = bo.ClientOrderId
-								logger.Info("🔗 Relinking: Matching Orphan Sell Order found by Quantity.", "sellID", foundSellID)
-								break
-							}
-						}
-					}
+				foundSellID, how := s.findExitOnBook(tx, binanceOrderMap)
+				if foundSellID != "" {
+					logger.Info("🔗 Relinking: Existing Sell Order found.", "sellID", foundSellID, "by", how)
 				}
 
 				if foundSellID != "" {
 					// We found an existing active sell order. Update our records instead of duplicating.
 					tx.SellOrderID = foundSellID
-					tx.StatusTransaction = "waiting_sell"
-					tx.UpdatedAt = time.Now()
+					tx.SellPrice, _ = strconv.ParseFloat(binanceOrderMap[foundSellID].Price, 64)
+					s.transition(&tx, model.StatusExitPlaced, "periodic sync: relinked exit "+foundSellID)
 					s.TransactionRepo.Update(tx)
 					logger.Info("✅ Smart Recovery: Linked existing Sell Order. Skipped duplicate creation.", "buyID", tx.ID, "sellID", foundSellID)
 				} else {
 					// No existing sell order found. Proceed to create one.
 					logger.Info("🚀 Sync: Triggering Maker Exit for Recovered Buy", "buyID", tx.ID)
-					s.placeMakerExitOrder(&tx)
+					s.placeMakerExitOrder(tracing.FromNotes(tx.Notes), &tx)
 				}
 			}
 
 			// ACTION: If it was a SELL (Maker Exit)
 			if tx.Type == "sell" {
-				tx.StatusTransaction = "closed"
+				if !s.transition(&tx, model.StatusClosed, "periodic sync: sold") {
+					continue
+				}
 				now := time.Now()
 				tx.ClosedAt = &now
 				tx.Notes += " | Sold via Periodic Check"
@@ -1643,9 +2083,10 @@ func (s *Strategy) ForceSyncOpenOrders() {
 			}
 
 		} else if resp.Status == "CANCELED" || resp.Status == "EXPIRED" || resp.Status == "REJECTED" {
-			tx.StatusTransaction = "closed"
+			if !s.transition(&tx, terminalStatusFor(tx), "periodic sync: "+resp.Status) {
+				continue
+			}
 			tx.Notes += fmt.Sprintf(" | Synced (%s via Periodic Check)", resp.Status)
-			tx.UpdatedAt = time.Now()
 			s.TransactionRepo.Update(tx)
 			logger.Warn("⚠️ Sync: Order CANCELED/EXPIRED (Recovered)", "id", tx.ID, "status", resp.Status)
 		}
@@ -1658,19 +2099,32 @@ func (s *Strategy) ForceSyncOpenOrders() {
 
 // StartPeriodicSync starts a background ticker to force sync orders every 5 minutes
 func (s *Strategy) StartPeriodicSync() {
-	go func() {
+	crash.Go("periodic sync", func() {
 		logger.Info("⏰ Starting Periodic Order Sync (Every 5 minutes)")
 		ticker := time.NewTicker(5 * time.Minute)
 		defer ticker.Stop()
 
 		for range ticker.C {
+			if s.Binance.RateLimited() {
+				logger.Warn("⏸️ Periodic sync skipped: Binance rate limit protection", "until", s.Binance.RateLimit().ProtectedUntil.Format(time.RFC3339))
+				continue
+			}
 			s.ForceSyncOpenOrders()
 			s.PeriodicSyncOrders() // Ghost cleanup
+			s.checkVaultStatement()
+			s.checkShadowReport()
+			s.checkExecutionReport()
+			s.checkRangeSuggestion()
+			s.checkExchangeFilters()
+			s.checkSnapshot()
+			s.checkDustConversion()
 		}
-	}()
+	})
 }
 
 func (s *Strategy) isMarketSafe(currentPrice float64) bool {
+	s.relaxCircuitBreakerEscalation()
+
 	// Check if feature is enabled
 	if !s.Cfg.CrashProtectionEnabled {
 		return true
@@ -1717,12 +2171,14 @@ func (s *Strategy) isMarketSafe(currentPrice float64) bool {
 			// Normalized.
 			logger.Info("✅ Circuit Breaker Normalizado. Resuming trades.")
 			s.circuitBreakerTriggeredAt = time.Time{} // Reset
-			s.TelegramService.SendMessage("✅ *Circuit Breaker Normalizado*\nVolatilidade controlada. Retomando operações.")
+			s.saveCircuitBreaker()
+			s.Notifier.NotifyTemplate(service.CategoryCircuitBreaker, service.SeverityInfo, service.TemplateCircuitBreakerNormalized, nil)
 			return true
 		} else {
 			// Still volatile. Extend.
 			logger.Warn("⚠️ Market still volatile after cooldown. Extending pause.", "drop", fmt.Sprintf("%.2f%%", dropPct*100))
 			s.circuitBreakerTriggeredAt = time.Now()
+			s.saveCircuitBreaker()
 			return false
 		}
 	}
@@ -1737,10 +2193,15 @@ func (s *Strategy) isMarketSafe(currentPrice float64) bool {
 			"current", currentPrice,
 		)
 
-		msg := fmt.Sprintf("⚠️ *ALERTA: Circuit Breaker Ativado!* ⚠️\n\nQueda detectada: %.2f%%\nPreço Atual: %.2f\nMax (15m): %.2f\n\n⛔ *Compras Pausadas por %d min.*",
-			dropPct*100, currentPrice, maxHigh, s.Cfg.CrashPauseMin)
-
-		s.TelegramService.SendMessage(msg)
+		s.Notifier.NotifyTemplate(service.CategoryCircuitBreaker, service.SeverityWarning, service.TemplateCircuitBreakerTriggered, service.CircuitBreakerMessageData{
+			DropPct:  dropPct * 100,
+			Price:    currentPrice,
+			MaxHigh:  maxHigh,
+			PauseMin: s.Cfg.CrashPauseMin,
+		})
+		s.countCircuitBreakerTrigger()
+		s.saveCircuitBreaker()
+		s.recordCircuitBreakerTrip(s.circuitBreakerTriggeredAt)
 
 		return false
 	}
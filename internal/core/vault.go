@@ -0,0 +1,114 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"grid-trading-btc-binance/internal/api"
+	"grid-trading-btc-binance/internal/audit"
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/service"
+)
+
+// skimProfit moves VAULT_SKIM_PCT of a realized profit into the vault and returns the skimmed amount.
+// Losses are never skimmed.
+func (s *Strategy) skimProfit(profit float64) float64 {
+	if s.Cfg.VaultSkimPct <= 0 || s.VaultRepo == nil || profit <= 0 {
+		return 0
+	}
+
+	amount := profit * s.Cfg.VaultSkimPct
+	if err := s.VaultRepo.Skim(time.Now(), profit, amount); err != nil {
+		logger.Error("Failed to persist vault skim", "error", err)
+		return 0
+	}
+	logger.Info("🏦 Profit skimmed to vault", "profit", profit, "skimmed", amount, "pending", s.VaultRepo.Get().Pending)
+
+	if s.Cfg.VaultMode == "transfer" {
+		s.flushVaultTransfers()
+	}
+	return amount
+}
+
+// flushVaultTransfers moves the pending vault balance out of the Spot wallet once it
+// reaches VAULT_TRANSFER_MIN_USDT (avoids dust transfers after every trade): to the Funding
+// wallet, or to the master account when the keys belong to a sub-account.
+func (s *Strategy) flushVaultTransfers() {
+	pending := s.VaultRepo.Get().Pending
+	if pending < s.Cfg.VaultTransferMinUSDT || s.monitorOnly("vault transfer", "pending", pending) {
+		return
+	}
+
+	amount := math.Floor(pending*100) / 100 // Never transfer more than was skimmed
+	audit.Intent("", "", "vault_transfer", audit.Fields{"amount": amount, "pending": pending, "asset": s.Cfg.QuoteAsset, "sub_account": s.Cfg.SubAccountEmail})
+	if s.Cfg.SubAccountEmail != "" {
+		// Sub-account: the profit leaves for the master account
+		txnID, err := s.Binance.SubAccountToMaster(s.Cfg.QuoteAsset, fmt.Sprintf("%.2f", amount))
+		if err != nil {
+			logger.Error("❌ Vault transfer failed. Amount stays reserved in Spot.", "amount", amount, "error", err)
+			return
+		}
+		if err := s.VaultRepo.MarkTransferred(time.Now(), amount, txnID); err != nil {
+			logger.Error("Failed to persist vault transfer", "error", err)
+		}
+		s.updateBalance(s.Cfg.QuoteAsset, -amount)
+		logger.Info("🏦 Vault transfer completed (sub-account -> master)", "amount", amount, "txnId", txnID)
+		return
+	}
+
+	resp, err := s.Binance.UniversalTransfer(api.TransferSpotToFunding, s.Cfg.QuoteAsset, fmt.Sprintf("%.2f", amount))
+	if err != nil {
+		logger.Error("❌ Vault transfer failed. Amount stays reserved in Spot.", "amount", amount, "error", err)
+		return
+	}
+
+	if err := s.VaultRepo.MarkTransferred(time.Now(), amount, ""); err != nil {
+		logger.Error("Failed to persist vault transfer", "error", err)
+	}
+	s.updateBalance(s.Cfg.QuoteAsset, -amount)
+	logger.Info("🏦 Vault transfer completed (Spot -> Funding)", "amount", amount, "tranId", resp.TranID)
+}
+
+// vaultReservedUSDT is the skimmed USDT still sitting in the Spot wallet
+func (s *Strategy) vaultReservedUSDT() float64 {
+	if s.VaultRepo == nil {
+		return 0
+	}
+	return s.VaultRepo.Get().Pending
+}
+
+// checkVaultStatement sends the statement of the previous month once the month rolls over
+func (s *Strategy) checkVaultStatement() {
+	if s.Cfg.VaultSkimPct <= 0 || s.VaultRepo == nil {
+		return
+	}
+
+	now := time.Now()
+	firstOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	prevMonth := firstOfMonth.AddDate(0, 0, -1).Format("2006-01")
+
+	vault := s.VaultRepo.Get()
+	if vault.LastStatementMonth >= prevMonth {
+		return
+	}
+
+	if month, ok := vault.Months[prevMonth]; ok {
+		s.Notifier.NotifyTemplate(service.CategoryReport, service.SeverityInfo, service.TemplateVaultStatement, service.VaultStatementMessageData{
+			Month:          prevMonth,
+			Mode:           s.Cfg.VaultMode,
+			SkimPct:        s.Cfg.VaultSkimPct * 100,
+			Trades:         month.Trades,
+			RealizedProfit: month.RealizedProfit,
+			Skimmed:        month.Skimmed,
+			Transferred:    month.Transferred,
+			TotalSkimmed:   vault.TotalSkimmed,
+			Pending:        vault.Pending,
+		})
+		logger.Info("🏦 Monthly vault statement sent", "month", prevMonth)
+	}
+
+	if err := s.VaultRepo.SetLastStatementMonth(prevMonth); err != nil {
+		logger.Error("Failed to persist vault statement month", "error", err)
+	}
+}
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"time"
+
+	"grid-trading-btc-binance/internal/crash"
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/model"
+	"grid-trading-btc-binance/internal/service"
+)
+
+const watchdogInterval = 15 * time.Second
+
+// staleFeed tracks one data feed between watchdog checks
+type staleFeed struct {
+	name          string
+	staleSince    time.Time // Zero while the feed is fresh
+	lastReconnect time.Time
+}
+
+// StartWatchdog alerts and forces the reconnection of both streams when market data stops:
+// no ticker for WATCHDOG_TICKER_STALE_SEC (Execute is never called, so the strategy halts
+// silently) or no user stream event/ping for WATCHDOG_STREAM_STALE_MIN while orders are open
+// (fills would be missed). The reconnection is retried every limit while the feed stays stale.
+func (s *Strategy) StartWatchdog(market *service.MarketDataService, stream *service.StreamService) {
+	tickerLimit := time.Duration(s.Cfg.WatchdogTickerStaleSec) * time.Second
+	streamLimit := time.Duration(s.Cfg.WatchdogStreamStaleMin) * time.Minute
+	if tickerLimit == 0 && streamLimit == 0 {
+		return
+	}
+
+	crash.Go("watchdog", func() {
+		logger.Info("🐕 Starting stream watchdog", "ticker_stale", tickerLimit.String(), "stream_stale", streamLimit.String())
+		started := time.Now()
+		tickerFeed := &staleFeed{name: "Market (" + s.Cfg.Symbol + ")"}
+		streamFeed := &staleFeed{name: "User Stream"}
+
+		ticker := time.NewTicker(watchdogInterval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			openOrders := s.countOpenOrders()
+			reconnect := false
+			if tickerLimit > 0 {
+				age := now.Sub(orStarted(market.LastTickerAt(s.Cfg.Symbol), started))
+				reconnect = s.watchFeed(tickerFeed, age, tickerLimit, openOrders, now) || reconnect
+			}
+			if streamLimit > 0 {
+				age := now.Sub(orStarted(stream.LastEventAt(), started))
+				if openOrders == 0 {
+					age = 0 // A quiet stream only matters when fills can be missed
+				}
+				reconnect = s.watchFeed(streamFeed, age, streamLimit, openOrders, now) || reconnect
+			}
+			if reconnect {
+				market.Reconnect()
+				stream.Reconnect()
+			}
+		}
+	})
+}
+
+// watchFeed alerts when the feed turns stale and when it recovers, and reports whether the
+// streams must be reconnected now
+func (s *Strategy) watchFeed(feed *staleFeed, age, limit time.Duration, openOrders int, now time.Time) bool {
+	if age <= limit {
+		if !feed.staleSince.IsZero() {
+			stale := now.Sub(feed.staleSince).Round(time.Second)
+			logger.Info("✅ Feed recovered", "feed", feed.name, "stale_for", stale.String())
+			s.Notifier.NotifyTemplate(service.CategoryError, service.SeverityInfo, service.TemplateStreamRecovered, service.StreamStaleMessageData{
+				Stream: feed.name,
+				Age:    stale.String(),
+			})
+			feed.staleSince = time.Time{}
+		}
+		return false
+	}
+
+	if feed.staleSince.IsZero() {
+		feed.staleSince = now.Add(-age)
+		logger.Error("🚨 Feed is stale. Forcing reconnection.", "feed", feed.name, "age", age.Round(time.Second).String(), "open_orders", openOrders)
+		s.Notifier.NotifyTemplate(service.CategoryError, service.SeverityCritical, service.TemplateStreamStale, service.StreamStaleMessageData{
+			Stream:     feed.name,
+			Age:        age.Round(time.Second).String(),
+			Limit:      limit.String(),
+			OpenOrders: openOrders,
+		})
+		feed.lastReconnect = now
+		return true
+	}
+	if now.Sub(feed.lastReconnect) >= limit {
+		logger.Warn("🔁 Feed still stale. Retrying reconnection.", "feed", feed.name, "age", age.Round(time.Second).String())
+		feed.lastReconnect = now
+		return true
+	}
+	return false
+}
+
+// countOpenOrders counts the orders on the book: open buys and placed exits
+func (s *Strategy) countOpenOrders() int {
+	return s.TransactionRepo.Count(s.gridBuys(model.StatusOpen, model.StatusExitPlaced))
+}
+
+// orStarted returns at, or started when nothing was seen yet
+func orStarted(at, started time.Time) time.Time {
+	if at.IsZero() {
+		return started
+	}
+	return at
+}
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"grid-trading-btc-binance/internal/config"
+	"grid-trading-btc-binance/internal/logger"
+)
+
+// activeTradingWindow returns the TRADING_WINDOWS entry in force at now (nil = none). When
+// several overlap, a pause wins, then the smallest size.
+func (s *Strategy) activeTradingWindow(now time.Time) *config.TradingWindow {
+	var active *config.TradingWindow
+	for i := range s.Cfg.TradingWindows {
+		w := &s.Cfg.TradingWindows[i]
+		if !w.Contains(now) {
+			continue
+		}
+		if active == nil || (w.Pause && !active.Pause) || (!w.Pause && !active.Pause && w.SizeFactor < active.SizeFactor) {
+			active = w
+		}
+	}
+	return active
+}
+
+// tradingWindowGate applies the trading window in force to a new grid entry: false while a
+// pause window is active, otherwise the order value factor (1 = no window). Entering and
+// leaving a window is logged once.
+func (s *Strategy) tradingWindowGate() (float64, bool) {
+	w := s.activeTradingWindow(time.Now())
+	label := ""
+	if w != nil {
+		label = w.Label
+	}
+	if label != s.tradingWindow {
+		if label == "" {
+			logger.Info("🕒 Trading window ended", "window", s.tradingWindow)
+		} else {
+			logger.Info("🕒 Trading window active", "window", label, "pause", w.Pause, "size_factor", w.SizeFactor)
+		}
+		s.tradingWindow = label
+	}
+
+	switch {
+	case w == nil:
+		return 1, true
+	case w.Pause:
+		return 0, false
+	default:
+		return w.SizeFactor, true
+	}
+}
+
+// tradingWindowText describes the trading window in force for /status
+func (s *Strategy) tradingWindowText() string {
+	w := s.activeTradingWindow(time.Now())
+	switch {
+	case w == nil:
+		return "nenhuma"
+	case w.Pause:
+		return w.Label + " (novas compras pausadas)"
+	default:
+		return fmt.Sprintf("%s (tamanho x%.2f)", w.Label, w.SizeFactor)
+	}
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"math"
+	"strconv"
+
+	"grid-trading-btc-binance/internal/config"
+	"grid-trading-btc-binance/internal/fees"
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/model"
+)
+
+// zoneAt returns the GRID_ZONES zone holding price (nil = none, or no zones configured)
+func (s *Strategy) zoneAt(price float64) *config.GridZone {
+	for i := range s.Cfg.GridZones {
+		if s.Cfg.GridZones[i].Contains(price) {
+			return &s.Cfg.GridZones[i]
+		}
+	}
+	return nil
+}
+
+// spacingAt is the grid spacing for an order at price: the fixed spacing of its zone, or the
+// dynamic (volatility) spacing
+func (s *Strategy) spacingAt(price float64) float64 {
+	if zone := s.zoneAt(price); zone != nil && zone.Spacing > 0 {
+		return math.Max(zone.Spacing, fees.For(s.Cfg).MinSpacing())
+	}
+	return s.VolatilityService.GetDynamicSpacing()
+}
+
+// gridCapital is the capital the zones split: the equity baseline when compounding, otherwise
+// the deployable USDT plus what the grid orders already hold (open buys and inventory)
+func (s *Strategy) gridCapital(orders []model.Transaction) float64 {
+	if s.Cfg.CompoundProfits && s.EquityRepo != nil && s.EquityRepo.Initialized() {
+		return s.EquityRepo.Get().Equity()
+	}
+	capital := s.deployableUSDT()
+	for _, o := range orders {
+		capital += orderCost(o)
+	}
+	return capital
+}
+
+// zoneOrderValue sizes a new entry in zone: its capital share split over its levels, capped by
+// what the zone has left. false when the zone is at its level or capital budget.
+func (s *Strategy) zoneOrderValue(zone config.GridZone, orders []model.Transaction) (float64, bool) {
+	held, used := 0, 0.0
+	for _, o := range orders {
+		if p, _ := strconv.ParseFloat(o.Price, 64); zone.Contains(p) {
+			held++
+			used += orderCost(o)
+		}
+	}
+	if held >= zone.Levels {
+		logger.Debug("🧱 Zone level budget reached", "zone", zone.Label, "orders", held)
+		return 0, false
+	}
+
+	budget := s.gridCapital(orders) * zone.Capital
+	value := math.Min(budget/float64(zone.Levels), budget-used)
+	if value < s.Cfg.MinOrderValue {
+		logger.Debug("🧱 Zone capital budget reached", "zone", zone.Label, "budget", budget, "used", used)
+		return 0, false
+	}
+	return value, true
+}
+
+func orderCost(tx model.Transaction) float64 {
+	qty, _ := strconv.ParseFloat(tx.Amount, 64)
+	price, _ := strconv.ParseFloat(tx.Price, 64)
+	return qty * price
+}
@@ -0,0 +1,130 @@
+package crash
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"grid-trading-btc-binance/internal/logger"
+
+	"github.com/getsentry/sentry-go"
+)
+
+const (
+	restartMinDelay = 1 * time.Second
+	restartMaxDelay = 1 * time.Minute
+	restartStable   = 5 * time.Minute // A goroutine that ran this long restarts with the minimum delay again
+	alertDebounce   = 5 * time.Minute // At most one alert per goroutine name per window
+	sentryFlushWait = 2 * time.Second
+)
+
+var (
+	mu          sync.Mutex
+	alert       func(name, message string, suppressed int)
+	lastAlert   = map[string]time.Time{}
+	suppressed  = map[string]int{}
+	sentryReady bool
+)
+
+// InitSentry enables the Sentry reports (no-op without a DSN)
+func InitSentry(dsn, environment string) error {
+	if dsn == "" {
+		return nil
+	}
+	if err := sentry.Init(sentry.ClientOptions{Dsn: dsn, Environment: environment}); err != nil {
+		return fmt.Errorf("failed to initialize Sentry: %w", err)
+	}
+	mu.Lock()
+	sentryReady = true
+	mu.Unlock()
+	logger.Info("🛰️ Sentry crash reporting enabled", "environment", environment)
+	return nil
+}
+
+// SetAlert registers the callback that notifies a recovered panic (Telegram). It is called at
+// most once per goroutine name every alertDebounce, with the number of panics suppressed since.
+func SetAlert(fn func(name, message string, suppressed int)) {
+	mu.Lock()
+	alert = fn
+	mu.Unlock()
+}
+
+// Guard runs fn, recovering and reporting a panic. It returns true if fn panicked.
+// Used around each event of a loop, so one bad event never stops the ones after it.
+func Guard(name string, fn func()) (panicked bool) {
+	defer func() {
+		if value := recover(); value != nil {
+			report(name, value, debug.Stack())
+			panicked = true
+		}
+	}()
+	fn()
+	return false
+}
+
+// Recover reports a panic without re-raising it: `defer crash.Recover(name)` at the top of
+// callbacks run on goroutines the bot does not own (library read loops)
+func Recover(name string) {
+	if value := recover(); value != nil {
+		report(name, value, debug.Stack())
+	}
+}
+
+// Go runs fn in a goroutine and starts it again (with backoff) whenever it panics.
+// Used for the background loops: a panic costs one iteration, never the whole loop.
+func Go(name string, fn func()) {
+	go func() {
+		delay := restartMinDelay
+		for {
+			started := time.Now()
+			if !Guard(name, fn) {
+				return // Returned normally
+			}
+			if time.Since(started) >= restartStable {
+				delay = restartMinDelay
+			}
+			logger.Warn("🔁 Restarting goroutine after panic", "goroutine", name, "in", delay.String())
+			time.Sleep(delay)
+			delay = min(delay*2, restartMaxDelay)
+		}
+	}()
+}
+
+func report(name string, value interface{}, stack []byte) {
+	message := fmt.Sprint(value)
+	logger.Error("💥 Recovered panic", "goroutine", name, "panic", message, "stack", string(stack))
+
+	mu.Lock()
+	useSentry := sentryReady
+	notify := alert
+	send := time.Since(lastAlert[name]) >= alertDebounce
+	skipped := suppressed[name]
+	if send {
+		lastAlert[name] = time.Now()
+		suppressed[name] = 0
+	} else {
+		suppressed[name]++
+	}
+	mu.Unlock()
+
+	if useSentry {
+		hub := sentry.CurrentHub().Clone()
+		hub.ConfigureScope(func(scope *sentry.Scope) {
+			scope.SetTag("goroutine", name)
+		})
+		hub.Recover(value)
+		hub.Flush(sentryFlushWait)
+	}
+	if send && notify != nil {
+		// The alert itself must never take the goroutine down
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Error("Failed to send panic alert", "panic", fmt.Sprint(r))
+				}
+			}()
+			notify(name, message, skipped)
+		}()
+	}
+}
@@ -0,0 +1,273 @@
+package data
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"grid-trading-btc-binance/internal/api"
+	"grid-trading-btc-binance/internal/logger"
+)
+
+const (
+	DefaultKlinesDir = "data/klines"
+
+	klinesPageLimit = 1000 // Binance maximum per /api/v3/klines request
+)
+
+var csvHeader = []string{"open_time", "open", "high", "low", "close", "volume", "close_time"}
+
+const csvMinColumns = 5 // Caches written before volume and close_time
+
+// KlineStore downloads klines and keeps them in a CSV cache (one file per symbol and
+// interval) so backtests and the volatility warm-up don't depend on REST availability.
+// The cache holds only closed candles and is always contiguous: requests outside it are
+// downloaded (paging past the 1000-candle limit) from the cache edge to the requested edge.
+type KlineStore struct {
+	Binance *api.BinanceClient
+	Dir     string
+
+	mu sync.Mutex
+}
+
+func NewKlineStore(binance *api.BinanceClient, dir string) *KlineStore {
+	if dir == "" {
+		dir = DefaultKlinesDir
+	}
+	return &KlineStore{Binance: binance, Dir: dir}
+}
+
+// Range returns the closed candles opened in [start, end), downloading what the cache lacks
+func (s *KlineStore) Range(symbol, interval string, start, end time.Time) ([]api.Kline, error) {
+	step, err := IntervalDuration(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cached, err := s.read(symbol, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	// Never ask for candles that are still open
+	lastClosed := time.Now().Add(-step).UnixMilli()
+	startMs, endMs := start.UnixMilli(), min(end.UnixMilli(), lastClosed+1)
+
+	var fetched []api.Kline
+	if len(cached) == 0 {
+		if fetched, err = s.download(symbol, interval, startMs, endMs); err != nil {
+			return nil, err
+		}
+	} else {
+		first, last := cached[0].OpenTime, cached[len(cached)-1].OpenTime
+		if startMs < first {
+			head, err := s.download(symbol, interval, startMs, first)
+			if err != nil {
+				return nil, err
+			}
+			fetched = append(fetched, head...)
+		}
+		if endMs > last+step.Milliseconds() {
+			tail, err := s.download(symbol, interval, last+1, endMs)
+			if err != nil {
+				return nil, err
+			}
+			fetched = append(fetched, tail...)
+		}
+	}
+
+	if len(fetched) > 0 {
+		cached = merge(cached, fetched, lastClosed)
+		if err := s.write(symbol, interval, cached); err != nil {
+			return nil, err
+		}
+		logger.Info("💾 Klines cache updated", "symbol", symbol, "interval", interval, "downloaded", len(fetched), "cached", len(cached))
+	}
+
+	return between(cached, startMs, endMs), nil
+}
+
+// Recent returns the last n closed candles: refreshed from REST when possible, otherwise
+// from the cache as long as its newest candle is not older than maxAge.
+func (s *KlineStore) Recent(symbol, interval string, n int, maxAge time.Duration) ([]api.Kline, error) {
+	step, err := IntervalDuration(interval)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	klines, err := s.Range(symbol, interval, now.Add(-time.Duration(n+1)*step), now)
+	if err != nil {
+		logger.Warn("⚠️ Klines REST unavailable, using local cache", "symbol", symbol, "interval", interval, "error", err)
+
+		s.mu.Lock()
+		klines, err = s.read(symbol, interval)
+		s.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		if len(klines) == 0 {
+			return nil, fmt.Errorf("no cached klines for %s %s", symbol, interval)
+		}
+		newest := time.UnixMilli(klines[len(klines)-1].OpenTime).Add(step)
+		if age := now.Sub(newest); age > maxAge {
+			return nil, fmt.Errorf("cached klines for %s %s are %s old", symbol, interval, age.Round(time.Second))
+		}
+	}
+	if len(klines) > n {
+		klines = klines[len(klines)-n:]
+	}
+	return klines, nil
+}
+
+// download pages through /api/v3/klines from startMs (inclusive) to endMs (exclusive)
+func (s *KlineStore) download(symbol, interval string, startMs, endMs int64) ([]api.Kline, error) {
+	var all []api.Kline
+	for from := startMs; from < endMs; {
+		page, err := s.Binance.GetKlines(symbol, interval, from, endMs-1, klinesPageLimit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download klines: %w", err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		all = append(all, page...)
+		from = page[len(page)-1].OpenTime + 1
+		if len(page) < klinesPageLimit {
+			break
+		}
+	}
+	return all, nil
+}
+
+func (s *KlineStore) path(symbol, interval string) string {
+	return filepath.Join(s.Dir, fmt.Sprintf("%s_%s.csv", symbol, interval))
+}
+
+func (s *KlineStore) read(symbol, interval string) ([]api.Kline, error) {
+	file, err := os.Open(s.path(symbol, interval))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open klines cache: %w", err)
+	}
+	defer file.Close()
+
+	r := csv.NewReader(file)
+	if _, err := r.Read(); err != nil { // Header
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read klines cache: %w", err)
+	}
+
+	var klines []api.Kline
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read klines cache: %w", err)
+		}
+		if len(row) < csvMinColumns {
+			continue
+		}
+		openTime, err := strconv.ParseInt(row[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		k := api.Kline{OpenTime: openTime, Open: row[1], High: row[2], Low: row[3], Close: row[4]}
+		if len(row) >= len(csvHeader) {
+			k.Volume = row[5]
+			k.CloseTime, _ = strconv.ParseInt(row[6], 10, 64)
+		}
+		klines = append(klines, k)
+	}
+	return klines, nil
+}
+
+// write replaces the cache file atomically (temp file + rename)
+func (s *KlineStore) write(symbol, interval string, klines []api.Kline) error {
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return fmt.Errorf("failed to create klines dir: %w", err)
+	}
+	path := s.path(symbol, interval)
+	tmp, err := os.CreateTemp(s.Dir, filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to write klines cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	w := csv.NewWriter(tmp)
+	w.Write(csvHeader)
+	for _, k := range klines {
+		w.Write([]string{strconv.FormatInt(k.OpenTime, 10), k.Open, k.High, k.Low, k.Close, k.Volume, strconv.FormatInt(k.CloseTime, 10)})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write klines cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write klines cache: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// merge combines both sets sorted by open time, without duplicates or open candles
+func merge(cached, fetched []api.Kline, lastClosed int64) []api.Kline {
+	byTime := make(map[int64]api.Kline, len(cached)+len(fetched))
+	for _, k := range cached {
+		byTime[k.OpenTime] = k
+	}
+	for _, k := range fetched {
+		if k.OpenTime <= lastClosed {
+			byTime[k.OpenTime] = k
+		}
+	}
+	merged := make([]api.Kline, 0, len(byTime))
+	for _, k := range byTime {
+		merged = append(merged, k)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].OpenTime < merged[j].OpenTime })
+	return merged
+}
+
+// between returns the candles opened in [startMs, endMs)
+func between(klines []api.Kline, startMs, endMs int64) []api.Kline {
+	from := sort.Search(len(klines), func(i int) bool { return klines[i].OpenTime >= startMs })
+	to := sort.Search(len(klines), func(i int) bool { return klines[i].OpenTime >= endMs })
+	return klines[from:to]
+}
+
+// IntervalDuration parses a Binance kline interval (1m, 5m, 1h, 4h, 1d, 1w)
+func IntervalDuration(interval string) (time.Duration, error) {
+	if len(interval) < 2 {
+		return 0, fmt.Errorf("invalid interval %q", interval)
+	}
+	n, err := strconv.Atoi(interval[:len(interval)-1])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid interval %q", interval)
+	}
+	switch interval[len(interval)-1] {
+	case 'm':
+		return time.Duration(n) * time.Minute, nil
+	case 'h':
+		return time.Duration(n) * time.Hour, nil
+	case 'd':
+		return time.Duration(n) * 24 * time.Hour, nil
+	case 'w':
+		return time.Duration(n) * 7 * 24 * time.Hour, nil
+	}
+	return 0, fmt.Errorf("invalid interval %q", interval)
+}
@@ -0,0 +1,68 @@
+// Package fees models the commission the traded pair pays on each fill: the standard
+// account rate, the BNB-discounted rate or none (zero-fee pairs such as BTCFDUSD).
+package fees
+
+import "grid-trading-btc-binance/internal/config"
+
+const (
+	Auto     = "auto"     // Zero on the known zero-fee pairs, BNB otherwise
+	Standard = "standard" // MAKER_FEE_PCT/TAKER_FEE_PCT as they are
+	BNB      = "bnb"      // Fees paid in BNB, with the BNB discount
+	Zero     = "zero"     // No maker fee
+
+	// BNBDiscount is the share of the fee waived when it is paid in BNB
+	BNBDiscount = 0.25
+
+	// spacingMargin is what the spacing floor keeps above the round-trip fees
+	// (0.2% floor with the 0.075% BNB rate)
+	spacingMargin = 0.0005
+)
+
+// zeroFeeSymbols are the pairs Binance trades without maker fee (FEE_MODEL=auto)
+var zeroFeeSymbols = map[string]bool{"BTCFDUSD": true}
+
+// Model is the fee schedule of the traded pair, as fractions of the notional
+type Model struct {
+	Kind  string
+	Maker float64
+	Taker float64
+}
+
+// For resolves FEE_MODEL for cfg.Symbol over the account rates (MAKER_FEE_PCT/TAKER_FEE_PCT,
+// synced from Binance on startup). Zero-fee pairs only waive the maker fee: taker fills
+// (panic, take-profit) still pay the standard rate.
+func For(cfg *config.Config) Model {
+	kind := cfg.FeeModel
+	if kind == Auto || kind == "" {
+		kind = BNB
+		if zeroFeeSymbols[cfg.Symbol] {
+			kind = Zero
+		}
+	}
+
+	switch kind {
+	case Zero:
+		return Model{Kind: Zero, Taker: cfg.TakerFeePct}
+	case BNB:
+		return Model{Kind: BNB, Maker: cfg.MakerFeePct * (1 - BNBDiscount), Taker: cfg.TakerFeePct * (1 - BNBDiscount)}
+	default:
+		return Model{Kind: Standard, Maker: cfg.MakerFeePct, Taker: cfg.TakerFeePct}
+	}
+}
+
+// RoundTrip is the fee of a maker buy plus its maker exit
+func (m Model) RoundTrip() float64 {
+	return 2 * m.Maker
+}
+
+// MinExitPrice is the lowest exit for a buy at buyPrice that nets minNetProfitPct of its cost
+// after the maker fee of both legs: sell*(1-fee) - buy*(1+fee) >= buy*(1+fee)*minNetProfitPct
+func (m Model) MinExitPrice(buyPrice, minNetProfitPct float64) float64 {
+	return buyPrice * (1 + m.Maker) * (1 + minNetProfitPct) / (1 - m.Maker)
+}
+
+// MinSpacing is the tightest grid spacing that still profits after the round-trip fees.
+// Fee-free pairs get a much tighter floor than the 0.2% of the BNB rate.
+func (m Model) MinSpacing() float64 {
+	return m.RoundTrip() + spacingMargin
+}
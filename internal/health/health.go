@@ -0,0 +1,197 @@
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"grid-trading-btc-binance/internal/api"
+	"grid-trading-btc-binance/internal/config"
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/repository"
+	"grid-trading-btc-binance/internal/service"
+)
+
+const minRequestsForErrorRate = 5 // Below this, a couple of failures say nothing about the API
+
+// Directories the bot writes to (state files in the working directory, CSVs and logs in logs/)
+var writableDirs = []string{".", "logs"}
+
+// Check is the result of one probe
+type Check struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail"`
+}
+
+// Report is the body of /healthz and /readyz
+type Report struct {
+	Status   string              `json:"status"` // ok | fail
+	Uptime   string              `json:"uptime"`
+	Checks   []Check             `json:"checks"`
+	Endpoint *api.EndpointStatus `json:"endpoint,omitempty"` // REST host in use and the probes (ENDPOINT_FAILOVER)
+	Time     time.Time           `json:"time"`
+}
+
+// Server answers the probes of systemd/Docker/Kubernetes. /healthz (liveness) fails when the
+// bot is wedged: no ticker or no user stream activity for too long (e.g. a WebSocket that died
+// silently), so the supervisor restarts it. /readyz (readiness) adds the WebSocket connection
+// states, the REST error rate, the 429/418 protection mode and the storage writability.
+type Server struct {
+	Cfg     *config.Config
+	Market  *service.MarketDataService
+	Stream  *service.StreamService
+	Binance *api.BinanceClient
+	Storage *repository.Storage
+
+	Endpoint func() api.EndpointStatus // Failover state (nil = ENDPOINT_FAILOVER off)
+
+	started time.Time
+}
+
+func NewServer(cfg *config.Config, market *service.MarketDataService, stream *service.StreamService, binance *api.BinanceClient, storage *repository.Storage) *Server {
+	return &Server{
+		Cfg:     cfg,
+		Market:  market,
+		Stream:  stream,
+		Binance: binance,
+		Storage: storage,
+		started: time.Now(),
+	}
+}
+
+// Start serves the endpoints on HEALTH_ADDR in background
+func (s *Server) Start() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		s.respond(w, s.Liveness())
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		s.respond(w, s.Readiness())
+	})
+
+	server := &http.Server{Addr: s.Cfg.HealthAddr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		logger.Info("🩺 Health endpoints listening", "addr", s.Cfg.HealthAddr)
+		if err := server.ListenAndServe(); err != nil {
+			logger.Error("❌ Health endpoints stopped", "addr", s.Cfg.HealthAddr, "error", err)
+		}
+	}()
+}
+
+// Liveness runs the checks that detect a wedged bot
+func (s *Server) Liveness() Report {
+	return s.report(s.tickerAge(), s.streamAge())
+}
+
+// Readiness runs every check
+func (s *Server) Readiness() Report {
+	checks := []Check{s.tickerAge(), s.streamAge(), s.marketConnected(), s.streamConnected(), s.apiErrors(), s.rateLimit()}
+	for _, dir := range writableDirs {
+		checks = append(checks, s.storageWritable(dir))
+	}
+	return s.report(checks...)
+}
+
+func (s *Server) report(checks ...Check) Report {
+	report := Report{Status: "ok", Uptime: time.Since(s.started).Round(time.Second).String(), Checks: checks, Time: time.Now()}
+	if s.Endpoint != nil {
+		endpoint := s.Endpoint()
+		report.Endpoint = &endpoint
+	}
+	for _, c := range checks {
+		if !c.OK {
+			report.Status = "fail"
+		}
+	}
+	return report
+}
+
+func (s *Server) respond(w http.ResponseWriter, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if report.Status != "ok" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		logger.Error("Failed to write health response", "error", err)
+	}
+}
+
+// since measures an age, counting from startup while nothing was seen yet (startup grace)
+func (s *Server) since(at time.Time) time.Duration {
+	if at.IsZero() {
+		at = s.started
+	}
+	return time.Since(at)
+}
+
+func (s *Server) tickerAge() Check {
+	age := s.since(s.Market.LastTickerAt(s.Cfg.Symbol))
+	limit := time.Duration(s.Cfg.HealthMaxTickerAgeSec) * time.Second
+	return Check{
+		Name:   "ticker_age",
+		OK:     age <= limit,
+		Detail: fmt.Sprintf("last %s ticker %s ago (max %s)", s.Cfg.Symbol, age.Round(time.Second), limit),
+	}
+}
+
+func (s *Server) streamAge() Check {
+	age := s.since(s.Stream.LastEventAt())
+	limit := time.Duration(s.Cfg.HealthMaxStreamAgeSec) * time.Second
+	return Check{
+		Name:   "user_stream_age",
+		OK:     age <= limit,
+		Detail: fmt.Sprintf("last user stream event or ping %s ago (max %s)", age.Round(time.Second), limit),
+	}
+}
+
+func (s *Server) marketConnected() Check {
+	ok := s.Market.Connected(s.Cfg.Symbol)
+	return Check{Name: "market_ws", OK: ok, Detail: connectedDetail(ok)}
+}
+
+func (s *Server) streamConnected() Check {
+	ok := s.Stream.Connected()
+	return Check{Name: "user_stream_ws", OK: ok, Detail: connectedDetail(ok)}
+}
+
+func (s *Server) apiErrors() Check {
+	stats := s.Binance.RequestStats()
+	rate := stats.ErrorRate()
+	return Check{
+		Name:   "api_error_rate",
+		OK:     stats.Requests < minRequestsForErrorRate || rate <= s.Cfg.HealthMaxAPIErrorRate,
+		Detail: fmt.Sprintf("%d of %d REST calls failed in the last %s (%.0f%%, max %.0f%%)", stats.Errors, stats.Requests, stats.Window, rate*100, s.Cfg.HealthMaxAPIErrorRate*100),
+	}
+}
+
+// rateLimit fails while the client is in the 429/418 protection mode. Readiness only: a restart
+// would hammer the API again.
+func (s *Server) rateLimit() Check {
+	status := s.Binance.RateLimit()
+	if !status.Active {
+		return Check{Name: "api_rate_limit", OK: true, Detail: "no 429/418"}
+	}
+	return Check{
+		Name:   "api_rate_limit",
+		OK:     false,
+		Detail: fmt.Sprintf("HTTP %d on %s: no REST call until %s, essential calls only until %s", status.Status, status.Path, status.Until.Format(time.RFC3339), status.ProtectedUntil.Format(time.RFC3339)),
+	}
+}
+
+func (s *Server) storageWritable(dir string) Check {
+	check := Check{Name: "storage:" + dir, OK: true, Detail: "writable"}
+	if err := s.Storage.CheckWritable(dir); err != nil {
+		check.OK, check.Detail = false, err.Error()
+	}
+	return check
+}
+
+func connectedDetail(ok bool) string {
+	if ok {
+		return "connected"
+	}
+	return "disconnected"
+}
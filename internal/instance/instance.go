@@ -0,0 +1,67 @@
+// Package instance keeps a second bot from running on the same host against the same symbol
+// and API key, which would place a duplicate grid and duplicate exits.
+package instance
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ErrRunning is returned by Acquire when another process holds the guard
+var ErrRunning = errors.New("another instance is already running")
+
+// Guard is an OS file lock: released by the kernel when the process dies, so a crash never
+// leaves a stale lock behind
+type Guard struct {
+	Path string
+	file *os.File
+}
+
+// Path returns the lock file for symbol + API key. It lives in the temp directory, so
+// copies of the bot started from different directories still collide; the key is hashed.
+func Path(symbol, apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return filepath.Join(os.TempDir(), fmt.Sprintf("grid-bot-%s-%s.lock", strings.ToUpper(symbol), hex.EncodeToString(sum[:6])))
+}
+
+// Acquire takes the guard. When another process holds it, the error wraps ErrRunning and
+// names its PID.
+func Acquire(symbol, apiKey string) (*Guard, error) {
+	path := Path(symbol, apiKey)
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open instance lock %s: %w", path, err)
+	}
+	if err := lockFile(file); err != nil {
+		file.Close()
+		if errors.Is(err, errLocked) {
+			return nil, fmt.Errorf("%w (pid %s, lock %s)", ErrRunning, holderPID(path), path)
+		}
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+
+	// Informational only: the lock is what guards
+	file.Truncate(0)
+	file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	return &Guard{Path: path, file: file}, nil
+}
+
+// Release frees the guard (also done by the OS on exit)
+func (g *Guard) Release() {
+	unlockFile(g.file)
+	g.file.Close()
+}
+
+func holderPID(path string) string {
+	raw, err := os.ReadFile(path)
+	if err != nil || strings.TrimSpace(string(raw)) == "" {
+		return "unknown"
+	}
+	return strings.TrimSpace(string(raw))
+}
@@ -0,0 +1,120 @@
+package leader
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// settleDelay is how long Acquire waits before reading its write back: when two instances
+// write at the same moment, the last rename wins and the other sees it lost
+const settleDelay = 200 * time.Millisecond
+
+// FileLock keeps the lease in a JSON file. Both instances must see the same file (same host
+// or a shared filesystem) and have synchronized clocks (NTP), since the expiry is wall time.
+type FileLock struct {
+	Path string
+}
+
+type fileLease struct {
+	Holder    string    `json:"holder"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+func NewFileLock(path string) *FileLock {
+	return &FileLock{Path: path}
+}
+
+func (l *FileLock) Name() string {
+	return "file:" + l.Path
+}
+
+func (l *FileLock) Acquire(id string, ttl time.Duration) (string, error) {
+	lease, err := l.read()
+	if err != nil {
+		return "", err
+	}
+	if lease.Holder != "" && lease.Holder != id && time.Now().Before(lease.ExpiresAt) {
+		return lease.Holder, nil
+	}
+	if err := l.write(fileLease{Holder: id, ExpiresAt: time.Now().Add(ttl)}); err != nil {
+		return "", err
+	}
+	time.Sleep(settleDelay)
+	if lease, err = l.read(); err != nil {
+		return "", err
+	}
+	return lease.Holder, nil
+}
+
+func (l *FileLock) Renew(id string, ttl time.Duration) (bool, error) {
+	lease, err := l.read()
+	if err != nil {
+		return false, err
+	}
+	if lease.Holder != id {
+		return false, nil
+	}
+	if err := l.write(fileLease{Holder: id, ExpiresAt: time.Now().Add(ttl)}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (l *FileLock) Release(id string) error {
+	lease, err := l.read()
+	if err != nil || lease.Holder != id {
+		return err
+	}
+	if err := os.Remove(l.Path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// read returns the current lease (zero value when there is none)
+func (l *FileLock) read() (fileLease, error) {
+	var lease fileLease
+	raw, err := os.ReadFile(l.Path)
+	if os.IsNotExist(err) {
+		return lease, nil
+	}
+	if err != nil {
+		return lease, fmt.Errorf("failed to read %s: %w", l.Path, err)
+	}
+	if len(raw) == 0 {
+		return lease, nil
+	}
+	if err := json.Unmarshal(raw, &lease); err != nil {
+		return lease, fmt.Errorf("failed to decode %s: %w", l.Path, err)
+	}
+	return lease, nil
+}
+
+// write replaces the lease atomically (readers never see a partial file)
+func (l *FileLock) write(lease fileLease) error {
+	raw, err := json.Marshal(lease)
+	if err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(filepath.Dir(l.Path), ".leader-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write lease: %w", err)
+	}
+	tmp := file.Name()
+	_, err = file.Write(raw)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, l.Path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to replace %s: %w", l.Path, err)
+	}
+	return nil
+}
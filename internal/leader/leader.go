@@ -0,0 +1,135 @@
+// Package leader elects one trading instance among several running against the same
+// account (hot standby): only the lease holder trades, a standby takes over once the
+// leader stops renewing its lease.
+package leader
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"grid-trading-btc-binance/internal/crash"
+	"grid-trading-btc-binance/internal/logger"
+)
+
+// Lock is a lease shared by the instances
+type Lock interface {
+	Name() string
+	// Acquire takes the lease for id if it is free or expired and returns the holder
+	// (id when acquired)
+	Acquire(id string, ttl time.Duration) (string, error)
+	// Renew extends the lease; false means id no longer holds it
+	Renew(id string, ttl time.Duration) (bool, error)
+	// Release frees the lease if id holds it
+	Release(id string) error
+}
+
+// Elector holds the lease for this instance. The lease is renewed every TTL/5; leadership
+// is given up when another instance holds it, or when renewals kept failing for half the
+// TTL (the lease may expire and be taken before we notice).
+type Elector struct {
+	Lock Lock
+	ID   string
+	TTL  time.Duration
+
+	mu        sync.Mutex
+	waited    bool
+	lastRenew time.Time
+	lost      chan struct{}
+	lostOnce  sync.Once
+}
+
+func NewElector(lock Lock, id string, ttl time.Duration) *Elector {
+	return &Elector{Lock: lock, ID: id, TTL: ttl, lost: make(chan struct{})}
+}
+
+// DefaultID identifies this process: hostname-pid
+func DefaultID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// Acquire blocks until this instance is the leader (standby mode while another one is)
+func (e *Elector) Acquire() {
+	lastHolder := ""
+	for {
+		holder, err := e.Lock.Acquire(e.ID, e.TTL)
+		switch {
+		case err != nil:
+			logger.Error("Failed to acquire leader lock, retrying", "lock", e.Lock.Name(), "error", err)
+		case holder == e.ID:
+			e.mu.Lock()
+			e.lastRenew = time.Now()
+			e.mu.Unlock()
+			logger.Info("👑 Leadership acquired, this instance trades", "id", e.ID, "lock", e.Lock.Name(), "after_standby", e.waited)
+			return
+		case holder != lastHolder:
+			logger.Warn("⏳ Standby: another instance is the leader, waiting for its lease to expire",
+				"leader", holder, "id", e.ID, "lock", e.Lock.Name(), "lease", e.TTL)
+			lastHolder = holder
+		}
+		e.waited = true
+		time.Sleep(e.heartbeat())
+	}
+}
+
+// TookOver reports whether this instance waited as standby before becoming leader
+func (e *Elector) TookOver() bool {
+	return e.waited
+}
+
+// Start renews the lease in background until it is lost
+func (e *Elector) Start() {
+	crash.Go("leader heartbeat", func() {
+		ticker := time.NewTicker(e.heartbeat())
+		defer ticker.Stop()
+
+		for range ticker.C {
+			held, err := e.Lock.Renew(e.ID, e.TTL)
+			e.mu.Lock()
+			if err == nil && held {
+				e.lastRenew = time.Now()
+				e.mu.Unlock()
+				continue
+			}
+			since := time.Since(e.lastRenew)
+			e.mu.Unlock()
+
+			if err == nil {
+				logger.Error("🚨 Leader lease taken by another instance", "id", e.ID, "lock", e.Lock.Name())
+				e.markLost()
+				return
+			}
+			logger.Warn("⚠️ Failed to renew leader lease", "lock", e.Lock.Name(), "since_last_renewal", since.Round(time.Second), "error", err)
+			if since > e.TTL/2 {
+				logger.Error("🚨 Leader lease could not be renewed in time, giving up leadership", "id", e.ID, "lock", e.Lock.Name())
+				e.markLost()
+				return
+			}
+		}
+	})
+}
+
+// Lost is closed when leadership is lost: the instance must stop trading at once
+func (e *Elector) Lost() <-chan struct{} {
+	return e.lost
+}
+
+// Release frees the lease (graceful shutdown), so the standby takes over right away
+func (e *Elector) Release() {
+	if err := e.Lock.Release(e.ID); err != nil {
+		logger.Warn("⚠️ Failed to release leader lock", "lock", e.Lock.Name(), "error", err)
+	}
+}
+
+func (e *Elector) markLost() {
+	e.lostOnce.Do(func() { close(e.lost) })
+}
+
+func (e *Elector) heartbeat() time.Duration {
+	return e.TTL / 5
+}
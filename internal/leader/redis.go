@@ -0,0 +1,130 @@
+package leader
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const redisTimeout = 3 * time.Second
+
+// Lua scripts: only the holder may extend or delete its lease
+const (
+	renewScript   = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("pexpire", KEYS[1], ARGV[2]) else return 0 end`
+	releaseScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) else return 0 end`
+)
+
+// RedisLock keeps the lease in a Redis key with a TTL (SET NX PX), so expiry does not depend
+// on the instances' clocks. One short connection per call: calls are seconds apart.
+type RedisLock struct {
+	Addr     string // host:port
+	Password string
+	Key      string
+}
+
+func NewRedisLock(addr, password, key string) *RedisLock {
+	return &RedisLock{Addr: addr, Password: password, Key: key}
+}
+
+func (l *RedisLock) Name() string {
+	return "redis:" + l.Addr + "/" + l.Key
+}
+
+func (l *RedisLock) Acquire(id string, ttl time.Duration) (string, error) {
+	reply, err := l.do("SET", l.Key, id, "NX", "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	if err != nil {
+		return "", err
+	}
+	if reply == "OK" {
+		return id, nil
+	}
+	holder, err := l.do("GET", l.Key)
+	if err != nil {
+		return "", err
+	}
+	if holder == "" {
+		return "", fmt.Errorf("lease expired while being read, retrying")
+	}
+	return holder, nil
+}
+
+func (l *RedisLock) Renew(id string, ttl time.Duration) (bool, error) {
+	reply, err := l.do("EVAL", renewScript, "1", l.Key, id, strconv.FormatInt(ttl.Milliseconds(), 10))
+	if err != nil {
+		return false, err
+	}
+	return reply == "1", nil
+}
+
+func (l *RedisLock) Release(id string) error {
+	_, err := l.do("EVAL", releaseScript, "1", l.Key, id)
+	return err
+}
+
+// do runs one command (after AUTH when a password is set) and returns the reply as text:
+// simple strings, integers and bulk strings as they are, nil as ""
+func (l *RedisLock) do(args ...string) (string, error) {
+	conn, err := net.DialTimeout("tcp", l.Addr, redisTimeout)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(redisTimeout))
+	r := bufio.NewReader(conn)
+
+	if l.Password != "" {
+		if _, err := roundTrip(conn, r, "AUTH", l.Password); err != nil {
+			return "", fmt.Errorf("redis AUTH: %w", err)
+		}
+	}
+	reply, err := roundTrip(conn, r, args...)
+	if err != nil {
+		return "", fmt.Errorf("redis %s: %w", args[0], err)
+	}
+	return reply, nil
+}
+
+// roundTrip writes a RESP array command and reads a scalar reply
+func roundTrip(w io.Writer, r *bufio.Reader, args ...string) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return "", err
+	}
+
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return "", fmt.Errorf("empty reply")
+	}
+	switch line[0] {
+	case '+', ':':
+		return line[1:], nil
+	case '-':
+		return "", fmt.Errorf("%s", line[1:])
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return "", fmt.Errorf("invalid bulk reply %q", line)
+		}
+		if size < 0 {
+			return "", nil
+		}
+		buf := make([]byte, size+2) // Payload + CRLF
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return "", err
+		}
+		return string(buf[:size]), nil
+	}
+	return "", fmt.Errorf("unexpected reply %q", line)
+}
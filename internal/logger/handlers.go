@@ -0,0 +1,171 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+type output struct {
+	handler slog.Handler
+	min     slog.Level // Records below this level are not written to this output
+}
+
+// moduleHandler applies the global level (or the module override) and fans the record out
+// to every output. The module is the package of the caller: core, service, api, market...
+type moduleHandler struct {
+	outputs []output
+	level   slog.Level
+	modules map[string]slog.Level
+}
+
+var moduleCache sync.Map // PC -> module name
+
+func (h *moduleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	lowest := h.level
+	for _, l := range h.modules {
+		lowest = min(lowest, l)
+	}
+	return level >= lowest
+}
+
+func (h *moduleHandler) Handle(ctx context.Context, r slog.Record) error {
+	level := h.level
+	if len(h.modules) > 0 {
+		if l, ok := h.modules[moduleOf(r.PC)]; ok {
+			level = l
+		}
+	}
+	if r.Level < level {
+		return nil
+	}
+
+	var firstErr error
+	for _, out := range h.outputs {
+		if r.Level < out.min {
+			continue
+		}
+		if err := out.handler.Handle(ctx, r.Clone()); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (h *moduleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler { return handler.WithAttrs(attrs) })
+}
+
+func (h *moduleHandler) WithGroup(name string) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler { return handler.WithGroup(name) })
+}
+
+func (h *moduleHandler) with(fn func(slog.Handler) slog.Handler) slog.Handler {
+	next := &moduleHandler{level: h.level, modules: h.modules, outputs: make([]output, len(h.outputs))}
+	for i, out := range h.outputs {
+		next.outputs[i] = output{handler: fn(out.handler), min: out.min}
+	}
+	return next
+}
+
+// moduleOf returns the package name of the function at pc
+// ("grid-trading-btc-binance/internal/service.(*StreamService).Start" -> "service")
+func moduleOf(pc uintptr) string {
+	if pc == 0 {
+		return ""
+	}
+	if module, ok := moduleCache.Load(pc); ok {
+		return module.(string)
+	}
+	name := ""
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		name = fn.Name()
+		if i := strings.LastIndex(name, "/"); i >= 0 {
+			name = name[i+1:]
+		}
+		if i := strings.Index(name, "."); i >= 0 {
+			name = name[:i]
+		}
+	}
+	moduleCache.Store(pc, name)
+	return name
+}
+
+// consoleHandler writes one readable line per record:
+// 14:03:05.123 INFO  [service] 📡 WebSocket Connected key=value
+type consoleHandler struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	attrs  string // Preformatted attributes from WithAttrs
+	prefix string // Group prefix from WithGroup
+}
+
+func newConsoleHandler(w io.Writer) *consoleHandler {
+	return &consoleHandler{mu: &sync.Mutex{}, w: w}
+}
+
+func (h *consoleHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *consoleHandler) Handle(_ context.Context, r slog.Record) error {
+	var b bytes.Buffer
+	b.WriteString(r.Time.Format("15:04:05.000"))
+	fmt.Fprintf(&b, " %-5s ", r.Level.String())
+	if module := moduleOf(r.PC); module != "" {
+		fmt.Fprintf(&b, "[%s] ", module)
+	}
+	b.WriteString(r.Message)
+	b.WriteString(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		writeAttr(&b, h.prefix, a)
+		return true
+	})
+	b.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.w.Write(b.Bytes())
+	return err
+}
+
+func (h *consoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var b bytes.Buffer
+	for _, a := range attrs {
+		writeAttr(&b, h.prefix, a)
+	}
+	return &consoleHandler{mu: h.mu, w: h.w, attrs: h.attrs + b.String(), prefix: h.prefix}
+}
+
+func (h *consoleHandler) WithGroup(name string) slog.Handler {
+	return &consoleHandler{mu: h.mu, w: h.w, attrs: h.attrs, prefix: h.prefix + name + "."}
+}
+
+func writeAttr(b *bytes.Buffer, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		for _, ga := range a.Value.Group() {
+			writeAttr(b, prefix+a.Key+".", ga)
+		}
+		return
+	}
+
+	var value string
+	switch a.Value.Kind() {
+	case slog.KindTime:
+		value = a.Value.Time().Format(time.RFC3339)
+	default:
+		value = a.Value.String()
+	}
+	if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+		value = fmt.Sprintf("%q", value)
+	}
+	fmt.Fprintf(b, " %s%s=%s", prefix, a.Key, value)
+}
@@ -1,30 +1,43 @@
 package logger
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"runtime"
+	"strings"
+	"sync"
 	"time"
 
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
+// Where the records go (LOG_OUTPUT)
+const (
+	OutputFile    = "file"    // JSON lines in File (rotated)
+	OutputConsole = "console" // Human-readable lines on stdout
+	OutputBoth    = "both"
+)
+
+// DefaultFile is the JSON log used before Configure and when LOG_FILE is not set
+const DefaultFile = "logs/app.log"
+
 var Log *slog.Logger
 
-func Init() {
-	// Ensure logs directory exists
-	// We're running from root usually, but let's be safe.
-	// "logs" dir validation.
-	// Note: We need to import "os"
-	_ = os.MkdirAll("logs", 0755)
-
-	fileWriter := &lumberjack.Logger{
-		Filename:   "logs/app.log",
-		MaxSize:    10, // megabytes
-		MaxBackups: 3,
-		MaxAge:     28,   // days
-		Compress:   true, // disabled by default
-	}
+// Options configures the outputs and levels (LOG_* settings)
+type Options struct {
+	Level        slog.Level
+	Output       string
+	File         string
+	ErrorFile    string                // Extra JSON file with only the Error records ("" = disabled)
+	ModuleLevels map[string]slog.Level // Package name (core, service, api...) -> minimum level
+}
 
+// Init starts the default logger (JSON into logs/app.log at Debug) so everything logged
+// before the configuration is loaded is kept. Configure replaces it afterwards.
+func Init() {
 	// Configurar Timezone Global para SP
 	loc, err := time.LoadLocation("America/Sao_Paulo")
 	if err != nil {
@@ -35,37 +48,124 @@ func Init() {
 		time.Local = loc
 	}
 
-	opts := &slog.HandlerOptions{
-		Level: slog.LevelDebug,
-		// Custom Replacement for Time format if needed, but changing Global Time.Local usually affects slog default handler
+	_ = Configure(Options{Level: slog.LevelDebug, Output: OutputFile, File: DefaultFile})
+}
+
+// Configure replaces the logger with the given outputs and levels
+func Configure(opts Options) error {
+	var outputs []output
+	if opts.Output == OutputFile || opts.Output == OutputBoth {
+		outputs = append(outputs, output{handler: slog.NewJSONHandler(rotated(opts.File), debugOpts), min: slog.LevelDebug})
+	}
+	if opts.Output == OutputConsole || opts.Output == OutputBoth {
+		outputs = append(outputs, output{handler: newConsoleHandler(os.Stdout), min: slog.LevelDebug})
+	}
+	if len(outputs) == 0 {
+		return fmt.Errorf("invalid log output %q (expected %s, %s or %s)", opts.Output, OutputFile, OutputConsole, OutputBoth)
+	}
+	if opts.ErrorFile != "" {
+		outputs = append(outputs, output{handler: slog.NewJSONHandler(rotated(opts.ErrorFile), debugOpts), min: slog.LevelError})
 	}
 
-	// Use JSON handler for structured logging
-	handler := slog.NewJSONHandler(fileWriter, opts)
-	Log = slog.New(handler)
+	Log = slog.New(&moduleHandler{outputs: outputs, level: opts.Level, modules: opts.ModuleLevels})
 	slog.SetDefault(Log)
+	return nil
 }
 
-func Info(msg string, args ...any) {
-	if Log != nil {
-		Log.Info(msg, args...)
+var debugOpts = &slog.HandlerOptions{Level: slog.LevelDebug} // Levels are filtered by moduleHandler
+
+// Rotating writers by path, reused when Configure runs again (one writer per file)
+var (
+	writersMu sync.Mutex
+	writers   = map[string]*lumberjack.Logger{}
+)
+
+func rotated(path string) io.Writer {
+	writersMu.Lock()
+	defer writersMu.Unlock()
+	if w, ok := writers[path]; ok {
+		return w
+	}
+
+	if dir := dirOf(path); dir != "" {
+		_ = os.MkdirAll(dir, 0755)
 	}
+	w := &lumberjack.Logger{
+		Filename:   path,
+		MaxSize:    10, // megabytes
+		MaxBackups: 3,
+		MaxAge:     28,   // days
+		Compress:   true, // disabled by default
+	}
+	writers[path] = w
+	return w
 }
 
-func Error(msg string, args ...any) {
-	if Log != nil {
-		Log.Error(msg, args...)
+func dirOf(path string) string {
+	if i := strings.LastIndexAny(path, `/\`); i > 0 {
+		return path[:i]
 	}
+	return ""
 }
 
-func Warn(msg string, args ...any) {
-	if Log != nil {
-		Log.Warn(msg, args...)
+// ParseLevel reads debug, info, warn or error
+func ParseLevel(value string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		return level, fmt.Errorf("invalid log level %q (expected debug, info, warn or error)", value)
 	}
+	return level, nil
+}
+
+// ParseModuleLevels reads per-module overrides: "service=warn,core=debug"
+func ParseModuleLevels(value string) (map[string]slog.Level, error) {
+	levels := map[string]slog.Level{}
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		module, raw, ok := strings.Cut(part, "=")
+		if !ok || strings.TrimSpace(module) == "" {
+			return nil, fmt.Errorf("invalid module level %q (expected module=level)", part)
+		}
+		level, err := ParseLevel(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("module %s: %w", module, err)
+		}
+		levels[strings.TrimSpace(module)] = level
+	}
+	return levels, nil
+}
+
+func Info(msg string, args ...any) {
+	log(slog.LevelInfo, msg, args...)
+}
+
+func Error(msg string, args ...any) {
+	log(slog.LevelError, msg, args...)
+}
+
+func Warn(msg string, args ...any) {
+	log(slog.LevelWarn, msg, args...)
 }
 
 func Debug(msg string, args ...any) {
-	if Log != nil {
-		Log.Debug(msg, args...)
+	log(slog.LevelDebug, msg, args...)
+}
+
+// log records the caller's PC (not this package's), which identifies the module
+func log(level slog.Level, msg string, args ...any) {
+	if Log == nil {
+		return
+	}
+	ctx := context.Background()
+	if !Log.Enabled(ctx, level) {
+		return
 	}
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:]) // Skip Callers, log and Info/Error/Warn/Debug
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	r.Add(args...)
+	_ = Log.Handler().Handle(ctx, r)
 }
@@ -0,0 +1,233 @@
+package market
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"grid-trading-btc-binance/internal/config"
+	"grid-trading-btc-binance/internal/crash"
+	"grid-trading-btc-binance/internal/logger"
+)
+
+// MacroEvent is one scheduled high-impact release (FOMC, CPI, payroll...)
+type MacroEvent struct {
+	Title  string
+	At     time.Time
+	Source string // file | feed
+}
+
+// feedEvent is an entry of the calendar feed, in the ForexFactory export format
+// ([{"title":"CPI m/m","country":"USD","date":"2026-11-12T08:30:00-05:00","impact":"High"}])
+type feedEvent struct {
+	Title   string `json:"title"`
+	Country string `json:"country"`
+	Date    string `json:"date"`
+	Impact  string `json:"impact"`
+}
+
+// MacroCalendar keeps the events of MACRO_CALENDAR_FILE and MACRO_CALENDAR_URL, reloaded every
+// MACRO_CALENDAR_REFRESH_HOURS, and tells when new grid entries must stop around one: from
+// MACRO_PAUSE_BEFORE_MIN before the release to MACRO_PAUSE_AFTER_MIN after it.
+type MacroCalendar struct {
+	Cfg    *config.Config
+	Client *http.Client
+
+	mu         sync.RWMutex
+	fileEvents []MacroEvent
+	feedEvents []MacroEvent
+	events     []MacroEvent // Both sources, by time
+}
+
+// NewMacroCalendar returns nil when neither MACRO_CALENDAR_FILE nor MACRO_CALENDAR_URL is set
+func NewMacroCalendar(cfg *config.Config) *MacroCalendar {
+	if cfg.MacroCalendarFile == "" && cfg.MacroCalendarURL == "" {
+		return nil
+	}
+	return &MacroCalendar{Cfg: cfg, Client: &http.Client{Timeout: 15 * time.Second}}
+}
+
+// Start loads the events now and then every MACRO_CALENDAR_REFRESH_HOURS
+func (c *MacroCalendar) Start() {
+	c.Refresh()
+	crash.Go("macro calendar", func() {
+		ticker := time.NewTicker(time.Duration(c.Cfg.MacroCalendarRefreshHours) * time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			c.Refresh()
+		}
+	})
+}
+
+// Refresh reloads both sources; a source that fails keeps its previous events
+func (c *MacroCalendar) Refresh() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.Cfg.MacroCalendarFile != "" {
+		events, err := c.readFile()
+		if err != nil {
+			logger.Warn("⚠️ Macro calendar: Failed to read the schedule file, keeping the previous events", "file", c.Cfg.MacroCalendarFile, "error", err)
+		} else {
+			c.fileEvents = events
+		}
+	}
+	if c.Cfg.MacroCalendarURL != "" {
+		events, err := c.fetchFeed()
+		if err != nil {
+			logger.Warn("⚠️ Macro calendar: Failed to fetch the feed, keeping the previous events", "url", c.Cfg.MacroCalendarURL, "error", err)
+		} else {
+			c.feedEvents = events
+		}
+	}
+
+	c.events = append(append([]MacroEvent{}, c.fileEvents...), c.feedEvents...)
+	sort.Slice(c.events, func(i, j int) bool { return c.events[i].At.Before(c.events[j].At) })
+	upcoming := 0
+	for _, e := range c.events {
+		if e.At.After(time.Now()) {
+			upcoming++
+		}
+	}
+	logger.Info("📅 Macro calendar loaded", "file_events", len(c.fileEvents), "feed_events", len(c.feedEvents), "upcoming", upcoming)
+}
+
+// Active returns the event whose pause is in force at now and when the entries resume (the end
+// of the last pause chained to it, when the windows of several events overlap)
+func (c *MacroCalendar) Active(now time.Time) (MacroEvent, time.Time, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	before := time.Duration(c.Cfg.MacroPauseBeforeMin) * time.Minute
+	after := time.Duration(c.Cfg.MacroPauseAfterMin) * time.Minute
+	for i, e := range c.events {
+		if now.Before(e.At.Add(-before)) || !now.Before(e.At.Add(after)) {
+			continue
+		}
+		resume := e.At.Add(after)
+		for _, next := range c.events[i+1:] {
+			if next.At.Add(-before).After(resume) {
+				break
+			}
+			resume = maxTime(resume, next.At.Add(after))
+		}
+		return e, resume, true
+	}
+	return MacroEvent{}, time.Time{}, false
+}
+
+// Next returns the first event whose pause starts after now
+func (c *MacroCalendar) Next(now time.Time) (MacroEvent, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	before := time.Duration(c.Cfg.MacroPauseBeforeMin) * time.Minute
+	for _, e := range c.events {
+		if e.At.Add(-before).After(now) {
+			return e, true
+		}
+	}
+	return MacroEvent{}, false
+}
+
+// readFile parses the schedule file: one event per line, "<YYYY-MM-DD> <HH:MM> <title>" in
+// MACRO_CALENDAR_TZ or "<RFC3339> <title>"; blank lines and lines starting with # are skipped
+func (c *MacroCalendar) readFile() ([]MacroEvent, error) {
+	f, err := os.Open(c.Cfg.MacroCalendarFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var events []MacroEvent
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		at, err := time.Parse(time.RFC3339, fields[0])
+		title := fields[1:]
+		if err != nil && len(fields) >= 2 {
+			at, err = time.ParseInLocation("2006-01-02 15:04", fields[0]+" "+fields[1], c.Cfg.MacroCalendarTZ)
+			title = fields[2:]
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: expected \"YYYY-MM-DD HH:MM <title>\" or \"<RFC3339> <title>\", got %q", n, line)
+		}
+		if len(title) == 0 {
+			return nil, fmt.Errorf("line %d: missing the event title", n)
+		}
+		events = append(events, MacroEvent{Title: strings.Join(title, " "), At: at, Source: "file"})
+	}
+	return events, scanner.Err()
+}
+
+// fetchFeed downloads the feed and keeps the events of MACRO_CALENDAR_COUNTRIES with at least
+// MACRO_CALENDAR_IMPACT (and a MACRO_CALENDAR_KEYWORDS match, when set)
+func (c *MacroCalendar) fetchFeed() ([]MacroEvent, error) {
+	resp, err := c.Client.Get(c.Cfg.MacroCalendarURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	var entries []feedEvent
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("invalid feed: %w", err)
+	}
+
+	var events []MacroEvent
+	for _, entry := range entries {
+		if !c.keepFeedEvent(entry) {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339, entry.Date)
+		if err != nil {
+			logger.Debug("Macro calendar: Skipping a feed event without a valid date", "title", entry.Title, "date", entry.Date)
+			continue
+		}
+		events = append(events, MacroEvent{Title: entry.Title + " (" + strings.ToUpper(entry.Country) + ")", At: at, Source: "feed"})
+	}
+	return events, nil
+}
+
+func (c *MacroCalendar) keepFeedEvent(entry feedEvent) bool {
+	impact := strings.ToLower(entry.Impact)
+	if impact != "high" && (c.Cfg.MacroCalendarImpact != "medium" || impact != "medium") {
+		return false
+	}
+	country := false
+	for _, want := range c.Cfg.MacroCalendarCountries {
+		country = country || strings.EqualFold(entry.Country, want)
+	}
+	if !country {
+		return false
+	}
+	if len(c.Cfg.MacroCalendarKeywords) == 0 {
+		return true
+	}
+	title := strings.ToLower(entry.Title)
+	for _, keyword := range c.Cfg.MacroCalendarKeywords {
+		if strings.Contains(title, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,213 @@
+package market
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"grid-trading-btc-binance/internal/api"
+	"grid-trading-btc-binance/internal/config"
+	"grid-trading-btc-binance/internal/crash"
+	"grid-trading-btc-binance/internal/data"
+	"grid-trading-btc-binance/internal/logger"
+)
+
+const (
+	levelMergePct     = 0.001 // Levels closer than this (0.1%) are one zone
+	volumeProfileBins = 50
+	volumeNodeMinPct  = 1.5 // A volume node holds at least this many times the mean volume per bin
+)
+
+// SupportLevels keeps the support and resistance zones of the symbol, recomputed from the klines
+// every SR_INTERVAL, and snaps the grid buys of PLACEMENT_MODE=support to just below them: a
+// buy resting under a support fills on the wick into it instead of at an arbitrary percentage.
+type SupportLevels struct {
+	Cfg    *config.Config
+	Klines *data.KlineStore
+
+	mu        sync.RWMutex
+	levels    []float64 // Ascending
+	updatedAt time.Time
+}
+
+func NewSupportLevels(cfg *config.Config, klines *data.KlineStore) *SupportLevels {
+	return &SupportLevels{Cfg: cfg, Klines: klines}
+}
+
+// Start computes the levels now and then once per SR_INTERVAL candle (at least every hour)
+func (s *SupportLevels) Start() {
+	s.Refresh()
+	interval, _ := data.IntervalDuration(s.Cfg.SRInterval)
+	interval = min(interval, time.Hour)
+	crash.Go("support levels", func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			s.Refresh()
+		}
+	})
+}
+
+// Refresh recomputes the levels; on failure the previous ones are kept
+func (s *SupportLevels) Refresh() {
+	if s.Klines.Binance != nil && s.Klines.Binance.RateLimited() {
+		return // 429/418 protection: keep the last levels
+	}
+	step, _ := data.IntervalDuration(s.Cfg.SRInterval)
+	klines, err := s.Klines.Recent(s.Cfg.Symbol, s.Cfg.SRInterval, s.Cfg.SRLookback, 2*step)
+	if err != nil {
+		logger.Warn("⚠️ Support levels: Failed to fetch klines, keeping the previous levels", "interval", s.Cfg.SRInterval, "error", err)
+		return
+	}
+	candles := parseCandles(klines)
+
+	var levels []float64
+	if s.Cfg.SRMethod == "volume" {
+		levels = volumeNodes(candles, volumeProfileBins)
+	} else {
+		levels = swingLevels(candles, s.Cfg.SRSwingWindow)
+	}
+	levels = mergeLevels(levels, levelMergePct)
+
+	s.mu.Lock()
+	s.levels, s.updatedAt = levels, time.Now()
+	s.mu.Unlock()
+	logger.Info("🧲 Support levels updated", "method", s.Cfg.SRMethod, "interval", s.Cfg.SRInterval, "candles", len(candles), "levels", len(levels))
+}
+
+// Levels returns the current zones, ascending, and when they were computed
+func (s *SupportLevels) Levels() ([]float64, time.Time) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]float64(nil), s.levels...), s.updatedAt
+}
+
+// SnapBuy returns the price of a buy that would go at bid: SR_OFFSET_PCT below the highest
+// level under the bid, when it is at most SR_MAX_SNAP_PCT away (ok false = no support in reach,
+// buy at the bid)
+func (s *SupportLevels) SnapBuy(bid float64) (price, support float64, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i := sort.SearchFloat64s(s.levels, bid) - 1 // Highest level strictly below the bid
+	if i < 0 || bid <= 0 {
+		return bid, 0, false
+	}
+	support = s.levels[i]
+	if (bid-support)/bid > s.Cfg.SRMaxSnapPct {
+		return bid, 0, false
+	}
+	return support * (1 - s.Cfg.SROffsetPct), support, true
+}
+
+// candle is a parsed kline of the level computations
+type candle struct {
+	high, low, volume float64
+}
+
+func parseCandles(klines []api.Kline) []candle {
+	candles := make([]candle, 0, len(klines))
+	for _, k := range klines {
+		h, _ := strconv.ParseFloat(k.High, 64)
+		l, _ := strconv.ParseFloat(k.Low, 64)
+		v, _ := strconv.ParseFloat(k.Volume, 64)
+		if h <= 0 || l <= 0 {
+			continue
+		}
+		candles = append(candles, candle{high: h, low: l, volume: v})
+	}
+	return candles
+}
+
+// swingLevels returns the swing lows and highs: candles whose low (high) is below (above) the
+// window candles on each side. A broken resistance acts as a support, so both count.
+func swingLevels(candles []candle, window int) []float64 {
+	var levels []float64
+	for i := window; i+window < len(candles); i++ {
+		low, high := true, true
+		for j := i - window; j <= i+window; j++ {
+			if j == i {
+				continue
+			}
+			// Ties on the right side still count, so a flat bottom yields its first candle
+			if candles[j].low < candles[i].low || (j < i && candles[j].low == candles[i].low) {
+				low = false
+			}
+			if candles[j].high > candles[i].high || (j < i && candles[j].high == candles[i].high) {
+				high = false
+			}
+		}
+		if low {
+			levels = append(levels, candles[i].low)
+		}
+		if high {
+			levels = append(levels, candles[i].high)
+		}
+	}
+	return levels
+}
+
+// volumeNodes builds the volume profile of the candles (each candle's volume spread evenly over
+// the price bins its range covers) and returns the centers of the local peaks holding at least
+// volumeNodeMinPct times the mean volume per bin
+func volumeNodes(candles []candle, bins int) []float64 {
+	if len(candles) == 0 {
+		return nil
+	}
+	lo, hi := candles[0].low, candles[0].high
+	for _, c := range candles[1:] {
+		lo, hi = math.Min(lo, c.low), math.Max(hi, c.high)
+	}
+	if hi <= lo {
+		return nil
+	}
+	width := (hi - lo) / float64(bins)
+	profile := make([]float64, bins)
+	total := 0.0
+	for _, c := range candles {
+		first := min(int((c.low-lo)/width), bins-1)
+		last := min(int((c.high-lo)/width), bins-1)
+		share := c.volume / float64(last-first+1)
+		for b := first; b <= last; b++ {
+			profile[b] += share
+		}
+		total += c.volume
+	}
+
+	threshold := total / float64(bins) * volumeNodeMinPct
+	var levels []float64
+	for b, v := range profile {
+		if v < threshold {
+			continue
+		}
+		if (b > 0 && profile[b-1] > v) || (b+1 < bins && profile[b+1] >= v) {
+			continue // Not the peak of its node
+		}
+		levels = append(levels, lo+(float64(b)+0.5)*width)
+	}
+	return levels
+}
+
+// mergeLevels sorts the levels and averages those within tolerance (relative) of the previous
+// one into a single zone
+func mergeLevels(levels []float64, tolerance float64) []float64 {
+	if len(levels) == 0 {
+		return nil
+	}
+	sorted := append([]float64(nil), levels...)
+	sort.Float64s(sorted)
+
+	var merged []float64
+	sum, count := sorted[0], 1
+	for _, level := range sorted[1:] {
+		if mean := sum / float64(count); (level-mean)/mean <= tolerance {
+			sum += level
+			count++
+			continue
+		}
+		merged = append(merged, sum/float64(count))
+		sum, count = level, 1
+	}
+	return append(merged, sum/float64(count))
+}
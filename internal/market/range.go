@@ -0,0 +1,92 @@
+package market
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"grid-trading-btc-binance/internal/api"
+)
+
+const (
+	rangeMinCoverage = 0.5 // Share of the suggested band the range must cover
+	rangeMaxWidth    = 4.0 // Range this many times wider than the suggested band spreads the grid too thin
+	rangeMinCandles  = 10  // Daily candles needed for a suggestion
+)
+
+// RangeSuggestion is a grid range derived from the recent daily candles: the Bollinger band of
+// the closes (SMA ± StdDevs σ), widened when needed so the price has at least ATRs average true
+// ranges of room on each side
+type RangeSuggestion struct {
+	Min, Max float64
+	Price    float64 // Last close
+	SMA      float64
+	StdDev   float64 // Of the closes
+	ATR      float64 // Average true range of a day
+	Days     int
+}
+
+// SuggestRange computes the suggestion from daily klines (oldest first). stdDevs and atrs are
+// the band width (2 = 30d ± 2σ) and the minimum room around the price.
+func SuggestRange(klines []api.Kline, stdDevs, atrs float64) (RangeSuggestion, error) {
+	var highs, lows, closes []float64
+	for _, k := range klines {
+		h, _ := strconv.ParseFloat(k.High, 64)
+		l, _ := strconv.ParseFloat(k.Low, 64)
+		c, _ := strconv.ParseFloat(k.Close, 64)
+		if h <= 0 || l <= 0 || c <= 0 {
+			continue
+		}
+		highs, lows, closes = append(highs, h), append(lows, l), append(closes, c)
+	}
+	n := len(closes)
+	if n < rangeMinCandles {
+		return RangeSuggestion{}, fmt.Errorf("%d daily candles, need at least %d", n, rangeMinCandles)
+	}
+
+	s := RangeSuggestion{Price: closes[n-1], Days: n}
+	for _, c := range closes {
+		s.SMA += c
+	}
+	s.SMA /= float64(n)
+	for _, c := range closes {
+		s.StdDev += (c - s.SMA) * (c - s.SMA)
+	}
+	s.StdDev = math.Sqrt(s.StdDev / float64(n))
+	for i := 1; i < n; i++ {
+		s.ATR += math.Max(highs[i]-lows[i], math.Max(math.Abs(highs[i]-closes[i-1]), math.Abs(lows[i]-closes[i-1])))
+	}
+	s.ATR /= float64(n - 1)
+
+	s.Min = math.Min(s.SMA-stdDevs*s.StdDev, s.Price-atrs*s.ATR)
+	s.Max = math.Max(s.SMA+stdDevs*s.StdDev, s.Price+atrs*s.ATR)
+	s.Min = math.Max(math.Floor(s.Min*100)/100, 0.01) // To the cent, outwards
+	s.Max = math.Ceil(s.Max*100) / 100
+	return s, nil
+}
+
+// Reasons of a stale range (see RangeSuggestion.Stale)
+const (
+	RangeOutside   = "outside"   // The price left the range
+	RangeUncovered = "uncovered" // The range covers less than half of the suggested band
+	RangeWide      = "wide"      // The range is so much wider than the band that the grid levels spread too thin
+)
+
+// Stale reports why the range [rangeMin, rangeMax] no longer fits the suggestion ("" = it fits)
+func (s RangeSuggestion) Stale(rangeMin, rangeMax float64) string {
+	switch {
+	case s.Price < rangeMin || s.Price > rangeMax:
+		return RangeOutside
+	case s.Coverage(rangeMin, rangeMax) < rangeMinCoverage:
+		return RangeUncovered
+	case rangeMax-rangeMin > (s.Max-s.Min)*rangeMaxWidth:
+		return RangeWide
+	}
+	return ""
+}
+
+// Coverage is the share (0-1) of the suggested band inside [rangeMin, rangeMax]
+func (s RangeSuggestion) Coverage(rangeMin, rangeMax float64) float64 {
+	overlap := math.Min(rangeMax, s.Max) - math.Max(rangeMin, s.Min)
+	return math.Max(overlap, 0) / (s.Max - s.Min)
+}
@@ -9,52 +9,123 @@ import (
 
 	"grid-trading-btc-binance/internal/api"
 	"grid-trading-btc-binance/internal/config"
+	"grid-trading-btc-binance/internal/crash"
+	"grid-trading-btc-binance/internal/data"
+	"grid-trading-btc-binance/internal/fees"
 	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/model"
+	"grid-trading-btc-binance/internal/repository"
+)
+
+const (
+	warmUpMaxAge = 30 * time.Minute // Cached candles older than this are useless for the current regime
+	highVolFloor = 0.002            // Short term volatility needed for HIGH_VOL_CRASH, against low volatility noise
+
+	regimeNormal  = "NORMAL"
+	regimeHighVol = "HIGH_VOL_CRASH"
 )
 
 type VolatilityService struct {
 	Cfg     *config.Config
 	Binance *api.BinanceClient
+	Klines  *data.KlineStore            // Startup warm-up (falls back to the local cache if REST is down)
+	State   *repository.StateRepository // Persists the last reading for the next start (nil = not kept)
+	History *repository.RegimeHistory   // Regime changes (nil = only logged)
 
 	// State
-	currentVol float64
-	multiplier float64
-	lastUpdate time.Time
-	mu         sync.RWMutex
+	currentVol  float64
+	multiplier  float64
+	regime      string
+	regimeSince time.Time // Start of the regime (zero = not judged yet)
+	lastUpdate  time.Time
+	mu          sync.RWMutex
 }
 
-func NewVolatilityService(cfg *config.Config, binance *api.BinanceClient) *VolatilityService {
+func NewVolatilityService(cfg *config.Config, binance *api.BinanceClient, klines *data.KlineStore) *VolatilityService {
 	return &VolatilityService{
 		Cfg:        cfg,
 		Binance:    binance,
+		Klines:     klines,
 		multiplier: cfg.LowVolMultiplier, // Default to Low Vol Multiplier (Normal Regime)
+		regime:     regimeNormal,
 	}
 }
 
-// StartPolling begins the background loop to fetch candles and update volatility
+// StartPolling restores the last reading, warms up before returning (so the first orders after a
+// restart already use the dynamic spacing) and begins the background loop to fetch candles and
+// update volatility
 func (s *VolatilityService) StartPolling() {
-	go func() {
+	s.restore()
+	// Initial Run (through the klines cache, so a REST outage at startup still warms up)
+	s.warmUp()
+
+	crash.Go("volatility polling", func() {
 		ticker := time.NewTicker(60 * time.Second)
 		defer ticker.Stop()
 
-		// Initial Run
-		s.UpdateVolatility()
-
 		for range ticker.C {
 			s.UpdateVolatility()
 		}
-	}()
+	})
+}
+
+// restore loads the persisted reading when it is not older than warmUpMaxAge. The multiplier
+// follows the regime with the current configuration, which may have changed since.
+func (s *VolatilityService) restore() {
+	if s.State == nil {
+		return
+	}
+	reading := s.State.Get().Volatility
+	if reading == nil || reading.Vol <= 0 {
+		return
+	}
+	age := time.Since(reading.At)
+	if age > warmUpMaxAge {
+		logger.Info("📊 Persisted volatility reading too old, waiting for the warm-up", "age", age.Round(time.Second).String())
+		return
+	}
+
+	s.mu.Lock()
+	s.currentVol = reading.Vol
+	s.regime, s.multiplier = regimeNormal, s.Cfg.LowVolMultiplier
+	if reading.Regime == regimeHighVol {
+		s.regime, s.multiplier = regimeHighVol, s.Cfg.HighVolMultiplier
+	}
+	s.regimeSince = reading.Since
+	s.lastUpdate = reading.At
+	s.mu.Unlock()
+	logger.Info("📊 Volatility restored from the last run", "short_vol", reading.Vol, "regime", reading.Regime, "age", age.Round(time.Second).String())
+}
+
+// warmUp computes the first volatility reading from the klines store
+func (s *VolatilityService) warmUp() {
+	if s.Klines == nil {
+		s.UpdateVolatility()
+		return
+	}
+	klines, err := s.Klines.Recent(s.Cfg.Symbol, "1m", 30, warmUpMaxAge)
+	if err != nil {
+		logger.Error("⚠️ VolatilityService: Warm-up failed, waiting for the next update", "error", err)
+		return
+	}
+	s.apply(klines)
 }
 
 // UpdateVolatility fetches 1m candles and calculates Garman-Klass Volatility + Regime
 func (s *VolatilityService) UpdateVolatility() {
+	if s.Binance.RateLimited() {
+		return // 429/418 protection: keep the last reading
+	}
 	// We need lookback for Long Term (20) + some buffer. Let's get 30 candles.
 	klines, err := s.Binance.GetRecentKlines(s.Cfg.Symbol, "1m", 30)
 	if err != nil {
 		logger.Error("⚠️ VolatilityService: Failed to fetch klines", "error", err)
 		return
 	}
+	s.apply(klines)
+}
 
+func (s *VolatilityService) apply(klines []api.Kline) {
 	if len(klines) < 20 {
 		logger.Warn("⚠️ VolatilityService: Not enough klines for calculation", "count", len(klines))
 		return
@@ -70,21 +141,37 @@ func (s *VolatilityService) UpdateVolatility() {
 	// 2. Calculate Long Term Volatility (Last 20 mins)
 	longVol := s.calculateGK(klines[len(klines)-20:])
 
-	// 3. Regime Detection
-	// If Short > Long * 1.5 -> Acceleration/Crash -> High Vol Multiplier
+	// 3. Regime Detection (with hysteresis)
+	// If Short > Long * REGIME_ENTER_RATIO -> Acceleration/Crash -> High Vol Multiplier, until
+	// Short < Long * REGIME_EXIT_RATIO. Either way a regime holds REGIME_MIN_DWELL_MIN minutes.
 	// Fix: Added Threshold > 0.002 (0.2%) to avoid Low Volatility Noise triggering Crash Mode
-	var newMultiplier float64
-	var regime string
+	s.mu.Lock()
+	now := time.Now()
+	current, since := s.regime, s.regimeSince
+	regime := current
+	if current == regimeHighVol {
+		if shortVol <= highVolFloor || longVol <= 0 || shortVol < longVol*s.Cfg.RegimeExitRatio {
+			regime = regimeNormal
+		}
+	} else if longVol > 0 && shortVol > longVol*s.Cfg.RegimeEnterRatio && shortVol > highVolFloor {
+		regime = regimeHighVol
+	}
+	dwell := time.Duration(s.Cfg.RegimeMinDwellMin) * time.Minute
+	if regime != current && !since.IsZero() && now.Sub(since) < dwell {
+		logger.Debug("📊 Volatility regime change held by the minimum dwell time", "regime", current, "candidate", regime,
+			"held_for", now.Sub(since).Round(time.Second).String(), "min_dwell", dwell.String())
+		regime = current
+	}
+	changed := regime != current
+	if changed || since.IsZero() {
+		s.regimeSince = now
+	}
 
-	if longVol > 0 && shortVol > (longVol*1.5) && shortVol > 0.002 {
+	newMultiplier := s.Cfg.LowVolMultiplier
+	if regime == regimeHighVol {
 		newMultiplier = s.Cfg.HighVolMultiplier
-		regime = "HIGH_VOL_CRASH"
-	} else {
-		newMultiplier = s.Cfg.LowVolMultiplier
-		regime = "NORMAL"
 	}
 
-	s.mu.Lock()
 	s.currentVol = shortVol // Use short term vol as base? Or just use the multiplier logic on base spacing?
 	// User Prompt:
 	// "Substituir o GRID_SPACING_PCT fixo por um cálculo dinâmico de volatilidade usando o estimador Garman-Klass"
@@ -96,9 +183,27 @@ func (s *VolatilityService) UpdateVolatility() {
 	// This fits "Opening the grid".
 
 	s.multiplier = newMultiplier
-	s.lastUpdate = time.Now()
+	s.regime = regime
+	s.lastUpdate = now
+	reading := model.VolatilityReading{Vol: shortVol, Multiplier: newMultiplier, Regime: regime, Since: s.regimeSince, At: now}
 	s.mu.Unlock()
 
+	if changed {
+		logger.Warn("🌪️ Volatility regime changed", "from", current, "to", regime, "short_vol", shortVol, "long_vol", longVol, "multiplier", newMultiplier)
+		if s.History != nil {
+			err := s.History.Append(model.RegimeChange{At: now, From: current, To: regime, ShortVol: shortVol, LongVol: longVol})
+			if err != nil {
+				logger.Warn("⚠️ VolatilityService: Failed to record the regime change", "error", err)
+			}
+		}
+	}
+
+	if s.State != nil && shortVol > 0 {
+		if err := s.State.SetVolatility(reading); err != nil {
+			logger.Warn("⚠️ VolatilityService: Failed to persist the reading", "error", err)
+		}
+	}
+
 	logger.Info("📊 Volatility Update (Garman-Klass)",
 		"short_vol", shortVol,
 		"long_vol", longVol,
@@ -168,12 +273,34 @@ func (s *VolatilityService) GetDynamicSpacing() float64 {
 
 	spacing := s.currentVol * s.multiplier
 
-	// SAFETY: Min Spacing 0.2% (0.002) to ensure profit after fees (0.15%)
-	if spacing < 0.002 {
-		spacing = 0.002
+	// SAFETY: never below the round-trip fees of the pair plus a margin (0.2% at the BNB rate,
+	// much tighter on zero-fee pairs)
+	return BoundSpacing(s.Cfg, spacing)
+}
+
+// BoundSpacing clamps a dynamic spacing to MIN_DYNAMIC_SPACING_PCT / MAX_DYNAMIC_SPACING_PCT.
+// The fee model floor wins over a lower ceiling: below it every exit would lose money.
+func BoundSpacing(cfg *config.Config, spacing float64) float64 {
+	if cfg.MaxDynamicSpacingPct > 0 {
+		spacing = math.Min(spacing, cfg.MaxDynamicSpacingPct)
 	}
+	return math.Max(spacing, math.Max(cfg.MinDynamicSpacingPct, fees.For(cfg).MinSpacing()))
+}
+
+// SpacingFor is GetDynamicSpacing computed with the multipliers and fallback of another
+// configuration (the shadow strategy) for the same volatility reading and regime
+func (s *VolatilityService) SpacingFor(cfg *config.Config) float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	return spacing
+	if s.currentVol == 0 {
+		return cfg.GridSpacingPct
+	}
+	multiplier := cfg.LowVolMultiplier
+	if s.regime == regimeHighVol {
+		multiplier = cfg.HighVolMultiplier
+	}
+	return BoundSpacing(cfg, s.currentVol*multiplier)
 }
 
 // GetMetrics returns the current internal state for logging/reporting
@@ -183,6 +310,67 @@ func (s *VolatilityService) GetMetrics() (shortVol, multiplier float64) {
 	return s.currentVol, s.multiplier
 }
 
+// GetRegime returns the current volatility regime (NORMAL or HIGH_VOL_CRASH)
+func (s *VolatilityService) GetRegime() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.regime
+}
+
+// RegimeStats summarizes the regime history over a period
+type RegimeStats struct {
+	Changes    int
+	HighVolPct float64 // Share of the period spent in HIGH_VOL_CRASH (0-100)
+}
+
+// RegimeStats reads the regime history between from and to (capped at now). Periods before the
+// first recorded change count in the regime it left, or in the current one without any.
+func (s *VolatilityService) RegimeStats(from, to time.Time) (RegimeStats, error) {
+	var stats RegimeStats
+	if s.History == nil {
+		return stats, nil
+	}
+	changes, err := s.History.Changes()
+	if err != nil {
+		return stats, err
+	}
+	if now := time.Now(); to.After(now) {
+		to = now
+	}
+	if !to.After(from) {
+		return stats, nil
+	}
+
+	var regime string
+	var high time.Duration
+	cursor := from
+	for _, c := range changes {
+		if c.At.Before(from) {
+			regime = c.To
+			continue
+		}
+		if regime == "" {
+			regime = c.From
+		}
+		if !c.At.Before(to) {
+			break
+		}
+		if regime == regimeHighVol {
+			high += c.At.Sub(cursor)
+		}
+		cursor, regime = c.At, c.To
+		stats.Changes++
+	}
+	if regime == "" {
+		regime = s.GetRegime()
+	}
+	if regime == regimeHighVol {
+		high += to.Sub(cursor)
+	}
+	stats.HighVolPct = float64(high) / float64(to.Sub(from)) * 100
+	return stats, nil
+}
+
 // GetLastHourRange fetches the High and Low prices of the last 1h candle to estimate volatility/drawdown
 func (s *VolatilityService) GetLastHourRange() (high, low float64, err error) {
 	// Fetch last 1 candle of 1h interval
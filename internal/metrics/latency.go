@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+const latencyWindow = 500 // Samples kept per series (percentiles cover the most recent ones)
+
+// LatencyStats summarizes the most recent samples of a latency series
+type LatencyStats struct {
+	Count int
+	P50   time.Duration
+	P90   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+// String renders "p50=120ms p90=310ms p99=900ms max=1.2s (n=42)"
+func (l LatencyStats) String() string {
+	if l.Count == 0 {
+		return "no samples"
+	}
+	return fmt.Sprintf("p50=%s p90=%s p99=%s max=%s (n=%d)",
+		roundLatency(l.P50), roundLatency(l.P90), roundLatency(l.P99), roundLatency(l.Max), l.Count)
+}
+
+func roundLatency(d time.Duration) time.Duration {
+	return d.Round(time.Millisecond)
+}
+
+// latencySeries is a ring buffer of the last latencyWindow samples
+type latencySeries struct {
+	samples []time.Duration
+	next    int
+}
+
+func (s *latencySeries) add(d time.Duration) {
+	if len(s.samples) < latencyWindow {
+		s.samples = append(s.samples, d)
+		return
+	}
+	s.samples[s.next] = d
+	s.next = (s.next + 1) % latencyWindow
+}
+
+func (s *latencySeries) stats() LatencyStats {
+	if len(s.samples) == 0 {
+		return LatencyStats{}
+	}
+	sorted := append([]time.Duration(nil), s.samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return LatencyStats{
+		Count: len(sorted),
+		P50:   percentile(sorted, 0.50),
+		P90:   percentile(sorted, 0.90),
+		P99:   percentile(sorted, 0.99),
+		Max:   sorted[len(sorted)-1],
+	}
+}
+
+// percentile uses the nearest-rank method on sorted samples
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(p*float64(len(sorted)) + 0.5)
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
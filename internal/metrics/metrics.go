@@ -5,10 +5,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
+	"grid-trading-btc-binance/internal/api"
 	"grid-trading-btc-binance/internal/config"
 	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/repository"
 )
 
 type Tracker struct {
@@ -19,23 +22,61 @@ type Tracker struct {
 	BatchCount  int
 	TotalCycles int64
 	MsTimeProd  int64
+	Debounced   int64 // Tickers skipped by the strategy debounce since startup
 	StartTime   time.Time
 	cfg         *config.Config
+	stateRepo   *repository.StateRepository // Lifetime counters survive restarts (nil = not persisted)
+
+	// Order latencies, recorded from the update workers and the bot loop
+	latencyMu   sync.Mutex
+	fillToExit  latencySeries // executionReport FILLED received -> maker exit acknowledged
+	createOrder latencySeries // CreateOrder round trip
+
+	// REST connections, recorded from the HTTP transport
+	connMu    sync.Mutex
+	conns     ConnectionStats
+	connSetup latencySeries // Connect + TLS handshake of the new connections
+	firstByte latencySeries // Request sent -> first response byte
+}
+
+// ConnectionStats summarizes the connection side of the REST calls since startup
+type ConnectionStats struct {
+	Calls     int64
+	Reused    int64 // Over a kept-alive connection
+	Resumed   int64 // New connections with an abbreviated (resumed) TLS handshake
+	HTTP2     int64
+	Setup     LatencyStats // New connections only
+	FirstByte LatencyStats
+}
+
+// String renders "reused=97% (n=320) new=9 resumed=8 setup p50=... ttfb p50=..."
+func (c ConnectionStats) String() string {
+	if c.Calls == 0 {
+		return "no samples"
+	}
+	return fmt.Sprintf("reused=%.0f%% (n=%d) new=%d resumed=%d http2=%d setup %s ttfb %s",
+		float64(c.Reused)/float64(c.Calls)*100, c.Calls, c.Calls-c.Reused, c.Resumed, c.HTTP2, c.Setup, c.FirstByte)
 }
 
 // MetricsPayload represents the JSON payload for the metrics API
 type MetricsPayload struct {
-	Strategy    string `json:"strategy"`
-	Cycles      string `json:"cycles"`
-	Min         string `json:"min"`
-	Max         string `json:"max"`
-	Avg         string `json:"avg"`
-	Uptime      string `json:"uptime"`
-	LastUpdated string `json:"lastUpdated"`
-	Now         string `json:"now"`
+	Strategy       string `json:"strategy"`
+	Cycles         string `json:"cycles"`
+	Min            string `json:"min"`
+	Max            string `json:"max"`
+	Avg            string `json:"avg"`
+	Uptime         string `json:"uptime"`
+	LastUpdated    string `json:"lastUpdated"`
+	Now            string `json:"now"`
+	FillToExitP50  string `json:"fillToExitP50"`  // Seconds, empty without samples
+	FillToExitP99  string `json:"fillToExitP99"`  // Seconds, empty without samples
+	CreateOrderP50 string `json:"createOrderP50"` // Seconds, empty without samples
+	CreateOrderP99 string `json:"createOrderP99"` // Seconds, empty without samples
+	RestReusedPct  string `json:"restReusedPct"`  // REST calls over a kept-alive connection, empty without samples
+	RestTTFBP50    string `json:"restTtfbP50"`    // Seconds, empty without samples
 }
 
-func NewTracker(cfg *config.Config) *Tracker {
+func NewTracker(cfg *config.Config, stateRepo *repository.StateRepository) *Tracker {
 	return &Tracker{
 		MinTime:     time.Duration(1<<63 - 1), // Max duration
 		MaxTime:     0,
@@ -43,6 +84,7 @@ func NewTracker(cfg *config.Config) *Tracker {
 		MsTimeProd:  cfg.MsTimeProduction,
 		StartTime:   time.Now(),
 		cfg:         cfg,
+		stateRepo:   stateRepo,
 	}
 }
 
@@ -69,6 +111,10 @@ func (t *Tracker) TrackCycle(duration time.Duration) {
 			"max_us", t.MaxTime.Microseconds(),
 			"avg_us", avgTime.Microseconds(),
 			"total_cycles", t.TotalCycles,
+			"debounced", t.Debounced,
+			"fill_to_exit", t.FillToExitStats().String(),
+			"create_order", t.CreateOrderStats().String(),
+			"rest_connections", t.ConnectionStats().String(),
 		)
 
 		// Send metrics to external API
@@ -98,15 +144,26 @@ func (t *Tracker) sendMetricsToAPI(avgTime time.Duration) {
 	maxSec := float64(t.MaxTime.Microseconds()) / 1000000.0
 	avgSec := float64(avgTime.Microseconds()) / 1000000.0
 
+	fillToExit := t.FillToExitStats()
+	createOrder := t.CreateOrderStats()
+	conns := t.ConnectionStats()
 	payload := MetricsPayload{
-		Strategy:    "grid-trading-bitcoin-binance",
-		Cycles:      fmt.Sprintf("%d", t.TotalCycles),
-		Min:         fmt.Sprintf("%.3f", minSec),
-		Max:         fmt.Sprintf("%.3f", maxSec),
-		Avg:         fmt.Sprintf("%.3f", avgSec),
-		Uptime:      fmt.Sprintf("%d", uptime),
-		LastUpdated: lastUpdated,
-		Now:         nowFormatted,
+		Strategy:       "grid-trading-bitcoin-binance",
+		Cycles:         fmt.Sprintf("%d", t.TotalCycles),
+		Min:            fmt.Sprintf("%.3f", minSec),
+		Max:            fmt.Sprintf("%.3f", maxSec),
+		Avg:            fmt.Sprintf("%.3f", avgSec),
+		Uptime:         fmt.Sprintf("%d", uptime),
+		LastUpdated:    lastUpdated,
+		Now:            nowFormatted,
+		FillToExitP50:  latencySeconds(fillToExit, fillToExit.P50),
+		FillToExitP99:  latencySeconds(fillToExit, fillToExit.P99),
+		CreateOrderP50: latencySeconds(createOrder, createOrder.P50),
+		CreateOrderP99: latencySeconds(createOrder, createOrder.P99),
+		RestTTFBP50:    latencySeconds(conns.FirstByte, conns.FirstByte.P50),
+	}
+	if conns.Calls > 0 {
+		payload.RestReusedPct = fmt.Sprintf("%.1f", float64(conns.Reused)/float64(conns.Calls)*100)
 	}
 
 	jsonData, err := json.Marshal(payload)
@@ -133,7 +190,103 @@ func (t *Tracker) sendMetricsToAPI(avgTime time.Duration) {
 	defer resp.Body.Close()
 }
 
+// TrackDebounced counts a ticker the strategy skipped (bot loop only)
+func (t *Tracker) TrackDebounced() {
+	if t == nil {
+		return
+	}
+	t.Debounced++
+}
+
+// TrackFillToExit records the time from the FILLED executionReport to the maker exit ack.
+// It is what the price can move against the exit during a crash.
+func (t *Tracker) TrackFillToExit(d time.Duration) {
+	if t == nil {
+		return
+	}
+	t.latencyMu.Lock()
+	t.fillToExit.add(d)
+	t.latencyMu.Unlock()
+}
+
+// TrackCreateOrder records one CreateOrder round trip
+func (t *Tracker) TrackCreateOrder(d time.Duration) {
+	if t == nil {
+		return
+	}
+	t.latencyMu.Lock()
+	t.createOrder.add(d)
+	t.latencyMu.Unlock()
+}
+
+// TrackConnection records the connection side of one REST call
+func (t *Tracker) TrackConnection(trace api.ConnTrace) {
+	if t == nil {
+		return
+	}
+	t.connMu.Lock()
+	defer t.connMu.Unlock()
+	t.conns.Calls++
+	if trace.Reused {
+		t.conns.Reused++
+	} else {
+		t.connSetup.add(trace.Connect + trace.Handshake)
+		if trace.Resumed {
+			t.conns.Resumed++
+		}
+	}
+	if trace.Protocol == "HTTP/2.0" {
+		t.conns.HTTP2++
+	}
+	t.firstByte.add(trace.FirstByte)
+}
+
+// ConnectionStats returns the connection reuse and latencies of the REST calls
+func (t *Tracker) ConnectionStats() ConnectionStats {
+	if t == nil {
+		return ConnectionStats{}
+	}
+	t.connMu.Lock()
+	defer t.connMu.Unlock()
+	stats := t.conns
+	stats.Setup = t.connSetup.stats()
+	stats.FirstByte = t.firstByte.stats()
+	return stats
+}
+
+// FillToExitStats returns the fill-to-exit percentiles of the recent fills
+func (t *Tracker) FillToExitStats() LatencyStats {
+	if t == nil {
+		return LatencyStats{}
+	}
+	t.latencyMu.Lock()
+	defer t.latencyMu.Unlock()
+	return t.fillToExit.stats()
+}
+
+// CreateOrderStats returns the CreateOrder round-trip percentiles of the recent orders
+func (t *Tracker) CreateOrderStats() LatencyStats {
+	if t == nil {
+		return LatencyStats{}
+	}
+	t.latencyMu.Lock()
+	defer t.latencyMu.Unlock()
+	return t.createOrder.stats()
+}
+
+func latencySeconds(stats LatencyStats, d time.Duration) string {
+	if stats.Count == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// persistMetrics saves the lifetime counters to the runtime state (never to .env)
 func (t *Tracker) persistMetrics() {
-	// Persistence to .env removed per user request.
-	// Metrics are now ephemeral or logged only.
+	if t.stateRepo == nil {
+		return
+	}
+	if err := t.stateRepo.SetCycleStats(t.TotalCycles, t.MsTimeProd); err != nil {
+		logger.Error("Failed to persist cycle metrics", "error", err)
+	}
 }
@@ -7,8 +7,10 @@ type ExchangeInfoResponse struct {
 
 // SymbolInfo represents a single symbol's configuration
 type SymbolInfo struct {
-	Symbol  string   `json:"symbol"`
-	Filters []Filter `json:"filters"`
+	Symbol     string   `json:"symbol"`
+	BaseAsset  string   `json:"baseAsset"`
+	QuoteAsset string   `json:"quoteAsset"`
+	Filters    []Filter `json:"filters"`
 }
 
 // Filter represents a trading rule filter
@@ -0,0 +1,59 @@
+package model
+
+import "time"
+
+// Transaction lifecycle statuses:
+//
+//	NEW -> OPEN -> FILLED -> EXIT_PLACED -> CLOSED
+//	 |      |        |          |
+//	 |      |        +-> FAILED +-> FILLED (exit canceled, needs a new one)
+//	 |      +-> CANCELED
+//	 +-> FAILED
+//
+// The persisted values of pre-existing statuses are kept ("waiting_sell", "failed_placement"),
+// so older transactions.json files load unchanged.
+const (
+	StatusNew        = "new"
+	StatusOpen       = "open"
+	StatusFilled     = "filled"
+	StatusExitPlaced = "waiting_sell"
+	StatusClosed     = "closed"
+	StatusCanceled   = "cancelled"
+	StatusFailed     = "failed_placement"
+)
+
+var allowedTransitions = map[string][]string{
+	StatusNew:        {StatusOpen, StatusFilled, StatusCanceled, StatusFailed},
+	StatusOpen:       {StatusFilled, StatusCanceled, StatusClosed}, // OPEN -> CLOSED for standalone sells
+	StatusFilled:     {StatusExitPlaced, StatusFailed, StatusClosed},
+	StatusExitPlaced: {StatusFilled, StatusClosed},
+	StatusFailed:     {StatusExitPlaced, StatusFilled, StatusClosed},
+}
+
+// CanTransition reports whether a transaction may move from one status to another.
+// Staying in a non-terminal status is allowed (no-op); terminal statuses never change.
+func CanTransition(from, to string) bool {
+	if from == to {
+		return !IsTerminal(from)
+	}
+	for _, next := range allowedTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTerminal reports whether the status ends the lifecycle
+func IsTerminal(status string) bool {
+	return status == StatusClosed || status == StatusCanceled
+}
+
+// TransactionEvent is an entry of the append-only lifecycle log
+type TransactionEvent struct {
+	TxID   string    `json:"txId"`
+	From   string    `json:"from"`
+	To     string    `json:"to"`
+	Reason string    `json:"reason,omitempty"`
+	At     time.Time `json:"at"`
+}
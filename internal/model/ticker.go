@@ -5,7 +5,9 @@ import "time"
 type Ticker struct {
 	Symbol string    `json:"symbol"`
 	Price  float64   `json:"price"`
-	Bid    float64   `json:"bid"` // Best Bid Price
-	Ask    float64   `json:"ask"` // Best Ask Price
+	Bid    float64   `json:"bid"`     // Best Bid Price
+	Ask    float64   `json:"ask"`     // Best Ask Price
+	BidQty float64   `json:"bid_qty"` // Quantity at the best bid
+	AskQty float64   `json:"ask_qty"` // Quantity at the best ask
 	Time   time.Time `json:"time"`
 }
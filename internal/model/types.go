@@ -1,6 +1,10 @@
 package model
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 // Transaction represents a trade execution
 type Transaction struct {
@@ -11,7 +15,7 @@ type Transaction struct {
 	Amount            string     `json:"amount"`
 	Price             string     `json:"price"`
 	Fee               string     `json:"fee"`
-	StatusTransaction string     `json:"statusTransaction"` // open, filled, cancelled, waiting_sell, closed
+	StatusTransaction string     `json:"statusTransaction"` // See lifecycle.go (StatusOpen, StatusFilled, ...)
 	Notes             string     `json:"notes"`
 	ClosedAt          *time.Time `json:"closedAt,omitempty"`
 	CreatedAt         time.Time  `json:"createdAt"`
@@ -22,6 +26,29 @@ type Transaction struct {
 	SellPrice     float64   `json:"sellPrice,omitempty"`     // Preço Limit da venda
 	SellCreatedAt time.Time `json:"sellCreatedAt,omitempty"` // Timestamp da criação da venda
 	QuantitySold  float64   `json:"quantitySold,omitempty"`  // Controle de execução parcial da venda
+
+	// Exit Recovery Fields (failed_placement)
+	ExitFailedAt   *time.Time `json:"exitFailedAt,omitempty"`   // Primeira falha da saída, base do prazo de recuperação
+	ExitRecoveries int        `json:"exitRecoveries,omitempty"` // Novas tentativas feitas pelo job de recuperação
+	ExitEscalated  bool       `json:"exitEscalated,omitempty"`  // Prazo esgotado: aguarda intervenção manual
+
+	// Trade Ledger Fields
+	EntryRegime    string  `json:"entryRegime,omitempty"`    // Regime de volatilidade quando a compra foi colocada
+	ExitSpacingPct float64 `json:"exitSpacingPct,omitempty"` // Spacing usado no alvo da venda (0.004 = 0.4%)
+	EntryLevel     int     `json:"entryLevel,omitempty"`     // Nível do grid (1 = primeira compra) em que a compra entrou
+}
+
+// GridLevel returns the grid level the buy was placed at, from the "Grid L<n>" note for buys
+// recorded before EntryLevel existed (0 = not a grid buy, or unknown)
+func (t Transaction) GridLevel() int {
+	if t.EntryLevel > 0 {
+		return t.EntryLevel
+	}
+	var level int
+	if i := strings.Index(t.Notes, "Grid L"); i >= 0 {
+		fmt.Sscanf(t.Notes[i+len("Grid L"):], "%d", &level)
+	}
+	return level
 }
 
 // Balance represents the user's balance for a specific currency
@@ -29,3 +56,154 @@ type Balance struct {
 	Currency string  `json:"currency"`
 	Amount   float64 `json:"amount"`
 }
+
+// EquityBaseline tracks the capital used as sizing base when profits are compounded
+type EquityBaseline struct {
+	BaseCapital    float64   `json:"baseCapital"`    // Capital at the start of compounding
+	RealizedProfit float64   `json:"realizedProfit"` // Accumulated realized profit since StartedAt
+	NetDeposits    float64   `json:"netDeposits"`    // Deposits minus withdrawals (USDT) since StartedAt
+	EarnInterest   float64   `json:"earnInterest"`   // Simple Earn interest on the idle USDT since StartedAt
+	StartedAt      time.Time `json:"startedAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// Equity returns the current sizing base (capital + realized profits + capital flows + interest)
+func (e EquityBaseline) Equity() float64 {
+	return e.BaseCapital + e.RealizedProfit + e.NetDeposits + e.EarnInterest
+}
+
+// Vault holds the share of realized profit skimmed out of trading
+type Vault struct {
+	TotalSkimmed       float64                `json:"totalSkimmed"`
+	Transferred        float64                `json:"transferred"` // Moved out of the Spot wallet
+	Pending            float64                `json:"pending"`     // Skimmed but still in Spot (excluded from trading)
+	Months             map[string]*VaultMonth `json:"months"`      // Keyed by YYYY-MM
+	LastStatementMonth string                 `json:"lastStatementMonth,omitempty"`
+	MasterTransferIDs  []string               `json:"masterTransferIds,omitempty"` // Latest transfers to the master account (not capital withdrawals)
+	UpdatedAt          time.Time              `json:"updatedAt"`
+}
+
+// VaultMonth aggregates the vault activity of a calendar month
+type VaultMonth struct {
+	RealizedProfit float64 `json:"realizedProfit"`
+	Skimmed        float64 `json:"skimmed"`
+	Transferred    float64 `json:"transferred"`
+	Trades         int     `json:"trades"`
+}
+
+// RuntimeState is bot state that must survive restarts (not user configuration)
+type RuntimeState struct {
+	Paused       bool       `json:"paused"`
+	PausedReason string     `json:"pausedReason,omitempty"`
+	PausedAt     *time.Time `json:"pausedAt,omitempty"`
+
+	LastReconciledTradeID int64 `json:"lastReconciledTradeId,omitempty"` // myTrades watermark
+	LastCapitalFlowSync   int64 `json:"lastCapitalFlowSync,omitempty"`   // Deposit/withdraw history watermark (ms)
+
+	// Values learned or changed at runtime. They override the .env input, which is never written.
+	MakerFeePct      *float64 `json:"makerFeePct,omitempty"` // Synced from the account (nil = not synced yet)
+	TakerFeePct      *float64 `json:"takerFeePct,omitempty"`
+	RangeMin         float64  `json:"rangeMin,omitempty"` // Set by /range (0 = use .env)
+	RangeMax         float64  `json:"rangeMax,omitempty"`
+	TotalCycles      int64    `json:"totalCycles,omitempty"` // Lifetime cycle counters (metrics tracker)
+	MsTimeProduction int64    `json:"msTimeProduction,omitempty"`
+	Profile          string   `json:"profile,omitempty"` // Parameter profile switched at runtime ("" = PROFILE from the config)
+
+	// Circuit breaker: an active pause and the day's trigger counter survive restarts and snapshots
+	CircuitBreakerAt       *time.Time `json:"circuitBreakerAt,omitempty"`
+	CircuitBreakerDay      string     `json:"circuitBreakerDay,omitempty"` // Day (YYYY-MM-DD) the trigger counter refers to
+	CircuitBreakerTriggers int        `json:"circuitBreakerTriggers,omitempty"`
+
+	// Circuit breaker escalation: trips within CB_TRIP_WINDOW_HOURS and the widen/halve in force
+	CircuitBreakerTrips       []time.Time `json:"circuitBreakerTrips,omitempty"`
+	CircuitBreakerEscalation  string      `json:"circuitBreakerEscalation,omitempty"`
+	CircuitBreakerEscalatedAt *time.Time  `json:"circuitBreakerEscalatedAt,omitempty"` // Renewed by every trip while escalated
+
+	ExecutionReportDay string `json:"executionReportDay,omitempty"` // Last day (YYYY-MM-DD) the execution report covered
+	RangeCheckDay      string `json:"rangeCheckDay,omitempty"`      // Last day (YYYY-MM-DD) the range was checked against the suggestion
+
+	// Drawdown stop: /resume is refused until CooloffUntil; a pending sell waits for /drawdown confirm
+	CooloffUntil    *time.Time `json:"cooloffUntil,omitempty"`
+	DrawdownPending bool       `json:"drawdownPending,omitempty"`
+
+	// Futures hedge: when the short in force was opened and the net result of the closed ones
+	HedgeOpenedAt    *time.Time `json:"hedgeOpenedAt,omitempty"`
+	HedgeRealizedPnL float64    `json:"hedgeRealizedPnl,omitempty"` // Realized PnL, fees and funding, in the quote asset
+
+	// Simple Earn: interest is tracked from EarnStartedAt as the growth of the position's
+	// cumulative rewards (last value seen in EarnRewardsSeen)
+	EarnStartedAt   *time.Time `json:"earnStartedAt,omitempty"`
+	EarnRewardsSeen float64    `json:"earnRewardsSeen,omitempty"`
+	EarnInterest    float64    `json:"earnInterest,omitempty"` // Interest accrued since EarnStartedAt, in the quote asset
+
+	LastDustConvertAt *time.Time `json:"lastDustConvertAt,omitempty"` // Last weekly dust conversion check
+
+	// BNB auto top-up: the daily cap holds across restarts
+	BNBTopUpDay   string `json:"bnbTopUpDay,omitempty"` // Day (YYYY-MM-DD) the top-up counter refers to
+	BNBTopUpCount int    `json:"bnbTopUpCount,omitempty"`
+
+	Volatility *VolatilityReading `json:"volatility,omitempty"` // Last reading, restored at startup while still recent
+}
+
+// VolatilityReading is a Garman-Klass reading of the volatility service
+type VolatilityReading struct {
+	Vol        float64   `json:"vol"` // Short term (5m) volatility per 1m candle
+	Multiplier float64   `json:"multiplier"`
+	Regime     string    `json:"regime"`
+	Since      time.Time `json:"since"` // Start of the regime, for the minimum dwell time
+	At         time.Time `json:"at"`
+}
+
+// RegimeChange is a volatility regime switch (logs/regime_history.jsonl)
+type RegimeChange struct {
+	At       time.Time `json:"at"`
+	From     string    `json:"from"`
+	To       string    `json:"to"`
+	ShortVol float64   `json:"shortVol"`
+	LongVol  float64   `json:"longVol"`
+}
+
+// DCAStack is the position accumulated by the DCA mode. It has no per-lot exits: the whole
+// stack is sold at once when the price reaches the take-profit over the average entry.
+type DCAStack struct {
+	Lots         []DCALot  `json:"lots"`
+	LastBuyPrice float64   `json:"lastBuyPrice"` // Kept after the stack closes, so the next buy waits for a drop
+	LastBuyAt    time.Time `json:"lastBuyAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// DCALot is a single DCA buy (quantity net of fees paid in the base asset)
+type DCALot struct {
+	OrderID string    `json:"orderId"` // Client order ID (DCA_BUY_...)
+	Qty     float64   `json:"qty"`
+	Price   float64   `json:"price"`
+	Fee     float64   `json:"fee"`
+	At      time.Time `json:"at"`
+}
+
+// Qty returns the total quantity held by the stack
+func (s DCAStack) Qty() float64 {
+	total := 0.0
+	for _, lot := range s.Lots {
+		total += lot.Qty
+	}
+	return total
+}
+
+// Cost returns the USDT spent on the stack
+func (s DCAStack) Cost() float64 {
+	total := 0.0
+	for _, lot := range s.Lots {
+		total += lot.Qty * lot.Price
+	}
+	return total
+}
+
+// AvgEntry returns the average entry price of the stack (0 if empty)
+func (s DCAStack) AvgEntry() float64 {
+	qty := s.Qty()
+	if qty <= 0 {
+		return 0
+	}
+	return s.Cost() / qty
+}
@@ -0,0 +1,61 @@
+package precision
+
+import (
+	"fmt"
+	"strconv"
+
+	"grid-trading-btc-binance/internal/model"
+)
+
+// Filters are the symbol's trading rules from exchangeInfo
+type Filters struct {
+	TickSize    float64 // PRICE_FILTER
+	StepSize    float64 // LOT_SIZE
+	MinQty      float64 // LOT_SIZE
+	MinNotional float64 // NOTIONAL (MIN_NOTIONAL on older symbols)
+}
+
+// DefaultFilters are BTCUSDT's rules, used until exchangeInfo is read
+var DefaultFilters = Filters{TickSize: 0.01, StepSize: 0.00001, MinQty: 0.00001, MinNotional: 5}
+
+// ParseFilters reads the rules of one symbol. Missing filters keep the default value.
+func ParseFilters(symbol model.SymbolInfo) Filters {
+	f := DefaultFilters
+	for _, filter := range symbol.Filters {
+		switch filter.FilterType {
+		case "PRICE_FILTER":
+			setPositive(&f.TickSize, filter.TickSize)
+		case "LOT_SIZE":
+			setPositive(&f.StepSize, filter.StepSize)
+			setPositive(&f.MinQty, filter.MinQty)
+		case "NOTIONAL", "MIN_NOTIONAL":
+			setPositive(&f.MinNotional, filter.MinNotional)
+		}
+	}
+	return f
+}
+
+func setPositive(field *float64, value string) {
+	if v, err := strconv.ParseFloat(value, 64); err == nil && v > 0 {
+		*field = v
+	}
+}
+
+// Changes lists the rules that differ in next ("tickSize 0.01 -> 0.1")
+func (f Filters) Changes(next Filters) []string {
+	var changes []string
+	add := func(name string, old, new float64) {
+		if old != new {
+			changes = append(changes, fmt.Sprintf("%s %s -> %s", name, formatStep(old), formatStep(new)))
+		}
+	}
+	add("tickSize", f.TickSize, next.TickSize)
+	add("stepSize", f.StepSize, next.StepSize)
+	add("minQty", f.MinQty, next.MinQty)
+	add("minNotional", f.MinNotional, next.MinNotional)
+	return changes
+}
+
+func formatStep(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
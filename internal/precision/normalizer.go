@@ -0,0 +1,107 @@
+package precision
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// epsilon absorbs float noise so a value already on the grid (87000.01 / 0.01) is not moved a step
+const epsilon = 1e-9
+
+// Normalizer rounds and formats prices and quantities to the symbol's filters. Buy prices are
+// rounded down and sell prices up, so rounding never makes an order more aggressive than the
+// strategy decided. Safe for concurrent use: the filters are refreshed while orders are placed.
+type Normalizer struct {
+	mu            sync.RWMutex
+	filters       Filters
+	priceDecimals int
+	qtyDecimals   int
+}
+
+func NewNormalizer(filters Filters) *Normalizer {
+	n := &Normalizer{}
+	n.SetFilters(filters)
+	return n
+}
+
+// SetFilters replaces the rules (exchangeInfo refresh)
+func (n *Normalizer) SetFilters(filters Filters) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.filters = filters
+	n.priceDecimals = Decimals(filters.TickSize)
+	n.qtyDecimals = Decimals(filters.StepSize)
+}
+
+// Filters returns the rules in use
+func (n *Normalizer) Filters() Filters {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.filters
+}
+
+// MinNotional is the minimum order value in quote asset
+func (n *Normalizer) MinNotional() float64 {
+	return n.Filters().MinNotional
+}
+
+// BuyPrice rounds price down to the tick and formats it
+func (n *Normalizer) BuyPrice(price float64) string {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return strconv.FormatFloat(floorTo(price, n.filters.TickSize), 'f', n.priceDecimals, 64)
+}
+
+// SellPrice rounds price up to the tick and formats it
+func (n *Normalizer) SellPrice(price float64) string {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return strconv.FormatFloat(ceilTo(price, n.filters.TickSize), 'f', n.priceDecimals, 64)
+}
+
+// FormatPrice rounds price to the nearest tick (recorded fill prices, averages)
+func (n *Normalizer) FormatPrice(price float64) string {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return strconv.FormatFloat(math.Round(price/n.filters.TickSize)*n.filters.TickSize, 'f', n.priceDecimals, 64)
+}
+
+// FloorQty rounds qty down to the step (never sells more than is held)
+func (n *Normalizer) FloorQty(qty float64) float64 {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return floorTo(qty, n.filters.StepSize)
+}
+
+// CeilQty rounds qty up to the step (keeps a buy above the minimum notional)
+func (n *Normalizer) CeilQty(qty float64) float64 {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return ceilTo(qty, n.filters.StepSize)
+}
+
+// FormatQty formats a quantity already on the step
+func (n *Normalizer) FormatQty(qty float64) string {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return strconv.FormatFloat(qty, 'f', n.qtyDecimals, 64)
+}
+
+func floorTo(value, step float64) float64 {
+	return math.Floor(value/step+epsilon) * step
+}
+
+func ceilTo(value, step float64) float64 {
+	return math.Ceil(value/step-epsilon) * step
+}
+
+// Decimals returns the number of decimal places of a filter step (0.01 -> 2, 1 -> 0)
+func Decimals(step float64) int {
+	s := strconv.FormatFloat(step, 'f', -1, 64)
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return len(s) - i - 1
+	}
+	return 0
+}
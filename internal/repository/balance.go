@@ -2,12 +2,15 @@ package repository
 
 import (
 	"grid-trading-btc-binance/internal/model"
+	"sort"
 	"sync"
+	"time"
 )
 
 type BalanceRepository struct {
-	cache map[string]*model.Balance
-	mu    sync.RWMutex
+	cache     map[string]*model.Balance
+	updatedAt time.Time // Last snapshot from Binance (REST sync or stream)
+	mu        sync.RWMutex
 }
 
 func NewBalanceRepository() *BalanceRepository {
@@ -41,6 +44,26 @@ func (r *BalanceRepository) SetBalances(balances []model.Balance) {
 	for i := range balances {
 		r.cache[balances[i].Currency] = &balances[i]
 	}
+	r.updatedAt = time.Now()
+}
+
+// ApplyPositions updates only the given currencies (outboundAccountPosition carries
+// just the assets that changed)
+func (r *BalanceRepository) ApplyPositions(balances []model.Balance) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, b := range balances {
+		r.cache[b.Currency] = &model.Balance{Currency: b.Currency, Amount: b.Amount}
+	}
+	r.updatedAt = time.Now()
+}
+
+// UpdatedAt returns when the cache was last refreshed from Binance
+func (r *BalanceRepository) UpdatedAt() time.Time {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.updatedAt
 }
 
 func (r *BalanceRepository) Get(currency string) (*model.Balance, bool) {
@@ -58,6 +81,19 @@ func (r *BalanceRepository) Get(currency string) (*model.Balance, bool) {
 	return &val, true
 }
 
+// All returns a copy of every cached balance, sorted by currency
+func (r *BalanceRepository) All() []model.Balance {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	balances := make([]model.Balance, 0, len(r.cache))
+	for _, b := range r.cache {
+		balances = append(balances, *b)
+	}
+	sort.Slice(balances, func(i, j int) bool { return balances[i].Currency < balances[j].Currency })
+	return balances
+}
+
 func (r *BalanceRepository) Update(currency string, amount float64) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"grid-trading-btc-binance/internal/model"
+	"sync"
+	"time"
+)
+
+const dcaFile = "dca_stack.json"
+
+// DCARepository persists the stack accumulated by the DCA mode
+type DCARepository struct {
+	storage *Storage
+	stack   model.DCAStack
+	mu      sync.RWMutex
+}
+
+func NewDCARepository(storage *Storage) *DCARepository {
+	return &DCARepository{storage: storage}
+}
+
+func (r *DCARepository) Load() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.storage.Exists(dcaFile) {
+		return nil
+	}
+	return r.storage.Read(dcaFile, &r.stack)
+}
+
+// Get returns a copy of the stack
+func (r *DCARepository) Get() model.DCAStack {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stack := r.stack
+	stack.Lots = append([]model.DCALot(nil), r.stack.Lots...)
+	return stack
+}
+
+// AddLot appends a buy to the stack and persists it
+func (r *DCARepository) AddLot(lot model.DCALot) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stack.Lots = append(r.stack.Lots, lot)
+	r.stack.LastBuyPrice = lot.Price
+	r.stack.LastBuyAt = lot.At
+	r.stack.UpdatedAt = time.Now()
+	return r.storage.Write(dcaFile, r.stack)
+}
+
+// Close empties the stack after its take-profit sold it (the last buy reference is kept)
+func (r *DCARepository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stack.Lots = nil
+	r.stack.UpdatedAt = time.Now()
+	return r.storage.Write(dcaFile, r.stack)
+}
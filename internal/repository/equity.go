@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/model"
+	"sync"
+	"time"
+)
+
+const equityFile = "equity_baseline.json"
+
+// EquityRepository persists the compounding baseline so sizing survives restarts
+type EquityRepository struct {
+	storage  *Storage
+	baseline model.EquityBaseline
+	loaded   bool
+	mu       sync.RWMutex
+}
+
+func NewEquityRepository(storage *Storage) *EquityRepository {
+	return &EquityRepository{storage: storage}
+}
+
+func (r *EquityRepository) Load() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.storage.Exists(equityFile) {
+		return nil
+	}
+	if err := r.storage.Read(equityFile, &r.baseline); err != nil {
+		return err
+	}
+	r.loaded = true
+	return nil
+}
+
+// Initialized reports whether a baseline has been persisted
+func (r *EquityRepository) Initialized() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.loaded
+}
+
+func (r *EquityRepository) Get() model.EquityBaseline {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.baseline
+}
+
+// Init starts a new baseline with the given capital
+func (r *EquityRepository) Init(baseCapital float64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.baseline = model.EquityBaseline{
+		BaseCapital: baseCapital,
+		StartedAt:   now,
+		UpdatedAt:   now,
+	}
+	r.loaded = true
+	logger.Info("📈 Equity baseline initialized", "base_capital", baseCapital)
+	return r.storage.Write(equityFile, r.baseline)
+}
+
+// AddProfit accumulates a realized profit (or loss) into the baseline
+func (r *EquityRepository) AddProfit(profit float64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.baseline.RealizedProfit += profit
+	r.baseline.UpdatedAt = time.Now()
+	return r.storage.Write(equityFile, r.baseline)
+}
+
+// AddEarnInterest accumulates Simple Earn interest on the idle USDT into the baseline
+func (r *EquityRepository) AddEarnInterest(amountUSDT float64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.baseline.EarnInterest += amountUSDT
+	r.baseline.UpdatedAt = time.Now()
+	return r.storage.Write(equityFile, r.baseline)
+}
+
+// AddCapitalFlow records a deposit (positive) or withdrawal (negative) in USDT, so it changes
+// the sizing base without being counted as trading performance
+func (r *EquityRepository) AddCapitalFlow(amountUSDT float64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.baseline.NetDeposits += amountUSDT
+	r.baseline.UpdatedAt = time.Now()
+	return r.storage.Write(equityFile, r.baseline)
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"grid-trading-btc-binance/internal/model"
+)
+
+const transactionEventsFile = "logs/transaction_events.jsonl"
+
+// EventLog is the append-only JSONL log of transaction status transitions
+type EventLog struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewEventLog(path string) *EventLog {
+	return &EventLog{path: path}
+}
+
+// Append writes one event as a JSON line
+func (l *EventLog) Append(event model.TransactionEvent) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("failed to create events dir: %w", err)
+	}
+	file, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", l.path, err)
+	}
+	defer file.Close()
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
+
+// Events returns the recorded events of a transaction, oldest first
+func (l *EventLog) Events(txID string) ([]model.TransactionEvent, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	file, err := os.Open(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var events []model.TransactionEvent
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event model.TransactionEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue // Skip a torn last line after a crash
+		}
+		if event.TxID == txID {
+			events = append(events, event)
+		}
+	}
+	return events, scanner.Err()
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"grid-trading-btc-binance/internal/model"
+)
+
+const RegimeHistoryFile = "logs/regime_history.jsonl"
+
+// RegimeHistory is the append-only JSONL log of the volatility regime changes
+type RegimeHistory struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewRegimeHistory(path string) *RegimeHistory {
+	return &RegimeHistory{path: path}
+}
+
+// Append writes one change as a JSON line
+func (h *RegimeHistory) Append(change model.RegimeChange) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return fmt.Errorf("failed to create regime history dir: %w", err)
+	}
+	file, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", h.path, err)
+	}
+	defer file.Close()
+
+	line, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
+
+// Changes returns every recorded change, oldest first
+func (h *RegimeHistory) Changes() ([]model.RegimeChange, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	file, err := os.Open(h.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var changes []model.RegimeChange
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var change model.RegimeChange
+		if err := json.Unmarshal(scanner.Bytes(), &change); err != nil {
+			continue // Skip a torn last line after a crash
+		}
+		changes = append(changes, change)
+	}
+	return changes, scanner.Err()
+}
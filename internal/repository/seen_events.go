@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	seenEventsFile = "ws_seen_events.json"
+	seenEventsTTL  = 7 * 24 * time.Hour // Binance never replays events this old
+)
+
+// SeenEventRepository is the persistent set of WebSocket events already processed,
+// so replays after a reconnect/restart or duplicated frames are applied only once
+type SeenEventRepository struct {
+	storage   *Storage
+	seen      map[string]int64 // key -> first seen (unix ms)
+	lastPrune time.Time
+	mu        sync.Mutex
+}
+
+func NewSeenEventRepository(storage *Storage) *SeenEventRepository {
+	return &SeenEventRepository{storage: storage, seen: make(map[string]int64)}
+}
+
+func (r *SeenEventRepository) Load() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.storage.Exists(seenEventsFile) {
+		return nil
+	}
+	if err := r.storage.Read(seenEventsFile, &r.seen); err != nil {
+		return err
+	}
+	if r.seen == nil {
+		r.seen = make(map[string]int64)
+	}
+	r.prune(time.Now())
+	return nil
+}
+
+// MarkSeen records key and reports whether it is the first time it was seen
+func (r *SeenEventRepository) MarkSeen(key string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.seen[key]; ok {
+		return false, nil
+	}
+
+	now := time.Now()
+	r.seen[key] = now.UnixMilli()
+	if now.Sub(r.lastPrune) > time.Hour {
+		r.prune(now)
+	}
+	return true, r.storage.Write(seenEventsFile, r.seen)
+}
+
+func (r *SeenEventRepository) prune(now time.Time) {
+	cutoff := now.Add(-seenEventsTTL).UnixMilli()
+	for key, at := range r.seen {
+		if at < cutoff {
+			delete(r.seen, key)
+		}
+	}
+	r.lastPrune = now
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"grid-trading-btc-binance/internal/model"
+	"sync"
+	"time"
+)
+
+const stateFile = "runtime_state.json"
+
+// StateRepository persists runtime state (e.g. the paused flag set by /panic, synced fees,
+// the /range override). It is the only runtime-mutable store: .env is read-only input.
+type StateRepository struct {
+	storage *Storage
+	state   model.RuntimeState
+	mu      sync.RWMutex
+}
+
+func NewStateRepository(storage *Storage) *StateRepository {
+	return &StateRepository{storage: storage}
+}
+
+func (r *StateRepository) Load() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.storage.Exists(stateFile) {
+		return nil
+	}
+	return r.storage.Read(stateFile, &r.state)
+}
+
+func (r *StateRepository) Get() model.RuntimeState {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.state
+}
+
+// SetPaused updates the paused flag and persists it
+func (r *StateRepository) SetPaused(paused bool, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.state.Paused = paused
+	if paused {
+		now := time.Now()
+		r.state.PausedReason = reason
+		r.state.PausedAt = &now
+	} else {
+		r.state.PausedReason = ""
+		r.state.PausedAt = nil
+	}
+	return r.storage.Write(stateFile, r.state)
+}
+
+// SetLastReconciledTradeID stores the myTrades watermark
+func (r *StateRepository) SetLastReconciledTradeID(id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.state.LastReconciledTradeID = id
+	return r.storage.Write(stateFile, r.state)
+}
+
+// SetLastCapitalFlowSync stores the deposit/withdraw history watermark
+func (r *StateRepository) SetLastCapitalFlowSync(ms int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.state.LastCapitalFlowSync = ms
+	return r.storage.Write(stateFile, r.state)
+}
+
+// SetFees stores the maker/taker fees synced from the account
+func (r *StateRepository) SetFees(maker, taker float64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.state.MakerFeePct = &maker
+	r.state.TakerFeePct = &taker
+	return r.storage.Write(stateFile, r.state)
+}
+
+// SetRange stores the price range set at runtime
+func (r *StateRepository) SetRange(rangeMin, rangeMax float64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.state.RangeMin = rangeMin
+	r.state.RangeMax = rangeMax
+	return r.storage.Write(stateFile, r.state)
+}
+
+// SetCycleStats stores the lifetime cycle counters
+func (r *StateRepository) SetCycleStats(totalCycles, msTimeProduction int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.state.TotalCycles = totalCycles
+	r.state.MsTimeProduction = msTimeProduction
+	return r.storage.Write(stateFile, r.state)
+}
+
+// SetProfile stores the active parameter profile
+func (r *StateRepository) SetProfile(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.state.Profile = name
+	return r.storage.Write(stateFile, r.state)
+}
+
+// SetCircuitBreaker stores the circuit breaker pause (zero at = not paused) and the day's triggers
+func (r *StateRepository) SetCircuitBreaker(at time.Time, day string, triggers int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.state.CircuitBreakerAt = nil
+	if !at.IsZero() {
+		r.state.CircuitBreakerAt = &at
+	}
+	r.state.CircuitBreakerDay = day
+	r.state.CircuitBreakerTriggers = triggers
+	return r.storage.Write(stateFile, r.state)
+}
+
+// SetCircuitBreakerEscalation stores the recent circuit breaker trips and the escalation in
+// force ("" = none, zero escalatedAt with it)
+func (r *StateRepository) SetCircuitBreakerEscalation(trips []time.Time, escalation string, escalatedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.state.CircuitBreakerTrips = trips
+	r.state.CircuitBreakerEscalation = escalation
+	r.state.CircuitBreakerEscalatedAt = nil
+	if !escalatedAt.IsZero() {
+		r.state.CircuitBreakerEscalatedAt = &escalatedAt
+	}
+	return r.storage.Write(stateFile, r.state)
+}
+
+// SetDrawdown stores the drawdown stop cool-off (zero = none) and whether its sell awaits confirmation
+func (r *StateRepository) SetDrawdown(cooloffUntil time.Time, pending bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.state.CooloffUntil = nil
+	if !cooloffUntil.IsZero() {
+		r.state.CooloffUntil = &cooloffUntil
+	}
+	r.state.DrawdownPending = pending
+	return r.storage.Write(stateFile, r.state)
+}
+
+// SetHedge stores when the hedge in force was opened (zero = none) and the net result of the
+// closed hedges
+func (r *StateRepository) SetHedge(openedAt time.Time, realizedPnL float64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.state.HedgeOpenedAt = nil
+	if !openedAt.IsZero() {
+		r.state.HedgeOpenedAt = &openedAt
+	}
+	r.state.HedgeRealizedPnL = realizedPnL
+	return r.storage.Write(stateFile, r.state)
+}
+
+// SetEarn stores when the Simple Earn interest tracking started, the cumulative rewards last
+// seen on the position and the interest accrued since the start
+func (r *StateRepository) SetEarn(startedAt time.Time, rewardsSeen, interest float64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.state.EarnStartedAt = &startedAt
+	r.state.EarnRewardsSeen = rewardsSeen
+	r.state.EarnInterest = interest
+	return r.storage.Write(stateFile, r.state)
+}
+
+// SetLastDustConvert stores when the weekly dust conversion last ran
+func (r *StateRepository) SetLastDustConvert(at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.state.LastDustConvertAt = &at
+	return r.storage.Write(stateFile, r.state)
+}
+
+// SetBNBTopUp stores the day's BNB auto top-up attempts
+func (r *StateRepository) SetBNBTopUp(day string, count int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.state.BNBTopUpDay = day
+	r.state.BNBTopUpCount = count
+	return r.storage.Write(stateFile, r.state)
+}
+
+// SetExecutionReportDay stores the last day covered by the daily execution report
+func (r *StateRepository) SetExecutionReportDay(day string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.state.ExecutionReportDay = day
+	return r.storage.Write(stateFile, r.state)
+}
+
+// SetRangeCheckDay stores the last day the range was checked against the suggestion
+func (r *StateRepository) SetRangeCheckDay(day string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.state.RangeCheckDay = day
+	return r.storage.Write(stateFile, r.state)
+}
+
+// SetVolatility stores the last volatility reading
+func (r *StateRepository) SetVolatility(reading model.VolatilityReading) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.state.Volatility = &reading
+	return r.storage.Write(stateFile, r.state)
+}
@@ -4,21 +4,39 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sync"
 )
 
+// StateFiles are the files holding the bot state (bundled by snapshots)
+func StateFiles() []string {
+	return []string{transactionsFile, exitLinksFile, historyFile, stateFile, equityFile, vaultFile, dcaFile, seenEventsFile}
+}
+
 type Storage struct {
-	mu sync.Mutex
+	mu  sync.Mutex
+	dir string // Base directory of the state files ("" = working directory)
 }
 
 func NewStorage() *Storage {
 	return &Storage{}
 }
 
+// NewStorageAt reads and writes the state files of the bot running in dir (e.g. the report
+// of `grid-bot accounts`)
+func NewStorageAt(dir string) *Storage {
+	return &Storage{dir: dir}
+}
+
+func (s *Storage) path(name string) string {
+	return filepath.Join(s.dir, name)
+}
+
 func (s *Storage) Read(path string, v interface{}) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	path = s.path(path)
 	file, err := os.Open(path)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -39,6 +57,7 @@ func (s *Storage) Write(path string, v interface{}) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	path = s.path(path)
 	file, err := os.Create(path)
 	if err != nil {
 		return fmt.Errorf("failed to create file %s: %w", path, err)
@@ -53,9 +72,23 @@ func (s *Storage) Write(path string, v interface{}) error {
 	return nil
 }
 
+// CheckWritable creates and removes a probe file in dir (health endpoints)
+func (s *Storage) CheckWritable(dir string) error {
+	file, err := os.CreateTemp(dir, ".health-*")
+	if err != nil {
+		return fmt.Errorf("%s is not writable: %w", dir, err)
+	}
+	name := file.Name()
+	file.Close()
+	if err := os.Remove(name); err != nil {
+		return fmt.Errorf("failed to remove probe file %s: %w", name, err)
+	}
+	return nil
+}
+
 func (s *Storage) Exists(path string) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	_, err := os.Stat(path)
+	_, err := os.Stat(s.path(path))
 	return !os.IsNotExist(err)
 }
@@ -2,27 +2,143 @@ package repository
 
 import (
 	"fmt"
+	"grid-trading-btc-binance/internal/audit"
+	"grid-trading-btc-binance/internal/crash"
 	"grid-trading-btc-binance/internal/logger"
 	"grid-trading-btc-binance/internal/model"
 	"sync"
 	"time"
 )
 
-const transactionsFile = "transactions.json"
+const (
+	transactionsFile = "transactions.json"
+	historyFile      = "logs/transactions_history.json"
+	exitLinksFile    = "exit_links.json"
+)
 
 type TransactionRepository struct {
 	storage      *Storage
 	transactions []model.Transaction
+	index        map[string]int                   // Transaction ID -> position in transactions
+	bySelector   map[selector]map[string]struct{} // Symbol/type/status -> transaction IDs (see Find)
+	exitLinks    map[string]string                // Sell order ID -> buy transaction ID (persisted in exitLinksFile)
 	mu           sync.RWMutex
+	historyMu    sync.Mutex // Serializes read-modify-write cycles on the history file
+	events       *EventLog
+
+	// Batched persistence (StartFlusher): mutations mark the files dirty instead of rewriting them
+	batched    bool
+	dirty      bool
+	linksDirty bool
 }
 
 func NewTransactionRepository(storage *Storage) *TransactionRepository {
 	return &TransactionRepository{
 		storage:      storage,
 		transactions: []model.Transaction{},
+		index:        make(map[string]int),
+		bySelector:   make(map[selector]map[string]struct{}),
+		exitLinks:    make(map[string]string),
+		events:       NewEventLog(transactionEventsFile),
 	}
 }
 
+// StartFlusher switches to batched persistence: Save/Update/Delete change the memory and mark
+// the repository dirty, and the files are written once per interval (and by Flush on shutdown).
+// A busy sync phase then costs one write of transactions.json instead of one per mutation.
+func (r *TransactionRepository) StartFlusher(interval time.Duration) {
+	r.mu.Lock()
+	r.batched = true
+	r.mu.Unlock()
+
+	crash.Go("transactions flusher", func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := r.Flush(); err != nil {
+				logger.Error("Failed to flush transactions", "error", err)
+			}
+		}
+	})
+}
+
+// Flush writes the pending changes. Without StartFlusher every mutation is already on disk.
+func (r *TransactionRepository) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.dirty {
+		if err := r.storage.Write(transactionsFile, r.transactions); err != nil {
+			return err
+		}
+		r.dirty = false
+	}
+	if r.linksDirty {
+		if err := r.storage.Write(exitLinksFile, r.exitLinks); err != nil {
+			return err
+		}
+		r.linksDirty = false
+	}
+	return nil
+}
+
+// persist writes transactions.json, or only marks it dirty when batched. The caller holds r.mu.
+func (r *TransactionRepository) persist() error {
+	if r.batched {
+		r.dirty = true
+		return nil
+	}
+	return r.storage.Write(transactionsFile, r.transactions)
+}
+
+// persistExitLinks is persist for the buy↔sell index. The caller holds r.mu.
+func (r *TransactionRepository) persistExitLinks() error {
+	if r.batched {
+		r.linksDirty = true
+		return nil
+	}
+	return r.storage.Write(exitLinksFile, r.exitLinks)
+}
+
+// Transition validates and applies a status change to tx, appending it to the event log.
+// The transaction itself is not persisted: callers still Save/Update/Archive it.
+func (r *TransactionRepository) Transition(tx *model.Transaction, to, reason string) error {
+	from := tx.StatusTransaction
+	if !model.CanTransition(from, to) {
+		return fmt.Errorf("invalid transition %s -> %s", from, to)
+	}
+	if from == to {
+		return nil
+	}
+
+	now := time.Now()
+	tx.StatusTransaction = to
+	tx.UpdatedAt = now
+
+	event := model.TransactionEvent{TxID: tx.ID, From: from, To: to, Reason: reason, At: now}
+	if err := r.events.Append(event); err != nil {
+		logger.Error("Failed to append transaction event", "id", tx.ID, "error", err)
+	}
+	audit.Transition(tx.ID, from, to, reason)
+	return nil
+}
+
+// Begin starts the lifecycle of a new transaction (NEW) and moves it to status
+func (r *TransactionRepository) Begin(tx *model.Transaction, status, reason string) error {
+	tx.StatusTransaction = model.StatusNew
+	now := time.Now()
+	if err := r.events.Append(model.TransactionEvent{TxID: tx.ID, To: model.StatusNew, Reason: "created", At: now}); err != nil {
+		logger.Error("Failed to append transaction event", "id", tx.ID, "error", err)
+	}
+	audit.Transition(tx.ID, "", model.StatusNew, "created")
+	return r.Transition(tx, status, reason)
+}
+
+// Events returns the lifecycle events recorded for a transaction
+func (r *TransactionRepository) Events(txID string) ([]model.TransactionEvent, error) {
+	return r.events.Events(txID)
+}
+
 func (r *TransactionRepository) Load() error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -35,7 +151,94 @@ func (r *TransactionRepository) Load() error {
 	if err := r.storage.Read(transactionsFile, &r.transactions); err != nil {
 		return err
 	}
-	return nil
+	r.reindex()
+	return r.loadExitLinks()
+}
+
+// ReadActive returns the active transactions as persisted, without loading the repository
+// (no file is created or rewritten): for offline tools that only inspect the state
+func (r *TransactionRepository) ReadActive() ([]model.Transaction, error) {
+	var transactions []model.Transaction
+	if !r.storage.Exists(transactionsFile) {
+		return transactions, nil
+	}
+	if err := r.storage.Read(transactionsFile, &transactions); err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// loadExitLinks reads the buy↔sell index and reconciles it with the loaded transactions: links
+// recorded on the transactions are added, links of transactions no longer active are dropped.
+// A link whose transaction lost its SellOrderID (edited file) is kept: the index is the source.
+func (r *TransactionRepository) loadExitLinks() error {
+	if r.storage.Exists(exitLinksFile) {
+		if err := r.storage.Read(exitLinksFile, &r.exitLinks); err != nil {
+			return err
+		}
+	}
+	if r.exitLinks == nil {
+		r.exitLinks = make(map[string]string)
+	}
+
+	changed := false
+	for _, tx := range r.transactions {
+		if r.linkExit(model.Transaction{}, tx) {
+			changed = true
+		}
+	}
+	if err := r.writeExitLinks(changed); err != nil {
+		return err
+	}
+	return r.unlinkExits()
+}
+
+// linkExit updates the index for a transaction changing from old to tx and reports a change.
+// The caller holds r.mu.
+func (r *TransactionRepository) linkExit(old, tx model.Transaction) bool {
+	changed := false
+	if old.SellOrderID != "" && old.SellOrderID != tx.SellOrderID && r.exitLinks[old.SellOrderID] == tx.ID {
+		delete(r.exitLinks, old.SellOrderID) // Exit replaced or cleared in code (canceled exit)
+		changed = true
+	}
+	if tx.SellOrderID != "" && r.exitLinks[tx.SellOrderID] != tx.ID {
+		r.exitLinks[tx.SellOrderID] = tx.ID
+		changed = true
+	}
+	return changed
+}
+
+// unlinkExits drops the links of transactions that left the active list. The caller holds r.mu.
+func (r *TransactionRepository) unlinkExits() error {
+	changed := false
+	for sellID, buyID := range r.exitLinks {
+		if _, ok := r.index[buyID]; !ok {
+			delete(r.exitLinks, sellID)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return r.persistExitLinks()
+}
+
+// reindex rebuilds the indexes after transactions were removed. The caller holds r.mu.
+func (r *TransactionRepository) reindex() {
+	r.index = make(map[string]int, len(r.transactions))
+	r.bySelector = make(map[selector]map[string]struct{})
+	for i, tx := range r.transactions {
+		r.index[tx.ID] = i
+		r.indexAdd(tx)
+	}
+}
+
+// writeExitLinks persists the index after linkExit reported a change. The caller holds r.mu.
+func (r *TransactionRepository) writeExitLinks(changed bool) error {
+	if !changed {
+		return nil
+	}
+	return r.persistExitLinks()
 }
 
 func (r *TransactionRepository) Save(tx model.Transaction) error {
@@ -43,44 +246,86 @@ func (r *TransactionRepository) Save(tx model.Transaction) error {
 	defer r.mu.Unlock()
 
 	r.transactions = append(r.transactions, tx)
-	return r.storage.Write(transactionsFile, r.transactions)
+	r.index[tx.ID] = len(r.transactions) - 1
+	r.indexAdd(tx)
+	if err := r.persist(); err != nil {
+		return err
+	}
+	return r.writeExitLinks(r.linkExit(model.Transaction{}, tx))
 }
 
 func (r *TransactionRepository) Update(tx model.Transaction) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	for i, t := range r.transactions {
-		if t.ID == tx.ID {
-			r.transactions[i] = tx
-			return r.storage.Write(transactionsFile, r.transactions)
-		}
+	i, ok := r.index[tx.ID]
+	if !ok {
+		return fmt.Errorf("transaction not found: %s", tx.ID)
+	}
+	old := r.transactions[i]
+	r.transactions[i] = tx
+	r.indexRemove(old)
+	r.indexAdd(tx)
+	if err := r.persist(); err != nil {
+		return err
 	}
-	return fmt.Errorf("transaction not found: %s", tx.ID)
+	return r.writeExitLinks(r.linkExit(old, tx))
 }
 
 func (r *TransactionRepository) Get(id string) (model.Transaction, bool) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	for _, tx := range r.transactions {
-		if tx.ID == id {
-			return tx, true
-		}
+	if i, ok := r.index[id]; ok {
+		return r.transactions[i], true
 	}
 	return model.Transaction{}, false
 }
 
+// GetBySellID returns the buy that owns the exit sellID. A buy whose SellOrderID was lost
+// (edited file) gets it back from the index.
 func (r *TransactionRepository) GetBySellID(sellID string) (model.Transaction, bool) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	for _, tx := range r.transactions {
-		if tx.SellOrderID == sellID {
-			return tx, true
+	buyID, ok := r.exitLinks[sellID]
+	if !ok {
+		return model.Transaction{}, false
+	}
+	i, ok := r.index[buyID]
+	if !ok {
+		return model.Transaction{}, false
+	}
+	tx := r.transactions[i]
+	if tx.SellOrderID == "" {
+		tx.SellOrderID = sellID
+	}
+	return tx, true
+}
+
+// IsLinkedExit reports whether sellID is the exit of an active transaction
+func (r *TransactionRepository) IsLinkedExit(sellID string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.exitLinks[sellID]
+	return ok
+}
+
+// ExitOf returns the exit linked to buyID (the index survives edits of SellOrderID)
+func (r *TransactionRepository) ExitOf(buyID string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if i, ok := r.index[buyID]; ok && r.transactions[i].SellOrderID != "" {
+		return r.transactions[i].SellOrderID, true
+	}
+	for sellID, id := range r.exitLinks {
+		if id == buyID {
+			return sellID, true
 		}
 	}
-	return model.Transaction{}, false
+	return "", false
 }
 
 func (r *TransactionRepository) GetAll() []model.Transaction {
@@ -104,7 +349,7 @@ func (r *TransactionRepository) GetTransactionsAfter(timestamp time.Time) []mode
 
 	var filtered []model.Transaction
 	for _, tx := range r.transactions {
-		if tx.StatusTransaction == "filled" || tx.StatusTransaction == "closed" {
+		if tx.StatusTransaction == model.StatusFilled || tx.StatusTransaction == model.StatusClosed {
 			if tx.UpdatedAt.After(timestamp) {
 				filtered = append(filtered, tx)
 			}
@@ -116,8 +361,6 @@ func (r *TransactionRepository) GetTransactionsAfter(timestamp time.Time) []mode
 // GetClosedTransactionsAfter reads the history file and returns closed transactions after timestamp
 // Used by the collector to calculate hourly realized profits from archived trades
 func (r *TransactionRepository) GetClosedTransactionsAfter(timestamp time.Time) []model.Transaction {
-	historyFile := "logs/transactions_history.json"
-
 	var history []model.Transaction
 	if !r.storage.Exists(historyFile) {
 		return history
@@ -130,7 +373,7 @@ func (r *TransactionRepository) GetClosedTransactionsAfter(timestamp time.Time)
 
 	var filtered []model.Transaction
 	for _, tx := range history {
-		if tx.StatusTransaction == "closed" {
+		if tx.StatusTransaction == model.StatusClosed {
 			// For closed trades, use ClosedAt if available, else UpdatedAt
 			var checkTime time.Time
 			if tx.ClosedAt != nil {
@@ -152,13 +395,16 @@ func (r *TransactionRepository) Remove(id string) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	for i, tx := range r.transactions {
-		if tx.ID == id {
-			r.transactions = append(r.transactions[:i], r.transactions[i+1:]...)
-			return r.storage.Write(transactionsFile, r.transactions)
-		}
+	i, ok := r.index[id]
+	if !ok {
+		return nil
 	}
-	return nil
+	r.transactions = append(r.transactions[:i], r.transactions[i+1:]...)
+	r.reindex()
+	if err := r.persist(); err != nil {
+		return err
+	}
+	return r.unlinkExits()
 }
 
 // Clear removes all transactions and saves empty list
@@ -167,12 +413,17 @@ func (r *TransactionRepository) Clear() error {
 	defer r.mu.Unlock()
 
 	r.transactions = []model.Transaction{}
-	return r.storage.Write(transactionsFile, r.transactions)
+	r.reindex()
+	if err := r.persist(); err != nil {
+		return err
+	}
+	return r.unlinkExits()
 }
 
 // Archive appends a closed transaction to the history file
 func (r *TransactionRepository) Archive(tx model.Transaction) error {
-	historyFile := "logs/transactions_history.json"
+	r.historyMu.Lock()
+	defer r.historyMu.Unlock()
 
 	// We need to read existing history first to append
 	// Optimization: This might be slow if history gets huge.
@@ -217,7 +468,11 @@ func (r *TransactionRepository) Delete(id string) error {
 	}
 
 	r.transactions = newTransactions
-	return r.storage.Write(transactionsFile, r.transactions)
+	r.reindex()
+	if err := r.persist(); err != nil {
+		return err
+	}
+	return r.unlinkExits()
 }
 
 // CleanupClosed iterates through loaded transactions, archives closed ones, and removes them from active list.
@@ -236,7 +491,7 @@ func (r *TransactionRepository) CleanupClosed() int {
 	var closedTransactions []model.Transaction
 
 	for _, tx := range r.transactions {
-		if tx.StatusTransaction == "closed" {
+		if model.IsTerminal(tx.StatusTransaction) {
 			closedTransactions = append(closedTransactions, tx)
 			closedCount++
 		} else {
@@ -251,7 +506,8 @@ func (r *TransactionRepository) CleanupClosed() int {
 	logger.Info("🧹 Cleanup: Found closed transactions to archive", "count", closedCount)
 
 	// Archive Logic (Bulk)
-	historyFile := "logs/transactions_history.json"
+	r.historyMu.Lock()
+	defer r.historyMu.Unlock()
 
 	// Read History (Needs to be outside Lock if storage.Read takes time? No, we are holding lock for consistency)
 	// Be careful with performance. Reading giant history file while holding lock on active transactions might block bot.
@@ -274,6 +530,7 @@ func (r *TransactionRepository) CleanupClosed() int {
 
 	// Update Active
 	r.transactions = activeTransactions
+	r.reindex()
 	if err := r.storage.Write(transactionsFile, r.transactions); err != nil {
 		logger.Error("❌ Cleanup Failed: Could not write active file", "error", err)
 		// Danger state: History updated but Active not cleared. transactions duplicates in history?
@@ -281,6 +538,45 @@ func (r *TransactionRepository) CleanupClosed() int {
 		return 0
 	}
 
+	if err := r.unlinkExits(); err != nil {
+		logger.Error("Failed to write exit links after cleanup", "error", err)
+	}
+
 	logger.Info("✅ Cleanup Complete: Archived and Removed transactions", "count", closedCount)
 	return closedCount
 }
+
+// GetHistory returns every archived transaction
+func (r *TransactionRepository) GetHistory() ([]model.Transaction, error) {
+	r.historyMu.Lock()
+	defer r.historyMu.Unlock()
+
+	var history []model.Transaction
+	if !r.storage.Exists(historyFile) {
+		return history, nil
+	}
+	if err := r.storage.Read(historyFile, &history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// UpdateHistory applies fn to the archived transactions and writes the result back when
+// fn reports a change. Archive calls are blocked meanwhile, so no record is lost.
+func (r *TransactionRepository) UpdateHistory(fn func(history []model.Transaction) ([]model.Transaction, bool)) error {
+	r.historyMu.Lock()
+	defer r.historyMu.Unlock()
+
+	var history []model.Transaction
+	if r.storage.Exists(historyFile) {
+		if err := r.storage.Read(historyFile, &history); err != nil {
+			return err
+		}
+	}
+
+	updated, changed := fn(history)
+	if !changed {
+		return nil
+	}
+	return r.storage.Write(historyFile, updated)
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"sort"
+	"strconv"
+
+	"grid-trading-btc-binance/internal/model"
+)
+
+// TransactionQuery selects active transactions. Empty fields match anything; Symbol, Type and
+// Statuses are served by the repository index, the price range filters the indexed selection.
+type TransactionQuery struct {
+	Symbol   string
+	Type     string   // buy, sell
+	Statuses []string // Any of them
+	MinPrice float64  // Order price bounds, inclusive (0 = unbounded)
+	MaxPrice float64
+}
+
+// selector is the index key: every active transaction is in exactly one selector set
+type selector struct {
+	symbol string
+	kind   string
+	status string
+}
+
+func selectorOf(tx model.Transaction) selector {
+	return selector{symbol: tx.Symbol, kind: tx.Type, status: tx.StatusTransaction}
+}
+
+func (q TransactionQuery) matches(sel selector) bool {
+	if q.Symbol != "" && sel.symbol != q.Symbol {
+		return false
+	}
+	if q.Type != "" && sel.kind != q.Type {
+		return false
+	}
+	if len(q.Statuses) == 0 {
+		return true
+	}
+	for _, status := range q.Statuses {
+		if sel.status == status {
+			return true
+		}
+	}
+	return false
+}
+
+func (q TransactionQuery) inRange(tx model.Transaction) bool {
+	if q.MinPrice <= 0 && q.MaxPrice <= 0 {
+		return true
+	}
+	price, err := strconv.ParseFloat(tx.Price, 64)
+	if err != nil {
+		return false
+	}
+	return (q.MinPrice <= 0 || price >= q.MinPrice) && (q.MaxPrice <= 0 || price <= q.MaxPrice)
+}
+
+// Find returns the active transactions matching q, in repository order. It touches only the
+// indexed selection, not the whole list, so it is cheap enough for every ticker.
+func (r *TransactionRepository) Find(q TransactionQuery) []model.Transaction {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	positions := r.positions(q)
+	found := make([]model.Transaction, 0, len(positions))
+	for _, i := range positions {
+		if tx := r.transactions[i]; q.inRange(tx) {
+			found = append(found, tx)
+		}
+	}
+	return found
+}
+
+// Count returns how many active transactions match q
+func (r *TransactionRepository) Count(q TransactionQuery) int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	count := 0
+	if q.MinPrice <= 0 && q.MaxPrice <= 0 {
+		for sel, ids := range r.bySelector {
+			if q.matches(sel) {
+				count += len(ids)
+			}
+		}
+		return count
+	}
+	for _, i := range r.positions(q) {
+		if q.inRange(r.transactions[i]) {
+			count++
+		}
+	}
+	return count
+}
+
+// positions returns the sorted positions of the transactions in the selectors matching q.
+// The caller holds r.mu.
+func (r *TransactionRepository) positions(q TransactionQuery) []int {
+	var positions []int
+	for sel, ids := range r.bySelector {
+		if !q.matches(sel) {
+			continue
+		}
+		for id := range ids {
+			positions = append(positions, r.index[id])
+		}
+	}
+	sort.Ints(positions)
+	return positions
+}
+
+// indexAdd and indexRemove keep bySelector in step with the list. The caller holds r.mu.
+func (r *TransactionRepository) indexAdd(tx model.Transaction) {
+	sel := selectorOf(tx)
+	ids, ok := r.bySelector[sel]
+	if !ok {
+		ids = make(map[string]struct{})
+		r.bySelector[sel] = ids
+	}
+	ids[tx.ID] = struct{}{}
+}
+
+func (r *TransactionRepository) indexRemove(tx model.Transaction) {
+	sel := selectorOf(tx)
+	if ids, ok := r.bySelector[sel]; ok {
+		delete(ids, tx.ID)
+		if len(ids) == 0 {
+			delete(r.bySelector, sel)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"grid-trading-btc-binance/internal/model"
+	"sync"
+	"time"
+)
+
+const (
+	vaultFile            = "vault.json"
+	maxMasterTransferIDs = 500 // Well beyond what the capital flow sync re-reads
+)
+
+// VaultRepository persists the profit vault (skimmed gains excluded from trading)
+type VaultRepository struct {
+	storage *Storage
+	vault   model.Vault
+	mu      sync.RWMutex
+}
+
+func NewVaultRepository(storage *Storage) *VaultRepository {
+	return &VaultRepository{
+		storage: storage,
+		vault:   model.Vault{Months: make(map[string]*model.VaultMonth)},
+	}
+}
+
+func (r *VaultRepository) Load() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.storage.Exists(vaultFile) {
+		return nil
+	}
+	if err := r.storage.Read(vaultFile, &r.vault); err != nil {
+		return err
+	}
+	if r.vault.Months == nil {
+		r.vault.Months = make(map[string]*model.VaultMonth)
+	}
+	return nil
+}
+
+// Get returns a copy of the vault state
+func (r *VaultRepository) Get() model.Vault {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	v := r.vault
+	v.Months = make(map[string]*model.VaultMonth, len(r.vault.Months))
+	for k, m := range r.vault.Months {
+		month := *m
+		v.Months[k] = &month
+	}
+	return v
+}
+
+// Skim records a realized profit and the amount moved into the vault
+func (r *VaultRepository) Skim(at time.Time, profit, amount float64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	month := r.month(at)
+	month.RealizedProfit += profit
+	month.Skimmed += amount
+	month.Trades++
+
+	r.vault.TotalSkimmed += amount
+	r.vault.Pending += amount
+	r.vault.UpdatedAt = time.Now()
+	return r.storage.Write(vaultFile, r.vault)
+}
+
+// MarkTransferred records a successful transfer out of the Spot wallet. masterTransferID is the
+// id of a transfer to the master account (sub-account mode), "" otherwise.
+func (r *VaultRepository) MarkTransferred(at time.Time, amount float64, masterTransferID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if masterTransferID != "" {
+		r.vault.MasterTransferIDs = append(r.vault.MasterTransferIDs, masterTransferID)
+		if n := len(r.vault.MasterTransferIDs); n > maxMasterTransferIDs {
+			r.vault.MasterTransferIDs = r.vault.MasterTransferIDs[n-maxMasterTransferIDs:]
+		}
+	}
+	r.month(at).Transferred += amount
+	r.vault.Transferred += amount
+	r.vault.Pending -= amount
+	if r.vault.Pending < 0 {
+		r.vault.Pending = 0
+	}
+	r.vault.UpdatedAt = time.Now()
+	return r.storage.Write(vaultFile, r.vault)
+}
+
+// SetLastStatementMonth marks the month (YYYY-MM) whose statement was already sent
+func (r *VaultRepository) SetLastStatementMonth(month string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.vault.LastStatementMonth = month
+	return r.storage.Write(vaultFile, r.vault)
+}
+
+// month returns the aggregate for the given time, creating it if needed (caller holds the lock)
+func (r *VaultRepository) month(at time.Time) *model.VaultMonth {
+	key := at.Format("2006-01")
+	m, ok := r.vault.Months[key]
+	if !ok {
+		m = &model.VaultMonth{}
+		r.vault.Months[key] = m
+	}
+	return m
+}
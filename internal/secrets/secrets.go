@@ -0,0 +1,205 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/joho/godotenv"
+	"github.com/zalando/go-keyring"
+)
+
+// Where the Binance API keys are read from
+const (
+	SourceEnv     = "env"     // BINANCE_API_KEY / BINANCE_SECRET_KEY in the environment or .env (plaintext)
+	SourceKeyring = "keyring" // OS keyring: macOS Keychain, Secret Service (GNOME Keyring/KWallet), Windows Credential Manager
+	SourceFile    = "file"    // age-encrypted file unlocked by a passphrase
+	SourceCommand = "command" // External provider: a command printing KEY=VALUE lines (vault, op, aws, gcloud...)
+)
+
+// Key names, shared by every source (keyring accounts, lines of the file and of the command output)
+const (
+	APIKeyName    = "BINANCE_API_KEY"
+	SecretKeyName = "BINANCE_SECRET_KEY"
+)
+
+// Defaults for the keyring service and the encrypted file
+const (
+	DefaultKeyringService = "grid-trading-btc-binance"
+	DefaultFile           = "secrets.age"
+)
+
+const commandTimeout = 30 * time.Second
+
+// Options selects and configures the source
+type Options struct {
+	Source         string
+	KeyringService string // Keyring service name the keys are stored under
+	File           string // age-encrypted secrets file
+	Passphrase     string // Unlocks File (SECRETS_PASSPHRASE)
+	PassphraseFile string // Or read the passphrase from a file (Docker/systemd credentials)
+	Command        string // Run through sh -c
+}
+
+// Keys are the Binance API credentials
+type Keys struct {
+	APIKey    string
+	SecretKey string
+}
+
+// ValidateSource checks a source name
+func ValidateSource(source string) error {
+	switch source {
+	case SourceEnv, SourceKeyring, SourceFile, SourceCommand:
+		return nil
+	}
+	return fmt.Errorf("invalid secrets source %q (expected %s, %s, %s or %s)", source, SourceEnv, SourceKeyring, SourceFile, SourceCommand)
+}
+
+// Load reads the keys from the configured source (not SourceEnv: those come with the config)
+func Load(opts Options) (Keys, error) {
+	var values map[string]string
+	var err error
+	switch opts.Source {
+	case SourceKeyring:
+		values, err = fromKeyring(opts.KeyringService)
+	case SourceFile:
+		values, err = fromFile(opts)
+	case SourceCommand:
+		values, err = fromCommand(opts.Command)
+	default:
+		return Keys{}, ValidateSource(opts.Source)
+	}
+	if err != nil {
+		return Keys{}, err
+	}
+
+	keys := Keys{APIKey: values[APIKeyName], SecretKey: values[SecretKeyName]}
+	if keys.APIKey == "" || keys.SecretKey == "" {
+		return Keys{}, fmt.Errorf("%s secrets must provide %s and %s", opts.Source, APIKeyName, SecretKeyName)
+	}
+	return keys, nil
+}
+
+func fromKeyring(service string) (map[string]string, error) {
+	values := make(map[string]string, 2)
+	for _, name := range []string{APIKeyName, SecretKeyName} {
+		value, err := keyring.Get(service, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from the keyring (service %q): %w", name, service, err)
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// StoreKeyring saves the keys in the OS keyring under service
+func StoreKeyring(service string, keys Keys) error {
+	if err := keyring.Set(service, APIKeyName, keys.APIKey); err != nil {
+		return fmt.Errorf("failed to store %s in the keyring: %w", APIKeyName, err)
+	}
+	if err := keyring.Set(service, SecretKeyName, keys.SecretKey); err != nil {
+		return fmt.Errorf("failed to store %s in the keyring: %w", SecretKeyName, err)
+	}
+	return nil
+}
+
+func fromFile(opts Options) (map[string]string, error) {
+	passphrase, err := resolvePassphrase(opts)
+	if err != nil {
+		return nil, err
+	}
+	identity, err := age.NewScryptIdentity(passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secrets passphrase: %w", err)
+	}
+
+	raw, err := os.ReadFile(opts.File)
+	if err != nil {
+		return nil, fmt.Errorf("error reading secrets file: %w", err)
+	}
+	var src io.Reader = bytes.NewReader(raw)
+	if bytes.HasPrefix(bytes.TrimSpace(raw), []byte(armor.Header)) {
+		src = armor.NewReader(src) // age -a
+	}
+	plain, err := age.Decrypt(src, identity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s (wrong passphrase?): %w", opts.File, err)
+	}
+	content, err := io.ReadAll(plain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %w", opts.File, err)
+	}
+	return parseValues(opts.File, content)
+}
+
+func resolvePassphrase(opts Options) (string, error) {
+	if opts.Passphrase != "" {
+		return opts.Passphrase, nil
+	}
+	if opts.PassphraseFile != "" {
+		raw, err := os.ReadFile(opts.PassphraseFile)
+		if err != nil {
+			return "", fmt.Errorf("error reading passphrase file: %w", err)
+		}
+		return strings.TrimRight(string(raw), "\r\n"), nil
+	}
+	return "", fmt.Errorf("SECRETS_SOURCE=file requires SECRETS_PASSPHRASE or SECRETS_PASSPHRASE_FILE")
+}
+
+// EncryptFile writes the keys to path as an age file protected by passphrase
+// (compatible with `age -d`)
+func EncryptFile(path, passphrase string, keys Keys) error {
+	recipient, err := age.NewScryptRecipient(passphrase)
+	if err != nil {
+		return fmt.Errorf("invalid secrets passphrase: %w", err)
+	}
+
+	var out bytes.Buffer
+	w, err := age.Encrypt(&out, recipient)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt secrets: %w", err)
+	}
+	fmt.Fprintf(w, "%s=%s\n%s=%s\n", APIKeyName, keys.APIKey, SecretKeyName, keys.SecretKey)
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to encrypt secrets: %w", err)
+	}
+
+	if err := os.WriteFile(path, out.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write secrets file: %w", err)
+	}
+	return nil
+}
+
+func fromCommand(command string) (map[string]string, error) {
+	if command == "" {
+		return nil, fmt.Errorf("SECRETS_SOURCE=command requires SECRETS_COMMAND")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("secrets command failed: %w (%s)", err, strings.TrimSpace(stderr.String()))
+	}
+	return parseValues("secrets command output", out)
+}
+
+// parseValues reads KEY=VALUE lines (.env syntax: comments, quotes and export are allowed)
+func parseValues(origin string, content []byte) (map[string]string, error) {
+	values, err := godotenv.Unmarshal(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s (expected KEY=VALUE lines): %w", origin, err)
+	}
+	return values, nil
+}
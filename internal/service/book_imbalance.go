@@ -0,0 +1,57 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const BookImbalanceCSVPath = "logs/book_imbalance.csv"
+
+// BookImbalanceHeader lists the columns of the order-book imbalance log
+var BookImbalanceHeader = []string{
+	"time", "source", "bid", "ask", "bid_volume", "ask_volume", "imbalance", "action", "delayed_sec",
+}
+
+// bookImbalanceTyped are the numeric book imbalance columns
+var bookImbalanceTyped = columnSet("bid", "ask", "bid_volume", "ask_volume", "imbalance", "delayed_sec")
+
+// BookImbalanceLog appends the order-book imbalance read before every grid buy the filter
+// evaluated (logs/book_imbalance.csv) with what it did: buy, delay or forced (placed after
+// BOOK_IMBALANCE_MAX_DELAY_SEC). Joined with later prices it tells whether the delays paid off.
+type BookImbalanceLog struct {
+	Writer *RecordWriter
+}
+
+func NewBookImbalanceLog(jsonOutput bool) *BookImbalanceLog {
+	jsonPath := ""
+	if jsonOutput {
+		jsonPath = "logs/book_imbalance.jsonl"
+	}
+	return &BookImbalanceLog{
+		Writer: NewRecordWriter(BookImbalanceCSVPath, jsonPath, BookImbalanceHeader, bookImbalanceTyped),
+	}
+}
+
+// Start starts the background persistence of the imbalance rows
+func (l *BookImbalanceLog) Start() {
+	l.Writer.Start()
+}
+
+// Record logs one imbalance reading and the action taken on it
+func (l *BookImbalanceLog) Record(at time.Time, source string, bid, ask, bidVolume, askVolume, imbalance float64, action string, delayed time.Duration) {
+	if l == nil {
+		return
+	}
+	l.Writer.Write([]string{
+		at.UTC().Format(time.RFC3339),
+		source,
+		strconv.FormatFloat(bid, 'f', -1, 64),
+		strconv.FormatFloat(ask, 'f', -1, 64),
+		strconv.FormatFloat(bidVolume, 'f', -1, 64),
+		strconv.FormatFloat(askVolume, 'f', -1, 64),
+		fmt.Sprintf("%.4f", imbalance),
+		action,
+		fmt.Sprintf("%.0f", delayed.Seconds()),
+	})
+}
@@ -1,24 +1,83 @@
 package service
 
 import (
-	"encoding/csv"
 	"fmt"
-	"os"
 	"strconv"
 	"time"
 
 	"grid-trading-btc-binance/internal/config"
 	"grid-trading-btc-binance/internal/logger"
 	"grid-trading-btc-binance/internal/market"
+	"grid-trading-btc-binance/internal/model"
 	"grid-trading-btc-binance/internal/repository"
 )
 
+const (
+	CollectorCSVPath  = "logs/analyze_strategy.csv"
+	collectorJSONPath = "logs/analyze_strategy.jsonl"
+)
+
+var collectorHeader = []string{
+	"timestamp", "strategy_name", "exchange", "symbol", "timeframe",
+	"grid_levels", "range_min", "range_max", "position_size_pct", "stop_loss_pct",
+	"btc_price", "bnb_price", "in_range",
+	"volatility_gk", "volatility_multiplier", "dynamic_spacing_pct",
+	"balance_usdt", "balance_btc", "balance_bnb", "strategy_equity_usdt", "inventory_ratio_btc",
+	"trades_total", "trades_buy", "trades_sell", "volume_usdt", "volume_btc", "realized_profit_usdt", "avg_buy_price", "avg_sell_price",
+	"total_fees_bnb", "total_fees_usdt_equiv", "open_orders_count", "unrealized_pnl_usdt", "range_utilization_pct",
+	"avg_holding_time_min",
+	"max_drawdown_pct_1h", // Group 3
+	"deployable_usdt", "reserved_usdt",
+	"deposits_usdt", "withdrawals_usdt",
+	"trades_30d", "win_rate_30d", "avg_win_usdt_30d", "avg_loss_usdt_30d", "profit_factor_30d", "expectancy_usdt_30d",
+	"sharpe_daily_30d", "sortino_daily_30d",
+	"fills_1h", "maker_ratio_pct_1h", "slippage_bps_1h",
+	"hedge_qty", "hedge_entry_price", "hedge_unrealized_usdt", "hedge_realized_usdt",
+	"earn_usdt", "earn_interest_usdt",
+	"volatility_regime", "regime_changes_24h", "high_vol_pct_24h",
+	"level_stats_30d",
+	"macro_pause",
+}
+
+// collectorTyped is every collector column but the text ones
+var collectorTyped = func() map[string]bool {
+	typed := columnSet(collectorHeader...)
+	for _, column := range []string{
+		"timestamp", "strategy_name", "exchange", "symbol", "timeframe",
+		"volatility_regime", "level_stats_30d", "macro_pause",
+	} {
+		delete(typed, column)
+	}
+	return typed
+}()
+
+// HedgeStats is the futures hedge as last seen by the strategy
+type HedgeStats struct {
+	Qty           float64 // Short size (0 = no hedge)
+	EntryPrice    float64
+	UnrealizedPnL float64
+	RealizedPnL   float64 // Realized PnL, fees and funding of every closed hedge
+}
+
+// EarnStats is the Simple Earn holding as last seen by the strategy
+type EarnStats struct {
+	Balance  float64 // Quote asset parked in Flexible savings
+	APR      float64 // Latest annual rate (0.05 = 5%)
+	Interest float64 // Interest accrued since the tracking started
+}
+
 type DataCollector struct {
 	Cfg               *config.Config
 	BalanceRepo       *repository.BalanceRepository
 	TransactionRepo   *repository.TransactionRepository
 	MarketData        *MarketDataService
 	VolatilityService *market.VolatilityService
+	Writer            *RecordWriter     // Background CSV (+ optional JSON Lines) persistence
+	Sink              MetricsSink       // Optional time-series copy of each record (nil = disabled)
+	Executions        *ExecutionLog     // Fill quality since the previous record (nil = not tracked)
+	Hedge             func() HedgeStats // Futures hedge accounting (nil = no hedge)
+	Earn              func() EarnStats  // Simple Earn holding (nil = not parked)
+	MacroPause        func() string     // Macro event pausing the new entries (nil = no macro calendar)
 }
 
 func NewDataCollector(cfg *config.Config, balanceRepo *repository.BalanceRepository, transactionRepo *repository.TransactionRepository, marketData *MarketDataService, volService *market.VolatilityService) *DataCollector {
@@ -28,7 +87,21 @@ func NewDataCollector(cfg *config.Config, balanceRepo *repository.BalanceReposit
 		TransactionRepo:   transactionRepo,
 		MarketData:        marketData,
 		VolatilityService: volService,
+		Writer:            newCollectorWriter(cfg),
+	}
+}
+
+func newCollectorWriter(cfg *config.Config) *RecordWriter {
+	jsonPath := ""
+	if cfg.CollectorJSONOutput {
+		jsonPath = collectorJSONPath
 	}
+	return NewRecordWriter(CollectorCSVPath, jsonPath, collectorHeader, collectorTyped)
+}
+
+// StartWriter starts the background persistence of the hourly records
+func (c *DataCollector) StartWriter() {
+	c.Writer.Start()
 }
 
 func (c *DataCollector) CollectAndSave() {
@@ -43,8 +116,8 @@ func (c *DataCollector) CollectAndSave() {
 	timestamp := now.Format(time.RFC3339)
 
 	// Market Data
-	btcPrice, _ := c.MarketData.GetPrice("BTCUSDT")
-	bnbPrice, _ := c.MarketData.GetPrice("BNBUSDT")
+	btcPrice, _ := c.MarketData.GetPrice(c.Cfg.Symbol)
+	bnbPrice, _ := c.MarketData.GetPrice("BNB" + c.Cfg.QuoteAsset)
 	inRange := "false"
 	if btcPrice >= c.Cfg.RangeMin && btcPrice <= c.Cfg.RangeMax {
 		inRange = "true"
@@ -53,6 +126,10 @@ func (c *DataCollector) CollectAndSave() {
 	// Volatility Data (Telemetria GK)
 	currentVol, volMult := c.VolatilityService.GetMetrics()
 	dynamicSpacing := c.VolatilityService.GetDynamicSpacing()
+	regimeStats, err := c.VolatilityService.RegimeStats(now.Add(-24*time.Hour), now)
+	if err != nil {
+		logger.Warn("⚠️ Collector: Failed to read the regime history", "error", err)
+	}
 
 	// Range Utilization
 	rangeDiff := c.Cfg.RangeMax - c.Cfg.RangeMin
@@ -62,48 +139,30 @@ func (c *DataCollector) CollectAndSave() {
 	}
 
 	// 1. Open Orders & Position Analysis (TRUE Inventory from DB)
-	allTx := c.TransactionRepo.GetAll()
-	openOrdersCount := 0
-	totalCostBasis := 0.0
-	totalQtyFilled := 0.0
-
-	for _, tx := range allTx {
-		if tx.Symbol == c.Cfg.Symbol {
-			if tx.StatusTransaction == "open" {
-				openOrdersCount++
-			} else if tx.StatusTransaction == "filled" && tx.Type == "buy" {
-				p, _ := strconv.ParseFloat(tx.Price, 64)
-				q, _ := strconv.ParseFloat(tx.Amount, 64)
-				totalCostBasis += (p * q)
-				totalQtyFilled += q
-			}
-		}
-	}
-
-	avgEntryPrice := 0.0
-	if totalQtyFilled > 0 {
-		avgEntryPrice = totalCostBasis / totalQtyFilled
+	var earn EarnStats
+	if c.Earn != nil {
+		earn = c.Earn()
 	}
+	inv := ComputeInventory(c.Cfg, c.BalanceRepo, c.TransactionRepo, btcPrice).WithParked(earn.Balance)
+	openOrdersCount := inv.OpenOrdersCount
+	totalQtyFilled := inv.GridQty
+	avgEntryPrice := inv.AvgEntryPrice()
 
 	// 2. Wallet Data
-	balanceUSDT := c.getBalance("USDT")
-	balanceBTC := c.getBalance("BTC")
+	balanceUSDT := inv.BalanceUSDT
+	balanceBTC := inv.BalanceBTC
 	balanceBNB := c.getBalance("BNB")
 
-	// Strategy Equity (USDT + BTC Value)
-	// FIX: Use totalQtyFilled (Inventory) + balanceUSDT to better represent strategy value?
-	// Or stay with Wallet? If Wallet BTC is 0 (Locked), Equity drops.
-	// We should add Locked Inventory Value to Equity.
-	strategyEquity := balanceUSDT + (totalQtyFilled * btcPrice) + (balanceBTC * btcPrice)
-	// Note: balanceBTC is "Free". totalQtyFilled is "Locked in Strategy".
-	// Usually they shouldn't overlap if 'filled' implies 'open sell'.
-
-	// Inventory Ratio
-	// Ratio = (BTC Value) / Total Equity
-	inventoryRatio := 0.0
-	if strategyEquity > 0 {
-		inventoryRatio = ((totalQtyFilled + balanceBTC) * btcPrice) / strategyEquity
+	// Capital split: the strategy never deploys below USDT_RESERVE
+	reservedUSDT := c.Cfg.USDTReserve
+	if balanceUSDT < reservedUSDT {
+		reservedUSDT = balanceUSDT
 	}
+	deployableUSDT := balanceUSDT - reservedUSDT
+
+	// Strategy Equity (USDT + BTC Value), including the inventory locked in exits
+	strategyEquity := inv.Equity
+	inventoryRatio := inv.InventoryRatio
 
 	unrealizedPnL := 0.0
 	if totalQtyFilled > 0 && avgEntryPrice > 0 {
@@ -128,10 +187,25 @@ func (c *DataCollector) CollectAndSave() {
 	totalHoldDurationMin := 0.0
 	countClosedTrades := 0
 
+	// Capital flows (not trading performance)
+	depositsUSDT := 0.0
+	withdrawalsUSDT := 0.0
+
 	for _, tx := range recentTx {
+		if tx.Type == "deposit" || tx.Type == "withdraw" {
+			amount, _ := strconv.ParseFloat(tx.Amount, 64)
+			price, _ := strconv.ParseFloat(tx.Price, 64)
+			if tx.Type == "deposit" {
+				depositsUSDT += amount * price
+			} else {
+				withdrawalsUSDT += amount * price
+			}
+			continue
+		}
+
 		// Only process type="buy" transactions that have been closed (sold)
 		// Skip orphan sell records and any repositioned/cancelled orders
-		if tx.Type != "buy" || tx.StatusTransaction != "closed" {
+		if tx.Type != "buy" || tx.StatusTransaction != model.StatusClosed {
 			continue
 		}
 
@@ -201,6 +275,26 @@ func (c *DataCollector) CollectAndSave() {
 		}
 	}
 
+	// Trade Statistics (rolling 30 days of the archive)
+	closed30d := c.TransactionRepo.GetClosedTransactionsAfter(now.AddDate(0, 0, -tradeStatsLookbackDays))
+	stats := ComputeTradeStats(closed30d, strategyEquity, bnbPrice, now, tradeStatsLookbackDays)
+	levels := ComputeLevelStats(closed30d, c.TransactionRepo.GetAll(), btcPrice, bnbPrice, now, tradeStatsLookbackDays)
+
+	// Execution Quality (fills since the previous record)
+	execStats := c.Executions.TakeHour()
+
+	// Futures Hedge (position at the last hedge check)
+	var hedge HedgeStats
+	if c.Hedge != nil {
+		hedge = c.Hedge()
+	}
+
+	// Macro Calendar (event pausing the new entries now)
+	macroPause := ""
+	if c.MacroPause != nil {
+		macroPause = c.MacroPause()
+	}
+
 	// 2. Prepare CSV Record
 	record := []string{
 		timestamp,
@@ -249,14 +343,58 @@ func (c *DataCollector) CollectAndSave() {
 		fmt.Sprintf("%.2f", rangeUtilizationPct),
 		fmt.Sprintf("%.2f", avgHoldingTimeMin), // Group 2: Avg Holding Time
 		fmt.Sprintf("%.4f", maxDrawdownPct),    // Group 3
+
+		// Capital (Reserve)
+		fmt.Sprintf("%.2f", deployableUSDT),
+		fmt.Sprintf("%.2f", reservedUSDT),
+
+		// Capital Flows (1h)
+		fmt.Sprintf("%.2f", depositsUSDT),
+		fmt.Sprintf("%.2f", withdrawalsUSDT),
+
+		// Trade Statistics (30d)
+		fmt.Sprintf("%d", stats.Trades),
+		fmt.Sprintf("%.4f", stats.WinRate),
+		fmt.Sprintf("%.4f", stats.AvgWin),
+		fmt.Sprintf("%.4f", stats.AvgLoss),
+		fmt.Sprintf("%.4f", stats.ProfitFactor),
+		fmt.Sprintf("%.4f", stats.Expectancy),
+		fmt.Sprintf("%.4f", stats.Sharpe),
+		fmt.Sprintf("%.4f", stats.Sortino),
+
+		// Execution Quality (1h)
+		fmt.Sprintf("%d", execStats.Fills),
+		fmt.Sprintf("%.2f", execStats.MakerRatioPct()),
+		fmt.Sprintf("%.4f", execStats.SlippageBps()),
+
+		// Futures Hedge
+		fmt.Sprintf("%.8f", hedge.Qty),
+		fmt.Sprintf("%.2f", hedge.EntryPrice),
+		fmt.Sprintf("%.4f", hedge.UnrealizedPnL),
+		fmt.Sprintf("%.4f", hedge.RealizedPnL),
+
+		// Simple Earn
+		fmt.Sprintf("%.2f", earn.Balance),
+		fmt.Sprintf("%.4f", earn.Interest),
+
+		// Volatility Regime (24h)
+		c.VolatilityService.GetRegime(),
+		fmt.Sprintf("%d", regimeStats.Changes),
+		fmt.Sprintf("%.2f", regimeStats.HighVolPct),
+
+		// Per Grid Level (30d)
+		FormatLevelStats(levels),
+
+		// Macro Calendar
+		macroPause,
 	}
 
-	// 3. Save to CSV
-	// Ensure folder exists
-	if _, err := os.Stat("logs"); os.IsNotExist(err) {
-		os.Mkdir("logs", 0755)
+	// 3. Save (in background, off the bot loop)
+	c.Writer.Write(record)
+	if c.Sink != nil {
+		tags := map[string]string{"symbol": c.Cfg.Symbol, "strategy": c.Cfg.StrategyMode}
+		c.Sink.Write(PointFromRecord("grid_hourly", tags, collectorHeader, collectorTyped, record, now))
 	}
-	c.appendToCSV("logs/analyze_strategy.csv", record)
 }
 
 func (c *DataCollector) getBalance(currency string) float64 {
@@ -266,42 +404,3 @@ func (c *DataCollector) getBalance(currency string) float64 {
 	}
 	return b.Amount
 }
-
-func (c *DataCollector) appendToCSV(filename string, record []string) {
-	fileExists := false
-	if _, err := os.Stat(filename); err == nil {
-		fileExists = true
-	}
-
-	f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		logger.Error("Failed to open CSV", "error", err)
-		return
-	}
-	defer f.Close()
-
-	w := csv.NewWriter(f)
-	defer w.Flush()
-
-	// Write Header if new file
-	if !fileExists {
-		header := []string{
-			"timestamp", "strategy_name", "exchange", "symbol", "timeframe",
-			"grid_levels", "range_min", "range_max", "position_size_pct", "stop_loss_pct",
-			"btc_price", "bnb_price", "in_range",
-			"volatility_gk", "volatility_multiplier", "dynamic_spacing_pct",
-			"balance_usdt", "balance_btc", "balance_bnb", "strategy_equity_usdt", "inventory_ratio_btc",
-			"trades_total", "trades_buy", "trades_sell", "volume_usdt", "volume_btc", "realized_profit_usdt", "avg_buy_price", "avg_sell_price",
-			"total_fees_bnb", "total_fees_usdt_equiv", "open_orders_count", "unrealized_pnl_usdt", "range_utilization_pct",
-			"avg_holding_time_min",
-			"max_drawdown_pct_1h", // Group 3
-		}
-		if err := w.Write(header); err != nil {
-			logger.Error("Failed to write CSV header", "error", err)
-		}
-	}
-
-	if err := w.Write(record); err != nil {
-		logger.Error("Failed to write CSV record", "error", err)
-	}
-}
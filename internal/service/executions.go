@@ -0,0 +1,213 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const ExecutionsCSVPath = "logs/executions.csv"
+
+// ExecutionsHeader lists the columns of the per-fill execution log
+var ExecutionsHeader = []string{
+	"time", "order_id", "side", "type",
+	"intended_price", "fill_price", "qty", "slippage_bps", "maker",
+}
+
+// executionsTyped are the numeric and boolean execution columns (order ids stay strings)
+var executionsTyped = columnSet("intended_price", "fill_price", "qty", "slippage_bps", "maker")
+
+// ExecutionStats aggregates fill quality: maker ratio over every fill and slippage
+// weighted by notional over the fills with a reference price
+type ExecutionStats struct {
+	Fills         int
+	MakerFills    int
+	WorstSlippage float64 // bps, highest adverse slippage of a single fill
+
+	slippageFills    int
+	slippageNotional float64 // Sum of slippage_bps * notional
+	notional         float64
+}
+
+func (s *ExecutionStats) add(fillPrice, qty, slippageBps float64, hasReference, maker bool) {
+	s.Fills++
+	if maker {
+		s.MakerFills++
+	}
+	if !hasReference {
+		return
+	}
+	notional := fillPrice * qty
+	s.slippageFills++
+	s.slippageNotional += slippageBps * notional
+	s.notional += notional
+	if s.slippageFills == 1 || slippageBps > s.WorstSlippage {
+		s.WorstSlippage = slippageBps
+	}
+}
+
+// MakerRatioPct is the share of fills executed as maker (0-100)
+func (s ExecutionStats) MakerRatioPct() float64 {
+	if s.Fills == 0 {
+		return 0
+	}
+	return float64(s.MakerFills) / float64(s.Fills) * 100
+}
+
+// SlippageBps is the notional-weighted mean slippage in basis points. Positive is adverse
+// (bought above / sold below the intended price), negative is price improvement.
+func (s ExecutionStats) SlippageBps() float64 {
+	if s.notional == 0 {
+		return 0
+	}
+	return s.slippageNotional / s.notional
+}
+
+// ExecutionLog records the intended vs actual price of every fill of the symbol and whether
+// it executed as maker or taker (logs/executions.csv), keeping the running hourly aggregate
+// for the collector. The intended price is the one registered with Expect when the order was
+// decided (the bid that triggered a buy, the reference of a market order), else the order's
+// limit price.
+type ExecutionLog struct {
+	Writer *RecordWriter
+
+	mu       sync.Mutex
+	intended map[string]float64 // By client order ID, until the order is done
+	hour     ExecutionStats     // Fills since the last TakeHour
+}
+
+func NewExecutionLog(jsonOutput bool) *ExecutionLog {
+	jsonPath := ""
+	if jsonOutput {
+		jsonPath = "logs/executions.jsonl"
+	}
+	return &ExecutionLog{
+		Writer:   NewRecordWriter(ExecutionsCSVPath, jsonPath, ExecutionsHeader, executionsTyped),
+		intended: make(map[string]float64),
+	}
+}
+
+// Start starts the background persistence of the execution rows
+func (l *ExecutionLog) Start() {
+	l.Writer.Start()
+}
+
+// Expect registers the price the strategy meant to trade an order at
+func (l *ExecutionLog) Expect(clientOrderID string, price float64) {
+	if l == nil || price <= 0 {
+		return
+	}
+	l.mu.Lock()
+	l.intended[clientOrderID] = price
+	l.mu.Unlock()
+}
+
+// Observe records the TRADE executionReports and forgets the expectation of finished orders
+func (l *ExecutionLog) Observe(event OrderUpdate) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if event.ExecutionType == "TRADE" {
+		l.record(event)
+	}
+	switch event.Status {
+	case "FILLED", "CANCELED", "REJECTED", "EXPIRED":
+		delete(l.intended, event.ClientOrderID)
+	}
+}
+
+func (l *ExecutionLog) record(event OrderUpdate) {
+	fillPrice, _ := strconv.ParseFloat(event.LastExecPrice, 64)
+	qty, _ := strconv.ParseFloat(event.LastExecQty, 64)
+	if fillPrice <= 0 || qty <= 0 {
+		return
+	}
+	intended, ok := l.intended[event.ClientOrderID]
+	if !ok {
+		intended, _ = strconv.ParseFloat(event.Price, 64) // 0 on market orders
+	}
+
+	slippage := 0.0
+	intendedStr, slippageStr := "", ""
+	if intended > 0 {
+		slippage = (fillPrice - intended) / intended * 10000
+		if event.Side == "SELL" {
+			slippage = -slippage
+		}
+		intendedStr = strconv.FormatFloat(intended, 'f', -1, 64)
+		slippageStr = fmt.Sprintf("%.4f", slippage)
+	}
+	l.hour.add(fillPrice, qty, slippage, intended > 0, event.IsMaker)
+
+	at := time.UnixMilli(event.TxTime)
+	if event.TxTime == 0 {
+		at = time.Now()
+	}
+	l.Writer.Write([]string{
+		at.UTC().Format(time.RFC3339),
+		event.ClientOrderID,
+		event.Side,
+		event.Type,
+		intendedStr,
+		event.LastExecPrice,
+		event.LastExecQty,
+		slippageStr,
+		strconv.FormatBool(event.IsMaker),
+	})
+}
+
+// TakeHour returns the fills recorded since the previous call and starts a new window
+func (l *ExecutionLog) TakeHour() ExecutionStats {
+	if l == nil {
+		return ExecutionStats{}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	stats := l.hour
+	l.hour = ExecutionStats{}
+	return stats
+}
+
+// ReadExecutionStats aggregates the rows of the execution log with from <= time < to
+func ReadExecutionStats(path string, from, to time.Time) (ExecutionStats, error) {
+	var stats ExecutionStats
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return stats, nil
+	}
+	if err != nil {
+		return stats, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	for first := true; ; first = false {
+		row, err := r.Read()
+		if err == io.EOF {
+			return stats, nil
+		}
+		if err != nil {
+			return stats, err
+		}
+		if first || len(row) < len(ExecutionsHeader) {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339, row[0])
+		if err != nil || at.Before(from) || !at.Before(to) {
+			continue
+		}
+		fillPrice, _ := strconv.ParseFloat(row[5], 64)
+		qty, _ := strconv.ParseFloat(row[6], 64)
+		slippage, errSlip := strconv.ParseFloat(row[7], 64)
+		stats.add(fillPrice, qty, slippage, errSlip == nil, row[8] == "true")
+	}
+}
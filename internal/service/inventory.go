@@ -0,0 +1,93 @@
+package service
+
+import (
+	"strconv"
+
+	"grid-trading-btc-binance/internal/config"
+	"grid-trading-btc-binance/internal/model"
+	"grid-trading-btc-binance/internal/repository"
+)
+
+// InventorySnapshot is the strategy's BTC/USDT position valued at a given price
+type InventorySnapshot struct {
+	BalanceUSDT     float64 // Free quote asset (USDT) in the wallet
+	BalanceBTC      float64 // Free base asset (BTC) in the wallet
+	LockedUSDT      float64 // Quote asset locked in open grid buys
+	LockedBTC       float64 // Base asset locked in the exits on the book (waiting_sell)
+	OpenOrdersCount int
+	GridQty         float64 // BTC held by the grid (filled buys, with or without an exit)
+	GridCostBasis   float64
+	ParkedUSDT      float64 // Quote asset outside the wallet (Simple Earn), see WithParked
+	Equity          float64 // USDT + all BTC valued at price
+	InventoryRatio  float64 // BTC value / Equity
+}
+
+// AvgEntryPrice returns the average entry of the grid inventory (0 if empty)
+func (i InventorySnapshot) AvgEntryPrice() float64 {
+	if i.GridQty <= 0 {
+		return 0
+	}
+	return i.GridCostBasis / i.GridQty
+}
+
+// WithParked adds quote asset held outside the wallet (Simple Earn) to the equity
+func (i InventorySnapshot) WithParked(amount float64) InventorySnapshot {
+	baseValue := i.InventoryRatio * i.Equity
+	i.ParkedUSDT += amount
+	i.Equity += amount
+	if i.Equity > 0 {
+		i.InventoryRatio = baseValue / i.Equity
+	}
+	return i
+}
+
+// ComputeInventory builds the inventory snapshot of cfg.Symbol from the local DB and balance cache
+func ComputeInventory(cfg *config.Config, balanceRepo *repository.BalanceRepository, transactionRepo *repository.TransactionRepository, price float64) InventorySnapshot {
+	var inv InventorySnapshot
+
+	// TRUE Inventory from DB
+	for _, tx := range transactionRepo.GetAll() {
+		if tx.Symbol != cfg.Symbol {
+			continue
+		}
+		p, _ := strconv.ParseFloat(tx.Price, 64)
+		q, _ := strconv.ParseFloat(tx.Amount, 64)
+		if tx.StatusTransaction == model.StatusOpen {
+			inv.OpenOrdersCount++
+			if tx.Type == "buy" {
+				inv.LockedUSDT += p * q
+			}
+			continue
+		}
+		if tx.Type != "buy" {
+			continue
+		}
+		switch tx.StatusTransaction {
+		case model.StatusExitPlaced:
+			inv.LockedBTC += q
+		case model.StatusFilled, model.StatusFailed:
+		default:
+			continue
+		}
+		inv.GridCostBasis += p * q
+		inv.GridQty += q
+	}
+
+	if b, ok := balanceRepo.Get(cfg.QuoteAsset); ok {
+		inv.BalanceUSDT = b.Amount
+	}
+	if b, ok := balanceRepo.Get(cfg.BaseAsset); ok {
+		inv.BalanceBTC = b.Amount
+	}
+
+	// Strategy Equity (USDT + BTC Value). The balances are free amounts: filled buys still
+	// waiting for an exit are already in BalanceBTC, what sits in exits and open buys is added
+	baseQty := inv.BalanceBTC + inv.LockedBTC
+	inv.Equity = inv.BalanceUSDT + inv.LockedUSDT + baseQty*price
+
+	// Ratio = (BTC Value) / Total Equity
+	if inv.Equity > 0 {
+		inv.InventoryRatio = baseQty * price / inv.Equity
+	}
+	return inv
+}
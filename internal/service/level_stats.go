@@ -0,0 +1,108 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"grid-trading-btc-binance/internal/model"
+)
+
+// LevelStats is the realized performance of the buys of one grid level (L1 = the first buy of
+// an empty grid, deeper levels are placed as the price keeps dropping)
+type LevelStats struct {
+	Level      int
+	RoundTrips int     // Positions of the level closed in the period (exit, take-profit, panic...)
+	Wins       int     // Round trips with a positive net result
+	Held       int     // Positions of the level still waiting for their exit
+	HitRate    float64 // Wins over round trips plus held positions (0-1): a held bag has not paid off yet
+	AvgHoldMin float64 // Mean buy-to-sell time of the round trips
+	NetProfit  float64 // Net of fees, in the quote asset
+	Unrealized float64 // Held positions marked at the price, in the quote asset
+}
+
+// ComputeLevelStats groups the round trips closed in the last `days` days and the positions
+// held now by the grid level of their buy. Fees are the BNB commissions valued at bnbPrice, as
+// in ComputeTradeStats. Buys without a level (DCA, imported, manual) are left out.
+func ComputeLevelStats(closed, active []model.Transaction, price, bnbPrice float64, now time.Time, days int) []LevelStats {
+	start := now.AddDate(0, 0, -days)
+	byLevel := make(map[int]*LevelStats)
+	statsOf := func(tx model.Transaction) *LevelStats {
+		level := tx.GridLevel()
+		if level <= 0 || tx.Type != "buy" {
+			return nil
+		}
+		if byLevel[level] == nil {
+			byLevel[level] = &LevelStats{Level: level}
+		}
+		return byLevel[level]
+	}
+
+	holdMin := make(map[int]float64)
+	for _, tx := range closed {
+		if tx.StatusTransaction != model.StatusClosed || tx.SellPrice == 0 || tx.ClosedAt == nil {
+			continue
+		}
+		if tx.ClosedAt.Before(start) || tx.ClosedAt.After(now) {
+			continue
+		}
+		stats := statsOf(tx)
+		if stats == nil {
+			continue
+		}
+		amount, _ := strconv.ParseFloat(tx.Amount, 64)
+		buyPrice, _ := strconv.ParseFloat(tx.Price, 64)
+		fee, _ := strconv.ParseFloat(tx.Fee, 64)
+		net := (tx.SellPrice-buyPrice)*amount - fee*bnbPrice
+
+		stats.RoundTrips++
+		if net > 0 {
+			stats.Wins++
+		}
+		stats.NetProfit += net
+		holdMin[stats.Level] += tx.ClosedAt.Sub(tx.CreatedAt).Minutes()
+	}
+
+	for _, tx := range active {
+		switch tx.StatusTransaction {
+		case model.StatusFilled, model.StatusExitPlaced, model.StatusFailed:
+		default:
+			continue
+		}
+		stats := statsOf(tx)
+		if stats == nil {
+			continue
+		}
+		amount, _ := strconv.ParseFloat(tx.Amount, 64)
+		buyPrice, _ := strconv.ParseFloat(tx.Price, 64)
+		stats.Held++
+		if price > 0 {
+			stats.Unrealized += (price - buyPrice) * (amount - tx.QuantitySold)
+		}
+	}
+
+	levels := make([]LevelStats, 0, len(byLevel))
+	for level, stats := range byLevel {
+		if stats.RoundTrips > 0 {
+			stats.AvgHoldMin = holdMin[level] / float64(stats.RoundTrips)
+		}
+		if n := stats.RoundTrips + stats.Held; n > 0 {
+			stats.HitRate = float64(stats.Wins) / float64(n)
+		}
+		levels = append(levels, *stats)
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i].Level < levels[j].Level })
+	return levels
+}
+
+// FormatLevelStats is the compact one-cell form of the levels used by the collector:
+// "L1:12/92%/35m/4.20 L2:..." (round trips / hit rate / average holding time / net profit)
+func FormatLevelStats(levels []LevelStats) string {
+	parts := make([]string, len(levels))
+	for i, l := range levels {
+		parts[i] = fmt.Sprintf("L%d:%d/%.0f%%/%.0fm/%.2f", l.Level, l.RoundTrips, l.HitRate*100, l.AvgHoldMin, l.NetProfit)
+	}
+	return strings.Join(parts, " ")
+}
@@ -5,6 +5,7 @@ import (
 	"sync"
 	"time"
 
+	"grid-trading-btc-binance/internal/crash"
 	"grid-trading-btc-binance/internal/logger"
 	"grid-trading-btc-binance/internal/model"
 
@@ -14,6 +15,9 @@ import (
 type MarketDataService struct {
 	mu           sync.RWMutex
 	prices       map[string]float64
+	lastTicker   map[string]time.Time // Health: when each symbol last ticked
+	connected    map[string]bool
+	stops        map[string]chan struct{} // Stops the current connection of each symbol
 	priceUpdates chan model.Ticker
 	stopCh       chan struct{}
 }
@@ -21,6 +25,9 @@ type MarketDataService struct {
 func NewMarketDataService() *MarketDataService {
 	return &MarketDataService{
 		prices:       make(map[string]float64),
+		lastTicker:   make(map[string]time.Time),
+		connected:    make(map[string]bool),
+		stops:        make(map[string]chan struct{}),
 		priceUpdates: make(chan model.Ticker, 100),
 		stopCh:       make(chan struct{}),
 	}
@@ -28,7 +35,7 @@ func NewMarketDataService() *MarketDataService {
 
 func (s *MarketDataService) Start(symbols []string) {
 	for _, symbol := range symbols {
-		go s.monitorSymbol(symbol)
+		crash.Go("market "+symbol, func() { s.monitorSymbol(symbol) })
 	}
 }
 
@@ -42,8 +49,11 @@ func (s *MarketDataService) monitorSymbol(symbol string) {
 		}
 
 		wsHandler := func(event *binance.WsBookTickerEvent) {
+			defer crash.Recover("market " + symbol + " handler") // Runs on the library's read goroutine
 			bestBid, _ := strconv.ParseFloat(event.BestBidPrice, 64)
 			bestAsk, _ := strconv.ParseFloat(event.BestAskPrice, 64)
+			bidQty, _ := strconv.ParseFloat(event.BestBidQty, 64)
+			askQty, _ := strconv.ParseFloat(event.BestAskQty, 64)
 			// Use best bid as "market price" proxy or average?
 			// Actually, typical implementation uses MidPrice or LastTrade.
 			// BookTicker gives us Bid/Ask but not LastTrade.
@@ -73,6 +83,7 @@ func (s *MarketDataService) monitorSymbol(symbol string) {
 
 			s.mu.Lock()
 			s.prices[symbol] = bestBid
+			s.lastTicker[symbol] = time.Now()
 			s.mu.Unlock()
 
 			s.priceUpdates <- model.Ticker{
@@ -80,6 +91,8 @@ func (s *MarketDataService) monitorSymbol(symbol string) {
 				Price:  bestBid, // Using Bid as reference price
 				Bid:    bestBid,
 				Ask:    bestAsk,
+				BidQty: bidQty,
+				AskQty: askQty,
 				Time:   time.Now(), // Event doesn't have standard time field always populated same way, safe to use Now
 			}
 		}
@@ -95,6 +108,10 @@ func (s *MarketDataService) monitorSymbol(symbol string) {
 			time.Sleep(5 * time.Second)
 			continue
 		}
+		s.setConnected(symbol, true)
+		s.mu.Lock()
+		s.stops[symbol] = stopC
+		s.mu.Unlock()
 
 		// Handle stop signal or connection close
 		select {
@@ -102,6 +119,7 @@ func (s *MarketDataService) monitorSymbol(symbol string) {
 			stopC <- struct{}{}
 			return
 		case <-doneC:
+			s.setConnected(symbol, false)
 			logger.Warn("WebSocket connection closed, reconnecting in 5s...", "symbol", symbol)
 			time.Sleep(5 * time.Second)
 		}
@@ -115,6 +133,40 @@ func (s *MarketDataService) GetPrice(symbol string) (float64, bool) {
 	return price, ok
 }
 
+// LastTickerAt returns when symbol last ticked (zero if it never did)
+func (s *MarketDataService) LastTickerAt(symbol string) time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lastTicker[symbol]
+}
+
+// Connected reports whether the book ticker WebSocket of symbol is up
+func (s *MarketDataService) Connected(symbol string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.connected[symbol]
+}
+
+// Reconnect drops the current WebSocket connections; monitorSymbol dials them again
+func (s *MarketDataService) Reconnect() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for symbol, stopC := range s.stops {
+		select {
+		case stopC <- struct{}{}:
+			logger.Warn("🔌 Forcing market WebSocket reconnection", "symbol", symbol)
+		default: // Already closing
+		}
+		delete(s.stops, symbol)
+	}
+}
+
+func (s *MarketDataService) setConnected(symbol string, connected bool) {
+	s.mu.Lock()
+	s.connected[symbol] = connected
+	s.mu.Unlock()
+}
+
 func (s *MarketDataService) GetUpdates() <-chan model.Ticker {
 	return s.priceUpdates
 }
@@ -0,0 +1,366 @@
+package service
+
+import (
+	"strconv"
+	"time"
+
+	"grid-trading-btc-binance/internal/model"
+)
+
+const messageDateFormat = "02/01/2006, 15:04:05"
+
+// TradeMessageData is exposed to the trade_buy/trade_sell templates
+type TradeMessageData struct {
+	Symbol       string
+	Base         string // Base and quote assets of Symbol (BTC, USDT)
+	Quote        string
+	ID           string
+	Status       string
+	Qty          float64
+	Price        float64
+	Total        float64
+	Profit       float64
+	ClosedOrders []string
+	BalanceUSDT  float64 // Quote asset balance
+	BalanceBNB   float64
+	BalanceBTC   float64 // Base asset balance
+	Date         string
+}
+
+// LowBalanceMessageData is exposed to the low_balance_* templates
+type LowBalanceMessageData struct {
+	Currency string
+	Balance  float64
+	Required float64
+	Date     string
+}
+
+// BNBTopUpMessageData is exposed to the bnb_topup template
+type BNBTopUpMessageData struct {
+	SpentUSDT  float64
+	BoughtBNB  float64
+	AvgPrice   float64
+	BalanceBNB float64
+	CountToday int
+	MaxPerDay  int
+	Date       string
+}
+
+// VaultStatementMessageData is exposed to the vault_statement template
+type VaultStatementMessageData struct {
+	Month          string
+	Mode           string
+	SkimPct        float64
+	Trades         int
+	RealizedProfit float64
+	Skimmed        float64
+	Transferred    float64
+	TotalSkimmed   float64
+	Pending        float64
+}
+
+// PanicMessageData is exposed to the panic_executed template
+type PanicMessageData struct {
+	Base           string // Asset sold
+	Reason         string
+	CanceledOrders int
+	FailedCancels  int
+	SoldQty        float64
+	AvgPrice       float64
+	Proceeds       float64
+	RealizedPnL    float64
+	Archived       int
+	Kept           int // Positions left active (not sold, or exit still on the book)
+}
+
+// ExitFailedMessageData is exposed to the exit_failed template. RetryMin is 0 when the
+// exit recovery is disabled.
+type ExitFailedMessageData struct {
+	ID       string
+	RetryMin int
+	Deadline string
+}
+
+// ExitCanceledMessageData is exposed to the exit_canceled template
+type ExitCanceledMessageData struct {
+	ID          string
+	SellOrderID string
+	Status      string // CANCELED | REJECTED | EXPIRED
+	Sold        string // Quantity the exit filled before it was canceled ("" if none)
+}
+
+// ExposureMessageData is exposed to the exposure_naked and exposure_restored templates
+type ExposureMessageData struct {
+	Count     int
+	Limit     string
+	Positions []string // "<id>: <qty> @ <price> (<time without exit>)"
+	Failed    int      // failed_placement positions left to the exit recovery
+}
+
+// DrawdownMessageData is exposed to the drawdown_stop template. SoldQty is 0 when nothing was
+// sold (only pause, confirmation pending or the sell failed, see Error).
+type DrawdownMessageData struct {
+	Base          string
+	Quote         string
+	UnrealizedPnL float64
+	PnLPct        float64 // Over the inventory cost
+	CostBasis     float64
+	SellPct       float64 // Share of the inventory to sell, in %
+	Pending       bool    // The sell waits for /drawdown confirm
+	SoldQty       float64
+	AvgPrice      float64
+	RealizedPnL   float64
+	Positions     int // Positions closed by the sell
+	Error         string
+	CooloffUntil  string
+}
+
+// HedgeMessageData is exposed to the hedge_opened and hedge_closed templates
+type HedgeMessageData struct {
+	Symbol         string  // Futures contract
+	Qty            float64 // Short size
+	Price          float64 // Average fill
+	InventoryValue float64 // Grid inventory at the bid
+	MovingAverage  float64
+	TrendHours     int
+	Reason         string  // hedge_closed: trend (price back above the average) or inventory
+	PnL            float64 // hedge_closed: realized PnL, fees and funding of this hedge
+	TotalPnL       float64 // hedge_closed: every closed hedge
+}
+
+// MarginMessageData is exposed to the margin_level_low and margin_level_restored templates
+type MarginMessageData struct {
+	Level          string // margin_level_low: warn or critical
+	MarginLevel    float64
+	Warn           float64
+	Critical       float64
+	Base           string
+	Quote          string
+	QuoteDebt      float64 // Borrowed + interest
+	BaseDebt       float64
+	LiquidatePrice float64 // Isolated only (0 = unknown)
+	Repaid         float64 // Quote asset repaid at the critical level
+}
+
+// EndpointMessageData is exposed to the endpoint_switched template
+type EndpointMessageData struct {
+	From     string
+	To       string
+	Reason   string // Why the endpoint was left (failover only)
+	FailBack bool   // Back to the primary endpoint
+}
+
+// ClockDriftMessageData is exposed to the clock_drift template
+type ClockDriftMessageData struct {
+	Drift       string // Estimated from the response Date headers
+	Max         string // CLOCK_DRIFT_ALERT_MS
+	OldOffsetMs int64
+	NewOffsetMs int64  // After the resync
+	Error       string // Resync failure ("" = resynced)
+}
+
+// RangeStaleMessageData is exposed to the range_stale template
+type RangeStaleMessageData struct {
+	Symbol       string
+	Reason       string // outside | uncovered | wide
+	Price        float64
+	RangeMin     float64 // Range in force when checked
+	RangeMax     float64
+	SuggestedMin float64
+	SuggestedMax float64
+	CoveragePct  float64 // Share of the suggested band inside the range
+	WidthRatio   float64 // Range width / suggested band width
+	Days         int
+	StdDevs      float64
+	ATR          float64
+	Applied      bool // RANGE_AUTO_APPLY switched to the suggestion
+	Canceled     int  // Buys outside the new range canceled
+	Error        string
+}
+
+// PriceAlertMessageData is exposed to the price_alert template
+type PriceAlertMessageData struct {
+	Symbol    string
+	Kind      string // above | below | move | range
+	Alert     string // The PRICE_ALERTS entry
+	Side      string // range: min | max, move: up | down
+	Price     float64
+	Level     float64 // Price crossed, range boundary or, for a move, the low/high it started from
+	ChangePct float64 // Move over the window (signed), or distance to the range boundary (negative = outside)
+	Minutes   int     // Move window
+	Outside   bool    // range: the price already left the range
+	RangeMin  float64
+	RangeMax  float64
+}
+
+// TradingViewMessageData is exposed to the tradingview_signal template
+type TradingViewMessageData struct {
+	Symbol string
+	Action string // pause | resume | range | flatten
+	Reason string // "reason" of the signal
+	Result string // What was done
+	Error  string // Why the action was refused ("" = applied)
+}
+
+// RateLimitMessageData is exposed to the rate_limited template
+type RateLimitMessageData struct {
+	Lifted         bool   // Protection mode over
+	Ban            bool   // HTTP 418 (IP ban) rather than 429
+	Path           string // REST call that got the 429/418
+	Since          string
+	Until          string // End of the Retry-After: no REST call before
+	ProtectedUntil string // Essential calls only before
+}
+
+// ExitRecoveryMessageData is exposed to the exit_recovered and exit_escalated templates
+type ExitRecoveryMessageData struct {
+	ID       string
+	Attempts int
+	Elapsed  string
+	Amount   string
+	Price    string
+}
+
+// UpdatesOverflowMessageData is exposed to the updates_overflow template
+type UpdatesOverflowMessageData struct {
+	Dropped  uint64
+	Capacity int
+}
+
+// CircuitBreakerMessageData is exposed to the circuit_breaker_* templates
+type CircuitBreakerMessageData struct {
+	DropPct  float64
+	Price    float64
+	MaxHigh  float64
+	PauseMin int
+}
+
+// CircuitBreakerEscalationMessageData is exposed to the circuit_breaker_escalated and
+// circuit_breaker_relaxed templates. Until is empty for stop (lifted by /resume).
+type CircuitBreakerEscalationMessageData struct {
+	Trips       int
+	WindowHours int
+	Escalation  string // widen, halve or stop
+	Until       string
+}
+
+// StreamStaleMessageData is exposed to the stream_stale and stream_recovered templates
+type StreamStaleMessageData struct {
+	Stream     string // market | user stream
+	Age        string
+	Limit      string
+	OpenOrders int
+}
+
+// FiltersChangedMessageData is exposed to the filters_changed template
+type FiltersChangedMessageData struct {
+	Symbol  string
+	Changes []string // "tickSize 0.01 -> 0.1"
+}
+
+// BackupFailedMessageData is exposed to the backup_failed template
+type BackupFailedMessageData struct {
+	Target      string
+	Error       string
+	IntervalMin int
+}
+
+// LeaderTakeoverMessageData is exposed to the leader_takeover template
+type LeaderTakeoverMessageData struct {
+	ID   string // Instance that took over
+	Lock string
+}
+
+// StartupReportMessageData is exposed to the startup_report template
+type StartupReportMessageData struct {
+	Symbol          string
+	Quote           string
+	OpenOrders      int // On Binance when the sync started
+	Imported        int
+	Linked          int
+	FilledOffline   int
+	CanceledOffline int
+	Purged          int
+	Duplicates      int
+	ZombiesRescued  int
+	ZombiesCleaned  int
+	OpenBuys        int
+	OpenBuysUSDT    float64
+	WithExit        int
+	WithoutExit     int
+	InventoryQty    float64
+	InventoryCost   float64
+	FreeUSDT        float64 // Free quote asset
+	Paused          bool
+	DryRun          bool // Purges, duplicates and zombies are what the cleanup would do (SYNC_DRY_RUN)
+}
+
+// ExecutionReportMessageData is exposed to the execution_report template (slippage in bps,
+// positive = adverse)
+type ExecutionReportMessageData struct {
+	Day              string
+	Symbol           string
+	Fills            int
+	TakerFills       int
+	MakerRatioPct    float64
+	SlippageBps      float64
+	WorstSlippageBps float64
+	RegimeChanges    int     // Volatility regime changes of the day
+	HighVolPct       float64 // Share of the day in HIGH_VOL_CRASH (0-100)
+}
+
+// GoroutinePanicMessageData is exposed to the goroutine_panic template
+type GoroutinePanicMessageData struct {
+	Goroutine  string
+	Error      string
+	Suppressed int // Panics of the same goroutine not alerted since the last alert
+}
+
+// ShadowReportMessageData is exposed to the shadow_report template (realized PnL net of fees)
+type ShadowReportMessageData struct {
+	Day            string
+	Profile        string
+	LiveTrades     int
+	LiveNet        float64
+	ShadowTrades   int
+	ShadowBuys     int
+	ShadowNet      float64
+	Diff           float64
+	TotalLiveNet   float64
+	TotalShadowNet float64
+	Equity         float64
+	Capital        float64
+	OpenPositions  int
+	OpenBuys       int
+}
+
+// ProfileMessageData is exposed to the profile_auto_switched template
+type ProfileMessageData struct {
+	Profile  string
+	Previous string
+	Triggers int
+}
+
+// newTradeMessageData builds the COMPRA/VENDA template data shared by every channel
+func newTradeMessageData(tx model.Transaction, profit float64, closedOrders []model.Transaction, usdtBalance, bnbBalance, btcBalance float64) TradeMessageData {
+	amount, _ := strconv.ParseFloat(tx.Amount, 64)
+	price, _ := strconv.ParseFloat(tx.Price, 64)
+
+	data := TradeMessageData{
+		Symbol:      tx.Symbol,
+		ID:          tx.ID,
+		Status:      tx.StatusTransaction,
+		Qty:         amount,
+		Price:       price,
+		Total:       amount * price,
+		Profit:      profit,
+		BalanceUSDT: usdtBalance,
+		BalanceBNB:  bnbBalance,
+		BalanceBTC:  btcBalance,
+		Date:        time.Now().Format(messageDateFormat),
+	}
+	for _, order := range closedOrders {
+		data.ClosedOrders = append(data.ClosedOrders, order.ID)
+	}
+	return data
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"grid-trading-btc-binance/internal/config"
+	"grid-trading-btc-binance/internal/logger"
+)
+
+const (
+	sinkQueueSize   = 500
+	sinkMaxPending  = 5000
+	sinkBatchSize   = 500
+	sinkFlushPeriod = 10 * time.Second // Also the retry backoff after a failed write
+)
+
+// Point is a time-series sample (InfluxDB data model)
+type Point struct {
+	Measurement string
+	Tags        map[string]string
+	Fields      map[string]interface{} // float64, int, bool or string
+	Time        time.Time
+}
+
+// MetricsSink receives metric points. Write must never block the caller.
+type MetricsSink interface {
+	Write(points ...Point)
+}
+
+// NewMetricsSink builds the sink selected by METRICS_SINK (nil when disabled)
+func NewMetricsSink(cfg *config.Config) MetricsSink {
+	switch cfg.MetricsSink {
+	case "influxdb":
+		sink := NewInfluxSink(cfg.InfluxURL, cfg.InfluxToken, cfg.InfluxOrg, cfg.InfluxBucket)
+		sink.Start()
+		logger.Info("📈 Metrics sink enabled", "type", cfg.MetricsSink, "url", cfg.InfluxURL, "bucket", cfg.InfluxBucket)
+		return sink
+	}
+	return nil
+}
+
+// PointFromRecord turns a CSV record into a point: the typed (numeric and boolean) columns
+// become fields, the rest are dropped (tags are given explicitly)
+func PointFromRecord(measurement string, tags map[string]string, header []string, typed map[string]bool, record []string, at time.Time) Point {
+	fields := make(map[string]interface{}, len(record))
+	for i, value := range record {
+		if i >= len(header) {
+			break
+		}
+		switch v := jsonValue(typed, header[i], value).(type) {
+		case float64, bool:
+			fields[header[i]] = v
+		}
+	}
+	return Point{Measurement: measurement, Tags: tags, Fields: fields, Time: at}
+}
+
+// InfluxSink batches points and writes them with the InfluxDB v2 line protocol API
+// (POST /api/v2/write). Anything speaking that API works, e.g. a Telegraf
+// influxdb_v2_listener forwarding to TimescaleDB.
+type InfluxSink struct {
+	URL    string
+	Token  string
+	Org    string
+	Bucket string
+	Client *http.Client
+
+	queue   chan Point
+	pending []Point
+}
+
+func NewInfluxSink(baseURL, token, org, bucket string) *InfluxSink {
+	return &InfluxSink{
+		URL:    strings.TrimRight(baseURL, "/"),
+		Token:  token,
+		Org:    org,
+		Bucket: bucket,
+		Client: &http.Client{Timeout: 10 * time.Second},
+		queue:  make(chan Point, sinkQueueSize),
+	}
+}
+
+// Write queues the points without blocking (dropped with an error log if the queue is full)
+func (s *InfluxSink) Write(points ...Point) {
+	for _, p := range points {
+		select {
+		case s.queue <- p:
+		default:
+			logger.Error("🚨 Metrics sink queue full, dropping point", "measurement", p.Measurement)
+		}
+	}
+}
+
+// Start runs the batching loop in background
+func (s *InfluxSink) Start() {
+	go func() {
+		ticker := time.NewTicker(sinkFlushPeriod)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case p := <-s.queue:
+				s.pending = append(s.pending, p)
+				if len(s.pending) < sinkBatchSize {
+					continue
+				}
+			case <-ticker.C:
+				if len(s.pending) == 0 {
+					continue
+				}
+			}
+			s.flush()
+		}
+	}()
+}
+
+func (s *InfluxSink) flush() {
+	if over := len(s.pending) - sinkMaxPending; over > 0 {
+		logger.Error("🚨 Metrics sink buffer full, dropping oldest points", "dropped", over)
+		s.pending = s.pending[over:]
+	}
+
+	var body bytes.Buffer
+	for _, p := range s.pending {
+		if line := lineProtocol(p); line != "" {
+			body.WriteString(line)
+			body.WriteByte('\n')
+		}
+	}
+
+	if err := s.post(body.Bytes()); err != nil {
+		logger.Error("Failed to write metrics, will retry", "pending", len(s.pending), "error", err)
+		return
+	}
+	s.pending = nil
+}
+
+func (s *InfluxSink) post(body []byte) error {
+	q := url.Values{}
+	q.Add("org", s.Org)
+	q.Add("bucket", s.Bucket)
+	q.Add("precision", "s")
+
+	req, err := http.NewRequest("POST", s.URL+"/api/v2/write?"+q.Encode(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if s.Token != "" {
+		req.Header.Set("Authorization", "Token "+s.Token)
+	}
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("status %d: %s", resp.StatusCode, string(msg))
+	}
+	return nil
+}
+
+// lineProtocol encodes a point (second precision); points without fields are skipped
+func lineProtocol(p Point) string {
+	if len(p.Fields) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(escapeLP(p.Measurement, ", "))
+
+	tagKeys := make([]string, 0, len(p.Tags))
+	for k := range p.Tags {
+		tagKeys = append(tagKeys, k)
+	}
+	sort.Strings(tagKeys)
+	for _, k := range tagKeys {
+		if p.Tags[k] == "" {
+			continue
+		}
+		b.WriteString("," + escapeLP(k, ",= ") + "=" + escapeLP(p.Tags[k], ",= "))
+	}
+
+	fieldKeys := make([]string, 0, len(p.Fields))
+	for k := range p.Fields {
+		fieldKeys = append(fieldKeys, k)
+	}
+	sort.Strings(fieldKeys)
+	for i, k := range fieldKeys {
+		if i == 0 {
+			b.WriteByte(' ')
+		} else {
+			b.WriteByte(',')
+		}
+		b.WriteString(escapeLP(k, ",= ") + "=")
+		switch v := p.Fields[k].(type) {
+		case float64:
+			b.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+		case int:
+			b.WriteString(strconv.Itoa(v) + "i")
+		case int64:
+			b.WriteString(strconv.FormatInt(v, 10) + "i")
+		case bool:
+			b.WriteString(strconv.FormatBool(v))
+		default:
+			s := fmt.Sprint(v)
+			s = strings.ReplaceAll(s, `\`, `\\`)
+			s = strings.ReplaceAll(s, `"`, `\"`)
+			b.WriteString(`"` + s + `"`)
+		}
+	}
+
+	b.WriteString(" " + strconv.FormatInt(p.Time.Unix(), 10))
+	return b.String()
+}
+
+func escapeLP(s, chars string) string {
+	for _, c := range chars {
+		s = strings.ReplaceAll(s, string(c), `\`+string(c))
+	}
+	return s
+}
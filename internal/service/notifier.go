@@ -0,0 +1,205 @@
+package service
+
+import (
+	"time"
+
+	"grid-trading-btc-binance/internal/config"
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/model"
+)
+
+// Category groups notifications so users can silence noisy events (e.g. COMPRA fills)
+// while still receiving the ones they care about.
+type Category string
+
+const (
+	CategoryEntryFill      Category = "entry_fill"
+	CategoryExit           Category = "exit"
+	CategoryCircuitBreaker Category = "circuit_breaker"
+	CategoryLowBalance     Category = "low_balance"
+	CategorySync           Category = "sync"
+	CategoryError          Category = "error"
+	CategoryReport         Category = "report"      // Periodic statements (not toggleable)
+	CategoryPriceAlert     Category = "price_alert" // PRICE_ALERTS (enabled by configuring them)
+)
+
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return "info"
+	}
+}
+
+// ParseSeverity converts the NOTIFY_MIN_SEVERITY value into a Severity (defaults to info)
+func ParseSeverity(value string) Severity {
+	switch value {
+	case "warning":
+		return SeverityWarning
+	case "critical":
+		return SeverityCritical
+	default:
+		return SeverityInfo
+	}
+}
+
+// Message is a single alert delivered to a channel. Text is already rendered
+// (and escaped) for that channel's markup.
+type Message struct {
+	Category Category
+	Severity Severity
+	Text     string
+	Time     time.Time
+}
+
+// Notifier is a delivery channel (Telegram, Discord, Slack, generic webhook)
+type Notifier interface {
+	Name() string
+	Markup() Markup
+	Send(msg Message)
+}
+
+// NotificationService is the central entry point for every user-facing alert.
+// It applies the per-category toggles and minimum severity before fanning out to all channels.
+type NotificationService struct {
+	Cfg       *config.Config
+	Telegram  *TelegramService
+	Channels  []Notifier
+	Templates *TemplateSet
+}
+
+func NewNotificationService(cfg *config.Config, telegram *TelegramService) *NotificationService {
+	n := &NotificationService{
+		Cfg:       cfg,
+		Telegram:  telegram,
+		Templates: LoadTemplates(cfg.NotifyTemplatesDir),
+	}
+
+	if telegram != nil && telegram.Configured() {
+		n.Channels = append(n.Channels, telegram)
+	}
+	if cfg.DiscordWebhookURL != "" {
+		n.Channels = append(n.Channels, NewDiscordNotifier(cfg.DiscordWebhookURL))
+	}
+	if cfg.SlackWebhookURL != "" {
+		n.Channels = append(n.Channels, NewSlackNotifier(cfg.SlackWebhookURL))
+	}
+	if cfg.WebhookURL != "" {
+		n.Channels = append(n.Channels, NewWebhookNotifier(cfg.WebhookURL, cfg.WebhookToken))
+	}
+
+	if len(n.Channels) == 0 {
+		logger.Warn("No notification channels configured, alerts will only be logged")
+	} else {
+		var names []string
+		for _, ch := range n.Channels {
+			names = append(names, ch.Name())
+		}
+		logger.Info("🔔 Notification channels enabled", "channels", names)
+	}
+
+	return n
+}
+
+// Enabled reports whether a notification of the given category/severity should be delivered.
+// Critical alerts always go through, regardless of the category toggle.
+func (n *NotificationService) Enabled(category Category, severity Severity) bool {
+	if severity < ParseSeverity(n.Cfg.NotifyMinSeverity) {
+		return false
+	}
+	if severity == SeverityCritical {
+		return true
+	}
+
+	switch category {
+	case CategoryEntryFill:
+		return n.Cfg.NotifyEntryFills
+	case CategoryExit:
+		return n.Cfg.NotifyExits
+	case CategoryCircuitBreaker:
+		return n.Cfg.NotifyCircuitBreaker
+	case CategoryLowBalance:
+		return n.Cfg.NotifyLowBalance
+	case CategorySync:
+		return n.Cfg.NotifySyncEvents
+	case CategoryError:
+		return n.Cfg.NotifyErrors
+	}
+	return true
+}
+
+// Notify sends a free-form message if its category/severity is enabled.
+// The text is treated as plain text and escaped for every channel.
+func (n *NotificationService) Notify(category Category, severity Severity, text string) {
+	if !n.Enabled(category, severity) {
+		logger.Debug("🔕 Notification suppressed by preferences", "category", category, "severity", severity.String())
+		return
+	}
+	now := time.Now()
+	for _, ch := range n.Channels {
+		ch.Send(Message{Category: category, Severity: severity, Text: n.accountTag(ch.Markup()) + ch.Markup().Escape(text), Time: now})
+	}
+}
+
+// NotifyTemplate renders the named template for each channel and sends it if enabled
+func (n *NotificationService) NotifyTemplate(category Category, severity Severity, name string, data interface{}) {
+	if !n.Enabled(category, severity) {
+		logger.Debug("🔕 Notification suppressed by preferences", "category", category, "severity", severity.String(), "template", name)
+		return
+	}
+	now := time.Now()
+	for _, ch := range n.Channels {
+		text, err := n.Templates.Render(name, data, ch.Markup())
+		if err != nil {
+			logger.Error("Failed to render notification", "channel", ch.Name(), "template", name, "error", err)
+			continue
+		}
+		ch.Send(Message{Category: category, Severity: severity, Text: n.accountTag(ch.Markup()) + text, Time: now})
+	}
+}
+
+// accountTag heads the alerts with ACCOUNT_NAME, so that the accounts sharing a channel can be
+// told apart ("" when unset)
+func (n *NotificationService) accountTag(markup Markup) string {
+	if n.Cfg.AccountName == "" {
+		return ""
+	}
+	return markup.Static("🏷️ *") + markup.Escape(n.Cfg.AccountName) + markup.Static("*\n")
+}
+
+// NotifyTrade routes BUY fills to the entry category and SELL fills to the exit category
+func (n *NotificationService) NotifyTrade(tx model.Transaction, profit float64, closedOrders []model.Transaction, usdtBalance, bnbBalance, btcBalance float64) {
+	category, name := CategoryEntryFill, TemplateTradeBuy
+	if tx.Type == "sell" {
+		category, name = CategoryExit, TemplateTradeSell
+	}
+	data := newTradeMessageData(tx, profit, closedOrders, usdtBalance, bnbBalance, btcBalance)
+	data.Base, data.Quote = n.Cfg.BaseAsset, n.Cfg.QuoteAsset
+	n.NotifyTemplate(category, SeverityInfo, name, data)
+}
+
+// NotifyLowBalance sends the low quote asset/BNB balance alert
+func (n *NotificationService) NotifyLowBalance(currency string, currentBalance, required float64) {
+	name := TemplateLowBalanceBNB
+	if currency == n.Cfg.QuoteAsset {
+		name = TemplateLowBalanceUSDT
+	}
+	data := LowBalanceMessageData{
+		Currency: currency,
+		Balance:  currentBalance,
+		Required: required,
+		Date:     time.Now().Format(messageDateFormat),
+	}
+	n.NotifyTemplate(CategoryLowBalance, SeverityWarning, name, data)
+}
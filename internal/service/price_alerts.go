@@ -0,0 +1,147 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"grid-trading-btc-binance/internal/config"
+	"grid-trading-btc-binance/internal/logger"
+)
+
+const priceAlertSampleEvery = time.Second // Resolution of the prices kept for the move alerts
+
+type priceSample struct {
+	at    time.Time
+	price float64
+}
+
+// PriceAlertService checks PRICE_ALERTS on every ticker of the symbol and sends price_alert.
+// It only reads prices, so the alerts keep coming while the bot is paused or in monitor mode.
+// Every condition is edge triggered: it fires when it becomes true (never for the state found on
+// the first ticker), re-arms once it is false again and stays silent for
+// PRICE_ALERT_COOLDOWN_MIN after firing.
+type PriceAlertService struct {
+	Cfg      *config.Config
+	Notifier *NotificationService
+
+	samples   []priceSample // One price per second over the longest move window, oldest first
+	maxWindow time.Duration
+	armed     map[string]bool // Keyed by alert index and side
+	firedAt   map[string]time.Time
+}
+
+// NewPriceAlertService returns nil when PRICE_ALERTS is empty
+func NewPriceAlertService(cfg *config.Config, notifier *NotificationService) *PriceAlertService {
+	if len(cfg.PriceAlerts) == 0 {
+		return nil
+	}
+	s := &PriceAlertService{
+		Cfg:      cfg,
+		Notifier: notifier,
+		armed:    make(map[string]bool),
+		firedAt:  make(map[string]time.Time),
+	}
+	for _, a := range cfg.PriceAlerts {
+		s.maxWindow = max(s.maxWindow, a.Window)
+	}
+	logger.Info("🔔 Price alerts enabled", "alerts", len(cfg.PriceAlerts), "cooldown_min", cfg.PriceAlertCooldownMin)
+	return s
+}
+
+// Check evaluates every alert at price (called from the ticker loop, not concurrently)
+func (s *PriceAlertService) Check(price float64, now time.Time) {
+	if price <= 0 {
+		return
+	}
+	s.record(price, now)
+
+	for i, a := range s.Cfg.PriceAlerts {
+		data := PriceAlertMessageData{
+			Symbol:   s.Cfg.Symbol,
+			Kind:     a.Kind,
+			Alert:    a.Label,
+			Price:    price,
+			RangeMin: s.Cfg.RangeMin,
+			RangeMax: s.Cfg.RangeMax,
+		}
+		switch a.Kind {
+		case config.PriceAlertAbove:
+			data.Level = a.Price
+			s.edge(i, "", price >= a.Price, now, SeverityInfo, data)
+		case config.PriceAlertBelow:
+			data.Level = a.Price
+			s.edge(i, "", price <= a.Price, now, SeverityInfo, data)
+		case config.PriceAlertRange:
+			rangeMin, rangeMax := s.Cfg.RangeMin, s.Cfg.RangeMax
+			if rangeMin <= 0 || rangeMax <= rangeMin {
+				continue
+			}
+			low, high := data, data
+			low.Side, low.Level, low.Outside = "min", rangeMin, price < rangeMin
+			low.ChangePct = (price - rangeMin) / rangeMin * 100
+			s.edge(i, "min", price <= rangeMin*(1+a.Pct), now, SeverityWarning, low)
+			high.Side, high.Level, high.Outside = "max", rangeMax, price > rangeMax
+			high.ChangePct = (rangeMax - price) / rangeMax * 100
+			s.edge(i, "max", price >= rangeMax*(1-a.Pct), now, SeverityWarning, high)
+		case config.PriceAlertMove:
+			lowest, highest := s.extremes(now.Add(-a.Window))
+			data.Minutes = int(a.Window.Minutes())
+			up, down := data, data
+			up.Side, up.Level, up.ChangePct = "up", lowest, (price-lowest)/lowest*100
+			s.edge(i, "up", price >= lowest*(1+a.Pct), now, SeverityWarning, up)
+			down.Side, down.Level, down.ChangePct = "down", highest, (price-highest)/highest*100
+			s.edge(i, "down", price <= highest*(1-a.Pct), now, SeverityWarning, down)
+		}
+	}
+}
+
+// edge fires the alert when its condition turns true while armed and out of the cooldown
+func (s *PriceAlertService) edge(index int, side string, active bool, now time.Time, severity Severity, data PriceAlertMessageData) {
+	key := fmt.Sprintf("%d:%s", index, side)
+	armed, seen := s.armed[key]
+	if !seen || !active {
+		s.armed[key] = !active // First ticker: a condition already true waits for the next crossing
+		return
+	}
+	cooldown := time.Duration(s.Cfg.PriceAlertCooldownMin) * time.Minute
+	if !armed || now.Sub(s.firedAt[key]) < cooldown {
+		return
+	}
+	s.armed[key] = false
+	s.firedAt[key] = now
+
+	logger.Warn("🔔 Price alert", "alert", data.Alert, "side", data.Side, "price", data.Price, "level", data.Level, "change_pct", data.ChangePct)
+	s.Notifier.NotifyTemplate(CategoryPriceAlert, severity, TemplatePriceAlert, data)
+}
+
+// record keeps one price per second, dropping the ones older than the longest move window
+func (s *PriceAlertService) record(price float64, now time.Time) {
+	if s.maxWindow == 0 {
+		return
+	}
+	if n := len(s.samples); n > 0 && now.Sub(s.samples[n-1].at) < priceAlertSampleEvery {
+		s.samples[n-1].price = price
+	} else {
+		s.samples = append(s.samples, priceSample{at: now, price: price})
+	}
+	drop := 0
+	for drop < len(s.samples) && now.Sub(s.samples[drop].at) > s.maxWindow {
+		drop++
+	}
+	s.samples = s.samples[drop:]
+}
+
+// extremes returns the lowest and highest price recorded since
+func (s *PriceAlertService) extremes(since time.Time) (float64, float64) {
+	lowest, highest := 0.0, 0.0
+	for _, sample := range s.samples {
+		if sample.at.Before(since) {
+			continue
+		}
+		if lowest == 0 || sample.price < lowest {
+			lowest = sample.price
+		}
+		highest = max(highest, sample.price)
+	}
+	return lowest, highest
+}
@@ -0,0 +1,264 @@
+package service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"grid-trading-btc-binance/internal/crash"
+	"grid-trading-btc-binance/internal/logger"
+)
+
+const (
+	recordQueueSize   = 100
+	recordMaxPending  = 1000             // Oldest records are dropped beyond this while the disk keeps failing
+	recordRetryPeriod = 30 * time.Second // Backoff between write attempts after a failure
+	recordStopTimeout = 5 * time.Second  // Wait for the final write on shutdown
+)
+
+var (
+	writersMu sync.Mutex
+	writers   []*RecordWriter // Started writers, flushed by StopRecordWriters
+)
+
+// RecordWriter appends CSV records (and optionally the same records as JSON Lines) from a
+// background goroutine, so the bot loop never blocks on disk I/O. Records that fail to be
+// written stay buffered and are retried. An existing CSV whose header differs from Header
+// (columns added by an upgrade) is rotated aside before the first write.
+type RecordWriter struct {
+	CSVPath  string
+	JSONPath string // Empty = CSV only
+	Header   []string
+	Typed    map[string]bool // Columns written to JSON as numbers/booleans, the rest stay strings
+
+	queue         chan []string
+	stop          chan chan struct{}
+	pending       [][]string
+	headerChecked bool
+}
+
+func NewRecordWriter(csvPath, jsonPath string, header []string, typed map[string]bool) *RecordWriter {
+	return &RecordWriter{
+		CSVPath:  csvPath,
+		JSONPath: jsonPath,
+		Header:   header,
+		Typed:    typed,
+		queue:    make(chan []string, recordQueueSize),
+		stop:     make(chan chan struct{}),
+	}
+}
+
+// Write queues a record without blocking (dropped with an error log if the queue is full)
+func (w *RecordWriter) Write(record []string) {
+	select {
+	case w.queue <- record:
+	default:
+		logger.Error("🚨 Record writer queue full, dropping record", "file", w.CSVPath)
+	}
+}
+
+// Start runs the writer loop in background
+func (w *RecordWriter) Start() {
+	writersMu.Lock()
+	writers = append(writers, w)
+	writersMu.Unlock()
+
+	crash.Go("record writer "+w.CSVPath, func() {
+		retry := time.NewTicker(recordRetryPeriod)
+		defer retry.Stop()
+
+		for {
+			select {
+			case record := <-w.queue:
+				w.pending = append(w.pending, record)
+				w.drain()
+			case <-retry.C:
+				if len(w.pending) == 0 {
+					continue
+				}
+			case done := <-w.stop:
+				w.drain()
+				if len(w.pending) > 0 {
+					w.flush()
+				}
+				close(done)
+				return
+			}
+			w.flush()
+		}
+	})
+}
+
+// drain takes whatever else is already queued so it goes in the same write
+func (w *RecordWriter) drain() {
+	for {
+		select {
+		case r := <-w.queue:
+			w.pending = append(w.pending, r)
+		default:
+			return
+		}
+	}
+}
+
+// Stop writes the queued and pending records and ends the writer loop (gives up after
+// recordStopTimeout, e.g. while the disk keeps failing)
+func (w *RecordWriter) Stop() {
+	done := make(chan struct{})
+	select {
+	case w.stop <- done:
+	case <-time.After(recordStopTimeout):
+		logger.Error("🚨 Record writer did not stop, records may be lost", "file", w.CSVPath)
+		return
+	}
+	select {
+	case <-done:
+	case <-time.After(recordStopTimeout):
+		logger.Error("🚨 Record writer did not finish its last write", "file", w.CSVPath)
+	}
+}
+
+// StopRecordWriters stops every started writer, writing what they still hold (shutdown path)
+func StopRecordWriters() {
+	writersMu.Lock()
+	started := writers
+	writers = nil
+	writersMu.Unlock()
+
+	for _, w := range started {
+		w.Stop()
+	}
+}
+
+func (w *RecordWriter) flush() {
+	if over := len(w.pending) - recordMaxPending; over > 0 {
+		logger.Error("🚨 Record writer buffer full, dropping oldest records", "file", w.CSVPath, "dropped", over)
+		w.pending = w.pending[over:]
+	}
+
+	if err := w.appendCSV(w.pending); err != nil {
+		logger.Error("Failed to write CSV records, will retry", "file", w.CSVPath, "pending", len(w.pending), "error", err)
+		return
+	}
+	if w.JSONPath != "" {
+		// Best effort: the CSV is the source of truth, a JSON failure does not hold it back
+		if err := w.appendJSON(w.pending); err != nil {
+			logger.Error("Failed to write JSON records", "file", w.JSONPath, "error", err)
+		}
+	}
+	w.pending = nil
+}
+
+func (w *RecordWriter) appendCSV(records [][]string) error {
+	if err := os.MkdirAll(filepath.Dir(w.CSVPath), 0755); err != nil {
+		return err
+	}
+	if !w.headerChecked {
+		if err := w.rotateStaleHeader(); err != nil {
+			return err
+		}
+		w.headerChecked = true
+	}
+	_, statErr := os.Stat(w.CSVPath)
+	fileExists := statErr == nil
+
+	f, err := os.OpenFile(w.CSVPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	cw := csv.NewWriter(f)
+	if !fileExists {
+		cw.Write(w.Header)
+	}
+	cw.WriteAll(records) // Flushes
+	if err := cw.Error(); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// rotateStaleHeader renames an existing CSV whose header is not Header to
+// <name>.<YYYYMMDD-HHMMSS>.csv, so rows of a new layout never land under an old header
+func (w *RecordWriter) rotateStaleHeader() error {
+	f, err := os.Open(w.CSVPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	header, err := csv.NewReader(f).Read()
+	f.Close()
+	if err != nil || slices.Equal(header, w.Header) {
+		return nil // Empty or unreadable header: the rows are appended as before
+	}
+
+	ext := filepath.Ext(w.CSVPath)
+	rotated := strings.TrimSuffix(w.CSVPath, ext) + "." + time.Now().Format("20060102-150405") + ext
+	if err := os.Rename(w.CSVPath, rotated); err != nil {
+		return fmt.Errorf("failed to rotate CSV with an outdated header: %w", err)
+	}
+	logger.Warn("🗂️ CSV header changed, previous file rotated", "file", w.CSVPath, "rotated_to", rotated, "old_columns", len(header), "new_columns", len(w.Header))
+	return nil
+}
+
+// appendJSON writes each record as one JSON object keyed by the CSV header
+func (w *RecordWriter) appendJSON(records [][]string) error {
+	if err := os.MkdirAll(filepath.Dir(w.JSONPath), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(w.JSONPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	for _, record := range records {
+		if len(record) != len(w.Header) {
+			return fmt.Errorf("record has %d fields, header has %d", len(record), len(w.Header))
+		}
+		obj := make(map[string]interface{}, len(record))
+		for i, value := range record {
+			obj[w.Header[i]] = jsonValue(w.Typed, w.Header[i], value)
+		}
+		if err := enc.Encode(obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jsonValue types the CSV strings of the typed columns so downstream tools get numbers and
+// booleans. Other columns (ids, names) stay strings, as do non-finite values, which JSON can't hold.
+func jsonValue(typed map[string]bool, column, value string) interface{} {
+	if !typed[column] {
+		return value
+	}
+	if value == "true" || value == "false" {
+		return value == "true"
+	}
+	if f, err := strconv.ParseFloat(value, 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+		return f
+	}
+	return value
+}
+
+// columnSet builds the typed column set of a RecordWriter
+func columnSet(columns ...string) map[string]bool {
+	set := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		set[column] = true
+	}
+	return set
+}
@@ -2,19 +2,37 @@ package service
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
 
 	"grid-trading-btc-binance/internal/api"
+	"grid-trading-btc-binance/internal/crash"
 	"grid-trading-btc-binance/internal/logger"
 )
 
 const (
 	StreamBaseURL = "wss://stream.binance.com:9443/ws"
+
+	listenKeyKeepAlive = 30 * time.Minute // Keys expire after 60 min without a keepalive
+	streamRotateAfter  = 23 * time.Hour   // Binance closes user stream connections at 24h
+	streamRotateRetry  = 1 * time.Minute
+	pongWriteWait      = 10 * time.Second
+
+	updatesQueueSize      = 500
+	updatesHighWaterRatio = 0.8              // Warn when the queue is this full
+	overflowDebounce      = 30 * time.Second // At most one OnOverflow call per window
 )
 
+var errListenKeyExpired = errors.New("listenKey expired")
+
 // OrderUpdate represents the payload from executionReport event
 type OrderUpdate struct {
 	Event         string `json:"e"` // Event type
@@ -42,129 +60,321 @@ type OrderUpdate struct {
 	TxTime        int64  `json:"T"` // Transaction time
 	TradeID       int64  `json:"t"` // Trade ID
 	Ignore        int64  `json:"I"` // Ignore
-	IsWorking     bool   `json:"w"` // Is the order on the book?
-	IsMaker       bool   `json:"m"` // Is this trade the maker side?
-	OrderCreation int64  `json:"O"` // Order creation time
-	CumQuoteQty   string `json:"Z"` // Cumulative quote asset transacted quantity
-	LastQuoteQty  string `json:"Y"` // Last quote asset transacted quantity (e.g. USDT)
-	QuoteOrderQty string `json:"Q"` // Quote Order Qty
-	WorkingTime   int64  `json:"W"` // Working Time
-	SelfTradePrev string `json:"V"` // SelfTradePreventionMode
+
+	ReceivedAt    time.Time `json:"-"` // When the bot read the frame (latency metrics)
+	IsWorking     bool      `json:"w"` // Is the order on the book?
+	IsMaker       bool      `json:"m"` // Is this trade the maker side?
+	OrderCreation int64     `json:"O"` // Order creation time
+	CumQuoteQty   string    `json:"Z"` // Cumulative quote asset transacted quantity
+	LastQuoteQty  string    `json:"Y"` // Last quote asset transacted quantity (e.g. USDT)
+	QuoteOrderQty string    `json:"Q"` // Quote Order Qty
+	WorkingTime   int64     `json:"W"` // Working Time
+	SelfTradePrev string    `json:"V"` // SelfTradePreventionMode
+}
+
+// AccountPosition is the outboundAccountPosition event (balances that changed)
+type AccountPosition struct {
+	Event      string `json:"e"`
+	EventTime  int64  `json:"E"`
+	LastUpdate int64  `json:"u"`
+	Balances   []struct {
+		Asset  string `json:"a"`
+		Free   string `json:"f"`
+		Locked string `json:"l"`
+	} `json:"B"`
 }
 
 type StreamService struct {
-	Binance     *api.BinanceClient
-	ListenKey   string
-	WSConn      *websocket.Conn
-	Updates     chan OrderUpdate
-	StopCh      chan struct{}
-	IsConnected bool
+	Binance   *api.BinanceClient
+	ListenKey string
+	WSConn    *websocket.Conn
+	Updates   chan OrderUpdate
+	StopCh    chan struct{}
+	BaseURL   string            // WebSocket base (BINANCE_STREAM_URL, StreamBaseURL by default)
+	Dialer    *websocket.Dialer // Proxy of the connection (see SetProxy)
+
+	// OnReconnect runs (in background) every time the stream connects after a drop,
+	// to catch up on events missed during the gap
+	OnReconnect   func()
+	connectedOnce bool
+
+	// OnAccountPosition receives balance changes pushed by the stream
+	OnAccountPosition func(AccountPosition)
+
+	// OnOverflow runs (in background, debounced) when updates had to be dropped because
+	// the queue was full. Dropped events must be recovered from REST (e.g. a resync).
+	OnOverflow   func(dropped uint64)
+	lastOverflow time.Time
+
+	received  atomic.Uint64
+	dropped   atomic.Uint64
+	highWater atomic.Int64
+
+	connMu    sync.Mutex // Guards WSConn between rotations and Reconnect
+	connected atomic.Bool
+	lastEvent atomic.Int64 // Unix ms of the last message or ping (health endpoints)
+}
+
+// StreamStats are the counters of the Updates queue
+type StreamStats struct {
+	Received  uint64
+	Dropped   uint64
+	Queued    int
+	Capacity  int
+	HighWater int
 }
 
 func NewStreamService(binance *api.BinanceClient) *StreamService {
 	return &StreamService{
 		Binance: binance,
-		Updates: make(chan OrderUpdate, 100),
+		Updates: make(chan OrderUpdate, updatesQueueSize),
+		BaseURL: StreamBaseURL,
+		Dialer:  websocket.DefaultDialer,
 		// StopCh initialized in Start()
 	}
 }
 
-func (s *StreamService) Start() error {
-	// 1. Get Listen Key
-	key, err := s.Binance.StartUserStream()
-	if err != nil {
-		return fmt.Errorf("failed to get listen key: %w", err)
+// SetProxy dials the stream through proxy (http:// or socks5://, credentials in the URL)
+func (s *StreamService) SetProxy(proxy *url.URL) {
+	dialer := *websocket.DefaultDialer
+	dialer.Proxy = http.ProxyURL(proxy)
+	s.Dialer = &dialer
+}
+
+// Stats returns a snapshot of the Updates queue metrics
+func (s *StreamService) Stats() StreamStats {
+	return StreamStats{
+		Received:  s.received.Load(),
+		Dropped:   s.dropped.Load(),
+		Queued:    len(s.Updates),
+		Capacity:  cap(s.Updates),
+		HighWater: int(s.highWater.Load()),
 	}
-	s.ListenKey = key
-	logger.Info("🔑 ListenKey acquired", "key", key)
+}
 
-	// 2. Connect to WebSocket
-	url := fmt.Sprintf("%s/%s", StreamBaseURL, s.ListenKey)
-	c, _, err := websocket.DefaultDialer.Dial(url, nil)
-	if err != nil {
-		return fmt.Errorf("failed to connect to websocket: %w", err)
+// Connected reports whether the user stream WebSocket is up
+func (s *StreamService) Connected() bool {
+	return s.connected.Load()
+}
+
+// LastEventAt returns when the stream last showed life: a message, a server ping or the
+// connection itself (zero before the first connection)
+func (s *StreamService) LastEventAt() time.Time {
+	if ms := s.lastEvent.Load(); ms > 0 {
+		return time.UnixMilli(ms)
 	}
-	s.WSConn = c
-	s.IsConnected = true
-	logger.Info("📡 WebSocket Connected to Binance User Stream")
+	return time.Time{}
+}
 
-	// 3. Start KeepAlive Loop (30m)
-	s.StopCh = make(chan struct{}) // Reset stop channel for new connection
-	go s.keepAliveLoop()
+func (s *StreamService) touch() {
+	s.lastEvent.Store(time.Now().UnixMilli())
+}
 
-	// 4. Start Reading Loop (Blocking)
-	// This will block until connection is closed or Stop() is called
-	s.readLoop()
+// enqueue never blocks the read loop (it must keep answering pings). When the queue is
+// full the update is dropped and OnOverflow is triggered so it can be recovered via REST.
+func (s *StreamService) enqueue(event OrderUpdate) {
+	s.received.Add(1)
 
-	return nil
+	select {
+	case s.Updates <- event:
+		depth := int64(len(s.Updates))
+		if prev := s.highWater.Load(); depth > prev && s.highWater.CompareAndSwap(prev, depth) &&
+			float64(depth) >= float64(cap(s.Updates))*updatesHighWaterRatio {
+			logger.Warn("⚠️ Order updates queue is saturating", "queued", depth, "capacity", cap(s.Updates))
+		}
+	default:
+		total := s.dropped.Add(1)
+		logger.Error("🚨 Order updates queue full, dropping update", "id", event.ClientOrderID, "status", event.Status, "dropped_total", total)
+		if s.OnOverflow != nil && time.Since(s.lastOverflow) > overflowDebounce {
+			s.lastOverflow = time.Now()
+			crash.Go("updates overflow", func() { s.OnOverflow(total) })
+		}
+	}
+}
+
+// streamSession is one WebSocket connection bound to a listenKey
+type streamSession struct {
+	listenKey string
+	conn      *websocket.Conn
+	done      chan error // Why the read loop ended (buffered, read at most once)
 }
 
-func (s *StreamService) keepAliveLoop() {
-	ticker := time.NewTicker(30 * time.Minute)
-	defer ticker.Stop()
+// Start connects the user stream and blocks until it must be rebuilt (connection lost,
+// listenKey expired) or Stop is called. The connection is rotated proactively before
+// Binance's 24h limit: the new one is opened before the old one is closed, so no event
+// is lost (the overlap is dropped by the consumer's dedup). Updates is never recreated,
+// so events already queued survive any rebuild.
+func (s *StreamService) Start() error {
+	session, err := s.openSession()
+	if err != nil {
+		return err
+	}
+	s.activate(session)
+	logger.Info("📡 WebSocket Connected to Binance User Stream")
+
+	if s.connectedOnce && s.OnReconnect != nil {
+		logger.Info("🔁 Stream reconnected. Resyncing events missed during the gap...")
+		crash.Go("stream resync", s.OnReconnect)
+	}
+	s.connectedOnce = true
+
+	s.StopCh = make(chan struct{}) // Reset stop channel for new connection
+	keepAlive := time.NewTicker(listenKeyKeepAlive)
+	defer keepAlive.Stop()
+	rotate := time.NewTimer(streamRotateAfter)
+	defer rotate.Stop()
+
+	defer func() {
+		s.connected.Store(false)
+		logger.Warn("🔌 WebSocket Connection Closed")
+	}()
 
 	for {
 		select {
 		case <-s.StopCh:
-			return
-		case <-ticker.C:
-			if err := s.Binance.KeepAliveUserStream(s.ListenKey); err != nil {
+			return nil
+
+		case err := <-session.done:
+			session.conn.Close()
+			if errors.Is(err, errListenKeyExpired) {
+				logger.Warn("⌛ ListenKey expired (server event). Rebuilding stream with a new key...")
+			} else {
+				logger.Error("❌ WebSocket Read Error", "error", err)
+			}
+			return nil
+
+		case <-keepAlive.C:
+			if err := s.Binance.KeepAliveUserStream(session.listenKey); err != nil {
+				if isListenKeyGone(err) {
+					logger.Warn("⌛ ListenKey no longer valid (keepalive rejected). Rebuilding stream...", "error", err)
+					session.conn.Close()
+					return nil
+				}
 				logger.Error("❌ Failed to keep alive listen key", "error", err)
 			} else {
 				logger.Debug("💓 ListenKey KeepAlive sent")
 			}
+			stats := s.Stats()
+			logger.Info("📊 Order updates queue", "received", stats.Received, "dropped", stats.Dropped, "queued", stats.Queued, "high_water", stats.HighWater, "capacity", stats.Capacity)
+
+		case <-rotate.C:
+			next, err := s.openSession()
+			if err != nil {
+				logger.Warn("⚠️ Proactive stream rotation failed. Retrying...", "retry_in", streamRotateRetry, "error", err)
+				rotate.Reset(streamRotateRetry)
+				continue
+			}
+			old := session
+			session = next
+			s.activate(session)
+			old.conn.Close() // Its read loop ends on its own done channel, which is no longer watched
+			if old.listenKey != next.listenKey {
+				_ = s.Binance.CloseUserStream(old.listenKey)
+			}
+			rotate.Reset(streamRotateAfter)
+			logger.Info("🔄 User stream rotated before the 24h connection limit")
 		}
 	}
 }
 
-func (s *StreamService) readLoop() {
-	defer func() {
-		if s.WSConn != nil {
-			s.WSConn.Close()
+// openSession acquires a listenKey (Binance returns the active one, extended, if it still exists),
+// dials the stream and starts reading it
+func (s *StreamService) openSession() (*streamSession, error) {
+	key, err := s.Binance.StartUserStream()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get listen key: %w", err)
+	}
+	logger.Info("🔑 ListenKey acquired", "key", key)
+
+	c, _, err := s.Dialer.Dial(fmt.Sprintf("%s/%s", s.BaseURL, key), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to websocket: %w", err)
+	}
+
+	// Binance pings idle connections: answer like the default handler, and count it as life
+	c.SetPingHandler(func(data string) error {
+		s.touch()
+		err := c.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(pongWriteWait))
+		if errors.Is(err, websocket.ErrCloseSent) {
+			return nil
 		}
-		s.IsConnected = false
-		logger.Warn("🔌 WebSocket Connection Closed")
-	}()
+		return err
+	})
+
+	session := &streamSession{listenKey: key, conn: c, done: make(chan error, 1)}
+	crash.Go("user stream reader", func() { s.readLoop(session) })
+	return session, nil
+}
+
+// Reconnect closes the current connection: Start returns and the caller's loop rebuilds
+// the stream (missed events are recovered by OnReconnect)
+func (s *StreamService) Reconnect() {
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+	if s.WSConn != nil && s.connected.Load() {
+		logger.Warn("🔌 Forcing user stream reconnection")
+		s.WSConn.Close()
+	}
+}
 
+func (s *StreamService) activate(session *streamSession) {
+	s.connMu.Lock()
+	s.ListenKey = session.listenKey
+	s.WSConn = session.conn
+	s.connMu.Unlock()
+	s.connected.Store(true)
+	s.touch()
+}
+
+func (s *StreamService) readLoop(session *streamSession) {
 	for {
-		select {
-		case <-s.StopCh:
+		_, message, err := session.conn.ReadMessage()
+		if err != nil {
+			session.done <- err
 			return
-		default:
-			_, message, err := s.WSConn.ReadMessage()
-			if err != nil {
-				logger.Error("❌ WebSocket Read Error", "error", err)
-				// Reconnection logic could go here. For now, we return (stop).
-				// Simple production bot might panic/exit to let supervisor restart, or retry.
-				// Let's implement a simple retry or just exit loop.
-				// For this task, we log and exit, Main will assume connection is critical.
-				return
-			}
+		}
+		s.touch()
+
+		// Parse generic to check event type first? Or just try unmarshal.
+		// The stream sends different events (outboundAccountPosition, executionReport).
+		// We care about executionReport.
+
+		// Optimistic unmarshal into OrderUpdate
+		// This works because OrderUpdate contains the 'e' (Event) field.
+		// Extra fields in other event types (like 'B' in outboundAccountPosition) will just be ignored
+		// comfortably without error, as long as common fields (like 'e', 'E') have compatible types.
+		var event OrderUpdate
+		if err := json.Unmarshal(message, &event); err != nil {
+			logger.Error("❌ Failed to parse WebSocket message", "error", err, "msg", string(message))
+			continue
+		}
 
-			// Parse generic to check event type first? Or just try unmarshal.
-			// The stream sends different events (outboundAccountPosition, executionReport).
-			// We care about executionReport.
-
-			// Optimistic unmarshal into OrderUpdate
-			// This works because OrderUpdate contains the 'e' (Event) field.
-			// Extra fields in other event types (like 'B' in outboundAccountPosition) will just be ignored
-			// comfortably without error, as long as common fields (like 'e', 'E') have compatible types.
-			var event OrderUpdate
-			if err := json.Unmarshal(message, &event); err != nil {
-				logger.Error("❌ Failed to parse WebSocket message", "error", err, "msg", string(message))
+		switch event.Event {
+		case "executionReport":
+			event.ReceivedAt = time.Now()
+			s.enqueue(event)
+		case "listenKeyExpired":
+			session.done <- errListenKeyExpired
+			return
+		case "outboundAccountPosition":
+			if s.OnAccountPosition == nil {
 				continue
 			}
-
-			if event.Event == "executionReport" {
-				s.Updates <- event
-			} else if event.Event == "outboundAccountPosition" {
-				// Handle balance updates if we wanted real-time balance
-				// logger.Debug("Balance Update Streamed")
+			var position AccountPosition
+			if err := json.Unmarshal(message, &position); err != nil {
+				logger.Error("❌ Failed to parse account position", "error", err)
+				continue
 			}
+			s.OnAccountPosition(position)
 		}
 	}
 }
 
+// isListenKeyGone reports whether Binance rejected the key as nonexistent/expired (-1125)
+func isListenKeyGone(err error) bool {
+	return strings.Contains(err.Error(), "-1125")
+}
+
 func (s *StreamService) Stop() error {
 	logger.Info("🛑 Stopping Stream Service...")
 	close(s.StopCh)
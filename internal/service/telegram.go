@@ -4,163 +4,226 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
-	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"grid-trading-btc-binance/internal/config"
+	"grid-trading-btc-binance/internal/crash"
 	"grid-trading-btc-binance/internal/logger"
-	"grid-trading-btc-binance/internal/model"
+)
+
+const (
+	telegramQueueSize      = 200
+	telegramMaxLength      = 4096            // Telegram hard limit per message
+	telegramBatchWindow    = 1 * time.Second // Messages generated within this window are merged
+	telegramMinInterval    = 1 * time.Second // ~1 msg/s per chat keeps us below Telegram flood limits
+	telegramMaxAttempts    = 5
+	telegramInitialBackoff = 1 * time.Second
 )
 
 type TelegramService struct {
 	Cfg *config.Config
+
+	queue     chan string
+	startOnce sync.Once
+	client    *http.Client
+	commands  map[string]CommandHandler
 }
 
 func NewTelegramService(cfg *config.Config) *TelegramService {
 	return &TelegramService{
-		Cfg: cfg,
+		Cfg:      cfg,
+		queue:    make(chan string, telegramQueueSize),
+		client:   &http.Client{Timeout: 10 * time.Second},
+		commands: make(map[string]CommandHandler),
 	}
 }
 
+// Configured reports whether the bot token and chat id are set
+func (s *TelegramService) Configured() bool {
+	return s.Cfg.TelegramToken != "" && s.Cfg.TelegramChatID != ""
+}
+
+func (s *TelegramService) Name() string {
+	return "telegram"
+}
+
+func (s *TelegramService) Markup() Markup {
+	return telegramMarkup{}
+}
+
+// Send implements Notifier
+func (s *TelegramService) Send(msg Message) {
+	s.SendMessage(msg.Text)
+}
+
+// SendMessage enqueues the message for the background sender. It never blocks the caller:
+// if the queue is saturated the message is dropped and logged.
 func (s *TelegramService) SendMessage(text string) {
-	if s.Cfg.TelegramToken == "" || s.Cfg.TelegramChatID == "" {
+	if !s.Configured() {
 		logger.Warn("Telegram credentials not set, skipping message")
 		return
 	}
 
-	url := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", s.Cfg.TelegramToken)
-	payload := map[string]string{
-		"chat_id":    s.Cfg.TelegramChatID,
-		"text":       text,
-		"parse_mode": "Markdown",
+	s.startOnce.Do(func() {
+		crash.Go("telegram sender", s.sendLoop)
+	})
+
+	select {
+	case s.queue <- text:
+	default:
+		logger.Error("🚨 Telegram queue full, dropping message", "queue_size", telegramQueueSize, "text", text)
 	}
+}
 
-	jsonPayload, err := json.Marshal(payload)
-	if err != nil {
-		logger.Error("Failed to marshal Telegram payload", "error", err)
-		return
+// sendLoop drains the queue, batching bursts and respecting the per-chat rate limit
+func (s *TelegramService) sendLoop() {
+	var lastSent time.Time
+
+	for first := range s.queue {
+		batch := []string{first}
+
+		// Batch: collect everything generated within the same window (e.g. sync bursts)
+		timer := time.NewTimer(telegramBatchWindow)
+	collect:
+		for {
+			select {
+			case next := <-s.queue:
+				batch = append(batch, next)
+			case <-timer.C:
+				break collect
+			}
+		}
+
+		for _, text := range mergeMessages(batch, telegramMaxLength) {
+			if wait := telegramMinInterval - time.Since(lastSent); wait > 0 {
+				time.Sleep(wait)
+			}
+			s.deliver(text)
+			lastSent = time.Now()
+		}
 	}
+}
 
-	// Send async
-	go func() {
-		resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonPayload))
+// mergeMessages joins messages with a blank line, splitting into chunks under maxLen
+func mergeMessages(batch []string, maxLen int) []string {
+	var chunks []string
+	var current string
+
+	for _, text := range batch {
+		if len(text) > maxLen {
+			text = truncateUTF8(text, maxLen-3) + "…" // Not a MarkdownV2 reserved char, unlike "."
+		}
+		if current == "" {
+			current = text
+			continue
+		}
+		if len(current)+2+len(text) > maxLen {
+			chunks = append(chunks, current)
+			current = text
+			continue
+		}
+		current += "\n\n" + text
+	}
+	if current != "" {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
+
+type telegramResponse struct {
+	OK          bool   `json:"ok"`
+	Description string `json:"description"`
+	Parameters  struct {
+		RetryAfter int `json:"retry_after"`
+	} `json:"parameters"`
+}
+
+// deliver sends a single message with retries, exponential backoff and 429 handling.
+// Messages are sent as MarkdownV2; if Telegram rejects the markup (e.g. a broken custom
+// template) the message is re-sent once as plain text instead of being lost.
+func (s *TelegramService) deliver(text string) {
+	url := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", s.Cfg.TelegramToken)
+	parseMode := "MarkdownV2"
+
+	backoff := telegramInitialBackoff
+	for attempt := 1; attempt <= telegramMaxAttempts; attempt++ {
+		payload := map[string]string{
+			"chat_id": s.Cfg.TelegramChatID,
+			"text":    text,
+		}
+		if parseMode != "" {
+			payload["parse_mode"] = parseMode
+		}
+
+		jsonPayload, err := json.Marshal(payload)
 		if err != nil {
-			logger.Error("Failed to send Telegram message", "error", err)
+			logger.Error("Failed to marshal Telegram payload", "error", err)
 			return
 		}
-		defer resp.Body.Close()
 
-		if resp.StatusCode != http.StatusOK {
-			logger.Error("Telegram API error", "status", resp.Status)
+		resp, err := s.client.Post(url, "application/json", bytes.NewBuffer(jsonPayload))
+		if err != nil {
+			logger.Warn("⚠️ Failed to send Telegram message. Retrying...", "attempt", attempt, "error", err)
+			time.Sleep(backoff)
+			backoff *= 2
+			continue
 		}
-	}()
-}
 
-func (s *TelegramService) SendTradeNotification(tx model.Transaction, profit float64, closedOrders []model.Transaction, usdtBalance, bnbBalance, btcBalance float64) {
-	var msg string
-	now := time.Now().Format("02/01/2006, 15:04:05")
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
 
-	amount, _ := strconv.ParseFloat(tx.Amount, 64)
-	price, _ := strconv.ParseFloat(tx.Price, 64)
-	total := amount * price
+		if resp.StatusCode == http.StatusOK {
+			return
+		}
 
-	// Escape IDs for Markdown
-	escapedTxID := s.escapeMarkdown(tx.ID)
+		var tgResp telegramResponse
+		_ = json.Unmarshal(body, &tgResp)
 
-	if tx.Type == "sell" {
-		// VENDA (Taker Profit)
-		var closedOrdersMsg string
-		if len(closedOrders) > 0 {
-			closedOrdersMsg = "\nOrdens Buy:"
-			for _, order := range closedOrders {
-				closedOrdersMsg += fmt.Sprintf("\n- %s", s.escapeMarkdown(order.ID))
+		switch {
+		case resp.StatusCode == http.StatusTooManyRequests:
+			wait := time.Duration(tgResp.Parameters.RetryAfter) * time.Second
+			if wait <= 0 {
+				wait = backoff
 			}
+			logger.Warn("⏳ Telegram rate limit hit (429). Waiting before retry...", "attempt", attempt, "retry_after", wait)
+			time.Sleep(wait)
+		case resp.StatusCode >= 500:
+			logger.Warn("⚠️ Telegram server error. Retrying...", "attempt", attempt, "status", resp.Status)
+			time.Sleep(backoff)
+			backoff *= 2
+		case resp.StatusCode == http.StatusBadRequest && parseMode != "" && strings.Contains(tgResp.Description, "can't parse entities"):
+			logger.Warn("⚠️ Telegram rejected MarkdownV2, re-sending as plain text", "description", tgResp.Description, "text", truncateForLog(text))
+			parseMode = ""
+		default:
+			// 4xx (other than 429) will not succeed on retry (bad token, chat not found, ...)
+			logger.Error("Telegram API error", "status", resp.Status, "description", tgResp.Description, "text", truncateForLog(text))
+			return
 		}
-
-		msg = fmt.Sprintf(
-			"🤖 Grid Trading - %s - Binance\n"+
-				"🆔 ID: %s\n"+
-				"📊 Status: %s\n"+
-				"🟢 Lado: VENDA\n"+
-				"📦 Qtd: %.6f\n"+
-				"💲 Preço: $%.2f\n"+
-				"💵 Total: $%.2f\n"+
-				"💰 LUCRO (Realizado): $%.4f\n"+
-				"%s\n\n"+
-				"💰 Saldo USDT: $%.2f\n"+
-				"💰 Saldo BNB: %.4f\n"+
-				"📅 Data: %s",
-			tx.Symbol,
-			escapedTxID,
-			s.escapeMarkdown(tx.StatusTransaction),
-			amount,
-			price,
-			total,
-			profit,
-			closedOrdersMsg,
-			usdtBalance,
-			bnbBalance,
-			now,
-		)
-	} else {
-		// COMPRA (Maker Fill)
-		msg = fmt.Sprintf(
-			"🤖 Grid Trading - %s - Binance\n"+
-				"🆔 ID: %s\n"+
-				"📊 Status: %s\n"+
-				"🟢 Lado: COMPRA\n"+
-				"📦 Qtd: %.6f\n"+
-				"💲 Preço: $%.2f\n"+
-				"💵 Total: $%.2f\n\n"+
-				"💰 Saldo BTC: %.6f\n"+
-				"💰 Saldo USDT: $%.2f\n"+
-				"📅 Data: %s",
-			tx.Symbol,
-			escapedTxID,
-			s.escapeMarkdown(tx.StatusTransaction),
-			amount,
-			price,
-			total,
-			btcBalance,
-			usdtBalance,
-			now,
-		)
-
 	}
 
-	s.SendMessage(msg)
+	logger.Error("❌ Telegram message dropped after retries", "attempts", telegramMaxAttempts, "text", truncateForLog(text))
 }
 
-func (s *TelegramService) SendLowBalanceAlert(currency string, currentBalance, required float64) {
-	now := time.Now().Format("02/01/2006, 15:04:05")
-	var msg string
-
-	if currency == "USDT" {
-		msg = fmt.Sprintf(
-			"⚠️ *ALERTA: Saldo USDT Baixo*\n\n"+
-				"💰 Saldo Atual: $%.2f\n"+
-				"📉 Necessário: $%.2f\n"+
-				"⚠️ O bot não conseguiu posicionar novas ordens de compra.\n\n"+
-				"📅 %s",
-			currentBalance, required, now,
-		)
-	} else {
-		msg = fmt.Sprintf(
-			"⚠️ *ALERTA: Saldo BNB Baixo*\n\n"+
-				"💰 Saldo BNB: %.4f\n"+
-				"📉 Limite Aproximado: %.4f\n"+
-				"⚠️ O saldo BNB está baixo para taxas (menos de 5%% do valor da ordem). Considere recarregar.\n\n"+
-				"📅 %s",
-			currentBalance, required, now,
-		)
+func truncateForLog(text string) string {
+	text = strings.ReplaceAll(text, "\n", " | ")
+	if len(text) > 200 {
+		return truncateUTF8(text, 200) + "..."
 	}
-	s.SendMessage(msg)
+	return text
 }
 
-func (s *TelegramService) escapeMarkdown(text string) string {
-	// Replace _ with \_ to prevent Markdown parsing errors
-	// In Go strings.ReplaceAll, backslash needs to be escaped too
-	return strings.ReplaceAll(text, "_", "\\_")
+// truncateUTF8 cuts text to at most n bytes without splitting a multi-byte rune (emojis)
+func truncateUTF8(text string, n int) string {
+	if len(text) <= n {
+		return text
+	}
+	for n > 0 && !utf8.RuneStart(text[n]) {
+		n--
+	}
+	return text[:n]
 }
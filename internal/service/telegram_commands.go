@@ -0,0 +1,157 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"grid-trading-btc-binance/internal/crash"
+	"grid-trading-btc-binance/internal/logger"
+)
+
+const telegramPollTimeout = 30 // seconds (long polling)
+
+// CommandHandler handles a Telegram command and returns the plain-text reply
+type CommandHandler func(args []string) string
+
+type telegramUpdate struct {
+	UpdateID int64 `json:"update_id"`
+	Message  *struct {
+		Chat struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+		Text string `json:"text"`
+	} `json:"message"`
+}
+
+type telegramUpdatesResponse struct {
+	OK          bool             `json:"ok"`
+	Description string           `json:"description"`
+	Result      []telegramUpdate `json:"result"`
+}
+
+// RegisterCommand adds a handler for "/name". Must be called before StartCommandListener.
+func (s *TelegramService) RegisterCommand(name string, handler CommandHandler) {
+	s.commands[strings.ToLower(name)] = handler
+}
+
+// StartCommandListener polls getUpdates in the background and dispatches commands
+// sent from the configured chat. Messages from any other chat are ignored.
+func (s *TelegramService) StartCommandListener() {
+	if !s.Configured() {
+		logger.Warn("Telegram credentials not set, commands disabled")
+		return
+	}
+	if len(s.commands) == 0 {
+		return
+	}
+
+	var names []string
+	for name := range s.commands {
+		names = append(names, "/"+name)
+	}
+	sort.Strings(names)
+	logger.Info("🤖 Telegram command listener started", "commands", names)
+
+	crash.Go("telegram commands", s.pollLoop)
+}
+
+func (s *TelegramService) pollLoop() {
+	pollClient := &http.Client{Timeout: (telegramPollTimeout + 10) * time.Second}
+
+	// Skip the backlog: a "/panic confirm" sent before a restart must never execute
+	offset := int64(-1)
+	if updates, err := s.getUpdates(pollClient, offset, 0); err == nil && len(updates) > 0 {
+		offset = updates[len(updates)-1].UpdateID + 1
+	} else {
+		offset = 0
+	}
+
+	for {
+		updates, err := s.getUpdates(pollClient, offset, telegramPollTimeout)
+		if err != nil {
+			logger.Warn("⚠️ Telegram getUpdates failed. Retrying in 5s...", "error", err)
+			time.Sleep(5 * time.Second)
+			continue
+		}
+
+		for _, u := range updates {
+			offset = u.UpdateID + 1
+			if u.Message == nil {
+				continue
+			}
+			if strconv.FormatInt(u.Message.Chat.ID, 10) != s.Cfg.TelegramChatID {
+				logger.Warn("🚫 Ignoring Telegram message from unauthorized chat", "chat_id", u.Message.Chat.ID)
+				continue
+			}
+			s.handleCommand(u.Message.Text)
+		}
+	}
+}
+
+func (s *TelegramService) getUpdates(client *http.Client, offset int64, timeout int) ([]telegramUpdate, error) {
+	params := url.Values{}
+	params.Add("offset", strconv.FormatInt(offset, 10))
+	params.Add("timeout", strconv.Itoa(timeout))
+	params.Add("allowed_updates", `["message"]`)
+	reqURL := fmt.Sprintf("https://api.telegram.org/bot%s/getUpdates?%s", s.Cfg.TelegramToken, params.Encode())
+
+	resp, err := client.Get(reqURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var result telegramUpdatesResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal error: %w", err)
+	}
+	if !result.OK {
+		// 409 Conflict: another poller or a webhook is active for this bot token
+		if resp.StatusCode == http.StatusConflict {
+			time.Sleep(30 * time.Second)
+		}
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, result.Description)
+	}
+	return result.Result, nil
+}
+
+func (s *TelegramService) handleCommand(text string) {
+	fields := strings.Fields(strings.TrimSpace(text))
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return
+	}
+
+	// "/panic@MyBot confirm" -> "panic", ["confirm"]
+	name := strings.ToLower(strings.TrimPrefix(fields[0], "/"))
+	if i := strings.Index(name, "@"); i >= 0 {
+		name = name[:i]
+	}
+
+	handler, ok := s.commands[name]
+	if !ok {
+		s.Reply(fmt.Sprintf("Comando desconhecido: /%s", name))
+		return
+	}
+
+	logger.Info("🤖 Telegram command received", "command", name, "args", fields[1:])
+	if reply := handler(fields[1:]); reply != "" {
+		s.Reply(reply)
+	}
+}
+
+// Reply sends a plain-text message (escaped for MarkdownV2)
+func (s *TelegramService) Reply(text string) {
+	s.SendMessage(telegramMarkup{}.Escape(text))
+}
@@ -0,0 +1,531 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+	"text/template/parse"
+
+	"grid-trading-btc-binance/internal/logger"
+)
+
+// Template names. Users can override any of them by dropping a "<name>.tmpl"
+// file in NOTIFY_TEMPLATES_DIR (e.g. to translate messages or change branding).
+const (
+	TemplateTradeBuy                 = "trade_buy"
+	TemplateTradeSell                = "trade_sell"
+	TemplateLowBalanceUSDT           = "low_balance_usdt"
+	TemplateLowBalanceBNB            = "low_balance_bnb"
+	TemplateBNBTopUp                 = "bnb_topup"
+	TemplateVaultStatement           = "vault_statement"
+	TemplatePanicExecuted            = "panic_executed"
+	TemplateExitFailed               = "exit_failed"
+	TemplateCircuitBreakerTriggered  = "circuit_breaker_triggered"
+	TemplateCircuitBreakerNormalized = "circuit_breaker_normalized"
+	TemplateUpdatesOverflow          = "updates_overflow"
+	TemplateProfileAutoSwitched      = "profile_auto_switched"
+	TemplateShadowReport             = "shadow_report"
+	TemplateStreamStale              = "stream_stale"
+	TemplateStreamRecovered          = "stream_recovered"
+	TemplateGoroutinePanic           = "goroutine_panic"
+	TemplateFiltersChanged           = "filters_changed"
+	TemplateBackupFailed             = "backup_failed"
+	TemplateLeaderTakeover           = "leader_takeover"
+	TemplateStartupReport            = "startup_report"
+	TemplateExecutionReport          = "execution_report"
+	TemplateExitRecovered            = "exit_recovered"
+	TemplateExitEscalated            = "exit_escalated"
+	TemplateExitCanceled             = "exit_canceled"
+	TemplateExposureNaked            = "exposure_naked"
+	TemplateExposureRestored         = "exposure_restored"
+	TemplateDrawdownStop             = "drawdown_stop"
+	TemplateCircuitBreakerEscalated  = "circuit_breaker_escalated"
+	TemplateCircuitBreakerRelaxed    = "circuit_breaker_relaxed"
+	TemplateHedgeOpened              = "hedge_opened"
+	TemplateHedgeClosed              = "hedge_closed"
+	TemplateMarginLevelLow           = "margin_level_low"
+	TemplateMarginLevelRestored      = "margin_level_restored"
+	TemplateEndpointSwitched         = "endpoint_switched"
+	TemplateRateLimited              = "rate_limited"
+	TemplateClockDrift               = "clock_drift"
+	TemplateRangeStale               = "range_stale"
+	TemplatePriceAlert               = "price_alert"
+	TemplateTradingViewSignal        = "tradingview_signal"
+)
+
+// Templates are written in a minimal markup: *bold*, _italic_ and `code`.
+// Literal text and every {{action}} output are escaped automatically for each channel,
+// so prices like 87000.50 or IDs like G1_B_L3_dm6bvms7uym8 never break formatting.
+var defaultTemplates = map[string]string{
+	TemplateTradeBuy: `🤖 Grid Trading - {{.Symbol}} - Binance
+🆔 ID: {{.ID}}
+📊 Status: {{.Status}}
+🟢 Lado: COMPRA
+📦 Qtd: {{printf "%.6f" .Qty}}
+💲 Preço: ${{printf "%.2f" .Price}}
+💵 Total: ${{printf "%.2f" .Total}}
+
+💰 Saldo {{.Base}}: {{printf "%.6f" .BalanceBTC}}
+💰 Saldo {{.Quote}}: ${{printf "%.2f" .BalanceUSDT}}
+📅 Data: {{.Date}}`,
+
+	TemplateTradeSell: `🤖 Grid Trading - {{.Symbol}} - Binance
+🆔 ID: {{.ID}}
+📊 Status: {{.Status}}
+🟢 Lado: VENDA
+📦 Qtd: {{printf "%.6f" .Qty}}
+💲 Preço: ${{printf "%.2f" .Price}}
+💵 Total: ${{printf "%.2f" .Total}}
+💰 LUCRO (Realizado): ${{printf "%.4f" .Profit}}
+{{- if .ClosedOrders}}
+Ordens Buy:
+{{- range .ClosedOrders}}
+- {{.}}
+{{- end}}
+{{- end}}
+
+💰 Saldo {{.Quote}}: ${{printf "%.2f" .BalanceUSDT}}
+💰 Saldo BNB: {{printf "%.4f" .BalanceBNB}}
+📅 Data: {{.Date}}`,
+
+	TemplateLowBalanceUSDT: `⚠️ *ALERTA: Saldo {{.Currency}} Baixo*
+
+💰 Saldo Atual: ${{printf "%.2f" .Balance}}
+📉 Necessário: ${{printf "%.2f" .Required}}
+⚠️ O bot não conseguiu posicionar novas ordens de compra.
+
+📅 {{.Date}}`,
+
+	TemplateLowBalanceBNB: `⚠️ *ALERTA: Saldo BNB Baixo*
+
+💰 Saldo BNB: {{printf "%.4f" .Balance}}
+📉 Limite Aproximado: {{printf "%.4f" .Required}}
+⚠️ O saldo BNB está baixo para taxas (menos de 5% do valor da ordem). Considere recarregar.
+
+📅 {{.Date}}`,
+
+	TemplateBNBTopUp: `✅ *Recarga Automática de BNB*
+
+💵 Gasto: ${{printf "%.2f" .SpentUSDT}}
+🪙 Comprado: {{printf "%.4f" .BoughtBNB}} BNB
+💲 Preço Médio: ${{printf "%.2f" .AvgPrice}}
+💰 Saldo BNB: {{printf "%.4f" .BalanceBNB}}
+📊 Recargas Hoje: {{.CountToday}}/{{.MaxPerDay}}
+
+📅 {{.Date}}`,
+
+	TemplateVaultStatement: `🏦 *Extrato Mensal do Cofre - {{.Month}}*
+
+📊 Trades: {{.Trades}}
+💰 Lucro Realizado: ${{printf "%.2f" .RealizedProfit}}
+🏦 Separado ({{printf "%.0f" .SkimPct}}%): ${{printf "%.2f" .Skimmed}}
+💸 Transferido: ${{printf "%.2f" .Transferred}}
+
+📦 Total no Cofre: ${{printf "%.2f" .TotalSkimmed}}
+⏳ Pendente (Spot): ${{printf "%.2f" .Pending}}
+⚙️ Modo: {{.Mode}}`,
+
+	TemplatePanicExecuted: `🚨 *PANIC EXECUTADO*
+
+Motivo: {{.Reason}}
+🧾 Ordens canceladas: {{.CanceledOrders}}{{if .FailedCancels}} (falhas: {{.FailedCancels}}){{end}}
+📦 Vendido: {{printf "%.5f" .SoldQty}} {{.Base}}
+💲 Preço Médio: ${{printf "%.2f" .AvgPrice}}
+💵 Recebido: ${{printf "%.2f" .Proceeds}}
+💰 Resultado: ${{printf "%.2f" .RealizedPnL}}
+🗄️ Transações arquivadas: {{.Archived}}{{if .Kept}}
+⚠️ Posições mantidas ativas: {{.Kept}} (não vendidas ou saída ainda no book){{end}}
+
+⏸️ *Bot pausado.* Use /resume para retomar.`,
+
+	TemplateExitFailed: `🚨 *CRITICAL*: Failed to place Maker Exit for Order {{.ID}}.{{if .RetryMin}}
+🔁 Nova tentativa automática a cada {{.RetryMin}} min até {{.Deadline}}.{{else}} Please check manually!{{end}}`,
+
+	TemplateCircuitBreakerTriggered: `⚠️ *ALERTA: Circuit Breaker Ativado!* ⚠️
+
+Queda detectada: {{printf "%.2f" .DropPct}}%
+Preço Atual: {{printf "%.2f" .Price}}
+Max (15m): {{printf "%.2f" .MaxHigh}}
+
+⛔ *Compras Pausadas por {{.PauseMin}} min.*`,
+
+	TemplateCircuitBreakerNormalized: `✅ *Circuit Breaker Normalizado*
+Volatilidade controlada. Retomando operações.`,
+
+	TemplateCircuitBreakerEscalated: `🧯 *Circuit Breaker Escalado*
+
+Disparou {{.Trips}}x nas últimas {{.WindowHours}} h.
+{{if eq .Escalation "widen"}}📏 Espaçamento do grid dobrado até {{.Until}} (renovado a cada novo disparo).{{else if eq .Escalation "halve"}}📦 Tamanho da posição reduzido à metade até {{.Until}} (renovado a cada novo disparo).{{else}}⏸️ *Bot pausado.* Use /resume para retomar.{{end}}`,
+
+	TemplateCircuitBreakerRelaxed: `✅ *Escalonamento do Circuit Breaker Encerrado*
+{{.WindowHours}} h sem disparos: {{if eq .Escalation "widen"}}espaçamento{{else}}tamanho da posição{{end}} de volta ao perfil ativo.`,
+
+	TemplateUpdatesOverflow: `🚨 *Fila de Eventos Saturada*
+
+Eventos descartados: {{.Dropped}} (capacidade: {{.Capacity}})
+🔁 Ressincronizando ordens via REST.`,
+
+	TemplateProfileAutoSwitched: `🎛️ *Perfil Alterado: {{.Profile}}*
+
+Circuit breaker ativado {{.Triggers}}x hoje (perfil anterior: {{.Previous}}).
+Use /profile para ver ou trocar o perfil.`,
+
+	TemplateStreamStale: `🚨 *Dados Parados: {{.Stream}}*
+
+Sem eventos há {{.Age}} (limite: {{.Limit}}).
+Ordens abertas: {{.OpenOrders}}
+🔁 Forçando reconexão dos streams.`,
+
+	TemplateStreamRecovered: `✅ *Dados Normalizados: {{.Stream}}*
+Eventos voltaram a chegar após {{.Age}} parado.`,
+
+	TemplateGoroutinePanic: `💥 *Panic Recuperado: {{.Goroutine}}*
+
+Erro: {{.Error}}{{if .Suppressed}}
+(+{{.Suppressed}} ocorrências nos últimos minutos){{end}}
+🔁 A rotina foi reiniciada. Stack trace em logs/app.log.`,
+
+	TemplateFiltersChanged: `⚠️ *Filtros da Binance Alterados: {{.Symbol}}*
+{{range .Changes}}
+• {{.}}{{end}}
+
+✅ As próximas ordens já usam os novos valores.`,
+
+	TemplateBackupFailed: `☁️ *Falha no Backup Remoto*
+
+Destino: {{.Target}}
+Erro: {{.Error}}
+🔁 Nova tentativa a cada {{.IntervalMin}} min. Os arquivos locais não foram afetados.`,
+
+	TemplateLeaderTakeover: `👑 *Instância Standby Assumiu*
+
+O líder parou de renovar o lock e esta instância ({{.ID}}) passou a operar.
+🔒 Lock: {{.Lock}}
+🔄 O sync de startup reconcilia as ordens abertas na Binance.`,
+
+	TemplateStartupReport: `📋 *Sync de Startup - {{.Symbol}}*
+📖 Ordens abertas na Binance: {{.OpenOrders}}
+
+👻 Importadas: {{.Imported}}
+🔗 Saídas religadas: {{.Linked}}
+✅ Executadas offline: {{.FilledOffline}}
+❌ Canceladas offline: {{.CanceledOffline}}
+📦 Fantasmas arquivadas: {{.Purged}}
+👯 Duplicadas removidas: {{.Duplicates}}
+🧟 Zumbis: {{.ZombiesRescued}} com nova saída, {{.ZombiesCleaned}} arquivadas
+
+📊 *Exposição*
+🟢 Compras abertas: {{.OpenBuys}} (${{printf "%.2f" .OpenBuysUSDT}})
+📦 Posições: {{.WithExit}} com saída, {{.WithoutExit}} sem saída
+🪙 Inventário: {{printf "%.6f" .InventoryQty}} (custo ${{printf "%.2f" .InventoryCost}})
+💵 {{.Quote}} livre: ${{printf "%.2f" .FreeUSDT}}{{if .DryRun}}
+
+🔍 *Limpeza só simulada*: fantasmas, duplicadas e zumbis acima não foram tocados. Revise com /cleanup e execute com /cleanup confirm.{{end}}{{if .Paused}}
+
+⏸️ *Bot pausado.* Use /resume para retomar.{{end}}`,
+
+	TemplateShadowReport: `🧪 *Shadow vs Live - {{.Day}}*
+Perfil shadow: {{.Profile}}
+
+🤖 Live: {{.LiveTrades}} trades, ${{printf "%.2f" .LiveNet}}
+🧪 Shadow: {{.ShadowTrades}} trades ({{.ShadowBuys}} compras), ${{printf "%.2f" .ShadowNet}}
+📊 Diferença (shadow - live): ${{printf "%.2f" .Diff}}
+
+📈 Acumulado: live ${{printf "%.2f" .TotalLiveNet}} / shadow ${{printf "%.2f" .TotalShadowNet}}
+💼 Shadow: equity ${{printf "%.2f" .Equity}} (capital ${{printf "%.2f" .Capital}}), {{.OpenPositions}} posições, {{.OpenBuys}} compras abertas`,
+
+	TemplateExecutionReport: `🎯 *Qualidade de Execução - {{.Day}}*
+{{.Symbol}}: {{.Fills}} execuções
+
+🏷️ Maker: {{printf "%.1f" .MakerRatioPct}}% ({{.TakerFills}} taker)
+📉 Slippage médio: {{printf "%.2f" .SlippageBps}} bps
+⚠️ Pior execução: {{printf "%.2f" .WorstSlippageBps}} bps
+🌪️ Regime: {{.RegimeChanges}} troca(s), {{printf "%.0f" .HighVolPct}}% do dia em alta volatilidade`,
+
+	TemplateExitRecovered: `✅ *Saída Recuperada*
+Ordem {{.ID}}: saída colocada após {{.Attempts}} tentativa(s) automática(s), {{.Elapsed}} sem saída.`,
+
+	TemplateExitEscalated: `🚨 *Recuperação da Saída Esgotada*
+
+Ordem {{.ID}} continua sem saída após {{.Attempts}} tentativa(s) em {{.Elapsed}}.
+📦 Qtd: {{.Amount}} @ ${{.Price}}
+✋ Verifique manualmente: o próximo sync arquiva a transação.`,
+
+	TemplateExitCanceled: `⚠️ *Saída Cancelada Fora do Bot*
+Ordem {{.ID}}: a saída {{.SellOrderID}} ficou {{.Status}}.{{if .Sold}}
+📦 Vendido antes do cancelamento: {{.Sold}}{{end}}
+🔁 Colocando uma nova saída.`,
+
+	TemplateExposureNaked: `🚨 *Posições Sem Saída*
+{{.Count}} compra(s) preenchida(s) sem saída na Binance há mais de {{.Limit}}:
+{{range .Positions}}
+• {{.}}{{end}}
+
+🔧 Recolocando as saídas automaticamente.`,
+
+	TemplateExposureRestored: `✅ *Exposição Normalizada*
+As compras preenchidas voltaram a ter saída na Binance.{{if .Failed}}
+⚠️ {{.Failed}} em failed placement, com a recuperação de saídas.{{end}}`,
+
+	TemplateDrawdownStop: `🩸 *STOP POR DRAWDOWN*
+
+📉 Resultado não realizado: ${{printf "%.2f" .UnrealizedPnL}} ({{printf "%.2f" .PnLPct}}% de ${{printf "%.2f" .CostBasis}})
+{{if .Pending}}⏳ Venda de {{printf "%.0f" .SellPct}}% do inventário aguardando confirmação: use /drawdown confirm.{{else if .SoldQty}}📦 Vendido: {{printf "%.5f" .SoldQty}} {{.Base}} ({{.Positions}} posições)
+💲 Preço Médio: ${{printf "%.2f" .AvgPrice}}
+💰 Resultado: ${{printf "%.2f" .RealizedPnL}}{{else if .Error}}❌ Venda falhou: {{.Error}}{{else}}📦 Nenhuma venda (somente pausa).{{end}}
+
+⏸️ *Grid pausado.* /resume liberado a partir de {{.CooloffUntil}}.`,
+
+	TemplateHedgeOpened: `🛡️ *Hedge Aberto*
+
+📉 Preço abaixo da média de {{.TrendHours}} h (${{printf "%.2f" .MovingAverage}}) com ${{printf "%.2f" .InventoryValue}} em inventário.
+📦 Short: {{printf "%.3f" .Qty}} {{.Symbol}} a ${{printf "%.2f" .Price}}`,
+
+	TemplateHedgeClosed: `🛡️ *Hedge Fechado*
+
+{{if eq .Reason "trend"}}📈 Preço voltou acima da média de {{.TrendHours}} h (${{printf "%.2f" .MovingAverage}}).{{else}}📦 Inventário do grid caiu para ${{printf "%.2f" .InventoryValue}}.{{end}}
+📦 Short recomprado: {{printf "%.3f" .Qty}} {{.Symbol}} a ${{printf "%.2f" .Price}}
+💰 Resultado (com taxas e funding): ${{printf "%.2f" .PnL}}
+📊 Acumulado dos hedges: ${{printf "%.2f" .TotalPnL}}`,
+
+	TemplateMarginLevelLow: `{{if eq .Level "critical"}}🚨 *Nível de Margem Crítico*{{else}}⚠️ *Nível de Margem Baixo*{{end}}
+
+📉 Nível de margem: {{printf "%.2f" .MarginLevel}} (alerta {{printf "%.2f" .Warn}}, crítico {{printf "%.2f" .Critical}})
+💳 Dívida: {{printf "%.2f" .QuoteDebt}} {{.Quote}}{{if .BaseDebt}} + {{printf "%.6f" .BaseDebt}} {{.Base}}{{end}}{{if .LiquidatePrice}}
+💀 Preço de liquidação: ${{printf "%.2f" .LiquidatePrice}}{{end}}
+{{if .Repaid}}🔧 Pagos {{printf "%.2f" .Repaid}} {{.Quote}} da dívida com o saldo livre.
+{{end}}⏸️ Novos empréstimos suspensos. Perto de 1.1 a Binance liquida a conta: reduza a posição ou deposite colateral.`,
+
+	TemplateMarginLevelRestored: `✅ *Nível de Margem Normalizado*
+📈 Nível de margem: {{printf "%.2f" .MarginLevel}} (alerta {{printf "%.2f" .Warn}})`,
+	TemplateEndpointSwitched: `{{if .FailBack}}✅ *Endpoint da API Restaurado*
+🌐 De volta ao principal: {{.To}}{{else}}🔀 *Failover do Endpoint da API*
+🌐 {{.From}} → {{.To}}
+📋 Motivo: {{.Reason}}{{end}}`,
+
+	TemplateRateLimited: `{{if .Lifted}}✅ *Limite da API Normalizado*
+🔁 Chamadas REST retomadas (proteção desde {{.Since}}).{{else}}{{if .Ban}}🚨 *IP Banido pela Binance (HTTP 418)*{{else}}⚠️ *Limite de Requisições da Binance (HTTP 429)*{{end}}
+📡 Chamada: {{.Path}}
+⏳ Nenhuma chamada REST até {{.Until}}; depois só ordens e cancelamentos até {{.ProtectedUntil}}.
+🔌 Preços e execuções seguem pelos WebSockets.{{if .Ban}}
+🛑 Insistir durante o banimento o estende por dias: não reinicie o bot nem use esta chave/IP em outros scripts.{{end}}{{end}}`,
+
+	TemplateClockDrift: `{{if .Error}}🚨 *Relógio Desalinhado com a Binance*{{else}}⏰ *Relógio Ressincronizado*{{end}}
+⏱️ Desvio do relógio local: {{.Drift}} (limite {{.Max}})
+{{if .Error}}❌ Falha ao ressincronizar: {{.Error}}
+⚠️ Ordens assinadas podem ser rejeitadas (-1021). Verifique o NTP do servidor.{{else}}✅ Offset corrigido de {{.OldOffsetMs}} ms para {{.NewOffsetMs}} ms. Se repetir, verifique o NTP do servidor.{{end}}`,
+
+	TemplateRangeStale: `📐 *Range Desatualizado - {{.Symbol}}*
+{{if eq .Reason "outside"}}💲 Preço ${{printf "%.2f" .Price}} fora do range.{{else if eq .Reason "uncovered"}}📉 O range cobre só {{printf "%.0f" .CoveragePct}}% da faixa sugerida.{{else}}📏 O range é {{printf "%.1f" .WidthRatio}}x mais largo que a faixa sugerida: os níveis ficam espaçados demais.{{end}}
+
+📐 Atual: ${{printf "%.2f" .RangeMin}} - ${{printf "%.2f" .RangeMax}}
+💡 Sugerido: ${{printf "%.2f" .SuggestedMin}} - ${{printf "%.2f" .SuggestedMax}} ({{.Days}} dias, média ± {{printf "%.1f" .StdDevs}}σ, ATR ${{printf "%.2f" .ATR}})
+{{if .Applied}}✅ Range sugerido aplicado ({{.Canceled}} ordens fora do range canceladas).{{else if .Error}}❌ Falha ao aplicar: {{.Error}}{{else}}Use /range {{printf "%.2f" .SuggestedMin}} {{printf "%.2f" .SuggestedMax}} para aplicar.{{end}}`,
+
+	TemplatePriceAlert: `🔔 *Alerta de Preço - {{.Symbol}}*
+{{if eq .Kind "above"}}📈 O preço cruzou para cima de ${{printf "%.2f" .Level}}: ${{printf "%.2f" .Price}}.{{else if eq .Kind "below"}}📉 O preço cruzou para baixo de ${{printf "%.2f" .Level}}: ${{printf "%.2f" .Price}}.{{else if eq .Kind "move"}}⚡ Movimento de {{printf "%+.2f" .ChangePct}}% em até {{.Minutes}} min: de ${{printf "%.2f" .Level}} para ${{printf "%.2f" .Price}}.{{else if .Outside}}🚪 O preço ${{printf "%.2f" .Price}} saiu do range pelo limite {{if eq .Side "min"}}inferior{{else}}superior{{end}} (${{printf "%.2f" .Level}}).{{else}}📐 O preço ${{printf "%.2f" .Price}} está a {{printf "%.2f" .ChangePct}}% do limite {{if eq .Side "min"}}inferior{{else}}superior{{end}} do range (${{printf "%.2f" .Level}}).{{end}}
+📐 Range: ${{printf "%.2f" .RangeMin}} - ${{printf "%.2f" .RangeMax}}
+🏷️ Alerta: {{.Alert}}`,
+
+	TemplateTradingViewSignal: `📡 *Sinal TradingView - {{.Symbol}}*
+{{if .Error}}❌ Ação {{.Action}} recusada: {{.Error}}{{else}}✅ {{.Result}}{{end}}{{if .Reason}}
+📝 Motivo: {{.Reason}}{{end}}`,
+}
+
+// Markup describes how a channel renders template output.
+// Escape is applied to dynamic values, Static to the literal template text
+// (which may contain the *bold*/_italic_ markers).
+type Markup interface {
+	Name() string
+	Escape(text string) string
+	Static(text string) string
+}
+
+// TemplateSet holds the parsed templates, pre-compiled for every markup in use
+type TemplateSet struct {
+	mu       sync.Mutex
+	sources  map[string]string
+	compiled map[string]map[string]*template.Template // markup -> name -> template
+}
+
+// LoadTemplates parses the built-in templates and applies overrides from dir (optional)
+func LoadTemplates(dir string) *TemplateSet {
+	ts := &TemplateSet{
+		sources:  make(map[string]string),
+		compiled: make(map[string]map[string]*template.Template),
+	}
+	for name, src := range defaultTemplates {
+		ts.sources[name] = src
+	}
+
+	if dir == "" {
+		return ts
+	}
+
+	for name := range defaultTemplates {
+		path := filepath.Join(dir, name+".tmpl")
+		content, err := os.ReadFile(path)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				logger.Error("Failed to read notification template, using default", "path", path, "error", err)
+			}
+			continue
+		}
+		// Validate before accepting the override
+		if _, err := template.New(name).Funcs(templateFuncs(plainMarkup{})).Parse(string(content)); err != nil {
+			logger.Error("Invalid notification template, using default", "path", path, "error", err)
+			continue
+		}
+		ts.sources[name] = string(content)
+		logger.Info("📝 Custom notification template loaded", "name", name, "path", path)
+	}
+	return ts
+}
+
+// Render executes the named template for the given markup
+func (ts *TemplateSet) Render(name string, data interface{}, markup Markup) (string, error) {
+	tmpl, err := ts.get(name, markup)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render template %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+func (ts *TemplateSet) get(name string, markup Markup) (*template.Template, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	byName, ok := ts.compiled[markup.Name()]
+	if !ok {
+		byName = make(map[string]*template.Template)
+		ts.compiled[markup.Name()] = byName
+	}
+	if tmpl, ok := byName[name]; ok {
+		return tmpl, nil
+	}
+
+	src, ok := ts.sources[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown template: %s", name)
+	}
+	tmpl, err := template.New(name).Funcs(templateFuncs(markup)).Parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+	escapeTree(tmpl.Tree.Root, markup)
+	byName[name] = tmpl
+	return tmpl, nil
+}
+
+func templateFuncs(markup Markup) template.FuncMap {
+	return template.FuncMap{
+		"esc": func(v interface{}) string {
+			return markup.Escape(fmt.Sprint(v))
+		},
+	}
+}
+
+// escapeTree rewrites the parse tree: literal text goes through markup.Static and every
+// printing action gets an implicit "| esc" appended (same idea as html/template).
+func escapeTree(node parse.Node, markup Markup) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			escapeTree(child, markup)
+		}
+	case *parse.TextNode:
+		n.Text = []byte(markup.Static(string(n.Text)))
+	case *parse.ActionNode:
+		if len(n.Pipe.Decl) > 0 {
+			return // Variable declaration, prints nothing
+		}
+		n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{
+			NodeType: parse.NodeCommand,
+			Pos:      n.Pos,
+			Args:     []parse.Node{parse.NewIdentifier("esc").SetPos(n.Pos)},
+		})
+	case *parse.IfNode:
+		escapeTree(n.List, markup)
+		escapeTree(n.ElseList, markup)
+	case *parse.RangeNode:
+		escapeTree(n.List, markup)
+		escapeTree(n.ElseList, markup)
+	case *parse.WithNode:
+		escapeTree(n.List, markup)
+		escapeTree(n.ElseList, markup)
+	}
+}
+
+// telegramMarkup renders Telegram MarkdownV2
+type telegramMarkup struct{}
+
+const markdownV2Reserved = "_*[]()~`>#+-=|{}.!\\"
+
+func (telegramMarkup) Name() string { return "telegram" }
+
+func (telegramMarkup) Escape(text string) string {
+	return escapeChars(text, markdownV2Reserved)
+}
+
+func (telegramMarkup) Static(text string) string {
+	// Keep the formatting markers, escape everything else
+	return escapeChars(text, "[]()>#+-=|{}.!\\")
+}
+
+// discordMarkup renders Discord markdown (bold is **text**)
+type discordMarkup struct{}
+
+func (discordMarkup) Name() string { return "discord" }
+
+func (discordMarkup) Escape(text string) string {
+	return escapeChars(text, "\\*_~`|>")
+}
+
+func (discordMarkup) Static(text string) string {
+	return strings.ReplaceAll(text, "*", "**")
+}
+
+// slackMarkup renders Slack mrkdwn (*bold*, _italic_ already match our markup)
+type slackMarkup struct{}
+
+func (slackMarkup) Name() string { return "slack" }
+
+func (slackMarkup) Escape(text string) string {
+	return slackEntities.Replace(text)
+}
+
+func (slackMarkup) Static(text string) string {
+	return slackEntities.Replace(text)
+}
+
+var slackEntities = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// plainMarkup leaves text untouched (generic webhook consumers)
+type plainMarkup struct{}
+
+func (plainMarkup) Name() string              { return "plain" }
+func (plainMarkup) Escape(text string) string { return text }
+func (plainMarkup) Static(text string) string { return text }
+
+func escapeChars(text, reserved string) string {
+	var b strings.Builder
+	b.Grow(len(text))
+	for _, r := range text {
+		if strings.ContainsRune(reserved, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"grid-trading-btc-binance/internal/model"
+)
+
+const TradeLedgerCSVPath = "logs/trade_ledger.csv"
+
+// TradeLedgerHeader lists the columns of the per-trade ledger (one row per round trip)
+var TradeLedgerHeader = []string{
+	"buy_order_id", "sell_order_id", "source", "symbol",
+	"buy_time", "buy_price", "sell_time", "sell_price", "qty",
+	"gross_usdt", "fee_bnb", "fee_usdt", "net_usdt",
+	"holding_min", "spacing_pct", "entry_regime", "entry_level",
+}
+
+// TradeLedgerTyped are the numeric ledger columns (order ids stay strings)
+var TradeLedgerTyped = columnSet(
+	"buy_price", "sell_price", "qty", "gross_usdt", "fee_bnb", "fee_usdt", "net_usdt",
+	"holding_min", "spacing_pct", "entry_level",
+)
+
+// TradeLedger appends every closed round trip (buy + exit) to logs/trade_ledger.csv, keeping
+// the per-trade detail the hourly aggregate loses. Parquet is not supported: CSV (and the
+// optional JSON Lines copy) load directly into pandas/DuckDB.
+type TradeLedger struct {
+	MarketData *MarketDataService // BNB price used to value the fees
+	BNBSymbol  string             // BNB/quote pair the fees are valued at
+	Writer     *RecordWriter
+}
+
+func NewTradeLedger(marketData *MarketDataService, quoteAsset string, jsonOutput bool) *TradeLedger {
+	jsonPath := ""
+	if jsonOutput {
+		jsonPath = "logs/trade_ledger.jsonl"
+	}
+	return &TradeLedger{
+		MarketData: marketData,
+		BNBSymbol:  "BNB" + quoteAsset,
+		Writer:     NewRecordWriter(TradeLedgerCSVPath, jsonPath, TradeLedgerHeader, TradeLedgerTyped),
+	}
+}
+
+// Start starts the background persistence of the ledger rows
+func (l *TradeLedger) Start() {
+	l.Writer.Start()
+}
+
+// Add records the buy tx sold at sellPrice and returns the ledger row. source tells how it
+// closed: grid (maker exit), recovery (exit found filled on sync), dca or panic.
+func (l *TradeLedger) Add(tx model.Transaction, sellPrice float64, soldAt time.Time, source string) []string {
+	bnbPrice, _ := l.MarketData.GetPrice(l.BNBSymbol)
+	record := LedgerRecord(tx, sellPrice, soldAt, source, bnbPrice)
+	l.Writer.Write(record)
+	return record
+}
+
+// LedgerValue returns a numeric column of a ledger row by name (0 if unknown)
+func LedgerValue(record []string, column string) float64 {
+	for i, name := range TradeLedgerHeader {
+		if name == column && i < len(record) {
+			v, _ := strconv.ParseFloat(record[i], 64)
+			return v
+		}
+	}
+	return 0
+}
+
+// LedgerRecord builds the ledger row of a round trip. Fees are the BNB commissions of both
+// legs valued at bnbPrice (fee_usdt and net_usdt are 0/gross when the price is unknown).
+func LedgerRecord(tx model.Transaction, sellPrice float64, soldAt time.Time, source string, bnbPrice float64) []string {
+	buyPrice, _ := strconv.ParseFloat(tx.Price, 64)
+	qty, _ := strconv.ParseFloat(tx.Amount, 64)
+	feeBNB, _ := strconv.ParseFloat(tx.Fee, 64)
+
+	gross := (sellPrice - buyPrice) * qty
+	feeUSDT := feeBNB * bnbPrice
+
+	return []string{
+		tx.ID,
+		tx.SellOrderID,
+		source,
+		tx.Symbol,
+		tx.CreatedAt.UTC().Format(time.RFC3339),
+		fmt.Sprintf("%.2f", buyPrice),
+		soldAt.UTC().Format(time.RFC3339),
+		fmt.Sprintf("%.2f", sellPrice),
+		fmt.Sprintf("%.8f", qty),
+		fmt.Sprintf("%.4f", gross),
+		fmt.Sprintf("%.8f", feeBNB),
+		fmt.Sprintf("%.4f", feeUSDT),
+		fmt.Sprintf("%.4f", gross-feeUSDT),
+		fmt.Sprintf("%.1f", soldAt.Sub(tx.CreatedAt).Minutes()),
+		fmt.Sprintf("%.6f", tx.ExitSpacingPct),
+		tx.EntryRegime,
+		strconv.Itoa(tx.GridLevel()),
+	}
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"grid-trading-btc-binance/internal/model"
+)
+
+const tradeStatsLookbackDays = 30
+
+// TradeStats are trade-level statistics over the archived round trips of a period
+type TradeStats struct {
+	Trades       int
+	WinRate      float64 // Share of trades with a positive net result (0-1)
+	AvgWin       float64 // USDT, net of fees
+	AvgLoss      float64 // USDT, net of fees (negative)
+	ProfitFactor float64 // Gross wins / gross losses (0 when there are no losing trades)
+	Expectancy   float64 // Mean net result per trade (USDT)
+	Sharpe       float64 // Annualized, over daily returns (days without trades count as 0)
+	Sortino      float64 // Same, penalizing only the negative days
+}
+
+// ComputeTradeStats evaluates the closed round trips (buy + exit) of the last `days` days.
+// Fees are the BNB commissions valued at bnbPrice, like the hourly fee columns. Daily
+// returns are the day's net PnL over the equity at the start of that day, rebuilt backwards
+// from the current equity by removing the realized PnL of the following days.
+func ComputeTradeStats(closed []model.Transaction, equity, bnbPrice float64, now time.Time, days int) TradeStats {
+	var stats TradeStats
+	start := now.AddDate(0, 0, -days)
+	daily := make([]float64, days)
+
+	var grossWins, grossLosses float64
+	wins, losses := 0, 0
+	for _, tx := range closed {
+		if tx.Type != "buy" || tx.StatusTransaction != model.StatusClosed || tx.SellOrderID == "" || tx.SellPrice == 0 || tx.ClosedAt == nil {
+			continue
+		}
+		if tx.ClosedAt.Before(start) || tx.ClosedAt.After(now) {
+			continue
+		}
+
+		amount, _ := strconv.ParseFloat(tx.Amount, 64)
+		buyPrice, _ := strconv.ParseFloat(tx.Price, 64)
+		fee, _ := strconv.ParseFloat(tx.Fee, 64)
+		net := (tx.SellPrice-buyPrice)*amount - fee*bnbPrice
+
+		stats.Trades++
+		stats.Expectancy += net
+		if net > 0 {
+			wins++
+			grossWins += net
+		} else {
+			losses++
+			grossLosses += -net
+		}
+
+		day := int(tx.ClosedAt.Sub(start) / (24 * time.Hour))
+		if day >= days {
+			day = days - 1
+		}
+		daily[day] += net
+	}
+
+	if stats.Trades == 0 {
+		return stats
+	}
+	stats.WinRate = float64(wins) / float64(stats.Trades)
+	stats.Expectancy /= float64(stats.Trades)
+	if wins > 0 {
+		stats.AvgWin = grossWins / float64(wins)
+	}
+	if losses > 0 {
+		stats.AvgLoss = -grossLosses / float64(losses)
+	}
+	if grossLosses > 0 {
+		stats.ProfitFactor = grossWins / grossLosses
+	}
+
+	// Daily returns over the equity at the start of each day (walking back from now)
+	returns := make([]float64, days)
+	dayEquity := equity
+	for i := days - 1; i >= 0; i-- {
+		dayEquity -= daily[i]
+		if dayEquity > 0 {
+			returns[i] = daily[i] / dayEquity
+		}
+	}
+	stats.Sharpe, stats.Sortino = sharpeSortino(returns, 365)
+	return stats
+}
+
+// sharpeSortino annualizes the mean return over its standard deviation (Sharpe) and over
+// its downside deviation (Sortino)
+func sharpeSortino(returns []float64, periodsPerYear float64) (float64, float64) {
+	if len(returns) < 2 {
+		return 0, 0
+	}
+	mean := 0.0
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+
+	variance, downside := 0.0, 0.0
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+		if r < 0 {
+			downside += r * r
+		}
+	}
+	std := math.Sqrt(variance / float64(len(returns)))
+	downDev := math.Sqrt(downside / float64(len(returns)))
+
+	annual := math.Sqrt(periodsPerYear)
+	var sharpe, sortino float64
+	if std > 0 {
+		sharpe = mean / std * annual
+	}
+	if downDev > 0 {
+		sortino = mean / downDev * annual
+	}
+	return sharpe, sortino
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"hash/fnv"
+
+	"grid-trading-btc-binance/internal/crash"
+	"grid-trading-btc-binance/internal/logger"
+)
+
+const workerQueueSize = 50
+
+// UpdateWorkerPool processes order updates concurrently, so one slow event (REST calls while
+// placing an exit) doesn't hold back the others. Updates with the same key are always routed
+// to the same worker, keeping the events of one order (and its exit) in sequence.
+type UpdateWorkerPool struct {
+	workers []chan OrderUpdate
+	keyFn   func(OrderUpdate) string
+	handle  func(OrderUpdate)
+}
+
+func NewUpdateWorkerPool(size int, keyFn func(OrderUpdate) string, handle func(OrderUpdate)) *UpdateWorkerPool {
+	if size < 1 {
+		size = 1
+	}
+	p := &UpdateWorkerPool{
+		workers: make([]chan OrderUpdate, size),
+		keyFn:   keyFn,
+		handle:  handle,
+	}
+	for i := range p.workers {
+		p.workers[i] = make(chan OrderUpdate, workerQueueSize)
+		go p.work(p.workers[i])
+	}
+	return p
+}
+
+// Run dispatches updates until the channel is closed (blocking)
+func (p *UpdateWorkerPool) Run(updates <-chan OrderUpdate) {
+	logger.Info("👷 Order update workers started", "workers", len(p.workers))
+	for update := range updates {
+		p.Dispatch(update)
+	}
+	for _, w := range p.workers {
+		close(w)
+	}
+}
+
+// Dispatch queues one update on the worker of its key (blocking while that queue is full).
+// Updates not coming from the stream (resync replays) go through here, so they never run
+// concurrently with the live events of the same transaction.
+func (p *UpdateWorkerPool) Dispatch(update OrderUpdate) {
+	h := fnv.New32a()
+	h.Write([]byte(p.keyFn(update)))
+	p.workers[h.Sum32()%uint32(len(p.workers))] <- update
+}
+
+func (p *UpdateWorkerPool) work(queue <-chan OrderUpdate) {
+	for update := range queue {
+		crash.Guard("order update "+update.ClientOrderID, func() { p.handle(update) })
+	}
+}
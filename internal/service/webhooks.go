@@ -0,0 +1,135 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"grid-trading-btc-binance/internal/logger"
+)
+
+const discordMaxContentLength = 2000
+
+var webhookClient = &http.Client{Timeout: 10 * time.Second}
+
+// postJSON sends the payload asynchronously, mirroring TelegramService.SendMessage
+func postJSON(channel, url string, payload interface{}, headers map[string]string) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		logger.Error("Failed to marshal notification payload", "channel", channel, "error", err)
+		return
+	}
+
+	go func() {
+		req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+		if err != nil {
+			logger.Error("Failed to create notification request", "channel", channel, "error", err)
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+
+		resp, err := webhookClient.Do(req)
+		if err != nil {
+			logger.Error("Failed to send notification", "channel", channel, "error", err)
+			return
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			logger.Error("Notification channel API error", "channel", channel, "status", resp.Status)
+		}
+	}()
+}
+
+// DiscordNotifier posts messages to a Discord channel webhook
+type DiscordNotifier struct {
+	URL string
+}
+
+func NewDiscordNotifier(url string) *DiscordNotifier {
+	return &DiscordNotifier{URL: url}
+}
+
+func (d *DiscordNotifier) Name() string {
+	return "discord"
+}
+
+func (d *DiscordNotifier) Markup() Markup {
+	return discordMarkup{}
+}
+
+func (d *DiscordNotifier) Send(msg Message) {
+	content := msg.Text
+	if len(content) > discordMaxContentLength {
+		content = truncateUTF8(content, discordMaxContentLength-3) + "..."
+	}
+	postJSON(d.Name(), d.URL, map[string]string{"content": content}, nil)
+}
+
+// SlackNotifier posts messages to a Slack incoming webhook
+type SlackNotifier struct {
+	URL string
+}
+
+func NewSlackNotifier(url string) *SlackNotifier {
+	return &SlackNotifier{URL: url}
+}
+
+func (s *SlackNotifier) Name() string {
+	return "slack"
+}
+
+func (s *SlackNotifier) Markup() Markup {
+	return slackMarkup{}
+}
+
+func (s *SlackNotifier) Send(msg Message) {
+	postJSON(s.Name(), s.URL, map[string]string{"text": msg.Text}, nil)
+}
+
+// WebhookPayload is the JSON body sent by the generic webhook channel
+type WebhookPayload struct {
+	Source    string `json:"source"`
+	Category  string `json:"category"`
+	Severity  string `json:"severity"`
+	Text      string `json:"text"`
+	Timestamp string `json:"timestamp"`
+}
+
+// WebhookNotifier posts a structured JSON payload to any HTTP endpoint
+type WebhookNotifier struct {
+	URL   string
+	Token string
+}
+
+func NewWebhookNotifier(url, token string) *WebhookNotifier {
+	return &WebhookNotifier{URL: url, Token: token}
+}
+
+func (w *WebhookNotifier) Name() string {
+	return "webhook"
+}
+
+func (w *WebhookNotifier) Markup() Markup {
+	return plainMarkup{}
+}
+
+func (w *WebhookNotifier) Send(msg Message) {
+	payload := WebhookPayload{
+		Source:    "grid-trading-btc-binance",
+		Category:  string(msg.Category),
+		Severity:  msg.Severity.String(),
+		Text:      msg.Text,
+		Timestamp: msg.Time.Format(time.RFC3339),
+	}
+
+	var headers map[string]string
+	if w.Token != "" {
+		headers = map[string]string{"Authorization": "Bearer " + w.Token}
+	}
+	postJSON(w.Name(), w.URL, payload, headers)
+}
@@ -0,0 +1,308 @@
+package shadow
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"grid-trading-btc-binance/internal/config"
+	"grid-trading-btc-binance/internal/fees"
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/market"
+	"grid-trading-btc-binance/internal/model"
+	"grid-trading-btc-binance/internal/repository"
+	"grid-trading-btc-binance/internal/service"
+)
+
+const (
+	StateFile      = "shadow_state.json" // Bundled by snapshots
+	ordersCSVPath  = "logs/shadow_orders.csv"
+	dayLayout      = "2006-01-02"
+	minNotionalUSD = 5.0 // Same floor as the live buyQuantity
+)
+
+// OrdersHeader lists the columns of logs/shadow_orders.csv (one row per simulated event)
+var OrdersHeader = []string{
+	"time", "event", "order_id", "price", "qty", "value_usdt", "spacing_pct", "regime", "net_usdt", "cash_usdt",
+}
+
+// order is a simulated grid position: an open buy or a filled buy waiting for its exit
+type order struct {
+	ID        string     `json:"id"`
+	BuyPrice  float64    `json:"buyPrice"`
+	Qty       float64    `json:"qty"`
+	PlacedAt  time.Time  `json:"placedAt"`
+	Filled    bool       `json:"filled"`
+	FilledAt  *time.Time `json:"filledAt,omitempty"`
+	SellPrice float64    `json:"sellPrice,omitempty"`
+	Spacing   float64    `json:"spacing"`
+}
+
+// DayStats are the realized results of one local day, live and shadow side by side
+type DayStats struct {
+	ShadowBuys   int     `json:"shadowBuys"`
+	ShadowTrades int     `json:"shadowTrades"`
+	ShadowNet    float64 `json:"shadowNet"` // Net of the simulated maker fees
+	LiveTrades   int     `json:"liveTrades"`
+	LiveNet      float64 `json:"liveNet"` // Net of the BNB fees (trade ledger)
+}
+
+type state struct {
+	StartedAt     time.Time            `json:"startedAt"`
+	Capital       float64              `json:"capital"`
+	Cash          float64              `json:"cash"` // Free virtual USDT (open buys have their value reserved)
+	Orders        []*order             `json:"orders"`
+	Days          map[string]*DayStats `json:"days"`
+	LastReportDay string               `json:"lastReportDay,omitempty"`
+}
+
+// Engine is a paper copy of the grid running another configuration (SHADOW_PROFILE). It sees
+// the same tickers as the live strategy and follows the placeNewGridOrders/placeMakerExitOrder
+// rules: a buy at the bid when the grid has no open buys or the price dropped the dynamic
+// spacing below the lowest one, filled when the last price trades through it, with an exit at
+// buy * (1 + spacing). Every simulated order goes to logs/shadow_orders.csv. Stale-order
+// expiry, repositioning and the circuit breaker are not simulated (same as the backtest).
+type Engine struct {
+	Cfg        *config.Config // Live config copy with the shadow profile applied
+	Profile    string
+	Volatility *market.VolatilityService
+	storage    *repository.Storage
+	writer     *service.RecordWriter
+
+	mu        sync.Mutex
+	state     state
+	lastPrice float64
+}
+
+func NewEngine(cfg *config.Config, profile string, volatility *market.VolatilityService, storage *repository.Storage) *Engine {
+	return &Engine{
+		Cfg:        cfg,
+		Profile:    profile,
+		Volatility: volatility,
+		storage:    storage,
+		writer:     service.NewRecordWriter(ordersCSVPath, "", OrdersHeader, nil),
+	}
+}
+
+// Load restores the shadow state, or starts a new one with capital virtual USDT
+func (e *Engine) Load(capital float64) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.storage.Exists(StateFile) {
+		if err := e.storage.Read(StateFile, &e.state); err != nil {
+			return err
+		}
+	} else {
+		e.state = state{StartedAt: time.Now(), Capital: capital, Cash: capital}
+	}
+	if e.state.Days == nil {
+		e.state.Days = make(map[string]*DayStats)
+	}
+	e.writer.Start()
+	logger.Info("🧪 Shadow strategy active", "profile", e.Profile, "capital", e.state.Capital, "cash", e.state.Cash, "positions", len(e.state.Orders))
+	return nil
+}
+
+// OnTicker advances the simulation with a live ticker of the traded symbol
+func (e *Engine) OnTicker(ticker model.Ticker) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	e.lastPrice = ticker.Price
+	changed := e.fill(ticker.Price, now)
+	if e.place(ticker, now) {
+		changed = true
+	}
+	if changed {
+		e.save()
+	}
+}
+
+// fill executes the simulated buys and exits the last price traded through
+func (e *Engine) fill(price float64, now time.Time) bool {
+	changed := false
+	remaining := e.state.Orders[:0]
+	for _, o := range e.state.Orders {
+		switch {
+		case !o.Filled && price < o.BuyPrice:
+			o.Filled = true
+			filledAt := now
+			o.FilledAt = &filledAt
+			o.SellPrice = o.BuyPrice * (1 + o.Spacing)
+			e.record(now, "buy_filled", o, o.BuyPrice, 0)
+			changed = true
+		case o.Filled && price > o.SellPrice:
+			fee := fees.For(e.Cfg).Maker
+			revenue := o.SellPrice * o.Qty * (1 - fee)
+			net := revenue - o.BuyPrice*o.Qty*(1+fee)
+			e.state.Cash += revenue
+			day := e.day(now)
+			day.ShadowTrades++
+			day.ShadowNet += net
+			e.record(now, "exit_filled", o, o.SellPrice, net)
+			changed = true
+			continue
+		}
+		remaining = append(remaining, o)
+	}
+	e.state.Orders = remaining
+	return changed
+}
+
+// place opens a new simulated buy when the live grid rules would
+func (e *Engine) place(ticker model.Ticker, now time.Time) bool {
+	cfg := e.Cfg
+	ask, bid := ticker.Price, ticker.Bid
+	if bid <= 0 {
+		bid = ask
+	}
+	if ask < cfg.RangeMin || ask > cfg.RangeMax || len(e.state.Orders) >= cfg.GridLevels {
+		return false
+	}
+
+	spacing := e.Volatility.SpacingFor(cfg)
+	lowestOpen := 0.0
+	for _, o := range e.state.Orders {
+		if !o.Filled && (lowestOpen == 0 || o.BuyPrice < lowestOpen) {
+			lowestOpen = o.BuyPrice
+		}
+		// Anti-duplicate: nothing within half a spacing of an existing order or position
+		if math.Abs(o.BuyPrice-ask)/o.BuyPrice < spacing*0.5 {
+			return false
+		}
+	}
+	if lowestOpen > 0 && (lowestOpen-ask)/lowestOpen < spacing {
+		return false
+	}
+
+	deployable := e.state.Cash - cfg.USDTReserve
+	value := math.Max(deployable*cfg.PositionSizePct, cfg.MinOrderValue)
+	value = math.Max(value, minNotionalUSD)
+	cost := value * (1 + fees.For(cfg).Maker)
+	if deployable < cost {
+		return false
+	}
+
+	o := &order{
+		ID:       fmt.Sprintf("SHADOW_BUY_%d_L%d", now.UnixMilli(), len(e.state.Orders)+1),
+		BuyPrice: bid,
+		Qty:      value / bid,
+		PlacedAt: now,
+		Spacing:  spacing,
+	}
+	e.state.Cash -= cost
+	e.state.Orders = append(e.state.Orders, o)
+	e.day(now).ShadowBuys++
+	e.record(now, "buy_placed", o, bid, 0)
+	return true
+}
+
+// RecordLive adds a live closed trade (net USDT) to the day it closed on
+func (e *Engine) RecordLive(at time.Time, net float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	day := e.day(at)
+	day.LiveTrades++
+	day.LiveNet += net
+	e.save()
+}
+
+// Report is the live vs shadow comparison of one day
+type Report struct {
+	Day           string
+	Profile       string
+	Stats         DayStats
+	Total         DayStats // Every day since the shadow started
+	OpenBuys      int
+	OpenPositions int
+	Equity        float64 // Cash + open buys + positions at price
+	Capital       float64
+}
+
+// PendingReport returns the report of the last finished day not reported yet (positions are
+// valued at the last ticker)
+func (e *Engine) PendingReport(now time.Time) (Report, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	yesterday := now.AddDate(0, 0, -1).Format(dayLayout)
+	if e.lastPrice == 0 || e.state.LastReportDay >= yesterday || e.state.StartedAt.Format(dayLayout) > yesterday {
+		return Report{}, false
+	}
+
+	report := Report{Day: yesterday, Profile: e.Profile, Capital: e.state.Capital, Equity: e.state.Cash}
+	if stats, ok := e.state.Days[yesterday]; ok {
+		report.Stats = *stats
+	}
+	days := make([]string, 0, len(e.state.Days))
+	for d := range e.state.Days {
+		days = append(days, d)
+	}
+	sort.Strings(days)
+	for _, d := range days {
+		if d > yesterday {
+			break
+		}
+		s := e.state.Days[d]
+		report.Total.ShadowBuys += s.ShadowBuys
+		report.Total.ShadowTrades += s.ShadowTrades
+		report.Total.ShadowNet += s.ShadowNet
+		report.Total.LiveTrades += s.LiveTrades
+		report.Total.LiveNet += s.LiveNet
+	}
+	fee := fees.For(e.Cfg).Maker
+	for _, o := range e.state.Orders {
+		if o.Filled {
+			report.OpenPositions++
+			report.Equity += o.Qty * e.lastPrice * (1 - fee)
+		} else {
+			report.OpenBuys++
+			report.Equity += o.BuyPrice * o.Qty * (1 + fee)
+		}
+	}
+	return report, true
+}
+
+// MarkReported stores the last day whose report was sent
+func (e *Engine) MarkReported(day string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.state.LastReportDay = day
+	e.save()
+}
+
+func (e *Engine) day(at time.Time) *DayStats {
+	key := at.Format(dayLayout)
+	stats, ok := e.state.Days[key]
+	if !ok {
+		stats = &DayStats{}
+		e.state.Days[key] = stats
+	}
+	return stats
+}
+
+func (e *Engine) record(at time.Time, event string, o *order, price, net float64) {
+	e.writer.Write([]string{
+		at.UTC().Format(time.RFC3339),
+		event,
+		o.ID,
+		fmt.Sprintf("%.2f", price),
+		fmt.Sprintf("%.8f", o.Qty),
+		fmt.Sprintf("%.4f", price*o.Qty),
+		fmt.Sprintf("%.6f", o.Spacing),
+		e.Volatility.GetRegime(),
+		fmt.Sprintf("%.4f", net),
+		fmt.Sprintf("%.2f", e.state.Cash),
+	})
+}
+
+func (e *Engine) save() {
+	if err := e.storage.Write(StateFile, e.state); err != nil {
+		logger.Error("Failed to persist shadow state", "error", err)
+	}
+}
@@ -0,0 +1,177 @@
+// Package snapshot bundles the bot state files into a single versioned JSON file and restores
+// them, for host migration and rollback after bad manual edits.
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"grid-trading-btc-binance/internal/model"
+	"grid-trading-btc-binance/internal/repository"
+	"grid-trading-btc-binance/internal/shadow"
+)
+
+// Version is the snapshot format. Restore refuses newer versions.
+const Version = 1
+
+const (
+	filePrefix = "snapshot-"
+	fileLayout = "20060102-150405"
+)
+
+// Snapshot is the whole bot state at CreatedAt
+type Snapshot struct {
+	Version   int                        `json:"version"`
+	CreatedAt time.Time                  `json:"createdAt"`
+	Symbol    string                     `json:"symbol"`
+	Reason    string                     `json:"reason"`             // manual, periodic, pre-restore, backup
+	Balances  []model.Balance            `json:"balances,omitempty"` // Informational: balances are synced from Binance, never restored
+	Files     map[string]json.RawMessage `json:"files"`              // State file path -> content
+}
+
+// StateFiles are the files a snapshot bundles
+func StateFiles() []string {
+	return append(repository.StateFiles(), shadow.StateFile)
+}
+
+// Create reads the state files (missing ones are left out, and removed again on restore)
+func Create(symbol, reason string, balances []model.Balance) (*Snapshot, error) {
+	snap := &Snapshot{
+		Version:   Version,
+		CreatedAt: time.Now(),
+		Symbol:    symbol,
+		Reason:    reason,
+		Balances:  balances,
+		Files:     make(map[string]json.RawMessage),
+	}
+	for _, path := range StateFiles() {
+		raw, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if !json.Valid(raw) {
+			return nil, fmt.Errorf("%s is not valid JSON", path)
+		}
+		snap.Files[path] = bytes.TrimSpace(raw)
+	}
+	return snap, nil
+}
+
+// Write saves snap in dir as snapshot-YYYYMMDD-HHMMSS.json and returns its path
+func Write(dir string, snap *Snapshot) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	path := filepath.Join(dir, filePrefix+snap.CreatedAt.Format(fileLayout)+".json")
+	raw, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	if err := writeAtomic(path, raw); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// Read loads and validates a snapshot file
+func Read(path string) (*Snapshot, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(raw, &snap); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot %s: %w", path, err)
+	}
+	if snap.Version < 1 || snap.Version > Version {
+		return nil, fmt.Errorf("unsupported snapshot version %d (this build reads up to %d)", snap.Version, Version)
+	}
+	return &snap, nil
+}
+
+// Restore writes every bundled file back and removes the state files that did not exist
+// when the snapshot was taken, so the tree matches the snapshot exactly
+func Restore(snap *Snapshot) error {
+	for _, path := range StateFiles() {
+		raw, ok := snap.Files[path]
+		if !ok {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove %s: %w", path, err)
+			}
+			continue
+		}
+		if dir := filepath.Dir(path); dir != "." {
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				return fmt.Errorf("failed to create %s: %w", dir, err)
+			}
+		}
+		var buf bytes.Buffer // Same layout as the repositories write (the snapshot nests it deeper)
+		if err := json.Indent(&buf, raw, "", "  "); err != nil {
+			return fmt.Errorf("invalid content for %s: %w", path, err)
+		}
+		buf.WriteByte('\n')
+		if err := writeAtomic(path, buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Prune keeps the newest keep snapshots in dir (0 = keep all) and returns how many it removed
+func Prune(dir string, keep int) (int, error) {
+	if keep <= 0 {
+		return 0, nil
+	}
+	paths, err := List(dir)
+	if err != nil || len(paths) <= keep {
+		return 0, err
+	}
+	removed := 0
+	for _, path := range paths[:len(paths)-keep] {
+		if err := os.Remove(path); err != nil {
+			return removed, fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// List returns the snapshot files in dir, oldest first
+func List(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, entry := range entries {
+		if name := entry.Name(); !entry.IsDir() && strings.HasPrefix(name, filePrefix) && strings.HasSuffix(name, ".json") {
+			paths = append(paths, filepath.Join(dir, name))
+		}
+	}
+	sort.Strings(paths) // The timestamp layout sorts chronologically
+	return paths, nil
+}
+
+// writeAtomic writes through a temporary file, so a crash never leaves a truncated state file
+func writeAtomic(path string, raw []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
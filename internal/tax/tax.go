@@ -0,0 +1,163 @@
+package tax
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Lot matching methods
+const (
+	MethodFIFO    = "fifo"
+	MethodLIFO    = "lifo"
+	MethodAverage = "average" // Average cost of the whole position (e.g. Brazilian custo médio)
+)
+
+// ValidateMethod checks a lot matching method name
+func ValidateMethod(method string) error {
+	switch method {
+	case MethodFIFO, MethodLIFO, MethodAverage:
+		return nil
+	}
+	return fmt.Errorf("invalid lot matching method %q (expected %s, %s or %s)", method, MethodFIFO, MethodLIFO, MethodAverage)
+}
+
+// Fill is one execution (or one archived leg). Fees are already valued in the quote asset;
+// fees paid in the base asset on buys are expected to be taken out of Qty instead.
+type Fill struct {
+	Time    time.Time
+	OrderID string
+	IsBuy   bool
+	Qty     float64
+	Price   float64
+	Fee     float64 // Quote asset
+}
+
+// Disposal is a sale matched against the lots it consumed
+type Disposal struct {
+	Time         time.Time
+	OrderID      string
+	Qty          float64
+	Proceeds     float64 // Quote, net of the sale fee
+	CostBasis    float64 // Quote, including the purchase fees of the matched lots
+	Fee          float64 // Sale fee (already out of Proceeds)
+	HoldingDays  float64 // Quantity-weighted; 0 with average cost
+	UnmatchedQty float64 // Sold without a known purchase (holdings from before the history): zero cost
+}
+
+// Gain returns the capital gain (negative = loss)
+func (d Disposal) Gain() float64 {
+	return d.Proceeds - d.CostBasis
+}
+
+type lot struct {
+	time time.Time
+	qty  float64
+	cost float64
+}
+
+// Match sorts the fills by time and matches every sale against the open lots with the given
+// method. It returns the disposals and the quantity and cost still held at the end.
+func Match(fills []Fill, method string) ([]Disposal, float64, float64, error) {
+	if err := ValidateMethod(method); err != nil {
+		return nil, 0, 0, err
+	}
+	sorted := append([]Fill(nil), fills...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+
+	var lots []lot
+	var disposals []Disposal
+	for _, f := range sorted {
+		if f.Qty <= 0 {
+			continue
+		}
+		if f.IsBuy {
+			cost := f.Qty*f.Price + f.Fee
+			if method == MethodAverage && len(lots) > 0 {
+				lots[0].qty += f.Qty
+				lots[0].cost += cost
+				continue
+			}
+			lots = append(lots, lot{time: f.Time, qty: f.Qty, cost: cost})
+			continue
+		}
+
+		d := Disposal{Time: f.Time, OrderID: f.OrderID, Qty: f.Qty, Proceeds: f.Qty*f.Price - f.Fee, Fee: f.Fee}
+		remaining := f.Qty
+		var weightedDays float64
+		for remaining > 1e-12 && len(lots) > 0 {
+			i := 0
+			if method == MethodLIFO {
+				i = len(lots) - 1
+			}
+			take := min(remaining, lots[i].qty)
+			unitCost := lots[i].cost / lots[i].qty
+			d.CostBasis += take * unitCost
+			weightedDays += take * f.Time.Sub(lots[i].time).Hours() / 24
+			lots[i].qty -= take
+			lots[i].cost -= take * unitCost
+			remaining -= take
+			if lots[i].qty <= 1e-12 {
+				lots = append(lots[:i], lots[i+1:]...)
+			}
+		}
+		if remaining > 1e-12 {
+			d.UnmatchedQty = remaining
+		}
+		if matched := f.Qty - d.UnmatchedQty; method != MethodAverage && matched > 0 {
+			d.HoldingDays = weightedDays / matched
+		}
+		disposals = append(disposals, d)
+	}
+
+	var heldQty, heldCost float64
+	for _, l := range lots {
+		heldQty += l.qty
+		heldCost += l.cost
+	}
+	return disposals, heldQty, heldCost, nil
+}
+
+// YearSummary totals the disposals of one fiscal year
+type YearSummary struct {
+	Year      int
+	Disposals int
+	Proceeds  float64
+	CostBasis float64
+	Fees      float64
+	Gains     float64 // Sum of the positive results
+	Losses    float64 // Sum of the negative results (negative)
+}
+
+// Net returns gains plus losses
+func (y YearSummary) Net() float64 {
+	return y.Gains + y.Losses
+}
+
+// Summarize groups the disposals by calendar year in loc, oldest year first
+func Summarize(disposals []Disposal, loc *time.Location) []YearSummary {
+	byYear := make(map[int]*YearSummary)
+	for _, d := range disposals {
+		year := d.Time.In(loc).Year()
+		y, ok := byYear[year]
+		if !ok {
+			y = &YearSummary{Year: year}
+			byYear[year] = y
+		}
+		y.Disposals++
+		y.Proceeds += d.Proceeds
+		y.CostBasis += d.CostBasis
+		y.Fees += d.Fee
+		if gain := d.Gain(); gain >= 0 {
+			y.Gains += gain
+		} else {
+			y.Losses += gain
+		}
+	}
+	summaries := make([]YearSummary, 0, len(byYear))
+	for _, y := range byYear {
+		summaries = append(summaries, *y)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Year < summaries[j].Year })
+	return summaries
+}
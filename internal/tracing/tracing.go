@@ -0,0 +1,126 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	serviceName = "grid-bot"
+	notesPrefix = "trace=" // Transaction.Notes carries "trace=<W3C traceparent>"
+)
+
+var propagator = propagation.TraceContext{}
+
+// Init exports the spans over OTLP/HTTP to endpoint (Jaeger, Tempo, an OTel collector...).
+// Without an endpoint the global tracer stays a no-op and every helper here costs nothing.
+// The returned function flushes the pending spans on shutdown.
+func Init(endpoint string, sampleRatio float64, symbol string) (func(context.Context) error, error) {
+	if endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(context.Background(), otlptracehttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", serviceName),
+			attribute.String("symbol", symbol),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+func tracer() trace.Tracer {
+	return otel.Tracer("grid-trading-btc-binance")
+}
+
+// Start opens a span as a child of ctx
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartAt opens a span that began at at (the ticker or the exchange event that caused it),
+// so the time spent before the bot reacted shows up in the trace
+func StartAt(ctx context.Context, name string, at time.Time, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if at.IsZero() || at.After(time.Now()) {
+		at = time.Now()
+	}
+	return tracer().Start(ctx, name, trace.WithTimestamp(at), trace.WithAttributes(attrs...))
+}
+
+// Call runs one API call in its own child span, recording its error
+func Call(ctx context.Context, name string, fn func() error, attrs ...attribute.KeyValue) error {
+	_, span := Start(ctx, name, attrs...)
+	err := fn()
+	End(span, err)
+	return err
+}
+
+// End closes span, marking it failed when err is set
+func End(span trace.Span, err error) {
+	Fail(span, err)
+	span.End()
+}
+
+// Fail marks span as failed (no-op when err is nil)
+func Fail(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// Note returns the " | trace=<traceparent>" suffix for Transaction.Notes, or "" when ctx
+// has no sampled span (tracing disabled)
+func Note(ctx context.Context) string {
+	if !trace.SpanContextFromContext(ctx).IsSampled() {
+		return ""
+	}
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	if carrier["traceparent"] == "" {
+		return ""
+	}
+	return " | " + notesPrefix + carrier["traceparent"]
+}
+
+// FromNotes returns a context whose parent is the span saved in the transaction notes, so the
+// fill and the exit of a position join the trace of its buy. Without one it is a fresh context.
+func FromNotes(notes string) context.Context {
+	ctx := context.Background()
+	i := strings.LastIndex(notes, notesPrefix)
+	if i < 0 {
+		return ctx
+	}
+	value := notes[i+len(notesPrefix):]
+	if end := strings.IndexAny(value, " |"); end >= 0 {
+		value = value[:end]
+	}
+	return propagator.Extract(ctx, propagation.MapCarrier{"traceparent": value})
+}
+
+// TraceID returns the trace ID of ctx ("" when not traced), for the logs
+func TraceID(ctx context.Context) string {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.HasTraceID() {
+		return ""
+	}
+	return sc.TraceID().String()
+}
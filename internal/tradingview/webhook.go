@@ -0,0 +1,315 @@
+package tradingview
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"grid-trading-btc-binance/internal/config"
+	"grid-trading-btc-binance/internal/core"
+	"grid-trading-btc-binance/internal/logger"
+	"grid-trading-btc-binance/internal/service"
+)
+
+const (
+	Path              = "/webhook/tradingview"
+	SignatureHeader   = "X-Signature"
+	PauseReasonPrefix = "tradingview: " // Pauses set by a signal: only these are lifted by resume
+
+	maxBodyBytes = 16 << 10
+	maxShiftPct  = 0.5 // A range shift is a nudge, not a new market
+)
+
+// Actions of a signal
+const (
+	ActionPause   = "pause"   // Stop placing buys (the exits keep working)
+	ActionResume  = "resume"  // Lift a pause set by a signal
+	ActionRange   = "range"   // Set min/max, or shift both bounds by shift_pct
+	ActionFlatten = "flatten" // Kill switch: cancel every order and market-sell (TRADINGVIEW_ALLOW_FLATTEN)
+)
+
+// Signal is the JSON body of a TradingView alert, e.g.
+// {"action":"pause","reason":"CPI","symbol":"{{ticker}}","time":"{{timenow}}"}
+type Signal struct {
+	Action   string  `json:"action"`
+	Reason   string  `json:"reason,omitempty"`
+	Symbol   string  `json:"symbol,omitempty"` // Must be SYMBOL when set (an exchange prefix like BINANCE: is ignored)
+	Time     string  `json:"time,omitempty"`   // RFC3339, required unless TRADINGVIEW_MAX_AGE_SEC=0
+	Min      float64 `json:"min,omitempty"`
+	Max      float64 `json:"max,omitempty"`
+	ShiftPct float64 `json:"shift_pct,omitempty"` // Fraction, negative = down (0.02 = +2%)
+}
+
+// Response is the JSON answer to a signal
+type Response struct {
+	OK     bool   `json:"ok"`
+	Action string `json:"action,omitempty"`
+	Result string `json:"result,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// refusal is a valid signal the bot declines to apply (HTTP status and reason)
+type refusal struct {
+	status int
+	err    error
+}
+
+func (r *refusal) Error() string { return r.err.Error() }
+
+// statusOf is the HTTP status of a refusal, 400 for any other error (an invalid signal)
+func statusOf(err error) int {
+	var refused *refusal
+	if errors.As(err, &refused) {
+		return refused.status
+	}
+	return http.StatusBadRequest
+}
+
+func refuse(status int, format string, args ...interface{}) error {
+	return &refusal{status: status, err: fmt.Errorf(format, args...)}
+}
+
+// Server receives the TradingView alerts on TRADINGVIEW_ADDR and maps them to bot actions, so
+// external signals (news, indicators) can gate the grid. Every body must carry the HMAC-SHA256
+// of TRADINGVIEW_SECRET, in the X-Signature header (hex, optionally "sha256=" prefixed) or in
+// the sig query parameter. A signature in the URL ends up in proxy and access logs, so it is
+// only accepted with TRADINGVIEW_MAX_AGE_SEC > 0: the signed time and the replay check keep a
+// leaked URL from being reused.
+type Server struct {
+	Cfg      *config.Config
+	Strategy *core.Strategy
+	Notifier *service.NotificationService
+
+	mu   sync.Mutex           // One signal at a time
+	seen map[string]time.Time // Signatures accepted within TRADINGVIEW_MAX_AGE_SEC (replays)
+}
+
+func NewServer(cfg *config.Config, strategy *core.Strategy, notifier *service.NotificationService) *Server {
+	return &Server{
+		Cfg:      cfg,
+		Strategy: strategy,
+		Notifier: notifier,
+		seen:     make(map[string]time.Time),
+	}
+}
+
+// Start serves the webhook on TRADINGVIEW_ADDR in background
+func (s *Server) Start() {
+	mux := http.NewServeMux()
+	mux.HandleFunc(Path, s.handle)
+
+	server := &http.Server{Addr: s.Cfg.TradingViewAddr, Handler: mux, ReadHeaderTimeout: 5 * time.Second, ReadTimeout: 10 * time.Second}
+	go func() {
+		logger.Info("📡 TradingView webhook listening", "addr", s.Cfg.TradingViewAddr, "path", Path, "allow_flatten", s.Cfg.TradingViewAllowFlatten)
+		if err := server.ListenAndServe(); err != nil {
+			logger.Error("❌ TradingView webhook stopped", "addr", s.Cfg.TradingViewAddr, "error", err)
+		}
+	}()
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.respond(w, http.StatusMethodNotAllowed, Response{Error: "POST only"})
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err != nil {
+		s.respond(w, http.StatusRequestEntityTooLarge, Response{Error: "body too large"})
+		return
+	}
+	signature := r.Header.Get(SignatureHeader)
+	if signature == "" {
+		signature = r.URL.Query().Get("sig")
+		if signature != "" && s.Cfg.TradingViewMaxAgeSec == 0 {
+			logger.Warn("📡 TradingView signal signed in the URL refused: requires TRADINGVIEW_MAX_AGE_SEC > 0", "remote", r.RemoteAddr)
+			s.respond(w, http.StatusUnauthorized, Response{Error: "sig query parameter requires TRADINGVIEW_MAX_AGE_SEC > 0, use the " + SignatureHeader + " header"})
+			return
+		}
+	}
+	if !Verify(s.Cfg.TradingViewSecret, body, signature) {
+		logger.Warn("📡 TradingView signal with an invalid signature refused", "remote", r.RemoteAddr)
+		s.respond(w, http.StatusUnauthorized, Response{Error: "invalid signature"})
+		return
+	}
+	signature = normalizeSignature(signature)
+
+	var signal Signal
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&signal); err != nil {
+		s.respond(w, http.StatusBadRequest, Response{Error: fmt.Sprintf("invalid payload: %v", err)})
+		return
+	}
+	signal.Action = strings.ToLower(strings.TrimSpace(signal.Action))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if err := s.checkFresh(signal, signature, now); err != nil {
+		logger.Warn("📡 TradingView signal refused", "action", signal.Action, "error", err)
+		s.respond(w, statusOf(err), Response{Action: signal.Action, Error: err.Error()})
+		return
+	}
+
+	logger.Info("📡 TradingView signal received", "action", signal.Action, "reason", signal.Reason, "remote", r.RemoteAddr)
+	result, err := s.apply(signal)
+	data := service.TradingViewMessageData{Symbol: s.Cfg.Symbol, Action: signal.Action, Reason: signal.Reason, Result: result}
+	severity := service.SeverityWarning
+	if signal.Action == ActionFlatten {
+		severity = service.SeverityCritical
+	}
+	if err != nil {
+		status := statusOf(err)
+		logger.Warn("📡 TradingView signal not applied", "action", signal.Action, "status", status, "error", err)
+		if status != http.StatusBadRequest {
+			data.Error = err.Error()
+			s.Notifier.NotifyTemplate(service.CategoryCircuitBreaker, severity, service.TemplateTradingViewSignal, data)
+		}
+		s.respond(w, status, Response{Action: signal.Action, Error: err.Error()})
+		return
+	}
+	if s.Cfg.TradingViewMaxAgeSec > 0 {
+		s.seen[signature] = now
+	}
+	logger.Warn("📡 TradingView signal applied", "action", signal.Action, "result", result)
+	s.Notifier.NotifyTemplate(service.CategoryCircuitBreaker, severity, service.TemplateTradingViewSignal, data)
+	s.respond(w, http.StatusOK, Response{OK: true, Action: signal.Action, Result: result})
+}
+
+// checkFresh refuses signals for another symbol and, with TRADINGVIEW_MAX_AGE_SEC, stale or
+// replayed ones
+func (s *Server) checkFresh(signal Signal, signature string, now time.Time) error {
+	if signal.Symbol != "" {
+		symbol := signal.Symbol
+		if i := strings.LastIndex(symbol, ":"); i >= 0 {
+			symbol = symbol[i+1:]
+		}
+		if !strings.EqualFold(symbol, s.Cfg.Symbol) {
+			return fmt.Errorf("signal for %s, this bot trades %s", signal.Symbol, s.Cfg.Symbol)
+		}
+	}
+	if s.Cfg.TradingViewMaxAgeSec == 0 {
+		return nil
+	}
+
+	maxAge := time.Duration(s.Cfg.TradingViewMaxAgeSec) * time.Second
+	for sig, at := range s.seen {
+		if now.Sub(at) > maxAge {
+			delete(s.seen, sig)
+		}
+	}
+	if signal.Time == "" {
+		return fmt.Errorf("missing time (required while TRADINGVIEW_MAX_AGE_SEC > 0)")
+	}
+	sent, err := time.Parse(time.RFC3339, signal.Time)
+	if err != nil {
+		return fmt.Errorf("invalid time %q: expected RFC3339", signal.Time)
+	}
+	if age := now.Sub(sent); age > maxAge || age < -maxAge {
+		return fmt.Errorf("signal time %s is %s away from now (max %s)", signal.Time, age.Round(time.Second), maxAge)
+	}
+	if _, replay := s.seen[signature]; replay {
+		return refuse(http.StatusConflict, "signal already applied")
+	}
+	return nil
+}
+
+// apply runs the action of the signal and describes what was done
+func (s *Server) apply(signal Signal) (string, error) {
+	st := s.Strategy
+	reason := signal.Reason
+	if reason == "" {
+		reason = "signal"
+	}
+	if st.Cfg.MonitorOnly {
+		return "", refuse(http.StatusForbidden, "monitor-only mode (MONITOR_ONLY): signals are applied by the trading instance")
+	}
+
+	switch signal.Action {
+	case ActionPause:
+		if st.IsPaused() {
+			return "", refuse(http.StatusConflict, "already paused (%s)", st.StateRepo.Get().PausedReason)
+		}
+		st.Pause(PauseReasonPrefix + reason)
+		return "Bot pausado: nenhuma compra nova até o sinal de retomada ou /resume.", nil
+
+	case ActionResume:
+		if !st.IsPaused() {
+			return "", refuse(http.StatusConflict, "not paused")
+		}
+		if paused := st.StateRepo.Get().PausedReason; !strings.HasPrefix(paused, PauseReasonPrefix) {
+			return "", refuse(http.StatusConflict, "paused by %q, not by a signal: use /resume", paused)
+		}
+		st.Resume()
+		return "Bot retomado: novas ordens voltarão a ser criadas.", nil
+
+	case ActionRange:
+		rangeMin, rangeMax := signal.Min, signal.Max
+		if signal.ShiftPct != 0 {
+			if rangeMin != 0 || rangeMax != 0 {
+				return "", fmt.Errorf("use either min/max or shift_pct")
+			}
+			if math.Abs(signal.ShiftPct) >= maxShiftPct {
+				return "", fmt.Errorf("shift_pct must be between -%.1f and %.1f, got %.4f", maxShiftPct, maxShiftPct, signal.ShiftPct)
+			}
+			rangeMin, rangeMax = st.Cfg.RangeMin*(1+signal.ShiftPct), st.Cfg.RangeMax*(1+signal.ShiftPct)
+		}
+		canceled, err := st.SetRange(rangeMin, rangeMax)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Range atualizado para $%.2f - $%.2f (%d ordens fora do range canceladas).", rangeMin, rangeMax, canceled), nil
+
+	case ActionFlatten:
+		if !s.Cfg.TradingViewAllowFlatten {
+			return "", refuse(http.StatusForbidden, "flatten is disabled (TRADINGVIEW_ALLOW_FLATTEN=false)")
+		}
+		result, err := st.ExecutePanic("tradingview " + reason)
+		if err != nil {
+			return "", refuse(http.StatusInternalServerError, "flatten finished with errors: %v", err)
+		}
+		return fmt.Sprintf("PANIC executado: %d ordens canceladas, %.5f %s vendidos a $%.2f. Bot PAUSADO, use /resume para retomar.",
+			result.CanceledOrders, result.SoldQty, st.Cfg.BaseAsset, result.AvgPrice), nil
+	}
+	return "", fmt.Errorf("unknown action %q (expected pause, resume, range or flatten)", signal.Action)
+}
+
+func (s *Server) respond(w http.ResponseWriter, status int, response Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.Error("Failed to write TradingView webhook response", "error", err)
+	}
+}
+
+// Sign is the hex HMAC-SHA256 of body with the secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a hex signature ("sha256=" prefix allowed) of body in constant time
+func Verify(secret string, body []byte, signature string) bool {
+	given, err := hex.DecodeString(normalizeSignature(signature))
+	if err != nil || len(given) != sha256.Size {
+		return false
+	}
+	expected, _ := hex.DecodeString(Sign(secret, body))
+	return hmac.Equal(given, expected)
+}
+
+func normalizeSignature(signature string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(signature)), "sha256=")
+}
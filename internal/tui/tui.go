@@ -0,0 +1,216 @@
+// Package tui renders a live status screen in the terminal (tmux-friendly), redrawn in place
+// every second: price, grid ladder, inventory, recent fills and API weight.
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+
+	"grid-trading-btc-binance/internal/core"
+	"grid-trading-btc-binance/internal/crash"
+)
+
+const (
+	apiWeightLimit = 6000 // REQUEST_WEIGHT per minute of the spot API
+	fixedRows      = 22   // Screen rows used by everything but the ladder
+
+	reset  = "\x1b[0m"
+	bold   = "\x1b[1m"
+	dim    = "\x1b[2m"
+	red    = "\x1b[31m"
+	green  = "\x1b[32m"
+	yellow = "\x1b[33m"
+	cyan   = "\x1b[36m"
+)
+
+// Frame is what one redraw shows
+type Frame struct {
+	core.Dashboard
+	TickerAt    time.Time // Last market ticker
+	APIWeight   int       // Last X-MBX-USED-WEIGHT-1M
+	APIWeightAt time.Time
+}
+
+// Screen redraws the frame returned by Source every Interval
+type Screen struct {
+	Out      io.Writer
+	Interval time.Duration
+	Source   func() Frame
+}
+
+// Start draws in background until the process exits
+func (s *Screen) Start() {
+	crash.Go("tui", func() {
+		ticker := time.NewTicker(s.Interval)
+		defer ticker.Stop()
+		fmt.Fprint(s.Out, "\x1b[2J")
+		for {
+			fmt.Fprint(s.Out, "\x1b[H"+Render(s.Source(), time.Now(), ladderRows())+"\x1b[J")
+			<-ticker.C
+		}
+	})
+}
+
+// ladderRows fits the ladder in the terminal height (20 when it is unknown)
+func ladderRows() int {
+	_, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return 20
+	}
+	return max(height-fixedRows, 5)
+}
+
+// Render returns the screen text, each line ending with "clear to end of line"
+func Render(f Frame, now time.Time, maxLadder int) string {
+	var b strings.Builder
+	line := func(format string, args ...interface{}) {
+		fmt.Fprintf(&b, format, args...)
+		b.WriteString("\x1b[K\n")
+	}
+
+	state := green + f.State + reset
+	if f.State != "ACTIVE" {
+		state = yellow + f.State + reset
+	}
+	line("%s🤖 Grid Bot %s%s  %s  profile %s  %s", bold, f.Symbol, reset, state, f.Profile, dim+now.Format("15:04:05")+reset)
+	line("")
+
+	tickerAge := "no ticker"
+	if !f.TickerAt.IsZero() {
+		tickerAge = now.Sub(f.TickerAt).Round(time.Second).String() + " ago"
+	}
+	line("%sPrice%s   %s%s%s  %s(%s)%s", bold, reset, bold, money(f.Price), reset, dim, tickerAge, reset)
+	next := "none (grid full or out of range)"
+	if f.NextBuy > 0 {
+		next = money(f.NextBuy)
+	}
+	line("Regime  %s  spacing %.2f%%  next buy %s", f.Regime, f.Spacing*100, next)
+	line("")
+
+	line("%sGrid Ladder%s (%d levels)", bold, reset, len(f.Ladder))
+	b.WriteString(renderLadder(f, maxLadder))
+	line("")
+
+	pnl := f.UnrealizedPnL
+	pnlColor := green
+	if pnl < 0 {
+		pnlColor = red
+	}
+	avg := 0.0
+	if f.InventoryQty > 0 {
+		avg = f.InventoryCost / f.InventoryQty
+	}
+	line("%sInventory%s  %.5f (cost %s, avg %s)  unrealized %s%+.2f%s  free USDT %s",
+		bold, reset, f.InventoryQty, money(f.InventoryCost), money(avg), pnlColor, pnl, reset, money(f.FreeUSDT))
+	line("")
+
+	line("%sRecent Fills%s", bold, reset)
+	if len(f.Fills) == 0 {
+		line("  %snone since start%s", dim, reset)
+	}
+	for i, fill := range f.Fills {
+		if i == 5 {
+			break
+		}
+		color, extra := cyan, ""
+		if fill.Side == "SELL" {
+			color, extra = green, fmt.Sprintf("  profit %+.2f", fill.Profit)
+		}
+		line("  %s  %s%-4s%s %.5f @ %s%s", fill.At.Format("15:04:05"), color, fill.Side, reset, fill.Qty, money(fill.Price), extra)
+	}
+	line("")
+
+	weight := "not reported yet"
+	if !f.APIWeightAt.IsZero() {
+		used := f.APIWeight
+		if now.Sub(f.APIWeightAt) > time.Minute {
+			used = 0 // The window reset since the last call
+		}
+		color := green
+		if used > apiWeightLimit*3/4 {
+			color = red
+		} else if used > apiWeightLimit/2 {
+			color = yellow
+		}
+		weight = fmt.Sprintf("%s%d%s / %d per minute", color, used, reset, apiWeightLimit)
+	}
+	line("%sAPI Weight%s  %s", bold, reset, weight)
+	line("%sCtrl+C stops the bot (pending state is flushed)%s", dim, reset)
+	return b.String()
+}
+
+// renderLadder lists the levels from the highest price down, with the current price and the
+// next buy trigger placed between them. Far levels are elided to fit maxRows.
+func renderLadder(f Frame, maxRows int) string {
+	type row struct {
+		price float64
+		text  string
+	}
+	var rows []row
+	for _, l := range f.Ladder {
+		var text string
+		switch l.Kind {
+		case "buy":
+			text = fmt.Sprintf("  %s%-5s%s %12s  %.5f  %s", cyan, "BUY", reset, money(l.Price), l.Qty, dim+l.ID+reset)
+		case "exit":
+			text = fmt.Sprintf("  %s%-5s%s %12s  %.5f  entry %s (%+.2f%%)", green, "EXIT", reset, money(l.Price), l.Qty, money(l.EntryPrice), pct(l.Price, l.EntryPrice))
+		default:
+			text = fmt.Sprintf("  %s%-5s%s %12s  %.5f  no exit yet", yellow, "HELD", reset, money(l.Price), l.Qty)
+		}
+		rows = append(rows, row{l.Price, text})
+	}
+	markers := []row{{f.Price, fmt.Sprintf("  %s◀ PRICE %12s%s", bold, money(f.Price), reset)}}
+	if f.NextBuy > 0 && f.NextBuy != f.Price {
+		markers = append(markers, row{f.NextBuy, fmt.Sprintf("  %s· next  %12s%s", dim, money(f.NextBuy), reset)})
+	}
+	for _, m := range markers {
+		at := len(rows)
+		for i, r := range rows {
+			if m.price >= r.price {
+				at = i
+				break
+			}
+		}
+		rows = append(rows[:at], append([]row{m}, rows[at:]...)...)
+	}
+
+	// Keep the rows around the price when the ladder does not fit
+	start, end := 0, len(rows)
+	if len(rows) > maxRows {
+		center := 0
+		for i, r := range rows {
+			if strings.Contains(r.text, "◀ PRICE") {
+				center = i
+			}
+		}
+		start = max(min(center-maxRows/2, len(rows)-maxRows), 0)
+		end = start + maxRows
+	}
+	var b strings.Builder
+	if start > 0 {
+		fmt.Fprintf(&b, "  %s… %d above%s\x1b[K\n", dim, start, reset)
+	}
+	for _, r := range rows[start:end] {
+		b.WriteString(r.text + "\x1b[K\n")
+	}
+	if end < len(rows) {
+		fmt.Fprintf(&b, "  %s… %d below%s\x1b[K\n", dim, len(rows)-end, reset)
+	}
+	return b.String()
+}
+
+func money(v float64) string {
+	return fmt.Sprintf("$%.2f", v)
+}
+
+func pct(price, entry float64) float64 {
+	if entry == 0 {
+		return 0
+	}
+	return (price/entry - 1) * 100
+}